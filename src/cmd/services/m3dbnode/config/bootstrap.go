@@ -24,6 +24,8 @@ import (
 	"fmt"
 	"math"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
@@ -37,19 +39,38 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/topology"
+	xcontext "github.com/m3db/m3/src/x/context"
+)
+
+const (
+	// BootstrapperSkipIfPeersUnavailableSuffix is the suffix annotating an
+	// entry of the list of bootstrappers to be skipped when the peers are
+	// unavailable, e.g. "peers?" so that the following bootstrappers are
+	// preferred when peers are down.
+	BootstrapperSkipIfPeersUnavailableSuffix = "?"
 )
 
 var (
 	// defaultNumProcessorsPerCPU is the default number of processors per CPU.
 	defaultNumProcessorsPerCPU = 0.125
+
+	// defaultPartialFailureRetryInterval is the default interval at which
+	// namespaces and shards that failed to bootstrap are retried.
+	defaultPartialFailureRetryInterval = time.Minute
 )
 
 // BootstrapConfiguration specifies the config for bootstrappers.
 type BootstrapConfiguration struct {
 	// Bootstrappers is the list of bootstrappers, ordered by precedence in
-	// descending order.
+	// descending order. The peers bootstrapper may be annotated as "peers?"
+	// to be skipped entirely when peers cannot serve all of the shards.
 	Bootstrappers []string `yaml:"bootstrappers" validate:"nonzero"`
 
+	// NamespaceBootstrappers overrides the list of bootstrappers for specific
+	// namespaces, keyed by namespace ID, namespaces without an override use
+	// the list of bootstrappers.
+	NamespaceBootstrappers map[string][]string `yaml:"namespaceBootstrappers"`
+
 	// Filesystem bootstrapper configuration.
 	Filesystem *BootstrapFilesystemConfiguration `yaml:"fs"`
 
@@ -59,6 +80,73 @@ type BootstrapConfiguration struct {
 	// CacheSeriesMetadata determines whether individual bootstrappers cache
 	// series metadata across all calls (namespaces / shards / blocks).
 	CacheSeriesMetadata *bool `yaml:"cacheSeriesMetadata"`
+
+	// Verify configures verification that all the shards assigned to the
+	// node by the topology were bootstrapped once bootstrap completes.
+	Verify *BootstrapVerifyConfiguration `yaml:"verify"`
+
+	// VerifyBlocksOnLoad determines whether bootstrapped blocks are verified
+	// against their checksum and decoded before being loaded, corrupt blocks
+	// are skipped rather than loaded. Disabled by default as it requires
+	// reading all of the bootstrapped data an additional time.
+	VerifyBlocksOnLoad *bool `yaml:"verifyBlocksOnLoad"`
+
+	// MergeDuplicateSeriesBootstrap determines whether bootstrapping a series
+	// that is already bootstrapped merges the bootstrapped blocks into the
+	// series rather than failing, which makes re-bootstrapping a subset of
+	// shards idempotent. Disabled by default.
+	MergeDuplicateSeriesBootstrap *bool `yaml:"mergeDuplicateSeriesBootstrap"`
+
+	// PartialFailure configures serving the successfully bootstrapped
+	// namespaces and shards when bootstrapping fails for a subset of them
+	// rather than exiting.
+	PartialFailure *BootstrapPartialFailureConfiguration `yaml:"partialFailure"`
+
+	// NamespaceConcurrency is the number of namespaces bootstrapped
+	// concurrently, defaults to bootstrapping namespaces one at a time.
+	NamespaceConcurrency int `yaml:"namespaceConcurrency" validate:"min=0"`
+}
+
+// BootstrapPartialFailureConfiguration specifies config for handling
+// bootstrap failures for a subset of the namespaces and shards.
+type BootstrapPartialFailureConfiguration struct {
+	// Enabled determines whether bootstrap failures are non-fatal, the
+	// failed namespaces and shards are retried in the background and the
+	// bootstrapped readiness checks fail until they are bootstrapped.
+	Enabled bool `yaml:"enabled"`
+
+	// RetryInterval is the interval at which the namespaces and shards that
+	// failed to bootstrap are retried, defaults to one minute.
+	RetryInterval time.Duration `yaml:"retryInterval" validate:"min=0"`
+}
+
+// RetryIntervalOrDefault returns the configured retry interval if set,
+// or the default interval otherwise.
+func (c BootstrapPartialFailureConfiguration) RetryIntervalOrDefault() time.Duration {
+	if c.RetryInterval > 0 {
+		return c.RetryInterval
+	}
+	return defaultPartialFailureRetryInterval
+}
+
+// BootstrapVerifyConfiguration specifies config for verifying the
+// bootstrapped shards against the topology.
+type BootstrapVerifyConfiguration struct {
+	// Enabled determines whether the verification runs after bootstrap.
+	Enabled bool `yaml:"enabled"`
+
+	// FailReadiness determines whether the bootstrapped readiness checks
+	// fail when any assigned shards were not bootstrapped or a namespace
+	// holds series in less than the MinShardsWithDataFraction of shards.
+	FailReadiness bool `yaml:"failReadiness"`
+
+	// MinShardsWithDataFraction is the minimum fraction of the shards
+	// assigned by the topology that must hold at least one series in each
+	// namespace once bootstrapped, this catches partial bootstraps that leave
+	// whole shards empty. It does not measure how many series or blocks each
+	// shard holds. Zero disables the check, it should only be enabled for
+	// clusters where every namespace is expected to hold data in most shards.
+	MinShardsWithDataFraction float64 `yaml:"minShardsWithDataFraction" validate:"min=0.0,max=1.0"`
 }
 
 // BootstrapFilesystemConfiguration specifies config for the fs bootstrapper.
@@ -86,6 +174,15 @@ type BootstrapCommitlogConfiguration struct {
 	// this to true allows the node to attempt a repair if the peers bootstrapper is configured
 	// after the commitlog bootstrapper.
 	ReturnUnfulfilledForCorruptCommitLogFiles bool `yaml:"returnUnfulfilledForCorruptCommitLogFiles"`
+
+	// ReadConcurrency controls how many commit log files are read and decoded
+	// concurrently during bootstrap, if zero the commit log default is used.
+	ReadConcurrency int `yaml:"readConcurrency" validate:"min=0"`
+
+	// EncodingConcurrency controls how many workers encode the datapoints
+	// read from the commit log during bootstrap, if zero the commitlog
+	// bootstrapper default is used.
+	EncodingConcurrency int `yaml:"encodingConcurrency" validate:"min=0"`
 }
 
 func newDefaultBootstrapCommitlogConfiguration() BootstrapCommitlogConfiguration {
@@ -106,35 +203,81 @@ type BootstrapConfigurationValidator interface {
 	ValidateUninitializedBootstrapperOptions(opts uninitialized.Options) error
 }
 
-// New creates a bootstrap process based on the bootstrap configuration.
+// New creates a bootstrap process based on the bootstrap configuration, if
+// the cancellable is not nil the process aborts bootstrapping once cancelled.
 func (bsc BootstrapConfiguration) New(
 	validator BootstrapConfigurationValidator,
 	opts storage.Options,
 	topoMapProvider topology.MapProvider,
 	origin topology.Host,
 	adminClient client.AdminClient,
+	cancellable xcontext.Cancellable,
 ) (bootstrap.ProcessProvider, error) {
-	if err := validator.ValidateBootstrappersOrder(bsc.Bootstrappers); err != nil {
-		return nil, err
-	}
-
-	var (
-		mutableSegmentAlloc = index.NewBootstrapResultMutableSegmentAllocator(
-			opts.IndexOptions())
-		bs  bootstrap.BootstrapperProvider
-		err error
-	)
+	mutableSegmentAlloc := index.NewBootstrapResultMutableSegmentAllocator(
+		opts.IndexOptions())
 	rsOpts := result.NewOptions().
 		SetInstrumentOptions(opts.InstrumentOptions()).
 		SetDatabaseBlockOptions(opts.DatabaseBlockOptions()).
 		SetSeriesCachePolicy(opts.SeriesCachePolicy()).
 		SetIndexMutableSegmentAllocator(mutableSegmentAlloc)
 
-	fsOpts := opts.CommitLogOptions().FilesystemOptions()
+	bs, err := bsc.newBootstrapperProvider(bsc.Bootstrappers, validator,
+		opts, rsOpts, adminClient)
+	if err != nil {
+		return nil, err
+	}
+
+	nsProviders := make(map[string]bootstrap.BootstrapperProvider,
+		len(bsc.NamespaceBootstrappers))
+	for ns, bootstrappers := range bsc.NamespaceBootstrappers {
+		nsProviders[ns], err = bsc.newBootstrapperProvider(bootstrappers,
+			validator, opts, rsOpts, adminClient)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"invalid bootstrappers for namespace %s: %v", ns, err)
+		}
+	}
+
+	providerOpts := bootstrap.NewProcessOptions().
+		SetTopologyMapProvider(topoMapProvider).
+		SetOrigin(origin)
+	if bsc.CacheSeriesMetadata != nil {
+		providerOpts = providerOpts.SetCacheSeriesMetadata(*bsc.CacheSeriesMetadata)
+	}
+	if cancellable != nil {
+		providerOpts = providerOpts.SetCancellable(cancellable)
+	}
+	provider, err := bootstrap.NewProcessProvider(bs, providerOpts, rsOpts)
+	if err != nil {
+		return nil, err
+	}
+	provider.SetNamespaceBootstrapperProviders(nsProviders)
+	return provider, nil
+}
+
+// newBootstrapperProvider creates a bootstrapper provider chaining the
+// named bootstrappers in order of precedence.
+func (bsc BootstrapConfiguration) newBootstrapperProvider(
+	bootstrappers []string,
+	validator BootstrapConfigurationValidator,
+	opts storage.Options,
+	rsOpts result.Options,
+	adminClient client.AdminClient,
+) (bootstrap.BootstrapperProvider, error) {
+	if err := validator.ValidateBootstrappersOrder(bootstrappers); err != nil {
+		return nil, err
+	}
+
+	var (
+		bs     bootstrap.BootstrapperProvider
+		err    error
+		fsOpts = opts.CommitLogOptions().FilesystemOptions()
+	)
 
 	// Start from the end of the list because the bootstrappers are ordered by precedence in descending order.
-	for i := len(bsc.Bootstrappers) - 1; i >= 0; i-- {
-		switch bsc.Bootstrappers[i] {
+	for i := len(bootstrappers) - 1; i >= 0; i-- {
+		name, skipIfPeersUnavailable := ParseBootstrapper(bootstrappers[i])
+		switch name {
 		case bootstrapper.NoOpAllBootstrapperName:
 			bs = bootstrapper.NewNoOpAllBootstrapperProvider()
 		case bootstrapper.NoOpNoneBootstrapperName:
@@ -159,11 +302,18 @@ func (bsc BootstrapConfiguration) New(
 			}
 		case commitlog.CommitLogBootstrapperName:
 			cCfg := bsc.commitlogConfig()
+			commitLogOpts := opts.CommitLogOptions()
+			if cCfg.ReadConcurrency > 0 {
+				commitLogOpts = commitLogOpts.SetReadConcurrency(cCfg.ReadConcurrency)
+			}
 			cOpts := commitlog.NewOptions().
 				SetResultOptions(rsOpts).
-				SetCommitLogOptions(opts.CommitLogOptions()).
+				SetCommitLogOptions(commitLogOpts).
 				SetRuntimeOptionsManager(opts.RuntimeOptionsManager()).
 				SetReturnUnfulfilledForCorruptCommitLogFiles(cCfg.ReturnUnfulfilledForCorruptCommitLogFiles)
+			if cCfg.EncodingConcurrency > 0 {
+				cOpts = cOpts.SetEncodingConcurrency(cCfg.EncodingConcurrency)
+			}
 			if err := validator.ValidateCommitLogBootstrapperOptions(cOpts); err != nil {
 				return nil, err
 			}
@@ -181,7 +331,8 @@ func (bsc BootstrapConfiguration) New(
 				SetAdminClient(adminClient).
 				SetPersistManager(opts.PersistManager()).
 				SetDatabaseBlockRetrieverManager(opts.DatabaseBlockRetrieverManager()).
-				SetRuntimeOptionsManager(opts.RuntimeOptionsManager())
+				SetRuntimeOptionsManager(opts.RuntimeOptionsManager()).
+				SetSkipIfPeersUnavailable(skipIfPeersUnavailable)
 			if err := validator.ValidatePeersBootstrapperOptions(pOpts); err != nil {
 				return nil, err
 			}
@@ -198,17 +349,21 @@ func (bsc BootstrapConfiguration) New(
 			}
 			bs = uninitialized.NewUninitializedTopologyBootstrapperProvider(uOpts, bs)
 		default:
-			return nil, fmt.Errorf("unknown bootstrapper: %s", bsc.Bootstrappers[i])
+			return nil, fmt.Errorf("unknown bootstrapper: %s", bootstrappers[i])
 		}
 	}
 
-	providerOpts := bootstrap.NewProcessOptions().
-		SetTopologyMapProvider(topoMapProvider).
-		SetOrigin(origin)
-	if bsc.CacheSeriesMetadata != nil {
-		providerOpts = providerOpts.SetCacheSeriesMetadata(*bsc.CacheSeriesMetadata)
+	return bs, nil
+}
+
+// ParseBootstrapper parses an entry of the list of bootstrappers, returning
+// the bootstrapper name and whether it is skipped when peers are unavailable.
+// Entries without an annotation are plain bootstrapper names.
+func ParseBootstrapper(entry string) (string, bool) {
+	if strings.HasSuffix(entry, BootstrapperSkipIfPeersUnavailableSuffix) {
+		return strings.TrimSuffix(entry, BootstrapperSkipIfPeersUnavailableSuffix), true
 	}
-	return bootstrap.NewProcessProvider(bs, providerOpts, rsOpts)
+	return entry, false
 }
 
 func (bsc BootstrapConfiguration) filesystemConfig() BootstrapFilesystemConfiguration {
@@ -268,7 +423,13 @@ func (v bootstrapConfigurationValidator) ValidateBootstrappersOrder(names []stri
 	}
 
 	validated := make(map[string]struct{})
-	for _, name := range names {
+	for _, entry := range names {
+		name, skipIfPeersUnavailable := ParseBootstrapper(entry)
+		if skipIfPeersUnavailable && name != peers.PeersBootstrapperName {
+			return fmt.Errorf(
+				"bootstrapper %s cannot be skipped if peers are unavailable", name)
+		}
+
 		precedingAllowed, ok := precedingBootstrappersAllowedByBootstrapper[name]
 		if !ok {
 			return fmt.Errorf("unknown bootstrapper: %v", name)
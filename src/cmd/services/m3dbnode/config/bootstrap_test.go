@@ -24,11 +24,14 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/m3db/m3/src/dbnode/storage"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/bootstrapper"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/bootstrapper/commitlog"
 	bfs "github.com/m3db/m3/src/dbnode/storage/bootstrap/bootstrapper/fs"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/bootstrapper/peers"
+	"github.com/m3db/m3/src/dbnode/topology"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -64,6 +67,12 @@ func TestValidatorValidateBootstrappersOrder(t *testing.T) {
 		{false, []string{commitLogBs, commitLogBs, noOpNoneBs}},
 		// Do not allow unknown bootstrappers
 		{false, []string{"foo"}},
+		// Allow peers to be skipped if peers are unavailable
+		{true, []string{fsBs, peersBs + "?", commitLogBs, noOpNoneBs}},
+		// Do not allow other bootstrappers to be skipped if peers are unavailable
+		{false, []string{fsBs + "?", peersBs, commitLogBs}},
+		// Do not allow an annotated bootstrapper to appear twice
+		{false, []string{fsBs, peersBs + "?", peersBs}},
 	}
 
 	for _, tt := range tests {
@@ -79,3 +88,44 @@ func TestValidatorValidateBootstrappersOrder(t *testing.T) {
 		})
 	}
 }
+
+func TestParseBootstrapper(t *testing.T) {
+	name, skipIfPeersUnavailable := ParseBootstrapper(peersBs)
+	require.Equal(t, peersBs, name)
+	require.False(t, skipIfPeersUnavailable)
+
+	name, skipIfPeersUnavailable = ParseBootstrapper(peersBs + "?")
+	require.Equal(t, peersBs, name)
+	require.True(t, skipIfPeersUnavailable)
+}
+
+func TestBootstrapConfigurationNewNamespaceBootstrappers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		validator       = NewBootstrapConfigurationValidator()
+		opts            = storage.NewOptions()
+		topoMapProvider = topology.NewMockMapProvider(ctrl)
+		origin          = topology.NewHost("origin", "127.0.0.1:9000")
+	)
+
+	cfg := BootstrapConfiguration{
+		Bootstrappers: []string{noOpAllBs},
+		NamespaceBootstrappers: map[string][]string{
+			"foo": []string{noOpNoneBs},
+		},
+	}
+	provider, err := cfg.New(validator, opts, topoMapProvider, origin, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, noOpAllBs, provider.BootstrapperProvider().String())
+
+	nsProviders := provider.NamespaceBootstrapperProviders()
+	require.Equal(t, 1, len(nsProviders))
+	require.Equal(t, noOpNoneBs, nsProviders["foo"].String())
+
+	// Namespace bootstrappers are validated the same as the default list.
+	cfg.NamespaceBootstrappers["bar"] = []string{peersBs, fsBs}
+	_, err = cfg.New(validator, opts, topoMapProvider, origin, nil, nil)
+	require.Error(t, err)
+}
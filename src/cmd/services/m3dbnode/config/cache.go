@@ -61,6 +61,11 @@ func (c CacheConfigurations) PostingsListConfiguration() PostingsListCacheConfig
 type SeriesCacheConfiguration struct {
 	Policy series.CachePolicy                 `yaml:"policy"`
 	LRU    *LRUSeriesCachePolicyConfiguration `yaml:"lru"`
+
+	// EncodedTags keeps a serialized copy of each series' tags in memory so
+	// that fetching blocks metadata can return them without encoding the
+	// tags on every call, at the cost of additional memory per series.
+	EncodedTags bool `yaml:"encodedTags"`
 }
 
 // LRUSeriesCachePolicyConfiguration contains configuration for the LRU
@@ -24,6 +24,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"net/url"
 	"path"
 	"strings"
@@ -32,6 +33,8 @@ import (
 	coordinatorcfg "github.com/m3db/m3/src/cmd/services/m3query/config"
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/environment"
+	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
+	"github.com/m3db/m3/src/dbnode/storage/repair"
 	"github.com/m3db/m3/src/dbnode/storage/series"
 	"github.com/m3db/m3/src/x/config/hostid"
 	"github.com/m3db/m3/src/x/instrument"
@@ -95,6 +98,14 @@ type DBConfiguration struct {
 	// The host and port on which to listen for debug endpoints.
 	DebugListenAddress string `yaml:"debugListenAddress"`
 
+	// DebugProfile is the configuration for the profiles collected by the
+	// debug dump endpoint.
+	DebugProfile *DebugProfileConfiguration `yaml:"debugProfile"`
+
+	// DebugForceSnapshotEnabled enables the debug endpoint that snapshots a
+	// block of a namespace on demand.
+	DebugForceSnapshotEnabled bool `yaml:"debugForceSnapshotEnabled"`
+
 	// HostID is the local host ID configuration.
 	HostID hostid.Configuration `yaml:"hostID"`
 
@@ -145,15 +156,75 @@ type DBConfiguration struct {
 	// Write new series asynchronously for fast ingestion of new ID bursts.
 	WriteNewSeriesAsync bool `yaml:"writeNewSeriesAsync"`
 
+	// Drop writes outside of the buffer past/future window silently rather than
+	// rejecting them with an error for namespaces with cold writes disabled.
+	DropDisabledColdWrites bool `yaml:"dropDisabledColdWrites"`
+
 	// Proto contains the configuration specific to running in the ProtoDataMode.
 	Proto *ProtoConfiguration `yaml:"proto"`
 
 	// Tracing configures opentracing. If not provided, tracing is disabled.
 	Tracing *opentracing.TracingConfiguration `yaml:"tracing"`
 
+	// TracingNamespaceSampleRates overrides the sample rate of the global tracer
+	// for requests to specific namespaces, namespaces without an override are
+	// sampled using the global tracer configured by Tracing.
+	TracingNamespaceSampleRates map[string]float64 `yaml:"tracingNamespaceSampleRates"`
+
 	// Limits contains configuration for limits that can be applied to M3DB for the purposes
 	// of applying back-pressure or protecting the db nodes.
 	Limits Limits `yaml:"limits"`
+
+	// ReadProxyWhileBootstrappingNamespaces are the namespaces for which reads
+	// are proxied to a peer while the node is bootstrapping, this should only
+	// be enabled for namespaces where stale reads are acceptable.
+	ReadProxyWhileBootstrappingNamespaces []string `yaml:"readProxyWhileBootstrappingNamespaces"`
+
+	// BootstrappedReadinessGracePeriod is the period the node must remain
+	// bootstrapped after a restart before the bootstrapped readiness checks
+	// report success, this gives the node time to stabilize before load
+	// balancers begin to send it traffic.
+	BootstrappedReadinessGracePeriod time.Duration `yaml:"bootstrappedReadinessGracePeriod" validate:"min=0"`
+
+	// AdminAuditLog configures auditing of administrative RPCs such as
+	// repair, truncate, quiesce and runtime option changes.
+	AdminAuditLog AdminAuditLogConfiguration `yaml:"adminAuditLog"`
+
+	// StrictStartup when enabled runs pre-flight checks (data directory is
+	// readable, configured schemas load and the topology is reachable) before
+	// binding any listeners so that a misconfigured node fails fast instead of
+	// advertising listeners. By default listeners are bound as early as
+	// possible so orchestration tools can check health endpoints.
+	StrictStartup bool `yaml:"strictStartup"`
+
+	// MaxNamespaceRetentionPeriod is the maximum retention period a namespace
+	// may be created with, namespaces exceeding it are rejected. This guards
+	// against accidentally creating namespaces that would exhaust disk, if
+	// zero there is no maximum.
+	MaxNamespaceRetentionPeriod time.Duration `yaml:"maxNamespaceRetentionPeriod" validate:"min=0"`
+
+	// ConsistencyCheckSampleRate is the fraction of series checked on each
+	// shard tick for block starts present in both the cached blocks and the
+	// buffer, which is a correctness invariant, if zero no series are checked.
+	ConsistencyCheckSampleRate float64 `yaml:"consistencyCheckSampleRate" validate:"min=0.0,max=1.0"`
+
+	// MaxEncodersPerBufferBucket is the maximum number of encoders a buffer
+	// bucket may hold before out of order writes trigger an inline merge of
+	// its encoders rather than waiting for the next tick, bounding the read
+	// amplification of out of order writes. If zero there is no maximum.
+	MaxEncodersPerBufferBucket int `yaml:"maxEncodersPerBufferBucket" validate:"min=0"`
+
+	// SeriesIdleEvictionTimeout is how long a series may go without writes
+	// before it is evicted on tick once all of its data is flushed, freeing
+	// the memory held by short lived series. If zero series are never evicted
+	// for being idle. Ignored with the "all" cache policy since evicted
+	// series could not be read back from disk.
+	SeriesIdleEvictionTimeout time.Duration `yaml:"seriesIdleEvictionTimeout" validate:"min=0"`
+
+	// GracefulShutdownTimeout is how long to wait for the database to close on
+	// shutdown before exiting regardless, defaults to 10s. Nodes with a large
+	// amount of in-memory state may need longer to close cleanly.
+	GracefulShutdownTimeout time.Duration `yaml:"gracefulShutdownTimeout" validate:"min=0"`
 }
 
 // InitDefaultsAndValidate initializes all default values and validates the Configuration.
@@ -179,9 +250,37 @@ func (c *DBConfiguration) InitDefaultsAndValidate() error {
 		return err
 	}
 
+	if err := c.CommitLog.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.DebugProfile.Validate(); err != nil {
+		return err
+	}
+
+	for ns, rate := range c.TracingNamespaceSampleRates {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf(
+				"tracing sample rate for namespace %s must be between 0 and 1, instead: %v",
+				ns, rate)
+		}
+	}
+
 	return nil
 }
 
+// AdminAuditLogConfiguration is the configuration for auditing of
+// administrative RPCs.
+type AdminAuditLogConfiguration struct {
+	// Enabled emits a structured log entry, including the identity of the
+	// caller, each time an administrative RPC is invoked.
+	Enabled bool `yaml:"enabled"`
+
+	// MetricsEnabled increments a counter tagged by operation each time an
+	// administrative RPC is invoked.
+	MetricsEnabled bool `yaml:"metricsEnabled"`
+}
+
 // IndexConfiguration contains index-specific configuration.
 type IndexConfiguration struct {
 	// MaxQueryIDsConcurrency controls the maximum number of outstanding QueryID
@@ -215,6 +314,9 @@ type TransformConfiguration struct {
 	TruncateBy series.TruncateType `yaml:"truncateBy"`
 	// ForcedValue determines what to set all incoming write values to.
 	ForcedValue *float64 `yaml:"forceValue"`
+	// RoundToMultiple rounds all incoming write values to the nearest
+	// multiple of it when positive, e.g. 0.5 or 0.01 for two decimal places.
+	RoundToMultiple float64 `yaml:"roundToMultiple"`
 }
 
 func (c *TransformConfiguration) Validate() error {
@@ -222,9 +324,53 @@ func (c *TransformConfiguration) Validate() error {
 		return nil
 	}
 
+	if m := c.RoundToMultiple; m < 0 || math.IsNaN(m) || math.IsInf(m, 0) {
+		return fmt.Errorf(
+			"transforms roundToMultiple must be a finite non-negative value: %v", m)
+	}
+
 	return c.TruncateBy.Validate()
 }
 
+const (
+	defaultDebugCPUProfileDuration = 5 * time.Second
+	minDebugCPUProfileDuration     = time.Second
+	maxDebugCPUProfileDuration     = time.Minute
+)
+
+// DebugProfileConfiguration is the configuration for the profiles collected
+// by the debug dump endpoint.
+type DebugProfileConfiguration struct {
+	// CPUProfileDuration is how long the CPU profile is collected for,
+	// defaults to 5s.
+	CPUProfileDuration time.Duration `yaml:"cpuProfileDuration"`
+}
+
+// Validate validates the DebugProfileConfiguration.
+func (c *DebugProfileConfiguration) Validate() error {
+	if c == nil || c.CPUProfileDuration == 0 {
+		return nil
+	}
+
+	if c.CPUProfileDuration < minDebugCPUProfileDuration ||
+		c.CPUProfileDuration > maxDebugCPUProfileDuration {
+		return fmt.Errorf(
+			"debug cpu profile duration must be between %v and %v, instead: %v",
+			minDebugCPUProfileDuration, maxDebugCPUProfileDuration,
+			c.CPUProfileDuration)
+	}
+	return nil
+}
+
+// CPUProfileDurationOrDefault returns the configured CPU profile duration or
+// the default if not set.
+func (c *DebugProfileConfiguration) CPUProfileDurationOrDefault() time.Duration {
+	if c == nil || c.CPUProfileDuration == 0 {
+		return defaultDebugCPUProfileDuration
+	}
+	return c.CPUProfileDuration
+}
+
 // TickConfiguration is the tick configuration for background processing of
 // series as blocks are rotated from mutable to immutable and out of order
 // writes are merged.
@@ -248,6 +394,15 @@ type BlockRetrievePolicy struct {
 	// FetchConcurrency is the concurrency to fetch blocks from disk. For
 	// spinning disks it is highly recommended to set this value to 1.
 	FetchConcurrency int `yaml:"fetchConcurrency" validate:"min=0"`
+
+	// MaxOpenFiles is the maximum number of files that may be concurrently
+	// open for reading across all namespace block retrievers, zero means
+	// no limit is applied.
+	MaxOpenFiles int `yaml:"maxOpenFiles" validate:"min=0"`
+
+	// NotFoundCacheSize is the number of series IDs per fileset volume that
+	// are remembered as not present after a failed seek, zero disables it.
+	NotFoundCacheSize int `yaml:"notFoundCacheSize" validate:"min=0"`
 }
 
 // CommitLogPolicy is the commit log policy.
@@ -276,11 +431,61 @@ type CommitLogPolicy struct {
 	// enough for almost all workloads assuming a reasonable batch size is used.
 	QueueChannel *CommitLogQueuePolicy `yaml:"queueChannel"`
 
+	// DurableAckNamespaces are the namespaces for which writes are only
+	// acknowledged once they have been flushed and fsync'd to disk, this
+	// adds up to FlushEvery latency to writes for these namespaces. Note
+	// that the commit log is shared by all namespaces so setting any entry
+	// causes every commit log flush on the node to be fsync'd, including
+	// flushes that only hold writes for other namespaces.
+	DurableAckNamespaces []string `yaml:"durableAckNamespaces"`
+
+	// The size in bytes after which the active commit log segment is rotated,
+	// zero disables rotating segments by size.
+	RotateMaxBytes int `yaml:"rotateMaxBytes" validate:"min=0"`
+
+	// The amount of time after which the active commit log segment is rotated,
+	// zero disables rotating segments by time.
+	RotateEvery time.Duration `yaml:"rotateEvery" validate:"min=0"`
+
+	// The number of queued commit log writes at which writes are rejected
+	// with a retryable error before being applied to series buffers, zero
+	// disables write backpressure.
+	BackpressureHighWatermark int `yaml:"backpressureHighWatermark" validate:"min=0"`
+
+	// The commit log write strategy, either write_behind which acknowledges
+	// writes once they are queued or write_wait which only acknowledges writes
+	// once the chunk containing them has been flushed to disk. Defaults to
+	// write_behind, write_wait adds up to FlushEvery latency to every write and
+	// greatly reduces write throughput.
+	Strategy string `yaml:"strategy"`
+
 	// Deprecated. Left in struct to keep old YAMLs parseable.
 	// TODO(V1): remove
 	DeprecatedBlockSize *time.Duration `yaml:"blockSize"`
 }
 
+// Validate validates the CommitLogPolicy.
+func (p CommitLogPolicy) Validate() error {
+	if p.Strategy == "" {
+		return nil
+	}
+
+	_, err := commitlog.ParseStrategy(p.Strategy)
+	return err
+}
+
+// StrategyOrDefault returns the configured commit log strategy or the
+// write behind strategy if none is configured.
+func (p CommitLogPolicy) StrategyOrDefault() commitlog.Strategy {
+	if p.Strategy == "" {
+		return commitlog.StrategyWriteBehind
+	}
+
+	// NB: the strategy is validated on startup so the error can be ignored.
+	strategy, _ := commitlog.ParseStrategy(p.Strategy)
+	return strategy
+}
+
 // CalculationType is a type of configuration parameter.
 type CalculationType string
 
@@ -326,6 +531,14 @@ type RepairPolicy struct {
 	// If enabled, what percentage of metadata should perform a detailed debug
 	// shadow comparison.
 	DebugShadowComparisonsPercentage float64 `yaml:"debugShadowComparisonsPercentage"`
+
+	// DryRun makes repairs only report the differences between peers, repairs
+	// are never marked complete so every interval reports the full range.
+	DryRun bool `yaml:"dryRun"`
+
+	// PeerSelection is the strategy used to select the peers of a shard that
+	// repairs compare against, defaults to all peers.
+	PeerSelection *repair.PeerSelection `yaml:"peerSelection"`
 }
 
 // HashingConfiguration is the configuration for hashing.
@@ -339,6 +552,23 @@ type ProtoConfiguration struct {
 	// Enabled specifies whether proto is enabled.
 	Enabled        bool                            `yaml:"enabled"`
 	SchemaRegistry map[string]NamespaceProtoSchema `yaml:"schema_registry"`
+
+	// FieldPresenceDrift optionally enables monitoring of the fields present in
+	// written messages to detect producers using an unexpected schema.
+	FieldPresenceDrift *ProtoFieldPresenceDriftConfiguration `yaml:"fieldPresenceDrift"`
+}
+
+// ProtoFieldPresenceDriftConfiguration is the configuration for monitoring
+// drift in the fields present in written proto messages.
+type ProtoFieldPresenceDriftConfiguration struct {
+	// WindowSize is the number of messages per message type that make up
+	// each window of field presence that is compared, if zero a default is used.
+	WindowSize int `yaml:"windowSize" validate:"min=0"`
+
+	// Tolerance is the largest change in the fraction of messages a field is
+	// present in between windows before drift is reported, if zero a default
+	// is used.
+	Tolerance float64 `yaml:"tolerance" validate:"min=0.0,max=1.0"`
 }
 
 type NamespaceProtoSchema struct {
@@ -516,6 +746,18 @@ func InitialClusterEndpoints(initialCluster []environment.SeedNode) ([]string, e
 	return endpoints, nil
 }
 
+// PeerSeedNodes returns the seed nodes other than the given hostID.
+func PeerSeedNodes(initialCluster []environment.SeedNode, hostID string) []environment.SeedNode {
+	peers := make([]environment.SeedNode, 0, len(initialCluster))
+	for _, seedNode := range initialCluster {
+		if seedNode.HostID != hostID {
+			peers = append(peers, seedNode)
+		}
+	}
+
+	return peers
+}
+
 // IsSeedNode returns whether the given hostID is an etcd node.
 func IsSeedNode(initialCluster []environment.SeedNode, hostID string) bool {
 	for _, seedNode := range initialCluster {
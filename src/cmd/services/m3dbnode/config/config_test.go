@@ -23,13 +23,16 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/environment"
+	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
 	"github.com/m3db/m3/src/dbnode/storage"
-	"github.com/m3db/m3/src/dbnode/storage/bootstrap/bootstrapper/commitlog"
+	bcl "github.com/m3db/m3/src/dbnode/storage/bootstrap/bootstrapper/commitlog"
 	"github.com/m3db/m3/src/dbnode/topology"
 	xconfig "github.com/m3db/m3/src/x/config"
 	"github.com/m3db/m3/src/x/instrument"
@@ -342,6 +345,7 @@ func TestConfiguration(t *testing.T) {
   transforms:
     truncateBy: 0
     forceValue: null
+    roundToMultiple: 0
   logging:
     file: /var/log/m3dbnode.log
     level: info
@@ -359,11 +363,14 @@ func TestConfiguration(t *testing.T) {
     samplingRate: 1
     extended: 3
     sanitization: 2
+    debugMetrics: false
   listenAddress: 0.0.0.0:9000
   clusterListenAddress: 0.0.0.0:9001
   httpNodeListenAddress: 0.0.0.0:9002
   httpClusterListenAddress: 0.0.0.0:9003
   debugListenAddress: 0.0.0.0:9004
+  debugProfile: null
+  debugForceSnapshotEnabled: false
   hostID:
     resolver: config
     value: host1
@@ -405,11 +412,19 @@ func TestConfiguration(t *testing.T) {
     - filesystem
     - peers
     - noop-all
+    namespaceBootstrappers: {}
     fs:
       numProcessorsPerCPU: 0.42
     commitlog:
       returnUnfulfilledForCorruptCommitLogFiles: false
+      readConcurrency: 0
+      encodingConcurrency: 0
     cacheSeriesMetadata: null
+    verify: null
+    verifyBlocksOnLoad: null
+    mergeDuplicateSeriesBootstrap: null
+    partialFailure: null
+    namespaceConcurrency: 0
   blockRetrieve: null
   cache:
     series: null
@@ -430,6 +445,7 @@ func TestConfiguration(t *testing.T) {
     mmap: null
     force_index_summaries_mmap_memory: true
     force_bloom_filter_mmap_memory: true
+    fsyncBatch: null
   commitlog:
     flushMaxBytes: 524288
     flushEvery: 1s
@@ -437,6 +453,11 @@ func TestConfiguration(t *testing.T) {
       calculationType: fixed
       size: 2097152
     queueChannel: null
+    durableAckNamespaces: []
+    rotateMaxBytes: 0
+    rotateEvery: 0s
+    backpressureHighWatermark: 0
+    strategy: ""
     blockSize: null
   repair:
     enabled: false
@@ -447,6 +468,8 @@ func TestConfiguration(t *testing.T) {
     checkInterval: 1m0s
     debugShadowComparisonsEnabled: false
     debugShadowComparisonsPercentage: 0
+    dryRun: false
+    peerSelection: null
   pooling:
     blockAllocSize: 16
     type: simple
@@ -564,6 +587,10 @@ func TestConfiguration(t *testing.T) {
       size: 8192
       lowWatermark: 0.01
       highWatermark: 0.02
+    indexAggregateResultsPool:
+      size: 8192
+      lowWatermark: 0.01
+      highWatermark: 0.02
     tagEncoderPool:
       size: 8192
       lowWatermark: 0.01
@@ -588,6 +615,8 @@ func TestConfiguration(t *testing.T) {
       size: 8
       lowWatermark: 0
       highWatermark: 0
+    recycleEvictedEncoders: null
+    bytesPoolSampling: null
   config:
     service:
       zone: embedded
@@ -642,9 +671,11 @@ func TestConfiguration(t *testing.T) {
         trustedCaFile: ""
         clientCertAuth: false
         autoTls: false
+      continueOnEmbeddedKVFailure: false
   hashing:
     seed: 42
   writeNewSeriesAsync: true
+  dropDisabledColdWrites: false
   proto: null
   tracing:
     serviceName: ""
@@ -659,9 +690,25 @@ func TestConfiguration(t *testing.T) {
       headers: null
       baggage_restrictions: null
       throttler: null
+  tracingNamespaceSampleRates: {}
   limits:
     maxOutstandingWriteRequests: 0
     maxOutstandingReadRequests: 0
+    maxReadBlockReaders: 0
+    maxConcurrentSnapshots: 0
+    writeTimeout: 0s
+    maxPendingNewSeriesInsertsPerShard: 0
+  readProxyWhileBootstrappingNamespaces: []
+  bootstrappedReadinessGracePeriod: 0s
+  adminAuditLog:
+    enabled: false
+    metricsEnabled: false
+  strictStartup: false
+  maxNamespaceRetentionPeriod: 0s
+  consistencyCheckSampleRate: 0
+  maxEncodersPerBufferBucket: 0
+  seriesIdleEvictionTimeout: 0s
+  gracefulShutdownTimeout: 0s
 coordinator: null
 `
 
@@ -926,7 +973,7 @@ func TestBootstrapCommitLogConfig(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	notDefault := !commitlog.DefaultReturnUnfulfilledForCorruptCommitLogFiles
+	notDefault := !bcl.DefaultReturnUnfulfilledForCorruptCommitLogFiles
 	notDefaultStr := fmt.Sprintf("%v", notDefault)
 
 	testConf := `
@@ -977,7 +1024,7 @@ db:
 	validator.EXPECT().ValidateUninitializedBootstrapperOptions(gomock.Any()).Return(nil)
 	validator.EXPECT().
 		ValidateCommitLogBootstrapperOptions(gomock.Any()).
-		DoAndReturn(func(opts commitlog.Options) error {
+		DoAndReturn(func(opts bcl.Options) error {
 			actual := opts.ReturnUnfulfilledForCorruptCommitLogFiles()
 			expected := notDefault
 			require.Equal(t, expected, actual)
@@ -989,6 +1036,90 @@ db:
 	adminClient := client.NewMockAdminClient(ctrl)
 
 	_, err = cfg.DB.Bootstrap.New(validator,
-		storage.DefaultTestOptions(), mapProvider, origin, adminClient)
+		storage.DefaultTestOptions(), mapProvider, origin, adminClient, nil)
 	require.NoError(t, err)
 }
+
+func TestDebugProfileConfig(t *testing.T) {
+	var cfg *DebugProfileConfiguration
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, 5*time.Second, cfg.CPUProfileDurationOrDefault())
+
+	cfg = &DebugProfileConfiguration{}
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, 5*time.Second, cfg.CPUProfileDurationOrDefault())
+
+	cfg.CPUProfileDuration = 30 * time.Second
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, 30*time.Second, cfg.CPUProfileDurationOrDefault())
+
+	cfg.CPUProfileDuration = 500 * time.Millisecond
+	require.Error(t, cfg.Validate())
+
+	cfg.CPUProfileDuration = 2 * time.Minute
+	require.Error(t, cfg.Validate())
+}
+
+func TestCommitLogStrategyConfig(t *testing.T) {
+	var cfg CommitLogPolicy
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, commitlog.StrategyWriteBehind, cfg.StrategyOrDefault())
+
+	cfg.Strategy = "write_wait"
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, commitlog.StrategyWriteWait, cfg.StrategyOrDefault())
+
+	cfg.Strategy = "write_behind"
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, commitlog.StrategyWriteBehind, cfg.StrategyOrDefault())
+
+	cfg.Strategy = "write_sometimes"
+	require.Error(t, cfg.Validate())
+}
+
+func TestTransformConfig(t *testing.T) {
+	var cfg *TransformConfiguration
+	require.NoError(t, cfg.Validate())
+
+	cfg = &TransformConfiguration{}
+	require.NoError(t, cfg.Validate())
+
+	cfg.RoundToMultiple = 0.5
+	require.NoError(t, cfg.Validate())
+
+	cfg.RoundToMultiple = -0.5
+	require.Error(t, cfg.Validate())
+
+	cfg.RoundToMultiple = math.Inf(1)
+	require.Error(t, cfg.Validate())
+
+	cfg.RoundToMultiple = math.NaN()
+	require.Error(t, cfg.Validate())
+}
+
+func TestPeerSeedNodes(t *testing.T) {
+	seedNodes := []environment.SeedNode{
+		environment.SeedNode{
+			HostID:   "host1",
+			Endpoint: "http://1.1.1.1:2380",
+		},
+		environment.SeedNode{
+			HostID:   "host2",
+			Endpoint: "http://1.1.1.2:2380",
+		},
+		environment.SeedNode{
+			HostID:   "host3",
+			Endpoint: "http://1.1.1.3:2380",
+		},
+	}
+	res := PeerSeedNodes(seedNodes, "host2")
+	require.Equal(t, 2, len(res))
+	assert.Equal(t, "host1", res[0].HostID)
+	assert.Equal(t, "host3", res[1].HostID)
+
+	res = PeerSeedNodes(seedNodes, "host4")
+	assert.Equal(t, seedNodes, res)
+
+	res = PeerSeedNodes(seedNodes[:1], "host1")
+	assert.Equal(t, 0, len(res))
+}
@@ -23,6 +23,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 )
 
 const (
@@ -93,6 +94,21 @@ type FilesystemConfiguration struct {
 	// ForceBloomFilterMmapMemory forces the mmap that stores the index lookup bytes
 	// to be an anonymous region in memory as opposed to a file-based mmap.
 	ForceBloomFilterMmapMemory *bool `yaml:"force_bloom_filter_mmap_memory"`
+
+	// FsyncBatch batches the fsyncs of flushed files, by default no fsyncs are
+	// issued and syncing written files is left to the operating system, so
+	// enabling this adds fsyncs to flushes rather than batching existing ones.
+	FsyncBatch *FsyncBatchConfiguration `yaml:"fsyncBatch"`
+}
+
+// FsyncBatchConfiguration is the fsync batching configuration.
+type FsyncBatchConfiguration struct {
+	// Size is the number of files to batch before syncing them.
+	Size int `yaml:"size" validate:"min=1"`
+
+	// Interval is the max time between syncing batched files, zero only
+	// syncs full batches and at the end of each flush.
+	Interval time.Duration `yaml:"interval"`
 }
 
 // Validate validates the Filesystem configuration. We use this method to validate
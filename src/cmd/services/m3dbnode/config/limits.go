@@ -20,6 +20,8 @@
 
 package config
 
+import "time"
+
 // Limits contains configuration for configurable limits that can be applied to M3DB.
 type Limits struct {
 	// MaxOutstandingWriteRequests controls the maximum number of outstanding write requests
@@ -32,4 +34,23 @@ type Limits struct {
 	// the server will allow before it begins rejecting requests. Just like MaxOutstandingWriteRequests
 	// this value is independent of the number of time series being read.
 	MaxOutstandingReadRequests int `yaml:"maxOutstandingReadRequests" validate:"min=0"`
+	// MaxReadBlockReaders controls the maximum number of block readers a single read of
+	// a series may assemble before the read is rejected as too large, zero disables the
+	// limit. This value can be overridden at runtime via KV.
+	MaxReadBlockReaders int `yaml:"maxReadBlockReaders" validate:"min=0"`
+	// MaxConcurrentSnapshots controls the maximum number of shards snapshotted
+	// concurrently, zero uses the default of snapshotting shards one at a time.
+	MaxConcurrentSnapshots int `yaml:"maxConcurrentSnapshots" validate:"min=0"`
+	// WriteTimeout controls the maximum amount of time a write request may wait to be
+	// enqueued to the commit log before failing with a retryable error, zero disables
+	// the timeout. It does not bound the time spent writing to the series, such as
+	// waiting on a series lock, before the enqueue, so a write that times out has already
+	// been applied and may be read although it is not durable. This value can be
+	// overridden at runtime via KV.
+	WriteTimeout time.Duration `yaml:"writeTimeout" validate:"min=0"`
+	// MaxPendingNewSeriesInsertsPerShard controls the maximum number of new series inserts
+	// that may be pending in a shard's async insert queue before new series writes are
+	// rejected with a retryable error, zero disables the limit. This value can be
+	// overridden at runtime via KV.
+	MaxPendingNewSeriesInsertsPerShard int `yaml:"maxPendingNewSeriesInsertsPerShard" validate:"min=0"`
 }
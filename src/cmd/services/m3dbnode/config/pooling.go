@@ -20,7 +20,10 @@
 
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // PoolingType is a type of pooling, using runtime or mmap'd bytes pooling.
 type PoolingType string
@@ -29,12 +32,20 @@ const (
 	// SimplePooling uses the basic Go runtime to allocate bytes for bytes pools.
 	SimplePooling PoolingType = "simple"
 
+	// ExternalPooling uses the bytes pool factory registered with the
+	// storage options to allocate bytes for bytes pools.
+	ExternalPooling PoolingType = "external"
+
 	defaultPoolingType = SimplePooling
 )
 
 const (
-	defaultMaxFinalizerCapacity = 4
-	defaultBlockAllocSize       = 16
+	defaultMaxFinalizerCapacity   = 4
+	defaultBlockAllocSize         = 16
+	defaultRecycleEvictedEncoders = false
+
+	defaultBytesPoolSamplingSampleRate     = 0.01
+	defaultBytesPoolSamplingReportInterval = 10 * time.Minute
 )
 
 type poolPolicyDefault struct {
@@ -63,10 +74,11 @@ var (
 	}
 
 	defaultPoolPolicies = map[string]poolPolicyDefault{
-		"tagsIterator": defaultPoolPolicy,
-		"indexResults": defaultPoolPolicy,
-		"tagEncoder":   defaultPoolPolicy,
-		"tagDecoder":   defaultPoolPolicy,
+		"tagsIterator":          defaultPoolPolicy,
+		"indexResults":          defaultPoolPolicy,
+		"indexAggregateResults": defaultPoolPolicy,
+		"tagEncoder":            defaultPoolPolicy,
+		"tagDecoder":            defaultPoolPolicy,
 		"context": poolPolicyDefault{
 			size:                262144,
 			refillLowWaterMark:  defaultRefillLowWaterMark,
@@ -312,6 +324,10 @@ type PoolingPolicy struct {
 	// The policy for the index.ResultsPool.
 	IndexResultsPool PoolPolicy `yaml:"indexResultsPool"`
 
+	// The policy for the index.AggregateResultsPool, any values not set
+	// are inherited from the IndexResultsPool policy.
+	IndexAggregateResultsPool PoolPolicy `yaml:"indexAggregateResultsPool"`
+
 	// The policy for the TagEncoderPool.
 	TagEncoderPool PoolPolicy `yaml:"tagEncoderPool"`
 
@@ -329,6 +345,15 @@ type PoolingPolicy struct {
 
 	// The policy for the PostingsListPool.
 	PostingsListPool PoolPolicy `yaml:"postingsListPool"`
+
+	// Whether encoders of buffer buckets evicted after being flushed are
+	// reset and returned to the encoder pool rather than left to be garbage
+	// collected.
+	RecycleEvictedEncoders *bool `yaml:"recycleEvictedEncoders"`
+
+	// The sampling of the capacities requested from the Bytes pool used to
+	// recommend buckets, the Bytes pool is not sampled if not set.
+	BytesPoolSampling *BytesPoolSamplingPolicy `yaml:"bytesPoolSampling"`
 }
 
 // InitDefaultsAndValidate initializes all default values and validates the configuration
@@ -387,6 +412,10 @@ func (p *PoolingPolicy) InitDefaultsAndValidate() error {
 	if err := p.IndexResultsPool.initDefaultsAndValidate("indexResults"); err != nil {
 		return err
 	}
+	p.IndexAggregateResultsPool.inheritUnset(p.IndexResultsPool)
+	if err := p.IndexAggregateResultsPool.initDefaultsAndValidate("indexAggregateResults"); err != nil {
+		return err
+	}
 	if err := p.TagEncoderPool.initDefaultsAndValidate("tagEncoder"); err != nil {
 		return err
 	}
@@ -405,6 +434,11 @@ func (p *PoolingPolicy) InitDefaultsAndValidate() error {
 	if err := p.BufferBucketVersionsPool.initDefaultsAndValidate("bufferBucketVersions"); err != nil {
 		return err
 	}
+	if p.BytesPoolSampling != nil {
+		if err := p.BytesPoolSampling.validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -428,6 +462,17 @@ func (p *PoolingPolicy) TypeOrDefault() PoolingType {
 	return defaultPoolingType
 }
 
+// RecycleEvictedEncodersOrDefault returns whether encoders of evicted buffer
+// buckets are returned to the encoder pool if provided, or a default value
+// otherwise.
+func (p *PoolingPolicy) RecycleEvictedEncodersOrDefault() bool {
+	if p.RecycleEvictedEncoders != nil {
+		return *p.RecycleEvictedEncoders
+	}
+
+	return defaultRecycleEvictedEncoders
+}
+
 // PoolPolicy specifies a single pool policy.
 type PoolPolicy struct {
 	// The size of the pool.
@@ -469,6 +514,19 @@ func (p *PoolPolicy) initDefaultsAndValidate(poolName string) error {
 	return nil
 }
 
+// inheritUnset sets any unset values from the given policy.
+func (p *PoolPolicy) inheritUnset(from PoolPolicy) {
+	if p.Size == nil {
+		p.Size = from.Size
+	}
+	if p.RefillLowWaterMark == nil {
+		p.RefillLowWaterMark = from.RefillLowWaterMark
+	}
+	if p.RefillHighWaterMark == nil {
+		p.RefillHighWaterMark = from.RefillHighWaterMark
+	}
+}
+
 // SizeOrDefault returns the configured size if present, or a default value otherwise.
 func (p *PoolPolicy) SizeOrDefault() int {
 	return *p.Size
@@ -634,3 +692,46 @@ func (p ContextPoolPolicy) MaxFinalizerCapacityOrDefault() int {
 func intPtr(x int) *int {
 	return &x
 }
+
+// BytesPoolSamplingPolicy specifies how the capacities requested from the
+// Bytes pool are sampled, sampling does not change how the pool allocates and
+// only periodically reports the buckets recommended from the samples.
+type BytesPoolSamplingPolicy struct {
+	// The fraction of requests to sample, in the range (0, 1].
+	SampleRate *float64 `yaml:"sampleRate"`
+
+	// How often to log the recommended buckets.
+	ReportInterval *time.Duration `yaml:"reportInterval"`
+}
+
+func (p *BytesPoolSamplingPolicy) validate() error {
+	if v := p.SampleRateOrDefault(); v <= 0 || v > 1 {
+		return fmt.Errorf(
+			"bytes pool sampling sample rate must be in the range (0, 1], got: %f", v)
+	}
+	if v := p.ReportIntervalOrDefault(); v <= 0 {
+		return fmt.Errorf(
+			"bytes pool sampling report interval must be positive, got: %v", v)
+	}
+	return nil
+}
+
+// SampleRateOrDefault returns the configured sample rate if provided, or a
+// default value otherwise.
+func (p *BytesPoolSamplingPolicy) SampleRateOrDefault() float64 {
+	if p.SampleRate != nil {
+		return *p.SampleRate
+	}
+
+	return defaultBytesPoolSamplingSampleRate
+}
+
+// ReportIntervalOrDefault returns the configured report interval if provided,
+// or a default value otherwise.
+func (p *BytesPoolSamplingPolicy) ReportIntervalOrDefault() time.Duration {
+	if p.ReportInterval != nil {
+		return *p.ReportInterval
+	}
+
+	return defaultBytesPoolSamplingReportInterval
+}
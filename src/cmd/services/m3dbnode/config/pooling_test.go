@@ -21,6 +21,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -51,3 +52,56 @@ func TestContextPoolMaxFinalizerCapacityOrDefault(t *testing.T) {
 	cpp.MaxFinalizerCapacity = 10
 	require.Equal(t, 10, cpp.MaxFinalizerCapacityOrDefault())
 }
+
+func TestPoolingPolicyTypeOrDefault(t *testing.T) {
+	var policy PoolingPolicy
+	require.Equal(t, SimplePooling, policy.TypeOrDefault())
+
+	external := ExternalPooling
+	policy.Type = &external
+	require.Equal(t, ExternalPooling, policy.TypeOrDefault())
+}
+
+func TestPoolingPolicyIndexAggregateResultsPoolInheritsUnset(t *testing.T) {
+	size := 10
+	refillLowWaterMark := 0.5
+	var policy PoolingPolicy
+	policy.IndexResultsPool = PoolPolicy{
+		Size:               &size,
+		RefillLowWaterMark: &refillLowWaterMark,
+	}
+
+	aggregateSize := 20
+	policy.IndexAggregateResultsPool.Size = &aggregateSize
+
+	require.NoError(t, policy.InitDefaultsAndValidate())
+	require.Equal(t, aggregateSize, policy.IndexAggregateResultsPool.SizeOrDefault())
+	require.Equal(t, refillLowWaterMark,
+		policy.IndexAggregateResultsPool.RefillLowWaterMarkOrDefault())
+	require.Equal(t, 0.0, policy.IndexAggregateResultsPool.RefillHighWaterMarkOrDefault())
+	require.Equal(t, size, policy.IndexResultsPool.SizeOrDefault())
+}
+
+func TestPoolingPolicyBytesPoolSampling(t *testing.T) {
+	var policy PoolingPolicy
+	policy.BytesPoolSampling = &BytesPoolSamplingPolicy{}
+	require.NoError(t, policy.InitDefaultsAndValidate())
+	require.Equal(t, defaultBytesPoolSamplingSampleRate,
+		policy.BytesPoolSampling.SampleRateOrDefault())
+	require.Equal(t, defaultBytesPoolSamplingReportInterval,
+		policy.BytesPoolSampling.ReportIntervalOrDefault())
+
+	sampleRate := 1.5
+	policy.BytesPoolSampling.SampleRate = &sampleRate
+	require.Error(t, policy.InitDefaultsAndValidate())
+
+	sampleRate = 0.5
+	reportInterval := time.Duration(0)
+	policy.BytesPoolSampling.ReportInterval = &reportInterval
+	require.Error(t, policy.InitDefaultsAndValidate())
+
+	reportInterval = time.Minute
+	require.NoError(t, policy.InitDefaultsAndValidate())
+	require.Equal(t, sampleRate, policy.BytesPoolSampling.SampleRateOrDefault())
+	require.Equal(t, reportInterval, policy.BytesPoolSampling.ReportIntervalOrDefault())
+}
@@ -39,7 +39,11 @@ import (
 )
 
 var (
-	configFile = flag.String("f", "", "configuration file")
+	configFile         = flag.String("f", "", "configuration file")
+	validateConfigOnly = flag.Bool("validate-config-only", false,
+		"validate the configuration file and exit without starting the node")
+	watchConfig = flag.Bool("watch-config", false,
+		"watch the configuration file and apply runtime safe changes without a restart")
 )
 
 func main() {
@@ -64,6 +68,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *validateConfigOnly {
+		if cfg.DB != nil {
+			dbserver.Run(dbserver.RunOptions{
+				Config:       *cfg.DB,
+				ValidateOnly: true,
+			})
+		}
+		fmt.Fprintf(os.Stdout, "configuration %s is valid\n", *configFile)
+		return
+	}
+
 	var (
 		numComponents     int
 		dbClientCh        chan client.Client
@@ -98,12 +113,17 @@ func main() {
 	}
 
 	if cfg.DB != nil {
-		dbserver.Run(dbserver.RunOptions{
+		dbRunOpts := dbserver.RunOptions{
 			Config:          *cfg.DB,
 			ClientCh:        dbClientCh,
 			ClusterClientCh: clusterClientCh,
 			InterruptCh:     interruptCh,
-		})
+		}
+		if *watchConfig {
+			dbRunOpts.ConfigFile = *configFile
+			dbRunOpts.WatchConfigFile = true
+		}
+		dbserver.Run(dbRunOpts)
 	} else if cfg.Coordinator != nil {
 		<-coordinatorDoneCh
 	}
@@ -158,6 +158,7 @@ func TestConfig(t *testing.T) {
 	// Run server
 	var (
 		interruptCh = make(chan error, 1)
+		readinessCh = make(chan struct{}, 1)
 		bootstrapCh = make(chan struct{}, 1)
 		serverWg    sync.WaitGroup
 	)
@@ -165,6 +166,7 @@ func TestConfig(t *testing.T) {
 	go func() {
 		server.Run(server.RunOptions{
 			ConfigFile:  configFd.Name(),
+			ReadinessCh: readinessCh,
 			BootstrapCh: bootstrapCh,
 			InterruptCh: interruptCh,
 		})
@@ -176,6 +178,9 @@ func TestConfig(t *testing.T) {
 		http.DefaultServeMux = http.NewServeMux()
 	}()
 
+	// Wait for readiness, which is always notified before bootstrap
+	<-readinessCh
+
 	// Wait for bootstrap
 	<-bootstrapCh
 
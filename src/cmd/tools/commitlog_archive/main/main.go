@@ -0,0 +1,189 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/tools"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
+	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
+	"github.com/m3db/m3/src/x/ident"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"go.uber.org/zap"
+)
+
+const (
+	modeExport = "export"
+	modeImport = "import"
+)
+
+var (
+	optMode            = flag.String("mode", modeExport, "Mode - either 'export' or 'import'")
+	optPathPrefix      = flag.String("path-prefix", "/var/lib/m3db", "Path prefix - must contain a folder called 'commitlogs'")
+	optArchive         = flag.String("archive", "", "Archive file path to export to or import from")
+	optNamespaces      = flag.String("namespaces", "", "Namespaces - comma separated list of namespaces to export, or expected on import")
+	optBlockSize       = flag.Duration("block-size", 2*time.Hour, "Namespace block size recorded in the archive on export")
+	optRetentionPeriod = flag.Duration("retention", 48*time.Hour, "Namespace retention period recorded in the archive on export")
+	optFlushSize       = flag.Int("flush-size", 524288, "Flush size of commit log")
+)
+
+func main() {
+	flag.Parse()
+	if *optPathPrefix == "" ||
+		*optArchive == "" ||
+		strings.TrimSpace(*optNamespaces) == "" ||
+		(*optMode != modeExport && *optMode != modeImport) {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	rawLogger, err := zap.NewDevelopment()
+	if err != nil {
+		log.Fatalf("unable to create logger: %+v", err)
+	}
+	logger := rawLogger.Sugar()
+
+	var namespaces []string
+	for _, ns := range strings.Split(*optNamespaces, ",") {
+		ns = strings.TrimSpace(ns)
+		if ns == "" {
+			logger.Fatalf("invalid namespace list: '%s'", *optNamespaces)
+		}
+		namespaces = append(namespaces, ns)
+	}
+
+	instrumentOpts := instrument.NewOptions().
+		SetLogger(rawLogger)
+	fsOpts := fs.NewOptions().
+		SetInstrumentOptions(instrumentOpts).
+		SetFilePathPrefix(*optPathPrefix)
+	opts := commitlog.NewOptions().
+		SetInstrumentOptions(instrumentOpts).
+		SetFilesystemOptions(fsOpts).
+		SetFlushSize(*optFlushSize).
+		SetBlockSize(*optBlockSize).
+		SetBytesPool(tools.NewCheckedBytesPool())
+
+	switch *optMode {
+	case modeExport:
+		export(logger, opts, namespaces)
+	case modeImport:
+		importArchive(logger, opts, namespaces)
+	}
+}
+
+func export(
+	logger *zap.SugaredLogger,
+	opts commitlog.Options,
+	namespaces []string,
+) {
+	archiveNamespaces := make([]commitlog.ArchiveNamespace, 0, len(namespaces))
+	for _, ns := range namespaces {
+		archiveNamespaces = append(archiveNamespaces, commitlog.ArchiveNamespace{
+			ID:              ns,
+			BlockSize:       *optBlockSize,
+			RetentionPeriod: *optRetentionPeriod,
+		})
+	}
+
+	f, err := os.Create(*optArchive)
+	if err != nil {
+		logger.Fatalf("unable to create archive: %v", err)
+	}
+
+	stats, corruptFiles, err := commitlog.ExportArchive(f, commitlog.IteratorOpts{
+		CommitLogOptions: opts,
+	}, archiveNamespaces)
+	for _, corruptFile := range corruptFiles {
+		logger.Warnf("skipped corrupt commit log file: %v", corruptFile)
+	}
+	if err != nil {
+		logger.Fatalf("unable to export archive: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		logger.Fatalf("unable to close archive: %v", err)
+	}
+
+	logger.With(
+		zap.String("archive", *optArchive),
+		zap.Int("series", stats.NumSeries),
+		zap.Int("datapoints", stats.NumDatapoints),
+	).Info("exported commit log archive")
+}
+
+func importArchive(
+	logger *zap.SugaredLogger,
+	opts commitlog.Options,
+	namespaces []string,
+) {
+	f, err := os.Open(*optArchive)
+	if err != nil {
+		logger.Fatalf("unable to open archive: %v", err)
+	}
+	defer f.Close()
+
+	reader, err := commitlog.NewArchiveReader(f, opts)
+	if err != nil {
+		logger.Fatalf("unable to read archive: %v", err)
+	}
+	defer reader.Close()
+
+	metadata := reader.Metadata()
+	logger.With(
+		zap.Time("createdAt", metadata.CreatedAt),
+		zap.Any("namespaces", metadata.Namespaces),
+	).Info("read commit log archive metadata")
+
+	// NB: The archive is written as a new commit log file in the node's
+	// commit log directory so the node must not be running, the writes are
+	// loaded by the commit log bootstrapper the next time the node starts.
+	commitLog, err := commitlog.NewCommitLog(opts.SetStrategy(commitlog.StrategyWriteBehind))
+	if err != nil {
+		logger.Fatalf("unable to create commit log: %v", err)
+	}
+	if err := commitLog.Open(); err != nil {
+		logger.Fatalf("unable to open commit log: %v", err)
+	}
+
+	ids := make([]ident.ID, 0, len(namespaces))
+	for _, ns := range namespaces {
+		ids = append(ids, ident.StringID(ns))
+	}
+	stats, err := commitlog.ImportArchive(reader, commitLog, ids)
+	if err != nil {
+		logger.Fatalf("unable to import archive: %v", err)
+	}
+	if err := commitLog.Close(); err != nil {
+		logger.Fatalf("unable to close commit log: %v", err)
+	}
+
+	logger.With(
+		zap.String("archive", *optArchive),
+		zap.Int("series", stats.NumSeries),
+		zap.Int("datapoints", stats.NumDatapoints),
+	).Info("imported commit log archive")
+}
@@ -106,6 +106,9 @@ var (
 	errSessionInvalidConnectClusterConnectConsistencyLevel = errors.New("session has invalid connect consistency level specified")
 	// errSessionHasNoHostQueueForHost is raised when host queue requested for a missing host
 	errSessionHasNoHostQueueForHost = newHostNotAvailableError(errors.New("session has no host queue for host"))
+	// errSessionHasNoPeerForShards is raised when no peer other than the origin
+	// has any of the requested shards available
+	errSessionHasNoPeerForShards = newHostNotAvailableError(errors.New("session has no peer with shards available"))
 	// errUnableToEncodeTags is raised when the server is unable to encode provided tags
 	// to be sent over the wire.
 	errUnableToEncodeTags = errors.New("unable to include tags")
@@ -597,6 +600,39 @@ func (s *session) BorrowConnection(hostID string, fn withConnectionFn) error {
 	return err
 }
 
+func (s *session) BorrowPeerConnection(shards []uint32, fn func(c rpc.TChanNode)) error {
+	topoMap, err := s.TopologyMap()
+	if err != nil {
+		return err
+	}
+
+	var (
+		peerID        string
+		peerAvailable int
+	)
+	for _, hostShardSet := range topoMap.HostShardSets() {
+		hostID := hostShardSet.Host().ID()
+		if s.origin != nil && s.origin.ID() == hostID {
+			continue
+		}
+		available := 0
+		for _, id := range shards {
+			state, err := hostShardSet.ShardSet().LookupStateByID(id)
+			if err == nil && state == shard.Available {
+				available++
+			}
+		}
+		if available > peerAvailable {
+			peerID, peerAvailable = hostID, available
+		}
+	}
+	if peerAvailable == 0 {
+		return errSessionHasNoPeerForShards
+	}
+
+	return s.BorrowConnection(peerID, fn)
+}
+
 func (s *session) hostQueues(
 	topoMap topology.Map,
 	existing []hostQueue,
@@ -1859,6 +1895,38 @@ func (s *session) peersForShard(shard uint32) (peers, error) {
 	return result, nil
 }
 
+// withSelectedPeers returns the peers chosen by the peer selector with the
+// majority capped to a majority of the selected peers, peers that were not
+// selected are never streamed from and can never count towards the majority.
+func (p peers) withSelectedPeers(selectPeers PeerSelector) peers {
+	p.peers = selectedPeers(p.shard, p.peers, selectPeers)
+	if majority := len(p.peers)/2 + 1; majority < p.majorityReplicas {
+		p.majorityReplicas = majority
+	}
+	return p
+}
+
+// selectedPeers returns the peers chosen by the peer selector.
+func selectedPeers(shard uint32, peers []peer, selectPeers PeerSelector) []peer {
+	hosts := make([]topology.Host, 0, len(peers))
+	for _, p := range peers {
+		hosts = append(hosts, p.Host())
+	}
+
+	selected := make(map[string]struct{}, len(peers))
+	for _, host := range selectPeers(shard, hosts) {
+		selected[host.ID()] = struct{}{}
+	}
+
+	result := make([]peer, 0, len(selected))
+	for _, p := range peers {
+		if _, ok := selected[p.Host().ID()]; ok {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
 func (s *session) FetchBootstrapBlocksMetadataFromPeers(
 	namespace ident.ID,
 	shard uint32,
@@ -1867,7 +1935,7 @@ func (s *session) FetchBootstrapBlocksMetadataFromPeers(
 ) (PeerBlockMetadataIter, error) {
 	level := newSessionBootstrapRuntimeReadConsistencyLevel(s)
 	return s.fetchBlocksMetadataFromPeers(namespace,
-		shard, start, end, level, resultOpts)
+		shard, start, end, level, nil, resultOpts)
 }
 
 func (s *session) FetchBlocksMetadataFromPeers(
@@ -1879,7 +1947,20 @@ func (s *session) FetchBlocksMetadataFromPeers(
 ) (PeerBlockMetadataIter, error) {
 	level := newStaticRuntimeReadConsistencyLevel(consistencyLevel)
 	return s.fetchBlocksMetadataFromPeers(namespace,
-		shard, start, end, level, resultOpts)
+		shard, start, end, level, nil, resultOpts)
+}
+
+func (s *session) FetchBlocksMetadataFromSelectedPeers(
+	namespace ident.ID,
+	shard uint32,
+	start, end time.Time,
+	consistencyLevel topology.ReadConsistencyLevel,
+	selectPeers PeerSelector,
+	resultOpts result.Options,
+) (PeerBlockMetadataIter, error) {
+	level := newStaticRuntimeReadConsistencyLevel(consistencyLevel)
+	return s.fetchBlocksMetadataFromPeers(namespace,
+		shard, start, end, level, selectPeers, resultOpts)
 }
 
 func (s *session) fetchBlocksMetadataFromPeers(
@@ -1887,12 +1968,16 @@ func (s *session) fetchBlocksMetadataFromPeers(
 	shard uint32,
 	start, end time.Time,
 	level runtimeReadConsistencyLevel,
+	selectPeers PeerSelector,
 	resultOpts result.Options,
 ) (PeerBlockMetadataIter, error) {
 	peers, err := s.peersForShard(shard)
 	if err != nil {
 		return nil, err
 	}
+	if selectPeers != nil {
+		peers = peers.withSelectedPeers(selectPeers)
+	}
 
 	var (
 		metadataCh = make(chan receivedBlockMetadata,
@@ -816,6 +816,81 @@ func testBlocksToBlockReplicasMetadata(
 	return blockReplicas
 }
 
+func TestSelectedPeers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var peers []peer
+	for _, id := range []string{"a", "b", "c"} {
+		p := NewMockpeer(ctrl)
+		p.EXPECT().Host().Return(topology.NewHost(id, id+":9000")).AnyTimes()
+		peers = append(peers, p)
+	}
+
+	var selectedShard uint32
+	result := selectedPeers(7, peers, func(
+		shard uint32,
+		hosts []topology.Host,
+	) []topology.Host {
+		selectedShard = shard
+		require.Equal(t, 3, len(hosts))
+		return []topology.Host{hosts[2], hosts[0]}
+	})
+
+	require.Equal(t, uint32(7), selectedShard)
+	require.Equal(t, 2, len(result))
+	// The order of the peers of the shard is preserved.
+	assert.Equal(t, "a", result[0].Host().ID())
+	assert.Equal(t, "c", result[1].Host().ID())
+}
+
+func TestPeersWithSelectedPeersReducesMajorityRF5(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Five replicas with the local host excluded leaves four peers.
+	var hostPeers []peer
+	for _, id := range []string{"a", "b", "c", "d"} {
+		p := NewMockpeer(ctrl)
+		p.EXPECT().Host().Return(topology.NewHost(id, id+":9000")).AnyTimes()
+		hostPeers = append(hostPeers, p)
+	}
+	allPeers := peers{
+		peers:            hostPeers,
+		shard:            3,
+		majorityReplicas: 3,
+	}
+
+	tests := []struct {
+		name             string
+		numSelected      int
+		expectedMajority int
+	}{
+		{name: "single peer", numSelected: 1, expectedMajority: 1},
+		{name: "majority of peers", numSelected: 3, expectedMajority: 2},
+		{name: "all peers", numSelected: 4, expectedMajority: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := allPeers.withSelectedPeers(func(
+				shard uint32,
+				hosts []topology.Host,
+			) []topology.Host {
+				return hosts[:test.numSelected]
+			})
+			require.Equal(t, test.numSelected, len(result.peers))
+			require.Equal(t, test.expectedMajority, result.majorityReplicas)
+
+			// Every selected peer succeeding must achieve a majority read
+			// so that fetching metadata from the peers does not retry forever.
+			require.True(t, topology.ReadConsistencyAchieved(
+				topology.ReadConsistencyLevelMajority, result.majorityReplicas,
+				len(result.peers), len(result.peers)))
+		})
+	}
+}
+
 func TestSelectPeersFromPerPeerBlockMetadatasAllPeersSucceed(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
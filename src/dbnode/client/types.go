@@ -186,6 +186,11 @@ type AdminSession interface {
 	// Truncate will truncate the namespace for a given shard.
 	Truncate(namespace ident.ID) (int64, error)
 
+	// BorrowPeerConnection borrows a connection to the peer, other than the
+	// origin, that has the most of the given shards available and executes
+	// the function with it.
+	BorrowPeerConnection(shards []uint32, fn func(c rpc.TChanNode)) error
+
 	// FetchBootstrapBlocksFromPeers will fetch the most fulfilled block
 	// for each series using the runtime configurable bootstrap level consistency.
 	FetchBootstrapBlocksFromPeers(
@@ -214,6 +219,17 @@ type AdminSession interface {
 		result result.Options,
 	) (PeerBlockMetadataIter, error)
 
+	// FetchBlocksMetadataFromSelectedPeers will fetch the blocks metadata
+	// from the available peers chosen by the peer selector.
+	FetchBlocksMetadataFromSelectedPeers(
+		namespace ident.ID,
+		shard uint32,
+		start, end time.Time,
+		consistencyLevel topology.ReadConsistencyLevel,
+		selectPeers PeerSelector,
+		result result.Options,
+	) (PeerBlockMetadataIter, error)
+
 	// FetchBlocksFromPeers will fetch the required blocks from the
 	// peers specified.
 	FetchBlocksFromPeers(
@@ -225,6 +241,10 @@ type AdminSession interface {
 	) (PeerBlocksIter, error)
 }
 
+// PeerSelector selects the peers of a shard to fetch from, returning a
+// subset of the given peers.
+type PeerSelector func(shard uint32, peers []topology.Host) []topology.Host
+
 // Options is a set of client options.
 type Options interface {
 	// Validate validates the options.
@@ -0,0 +1,109 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encoding
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+// compactTimeUnits are the time units with a time encoding scheme ordered
+// from coarsest to finest.
+var compactTimeUnits = []xtime.Unit{
+	xtime.Second,
+	xtime.Millisecond,
+	xtime.Microsecond,
+	xtime.Nanosecond,
+}
+
+type compactDatapoint struct {
+	dp         ts.Datapoint
+	unit       xtime.Unit
+	annotation ts.Annotation
+}
+
+// EncodeCompacted encodes the datapoints of the iterator with the coarsest
+// time unit that exactly represents every timestamp, which takes fewer bits
+// per timestamp and avoids time unit changes in the stream. The datapoints
+// are buffered since the time unit is only known once all have been read,
+// if any datapoint has a time unit without a time encoding scheme the
+// datapoints are encoded with their original time units.
+func EncodeCompacted(iter Iterator, encoder Encoder) error {
+	var (
+		datapoints []compactDatapoint
+		unitIdx    int
+		compact    = true
+	)
+	for iter.Next() {
+		dp, unit, annotation := iter.Current()
+		if len(annotation) > 0 {
+			// Copy the annotation since it is invalidated on the next call
+			// to Next.
+			annotation = append(ts.Annotation(nil), annotation...)
+		}
+		datapoints = append(datapoints, compactDatapoint{
+			dp:         dp,
+			unit:       unit,
+			annotation: annotation,
+		})
+
+		if !isCompactTimeUnit(unit) {
+			compact = false
+		}
+		for unitIdx < len(compactTimeUnits)-1 &&
+			!isMultipleOfUnit(dp.Timestamp, compactTimeUnits[unitIdx]) {
+			unitIdx++
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	for _, curr := range datapoints {
+		unit := curr.unit
+		if compact {
+			unit = compactTimeUnits[unitIdx]
+		}
+		if err := encoder.Encode(curr.dp, unit, curr.annotation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isCompactTimeUnit(unit xtime.Unit) bool {
+	for _, compactUnit := range compactTimeUnits {
+		if unit == compactUnit {
+			return true
+		}
+	}
+	return false
+}
+
+func isMultipleOfUnit(t time.Time, unit xtime.Unit) bool {
+	d, err := unit.Value()
+	if err != nil {
+		return false
+	}
+	return t.UnixNano()%int64(d) == 0
+}
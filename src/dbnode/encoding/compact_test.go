@@ -0,0 +1,88 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encoding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testCompactDatapoint struct {
+	t    time.Time
+	unit xtime.Unit
+}
+
+func testEncodeCompacted(
+	t *testing.T,
+	datapoints []testCompactDatapoint,
+	expected xtime.Unit,
+) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	iter := NewMockIterator(ctrl)
+	encoder := NewMockEncoder(ctrl)
+	for i, curr := range datapoints {
+		dp := ts.Datapoint{Timestamp: curr.t, Value: float64(i)}
+		iter.EXPECT().Next().Return(true)
+		iter.EXPECT().Current().Return(dp, curr.unit, ts.Annotation(nil))
+
+		unit := expected
+		if unit == xtime.None {
+			unit = curr.unit
+		}
+		encoder.EXPECT().Encode(dp, unit, ts.Annotation(nil)).Return(nil)
+	}
+	iter.EXPECT().Next().Return(false)
+	iter.EXPECT().Err().Return(nil)
+
+	require.NoError(t, EncodeCompacted(iter, encoder))
+}
+
+func TestEncodeCompacted(t *testing.T) {
+	start := time.Unix(1500000000, 0)
+
+	// All timestamps are whole seconds.
+	testEncodeCompacted(t, []testCompactDatapoint{
+		{start, xtime.Nanosecond},
+		{start.Add(10 * time.Second), xtime.Millisecond},
+		{start.Add(20 * time.Second), xtime.Second},
+	}, xtime.Second)
+
+	// A single millisecond timestamp requires milliseconds throughout.
+	testEncodeCompacted(t, []testCompactDatapoint{
+		{start, xtime.Nanosecond},
+		{start.Add(10*time.Second + time.Millisecond), xtime.Nanosecond},
+		{start.Add(20 * time.Second), xtime.Nanosecond},
+	}, xtime.Millisecond)
+
+	// Time units without a time encoding scheme are left as is.
+	testEncodeCompacted(t, []testCompactDatapoint{
+		{start, xtime.Nanosecond},
+		{start.Add(time.Minute), xtime.Minute},
+	}, xtime.None)
+}
@@ -45,6 +45,7 @@ type options struct {
 	bytesPool            pool.CheckedBytesPool
 	segmentReaderPool    xio.SegmentReaderPool
 	byteFieldDictLRUSize int
+	fieldPresenceMonitor FieldPresenceMonitor
 }
 
 func newOptions() Options {
@@ -140,3 +141,13 @@ func (o *options) SetByteFieldDictionaryLRUSize(value int) Options {
 func (o *options) ByteFieldDictionaryLRUSize() int {
 	return o.byteFieldDictLRUSize
 }
+
+func (o *options) SetFieldPresenceMonitor(value FieldPresenceMonitor) Options {
+	opts := *o
+	opts.fieldPresenceMonitor = value
+	return &opts
+}
+
+func (o *options) FieldPresenceMonitor() FieldPresenceMonitor {
+	return o.fieldPresenceMonitor
+}
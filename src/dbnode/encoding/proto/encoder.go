@@ -71,6 +71,7 @@ type Encoder struct {
 	// avoid allocations.
 	varIntBuf              [8]byte
 	fieldsChangedToDefault []int32
+	presentFieldNums       []int32
 	marshalBuf             []byte
 
 	unmarshaller customFieldUnmarshaller
@@ -139,6 +140,10 @@ func (enc *Encoder) Encode(dp ts.Datapoint, timeUnit xtime.Unit, protoBytes ts.A
 			"%s error unmarshalling message: %v", encErrPrefix, err)
 	}
 
+	if monitor := enc.opts.FieldPresenceMonitor(); monitor != nil {
+		enc.observeFieldPresence(monitor)
+	}
+
 	if enc.numEncoded == 0 {
 		enc.encodeStreamHeader()
 	}
@@ -171,6 +176,19 @@ func (enc *Encoder) Encode(dp ts.Datapoint, timeUnit xtime.Unit, protoBytes ts.A
 	return nil
 }
 
+// observeFieldPresence reports the fields present in the most recently
+// unmarshalled message to the monitor.
+func (enc *Encoder) observeFieldPresence(monitor encoding.FieldPresenceMonitor) {
+	enc.presentFieldNums = enc.presentFieldNums[:0]
+	for _, v := range enc.unmarshaller.sortedCustomFieldValues() {
+		enc.presentFieldNums = append(enc.presentFieldNums, v.fieldNumber)
+	}
+	for _, v := range enc.unmarshaller.sortedNonCustomFieldValues() {
+		enc.presentFieldNums = append(enc.presentFieldNums, v.fieldNum)
+	}
+	monitor.Observe(enc.schema.GetFullyQualifiedName(), enc.presentFieldNums)
+}
+
 func (enc *Encoder) encodeSchemaAndOrTimeUnit(
 	needToEncodeSchema bool,
 	needToEncodeTimeUnit bool,
@@ -29,6 +29,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/ts"
 	xtime "github.com/m3db/m3/src/x/time"
 
+	"github.com/golang/mock/gomock"
 	dpb "github.com/golang/protobuf/protoc-gen-go/descriptor"
 	"github.com/jhump/protoreflect/desc"
 	"github.com/stretchr/testify/require"
@@ -127,3 +128,22 @@ func getCurrEncoderBytes(t *testing.T, enc *Encoder) []byte {
 	}
 	return currSeg.Head.Bytes()
 }
+
+func TestEncoderObservesFieldPresence(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	monitor := encoding.NewMockFieldPresenceMonitor(ctrl)
+	monitor.EXPECT().Observe(testVLSchema.GetFullyQualifiedName(), []int32{1, 2, 3, 4})
+
+	start := time.Now().Truncate(time.Second)
+	enc := NewEncoder(start, testEncodingOptions.SetFieldPresenceMonitor(monitor))
+	enc.Reset(start, 0, namespace.GetTestSchemaDescr(testVLSchema))
+
+	vl := newVL(1.0, 2.0, 3, []byte("some-delivery-id"), nil)
+	vlBytes, err := vl.Marshal()
+	require.NoError(t, err)
+
+	dp := ts.Datapoint{Timestamp: start.Add(time.Second)}
+	require.NoError(t, enc.Encode(dp, xtime.Second, vlBytes))
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package proto
+
+import (
+	"math"
+	"sync"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultFieldPresenceWindowSize = 10000
+	defaultFieldPresenceTolerance  = 0.1
+)
+
+// FieldPresenceMonitorOptions are the options for a field presence monitor.
+type FieldPresenceMonitorOptions struct {
+	// WindowSize is the number of messages of a message type observed before
+	// the fraction of messages each field was present in is compared against
+	// the previous window, if zero a default is used.
+	WindowSize int
+	// Tolerance is the largest change in the fraction of messages a field was
+	// present in between windows before drift is reported, if zero a default
+	// is used.
+	Tolerance float64
+	// InstrumentOptions are the instrument options.
+	InstrumentOptions instrument.Options
+}
+
+type fieldPresenceMonitor struct {
+	sync.Mutex
+
+	windowSize int
+	tolerance  float64
+	scope      tally.Scope
+	logger     *zap.Logger
+	messages   map[string]*fieldPresenceState
+}
+
+type fieldPresenceState struct {
+	counts   map[int32]int
+	observed int
+	baseline map[int32]float64
+
+	drift   tally.Gauge
+	drifted tally.Counter
+}
+
+// NewFieldPresenceMonitor returns a monitor that tracks the fraction of
+// messages each field is present in per message type and reports when it
+// changes by more than the tolerance between windows, which can indicate a
+// producer writing with an unexpected schema.
+func NewFieldPresenceMonitor(
+	opts FieldPresenceMonitorOptions,
+) encoding.FieldPresenceMonitor {
+	iopts := opts.InstrumentOptions
+	if iopts == nil {
+		iopts = instrument.NewOptions()
+	}
+	windowSize := opts.WindowSize
+	if windowSize <= 0 {
+		windowSize = defaultFieldPresenceWindowSize
+	}
+	tolerance := opts.Tolerance
+	if tolerance <= 0 {
+		tolerance = defaultFieldPresenceTolerance
+	}
+	return &fieldPresenceMonitor{
+		windowSize: windowSize,
+		tolerance:  tolerance,
+		scope:      iopts.MetricsScope(),
+		logger:     iopts.Logger(),
+		messages:   make(map[string]*fieldPresenceState),
+	}
+}
+
+func (m *fieldPresenceMonitor) Observe(messageName string, fieldNums []int32) {
+	m.Lock()
+	defer m.Unlock()
+
+	state, ok := m.messages[messageName]
+	if !ok {
+		scope := m.scope.Tagged(map[string]string{"message": messageName})
+		state = &fieldPresenceState{
+			counts:  make(map[int32]int),
+			drift:   scope.Gauge("field-presence-drift"),
+			drifted: scope.Counter("field-presence-drift-exceeded"),
+		}
+		m.messages[messageName] = state
+	}
+
+	for _, fieldNum := range fieldNums {
+		state.counts[fieldNum]++
+	}
+	state.observed++
+	if state.observed < m.windowSize {
+		return
+	}
+
+	presence := make(map[int32]float64, len(state.counts))
+	for fieldNum, count := range state.counts {
+		presence[fieldNum] = float64(count) / float64(state.observed)
+		delete(state.counts, fieldNum)
+	}
+	state.observed = 0
+
+	if state.baseline == nil {
+		state.baseline = presence
+		return
+	}
+
+	drift := fieldPresenceDrift(state.baseline, presence)
+	state.drift.Update(drift)
+	if drift <= m.tolerance {
+		state.baseline = presence
+		return
+	}
+
+	// Keep comparing against the last window within tolerance so that the
+	// drift continues to be reported until the producer is fixed.
+	state.drifted.Inc(1)
+	m.logger.Warn("proto field presence drifted beyond tolerance, "+
+		"a producer may be writing with an unexpected schema",
+		zap.String("message", messageName),
+		zap.Float64("drift", drift),
+		zap.Float64("tolerance", m.tolerance))
+}
+
+// fieldPresenceDrift returns the largest absolute change in the fraction of
+// messages a field was present in, fields missing from either side are
+// treated as never present.
+func fieldPresenceDrift(baseline, current map[int32]float64) float64 {
+	var drift float64
+	for fieldNum, before := range baseline {
+		if d := math.Abs(current[fieldNum] - before); d > drift {
+			drift = d
+		}
+	}
+	for fieldNum, after := range current {
+		if _, ok := baseline[fieldNum]; ok {
+			continue
+		}
+		if after > drift {
+			drift = after
+		}
+	}
+	return drift
+}
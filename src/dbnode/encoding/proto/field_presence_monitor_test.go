@@ -0,0 +1,87 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestFieldPresenceMonitorReportsDrift(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	monitor := NewFieldPresenceMonitor(FieldPresenceMonitorOptions{
+		WindowSize:        4,
+		Tolerance:         0.25,
+		InstrumentOptions: instrument.NewOptions().SetMetricsScope(scope),
+	})
+
+	observe := func(fieldNums ...int32) {
+		monitor.Observe("test.Message", fieldNums)
+	}
+	drifted := func() int64 {
+		counters := scope.Snapshot().Counters()
+		counter, ok := counters["field-presence-drift-exceeded+message=test.Message"]
+		if !ok {
+			return 0
+		}
+		return counter.Value()
+	}
+
+	// First window establishes the baseline.
+	for i := 0; i < 4; i++ {
+		observe(1, 2)
+	}
+	require.Equal(t, int64(0), drifted())
+
+	// Field 2 missing from one in four messages is within tolerance.
+	observe(1, 2)
+	observe(1, 2)
+	observe(1, 2)
+	observe(1)
+	require.Equal(t, int64(0), drifted())
+
+	// Every message switching to a different field is beyond tolerance.
+	for i := 0; i < 4; i++ {
+		observe(1, 3)
+	}
+	require.Equal(t, int64(1), drifted())
+
+	gauges := scope.Snapshot().Gauges()
+	gauge, ok := gauges["field-presence-drift+message=test.Message"]
+	require.True(t, ok)
+	require.Equal(t, 1.0, gauge.Value())
+}
+
+func TestFieldPresenceDrift(t *testing.T) {
+	require.Equal(t, 0.0, fieldPresenceDrift(
+		map[int32]float64{1: 1, 2: 0.5},
+		map[int32]float64{1: 1, 2: 0.5}))
+	require.Equal(t, 0.5, fieldPresenceDrift(
+		map[int32]float64{1: 1, 2: 0.5},
+		map[int32]float64{1: 1}))
+	require.Equal(t, 0.75, fieldPresenceDrift(
+		map[int32]float64{1: 1},
+		map[int32]float64{1: 1, 3: 0.75}))
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package tuple provides an encoding for a small fixed-width tuple of float
+// values written at a single timestamp. The first value of the tuple is
+// encoded as the datapoint value and the whole tuple is encoded as the
+// datapoint annotation so that existing readers continue to see the first
+// value while tuple aware readers can decode every value.
+package tuple
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+)
+
+const (
+	// MaxValues is the maximum number of values that can be encoded
+	// in a single tuple.
+	MaxValues = math.MaxUint8
+
+	headerLen = 1
+	valueLen  = 8
+)
+
+var (
+	errNoValues            = errors.New("tuple must contain at least one value")
+	errAnnotationTooShort  = errors.New("tuple annotation is too short")
+	errAnnotationWrongSize = errors.New("tuple annotation size does not match number of values")
+	errNoTuple             = errors.New("datapoint does not contain a tuple")
+)
+
+// Encode encodes the values into an annotation by appending them to the
+// provided buffer, which may be nil. Since encoders may retain a reference
+// to the last annotation written the buffer should not be reused for
+// subsequent writes to the same series.
+func Encode(values []float64, buf []byte) (ts.Annotation, error) {
+	if len(values) == 0 {
+		return nil, errNoValues
+	}
+	if len(values) > MaxValues {
+		return nil, fmt.Errorf("tuple has %d values, exceeds max of %d",
+			len(values), MaxValues)
+	}
+
+	var valueBuf [valueLen]byte
+	buf = append(buf, byte(len(values)))
+	for _, v := range values {
+		binary.BigEndian.PutUint64(valueBuf[:], math.Float64bits(v))
+		buf = append(buf, valueBuf[:]...)
+	}
+	return buf, nil
+}
+
+// EncodedLen returns the length of the annotation for a tuple with
+// the given number of values.
+func EncodedLen(numValues int) int {
+	return headerLen + numValues*valueLen
+}
+
+// Decode decodes the values encoded in an annotation and appends them to
+// the provided slice.
+func Decode(ant ts.Annotation, values []float64) ([]float64, error) {
+	if len(ant) < headerLen {
+		return values, errAnnotationTooShort
+	}
+
+	numValues := int(ant[0])
+	if len(ant) != EncodedLen(numValues) {
+		return values, errAnnotationWrongSize
+	}
+
+	for i := 0; i < numValues; i++ {
+		offset := headerLen + i*valueLen
+		bits := binary.BigEndian.Uint64(ant[offset : offset+valueLen])
+		values = append(values, math.Float64frombits(bits))
+	}
+	return values, nil
+}
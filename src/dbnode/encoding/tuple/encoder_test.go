@@ -0,0 +1,57 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tuple
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	values := []float64{1.5, -2, math.MaxFloat64, 0}
+	ant, err := Encode(values, nil)
+	require.NoError(t, err)
+	require.Equal(t, EncodedLen(len(values)), len(ant))
+
+	decoded, err := Decode(ant, nil)
+	require.NoError(t, err)
+	require.Equal(t, values, decoded)
+}
+
+func TestEncodeInvalidNumValues(t *testing.T) {
+	_, err := Encode(nil, nil)
+	require.Error(t, err)
+
+	_, err = Encode(make([]float64, MaxValues+1), nil)
+	require.Error(t, err)
+}
+
+func TestDecodeInvalidAnnotation(t *testing.T) {
+	_, err := Decode(nil, nil)
+	require.Error(t, err)
+
+	ant, err := Encode([]float64{1, 2}, nil)
+	require.NoError(t, err)
+	_, err = Decode(ant[:len(ant)-1], nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tuple
+
+import (
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/ts"
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+// Iterator decodes the tuples written to a series from an underlying
+// datapoint iterator.
+type Iterator struct {
+	iter   encoding.Iterator
+	values []float64
+	err    error
+}
+
+// NewIterator returns a new tuple iterator that reads from the
+// given datapoint iterator.
+func NewIterator(iter encoding.Iterator) *Iterator {
+	it := &Iterator{}
+	it.Reset(iter)
+	return it
+}
+
+// Reset resets the iterator to read from a new datapoint iterator so
+// that it can be reused.
+func (it *Iterator) Reset(iter encoding.Iterator) {
+	it.iter = iter
+	it.values = it.values[:0]
+	it.err = nil
+}
+
+// Next moves to the next tuple.
+func (it *Iterator) Next() bool {
+	if it.err != nil || !it.iter.Next() {
+		return false
+	}
+
+	_, _, ant := it.iter.Current()
+	if len(ant) == 0 {
+		// NB: Encoders only write an annotation when it differs from the
+		// previous annotation, so an empty annotation means the tuple is
+		// unchanged from the previous datapoint.
+		if len(it.values) == 0 {
+			it.err = errNoTuple
+			return false
+		}
+		return true
+	}
+
+	it.values, it.err = Decode(ant, it.values[:0])
+	return it.err == nil
+}
+
+// Current returns the current datapoint as well as the values of its tuple.
+// Users should not hold on to the returned values as they are invalidated
+// when the iterator calls Next().
+func (it *Iterator) Current() (ts.Datapoint, xtime.Unit, []float64) {
+	dp, unit, _ := it.iter.Current()
+	return dp, unit, it.values
+}
+
+// Err returns the error encountered.
+func (it *Iterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.iter.Err()
+}
+
+// Close closes the underlying datapoint iterator.
+func (it *Iterator) Close() {
+	if it.iter != nil {
+		it.iter.Close()
+		it.iter = nil
+	}
+}
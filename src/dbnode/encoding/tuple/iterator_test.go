@@ -0,0 +1,91 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tuple
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
+	"github.com/m3db/m3/src/dbnode/ts"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIteratorReadsTuples(t *testing.T) {
+	start := time.Now().Truncate(time.Hour)
+	input := [][]float64{
+		{1, 10},
+		{2, 20},
+		// Identical consecutive tuples are not re-encoded as an annotation.
+		{2, 20},
+		{3, 30},
+	}
+
+	encoder := m3tsz.NewEncoder(start, nil, m3tsz.DefaultIntOptimizationEnabled, nil)
+	for i, values := range input {
+		ant, err := Encode(values, nil)
+		require.NoError(t, err)
+		dp := ts.Datapoint{
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			Value:     values[0],
+		}
+		require.NoError(t, encoder.Encode(dp, xtime.Second, ant))
+	}
+
+	stream, ok := encoder.Stream(encoding.StreamOptions{})
+	require.True(t, ok)
+
+	iter := NewIterator(m3tsz.NewReaderIterator(stream,
+		m3tsz.DefaultIntOptimizationEnabled, encoding.NewOptions()))
+	defer iter.Close()
+
+	var i int
+	for iter.Next() {
+		dp, unit, values := iter.Current()
+		require.Equal(t, start.Add(time.Duration(i)*time.Second), dp.Timestamp)
+		require.Equal(t, input[i][0], dp.Value)
+		require.Equal(t, xtime.Second, unit)
+		require.Equal(t, input[i], values)
+		i++
+	}
+	require.NoError(t, iter.Err())
+	require.Equal(t, len(input), i)
+}
+
+func TestIteratorErrorsWithoutTuple(t *testing.T) {
+	start := time.Now().Truncate(time.Hour)
+	encoder := m3tsz.NewEncoder(start, nil, m3tsz.DefaultIntOptimizationEnabled, nil)
+	require.NoError(t, encoder.Encode(ts.Datapoint{Timestamp: start, Value: 1},
+		xtime.Second, nil))
+
+	stream, ok := encoder.Stream(encoding.StreamOptions{})
+	require.True(t, ok)
+
+	iter := NewIterator(m3tsz.NewReaderIterator(stream,
+		m3tsz.DefaultIntOptimizationEnabled, encoding.NewOptions()))
+	defer iter.Close()
+
+	require.False(t, iter.Next())
+	require.Error(t, iter.Err())
+}
@@ -144,6 +144,22 @@ type Options interface {
 
 	// ByteFieldDictionaryLRUSize returns the ByteFieldDictionaryLRUSize.
 	ByteFieldDictionaryLRUSize() int
+
+	// SetFieldPresenceMonitor sets the monitor that observes the fields present
+	// in ProtoBuf messages as they are encoded, nil disables monitoring.
+	SetFieldPresenceMonitor(value FieldPresenceMonitor) Options
+
+	// FieldPresenceMonitor returns the FieldPresenceMonitor.
+	FieldPresenceMonitor() FieldPresenceMonitor
+}
+
+// FieldPresenceMonitor observes which fields are present in the ProtoBuf
+// messages written to encoders so that unexpected changes, such as a producer
+// writing with the wrong schema, can be detected.
+type FieldPresenceMonitor interface {
+	// Observe records the field numbers present in a single message of the
+	// given message type, implementations must not retain the slice.
+	Observe(messageName string, fieldNums []int32)
 }
 
 // Iterator is the generic interface for iterating over encoded data.
@@ -64,6 +64,11 @@ type SeedNodesConfig struct {
 	InitialCluster           []SeedNode             `yaml:"initialCluster"`
 	ClientTransportSecurity  SeedNodeSecurityConfig `yaml:"clientTransportSecurity"`
 	PeerTransportSecurity    SeedNodeSecurityConfig `yaml:"peerTransportSecurity"`
+
+	// ContinueOnEmbeddedKVFailure when true lets a seed node that fails to
+	// start its embedded etcd server continue as a client of the other
+	// seed nodes rather than exiting.
+	ContinueOnEmbeddedKVFailure bool `yaml:"continueOnEmbeddedKVFailure"`
 }
 
 // SeedNode represents a seed node for the cluster
@@ -75,6 +75,9 @@ service Node {
 	NodeWriteNewSeriesBackoffDurationResult setWriteNewSeriesBackoffDuration(1: NodeSetWriteNewSeriesBackoffDurationRequest req) throws (1: Error err)
 	NodeWriteNewSeriesLimitPerShardPerSecondResult getWriteNewSeriesLimitPerShardPerSecond() throws (1: Error err)
 	NodeWriteNewSeriesLimitPerShardPerSecondResult setWriteNewSeriesLimitPerShardPerSecond(1: NodeSetWriteNewSeriesLimitPerShardPerSecondRequest req) throws (1: Error err)
+	void quiesce() throws (1: Error err)
+	void unquiesce() throws (1: Error err)
+	WarmPostingsListCacheResult warmPostingsListCache(1: WarmPostingsListCacheRequest req) throws (1: Error err)
 }
 
 struct FetchRequest {
@@ -441,3 +444,11 @@ struct Query {
   6: optional AllQuery         all
   7: optional FieldQuery       field
 }
+
+struct WarmPostingsListCacheRequest {
+	1: required list<FetchTaggedRequest> queries
+}
+
+struct WarmPostingsListCacheResult {
+	1: required i64 numQueries
+}
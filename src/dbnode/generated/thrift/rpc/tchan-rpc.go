@@ -60,12 +60,15 @@ type TChanNode interface {
 	GetWriteNewSeriesLimitPerShardPerSecond(ctx thrift.Context) (*NodeWriteNewSeriesLimitPerShardPerSecondResult_, error)
 	Health(ctx thrift.Context) (*NodeHealthResult_, error)
 	Query(ctx thrift.Context, req *QueryRequest) (*QueryResult_, error)
+	Quiesce(ctx thrift.Context) error
 	Repair(ctx thrift.Context) error
 	SetPersistRateLimit(ctx thrift.Context, req *NodeSetPersistRateLimitRequest) (*NodePersistRateLimitResult_, error)
 	SetWriteNewSeriesAsync(ctx thrift.Context, req *NodeSetWriteNewSeriesAsyncRequest) (*NodeWriteNewSeriesAsyncResult_, error)
 	SetWriteNewSeriesBackoffDuration(ctx thrift.Context, req *NodeSetWriteNewSeriesBackoffDurationRequest) (*NodeWriteNewSeriesBackoffDurationResult_, error)
 	SetWriteNewSeriesLimitPerShardPerSecond(ctx thrift.Context, req *NodeSetWriteNewSeriesLimitPerShardPerSecondRequest) (*NodeWriteNewSeriesLimitPerShardPerSecondResult_, error)
 	Truncate(ctx thrift.Context, req *TruncateRequest) (*TruncateResult_, error)
+	Unquiesce(ctx thrift.Context) error
+	WarmPostingsListCache(ctx thrift.Context, req *WarmPostingsListCacheRequest) (*WarmPostingsListCacheResult_, error)
 	Write(ctx thrift.Context, req *WriteRequest) error
 	WriteBatchRaw(ctx thrift.Context, req *WriteBatchRawRequest) error
 	WriteTagged(ctx thrift.Context, req *WriteTaggedRequest) error
@@ -732,6 +735,22 @@ func (c *tchanNodeClient) Query(ctx thrift.Context, req *QueryRequest) (*QueryRe
 	return resp.GetSuccess(), err
 }
 
+func (c *tchanNodeClient) Quiesce(ctx thrift.Context) error {
+	var resp NodeQuiesceResult
+	args := NodeQuiesceArgs{}
+	success, err := c.client.Call(ctx, c.thriftService, "quiesce", &args, &resp)
+	if err == nil && !success {
+		switch {
+		case resp.Err != nil:
+			err = resp.Err
+		default:
+			err = fmt.Errorf("received no result or unknown exception for quiesce")
+		}
+	}
+
+	return err
+}
+
 func (c *tchanNodeClient) Repair(ctx thrift.Context) error {
 	var resp NodeRepairResult
 	args := NodeRepairArgs{}
@@ -838,6 +857,40 @@ func (c *tchanNodeClient) Truncate(ctx thrift.Context, req *TruncateRequest) (*T
 	return resp.GetSuccess(), err
 }
 
+func (c *tchanNodeClient) Unquiesce(ctx thrift.Context) error {
+	var resp NodeUnquiesceResult
+	args := NodeUnquiesceArgs{}
+	success, err := c.client.Call(ctx, c.thriftService, "unquiesce", &args, &resp)
+	if err == nil && !success {
+		switch {
+		case resp.Err != nil:
+			err = resp.Err
+		default:
+			err = fmt.Errorf("received no result or unknown exception for unquiesce")
+		}
+	}
+
+	return err
+}
+
+func (c *tchanNodeClient) WarmPostingsListCache(ctx thrift.Context, req *WarmPostingsListCacheRequest) (*WarmPostingsListCacheResult_, error) {
+	var resp NodeWarmPostingsListCacheResult
+	args := NodeWarmPostingsListCacheArgs{
+		Req: req,
+	}
+	success, err := c.client.Call(ctx, c.thriftService, "warmPostingsListCache", &args, &resp)
+	if err == nil && !success {
+		switch {
+		case resp.Err != nil:
+			err = resp.Err
+		default:
+			err = fmt.Errorf("received no result or unknown exception for warmPostingsListCache")
+		}
+	}
+
+	return resp.GetSuccess(), err
+}
+
 func (c *tchanNodeClient) Write(ctx thrift.Context, req *WriteRequest) error {
 	var resp NodeWriteResult
 	args := NodeWriteArgs{
@@ -943,12 +996,15 @@ func (s *tchanNodeServer) Methods() []string {
 		"getWriteNewSeriesLimitPerShardPerSecond",
 		"health",
 		"query",
+		"quiesce",
 		"repair",
 		"setPersistRateLimit",
 		"setWriteNewSeriesAsync",
 		"setWriteNewSeriesBackoffDuration",
 		"setWriteNewSeriesLimitPerShardPerSecond",
 		"truncate",
+		"unquiesce",
+		"warmPostingsListCache",
 		"write",
 		"writeBatchRaw",
 		"writeTagged",
@@ -988,6 +1044,8 @@ func (s *tchanNodeServer) Handle(ctx thrift.Context, methodName string, protocol
 		return s.handleHealth(ctx, protocol)
 	case "query":
 		return s.handleQuery(ctx, protocol)
+	case "quiesce":
+		return s.handleQuiesce(ctx, protocol)
 	case "repair":
 		return s.handleRepair(ctx, protocol)
 	case "setPersistRateLimit":
@@ -1000,6 +1058,10 @@ func (s *tchanNodeServer) Handle(ctx thrift.Context, methodName string, protocol
 		return s.handleSetWriteNewSeriesLimitPerShardPerSecond(ctx, protocol)
 	case "truncate":
 		return s.handleTruncate(ctx, protocol)
+	case "unquiesce":
+		return s.handleUnquiesce(ctx, protocol)
+	case "warmPostingsListCache":
+		return s.handleWarmPostingsListCache(ctx, protocol)
 	case "write":
 		return s.handleWrite(ctx, protocol)
 	case "writeBatchRaw":
@@ -1434,6 +1496,33 @@ func (s *tchanNodeServer) handleQuery(ctx thrift.Context, protocol athrift.TProt
 	return err == nil, &res, nil
 }
 
+func (s *tchanNodeServer) handleQuiesce(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeQuiesceArgs
+	var res NodeQuiesceResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	err :=
+		s.handler.Quiesce(ctx)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+	}
+
+	return err == nil, &res, nil
+}
+
 func (s *tchanNodeServer) handleRepair(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req NodeRepairArgs
 	var res NodeRepairResult
@@ -1601,6 +1690,61 @@ func (s *tchanNodeServer) handleTruncate(ctx thrift.Context, protocol athrift.TP
 	return err == nil, &res, nil
 }
 
+func (s *tchanNodeServer) handleUnquiesce(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeUnquiesceArgs
+	var res NodeUnquiesceResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	err :=
+		s.handler.Unquiesce(ctx)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+	}
+
+	return err == nil, &res, nil
+}
+
+func (s *tchanNodeServer) handleWarmPostingsListCache(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeWarmPostingsListCacheArgs
+	var res NodeWarmPostingsListCacheResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	r, err :=
+		s.handler.WarmPostingsListCache(ctx, req.Req)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+		res.Success = r
+	}
+
+	return err == nil, &res, nil
+}
+
 func (s *tchanNodeServer) handleWrite(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req NodeWriteArgs
 	var res NodeWriteResult
@@ -33,6 +33,45 @@ const (
 	// configuration specifying a hard limit for a cluster new series insertions.
 	ClusterNewSeriesInsertLimitKey = "m3db.node.cluster-new-series-insert-limit"
 
+	// MaxReadBlockReadersKey is the KV config key for the runtime
+	// configuration specifying a hard limit for the number of block readers
+	// a single read of a series may assemble.
+	MaxReadBlockReadersKey = "m3db.node.max-read-block-readers"
+
+	// FetchConcurrencyKey is the KV config key for the runtime configuration
+	// specifying the number of concurrent fetches from disk each block
+	// retriever performs.
+	FetchConcurrencyKey = "m3db.node.fetch-concurrency"
+
+	// WriteTimeoutKey is the KV config key for the runtime configuration
+	// specifying the server side timeout for enqueueing a write to the
+	// commit log, specified as a duration string.
+	WriteTimeoutKey = "m3db.node.write-timeout"
+
+	// MaxPendingNewSeriesInsertsPerShardKey is the KV config key for the
+	// runtime configuration specifying a hard limit for the number of new
+	// series inserts pending in a shard's async insert queue.
+	MaxPendingNewSeriesInsertsPerShardKey = "m3db.node.max-pending-new-series-inserts-per-shard"
+
+	// DebugLoggingNamespacesKey is the KV config key for the runtime
+	// configuration specifying the IDs of the namespaces that should log
+	// at debug level regardless of the process wide log level.
+	DebugLoggingNamespacesKey = "m3db.node.debug-logging-namespaces"
+
+	// GCPercentageKey is the KV config key for the runtime configuration
+	// overriding the garbage collection target percentage.
+	GCPercentageKey = "m3db.node.gc-percentage"
+
+	// TagEncoderPoolRefillHighWatermarkKey is the KV config key for the
+	// runtime configuration overriding the refill high watermark of the tag
+	// encoder pool.
+	TagEncoderPoolRefillHighWatermarkKey = "m3db.node.tag-encoder-pool-refill-high-watermark"
+
+	// TagDecoderPoolRefillHighWatermarkKey is the KV config key for the
+	// runtime configuration overriding the refill high watermark of the tag
+	// decoder pool.
+	TagDecoderPoolRefillHighWatermarkKey = "m3db.node.tag-decoder-pool-refill-high-watermark"
+
 	// ClientBootstrapConsistencyLevel is the KV config key for the runtime
 	// configuration specifying the client bootstrap consistency level
 	ClientBootstrapConsistencyLevel = "m3db.client.bootstrap-consistency-level"
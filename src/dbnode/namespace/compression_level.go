@@ -0,0 +1,84 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"fmt"
+)
+
+// CompressionLevel determines how hard blocks are compressed when they are
+// flushed to disk, the in-memory encoding is the same for all levels.
+type CompressionLevel uint8
+
+const (
+	// DefaultCompressionLevel persists blocks as they are encoded in memory.
+	DefaultCompressionLevel CompressionLevel = iota
+
+	// HighCompressionLevel re-encodes blocks on flush to reduce their size on
+	// disk at the cost of CPU, the persisted blocks are decoded the same as
+	// blocks persisted at the default level.
+	HighCompressionLevel
+)
+
+var validCompressionLevels = []CompressionLevel{
+	DefaultCompressionLevel,
+	HighCompressionLevel,
+}
+
+// Validate validates that the compression level is valid.
+func (l CompressionLevel) Validate() error {
+	if l >= DefaultCompressionLevel && l <= HighCompressionLevel {
+		return nil
+	}
+
+	return fmt.Errorf("invalid compression level: '%v' valid levels are: %v",
+		l, validCompressionLevels)
+}
+
+func (l CompressionLevel) String() string {
+	switch l {
+	case DefaultCompressionLevel:
+		return "default"
+	case HighCompressionLevel:
+		return "high"
+	default:
+		// Should never get here.
+		return "unknown"
+	}
+}
+
+// UnmarshalYAML unmarshals a stored compression level.
+func (l *CompressionLevel) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+
+	for _, valid := range validCompressionLevels {
+		if str == valid.String() {
+			*l = valid
+			return nil
+		}
+	}
+
+	*l = DefaultCompressionLevel
+	return nil
+}
@@ -48,15 +48,40 @@ func (m *MapConfiguration) Map() (Map, error) {
 
 // MetadataConfiguration is the configuration for a single namespace
 type MetadataConfiguration struct {
-	ID                string                  `yaml:"id" validate:"nonzero"`
-	BootstrapEnabled  *bool                   `yaml:"bootstrapEnabled"`
-	FlushEnabled      *bool                   `yaml:"flushEnabled"`
-	WritesToCommitLog *bool                   `yaml:"writesToCommitLog"`
-	CleanupEnabled    *bool                   `yaml:"cleanupEnabled"`
-	RepairEnabled     *bool                   `yaml:"repairEnabled"`
-	ColdWritesEnabled *bool                   `yaml:"coldWritesEnabled"`
-	Retention         retention.Configuration `yaml:"retention" validate:"nonzero"`
-	Index             IndexConfiguration      `yaml:"index"`
+	ID                string                   `yaml:"id" validate:"nonzero"`
+	BootstrapEnabled  *bool                    `yaml:"bootstrapEnabled"`
+	FlushEnabled      *bool                    `yaml:"flushEnabled"`
+	WritesToCommitLog *bool                    `yaml:"writesToCommitLog"`
+	CleanupEnabled    *bool                    `yaml:"cleanupEnabled"`
+	RepairEnabled     *bool                    `yaml:"repairEnabled"`
+	ColdWritesEnabled *bool                    `yaml:"coldWritesEnabled"`
+	Retention         retention.Configuration  `yaml:"retention" validate:"nonzero"`
+	Index             IndexConfiguration       `yaml:"index"`
+	Repair            RepairConfiguration      `yaml:"repair"`
+	ReadCache         ReadCacheConfiguration   `yaml:"readCache"`
+	ValueBounds       ValueBoundsConfiguration `yaml:"valueBounds"`
+
+	// WriteNewSeriesBackoffDuration overrides the database wide runtime
+	// new series insert backoff for the namespace when set.
+	WriteNewSeriesBackoffDuration time.Duration `yaml:"writeNewSeriesBackoffDuration"`
+
+	// MaxWriteFutureSkew rejects writes with timestamps further ahead of the
+	// node's clock than this tolerance when set, guarding against clients
+	// with badly skewed clocks.
+	MaxWriteFutureSkew time.Duration `yaml:"maxWriteFutureSkew" validate:"min=0"`
+
+	// CompressionLevel trades CPU on flush for smaller blocks on disk, it is
+	// best suited to cold namespaces that are rarely read.
+	CompressionLevel CompressionLevel `yaml:"compressionLevel"`
+
+	// SeriesCachePolicy overrides the database wide series cache policy for
+	// the namespace when set, e.g. to cache a hot namespace differently to
+	// a cold archival namespace.
+	SeriesCachePolicy string `yaml:"seriesCachePolicy"`
+
+	// ForcedValue overrides the database wide forced value for the namespace
+	// when set, all values written to the namespace are set to it.
+	ForcedValue *float64 `yaml:"forceValue"`
 }
 
 // Metadata returns a Metadata corresponding to the receiver struct
@@ -65,10 +90,22 @@ func (mc *MetadataConfiguration) Metadata() (Metadata, error) {
 	ropts := mc.Retention.Options()
 	opts := NewOptions().
 		SetRetentionOptions(ropts).
-		SetIndexOptions(iopts)
+		SetIndexOptions(iopts).
+		SetRepairOptions(mc.Repair.Options()).
+		SetReadCacheOptions(mc.ReadCache.Options()).
+		SetValueBoundsOptions(mc.ValueBounds.Options()).
+		SetWriteNewSeriesBackoffDuration(mc.WriteNewSeriesBackoffDuration).
+		SetMaxWriteFutureSkew(mc.MaxWriteFutureSkew).
+		SetCompressionLevel(mc.CompressionLevel).
+		SetSeriesCachePolicy(mc.SeriesCachePolicy)
 	if v := mc.BootstrapEnabled; v != nil {
 		opts = opts.SetBootstrapEnabled(*v)
 	}
+	if v := mc.ForcedValue; v != nil {
+		opts = opts.SetForcedValueOptions(NewForcedValueOptions().
+			SetEnabled(true).
+			SetValue(*v))
+	}
 	if v := mc.FlushEnabled; v != nil {
 		opts = opts.SetFlushEnabled(*v)
 	}
@@ -99,3 +136,64 @@ func (ic *IndexConfiguration) Options() IndexOptions {
 		SetEnabled(ic.Enabled).
 		SetBlockSize(ic.BlockSize)
 }
+
+// RepairConfiguration overrides the database wide repair configuration for
+// a single namespace, any value left unset uses the database wide value.
+type RepairConfiguration struct {
+	Interval time.Duration `yaml:"interval"`
+	Offset   time.Duration `yaml:"offset"`
+	Jitter   time.Duration `yaml:"jitter"`
+	Throttle time.Duration `yaml:"throttle"`
+}
+
+// Options returns the RepairOptions corresponding to the receiver struct.
+func (rc *RepairConfiguration) Options() RepairOptions {
+	return NewRepairOptions().
+		SetInterval(rc.Interval).
+		SetOffset(rc.Offset).
+		SetJitter(rc.Jitter).
+		SetThrottle(rc.Throttle)
+}
+
+// ReadCacheConfiguration controls caching of read results for the namespace,
+// a zero size or ttl uses the default value.
+type ReadCacheConfiguration struct {
+	Enabled bool          `yaml:"enabled"`
+	Size    int           `yaml:"size"`
+	TTL     time.Duration `yaml:"ttl"`
+}
+
+// Options returns the ReadCacheOptions corresponding to the receiver struct.
+func (rc *ReadCacheConfiguration) Options() ReadCacheOptions {
+	opts := NewReadCacheOptions().SetEnabled(rc.Enabled)
+	if rc.Size != 0 {
+		opts = opts.SetSize(rc.Size)
+	}
+	if rc.TTL != 0 {
+		opts = opts.SetTTL(rc.TTL)
+	}
+	return opts
+}
+
+// ValueBoundsConfiguration controls validation of written values for the
+// namespace, an unset min or max leaves that side of the range unbounded.
+type ValueBoundsConfiguration struct {
+	Enabled bool     `yaml:"enabled"`
+	Min     *float64 `yaml:"min"`
+	Max     *float64 `yaml:"max"`
+	Clamp   bool     `yaml:"clamp"`
+}
+
+// Options returns the ValueBoundsOptions corresponding to the receiver struct.
+func (vc *ValueBoundsConfiguration) Options() ValueBoundsOptions {
+	opts := NewValueBoundsOptions().
+		SetEnabled(vc.Enabled).
+		SetClamp(vc.Clamp)
+	if vc.Min != nil {
+		opts = opts.SetMin(*vc.Min)
+	}
+	if vc.Max != nil {
+		opts = opts.SetMax(*vc.Max)
+	}
+	return opts
+}
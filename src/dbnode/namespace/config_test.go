@@ -166,6 +166,21 @@ metadatas:
     index:
       enabled: true
       blockSize: 24h
+    repair:
+      interval: 6h
+      jitter: 30m
+    readCache:
+      enabled: true
+      ttl: 5s
+    valueBounds:
+      enabled: true
+      min: 0
+      clamp: true
+    writeNewSeriesBackoffDuration: 2ms
+    maxWriteFutureSkew: 1h
+    compressionLevel: high
+    seriesCachePolicy: none
+    forceValue: 1
 `)
 
 	var conf MapConfiguration
@@ -224,6 +239,27 @@ metadatas:
 	require.Equal(t, true, opts.RepairEnabled())
 	require.Equal(t, true, opts.IndexOptions().Enabled())
 	require.Equal(t, 24*time.Hour, opts.IndexOptions().BlockSize())
+	require.True(t, NewRepairOptions().
+		SetInterval(6*time.Hour).
+		SetJitter(30*time.Minute).
+		Equal(opts.RepairOptions()))
+	require.True(t, NewReadCacheOptions().
+		SetEnabled(true).
+		SetTTL(5*time.Second).
+		Equal(opts.ReadCacheOptions()))
+	require.True(t, NewValueBoundsOptions().
+		SetEnabled(true).
+		SetMin(0).
+		SetClamp(true).
+		Equal(opts.ValueBoundsOptions()))
+	require.Equal(t, 2*time.Millisecond, opts.WriteNewSeriesBackoffDuration())
+	require.Equal(t, time.Hour, opts.MaxWriteFutureSkew())
+	require.Equal(t, HighCompressionLevel, opts.CompressionLevel())
+	require.Equal(t, "none", opts.SeriesCachePolicy())
+	require.True(t, NewForcedValueOptions().
+		SetEnabled(true).
+		SetValue(1).
+		Equal(opts.ForcedValueOptions()))
 	testRetentionOpts = retention.NewOptions().
 		SetRetentionPeriod(960 * time.Hour).
 		SetBlockSize(12 * time.Hour).
@@ -0,0 +1,67 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+const (
+	// Namespace forced values are disabled by default.
+	defaultForcedValueEnabled = false
+
+	// The forced value is zero by default.
+	defaultForcedValue = 0.0
+)
+
+type forcedValueOpts struct {
+	enabled bool
+	value   float64
+}
+
+// NewForcedValueOptions returns a new ForcedValueOptions.
+func NewForcedValueOptions() ForcedValueOptions {
+	return &forcedValueOpts{
+		enabled: defaultForcedValueEnabled,
+		value:   defaultForcedValue,
+	}
+}
+
+func (f *forcedValueOpts) Equal(value ForcedValueOptions) bool {
+	return f.Enabled() == value.Enabled() &&
+		f.Value() == value.Value()
+}
+
+func (f *forcedValueOpts) SetEnabled(value bool) ForcedValueOptions {
+	fo := *f
+	fo.enabled = value
+	return &fo
+}
+
+func (f *forcedValueOpts) Enabled() bool {
+	return f.enabled
+}
+
+func (f *forcedValueOpts) SetValue(value float64) ForcedValueOptions {
+	fo := *f
+	fo.value = value
+	return &fo
+}
+
+func (f *forcedValueOpts) Value() float64 {
+	return f.value
+}
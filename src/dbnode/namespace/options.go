@@ -22,6 +22,8 @@ package namespace
 
 import (
 	"errors"
+	"math"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/retention"
 )
@@ -53,6 +55,14 @@ var (
 	errIndexBlockSizePositive                       = errors.New("index block size must positive")
 	errIndexBlockSizeTooLarge                       = errors.New("index block size needs to be <= namespace retention period")
 	errIndexBlockSizeMustBeAMultipleOfDataBlockSize = errors.New("index block size must be a multiple of data block size")
+	errRepairOptionsNegative                        = errors.New("repair interval, offset, jitter and throttle must not be negative")
+	errReadCacheSizePositive                        = errors.New("read cache size must be positive")
+	errReadCacheTTLPositive                         = errors.New("read cache ttl must be positive")
+	errWriteNewSeriesBackoffNegative                = errors.New("write new series backoff duration must not be negative")
+	errMaxWriteFutureSkewNegative                   = errors.New("max write future skew must not be negative")
+	errValueBoundsMinGreaterThanMax                 = errors.New("value bounds min must not be greater than max")
+	errForcedValueNotFinite                         = errors.New("forced value must be finite")
+	errForcedValueOutOfBounds                       = errors.New("forced value must be within the value bounds")
 )
 
 type options struct {
@@ -65,7 +75,16 @@ type options struct {
 	coldWritesEnabled bool
 	retentionOpts     retention.Options
 	indexOpts         IndexOptions
+	repairOpts        RepairOptions
+	readCacheOpts     ReadCacheOptions
+	valueBoundsOpts   ValueBoundsOptions
+	forcedValueOpts   ForcedValueOptions
 	schemaHis         SchemaHistory
+
+	writeNewSeriesBackoffDuration time.Duration
+	maxWriteFutureSkew            time.Duration
+	compressionLevel              CompressionLevel
+	seriesCachePolicy             string
 }
 
 // NewSchemaHistory returns an empty schema history.
@@ -85,6 +104,10 @@ func NewOptions() Options {
 		coldWritesEnabled: defaultColdWritesEnabled,
 		retentionOpts:     retention.NewOptions(),
 		indexOpts:         NewIndexOptions(),
+		repairOpts:        NewRepairOptions(),
+		readCacheOpts:     NewReadCacheOptions(),
+		valueBoundsOpts:   NewValueBoundsOptions(),
+		forcedValueOpts:   NewForcedValueOptions(),
 		schemaHis:         NewSchemaHistory(),
 	}
 }
@@ -93,6 +116,43 @@ func (o *options) Validate() error {
 	if err := o.retentionOpts.Validate(); err != nil {
 		return err
 	}
+	if o.repairOpts.Interval() < 0 || o.repairOpts.Offset() < 0 ||
+		o.repairOpts.Jitter() < 0 || o.repairOpts.Throttle() < 0 {
+		return errRepairOptionsNegative
+	}
+	if o.writeNewSeriesBackoffDuration < 0 {
+		return errWriteNewSeriesBackoffNegative
+	}
+	if o.maxWriteFutureSkew < 0 {
+		return errMaxWriteFutureSkewNegative
+	}
+	if err := o.compressionLevel.Validate(); err != nil {
+		return err
+	}
+	if o.readCacheOpts.Enabled() {
+		if o.readCacheOpts.Size() <= 0 {
+			return errReadCacheSizePositive
+		}
+		if o.readCacheOpts.TTL() <= 0 {
+			return errReadCacheTTLPositive
+		}
+	}
+	if o.valueBoundsOpts.Enabled() &&
+		o.valueBoundsOpts.Min() > o.valueBoundsOpts.Max() {
+		return errValueBoundsMinGreaterThanMax
+	}
+	if o.forcedValueOpts.Enabled() {
+		value := o.forcedValueOpts.Value()
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return errForcedValueNotFinite
+		}
+		// Value bounds are validated before the value is forced, so a forced
+		// value outside of the bounds would be written regardless.
+		if o.valueBoundsOpts.Enabled() &&
+			(value < o.valueBoundsOpts.Min() || value > o.valueBoundsOpts.Max()) {
+			return errForcedValueOutOfBounds
+		}
+	}
 	if !o.indexOpts.Enabled() {
 		return nil
 	}
@@ -124,6 +184,14 @@ func (o *options) Equal(value Options) bool {
 		o.coldWritesEnabled == value.ColdWritesEnabled() &&
 		o.retentionOpts.Equal(value.RetentionOptions()) &&
 		o.indexOpts.Equal(value.IndexOptions()) &&
+		o.repairOpts.Equal(value.RepairOptions()) &&
+		o.readCacheOpts.Equal(value.ReadCacheOptions()) &&
+		o.valueBoundsOpts.Equal(value.ValueBoundsOptions()) &&
+		o.forcedValueOpts.Equal(value.ForcedValueOptions()) &&
+		o.writeNewSeriesBackoffDuration == value.WriteNewSeriesBackoffDuration() &&
+		o.maxWriteFutureSkew == value.MaxWriteFutureSkew() &&
+		o.compressionLevel == value.CompressionLevel() &&
+		o.seriesCachePolicy == value.SeriesCachePolicy() &&
 		o.schemaHis.Equal(value.SchemaHistory())
 }
 
@@ -217,6 +285,86 @@ func (o *options) IndexOptions() IndexOptions {
 	return o.indexOpts
 }
 
+func (o *options) SetRepairOptions(value RepairOptions) Options {
+	opts := *o
+	opts.repairOpts = value
+	return &opts
+}
+
+func (o *options) RepairOptions() RepairOptions {
+	return o.repairOpts
+}
+
+func (o *options) SetReadCacheOptions(value ReadCacheOptions) Options {
+	opts := *o
+	opts.readCacheOpts = value
+	return &opts
+}
+
+func (o *options) ReadCacheOptions() ReadCacheOptions {
+	return o.readCacheOpts
+}
+
+func (o *options) SetValueBoundsOptions(value ValueBoundsOptions) Options {
+	opts := *o
+	opts.valueBoundsOpts = value
+	return &opts
+}
+
+func (o *options) ValueBoundsOptions() ValueBoundsOptions {
+	return o.valueBoundsOpts
+}
+
+func (o *options) SetForcedValueOptions(value ForcedValueOptions) Options {
+	opts := *o
+	opts.forcedValueOpts = value
+	return &opts
+}
+
+func (o *options) ForcedValueOptions() ForcedValueOptions {
+	return o.forcedValueOpts
+}
+
+func (o *options) SetWriteNewSeriesBackoffDuration(value time.Duration) Options {
+	opts := *o
+	opts.writeNewSeriesBackoffDuration = value
+	return &opts
+}
+
+func (o *options) WriteNewSeriesBackoffDuration() time.Duration {
+	return o.writeNewSeriesBackoffDuration
+}
+
+func (o *options) SetMaxWriteFutureSkew(value time.Duration) Options {
+	opts := *o
+	opts.maxWriteFutureSkew = value
+	return &opts
+}
+
+func (o *options) MaxWriteFutureSkew() time.Duration {
+	return o.maxWriteFutureSkew
+}
+
+func (o *options) SetCompressionLevel(value CompressionLevel) Options {
+	opts := *o
+	opts.compressionLevel = value
+	return &opts
+}
+
+func (o *options) CompressionLevel() CompressionLevel {
+	return o.compressionLevel
+}
+
+func (o *options) SetSeriesCachePolicy(value string) Options {
+	opts := *o
+	opts.seriesCachePolicy = value
+	return &opts
+}
+
+func (o *options) SeriesCachePolicy() string {
+	return o.seriesCachePolicy
+}
+
 func (o *options) SetSchemaHistory(value SchemaHistory) Options {
 	opts := *o
 	opts.schemaHis = value
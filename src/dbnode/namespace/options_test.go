@@ -22,6 +22,7 @@ package namespace
 
 import (
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -51,6 +52,64 @@ func TestOptionsEqualsIndexOpts(t *testing.T) {
 	require.False(t, o2.Equal(o1))
 }
 
+func TestOptionsEqualsRepairOpts(t *testing.T) {
+	o1 := NewOptions()
+	o2 := o1.SetRepairOptions(
+		o1.RepairOptions().SetInterval(time.Hour))
+	require.True(t, o1.Equal(o1))
+	require.True(t, o2.Equal(o2))
+	require.False(t, o1.Equal(o2))
+	require.False(t, o2.Equal(o1))
+}
+
+func TestOptionsEqualsReadCacheOpts(t *testing.T) {
+	o1 := NewOptions()
+	o2 := o1.SetReadCacheOptions(
+		o1.ReadCacheOptions().SetEnabled(true))
+	require.True(t, o1.Equal(o1))
+	require.True(t, o2.Equal(o2))
+	require.False(t, o1.Equal(o2))
+	require.False(t, o2.Equal(o1))
+}
+
+func TestOptionsEqualsValueBoundsOpts(t *testing.T) {
+	o1 := NewOptions()
+	o2 := o1.SetValueBoundsOptions(
+		o1.ValueBoundsOptions().SetEnabled(true).SetMin(0))
+	require.True(t, o1.Equal(o1))
+	require.True(t, o2.Equal(o2))
+	require.False(t, o1.Equal(o2))
+	require.False(t, o2.Equal(o1))
+}
+
+func TestOptionsEqualsForcedValueOpts(t *testing.T) {
+	o1 := NewOptions()
+	o2 := o1.SetForcedValueOptions(
+		o1.ForcedValueOptions().SetEnabled(true).SetValue(1))
+	require.True(t, o1.Equal(o1))
+	require.True(t, o2.Equal(o2))
+	require.False(t, o1.Equal(o2))
+	require.False(t, o2.Equal(o1))
+}
+
+func TestOptionsEqualsWriteNewSeriesBackoffDuration(t *testing.T) {
+	o1 := NewOptions()
+	o2 := o1.SetWriteNewSeriesBackoffDuration(time.Millisecond)
+	require.True(t, o1.Equal(o1))
+	require.True(t, o2.Equal(o2))
+	require.False(t, o1.Equal(o2))
+	require.False(t, o2.Equal(o1))
+}
+
+func TestOptionsEqualsMaxWriteFutureSkew(t *testing.T) {
+	o1 := NewOptions()
+	o2 := o1.SetMaxWriteFutureSkew(time.Minute)
+	require.True(t, o1.Equal(o1))
+	require.True(t, o2.Equal(o2))
+	require.False(t, o1.Equal(o2))
+	require.False(t, o2.Equal(o1))
+}
+
 func TestOptionsEqualsSchema(t *testing.T) {
 	o1 := NewOptions()
 	s1, err := LoadSchemaHistory(testSchemaOptions)
@@ -180,3 +239,74 @@ func TestOptionsValidateNoIndexing(t *testing.T) {
 	rOpts.EXPECT().Validate().Return(nil)
 	require.NoError(t, o1.Validate())
 }
+
+func TestOptionsEqualsCompressionLevel(t *testing.T) {
+	o1 := NewOptions()
+	o2 := o1.SetCompressionLevel(HighCompressionLevel)
+	require.True(t, o1.Equal(o1))
+	require.True(t, o2.Equal(o2))
+	require.False(t, o1.Equal(o2))
+	require.False(t, o2.Equal(o1))
+}
+
+func TestOptionsEqualsSeriesCachePolicy(t *testing.T) {
+	o1 := NewOptions()
+	o2 := o1.SetSeriesCachePolicy("none")
+	require.True(t, o1.Equal(o1))
+	require.True(t, o2.Equal(o2))
+	require.False(t, o1.Equal(o2))
+	require.False(t, o2.Equal(o1))
+}
+
+func TestOptionsValidateRepairOptionsNegative(t *testing.T) {
+	o1 := NewOptions().SetRepairOptions(
+		NewRepairOptions().SetJitter(-time.Minute))
+	require.Equal(t, errRepairOptionsNegative, o1.Validate())
+}
+
+func TestOptionsValidateReadCacheOptions(t *testing.T) {
+	o1 := NewOptions().SetReadCacheOptions(
+		NewReadCacheOptions().SetSize(0))
+	require.NoError(t, o1.Validate())
+
+	o1 = o1.SetReadCacheOptions(o1.ReadCacheOptions().SetEnabled(true))
+	require.Equal(t, errReadCacheSizePositive, o1.Validate())
+
+	o1 = o1.SetReadCacheOptions(
+		NewReadCacheOptions().SetEnabled(true).SetTTL(0))
+	require.Equal(t, errReadCacheTTLPositive, o1.Validate())
+}
+
+func TestOptionsValidateValueBoundsOptions(t *testing.T) {
+	o1 := NewOptions().SetValueBoundsOptions(
+		NewValueBoundsOptions().SetMin(10).SetMax(1))
+	require.NoError(t, o1.Validate())
+
+	o1 = o1.SetValueBoundsOptions(o1.ValueBoundsOptions().SetEnabled(true))
+	require.Equal(t, errValueBoundsMinGreaterThanMax, o1.Validate())
+
+	o1 = o1.SetValueBoundsOptions(o1.ValueBoundsOptions().SetMax(10))
+	require.NoError(t, o1.Validate())
+}
+
+func TestOptionsValidateForcedValueOptions(t *testing.T) {
+	o1 := NewOptions().SetForcedValueOptions(
+		NewForcedValueOptions().SetValue(math.NaN()))
+	require.NoError(t, o1.Validate())
+
+	o1 = o1.SetForcedValueOptions(o1.ForcedValueOptions().SetEnabled(true))
+	require.Equal(t, errForcedValueNotFinite, o1.Validate())
+
+	o1 = o1.SetForcedValueOptions(o1.ForcedValueOptions().SetValue(math.Inf(1)))
+	require.Equal(t, errForcedValueNotFinite, o1.Validate())
+
+	o1 = o1.SetForcedValueOptions(o1.ForcedValueOptions().SetValue(1))
+	require.NoError(t, o1.Validate())
+
+	o1 = o1.SetValueBoundsOptions(
+		NewValueBoundsOptions().SetEnabled(true).SetMin(10).SetMax(100))
+	require.Equal(t, errForcedValueOutOfBounds, o1.Validate())
+
+	o1 = o1.SetForcedValueOptions(o1.ForcedValueOptions().SetValue(10))
+	require.NoError(t, o1.Validate())
+}
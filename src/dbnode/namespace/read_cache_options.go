@@ -0,0 +1,87 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"time"
+)
+
+const (
+	// Namespace read result caching is disabled by default.
+	defaultReadCacheEnabled = false
+
+	// defaultReadCacheSize is the default max number of cached read results.
+	defaultReadCacheSize = 4096
+
+	// defaultReadCacheTTL is the default time a read result is cached for.
+	defaultReadCacheTTL = 10 * time.Second
+)
+
+type readCacheOpts struct {
+	enabled bool
+	size    int
+	ttl     time.Duration
+}
+
+// NewReadCacheOptions returns a new ReadCacheOptions.
+func NewReadCacheOptions() ReadCacheOptions {
+	return &readCacheOpts{
+		enabled: defaultReadCacheEnabled,
+		size:    defaultReadCacheSize,
+		ttl:     defaultReadCacheTTL,
+	}
+}
+
+func (r *readCacheOpts) Equal(value ReadCacheOptions) bool {
+	return r.Enabled() == value.Enabled() &&
+		r.Size() == value.Size() &&
+		r.TTL() == value.TTL()
+}
+
+func (r *readCacheOpts) SetEnabled(value bool) ReadCacheOptions {
+	ro := *r
+	ro.enabled = value
+	return &ro
+}
+
+func (r *readCacheOpts) Enabled() bool {
+	return r.enabled
+}
+
+func (r *readCacheOpts) SetSize(value int) ReadCacheOptions {
+	ro := *r
+	ro.size = value
+	return &ro
+}
+
+func (r *readCacheOpts) Size() int {
+	return r.size
+}
+
+func (r *readCacheOpts) SetTTL(value time.Duration) ReadCacheOptions {
+	ro := *r
+	ro.ttl = value
+	return &ro
+}
+
+func (r *readCacheOpts) TTL() time.Duration {
+	return r.ttl
+}
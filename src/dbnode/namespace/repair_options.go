@@ -0,0 +1,85 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"time"
+)
+
+type repairOpts struct {
+	interval time.Duration
+	offset   time.Duration
+	jitter   time.Duration
+	throttle time.Duration
+}
+
+// NewRepairOptions returns a new RepairOptions with no overrides set, all
+// values defer to the database wide repair options.
+func NewRepairOptions() RepairOptions {
+	return &repairOpts{}
+}
+
+func (r *repairOpts) Equal(value RepairOptions) bool {
+	return r.Interval() == value.Interval() &&
+		r.Offset() == value.Offset() &&
+		r.Jitter() == value.Jitter() &&
+		r.Throttle() == value.Throttle()
+}
+
+func (r *repairOpts) SetInterval(value time.Duration) RepairOptions {
+	ro := *r
+	ro.interval = value
+	return &ro
+}
+
+func (r *repairOpts) Interval() time.Duration {
+	return r.interval
+}
+
+func (r *repairOpts) SetOffset(value time.Duration) RepairOptions {
+	ro := *r
+	ro.offset = value
+	return &ro
+}
+
+func (r *repairOpts) Offset() time.Duration {
+	return r.offset
+}
+
+func (r *repairOpts) SetJitter(value time.Duration) RepairOptions {
+	ro := *r
+	ro.jitter = value
+	return &ro
+}
+
+func (r *repairOpts) Jitter() time.Duration {
+	return r.jitter
+}
+
+func (r *repairOpts) SetThrottle(value time.Duration) RepairOptions {
+	ro := *r
+	ro.throttle = value
+	return &ro
+}
+
+func (r *repairOpts) Throttle() time.Duration {
+	return r.throttle
+}
@@ -92,6 +92,65 @@ type Options interface {
 	// IndexOptions returns the IndexOptions.
 	IndexOptions() IndexOptions
 
+	// SetRepairOptions sets the per namespace repair overrides.
+	SetRepairOptions(value RepairOptions) Options
+
+	// RepairOptions returns the per namespace repair overrides.
+	RepairOptions() RepairOptions
+
+	// SetReadCacheOptions sets the read result cache options.
+	SetReadCacheOptions(value ReadCacheOptions) Options
+
+	// ReadCacheOptions returns the read result cache options.
+	ReadCacheOptions() ReadCacheOptions
+
+	// SetValueBoundsOptions sets the write value bounds options.
+	SetValueBoundsOptions(value ValueBoundsOptions) Options
+
+	// ValueBoundsOptions returns the write value bounds options.
+	ValueBoundsOptions() ValueBoundsOptions
+
+	// SetForcedValueOptions sets the write forced value options, overriding
+	// the database wide forced value when enabled.
+	SetForcedValueOptions(value ForcedValueOptions) Options
+
+	// ForcedValueOptions returns the write forced value options.
+	ForcedValueOptions() ForcedValueOptions
+
+	// SetWriteNewSeriesBackoffDuration sets the new series insert backoff
+	// for the namespace, zero uses the database wide runtime option.
+	SetWriteNewSeriesBackoffDuration(value time.Duration) Options
+
+	// WriteNewSeriesBackoffDuration returns the new series insert backoff
+	// for the namespace, zero uses the database wide runtime option.
+	WriteNewSeriesBackoffDuration() time.Duration
+
+	// SetMaxWriteFutureSkew sets how far ahead of the node's clock a write
+	// timestamp may be before the write is rejected as clock skewed, zero
+	// disables the check.
+	SetMaxWriteFutureSkew(value time.Duration) Options
+
+	// MaxWriteFutureSkew returns how far ahead of the node's clock a write
+	// timestamp may be before the write is rejected as clock skewed, zero
+	// disables the check.
+	MaxWriteFutureSkew() time.Duration
+
+	// SetCompressionLevel sets the compression level for blocks flushed to
+	// disk for the namespace.
+	SetCompressionLevel(value CompressionLevel) Options
+
+	// CompressionLevel returns the compression level for blocks flushed to
+	// disk for the namespace.
+	CompressionLevel() CompressionLevel
+
+	// SetSeriesCachePolicy sets the name of the series cache policy for the
+	// namespace, when empty the database wide series cache policy is used.
+	SetSeriesCachePolicy(value string) Options
+
+	// SeriesCachePolicy returns the name of the series cache policy for the
+	// namespace, when empty the database wide series cache policy is used.
+	SeriesCachePolicy() string
+
 	// SetSchemaHistory sets the schema registry for this namespace.
 	SetSchemaHistory(value SchemaHistory) Options
 
@@ -117,6 +176,119 @@ type IndexOptions interface {
 	BlockSize() time.Duration
 }
 
+// RepairOptions controls the per namespace overrides of the database wide
+// repair options, a zero value uses the database wide value instead.
+type RepairOptions interface {
+	// Equal returns true if the provide value is equal to this one.
+	Equal(value RepairOptions) bool
+
+	// SetInterval sets the repair interval.
+	SetInterval(value time.Duration) RepairOptions
+
+	// Interval returns the repair interval.
+	Interval() time.Duration
+
+	// SetOffset sets the repair time offset.
+	SetOffset(value time.Duration) RepairOptions
+
+	// Offset returns the repair time offset.
+	Offset() time.Duration
+
+	// SetJitter sets the repair time jitter.
+	SetJitter(value time.Duration) RepairOptions
+
+	// Jitter returns the repair time jitter.
+	Jitter() time.Duration
+
+	// SetThrottle sets the repair throttle.
+	SetThrottle(value time.Duration) RepairOptions
+
+	// Throttle returns the repair throttle.
+	Throttle() time.Duration
+}
+
+// ReadCacheOptions controls caching of read results for identical repeated
+// reads of a series, it is only safe for append mostly workloads since
+// results are cached until expired, the series is written to (including
+// batch writes) or the namespace is bootstrapped or truncated. Blocks merged
+// into a series directly with DatabaseSeries.ReconcileWithPeer do not drop
+// cached results and are only read once the cached results expire.
+type ReadCacheOptions interface {
+	// Equal returns true if the provide value is equal to this one.
+	Equal(value ReadCacheOptions) bool
+
+	// SetEnabled sets whether read results are cached.
+	SetEnabled(value bool) ReadCacheOptions
+
+	// Enabled returns whether read results are cached.
+	Enabled() bool
+
+	// SetSize sets the max number of cached read results.
+	SetSize(value int) ReadCacheOptions
+
+	// Size returns the max number of cached read results.
+	Size() int
+
+	// SetTTL sets how long a read result is cached for.
+	SetTTL(value time.Duration) ReadCacheOptions
+
+	// TTL returns how long a read result is cached for.
+	TTL() time.Duration
+}
+
+// ValueBoundsOptions controls validation of written values against a min
+// and max bound, catching upstream producer bugs before the values are
+// stored. Out of bounds values are either rejected or clamped to the bound.
+type ValueBoundsOptions interface {
+	// Equal returns true if the provide value is equal to this one.
+	Equal(value ValueBoundsOptions) bool
+
+	// SetEnabled sets whether written values are validated.
+	SetEnabled(value bool) ValueBoundsOptions
+
+	// Enabled returns whether written values are validated.
+	Enabled() bool
+
+	// SetMin sets the min allowed value, inclusive.
+	SetMin(value float64) ValueBoundsOptions
+
+	// Min returns the min allowed value, inclusive.
+	Min() float64
+
+	// SetMax sets the max allowed value, inclusive.
+	SetMax(value float64) ValueBoundsOptions
+
+	// Max returns the max allowed value, inclusive.
+	Max() float64
+
+	// SetClamp sets whether out of bounds values are clamped to the
+	// nearest bound instead of being rejected.
+	SetClamp(value bool) ValueBoundsOptions
+
+	// Clamp returns whether out of bounds values are clamped to the
+	// nearest bound instead of being rejected.
+	Clamp() bool
+}
+
+// ForcedValueOptions controls forcing the values of written datapoints to a
+// single value, e.g. for presence metrics where only the timestamp matters.
+type ForcedValueOptions interface {
+	// Equal returns true if the provide value is equal to this one.
+	Equal(value ForcedValueOptions) bool
+
+	// SetEnabled sets whether written values are forced.
+	SetEnabled(value bool) ForcedValueOptions
+
+	// Enabled returns whether written values are forced.
+	Enabled() bool
+
+	// SetValue sets the value written values are forced to.
+	SetValue(value float64) ForcedValueOptions
+
+	// Value returns the value written values are forced to.
+	Value() float64
+}
+
 // SchemaDescr describes the schema for a complex type value.
 type SchemaDescr interface {
 	// DeployId returns the deploy id of the schema.
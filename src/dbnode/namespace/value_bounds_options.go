@@ -0,0 +1,102 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"math"
+)
+
+const (
+	// Namespace value bounds validation is disabled by default.
+	defaultValueBoundsEnabled = false
+
+	// Values are rejected rather than clamped by default.
+	defaultValueBoundsClamp = false
+)
+
+var (
+	defaultValueBoundsMin = math.Inf(-1)
+	defaultValueBoundsMax = math.Inf(1)
+)
+
+type valueBoundsOpts struct {
+	enabled bool
+	min     float64
+	max     float64
+	clamp   bool
+}
+
+// NewValueBoundsOptions returns a new ValueBoundsOptions.
+func NewValueBoundsOptions() ValueBoundsOptions {
+	return &valueBoundsOpts{
+		enabled: defaultValueBoundsEnabled,
+		min:     defaultValueBoundsMin,
+		max:     defaultValueBoundsMax,
+		clamp:   defaultValueBoundsClamp,
+	}
+}
+
+func (v *valueBoundsOpts) Equal(value ValueBoundsOptions) bool {
+	return v.Enabled() == value.Enabled() &&
+		v.Min() == value.Min() &&
+		v.Max() == value.Max() &&
+		v.Clamp() == value.Clamp()
+}
+
+func (v *valueBoundsOpts) SetEnabled(value bool) ValueBoundsOptions {
+	vo := *v
+	vo.enabled = value
+	return &vo
+}
+
+func (v *valueBoundsOpts) Enabled() bool {
+	return v.enabled
+}
+
+func (v *valueBoundsOpts) SetMin(value float64) ValueBoundsOptions {
+	vo := *v
+	vo.min = value
+	return &vo
+}
+
+func (v *valueBoundsOpts) Min() float64 {
+	return v.min
+}
+
+func (v *valueBoundsOpts) SetMax(value float64) ValueBoundsOptions {
+	vo := *v
+	vo.max = value
+	return &vo
+}
+
+func (v *valueBoundsOpts) Max() float64 {
+	return v.max
+}
+
+func (v *valueBoundsOpts) SetClamp(value bool) ValueBoundsOptions {
+	vo := *v
+	vo.clamp = value
+	return &vo
+}
+
+func (v *valueBoundsOpts) Clamp() bool {
+	return v.clamp
+}
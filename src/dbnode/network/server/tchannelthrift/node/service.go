@@ -21,9 +21,12 @@
 package node
 
 import (
+	stdctx "context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/client"
@@ -52,8 +55,11 @@ import (
 
 	apachethrift "github.com/apache/thrift/lib/go/thrift"
 	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 	opentracinglog "github.com/opentracing/opentracing-go/log"
 	"github.com/uber-go/tally"
+	tchannel "github.com/uber/tchannel-go"
 	"github.com/uber/tchannel-go/thrift"
 	"go.uber.org/zap"
 )
@@ -70,6 +76,13 @@ const (
 	maxSegmentArrayPooledLength = 32
 	// Any pooled error slices that grow beyond this capcity will be thrown away.
 	writeBatchPooledReqPoolMaxErrorsSliceSize = 4096
+	// drainPollInterval is how often the in flight RPCs are checked when
+	// draining.
+	drainPollInterval = 10 * time.Millisecond
+)
+
+var (
+	noopCancelWriteTimeout = func() {}
 )
 
 var (
@@ -85,6 +98,14 @@ var (
 	// errNodeIsNotBootstrapped
 	errNodeIsNotBootstrapped = errors.New("node is not bootstrapped")
 
+	// errNodeIsWithinReadinessGracePeriod is raised when the node is
+	// bootstrapped but has not yet been so for the readiness grace period.
+	errNodeIsWithinReadinessGracePeriod = errors.New("node is bootstrapped but within readiness grace period")
+
+	// errNodeHasFailedBootstrapShards is raised when the node is bootstrapped
+	// but some namespaces failed to bootstrap and are being retried.
+	errNodeHasFailedBootstrapShards = errors.New("node has shards that failed to bootstrap")
+
 	// errDatabaseIsNotInitializedYet is raised when an RPC attempt is made before the database
 	// has been set.
 	errDatabaseIsNotInitializedYet = errors.New("database is not yet initialized")
@@ -97,44 +118,56 @@ var (
 
 	// errHealthNotSet is raised when server health data structure is not set.
 	errHealthNotSet = errors.New("server health not set")
+
+	// errReadProxyClientHasAlreadyBeenSet is raised when SetReadProxyClient() is called more than one time.
+	errReadProxyClientHasAlreadyBeenSet = errors.New("read proxy client has already been set")
 )
 
 type serviceMetrics struct {
-	fetch               instrument.MethodMetrics
-	fetchTagged         instrument.MethodMetrics
-	aggregate           instrument.MethodMetrics
-	write               instrument.MethodMetrics
-	writeTagged         instrument.MethodMetrics
-	fetchBlocks         instrument.MethodMetrics
-	fetchBlocksMetadata instrument.MethodMetrics
-	repair              instrument.MethodMetrics
-	truncate            instrument.MethodMetrics
-	fetchBatchRaw       instrument.BatchMethodMetrics
-	writeBatchRaw       instrument.BatchMethodMetrics
-	writeTaggedBatchRaw instrument.BatchMethodMetrics
-	overloadRejected    tally.Counter
+	fetch                 instrument.MethodMetrics
+	fetchTagged           instrument.MethodMetrics
+	aggregate             instrument.MethodMetrics
+	write                 instrument.MethodMetrics
+	writeTagged           instrument.MethodMetrics
+	fetchBlocks           instrument.MethodMetrics
+	fetchBlocksMetadata   instrument.MethodMetrics
+	repair                instrument.MethodMetrics
+	truncate              instrument.MethodMetrics
+	warmPostingsListCache instrument.MethodMetrics
+	fetchBatchRaw         instrument.BatchMethodMetrics
+	writeBatchRaw         instrument.BatchMethodMetrics
+	writeTaggedBatchRaw   instrument.BatchMethodMetrics
+	overloadRejected      tally.Counter
+	readProxied           tally.Counter
 }
 
 func newServiceMetrics(scope tally.Scope, samplingRate float64) serviceMetrics {
 	return serviceMetrics{
-		fetch:               instrument.NewMethodMetrics(scope, "fetch", samplingRate),
-		fetchTagged:         instrument.NewMethodMetrics(scope, "fetchTagged", samplingRate),
-		aggregate:           instrument.NewMethodMetrics(scope, "aggregate", samplingRate),
-		write:               instrument.NewMethodMetrics(scope, "write", samplingRate),
-		writeTagged:         instrument.NewMethodMetrics(scope, "writeTagged", samplingRate),
-		fetchBlocks:         instrument.NewMethodMetrics(scope, "fetchBlocks", samplingRate),
-		fetchBlocksMetadata: instrument.NewMethodMetrics(scope, "fetchBlocksMetadata", samplingRate),
-		repair:              instrument.NewMethodMetrics(scope, "repair", samplingRate),
-		truncate:            instrument.NewMethodMetrics(scope, "truncate", samplingRate),
-		fetchBatchRaw:       instrument.NewBatchMethodMetrics(scope, "fetchBatchRaw", samplingRate),
-		writeBatchRaw:       instrument.NewBatchMethodMetrics(scope, "writeBatchRaw", samplingRate),
-		writeTaggedBatchRaw: instrument.NewBatchMethodMetrics(scope, "writeTaggedBatchRaw", samplingRate),
-		overloadRejected:    scope.Counter("overload-rejected"),
+		fetch:                 instrument.NewMethodMetrics(scope, "fetch", samplingRate),
+		fetchTagged:           instrument.NewMethodMetrics(scope, "fetchTagged", samplingRate),
+		aggregate:             instrument.NewMethodMetrics(scope, "aggregate", samplingRate),
+		write:                 instrument.NewMethodMetrics(scope, "write", samplingRate),
+		writeTagged:           instrument.NewMethodMetrics(scope, "writeTagged", samplingRate),
+		fetchBlocks:           instrument.NewMethodMetrics(scope, "fetchBlocks", samplingRate),
+		fetchBlocksMetadata:   instrument.NewMethodMetrics(scope, "fetchBlocksMetadata", samplingRate),
+		repair:                instrument.NewMethodMetrics(scope, "repair", samplingRate),
+		truncate:              instrument.NewMethodMetrics(scope, "truncate", samplingRate),
+		warmPostingsListCache: instrument.NewMethodMetrics(scope, "warmPostingsListCache", samplingRate),
+		fetchBatchRaw:         instrument.NewBatchMethodMetrics(scope, "fetchBatchRaw", samplingRate),
+		writeBatchRaw:         instrument.NewBatchMethodMetrics(scope, "writeBatchRaw", samplingRate),
+		writeTaggedBatchRaw:   instrument.NewBatchMethodMetrics(scope, "writeTaggedBatchRaw", samplingRate),
+		overloadRejected:      scope.Counter("overload-rejected"),
+		readProxied:           scope.Counter("read-proxied-while-bootstrapping"),
 	}
 }
 
 // TODO(r): server side pooling for all return types from service methods
 type service struct {
+	// inflightRPCs is the number of read and write RPCs in flight, it is
+	// tracked regardless of the outstanding RPC limits to drain the service.
+	// NB: kept first in the struct for 64 bit alignment of atomic accesses.
+	inflightRPCs int64
+
 	state serviceState
 
 	logger *zap.Logger
@@ -143,6 +176,15 @@ type service struct {
 	nowFn   clock.NowFn
 	pools   pools
 	metrics serviceMetrics
+
+	nsTraceSampleRates  map[string]float64
+	traceSampleFn       func() float64
+	readProxyNamespaces map[string]struct{}
+
+	// auditLogger and auditScope are nil unless the admin audit log and
+	// admin audit metrics are enabled respectively.
+	auditLogger *zap.Logger
+	auditScope  tally.Scope
 }
 
 type serviceState struct {
@@ -150,6 +192,11 @@ type serviceState struct {
 	db     storage.Database
 	health *rpc.NodeHealthResult_
 
+	readProxyClient client.AdminClient
+
+	bootstrapVerifyErr error
+	bootstrappedAt     time.Time
+
 	numOutstandingWriteRPCs int
 	maxOutstandingWriteRPCs int
 
@@ -164,6 +211,34 @@ func (s *serviceState) DB() (storage.Database, bool) {
 	return v, v != nil
 }
 
+func (s *serviceState) ReadProxyClient() (client.AdminClient, bool) {
+	s.RLock()
+	v := s.readProxyClient
+	s.RUnlock()
+	return v, v != nil
+}
+
+func (s *serviceState) BootstrapVerifyError() error {
+	s.RLock()
+	v := s.bootstrapVerifyErr
+	s.RUnlock()
+	return v
+}
+
+// ObserveBootstrapped records whether the database was observed to be
+// bootstrapped at the given time and returns the time since which it has
+// been continuously observed as bootstrapped.
+func (s *serviceState) ObserveBootstrapped(bootstrapped bool, now time.Time) time.Time {
+	s.Lock()
+	defer s.Unlock()
+	if !bootstrapped {
+		s.bootstrappedAt = time.Time{}
+	} else if s.bootstrappedAt.IsZero() {
+		s.bootstrappedAt = now
+	}
+	return s.bootstrappedAt
+}
+
 func (s *serviceState) Health() (*rpc.NodeHealthResult_, bool) {
 	s.RLock()
 	v := s.health
@@ -240,6 +315,21 @@ type Service interface {
 
 	// Only safe to be called one time once the service has started.
 	SetDatabase(db storage.Database) error
+
+	// SetReadProxyClient sets the client used to proxy reads to a peer while
+	// the database is bootstrapping, only safe to be called one time once
+	// the service has started.
+	SetReadProxyClient(value client.AdminClient) error
+
+	// SetBootstrapVerifyError sets the error found when verifying the
+	// bootstrapped shards against the topology, a non-nil error fails
+	// the bootstrapped readiness checks.
+	SetBootstrapVerifyError(err error)
+
+	// Drain waits for the read and write RPCs in flight to complete, up to
+	// the timeout, and returns whether they all completed. It should be
+	// called once the service no longer accepts new requests.
+	Drain(timeout time.Duration) bool
 }
 
 // NewService creates a new node TChannel Thrift service
@@ -287,6 +377,22 @@ func NewService(db storage.Database, opts tchannelthrift.Options) Service {
 	writeBatchPooledReqPool := newWriteBatchPooledReqPool(writeBatchPoolSize, iopts)
 	writeBatchPooledReqPool.Init(opts.TagDecoderPool())
 
+	readProxyNamespaces := make(map[string]struct{})
+	for _, ns := range opts.ReadProxyWhileBootstrappingNamespaces() {
+		readProxyNamespaces[ns] = struct{}{}
+	}
+
+	var (
+		auditLogger *zap.Logger
+		auditScope  tally.Scope
+	)
+	if opts.AdminAuditLogEnabled() {
+		auditLogger = iopts.Logger().Named("admin-audit")
+	}
+	if opts.AdminAuditMetricsEnabled() {
+		auditScope = scope.SubScope("admin-audit")
+	}
+
 	return &service{
 		state: serviceState{
 			db: db,
@@ -312,6 +418,11 @@ func NewService(db storage.Database, opts tchannelthrift.Options) Service {
 			blockMetadataV2:         opts.BlockMetadataV2Pool(),
 			blockMetadataV2Slice:    opts.BlockMetadataV2SlicePool(),
 		},
+		nsTraceSampleRates:  opts.NamespaceTraceSampleRates(),
+		traceSampleFn:       rand.Float64,
+		readProxyNamespaces: readProxyNamespaces,
+		auditLogger:         auditLogger,
+		auditScope:          auditScope,
 	}
 }
 
@@ -363,13 +474,8 @@ func (s *service) Bootstrapped(ctx thrift.Context) (*rpc.NodeBootstrappedResult_
 		return nil, convert.ToRPCError(errDatabaseIsNotInitializedYet)
 	}
 
-	// Note that we use IsBootstrappedAndDurable instead of IsBootstrapped to
-	// make sure that in the scenario where a topology change has occurred, none
-	// of our automated tooling will assume a node is healthy until it has
-	// marked all its shards as available and is able to bootstrap all the
-	// shards it owns from its own local disk.
-	if bootstrapped := db.IsBootstrappedAndDurable(); !bootstrapped {
-		return nil, convert.ToRPCError(errNodeIsNotBootstrapped)
+	if err := s.bootstrappedReadiness(db); err != nil {
+		return nil, convert.ToRPCError(err)
 	}
 
 	return &rpc.NodeBootstrappedResult_{}, nil
@@ -400,13 +506,46 @@ func (s *service) BootstrappedInPlacementOrNoPlacement(ctx thrift.Context) (*rpc
 		return nil, convert.ToRPCError(errDatabaseIsNotInitializedYet)
 	}
 
-	if bootstrapped := db.IsBootstrappedAndDurable(); !bootstrapped {
-		return nil, convert.ToRPCError(errNodeIsNotBootstrapped)
+	if err := s.bootstrappedReadiness(db); err != nil {
+		return nil, convert.ToRPCError(err)
 	}
 
 	return &rpc.NodeBootstrappedInPlacementOrNoPlacementResult_{}, nil
 }
 
+// bootstrappedReadiness returns an error if the node should not yet be
+// considered ready by the bootstrapped readiness checks.
+func (s *service) bootstrappedReadiness(db storage.Database) error {
+	// Note that we use IsBootstrappedAndDurable instead of IsBootstrapped to
+	// make sure that in the scenario where a topology change has occurred, none
+	// of our automated tooling will assume a node is healthy until it has
+	// marked all its shards as available and is able to bootstrap all the
+	// shards it owns from its own local disk.
+	var (
+		now            = s.nowFn()
+		bootstrapped   = db.IsBootstrappedAndDurable()
+		bootstrappedAt = s.state.ObserveBootstrapped(bootstrapped, now)
+	)
+	if !bootstrapped {
+		return errNodeIsNotBootstrapped
+	}
+	if err := s.state.BootstrapVerifyError(); err != nil {
+		return err
+	}
+	if failed := db.FailedBootstrapShards(); len(failed) > 0 {
+		return fmt.Errorf("%v: %v", errNodeHasFailedBootstrapShards, failed)
+	}
+
+	// Only report ready once the node has remained bootstrapped for the
+	// grace period so that a node that has just restarted has time to
+	// stabilize before receiving traffic.
+	if now.Sub(bootstrappedAt) < s.opts.BootstrappedReadinessGracePeriod() {
+		return errNodeIsWithinReadinessGracePeriod
+	}
+
+	return nil
+}
+
 func (s *service) Query(tctx thrift.Context, req *rpc.QueryRequest) (*rpc.QueryResult_, error) {
 	db, err := s.startReadRPCWithDB()
 	if err != nil {
@@ -414,7 +553,8 @@ func (s *service) Query(tctx thrift.Context, req *rpc.QueryRequest) (*rpc.QueryR
 	}
 	defer s.readRPCCompleted()
 
-	ctx, sp := tchannelthrift.Context(tctx).StartTraceSpan(tracepoint.Query)
+	ctx, sp := tchannelthrift.Context(tctx).StartTraceSpan(tracepoint.Query,
+		s.traceSpanOptions(req.NameSpace)...)
 	sp.LogFields(
 		opentracinglog.String("query", req.Query.String()),
 		opentracinglog.String("namespace", req.NameSpace),
@@ -498,6 +638,10 @@ func (s *service) Fetch(tctx thrift.Context, req *rpc.FetchRequest) (*rpc.FetchR
 	}
 	defer s.readRPCCompleted()
 
+	if session, ok := s.readProxySession(db, req.NameSpace); ok {
+		return s.proxyFetch(tctx, session, req)
+	}
+
 	var (
 		callStart = s.nowFn()
 		ctx       = tchannelthrift.Context(tctx)
@@ -575,7 +719,12 @@ func (s *service) FetchTagged(tctx thrift.Context, req *rpc.FetchTaggedRequest)
 	}
 	defer s.readRPCCompleted()
 
-	ctx, sp := tchannelthrift.Context(tctx).StartTraceSpan(tracepoint.FetchTagged)
+	if session, ok := s.readProxySession(db, string(req.NameSpace)); ok {
+		return s.proxyFetchTagged(tctx, session, db, req)
+	}
+
+	ctx, sp := tchannelthrift.Context(tctx).StartTraceSpan(tracepoint.FetchTagged,
+		s.traceSpanOptions(string(req.NameSpace))...)
 	sp.LogFields(
 		opentracinglog.String("query", string(req.Query)),
 		opentracinglog.String("namespace", string(req.NameSpace)),
@@ -919,6 +1068,9 @@ func (s *service) FetchBlocksMetadataRawV2(tctx thrift.Context, req *rpc.FetchBl
 	if req.IncludeLastRead != nil {
 		opts.IncludeLastRead = *req.IncludeLastRead
 	}
+	// The tags are only ever returned encoded so avoid decoding the tags of
+	// series read from disk just to encode them again.
+	opts.LazyTags = true
 
 	var (
 		nsID  = s.newID(ctx, req.NameSpace)
@@ -971,9 +1123,9 @@ func (s *service) getBlocksMetadataV2FromResult(
 		var (
 			id          = fetchedMetadata.ID.Bytes()
 			tags        = fetchedMetadata.Tags
-			encodedTags []byte
+			encodedTags = fetchedMetadata.EncodedTags
 		)
-		if tags != nil && tags.Remaining() > 0 {
+		if encodedTags == nil && tags != nil && tags.Remaining() > 0 {
 			enc := s.pools.tagEncoder.Get()
 			ctx.RegisterFinalizer(enc)
 			encoded, err := s.encodeTags(enc, tags)
@@ -1035,6 +1187,8 @@ func (s *service) Write(tctx thrift.Context, req *rpc.WriteRequest) error {
 
 	callStart := s.nowFn()
 	ctx := tchannelthrift.Context(tctx)
+	cancelWriteTimeout := s.withWriteTimeout(db, ctx)
+	defer cancelWriteTimeout()
 
 	if req.Datapoint == nil {
 		s.metrics.write.ReportError(s.nowFn().Sub(callStart))
@@ -1082,6 +1236,8 @@ func (s *service) WriteTagged(tctx thrift.Context, req *rpc.WriteTaggedRequest)
 
 	callStart := s.nowFn()
 	ctx := tchannelthrift.Context(tctx)
+	cancelWriteTimeout := s.withWriteTimeout(db, ctx)
+	defer cancelWriteTimeout()
 
 	if req.Datapoint == nil {
 		s.metrics.writeTagged.ReportError(s.nowFn().Sub(callStart))
@@ -1136,6 +1292,8 @@ func (s *service) WriteBatchRaw(tctx thrift.Context, req *rpc.WriteBatchRawReque
 
 	callStart := s.nowFn()
 	ctx := tchannelthrift.Context(tctx)
+	cancelWriteTimeout := s.withWriteTimeout(db, ctx)
+	defer cancelWriteTimeout()
 
 	// NB(r): Use the pooled request tracking to return thrift alloc'd bytes
 	// to the thrift bytes pool and to return ident.ID wrappers to a pool for
@@ -1220,6 +1378,8 @@ func (s *service) WriteTaggedBatchRaw(tctx thrift.Context, req *rpc.WriteTaggedB
 
 	callStart := s.nowFn()
 	ctx := tchannelthrift.Context(tctx)
+	cancelWriteTimeout := s.withWriteTimeout(db, ctx)
+	defer cancelWriteTimeout()
 
 	// NB(r): Use the pooled request tracking to return thrift alloc'd bytes
 	// to the thrift bytes pool and to return ident.ID wrappers to a pool for
@@ -1302,6 +1462,8 @@ func (s *service) WriteTaggedBatchRaw(tctx thrift.Context, req *rpc.WriteTaggedB
 }
 
 func (s *service) Repair(tctx thrift.Context) error {
+	s.auditAdminRPC(tctx, "repair")
+
 	db, err := s.startRPCWithDB()
 	if err != nil {
 		return err
@@ -1319,7 +1481,81 @@ func (s *service) Repair(tctx thrift.Context) error {
 	return nil
 }
 
+func (s *service) Quiesce(tctx thrift.Context) error {
+	s.auditAdminRPC(tctx, "quiesce")
+
+	db, err := s.startRPCWithDB()
+	if err != nil {
+		return err
+	}
+
+	if err := db.Quiesce(); err != nil {
+		return convert.ToRPCError(err)
+	}
+
+	return nil
+}
+
+func (s *service) Unquiesce(tctx thrift.Context) error {
+	s.auditAdminRPC(tctx, "unquiesce")
+
+	db, err := s.startRPCWithDB()
+	if err != nil {
+		return err
+	}
+
+	if err := db.Unquiesce(); err != nil {
+		return convert.ToRPCError(err)
+	}
+
+	return nil
+}
+
+// WarmPostingsListCache executes a set of queries against the index so that
+// the postings lists they resolve are populated in the postings list cache,
+// subject to the cache regexp and terms options. This is useful to replay a
+// recorded query workload to warm the cache before taking traffic.
+func (s *service) WarmPostingsListCache(
+	tctx thrift.Context,
+	req *rpc.WarmPostingsListCacheRequest,
+) (*rpc.WarmPostingsListCacheResult_, error) {
+	s.auditAdminRPC(tctx, "warmPostingsListCache",
+		zap.Int("numQueries", len(req.Queries)))
+
+	db, err := s.startReadRPCWithDB()
+	if err != nil {
+		return nil, err
+	}
+	defer s.readRPCCompleted()
+
+	callStart := s.nowFn()
+	ctx := tchannelthrift.Context(tctx)
+	res := rpc.NewWarmPostingsListCacheResult_()
+	for _, queryReq := range req.Queries {
+		ns, query, opts, _, err := convert.FromRPCFetchTaggedRequest(queryReq, s.pools)
+		if err != nil {
+			s.metrics.warmPostingsListCache.ReportError(s.nowFn().Sub(callStart))
+			return nil, tterrors.NewBadRequestError(err)
+		}
+
+		// NB: The results are discarded, executing the query is enough to
+		// populate the postings list cache.
+		if _, err := db.QueryIDs(ctx, ns, query, opts); err != nil {
+			s.metrics.warmPostingsListCache.ReportError(s.nowFn().Sub(callStart))
+			return nil, convert.ToRPCError(err)
+		}
+		res.NumQueries++
+	}
+
+	s.metrics.warmPostingsListCache.ReportSuccess(s.nowFn().Sub(callStart))
+
+	return res, nil
+}
+
 func (s *service) Truncate(tctx thrift.Context, req *rpc.TruncateRequest) (r *rpc.TruncateResult_, err error) {
+	s.auditAdminRPC(tctx, "truncate",
+		zap.ByteString("namespace", req.NameSpace))
+
 	db, err := s.startRPCWithDB()
 	if err != nil {
 		return nil, err
@@ -1366,6 +1602,18 @@ func (s *service) SetPersistRateLimit(
 	ctx thrift.Context,
 	req *rpc.NodeSetPersistRateLimitRequest,
 ) (*rpc.NodePersistRateLimitResult_, error) {
+	fields := make([]zap.Field, 0, 3)
+	if req.LimitEnabled != nil {
+		fields = append(fields, zap.Bool("limitEnabled", *req.LimitEnabled))
+	}
+	if req.LimitMbps != nil {
+		fields = append(fields, zap.Float64("limitMbps", *req.LimitMbps))
+	}
+	if req.LimitCheckEvery != nil {
+		fields = append(fields, zap.Int64("limitCheckEvery", *req.LimitCheckEvery))
+	}
+	s.auditAdminRPC(ctx, "setPersistRateLimit", fields...)
+
 	db, err := s.startRPCWithDB()
 	if err != nil {
 		return nil, err
@@ -1408,6 +1656,9 @@ func (s *service) SetWriteNewSeriesAsync(
 	ctx thrift.Context,
 	req *rpc.NodeSetWriteNewSeriesAsyncRequest,
 ) (*rpc.NodeWriteNewSeriesAsyncResult_, error) {
+	s.auditAdminRPC(ctx, "setWriteNewSeriesAsync",
+		zap.Bool("writeNewSeriesAsync", req.WriteNewSeriesAsync))
+
 	db, err := s.startRPCWithDB()
 	if err != nil {
 		return nil, err
@@ -1447,6 +1698,10 @@ func (s *service) SetWriteNewSeriesBackoffDuration(
 	*rpc.NodeWriteNewSeriesBackoffDurationResult_,
 	error,
 ) {
+	s.auditAdminRPC(ctx, "setWriteNewSeriesBackoffDuration",
+		zap.Int64("writeNewSeriesBackoffDuration", req.WriteNewSeriesBackoffDuration),
+		zap.Stringer("durationType", req.DurationType))
+
 	db, err := s.startRPCWithDB()
 	if err != nil {
 		return nil, err
@@ -1490,6 +1745,10 @@ func (s *service) SetWriteNewSeriesLimitPerShardPerSecond(
 	*rpc.NodeWriteNewSeriesLimitPerShardPerSecondResult_,
 	error,
 ) {
+	s.auditAdminRPC(ctx, "setWriteNewSeriesLimitPerShardPerSecond",
+		zap.Int64("writeNewSeriesLimitPerShardPerSecond",
+			req.WriteNewSeriesLimitPerShardPerSecond))
+
 	db, err := s.startRPCWithDB()
 	if err != nil {
 		return nil, err
@@ -1504,6 +1763,33 @@ func (s *service) SetWriteNewSeriesLimitPerShardPerSecond(
 	return s.GetWriteNewSeriesLimitPerShardPerSecond(ctx)
 }
 
+// auditAdminRPC records the invocation of an administrative RPC along with
+// the identity of the caller as reported by the calling channel.
+func (s *service) auditAdminRPC(
+	ctx thrift.Context,
+	operation string,
+	fields ...zap.Field,
+) {
+	if s.auditScope != nil {
+		s.auditScope.Tagged(map[string]string{
+			"operation": operation,
+		}).Counter("invoked").Inc(1)
+	}
+	if s.auditLogger == nil {
+		return
+	}
+
+	fields = append(fields, zap.String("operation", operation))
+	if call := tchannel.CurrentCall(ctx); call != nil {
+		remote := call.RemotePeer()
+		fields = append(fields,
+			zap.String("callerName", call.CallerName()),
+			zap.String("callerHostPort", remote.HostPort),
+			zap.String("callerProcessName", remote.ProcessName))
+	}
+	s.auditLogger.Info("admin rpc invoked", fields...)
+}
+
 func (s *service) SetDatabase(db storage.Database) error {
 	s.state.Lock()
 	defer s.state.Unlock()
@@ -1516,10 +1802,43 @@ func (s *service) SetDatabase(db storage.Database) error {
 	return nil
 }
 
+func (s *service) SetReadProxyClient(value client.AdminClient) error {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	if s.state.readProxyClient != nil {
+		return errReadProxyClientHasAlreadyBeenSet
+	}
+
+	s.state.readProxyClient = value
+	return nil
+}
+
+func (s *service) SetBootstrapVerifyError(err error) {
+	s.state.Lock()
+	s.state.bootstrapVerifyErr = err
+	s.state.Unlock()
+}
+
+func (s *service) Drain(timeout time.Duration) bool {
+	deadline := s.nowFn().Add(timeout)
+	for atomic.LoadInt64(&s.inflightRPCs) > 0 {
+		if !s.nowFn().Before(deadline) {
+			return false
+		}
+		time.Sleep(drainPollInterval)
+	}
+	return true
+}
+
 func (s *service) startWriteRPCWithDB() (storage.Database, error) {
 	if s.state.maxOutstandingWriteRPCs == 0 {
 		// No limitations on number of outstanding requests.
-		return s.startRPCWithDB()
+		db, err := s.startRPCWithDB()
+		if err == nil {
+			atomic.AddInt64(&s.inflightRPCs, 1)
+		}
+		return db, err
 	}
 
 	db, dbIsInitialized, requestDoesNotExceedLimit := s.state.DBForWriteRPCWithLimit()
@@ -1535,10 +1854,40 @@ func (s *service) startWriteRPCWithDB() (storage.Database, error) {
 		return nil, convert.ToRPCError(errServerIsOverloaded)
 	}
 
+	atomic.AddInt64(&s.inflightRPCs, 1)
 	return db, nil
 }
 
+// withWriteTimeout applies the write timeout runtime option, if set, to the
+// request context so that a write fails with a retryable error rather than
+// blocking when it cannot be enqueued to the commit log in time. The deadline
+// is checked before the series write, failing the write without applying it,
+// and by the commit log enqueue, by which point the write has been applied and
+// is readable although not durable. The returned func restores the request
+// context and must be called once the write is done.
+func (s *service) withWriteTimeout(
+	db storage.Database,
+	ctx context.Context,
+) stdctx.CancelFunc {
+	timeout := db.Options().RuntimeOptionsManager().Get().WriteTimeout()
+	if timeout <= 0 {
+		return noopCancelWriteTimeout
+	}
+
+	parent, ok := ctx.GoContext()
+	if !ok {
+		parent = stdctx.Background()
+	}
+	goCtx, cancel := stdctx.WithTimeout(parent, timeout)
+	ctx.SetGoContext(goCtx)
+	return func() {
+		cancel()
+		ctx.SetGoContext(parent)
+	}
+}
+
 func (s *service) writeRPCCompleted() {
+	atomic.AddInt64(&s.inflightRPCs, -1)
 	if s.state.maxOutstandingWriteRPCs == 0 {
 		// Nothing to do since we're not tracking the number outstanding RPCs.
 		return
@@ -1550,7 +1899,11 @@ func (s *service) writeRPCCompleted() {
 func (s *service) startReadRPCWithDB() (storage.Database, error) {
 	if s.state.maxOutstandingReadRPCs == 0 {
 		// No limitations on number of outstanding requests.
-		return s.startRPCWithDB()
+		db, err := s.startRPCWithDB()
+		if err == nil {
+			atomic.AddInt64(&s.inflightRPCs, 1)
+		}
+		return db, err
 	}
 
 	db, dbIsInitialized, requestDoesNotExceedLimit := s.state.DBForReadRPCWithLimit()
@@ -1566,10 +1919,12 @@ func (s *service) startReadRPCWithDB() (storage.Database, error) {
 		return nil, convert.ToRPCError(errServerIsOverloaded)
 	}
 
+	atomic.AddInt64(&s.inflightRPCs, 1)
 	return db, nil
 }
 
 func (s *service) readRPCCompleted() {
+	atomic.AddInt64(&s.inflightRPCs, -1)
 	if s.state.maxOutstandingReadRPCs == 0 {
 		// Nothing to do since we're not tracking the number outstanding RPCs.
 		return
@@ -1592,6 +1947,117 @@ func (s *service) startRPCWithDB() (storage.Database, error) {
 	return db, nil
 }
 
+// traceSpanOptions returns the span options to use when starting a trace
+// span for a request to the given namespace. Namespaces with a configured
+// trace sample rate override the sampling decision of the global tracer,
+// all other namespaces defer to the global tracer.
+func (s *service) traceSpanOptions(namespace string) []opentracing.StartSpanOption {
+	rate, ok := s.nsTraceSampleRates[namespace]
+	if !ok {
+		return nil
+	}
+
+	var priority uint16
+	if s.traceSampleFn() < rate {
+		priority = 1
+	}
+	return []opentracing.StartSpanOption{
+		opentracing.Tag{Key: string(ext.SamplingPriority), Value: priority},
+	}
+}
+
+// readProxySession returns the session to proxy a read to a peer with if the
+// namespace is configured to proxy reads while bootstrapping and the database
+// has not yet bootstrapped, otherwise the read is served locally.
+func (s *service) readProxySession(
+	db storage.Database,
+	namespace string,
+) (client.AdminSession, bool) {
+	if _, ok := s.readProxyNamespaces[namespace]; !ok {
+		return nil, false
+	}
+	if db.IsBootstrapped() {
+		return nil, false
+	}
+	readProxyClient, ok := s.state.ReadProxyClient()
+	if !ok {
+		return nil, false
+	}
+	session, err := readProxyClient.DefaultAdminSession()
+	if err != nil {
+		s.logger.Warn("unable to proxy read while bootstrapping, serving locally",
+			zap.String("namespace", namespace), zap.Error(err))
+		return nil, false
+	}
+	return session, true
+}
+
+func (s *service) proxyFetch(
+	tctx thrift.Context,
+	session client.AdminSession,
+	req *rpc.FetchRequest,
+) (*rpc.FetchResult_, error) {
+	callStart := s.nowFn()
+	s.metrics.readProxied.Inc(1)
+
+	topoMap, err := session.TopologyMap()
+	if err != nil {
+		s.metrics.fetch.ReportError(s.nowFn().Sub(callStart))
+		return nil, convert.ToRPCError(err)
+	}
+
+	var (
+		shard    = topoMap.ShardSet().Lookup(ident.StringID(req.ID))
+		result   *rpc.FetchResult_
+		fetchErr error
+	)
+	err = session.BorrowPeerConnection([]uint32{shard}, func(c rpc.TChanNode) {
+		result, fetchErr = c.Fetch(tctx, req)
+	})
+	if err != nil {
+		s.metrics.fetch.ReportError(s.nowFn().Sub(callStart))
+		return nil, convert.ToRPCError(err)
+	}
+	if fetchErr != nil {
+		s.metrics.fetch.ReportError(s.nowFn().Sub(callStart))
+		return nil, fetchErr
+	}
+
+	s.metrics.fetch.ReportSuccess(s.nowFn().Sub(callStart))
+	return result, nil
+}
+
+func (s *service) proxyFetchTagged(
+	tctx thrift.Context,
+	session client.AdminSession,
+	db storage.Database,
+	req *rpc.FetchTaggedRequest,
+) (*rpc.FetchTaggedResult_, error) {
+	callStart := s.nowFn()
+	s.metrics.readProxied.Inc(1)
+
+	var (
+		result   *rpc.FetchTaggedResult_
+		fetchErr error
+	)
+	// NB: proxy to the peer that has the most of the shards owned by this
+	// node available so the result most closely matches a local read.
+	err := session.BorrowPeerConnection(db.ShardSet().AllIDs(), func(c rpc.TChanNode) {
+		result, fetchErr = c.FetchTagged(tctx, req)
+	})
+	if err != nil {
+		s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
+		return nil, convert.ToRPCError(err)
+	}
+	if fetchErr != nil {
+		s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
+		return nil, fetchErr
+	}
+
+	s.metrics.fetchTagged.ReportSuccess(s.nowFn().Sub(callStart))
+	return result, nil
+}
+
 func (s *service) newID(ctx context.Context, id []byte) ident.ID {
 	checkedBytes := s.pools.checkedBytesWrapper.Get(id)
 	return s.pools.id.GetBinaryID(ctx, checkedBytes)
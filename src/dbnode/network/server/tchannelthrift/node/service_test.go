@@ -29,6 +29,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/cluster/shard"
+	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/generated/thrift/rpc"
@@ -36,7 +38,9 @@ import (
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift"
 	"github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/convert"
 	tterrors "github.com/m3db/m3/src/dbnode/network/server/tchannelthrift/errors"
+	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
 	"github.com/m3db/m3/src/dbnode/runtime"
+	"github.com/m3db/m3/src/dbnode/sharding"
 	"github.com/m3db/m3/src/dbnode/storage"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/index"
@@ -46,16 +50,22 @@ import (
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/m3ninx/idx"
 	"github.com/m3db/m3/src/x/checked"
+	xcontext "github.com/m3db/m3/src/x/context"
 	"github.com/m3db/m3/src/x/ident"
+	"github.com/m3db/m3/src/x/instrument"
 	"github.com/m3db/m3/src/x/serialize"
 	xtime "github.com/m3db/m3/src/x/time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 	"github.com/uber/tchannel-go/thrift"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // Create opts once to avoid recreating a lot of default pools, etc
@@ -128,6 +138,99 @@ func TestServiceBootstrapped(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestServiceBootstrappedBootstrapVerifyError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsBootstrappedAndDurable().Return(true).AnyTimes()
+	mockDB.EXPECT().FailedBootstrapShards().Return(nil).AnyTimes()
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	// Should return an error when bootstrap verification found gaps
+	service.SetBootstrapVerifyError(errors.New("shards not bootstrapped"))
+	tctx, _ := thrift.NewContext(time.Minute)
+	_, err := service.Bootstrapped(tctx)
+	require.Error(t, err)
+
+	// Should not return an error once the verification error is cleared
+	service.SetBootstrapVerifyError(nil)
+	tctx, _ = thrift.NewContext(time.Minute)
+	_, err = service.Bootstrapped(tctx)
+	require.NoError(t, err)
+}
+
+func TestServiceBootstrappedFailedBootstrapShards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsBootstrappedAndDurable().Return(true).AnyTimes()
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	// Should return an error while shards that failed to bootstrap are retried
+	mockDB.EXPECT().FailedBootstrapShards().Return(map[string][]uint32{"ns": []uint32{1}})
+	tctx, _ := thrift.NewContext(time.Minute)
+	_, err := service.Bootstrapped(tctx)
+	require.Error(t, err)
+
+	// Should not return an error once the retry succeeds
+	mockDB.EXPECT().FailedBootstrapShards().Return(nil)
+	tctx, _ = thrift.NewContext(time.Minute)
+	_, err = service.Bootstrapped(tctx)
+	require.NoError(t, err)
+}
+
+func TestServiceBootstrappedReadinessGracePeriod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bootstrapped := true
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsBootstrappedAndDurable().DoAndReturn(func() bool {
+		return bootstrapped
+	}).AnyTimes()
+	mockDB.EXPECT().FailedBootstrapShards().Return(nil).AnyTimes()
+
+	opts := testTChannelThriftOptions.SetBootstrappedReadinessGracePeriod(time.Minute)
+	service := NewService(mockDB, opts).(*service)
+	now := time.Now()
+	service.nowFn = func() time.Time {
+		return now
+	}
+
+	// Should return an error until bootstrapped for the grace period
+	tctx, _ := thrift.NewContext(time.Minute)
+	_, err := service.Bootstrapped(tctx)
+	require.Error(t, err)
+
+	now = now.Add(30 * time.Second)
+	_, err = service.Bootstrapped(tctx)
+	require.Error(t, err)
+
+	now = now.Add(30 * time.Second)
+	_, err = service.Bootstrapped(tctx)
+	require.NoError(t, err)
+
+	// Should restart the grace period after no longer being bootstrapped
+	bootstrapped = false
+	_, err = service.Bootstrapped(tctx)
+	require.Error(t, err)
+
+	bootstrapped = true
+	_, err = service.Bootstrapped(tctx)
+	require.Error(t, err)
+
+	now = now.Add(time.Minute)
+	_, err = service.Bootstrapped(tctx)
+	require.NoError(t, err)
+}
+
 func TestServiceBootstrappedInPlacementOrNoPlacement(t *testing.T) {
 	type TopologyIsSetResult struct {
 		result bool
@@ -196,6 +299,7 @@ func TestServiceBootstrappedInPlacementOrNoPlacement(t *testing.T) {
 				if r := test.bootstrappedAndDurable; r != nil {
 					mockDB.EXPECT().IsBootstrappedAndDurable().Return(r.result)
 				}
+				mockDB.EXPECT().FailedBootstrapShards().Return(nil).AnyTimes()
 				db = mockDB
 			}
 
@@ -1230,6 +1334,7 @@ func TestServiceFetchBlocksMetadataEndpointV2Raw(t *testing.T) {
 		IncludeSizes:     includeSizes,
 		IncludeChecksums: includeChecksums,
 		IncludeLastRead:  includeLastRead,
+		LazyTags:         true,
 	}
 	mockDB.EXPECT().
 		FetchBlocksMetadataV2(ctx, ident.NewIDMatcher(nsID), uint32(0), start, end,
@@ -1506,6 +1611,111 @@ func TestServiceFetchTagged(t *testing.T) {
 	assert.Equal(t, "root", spans[1].OperationName)
 }
 
+func TestServiceTraceSpanOptionsNamespaceSampleRates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+
+	opts := testTChannelThriftOptions.
+		SetNamespaceTraceSampleRates(map[string]float64{
+			"sampled":   0.5,
+			"unsampled": 0,
+		})
+	service := NewService(mockDB, opts).(*service)
+	service.traceSampleFn = func() float64 { return 0.25 }
+
+	samplingPriority := func(opts []opentracing.StartSpanOption) uint16 {
+		require.Equal(t, 1, len(opts))
+		tag, ok := opts[0].(opentracing.Tag)
+		require.True(t, ok)
+		require.Equal(t, string(ext.SamplingPriority), tag.Key)
+		return tag.Value.(uint16)
+	}
+
+	// Namespaces without an override defer to the global tracer.
+	require.Nil(t, service.traceSpanOptions("metrics"))
+
+	// Namespaces with an override force the sampling decision.
+	require.Equal(t, uint16(1), samplingPriority(service.traceSpanOptions("sampled")))
+	require.Equal(t, uint16(0), samplingPriority(service.traceSpanOptions("unsampled")))
+
+	service.traceSampleFn = func() float64 { return 0.75 }
+	require.Equal(t, uint16(0), samplingPriority(service.traceSpanOptions("sampled")))
+}
+
+func TestServiceFetchReadProxyWhileBootstrapping(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false).AnyTimes()
+
+	opts := testTChannelThriftOptions.
+		SetReadProxyWhileBootstrappingNamespaces([]string{"proxied"})
+	service := NewService(mockDB, opts).(*service)
+
+	shardSet, err := sharding.NewShardSet(
+		sharding.NewShards([]uint32{0, 1}, shard.Available),
+		sharding.DefaultHashFn(2))
+	require.NoError(t, err)
+
+	topoMap := topology.NewMockMap(ctrl)
+	topoMap.EXPECT().ShardSet().Return(shardSet)
+
+	mockSession := client.NewMockAdminSession(ctrl)
+	mockSession.EXPECT().TopologyMap().Return(topoMap, nil)
+
+	mockClient := client.NewMockAdminClient(ctrl)
+	mockClient.EXPECT().DefaultAdminSession().Return(mockSession, nil)
+	require.NoError(t, service.SetReadProxyClient(mockClient))
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	req := &rpc.FetchRequest{
+		RangeStart:     0,
+		RangeEnd:       10,
+		RangeType:      rpc.TimeType_UNIX_SECONDS,
+		NameSpace:      "proxied",
+		ID:             "foo",
+		ResultTimeType: rpc.TimeType_UNIX_SECONDS,
+	}
+	expected := &rpc.FetchResult_{
+		Datapoints: []*rpc.Datapoint{{Timestamp: 5, Value: 42}},
+	}
+
+	peer := rpc.NewMockTChanNode(ctrl)
+	peer.EXPECT().Fetch(tctx, req).Return(expected, nil)
+
+	expectedShard := shardSet.Lookup(ident.StringID("foo"))
+	mockSession.EXPECT().
+		BorrowPeerConnection([]uint32{expectedShard}, gomock.Any()).
+		DoAndReturn(func(_ []uint32, fn func(c rpc.TChanNode)) error {
+			fn(peer)
+			return nil
+		})
+
+	// Proxied to a peer while bootstrapping.
+	mockDB.EXPECT().IsBootstrapped().Return(false)
+	r, err := service.Fetch(tctx, req)
+	require.NoError(t, err)
+	require.Equal(t, expected, r)
+
+	// Served locally once bootstrapped.
+	mockDB.EXPECT().IsBootstrapped().Return(true)
+	mockDB.EXPECT().
+		ReadEncoded(ctx, ident.NewIDMatcher("proxied"), ident.NewIDMatcher("foo"),
+			time.Unix(0, 0), time.Unix(10, 0)).
+		Return(nil, nil)
+	r, err = service.Fetch(tctx, req)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(r.Datapoints))
+}
+
 func TestServiceFetchTaggedIsOverloaded(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1845,6 +2055,63 @@ func TestServiceWrite(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestServiceWriteTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	runtimeOptsMgr := runtime.NewOptionsManager()
+	require.NoError(t, runtimeOptsMgr.Update(
+		runtime.NewOptions().SetWriteTimeout(time.Second)))
+	storageOpts := testStorageOpts.SetRuntimeOptionsManager(runtimeOptsMgr)
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(storageOpts).AnyTimes()
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	nsID := "metrics"
+	id := "foo"
+	at := time.Now().Truncate(time.Second)
+	value := 42.42
+
+	mockDB.EXPECT().
+		Write(ctx, ident.NewIDMatcher(nsID), ident.NewIDMatcher(id), at, value,
+			xtime.Second, nil).
+		DoAndReturn(func(
+			ctx xcontext.Context,
+			_, _ ident.ID,
+			_ time.Time,
+			_ float64,
+			_ xtime.Unit,
+			_ []byte,
+		) error {
+			// The write timeout is applied to the request context.
+			goCtx, ok := ctx.GoContext()
+			require.True(t, ok)
+			deadline, ok := goCtx.Deadline()
+			require.True(t, ok)
+			require.True(t, deadline.Before(time.Now().Add(time.Second)))
+			return commitlog.ErrCommitLogWriteTimeout
+		})
+
+	mockDB.EXPECT().IsOverloaded().Return(false)
+	err := service.Write(tctx, &rpc.WriteRequest{
+		NameSpace: nsID,
+		ID:        id,
+		Datapoint: &rpc.Datapoint{
+			Timestamp:         at.Unix(),
+			TimestampTimeType: rpc.TimeType_UNIX_SECONDS,
+			Value:             value,
+		},
+	})
+	require.Error(t, err)
+	require.True(t, tterrors.IsInternalError(err.(*rpc.Error)))
+}
+
 func TestServiceWriteOverloaded(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -2359,6 +2626,81 @@ func TestServiceRepair(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestServiceQuiesceUnquiesce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false).AnyTimes()
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	mockDB.EXPECT().Quiesce().Return(nil)
+	require.NoError(t, service.Quiesce(tctx))
+
+	mockDB.EXPECT().Unquiesce().Return(nil)
+	require.NoError(t, service.Unquiesce(tctx))
+}
+
+func TestServiceWarmPostingsListCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false)
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+	startNanos, err := convert.ToValue(start, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+	endNanos, err := convert.ToValue(end, rpc.TimeType_UNIX_NANOSECONDS)
+	require.NoError(t, err)
+
+	nsID := "metrics"
+	regexpQuery, err := idx.NewRegexpQuery([]byte("foo"), []byte("b.*"))
+	require.NoError(t, err)
+	termQuery := idx.NewTermQuery([]byte("baz"), []byte("qux"))
+
+	var queries []*rpc.FetchTaggedRequest
+	for _, q := range []idx.Query{regexpQuery, termQuery} {
+		mockDB.EXPECT().QueryIDs(
+			gomock.Any(),
+			ident.NewIDMatcher(nsID),
+			index.NewQueryMatcher(index.Query{Query: q}),
+			index.QueryOptions{
+				StartInclusive: start,
+				EndExclusive:   end,
+			}).Return(index.QueryResult{}, nil)
+
+		data, err := idx.Marshal(q)
+		require.NoError(t, err)
+		queries = append(queries, &rpc.FetchTaggedRequest{
+			NameSpace:  []byte(nsID),
+			Query:      data,
+			RangeStart: startNanos,
+			RangeEnd:   endNanos,
+		})
+	}
+
+	r, err := service.WarmPostingsListCache(tctx, &rpc.WarmPostingsListCacheRequest{
+		Queries: queries,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), r.NumQueries)
+}
+
 func TestServiceTruncate(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -2384,6 +2726,56 @@ func TestServiceTruncate(t *testing.T) {
 	assert.Equal(t, truncated, r.NumSeries)
 }
 
+func TestServiceAdminAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false).AnyTimes()
+
+	var (
+		buf    bytes.Buffer
+		scope  = tally.NewTestScope("", nil)
+		logger = zap.New(zapcore.NewCore(
+			zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+			zapcore.AddSync(&buf), zap.InfoLevel))
+		opts = testTChannelThriftOptions.
+			SetInstrumentOptions(instrument.NewOptions().
+				SetLogger(logger).
+				SetMetricsScope(scope)).
+			SetAdminAuditLogEnabled(true).
+			SetAdminAuditMetricsEnabled(true)
+	)
+	service := NewService(mockDB, opts).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	mockDB.EXPECT().Truncate(ident.NewIDMatcher("metrics")).Return(int64(1), nil)
+	_, err := service.Truncate(tctx, &rpc.TruncateRequest{NameSpace: []byte("metrics")})
+	require.NoError(t, err)
+
+	mockDB.EXPECT().Quiesce().Return(nil).Times(2)
+	require.NoError(t, service.Quiesce(tctx))
+	require.NoError(t, service.Quiesce(tctx))
+
+	logged := buf.String()
+	require.Contains(t, logged, `"logger":"admin-audit"`)
+	require.Contains(t, logged, `"operation":"truncate"`)
+	require.Contains(t, logged, `"namespace":"metrics"`)
+	require.Contains(t, logged, `"operation":"quiesce"`)
+
+	counters := scope.Snapshot().Counters()
+	truncates := counters["service.admin-audit.invoked+operation=truncate,service-name=node"]
+	require.NotNil(t, truncates)
+	require.Equal(t, int64(1), truncates.Value())
+	quiesces := counters["service.admin-audit.invoked+operation=quiesce,service-name=node"]
+	require.NotNil(t, quiesces)
+	require.Equal(t, int64(2), quiesces.Value())
+}
+
 func TestServiceSetPersistRateLimit(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -2517,3 +2909,31 @@ func TestServiceSetWriteNewSeriesLimitPerShardPerSecond(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, int64(84), setResp.WriteNewSeriesLimitPerShardPerSecond)
 }
+
+func TestServiceDrain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testStorageOpts).AnyTimes()
+	mockDB.EXPECT().IsOverloaded().Return(false).AnyTimes()
+
+	service := NewService(mockDB, testTChannelThriftOptions).(*service)
+
+	// Nothing in flight drains immediately.
+	require.True(t, service.Drain(0))
+
+	_, err := service.startWriteRPCWithDB()
+	require.NoError(t, err)
+	_, err = service.startReadRPCWithDB()
+	require.NoError(t, err)
+
+	// Times out while RPCs are in flight.
+	require.False(t, service.Drain(2*drainPollInterval))
+
+	go func() {
+		service.writeRPCCompleted()
+		service.readRPCCompleted()
+	}()
+	require.True(t, service.Drain(time.Minute))
+}
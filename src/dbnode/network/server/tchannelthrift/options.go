@@ -21,6 +21,8 @@
 package tchannelthrift
 
 import (
+	"time"
+
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3/src/x/ident"
@@ -40,6 +42,11 @@ type options struct {
 	tagDecoderPool              serialize.TagDecoderPool
 	maxOutstandingWriteRequests int
 	maxOutstandingReadRequests  int
+	nsTraceSampleRates          map[string]float64
+	readProxyNamespaces         []string
+	readinessGracePeriod        time.Duration
+	adminAuditLogEnabled        bool
+	adminAuditMetricsEnabled    bool
 }
 
 // NewOptions creates new options
@@ -171,3 +178,53 @@ func (o *options) SetMaxOutstandingReadRequests(value int) Options {
 func (o *options) MaxOutstandingReadRequests() int {
 	return o.maxOutstandingReadRequests
 }
+
+func (o *options) SetNamespaceTraceSampleRates(value map[string]float64) Options {
+	opts := *o
+	opts.nsTraceSampleRates = value
+	return &opts
+}
+
+func (o *options) NamespaceTraceSampleRates() map[string]float64 {
+	return o.nsTraceSampleRates
+}
+
+func (o *options) SetReadProxyWhileBootstrappingNamespaces(value []string) Options {
+	opts := *o
+	opts.readProxyNamespaces = value
+	return &opts
+}
+
+func (o *options) ReadProxyWhileBootstrappingNamespaces() []string {
+	return o.readProxyNamespaces
+}
+
+func (o *options) SetBootstrappedReadinessGracePeriod(value time.Duration) Options {
+	opts := *o
+	opts.readinessGracePeriod = value
+	return &opts
+}
+
+func (o *options) BootstrappedReadinessGracePeriod() time.Duration {
+	return o.readinessGracePeriod
+}
+
+func (o *options) SetAdminAuditLogEnabled(value bool) Options {
+	opts := *o
+	opts.adminAuditLogEnabled = value
+	return &opts
+}
+
+func (o *options) AdminAuditLogEnabled() bool {
+	return o.adminAuditLogEnabled
+}
+
+func (o *options) SetAdminAuditMetricsEnabled(value bool) Options {
+	opts := *o
+	opts.adminAuditMetricsEnabled = value
+	return &opts
+}
+
+func (o *options) AdminAuditMetricsEnabled() bool {
+	return o.adminAuditMetricsEnabled
+}
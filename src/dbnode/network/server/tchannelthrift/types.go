@@ -21,6 +21,8 @@
 package tchannelthrift
 
 import (
+	"time"
+
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3/src/x/ident"
@@ -93,4 +95,46 @@ type Options interface {
 	// MaxOutstandingReadRequests returns the maxinum number of allowed
 	// outstanding read requests.
 	MaxOutstandingReadRequests() int
+
+	// SetNamespaceTraceSampleRates sets the per namespace trace sample rates
+	// which override the sampling of the global tracer for requests to
+	// those namespaces.
+	SetNamespaceTraceSampleRates(value map[string]float64) Options
+
+	// NamespaceTraceSampleRates returns the per namespace trace sample rates
+	// which override the sampling of the global tracer for requests to
+	// those namespaces.
+	NamespaceTraceSampleRates() map[string]float64
+
+	// SetReadProxyWhileBootstrappingNamespaces sets the namespaces for which
+	// reads are proxied to a peer while the node is bootstrapping.
+	SetReadProxyWhileBootstrappingNamespaces(value []string) Options
+
+	// ReadProxyWhileBootstrappingNamespaces returns the namespaces for which
+	// reads are proxied to a peer while the node is bootstrapping.
+	ReadProxyWhileBootstrappingNamespaces() []string
+
+	// SetBootstrappedReadinessGracePeriod sets the period the node must remain
+	// bootstrapped before the bootstrapped readiness checks report success.
+	SetBootstrappedReadinessGracePeriod(value time.Duration) Options
+
+	// BootstrappedReadinessGracePeriod returns the period the node must remain
+	// bootstrapped before the bootstrapped readiness checks report success.
+	BootstrappedReadinessGracePeriod() time.Duration
+
+	// SetAdminAuditLogEnabled sets whether an audit log entry is emitted each
+	// time an administrative RPC is invoked.
+	SetAdminAuditLogEnabled(value bool) Options
+
+	// AdminAuditLogEnabled returns whether an audit log entry is emitted each
+	// time an administrative RPC is invoked.
+	AdminAuditLogEnabled() bool
+
+	// SetAdminAuditMetricsEnabled sets whether a counter per operation is
+	// incremented each time an administrative RPC is invoked.
+	SetAdminAuditMetricsEnabled(value bool) Options
+
+	// AdminAuditMetricsEnabled returns whether a counter per operation is
+	// incremented each time an administrative RPC is invoked.
+	AdminAuditMetricsEnabled() bool
 }
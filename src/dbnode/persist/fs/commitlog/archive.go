@@ -0,0 +1,515 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package commitlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
+	"github.com/m3db/m3/src/dbnode/persist/schema"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/x/checked"
+	"github.com/m3db/m3/src/x/context"
+	"github.com/m3db/m3/src/x/ident"
+	"github.com/m3db/m3/src/x/serialize"
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+const (
+	// archiveVersion is the version of the archive format written by
+	// ExportArchive, it is bumped on any incompatible format change.
+	archiveVersion = 1
+
+	// archiveMaxMetadataLen bounds the archive metadata size to guard
+	// against allocating huge buffers when reading a corrupt archive.
+	archiveMaxMetadataLen = 64 * 1024 * 1024
+
+	// archiveQueueFullBackoff is how long an import waits before retrying
+	// a write rejected because the commit log queue is full.
+	archiveQueueFullBackoff = time.Millisecond
+)
+
+var (
+	archiveMagic = []byte("m3clarch")
+
+	errArchiveInvalidMagic        = errors.New("commit log archive has invalid magic header")
+	errArchiveMetadataTooLarge    = errors.New("commit log archive metadata exceeds max size")
+	errArchiveTruncated           = errors.New("commit log archive is truncated")
+	errArchiveChecksumMismatch    = errors.New("commit log archive entry checksum mismatch")
+	errArchiveMissingMetadata     = errors.New("commit log archive entry missing series metadata")
+	errArchiveNoNamespaces        = errors.New("commit log archive requires at least one namespace")
+	errArchiveTagEncoderNoData    = errors.New("commit log archive tag encoder data not available")
+	errArchiveUnknownNamespaceFmt = "commit log archive contains series for unknown namespace: %s"
+)
+
+// ArchiveNamespace describes a namespace whose writes are contained in a
+// commit log archive, it lets the importing node verify the namespace is
+// configured compatibly before replaying the writes.
+type ArchiveNamespace struct {
+	ID              string        `json:"id"`
+	BlockSize       time.Duration `json:"blockSize"`
+	RetentionPeriod time.Duration `json:"retentionPeriod"`
+}
+
+// ArchiveMetadata is the self describing header of a commit log archive.
+type ArchiveMetadata struct {
+	Version    int                `json:"version"`
+	CreatedAt  time.Time          `json:"createdAt"`
+	Namespaces []ArchiveNamespace `json:"namespaces"`
+}
+
+// ArchiveStats describes the writes exported to or imported from an archive.
+type ArchiveStats struct {
+	NumSeries     int
+	NumDatapoints int
+}
+
+// ExportArchive reads the commit log files selected by the iterator options
+// and writes the writes belonging to the given namespaces to a single
+// portable archive, returning any corrupt commit log files skipped.
+func ExportArchive(
+	w io.Writer,
+	iterOpts IteratorOpts,
+	namespaces []ArchiveNamespace,
+) (ArchiveStats, []ErrorWithPath, error) {
+	if len(namespaces) == 0 {
+		return ArchiveStats{}, nil, errArchiveNoNamespaces
+	}
+
+	archived := make(map[string]struct{}, len(namespaces))
+	for _, ns := range namespaces {
+		archived[ns.ID] = struct{}{}
+	}
+	seriesPred := iterOpts.SeriesFilterPredicate
+	iterOpts.SeriesFilterPredicate = func(id ident.ID, namespace ident.ID) bool {
+		if _, ok := archived[namespace.String()]; !ok {
+			return false
+		}
+		return seriesPred == nil || seriesPred(id, namespace)
+	}
+	if iterOpts.FileFilterPredicate == nil {
+		iterOpts.FileFilterPredicate = ReadAllPredicate()
+	}
+
+	iter, corruptFiles, err := NewIterator(iterOpts)
+	if err != nil {
+		return ArchiveStats{}, nil, err
+	}
+	defer iter.Close()
+
+	opts := iterOpts.CommitLogOptions
+	aw := newArchiveWriter(w, opts)
+	if err := aw.writeMetadata(ArchiveMetadata{
+		Version:    archiveVersion,
+		CreatedAt:  opts.ClockOptions().NowFn()(),
+		Namespaces: namespaces,
+	}); err != nil {
+		return ArchiveStats{}, corruptFiles, err
+	}
+
+	for iter.Next() {
+		series, dp, unit, annotation := iter.Current()
+		if err := aw.write(series, dp, unit, annotation); err != nil {
+			return ArchiveStats{}, corruptFiles, err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return ArchiveStats{}, corruptFiles, err
+	}
+
+	if err := aw.close(); err != nil {
+		return ArchiveStats{}, corruptFiles, err
+	}
+	return aw.stats, corruptFiles, nil
+}
+
+// ImportArchive replays every write in the archive into the commit log so
+// the writes are loaded by the commit log bootstrapper the next time the
+// node bootstraps, the archive namespaces must all be in the given set.
+func ImportArchive(
+	r ArchiveReader,
+	commitLog CommitLog,
+	namespaces []ident.ID,
+) (ArchiveStats, error) {
+	known := make(map[string]struct{}, len(namespaces))
+	for _, ns := range namespaces {
+		known[ns.String()] = struct{}{}
+	}
+	for _, ns := range r.Metadata().Namespaces {
+		if _, ok := known[ns.ID]; !ok {
+			return ArchiveStats{}, fmt.Errorf(errArchiveUnknownNamespaceFmt, ns.ID)
+		}
+	}
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	var (
+		stats ArchiveStats
+		seen  = make(map[uint64]struct{})
+	)
+	for r.Next() {
+		series, dp, unit, annotation := r.Current()
+		for {
+			err := commitLog.Write(ctx, series, dp, unit, annotation)
+			if err == ErrCommitLogQueueFull {
+				time.Sleep(archiveQueueFullBackoff)
+				continue
+			}
+			if err != nil {
+				return stats, err
+			}
+			break
+		}
+		if _, ok := seen[series.UniqueIndex]; !ok {
+			seen[series.UniqueIndex] = struct{}{}
+			stats.NumSeries++
+		}
+		stats.NumDatapoints++
+	}
+	return stats, r.Err()
+}
+
+type archiveWriter struct {
+	w            *bufio.Writer
+	opts         Options
+	indexes      map[string]uint64
+	sizeBuffer   []byte
+	entryBuff    []byte
+	metadataBuff []byte
+	tagEncoder   serialize.TagEncoder
+	tagSliceIter ident.TagsIterator
+	stats        ArchiveStats
+}
+
+func newArchiveWriter(w io.Writer, opts Options) *archiveWriter {
+	return &archiveWriter{
+		w:            bufio.NewWriterSize(w, opts.FlushSize()),
+		opts:         opts,
+		indexes:      make(map[string]uint64),
+		sizeBuffer:   make([]byte, binary.MaxVarintLen64+4),
+		entryBuff:    make([]byte, 0, defaultEncoderBuffSize),
+		metadataBuff: make([]byte, 0, defaultEncoderBuffSize),
+		tagEncoder:   opts.FilesystemOptions().TagEncoderPool().Get(),
+		tagSliceIter: ident.NewTagsIterator(ident.Tags{}),
+	}
+}
+
+func (w *archiveWriter) writeMetadata(metadata ArchiveMetadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	if _, err := w.w.Write(archiveMagic); err != nil {
+		return err
+	}
+	n := binary.PutUvarint(w.sizeBuffer, uint64(len(data)))
+	if _, err := w.w.Write(w.sizeBuffer[:n]); err != nil {
+		return err
+	}
+	_, err = w.w.Write(data)
+	return err
+}
+
+func (w *archiveWriter) write(
+	series ts.Series,
+	datapoint ts.Datapoint,
+	unit xtime.Unit,
+	annotation ts.Annotation,
+) error {
+	// NB: Unique indexes are only unique per commit log file, so series are
+	// assigned an archive wide index and their metadata is written with the
+	// first entry seen for the series.
+	var (
+		key     = series.Namespace.String() + "/" + series.ID.String()
+		entry   schema.LogEntry
+		idx, ok = w.indexes[key]
+		err     error
+	)
+	if !ok {
+		idx = uint64(len(w.indexes))
+		w.indexes[key] = idx
+		w.metadataBuff, err = w.encodeMetadata(series)
+		if err != nil {
+			return err
+		}
+		entry.Metadata = w.metadataBuff
+		w.stats.NumSeries++
+	}
+
+	entry.Index = idx
+	entry.Create = w.opts.ClockOptions().NowFn()().UnixNano()
+	entry.Timestamp = datapoint.Timestamp.UnixNano()
+	entry.Value = datapoint.Value
+	entry.Unit = uint32(unit)
+	entry.Annotation = annotation
+
+	w.entryBuff, err = msgpack.EncodeLogEntryFast(w.entryBuff[:0], entry)
+	if err != nil {
+		return err
+	}
+	if err := w.writeEntry(w.entryBuff); err != nil {
+		return err
+	}
+	w.stats.NumDatapoints++
+	return nil
+}
+
+func (w *archiveWriter) encodeMetadata(series ts.Series) ([]byte, error) {
+	var encodedTags []byte
+	if series.Tags.Values() != nil {
+		w.tagSliceIter.Reset(series.Tags)
+		w.tagEncoder.Reset()
+		if err := w.tagEncoder.Encode(w.tagSliceIter); err != nil {
+			return nil, err
+		}
+		data, ok := w.tagEncoder.Data()
+		if !ok {
+			return nil, errArchiveTagEncoderNoData
+		}
+		encodedTags = data.Bytes()
+	}
+
+	return msgpack.EncodeLogMetadataFast(w.metadataBuff[:0], schema.LogMetadata{
+		ID:          series.ID.Bytes(),
+		Namespace:   series.Namespace.Bytes(),
+		Shard:       series.Shard,
+		EncodedTags: encodedTags,
+	})
+}
+
+func (w *archiveWriter) writeEntry(data []byte) error {
+	n := binary.PutUvarint(w.sizeBuffer, uint64(len(data)))
+	binary.BigEndian.PutUint32(w.sizeBuffer[n:], digest.Checksum(data))
+	if _, err := w.w.Write(w.sizeBuffer[:n+4]); err != nil {
+		return err
+	}
+	_, err := w.w.Write(data)
+	return err
+}
+
+func (w *archiveWriter) close() error {
+	// A zero length entry marks the end of the archive so that truncated
+	// archives are detected on import.
+	n := binary.PutUvarint(w.sizeBuffer, 0)
+	if _, err := w.w.Write(w.sizeBuffer[:n]); err != nil {
+		return err
+	}
+	w.tagEncoder.Finalize()
+	return w.w.Flush()
+}
+
+type archiveReader struct {
+	r        *bufio.Reader
+	opts     Options
+	metadata ArchiveMetadata
+	series   map[uint64]ts.Series
+
+	entryBuff     []byte
+	checksumBuff  []byte
+	tagDecoder    serialize.TagDecoder
+	tagDecoderBuf checked.Bytes
+
+	current    ts.Series
+	datapoint  ts.Datapoint
+	unit       xtime.Unit
+	annotation ts.Annotation
+	done       bool
+	err        error
+}
+
+// NewArchiveReader returns a reader for a commit log archive written by
+// ExportArchive, the archive metadata is read and validated eagerly.
+func NewArchiveReader(r io.Reader, opts Options) (ArchiveReader, error) {
+	tagDecoderBuf := checked.NewBytes(nil, nil)
+	tagDecoderBuf.IncRef()
+	ar := &archiveReader{
+		r:             bufio.NewReaderSize(r, opts.FlushSize()),
+		opts:          opts,
+		series:        make(map[uint64]ts.Series),
+		entryBuff:     make([]byte, 0, defaultEncoderBuffSize),
+		checksumBuff:  make([]byte, 4),
+		tagDecoder:    opts.FilesystemOptions().TagDecoderPool().Get(),
+		tagDecoderBuf: tagDecoderBuf,
+	}
+	if err := ar.readMetadata(); err != nil {
+		ar.Close()
+		return nil, err
+	}
+	return ar, nil
+}
+
+func (r *archiveReader) Metadata() ArchiveMetadata {
+	return r.metadata
+}
+
+func (r *archiveReader) Next() bool {
+	if r.done || r.err != nil {
+		return false
+	}
+	if err := r.readEntry(); err != nil {
+		r.err = err
+		return false
+	}
+	return !r.done
+}
+
+func (r *archiveReader) Current() (ts.Series, ts.Datapoint, xtime.Unit, ts.Annotation) {
+	return r.current, r.datapoint, r.unit, r.annotation
+}
+
+func (r *archiveReader) Err() error {
+	return r.err
+}
+
+func (r *archiveReader) Close() {
+	if r.tagDecoder != nil {
+		r.tagDecoder.Close()
+		r.tagDecoder = nil
+	}
+}
+
+func (r *archiveReader) readMetadata() error {
+	magic := make([]byte, len(archiveMagic))
+	if _, err := io.ReadFull(r.r, magic); err != nil {
+		return err
+	}
+	if !bytes.Equal(magic, archiveMagic) {
+		return errArchiveInvalidMagic
+	}
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return err
+	}
+	if size > archiveMaxMetadataLen {
+		return errArchiveMetadataTooLarge
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &r.metadata); err != nil {
+		return err
+	}
+	if r.metadata.Version != archiveVersion {
+		return fmt.Errorf("commit log archive version %d not supported, expected %d",
+			r.metadata.Version, archiveVersion)
+	}
+	return nil
+}
+
+func (r *archiveReader) readEntry() error {
+	size, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return errArchiveTruncated
+	}
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		r.done = true
+		return nil
+	}
+	if _, err := io.ReadFull(r.r, r.checksumBuff); err != nil {
+		return archiveReadErr(err)
+	}
+	r.entryBuff = resizeBufferOrGrowIfNeeded(r.entryBuff, int(size))
+	if _, err := io.ReadFull(r.r, r.entryBuff); err != nil {
+		return archiveReadErr(err)
+	}
+	if digest.Checksum(r.entryBuff) != binary.BigEndian.Uint32(r.checksumBuff) {
+		return errArchiveChecksumMismatch
+	}
+
+	entry, err := msgpack.DecodeLogEntryFast(r.entryBuff)
+	if err != nil {
+		return err
+	}
+	series, ok := r.series[entry.Index]
+	if !ok {
+		if len(entry.Metadata) == 0 {
+			return errArchiveMissingMetadata
+		}
+		series, err = r.decodeSeries(entry)
+		if err != nil {
+			return err
+		}
+		r.series[entry.Index] = series
+	}
+
+	r.current = series
+	r.datapoint = ts.Datapoint{
+		Timestamp: time.Unix(0, entry.Timestamp),
+		Value:     entry.Value,
+	}
+	r.unit = xtime.Unit(entry.Unit)
+	r.annotation = nil
+	if len(entry.Annotation) > 0 {
+		// Copy annotation to prevent reference to the reused entry buffer.
+		r.annotation = append(ts.Annotation(nil), entry.Annotation...)
+	}
+	return nil
+}
+
+func (r *archiveReader) decodeSeries(entry schema.LogEntry) (ts.Series, error) {
+	decoded, err := msgpack.DecodeLogMetadataFast(entry.Metadata)
+	if err != nil {
+		return ts.Series{}, err
+	}
+
+	var tags ident.Tags
+	if len(decoded.EncodedTags) != 0 {
+		r.tagDecoderBuf.Reset(decoded.EncodedTags)
+		r.tagDecoder.Reset(r.tagDecoderBuf)
+		for r.tagDecoder.Next() {
+			curr := r.tagDecoder.Current()
+			tags.Append(ident.StringTag(curr.Name.String(), curr.Value.String()))
+		}
+		if err := r.tagDecoder.Err(); err != nil {
+			return ts.Series{}, err
+		}
+	}
+
+	// NB: Series are held for the lifetime of the reader so that imports
+	// can hand them to the commit log, which writes them asynchronously.
+	return ts.Series{
+		UniqueIndex: entry.Index,
+		Namespace:   ident.BytesID(append([]byte(nil), decoded.Namespace...)),
+		ID:          ident.BytesID(append([]byte(nil), decoded.ID...)),
+		Tags:        tags,
+		Shard:       decoded.Shard,
+	}, nil
+}
+
+func archiveReadErr(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errArchiveTruncated
+	}
+	return err
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package commitlog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/x/ident"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestArchive(t *testing.T, writes []testWrite) []byte {
+	opts, scope := newTestOptions(t, overrides{
+		strategy: StrategyWriteWait,
+	})
+	defer cleanup(t, opts)
+
+	commitLog := newTestCommitLog(t, opts)
+	writeCommitLogs(t, scope, commitLog, writes).Wait()
+	require.NoError(t, commitLog.Close())
+
+	var buf bytes.Buffer
+	stats, corruptFiles, err := ExportArchive(&buf, IteratorOpts{
+		CommitLogOptions: opts,
+	}, []ArchiveNamespace{
+		{ID: "testNS", BlockSize: 2 * time.Hour, RetentionPeriod: 48 * time.Hour},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(corruptFiles))
+	require.Equal(t, ArchiveStats{NumSeries: 2, NumDatapoints: 3}, stats)
+	return buf.Bytes()
+}
+
+func TestCommitLogArchiveExportImport(t *testing.T) {
+	now := time.Now()
+	otherNS := testSeries(2, "foo.qux", ident.Tags{}, 127)
+	otherNS.Namespace = ident.StringID("otherNS")
+	writes := []testWrite{
+		{testSeries(0, "foo.bar", ident.NewTags(ident.StringTag("name1", "val1")), 127), now, 123.456, xtime.Second, []byte{1, 2, 3}, nil},
+		{testSeries(1, "foo.baz", ident.NewTags(ident.StringTag("name2", "val2")), 150), now, 456.789, xtime.Second, nil, nil},
+		{otherNS, now, 1, xtime.Second, nil, nil},
+		{testSeries(0, "foo.bar", ident.NewTags(ident.StringTag("name1", "val1")), 127), now.Add(time.Second), 789.123, xtime.Second, nil, nil},
+	}
+	archive := newTestArchive(t, writes)
+
+	// Writes for namespaces not included in the archive are skipped.
+	expected := append(append([]testWrite(nil), writes[:2]...), writes[3])
+
+	opts, _ := newTestOptions(t, overrides{
+		strategy: StrategyWriteBehind,
+	})
+	defer cleanup(t, opts)
+
+	reader, err := NewArchiveReader(bytes.NewReader(archive), opts)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	metadata := reader.Metadata()
+	require.Equal(t, archiveVersion, metadata.Version)
+	require.Equal(t, []ArchiveNamespace{
+		{ID: "testNS", BlockSize: 2 * time.Hour, RetentionPeriod: 48 * time.Hour},
+	}, metadata.Namespaces)
+
+	commitLog := newTestCommitLog(t, opts)
+	stats, err := ImportArchive(reader, commitLog, []ident.ID{ident.StringID("testNS")})
+	require.NoError(t, err)
+	require.Equal(t, ArchiveStats{NumSeries: 2, NumDatapoints: 3}, stats)
+	require.NoError(t, commitLog.Close())
+
+	assertCommitLogWritesByIterating(t, commitLog, expected)
+}
+
+func TestCommitLogArchiveImportUnknownNamespace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	writes := []testWrite{
+		{testSeries(0, "foo.bar", ident.Tags{}, 127), time.Now(), 1, xtime.Second, nil, nil},
+		{testSeries(1, "foo.baz", ident.Tags{}, 127), time.Now(), 2, xtime.Second, nil, nil},
+		{testSeries(1, "foo.baz", ident.Tags{}, 127), time.Now(), 3, xtime.Second, nil, nil},
+	}
+	archive := newTestArchive(t, writes)
+
+	reader, err := NewArchiveReader(bytes.NewReader(archive), testOpts)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	_, err = ImportArchive(reader, NewMockCommitLog(ctrl), []ident.ID{ident.StringID("otherNS")})
+	require.Error(t, err)
+}
+
+func TestCommitLogArchiveReaderTruncated(t *testing.T) {
+	writes := []testWrite{
+		{testSeries(0, "foo.bar", ident.Tags{}, 127), time.Now(), 1, xtime.Second, nil, nil},
+		{testSeries(1, "foo.baz", ident.Tags{}, 127), time.Now(), 2, xtime.Second, nil, nil},
+		{testSeries(1, "foo.baz", ident.Tags{}, 127), time.Now(), 3, xtime.Second, nil, nil},
+	}
+	archive := newTestArchive(t, writes)
+
+	// Drop the end of archive marker.
+	reader, err := NewArchiveReader(bytes.NewReader(archive[:len(archive)-1]), testOpts)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	var read int
+	for reader.Next() {
+		read++
+	}
+	require.Equal(t, 3, read)
+	require.Equal(t, errArchiveTruncated, reader.Err())
+
+	_, err = NewArchiveReader(bytes.NewReader([]byte("not an archive")), testOpts)
+	require.Equal(t, errArchiveInvalidMagic, err)
+}
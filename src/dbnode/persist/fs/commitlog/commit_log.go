@@ -33,6 +33,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/x/context"
 	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/ident"
 	xtime "github.com/m3db/m3/src/x/time"
 
 	"github.com/uber-go/tally"
@@ -44,6 +45,11 @@ var (
 	// when the queue is full
 	ErrCommitLogQueueFull = errors.New("commit log queue is full")
 
+	// ErrCommitLogWriteTimeout is raised as a retryable error when the
+	// deadline of the context used to write to the commit log is exceeded
+	// before the write could be enqueued
+	ErrCommitLogWriteTimeout = errors.New("commit log write timed out")
+
 	errCommitLogClosed = errors.New("commit log is closed")
 
 	zeroFile = persist.CommitLogFile{}
@@ -101,6 +107,8 @@ type commitLog struct {
 	writeFn              writeCommitLogFn
 	commitLogFailFn      commitLogFailFn
 
+	durableAckNamespaces map[string]struct{}
+
 	metrics commitLogMetrics
 
 	numWritesInQueue int64
@@ -137,6 +145,9 @@ type writerState struct {
 	// only be used when the order of operations does not matter.
 	writers     []commitLogWriter
 	activeFiles persist.CommitLogFiles
+	// The time at which the current primary writer became active, used to
+	// rotate the commit log when the configured rotate interval elapses.
+	primaryOpenedAt time.Time
 }
 
 type asyncResettableWriter struct {
@@ -175,12 +186,16 @@ type commitLogMetrics struct {
 	numWritesInQueue tally.Gauge
 	queueLength      tally.Gauge
 	queueCapacity    tally.Gauge
+	highWatermark    tally.Gauge
 	success          tally.Counter
 	errors           tally.Counter
 	openErrors       tally.Counter
 	closeErrors      tally.Counter
 	flushErrors      tally.Counter
 	flushDone        tally.Counter
+	segmentSize      tally.Gauge
+	rotations        tally.Counter
+	timeouts         tally.Counter
 }
 
 type eventType int
@@ -273,12 +288,16 @@ func NewCommitLog(opts Options) (CommitLog, error) {
 			numWritesInQueue: scope.Gauge("writes.queued"),
 			queueLength:      scope.Gauge("writes.queue-length"),
 			queueCapacity:    scope.Gauge("writes.queue-capacity"),
+			highWatermark:    scope.Gauge("writes.backpressure-high-watermark"),
 			success:          scope.Counter("writes.success"),
 			errors:           scope.Counter("writes.errors"),
 			openErrors:       scope.Counter("writes.open-errors"),
 			closeErrors:      scope.Counter("writes.close-errors"),
 			flushErrors:      scope.Counter("writes.flush-errors"),
 			flushDone:        scope.Counter("writes.flush-done"),
+			segmentSize:      scope.Gauge("writes.segment-size"),
+			rotations:        scope.Counter("writes.rotations"),
+			timeouts:         scope.Counter("writes.timeouts"),
 		},
 	}
 	// Setup backreferences for onFlush().
@@ -292,6 +311,13 @@ func NewCommitLog(opts Options) (CommitLog, error) {
 		commitLog.writeFn = commitLog.writeBehind
 	}
 
+	if namespaces := opts.DurableAckNamespaces(); len(namespaces) > 0 {
+		commitLog.durableAckNamespaces = make(map[string]struct{}, len(namespaces))
+		for _, ns := range namespaces {
+			commitLog.durableAckNamespaces[ns] = struct{}{}
+		}
+	}
+
 	return commitLog, nil
 }
 
@@ -416,6 +442,7 @@ func (l *commitLog) flushEvery(interval time.Duration) {
 		// item in the queue could (potentially) be a batch of many writes.
 		l.metrics.queueLength.Update(float64(len(l.writes)))
 		l.metrics.queueCapacity.Update(float64(cap(l.writes)))
+		l.metrics.highWatermark.Update(float64(l.opts.BackpressureHighWatermark()))
 
 		sleepFor := interval
 
@@ -455,6 +482,7 @@ func (l *commitLog) write() {
 	for write := range l.writes {
 		if write.eventType == flushEventType {
 			l.writerState.primary.writer.Flush(false)
+			l.maybeRotateLogs()
 			continue
 		}
 
@@ -546,6 +574,8 @@ func (l *commitLog) write() {
 
 		atomic.AddInt64(&l.numWritesInQueue, int64(-numDequeued))
 		l.metrics.success.Inc(numWritesSuccess)
+
+		l.maybeRotateLogs()
 	}
 
 	// Ensure that there is no active background goroutine in the middle of reseting
@@ -608,6 +638,45 @@ func (l *commitLog) onFlush(writer *asyncResettableWriter, err error) {
 	l.metrics.flushDone.Inc(1)
 }
 
+// maybeRotateLogs rotates the commit log if the primary writer has exceeded
+// the configured rotate size or has been active longer than the configured
+// rotate interval. It must only be called from the write() goroutine.
+func (l *commitLog) maybeRotateLogs() {
+	primary := l.writerState.primary.writer
+	if primary == nil {
+		// Can be nil if a previous attempt to open the writers failed.
+		return
+	}
+
+	size := primary.Size()
+	l.metrics.segmentSize.Update(float64(size))
+
+	var (
+		rotateSize     = int64(l.opts.RotateSize())
+		rotateInterval = l.opts.RotateInterval()
+		exceededSize   = rotateSize > 0 && size >= rotateSize
+		exceededTime   = rotateInterval > 0 &&
+			l.nowFn().Sub(l.writerState.primaryOpenedAt) >= rotateInterval
+	)
+	if !exceededSize && !exceededTime {
+		return
+	}
+
+	if _, _, err := l.openWriters(); err != nil {
+		l.metrics.errors.Inc(1)
+		l.metrics.openErrors.Inc(1)
+		l.log.Error("failed to rotate commit log", zap.Error(err))
+
+		if l.commitLogFailFn != nil {
+			l.commitLogFailFn(err)
+		}
+		return
+	}
+
+	l.metrics.rotations.Inc(1)
+	l.metrics.segmentSize.Update(float64(l.writerState.primary.writer.Size()))
+}
+
 // writerState lock must be held for the duration of this function call.
 func (l *commitLog) openWriters() (persist.CommitLogFile, persist.CommitLogFile, error) {
 	// Ensure that the previous asynchronous reset of the secondary writer (if any)
@@ -650,6 +719,7 @@ func (l *commitLog) openWriters() (persist.CommitLogFile, persist.CommitLogFile,
 		l.writerState.writers = []commitLogWriter{
 			l.writerState.primary.writer,
 			l.writerState.secondary.writer}
+		l.writerState.primaryOpenedAt = l.nowFn()
 
 		return primaryFile, secondaryFile, nil
 	}
@@ -658,6 +728,7 @@ func (l *commitLog) openWriters() (persist.CommitLogFile, persist.CommitLogFile,
 	// This consumes the standby secondary writer, but a new one will be prepared asynchronously by
 	// resetting the formerly primary writer.
 	l.writerState.primary, l.writerState.secondary = l.writerState.secondary, l.writerState.primary
+	l.writerState.primaryOpenedAt = l.nowFn()
 	l.startSecondaryWriterAsyncReset()
 
 	var (
@@ -719,7 +790,11 @@ func (l *commitLog) Write(
 	unit xtime.Unit,
 	annotation ts.Annotation,
 ) error {
-	return l.writeFn(ctx, writeOrWriteBatch{
+	writeFn := l.writeFn
+	if l.isDurableAckNamespace(series.Namespace) {
+		writeFn = l.writeWait
+	}
+	return writeFn(ctx, writeOrWriteBatch{
 		write: ts.Write{
 			Series:     series,
 			Datapoint:  datapoint,
@@ -733,11 +808,30 @@ func (l *commitLog) WriteBatch(
 	ctx context.Context,
 	writes ts.WriteBatch,
 ) error {
-	return l.writeFn(ctx, writeOrWriteBatch{
+	writeFn := l.writeFn
+	if l.durableAckNamespaces != nil {
+		for _, write := range writes.Iter() {
+			if l.isDurableAckNamespace(write.Write.Series.Namespace) {
+				writeFn = l.writeWait
+				break
+			}
+		}
+	}
+	return writeFn(ctx, writeOrWriteBatch{
 		writeBatch: writes,
 	})
 }
 
+// isDurableAckNamespace returns whether writes for the namespace should only
+// be acknowledged once they have been flushed and fsync'd to disk.
+func (l *commitLog) isDurableAckNamespace(ns ident.ID) bool {
+	if l.durableAckNamespaces == nil || ns == nil {
+		return false
+	}
+	_, ok := l.durableAckNamespaces[string(ns.Bytes())]
+	return ok
+}
+
 func (l *commitLog) writeWait(
 	ctx context.Context,
 	write writeOrWriteBatch,
@@ -788,12 +882,11 @@ func (l *commitLog) writeWait(
 	}
 
 	// Otherwise submit the write.
-	l.writes <- commitLogWrite{
-		write:      write,
-		callbackFn: completion,
-	}
-
+	err := l.enqueueWithRLock(ctx, writeToEnqueue, numToEnqueue)
 	l.closedState.RUnlock()
+	if err != nil {
+		return err
+	}
 
 	wg.Wait()
 
@@ -833,13 +926,48 @@ func (l *commitLog) writeBehind(
 	}
 
 	// Otherwise submit the write.
-	l.writes <- commitLogWrite{
+	err := l.enqueueWithRLock(ctx, commitLogWrite{
 		write: write,
+	}, numToEnqueue)
+	l.closedState.RUnlock()
+
+	return err
+}
+
+// enqueueWithRLock submits a write whose size has already been added to the
+// number of enqueued writes, if the context carries a deadline that is
+// exceeded before the write is submitted then the write is rejected.
+// closedState read lock must be held for the duration of this function call.
+func (l *commitLog) enqueueWithRLock(
+	ctx context.Context,
+	write commitLogWrite,
+	numToEnqueue int64,
+) error {
+	goCtx, ok := ctx.GoContext()
+	if !ok || goCtx.Done() == nil {
+		l.writes <- write
+		return nil
 	}
 
-	l.closedState.RUnlock()
+	// Check the deadline before attempting to submit since select chooses
+	// randomly amongst ready cases.
+	if goCtx.Err() == nil {
+		select {
+		case l.writes <- write:
+			return nil
+		case <-goCtx.Done():
+		}
+	}
 
-	return nil
+	atomic.AddInt64(&l.numWritesInQueue, -numToEnqueue)
+	if write.write.writeBatch != nil {
+		// Make sure to finalize the write batch even though we didn't accept the writes
+		// so it can be returned to the pool.
+		write.write.writeBatch.Finalize()
+	}
+	l.metrics.timeouts.Inc(1)
+
+	return xerrors.NewRetryableError(ErrCommitLogWriteTimeout)
 }
 
 func (l *commitLog) Close() error {
@@ -21,6 +21,7 @@
 package commitlog
 
 import (
+	stdctx "context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -37,6 +38,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/x/context"
+	xerrors "github.com/m3db/m3/src/x/errors"
 	"github.com/m3db/m3/src/x/ident"
 	xtime "github.com/m3db/m3/src/x/time"
 
@@ -211,6 +213,10 @@ func (w *mockCommitLogWriter) Flush(sync bool) error {
 	return w.flushFn(sync)
 }
 
+func (w *mockCommitLogWriter) Size() int64 {
+	return 0
+}
+
 func (w *mockCommitLogWriter) Close() error {
 	return w.closeFn()
 }
@@ -372,6 +378,118 @@ func TestCommitLogWrite(t *testing.T) {
 	assertCommitLogWritesByIterating(t, commitLog, writes)
 }
 
+func TestCommitLogWriteDurableAckNamespace(t *testing.T) {
+	opts, scope := newTestOptions(t, overrides{
+		strategy: StrategyWriteBehind,
+	})
+	opts = opts.SetDurableAckNamespaces([]string{"testNS"})
+	defer cleanup(t, opts)
+
+	commitLog := newTestCommitLog(t, opts)
+	require.True(t, commitLog.isDurableAckNamespace(ident.StringID("testNS")))
+	require.False(t, commitLog.isDurableAckNamespace(ident.StringID("otherNS")))
+
+	// Chunks must be fsync'd before writes are acknowledged.
+	primary := commitLog.writerState.primary.writer.(*writer)
+	require.True(t, primary.chunkWriter.(*fsChunkWriter).fsync)
+
+	writes := []testWrite{
+		{testSeries(0, "foo.bar", ident.NewTags(ident.StringTag("name1", "val1")), 127), time.Now(), 123.456, xtime.Second, nil, nil},
+	}
+
+	// Writes for the namespace wait for the flush despite the strategy.
+	writeCommitLogs(t, scope, commitLog, writes).Wait()
+
+	require.NoError(t, commitLog.Close())
+	assertCommitLogWritesByIterating(t, commitLog, writes)
+}
+
+func TestCommitLogWriteDurableAckWaitsForFsync(t *testing.T) {
+	// Disable periodic flushes so the flush is only triggered below.
+	flushInterval := time.Duration(0)
+	opts, _ := newTestOptions(t, overrides{
+		flushInterval: &flushInterval,
+		strategy:      StrategyWriteBehind,
+	})
+	opts = opts.SetDurableAckNamespaces([]string{"testNS"})
+	defer cleanup(t, opts)
+
+	commitLog := newTestCommitLog(t, opts)
+
+	// The chunk writer only calls the flush callback once fsync'd.
+	var flushed int32
+	chunkWriter := commitLog.writerState.primary.writer.(*writer).chunkWriter.(*fsChunkWriter)
+	flushFn := chunkWriter.flushFn
+	chunkWriter.flushFn = func(err error) {
+		atomic.StoreInt32(&flushed, 1)
+		flushFn(err)
+	}
+
+	var (
+		ctx             = context.NewContext()
+		series          = testSeries(0, "foo.bar", testTags1, 127)
+		dp              = ts.Datapoint{Timestamp: time.Now(), Value: 123.456}
+		flushedOnReturn int32
+		writeErr        error
+		writeDone       = make(chan struct{})
+	)
+	defer ctx.Close()
+
+	go func() {
+		writeErr = commitLog.Write(ctx, series, dp, xtime.Second, nil)
+		flushedOnReturn = atomic.LoadInt32(&flushed)
+		close(writeDone)
+	}()
+
+	select {
+	case <-writeDone:
+		require.FailNow(t, "durable ack write returned before flush")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	commitLog.writes <- commitLogWrite{eventType: flushEventType}
+
+	select {
+	case <-writeDone:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "durable ack write did not return after flush")
+	}
+	require.NoError(t, writeErr)
+	require.Equal(t, int32(1), flushedOnReturn)
+
+	require.NoError(t, commitLog.Close())
+}
+
+func TestCommitLogWriteRotatesBySize(t *testing.T) {
+	opts, scope := newTestOptions(t, overrides{
+		strategy: StrategyWriteWait,
+	})
+	opts = opts.SetRotateSize(1)
+	defer cleanup(t, opts)
+
+	commitLog := newTestCommitLog(t, opts)
+
+	writes := []testWrite{
+		{testSeries(0, "foo.bar", ident.NewTags(ident.StringTag("name1", "val1")), 127), time.Now(), 123.456, xtime.Second, nil, nil},
+		{testSeries(1, "foo.baz", ident.NewTags(ident.StringTag("name2", "val2")), 150), time.Now(), 456.789, xtime.Second, nil, nil},
+	}
+
+	// Each write exceeds the rotate size so every write triggers a rotation.
+	writeCommitLogs(t, scope, commitLog, writes).Wait()
+
+	require.NoError(t, commitLog.Close())
+
+	files, err := fs.SortedCommitLogFiles(fs.CommitLogsDirPath(opts.FilesystemOptions().FilePathPrefix()))
+	require.NoError(t, err)
+	require.True(t, len(files) > 2)
+
+	rotations, ok := snapshotCounterValue(scope, "commitlog.writes.rotations")
+	require.True(t, ok)
+	require.Equal(t, int64(len(writes)), rotations.Value())
+
+	assertCommitLogWritesByIterating(t, commitLog, writes)
+}
+
 func TestReadCommitLogMissingMetadata(t *testing.T) {
 	readConc := 4
 	// Make sure we're not leaking goroutines
@@ -648,6 +766,35 @@ func TestCommitLogWriteErrorOnClosed(t *testing.T) {
 	require.Equal(t, errCommitLogClosed, err)
 }
 
+func TestCommitLogWriteErrorOnTimeout(t *testing.T) {
+	opts, scope := newTestOptions(t, overrides{})
+	defer cleanup(t, opts)
+
+	commitLog := newTestCommitLog(t, opts)
+
+	series := testSeries(0, "foo.bar", testTags1, 127)
+	datapoint := ts.Datapoint{Timestamp: time.Now(), Value: 123.456}
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	// Deadline is exceeded before the write can be enqueued.
+	goCtx, cancel := stdctx.WithTimeout(stdctx.Background(), 0)
+	defer cancel()
+	ctx.SetGoContext(goCtx)
+
+	err := commitLog.Write(ctx, series, datapoint, xtime.Millisecond, nil)
+	require.True(t, xerrors.IsRetryableError(err))
+	require.Equal(t, ErrCommitLogWriteTimeout, xerrors.GetInnerRetryableError(err))
+	require.Equal(t, int64(0), commitLog.QueueLength())
+
+	timeouts, ok := snapshotCounterValue(scope, "commitlog.writes.timeouts")
+	require.True(t, ok)
+	require.Equal(t, int64(1), timeouts.Value())
+
+	require.NoError(t, commitLog.Close())
+}
+
 func TestCommitLogWriteErrorOnFull(t *testing.T) {
 	// Set backlog of size one and don't automatically flush.
 	backlogQueueSize := 1
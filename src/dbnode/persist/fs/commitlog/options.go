@@ -63,9 +63,12 @@ var (
 )
 
 var (
-	errFlushIntervalNonNegative = errors.New("flush interval must be non-negative")
-	errBlockSizePositive        = errors.New("block size must be a positive duration")
-	errReadConcurrencyPositive  = errors.New("read concurrency must be a positive integer")
+	errFlushIntervalNonNegative  = errors.New("flush interval must be non-negative")
+	errBlockSizePositive         = errors.New("block size must be a positive duration")
+	errReadConcurrencyPositive   = errors.New("read concurrency must be a positive integer")
+	errRotateSizeNonNegative     = errors.New("rotate size must be non-negative")
+	errRotateIntervalNonNegative = errors.New("rotate interval must be non-negative")
+	errBackpressureHighWatermark = errors.New("backpressure high watermark must be non-negative and at most the backlog queue size")
 )
 
 type options struct {
@@ -76,11 +79,15 @@ type options struct {
 	strategy                Strategy
 	flushSize               int
 	flushInterval           time.Duration
+	rotateSize              int
+	rotateInterval          time.Duration
 	backlogQueueSize        int
 	backlogQueueChannelSize int
 	bytesPool               pool.CheckedBytesPool
 	identPool               ident.Pool
 	readConcurrency         int
+	durableAckNamespaces    []string
+	backpressureWatermark   int
 }
 
 // NewOptions creates new commit log options
@@ -118,6 +125,19 @@ func (o *options) Validate() error {
 		return errReadConcurrencyPositive
 	}
 
+	if o.RotateSize() < 0 {
+		return errRotateSizeNonNegative
+	}
+
+	if o.RotateInterval() < 0 {
+		return errRotateIntervalNonNegative
+	}
+
+	if o.BackpressureHighWatermark() < 0 ||
+		o.BackpressureHighWatermark() > o.BacklogQueueSize() {
+		return errBackpressureHighWatermark
+	}
+
 	if float64(o.BacklogQueueSize())/float64(o.BacklogQueueChannelSize()) > MaximumQueueSizeQueueChannelSizeRatio {
 		return fmt.Errorf(
 			"BacklogQueueSize / BacklogQueueChannelSize ratio must be at most: %f, but was: %f",
@@ -197,6 +217,26 @@ func (o *options) FlushInterval() time.Duration {
 	return o.flushInterval
 }
 
+func (o *options) SetRotateSize(value int) Options {
+	opts := *o
+	opts.rotateSize = value
+	return &opts
+}
+
+func (o *options) RotateSize() int {
+	return o.rotateSize
+}
+
+func (o *options) SetRotateInterval(value time.Duration) Options {
+	opts := *o
+	opts.rotateInterval = value
+	return &opts
+}
+
+func (o *options) RotateInterval() time.Duration {
+	return o.rotateInterval
+}
+
 func (o *options) SetBacklogQueueSize(value int) Options {
 	opts := *o
 	opts.backlogQueueSize = value
@@ -246,3 +286,23 @@ func (o *options) SetIdentifierPool(value ident.Pool) Options {
 func (o *options) IdentifierPool() ident.Pool {
 	return o.identPool
 }
+
+func (o *options) SetDurableAckNamespaces(value []string) Options {
+	opts := *o
+	opts.durableAckNamespaces = value
+	return &opts
+}
+
+func (o *options) DurableAckNamespaces() []string {
+	return o.durableAckNamespaces
+}
+
+func (o *options) SetBackpressureHighWatermark(value int) Options {
+	opts := *o
+	opts.backpressureWatermark = value
+	return &opts
+}
+
+func (o *options) BackpressureHighWatermark() int {
+	return o.backpressureWatermark
+}
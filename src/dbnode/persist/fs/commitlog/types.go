@@ -99,6 +99,14 @@ type Iterator interface {
 	Close()
 }
 
+// ArchiveReader provides an iterator over the writes of a commit log archive.
+type ArchiveReader interface {
+	Iterator
+
+	// Metadata returns the archive metadata.
+	Metadata() ArchiveMetadata
+}
+
 // IteratorOpts is a struct that contains coptions for the Iterator
 type IteratorOpts struct {
 	CommitLogOptions      Options
@@ -153,6 +161,22 @@ type Options interface {
 	// FlushInterval returns the flush interval.
 	FlushInterval() time.Duration
 
+	// SetRotateSize sets the size in bytes after which the active commit log
+	// file is rotated, zero disables rotating by size.
+	SetRotateSize(value int) Options
+
+	// RotateSize returns the size in bytes after which the active commit log
+	// file is rotated, zero disables rotating by size.
+	RotateSize() int
+
+	// SetRotateInterval sets the interval after which the active commit log
+	// file is rotated, zero disables rotating by interval.
+	SetRotateInterval(value time.Duration) Options
+
+	// RotateInterval returns the interval after which the active commit log
+	// file is rotated, zero disables rotating by interval.
+	RotateInterval() time.Duration
+
 	// SetBacklogQueueSize sets the backlog queue size.
 	SetBacklogQueueSize(value int) Options
 
@@ -184,6 +208,27 @@ type Options interface {
 
 	// IdentifierPool returns the IdentifierPool to use for pooling identifiers.
 	IdentifierPool() ident.Pool
+
+	// SetDurableAckNamespaces sets the namespaces for which writes are only
+	// acknowledged once they have been flushed and fsync'd to disk,
+	// regardless of the strategy. If any are set every flush of the commit
+	// log is fsync'd, not only flushes holding writes for these namespaces.
+	SetDurableAckNamespaces(value []string) Options
+
+	// DurableAckNamespaces returns the namespaces for which writes are only
+	// acknowledged once they have been flushed and fsync'd to disk,
+	// regardless of the strategy.
+	DurableAckNamespaces() []string
+
+	// SetBackpressureHighWatermark sets the number of queued writes at which
+	// series writes are rejected with a retryable error before being applied,
+	// zero disables write backpressure.
+	SetBackpressureHighWatermark(value int) Options
+
+	// BackpressureHighWatermark returns the number of queued writes at which
+	// series writes are rejected with a retryable error before being applied,
+	// zero disables write backpressure.
+	BackpressureHighWatermark() int
 }
 
 // FileFilterInfo contains information about a commitog file that can be used to
@@ -81,6 +81,10 @@ type commitLogWriter interface {
 	// a new chunk to be created. Optionally forces the data to be FSync'd to disk.
 	Flush(sync bool) error
 
+	// Size returns the number of bytes of log entries written to the current
+	// commit log file, including bytes that are still buffered.
+	Size() int64
+
 	// Close the reader
 	Close() error
 }
@@ -112,13 +116,18 @@ type writer struct {
 	tagEncoder          serialize.TagEncoder
 	tagSliceIter        ident.TagsIterator
 	opts                Options
+	size                int64
 }
 
 func newCommitLogWriter(
 	flushFn flushFn,
 	opts Options,
 ) commitLogWriter {
-	shouldFsync := opts.Strategy() == StrategyWriteWait
+	// NB: Writes for durable ack namespaces are acknowledged on flush, so
+	// chunks must be fsync'd before the flush callback fires. The writer is
+	// shared by all namespaces so this fsyncs every flush on the node.
+	shouldFsync := opts.Strategy() == StrategyWriteWait ||
+		len(opts.DurableAckNamespaces()) > 0
 
 	return &writer{
 		filePathPrefix:      opts.FilesystemOptions().FilePathPrefix(),
@@ -180,6 +189,8 @@ func (w *writer) Open() (persist.CommitLogFile, error) {
 		w.Close()
 		return persist.CommitLogFile{}, err
 	}
+	// Only count log entries so that a freshly opened file is never rotated.
+	w.size = 0
 
 	return persist.CommitLogFile{
 		FilePath: filePath,
@@ -275,6 +286,10 @@ func (w *writer) Flush(sync bool) error {
 	return w.sync()
 }
 
+func (w *writer) Size() int64 {
+	return w.size
+}
+
 func (w *writer) sync() error {
 	return w.chunkWriter.sync()
 }
@@ -312,8 +327,11 @@ func (w *writer) write(data []byte) error {
 	if _, err := w.buffer.Write(w.sizeBuffer[:sizeLen]); err != nil {
 		return err
 	}
-	_, err := w.buffer.Write(data)
-	return err
+	if _, err := w.buffer.Write(data); err != nil {
+		return err
+	}
+	w.size += int64(totalLen)
+	return nil
 }
 
 type fsChunkWriter struct {
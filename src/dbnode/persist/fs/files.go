@@ -1612,6 +1612,41 @@ func dataFilesetPathFromTimeAndIndex(
 	return filesetPathFromTimeAndIndex(prefix, t, index, suffix)
 }
 
+// dataFileSetFilePaths returns the path of the checkpoint file and the paths
+// of every other file written for a data fileset volume.
+func dataFileSetFilePaths(
+	filePathPrefix string,
+	fileSetType persist.FileSetType,
+	id FileSetFileIdentifier,
+) (string, []string, error) {
+	var shardDir string
+	switch fileSetType {
+	case persist.FileSetFlushType:
+		shardDir = ShardDataDirPath(filePathPrefix, id.Namespace, id.Shard)
+	case persist.FileSetSnapshotType:
+		shardDir = ShardSnapshotsDirPath(filePathPrefix, id.Namespace, id.Shard)
+	default:
+		return "", nil, fmt.Errorf("unknown fileset type: %s", fileSetType)
+	}
+
+	suffixes := []string{
+		infoFileSuffix,
+		indexFileSuffix,
+		summariesFileSuffix,
+		bloomFilterFileSuffix,
+		dataFileSuffix,
+		digestFileSuffix,
+	}
+	paths := make([]string, 0, len(suffixes))
+	for _, suffix := range suffixes {
+		paths = append(paths,
+			filesetPathFromTimeAndIndex(shardDir, id.BlockStart, id.VolumeIndex, suffix))
+	}
+	checkpointPath := filesetPathFromTimeAndIndex(shardDir, id.BlockStart,
+		id.VolumeIndex, checkpointFileSuffix)
+	return checkpointPath, paths, nil
+}
+
 func filesetIndexSegmentFileSuffixFromTime(
 	t time.Time,
 	segmentIndex int,
@@ -0,0 +1,145 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/clock"
+	xerrors "github.com/m3db/m3/src/x/errors"
+
+	"github.com/uber-go/tally"
+)
+
+type fsyncFn func(path string) error
+
+// fsyncBatcher batches the fsyncs of the files written by the persist
+// manager, syncing the pending files and their parent directories once the
+// batch size is reached or the batch interval has elapsed since the last sync.
+// It is not thread-safe.
+type fsyncBatcher struct {
+	batchSize int
+	interval  time.Duration
+	nowFn     clock.NowFn
+	fsyncFn   fsyncFn
+
+	pending            []string
+	pendingCheckpoints []string
+	lastSync           time.Time
+
+	fsyncs tally.Counter
+}
+
+func newFsyncBatcher(opts Options, scope tally.Scope) *fsyncBatcher {
+	nowFn := opts.ClockOptions().NowFn()
+	return &fsyncBatcher{
+		batchSize: opts.FsyncBatchSize(),
+		interval:  opts.FsyncBatchInterval(),
+		nowFn:     nowFn,
+		fsyncFn:   fsyncPath,
+		lastSync:  nowFn(),
+		fsyncs:    scope.Counter("fsyncs"),
+	}
+}
+
+// enabled returns whether files are explicitly synced, when disabled the
+// operating system decides when written files reach disk.
+func (b *fsyncBatcher) enabled() bool {
+	return b.batchSize > 0
+}
+
+// add queues the files of a volume and its checkpoint file to be synced,
+// syncing every pending file if the batch is full or the batch interval has
+// elapsed.
+func (b *fsyncBatcher) add(checkpointPath string, paths ...string) error {
+	if !b.enabled() {
+		return nil
+	}
+
+	b.pending = append(b.pending, paths...)
+	b.pendingCheckpoints = append(b.pendingCheckpoints, checkpointPath)
+	if len(b.pending)+len(b.pendingCheckpoints) >= b.batchSize ||
+		(b.interval > 0 && b.nowFn().Sub(b.lastSync) >= b.interval) {
+		return b.sync()
+	}
+	return nil
+}
+
+// sync syncs every pending file and then each of their parent directories
+// once so that newly created files are durable. The checkpoint files are only
+// synced once the files they checkpoint are durable so that a durable
+// checkpoint file never refers to files that could still be lost.
+func (b *fsyncBatcher) sync() error {
+	if len(b.pending) == 0 && len(b.pendingCheckpoints) == 0 {
+		return nil
+	}
+
+	err := b.syncPaths(b.pending)
+	if err == nil {
+		err = b.syncPaths(b.pendingCheckpoints)
+	}
+
+	b.pending = resetPaths(b.pending)
+	b.pendingCheckpoints = resetPaths(b.pendingCheckpoints)
+	b.lastSync = b.nowFn()
+
+	return err
+}
+
+// syncPaths syncs the files and then each of their parent directories once.
+func (b *fsyncBatcher) syncPaths(paths []string) error {
+	var (
+		multiErr = xerrors.NewMultiError()
+		dirs     = make(map[string]struct{})
+	)
+	for _, path := range paths {
+		multiErr = multiErr.Add(b.fsyncFn(path))
+		dirs[filepath.Dir(path)] = struct{}{}
+	}
+	for dir := range dirs {
+		multiErr = multiErr.Add(b.fsyncFn(dir))
+	}
+	b.fsyncs.Inc(int64(len(paths) + len(dirs)))
+	return multiErr.FinalError()
+}
+
+func resetPaths(paths []string) []string {
+	for i := range paths {
+		paths[i] = ""
+	}
+	return paths[:0]
+}
+
+func fsyncPath(path string) error {
+	fd, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	if err := fd.Sync(); err != nil {
+		// NB: intentionally skipping fd.Close() error, as failure
+		// to sync takes precedence over failure to close the file
+		fd.Close()
+		return err
+	}
+	return fd.Close()
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func newTestFsyncBatcher(
+	batchSize int,
+	interval time.Duration,
+) (*fsyncBatcher, *[]string, *time.Time, tally.TestScope) {
+	var (
+		synced []string
+		now    = time.Now()
+		scope  = tally.NewTestScope("", nil)
+		opts   = testDefaultOpts.
+			SetFsyncBatchSize(batchSize).
+			SetFsyncBatchInterval(interval)
+	)
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return now
+	}))
+	b := newFsyncBatcher(opts, scope)
+	b.fsyncFn = func(path string) error {
+		synced = append(synced, path)
+		return nil
+	}
+	return b, &synced, &now, scope
+}
+
+func TestFsyncBatcherDisabledByDefault(t *testing.T) {
+	b, synced, _, _ := newTestFsyncBatcher(0, 0)
+	require.False(t, b.enabled())
+	require.NoError(t, b.add("/a/c", "/a/1", "/a/2"))
+	require.NoError(t, b.sync())
+	require.Empty(t, *synced)
+}
+
+func TestFsyncBatcherBatchSize(t *testing.T) {
+	b, synced, _, scope := newTestFsyncBatcher(5, 0)
+
+	require.NoError(t, b.add("/a/c1", "/a/1", "/a/2"))
+	require.Empty(t, *synced)
+
+	require.NoError(t, b.add("/b/c1", "/b/1"))
+	require.Equal(t, []string{"/a/1", "/a/2", "/b/1"}, (*synced)[:3])
+	require.ElementsMatch(t, []string{"/a", "/b"}, (*synced)[3:5])
+	require.Equal(t, []string{"/a/c1", "/b/c1"}, (*synced)[5:7])
+	require.ElementsMatch(t, []string{"/a", "/b"}, (*synced)[7:])
+	require.Equal(t, int64(9), scope.Snapshot().Counters()["fsyncs+"].Value())
+
+	// Remaining files are synced when the flush completes.
+	*synced = (*synced)[:0]
+	require.NoError(t, b.add("/a/c2", "/a/3"))
+	require.Empty(t, *synced)
+	require.NoError(t, b.sync())
+	require.Equal(t, []string{"/a/3", "/a", "/a/c2", "/a"}, *synced)
+}
+
+func TestFsyncBatcherInterval(t *testing.T) {
+	b, synced, now, _ := newTestFsyncBatcher(100, time.Second)
+
+	require.NoError(t, b.add("/a/c1", "/a/1"))
+	require.Empty(t, *synced)
+
+	*now = now.Add(time.Second)
+	require.NoError(t, b.add("/a/c2", "/a/2"))
+	require.Equal(t, []string{"/a/1", "/a/2", "/a", "/a/c1", "/a/c2", "/a"}, *synced)
+}
+
+func TestFsyncBatcherCheckpointsNotSyncedOnError(t *testing.T) {
+	b, _, _, _ := newTestFsyncBatcher(1, 0)
+	var synced []string
+	b.fsyncFn = func(path string) error {
+		synced = append(synced, path)
+		if path == "/a/1" {
+			return errors.New("sync failed")
+		}
+		return nil
+	}
+
+	// The checkpoint file is not synced if its data files fail to sync.
+	require.Error(t, b.add("/a/c", "/a/1"))
+	require.Equal(t, []string{"/a/1", "/a"}, synced)
+	require.Empty(t, b.pending)
+	require.Empty(t, b.pendingCheckpoints)
+}
+
+func TestFsyncBatcherReturnsErrors(t *testing.T) {
+	b, _, _, _ := newTestFsyncBatcher(1, 0)
+	b.fsyncFn = func(path string) error {
+		return errors.New("sync failed")
+	}
+	require.Error(t, b.add("/a/c", "/a/1"))
+	require.Empty(t, b.pending)
+	require.Empty(t, b.pendingCheckpoints)
+}
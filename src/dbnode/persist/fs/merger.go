@@ -174,7 +174,9 @@ func (m *merger) Merge(
 			blockSize,
 			blockAllocSize,
 			nsCtx.Schema,
-			encoderPool)
+			encoderPool,
+			// Proto encoded blocks do not benefit from time unit compaction.
+			nsOpts.CompressionLevel() == namespace.HighCompressionLevel && nsCtx.Schema == nil)
 	)
 	defer func() {
 		segReader.Finalize()
@@ -239,7 +241,14 @@ func (m *merger) Merge(
 		func(id ident.ID, tags ident.Tags, mergeWithData []xio.BlockReader) error {
 			segmentReaders = segmentReaders[:0]
 			segmentReaders = appendBlockReadersToSegmentReaders(segmentReaders, mergeWithData)
-			err := persistSegmentReaders(id, tags, segmentReaders, iterResources, prepared.Persist)
+			var err error
+			if iterResources.compact && len(segmentReaders) > 0 {
+				// Data from the merge target has not been compacted by a
+				// previous flush so is always re-encoded.
+				err = persistIter(id, tags, segmentReaders, iterResources, prepared.Persist)
+			} else {
+				err = persistSegmentReaders(id, tags, segmentReaders, iterResources, prepared.Persist)
+			}
 			// Context is safe to close after persisting data to disk.
 			tmpCtx.BlockingClose()
 			// Reset context here within the passed in function so that the
@@ -302,6 +311,14 @@ func persistIter(
 	it.Reset(segReaders, ir.blockStart, ir.blockSize, ir.schema)
 	encoder := ir.encoderPool.Get()
 	encoder.Reset(ir.blockStart, ir.blockAllocSize, ir.schema)
+	if ir.compact {
+		if err := encoding.EncodeCompacted(it, encoder); err != nil {
+			encoder.Close()
+			return err
+		}
+		return persistSegment(id, tags, encoder.Discard(), persistFn)
+	}
+
 	for it.Next() {
 		if err := encoder.Encode(it.Current()); err != nil {
 			encoder.Close()
@@ -347,6 +364,7 @@ type iterResources struct {
 	blockAllocSize int
 	schema         namespace.SchemaDescr
 	encoderPool    encoding.EncoderPool
+	compact        bool
 }
 
 func newIterResources(
@@ -356,6 +374,7 @@ func newIterResources(
 	blockAllocSize int,
 	schema namespace.SchemaDescr,
 	encoderPool encoding.EncoderPool,
+	compact bool,
 ) iterResources {
 	return iterResources{
 		multiIter:      multiIter,
@@ -364,5 +383,6 @@ func newIterResources(
 		blockAllocSize: blockAllocSize,
 		schema:         schema,
 		encoderPool:    encoderPool,
+		compact:        compact,
 	}
 }
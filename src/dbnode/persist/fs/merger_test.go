@@ -420,12 +420,68 @@ func TestMergeWithNoData(t *testing.T) {
 	testMergeWith(t, diskData, mergeTargetData, expected)
 }
 
+func TestMergeWithHighCompressionLevel(t *testing.T) {
+	// This test scenario is that data written with a finer time unit than
+	// needed is re-encoded with seconds, both for series merged with data
+	// on disk and for series only in the merge target.
+	diskData := newCheckedBytesByIDMap(newCheckedBytesByIDMapOptions{})
+	diskData.Set(id0, datapointsToCheckedBytesWithUnit(t, []ts.Datapoint{
+		{Timestamp: startTime.Add(1 * time.Second), Value: 1},
+		{Timestamp: startTime.Add(3 * time.Second), Value: 3},
+	}, xtime.Millisecond))
+
+	mergeTargetData := newCheckedBytesByIDMap(newCheckedBytesByIDMapOptions{})
+	mergeTargetData.Set(id0, datapointsToCheckedBytesWithUnit(t, []ts.Datapoint{
+		{Timestamp: startTime.Add(2 * time.Second), Value: 2},
+	}, xtime.Millisecond))
+	mergeTargetData.Set(id1, datapointsToCheckedBytesWithUnit(t, []ts.Datapoint{
+		{Timestamp: startTime.Add(4 * time.Second), Value: 4},
+		{Timestamp: startTime.Add(5 * time.Second), Value: 5},
+	}, xtime.Millisecond))
+
+	expected := newCheckedBytesByIDMap(newCheckedBytesByIDMapOptions{})
+	expected.Set(id0, datapointsToCheckedBytes(t, []ts.Datapoint{
+		{Timestamp: startTime.Add(1 * time.Second), Value: 1},
+		{Timestamp: startTime.Add(2 * time.Second), Value: 2},
+		{Timestamp: startTime.Add(3 * time.Second), Value: 3},
+	}))
+	expected.Set(id1, datapointsToCheckedBytes(t, []ts.Datapoint{
+		{Timestamp: startTime.Add(4 * time.Second), Value: 4},
+		{Timestamp: startTime.Add(5 * time.Second), Value: 5},
+	}))
+
+	nsOpts := namespace.NewOptions().SetCompressionLevel(namespace.HighCompressionLevel)
+	persisted := testMergeWithOptions(t, nsOpts, diskData, mergeTargetData, expected)
+	for _, data := range persisted {
+		segReader := srPool.Get()
+		segReader.Reset(data.segment)
+		iter := multiIterPool.Get()
+		iter.Reset([]xio.SegmentReader{segReader}, startTime, blockSize, nil)
+		for iter.Next() {
+			_, unit, _ := iter.Current()
+			require.Equal(t, xtime.Second, unit)
+		}
+		require.NoError(t, iter.Err())
+		iter.Close()
+	}
+}
+
 func testMergeWith(
 	t *testing.T,
 	diskData *checkedBytesMap,
 	mergeTargetData *checkedBytesMap,
 	expectedData *checkedBytesMap,
 ) {
+	testMergeWithOptions(t, namespace.NewOptions(), diskData, mergeTargetData, expectedData)
+}
+
+func testMergeWithOptions(
+	t *testing.T,
+	nsOpts namespace.Options,
+	diskData *checkedBytesMap,
+	mergeTargetData *checkedBytesMap,
+	expectedData *checkedBytesMap,
+) []persistedData {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	reader := mockReaderFromData(ctrl, diskData)
@@ -445,7 +501,6 @@ func testMergeWith(
 		}, nil)
 	nsCtx := namespace.Context{}
 
-	nsOpts := namespace.NewOptions()
 	merger := NewMerger(reader, 0, srPool, multiIterPool, identPool, encoderPool, nsOpts)
 	fsID := FileSetFileIdentifier{
 		Namespace:  ident.StringID("test-ns"),
@@ -457,6 +512,7 @@ func testMergeWith(
 	require.NoError(t, err)
 
 	assertPersistedAsExpected(t, persisted, expectedData)
+	return persisted
 }
 
 func assertPersistedAsExpected(
@@ -485,9 +541,17 @@ func assertPersistedAsExpected(
 }
 
 func datapointsToCheckedBytes(t *testing.T, dps []ts.Datapoint) checked.Bytes {
+	return datapointsToCheckedBytesWithUnit(t, dps, xtime.Second)
+}
+
+func datapointsToCheckedBytesWithUnit(
+	t *testing.T,
+	dps []ts.Datapoint,
+	unit xtime.Unit,
+) checked.Bytes {
 	encoder := encoderPool.Get()
 	for _, dp := range dps {
-		encoder.Encode(dp, xtime.Second, nil)
+		encoder.Encode(dp, unit, nil)
 	}
 
 	r, ok := encoder.Stream(encoding.StreamOptions{})
@@ -0,0 +1,85 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"container/list"
+	"sync"
+)
+
+// notFoundCache is a bounded LRU of the IDs that were not found in a volume
+// of a fileset despite passing its bloom filter. Bloom filters have false
+// positives so without it repeated reads of such IDs each cost a seek of
+// the index. It is tied to a single volume so it never needs invalidating,
+// a new volume starts with an empty cache.
+type notFoundCache struct {
+	sync.Mutex
+
+	size    int
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+func newNotFoundCache(size int) *notFoundCache {
+	return &notFoundCache{
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+// Contains returns whether the ID was recorded as not found.
+func (c *notFoundCache) Contains(id []byte) bool {
+	c.Lock()
+	elem, ok := c.entries[string(id)]
+	if ok {
+		c.lru.MoveToFront(elem)
+	}
+	c.Unlock()
+	return ok
+}
+
+// Add records the ID as not found, evicting the least recently used ID if
+// the cache is full.
+func (c *notFoundCache) Add(id []byte) {
+	c.Lock()
+	defer c.Unlock()
+
+	key := string(id)
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(key)
+	for c.lru.Len() > c.size {
+		back := c.lru.Back()
+		delete(c.entries, c.lru.Remove(back).(string))
+	}
+}
+
+// Len returns the number of IDs recorded as not found.
+func (c *notFoundCache) Len() int {
+	c.Lock()
+	n := c.lru.Len()
+	c.Unlock()
+	return n
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotFoundCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newNotFoundCache(2)
+
+	cache.Add([]byte("foo"))
+	cache.Add([]byte("bar"))
+	require.True(t, cache.Contains([]byte("foo")))
+
+	// Bar is now the least recently used and should be evicted.
+	cache.Add([]byte("baz"))
+	require.Equal(t, 2, cache.Len())
+	require.True(t, cache.Contains([]byte("foo")))
+	require.False(t, cache.Contains([]byte("bar")))
+	require.True(t, cache.Contains([]byte("baz")))
+
+	// Adding an existing ID does not grow the cache.
+	cache.Add([]byte("foo"))
+	require.Equal(t, 2, cache.Len())
+}
@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/persist/fs/msgpack"
@@ -66,6 +67,15 @@ const (
 	// defaultForceIndexBloomFilterMmapMemory is the default configuration for whether the bytes for the bloom filter
 	// should be mmap'd as an anonymous region (forced completely into memory) or mmap'd as a file.
 	defaultForceIndexBloomFilterMmapMemory = false
+
+	// defaultFsyncBatchSize is the default number of files to batch before
+	// syncing them, zero issues no fsyncs and leaves syncing written files to
+	// the operating system.
+	defaultFsyncBatchSize = 0
+
+	// defaultFsyncBatchInterval is the default max time between syncing
+	// batched files, zero only syncs full batches and at the end of a flush.
+	defaultFsyncBatchInterval = time.Duration(0)
 )
 
 var (
@@ -75,6 +85,7 @@ var (
 
 	errTagEncoderPoolNotSet = errors.New("tag encoder pool is not set")
 	errTagDecoderPoolNotSet = errors.New("tag decoder pool is not set")
+	errFsyncBatchNegative   = errors.New("fsync batch size and interval must not be negative")
 )
 
 type options struct {
@@ -98,6 +109,8 @@ type options struct {
 	forceIndexSummariesMmapMemory        bool
 	forceBloomFilterMmapMemory           bool
 	mmapEnableHugePages                  bool
+	fsyncBatchSize                       int
+	fsyncBatchInterval                   time.Duration
 }
 
 // NewOptions creates a new set of fs options
@@ -131,6 +144,8 @@ func NewOptions() Options {
 		tagEncoderPool:                       tagEncoderPool,
 		tagDecoderPool:                       tagDecoderPool,
 		fstOptions:                           fstOptions,
+		fsyncBatchSize:                       defaultFsyncBatchSize,
+		fsyncBatchInterval:                   defaultFsyncBatchInterval,
 	}
 }
 
@@ -151,6 +166,9 @@ func (o *options) Validate() error {
 	if o.tagDecoderPool == nil {
 		return errTagDecoderPoolNotSet
 	}
+	if o.fsyncBatchSize < 0 || o.fsyncBatchInterval < 0 {
+		return errFsyncBatchNegative
+	}
 	return nil
 }
 
@@ -353,3 +371,23 @@ func (o *options) SetFSTOptions(value fst.Options) Options {
 func (o *options) FSTOptions() fst.Options {
 	return o.fstOptions
 }
+
+func (o *options) SetFsyncBatchSize(value int) Options {
+	opts := *o
+	opts.fsyncBatchSize = value
+	return &opts
+}
+
+func (o *options) FsyncBatchSize() int {
+	return o.fsyncBatchSize
+}
+
+func (o *options) SetFsyncBatchInterval(value time.Duration) Options {
+	opts := *o
+	opts.fsyncBatchInterval = value
+	return &opts
+}
+
+func (o *options) FsyncBatchInterval() time.Duration {
+	return o.fsyncBatchInterval
+}
@@ -82,8 +82,9 @@ type persistManager struct {
 	dataPM  dataPersistManager
 	indexPM indexPersistManager
 
-	status            persistManagerStatus
-	currRateLimitOpts ratelimit.Options
+	status                     persistManagerStatus
+	currRateLimitOpts          ratelimit.Options
+	currMaxConcurrentSnapshots int
 
 	start        time.Time
 	count        int
@@ -111,6 +112,11 @@ type dataPersistManager struct {
 
 	// The ID of the snapshot being prepared. Only used when writing out snapshots.
 	snapshotID uuid.UUID
+
+	// The fileset volume being written, used to sync its files once closed.
+	fileSetID FileSetFileIdentifier
+
+	fsync *fsyncBatcher
 }
 
 type indexPersistManager struct {
@@ -180,6 +186,7 @@ func NewPersistManager(opts Options) (persist.Manager, error) {
 			segmentHolder:                 make([]checked.Bytes, 2),
 			nextSnapshotMetadataFileIndex: NextSnapshotMetadataFileIndex,
 			snapshotMetadataWriter:        NewSnapshotMetadataWriter(opts),
+			fsync:                         newFsyncBatcher(opts, scope),
 		},
 		indexPM: indexPersistManager{
 			writer:        idxWriter,
@@ -482,6 +489,7 @@ func (pm *persistManager) PrepareData(opts persist.DataPrepareOptions) (persist.
 	if err := pm.dataPM.writer.Open(dataWriterOpts); err != nil {
 		return prepared, err
 	}
+	pm.dataPM.fileSetID = dataWriterOpts.Identifier
 
 	prepared.Persist = pm.persist
 	prepared.Close = pm.closeData
@@ -498,6 +506,7 @@ func (pm *persistManager) persist(
 	pm.RLock()
 	// Rate limit options can change dynamically
 	opts := pm.currRateLimitOpts
+	maxConcurrentSnapshots := pm.currMaxConcurrentSnapshots
 	pm.RUnlock()
 
 	var (
@@ -505,6 +514,12 @@ func (pm *persistManager) persist(
 		slept time.Duration
 	)
 	rateLimitMbps := opts.LimitMbps()
+	if pm.dataPM.fileSetType == persist.FileSetSnapshotType && maxConcurrentSnapshots > 1 {
+		// NB: Shards are snapshotted concurrently by as many persist managers
+		// as the max concurrent snapshots, each is limited to an equal share
+		// of the rate limit so that together they do not exceed it.
+		rateLimitMbps /= float64(maxConcurrentSnapshots)
+	}
 	if opts.LimitEnabled() && rateLimitMbps > 0.0 {
 		if pm.start.IsZero() {
 			pm.start = start
@@ -536,7 +551,18 @@ func (pm *persistManager) persist(
 }
 
 func (pm *persistManager) closeData() error {
-	return pm.dataPM.writer.Close()
+	if err := pm.dataPM.writer.Close(); err != nil {
+		return err
+	}
+	if !pm.dataPM.fsync.enabled() {
+		return nil
+	}
+	checkpointPath, paths, err := dataFileSetFilePaths(pm.filePathPrefix,
+		pm.dataPM.fileSetType, pm.dataPM.fileSetID)
+	if err != nil {
+		return err
+	}
+	return pm.dataPM.fsync.add(checkpointPath, paths...)
 }
 
 // DoneFlush is called by the databaseFlushManager to finish the data persist process.
@@ -592,7 +618,27 @@ func (pm *persistManager) DoneSnapshot(
 	return pm.doneShared()
 }
 
+// DonePartialSnapshot is called to finish a snapshot persist process that
+// did not snapshot every namespace and block start.
+func (pm *persistManager) DonePartialSnapshot() error {
+	pm.Lock()
+	defer pm.Unlock()
+
+	if pm.status != persistManagerPersistingData {
+		return errPersistManagerNotPersisting
+	}
+
+	if pm.dataPM.fileSetType != persist.FileSetSnapshotType {
+		return errPersistManagerCannotDoneSnapshotNotSnapshot
+	}
+
+	return pm.doneShared()
+}
+
 func (pm *persistManager) doneShared() error {
+	// Sync any files still batched so they are durable once the flush is done
+	err := pm.dataPM.fsync.sync()
+
 	// Emit timing metrics
 	pm.metrics.writeDurationMs.Update(float64(pm.worked / time.Millisecond))
 	pm.metrics.throttleDurationMs.Update(float64(pm.slept / time.Millisecond))
@@ -600,7 +646,7 @@ func (pm *persistManager) doneShared() error {
 	// Reset state
 	pm.reset()
 
-	return nil
+	return err
 }
 
 func (pm *persistManager) dataFilesetExists(prepareOpts persist.DataPrepareOptions) (bool, error) {
@@ -632,5 +678,6 @@ func (pm *persistManager) dataFilesetExists(prepareOpts persist.DataPrepareOptio
 func (pm *persistManager) SetRuntimeOptions(value runtime.Options) {
 	pm.Lock()
 	pm.currRateLimitOpts = value.PersistRateLimitOptions()
+	pm.currMaxConcurrentSnapshots = value.MaxConcurrentSnapshots()
 	pm.Unlock()
 }
@@ -298,6 +298,30 @@ func TestPersistenceManagerPrepareSnapshotSuccess(t *testing.T) {
 	require.Equal(t, int64(104), pm.bytesWritten)
 }
 
+func TestPersistenceManagerDonePartialSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// No snapshot metadata is expected to be written.
+	pm, _, _, _ := testDataPersistManager(t, ctrl)
+	defer os.RemoveAll(pm.filePathPrefix)
+
+	flush, err := pm.StartSnapshotPersist(testSnapshotID)
+	require.NoError(t, err)
+	require.NoError(t, flush.DonePartialSnapshot())
+	require.Equal(t, persistManagerIdle, pm.status)
+
+	// The persist manager is idle again so it can't be done twice.
+	require.Equal(t, errPersistManagerNotPersisting, flush.DonePartialSnapshot())
+
+	// A flush can't be done as a partial snapshot.
+	flushPreparer, err := pm.StartFlushPersist()
+	require.NoError(t, err)
+	require.Equal(t, errPersistManagerCannotDoneSnapshotNotSnapshot,
+		pm.DonePartialSnapshot())
+	require.NoError(t, flushPreparer.DoneFlush())
+}
+
 func TestPersistenceManagerCloseData(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -632,6 +656,89 @@ func TestPersistenceManagerWithRateLimit(t *testing.T) {
 	}
 }
 
+func TestPersistenceManagerSnapshotRateLimitSharedByConcurrentSnapshots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pm, writer, _, opts := testDataPersistManager(t, ctrl)
+	defer os.RemoveAll(pm.filePathPrefix)
+
+	shard := uint32(0)
+	blockStart := time.Unix(1000, 0)
+
+	var (
+		now      time.Time
+		slept    time.Duration
+		id       = ident.StringID("foo")
+		head     = checked.NewBytes([]byte{0x1, 0x2}, nil)
+		tail     = checked.NewBytes([]byte{0x3}, nil)
+		segment  = ts.NewSegment(head, tail, ts.FinalizeNone)
+		checksum = digest.SegmentChecksum(segment)
+	)
+
+	pm.nowFn = func() time.Time { return now }
+	pm.sleepFn = func(d time.Duration) { slept += d }
+
+	writerOpts := xtest.CmpMatcher(DataWriterOpenOptions{
+		Identifier: FileSetFileIdentifier{
+			Namespace:  testNs1ID,
+			Shard:      shard,
+			BlockStart: blockStart,
+		},
+		BlockSize: testBlockSize,
+		Snapshot: DataWriterSnapshotOptions{
+			SnapshotID: testSnapshotID,
+		},
+	}, m3test.IdentTransformer)
+	writer.EXPECT().Open(writerOpts).Return(nil)
+	writer.EXPECT().WriteAll(id, ident.Tags{}, pm.dataPM.segmentHolder, checksum).Return(nil).AnyTimes()
+	writer.EXPECT().Close()
+
+	// Enable rate limiting with two shards snapshotted concurrently.
+	runtimeOpts := opts.RuntimeOptionsManager().Get()
+	opts.RuntimeOptionsManager().Update(
+		runtimeOpts.
+			SetMaxConcurrentSnapshots(2).
+			SetPersistRateLimitOptions(
+				runtimeOpts.PersistRateLimitOptions().
+					SetLimitEnabled(true).
+					SetLimitCheckEvery(2).
+					SetLimitMbps(16.0)))
+
+	// Wait until enabled
+	for func() bool {
+		pm.Lock()
+		defer pm.Unlock()
+		return !pm.currRateLimitOpts.LimitEnabled() || pm.currMaxConcurrentSnapshots != 2
+	}() {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	flush, err := pm.StartSnapshotPersist(testSnapshotID)
+	require.NoError(t, err)
+
+	prepared, err := flush.PrepareData(persist.DataPrepareOptions{
+		NamespaceMetadata: testNs1Metadata(t),
+		Shard:             shard,
+		BlockStart:        blockStart,
+	})
+	require.NoError(t, err)
+
+	now = time.Now()
+	require.NoError(t, prepared.Persist(id, ident.Tags{}, segment, checksum))
+	require.NoError(t, prepared.Persist(id, ident.Tags{}, segment, checksum))
+	require.Equal(t, time.Duration(0), slept)
+
+	// Each of the concurrent snapshots is limited to half of the limit so
+	// sleeps twice as long as a flush at the full limit would.
+	now = now.Add(time.Microsecond)
+	require.NoError(t, prepared.Persist(id, ident.Tags{}, segment, checksum))
+	require.Equal(t, time.Duration(4722), slept)
+
+	require.NoError(t, prepared.Close())
+	require.NoError(t, flush.DonePartialSnapshot())
+}
+
 func TestPersistenceManagerNamespaceSwitch(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
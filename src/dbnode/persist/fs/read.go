@@ -376,6 +376,25 @@ func (r *reader) ReadMetadata() (ident.ID, ident.TagIterator, int, uint32, error
 	return id, tags, length, checksum, nil
 }
 
+func (r *reader) ReadMetadataEncodedTags() (ident.ID, []byte, int, uint32, error) {
+	if r.metadataRead >= r.entries {
+		return nil, nil, 0, 0, io.EOF
+	}
+
+	entry := r.indexEntriesByOffsetAsc[r.metadataRead]
+	id := r.entryClonedID(entry.ID)
+	var encodedTags []byte
+	if len(entry.EncodedTags) > 0 {
+		// Copy since the entry may reference memory that is reused.
+		encodedTags = append([]byte(nil), entry.EncodedTags...)
+	}
+	length := int(entry.Size)
+	checksum := uint32(entry.Checksum)
+
+	r.metadataRead++
+	return id, encodedTags, length, checksum, nil
+}
+
 func (r *reader) ReadBloomFilter() (*ManagedConcurrentBloomFilter, error) {
 	return newManagedConcurrentBloomFilterFromFile(
 		r.bloomFilterFd,
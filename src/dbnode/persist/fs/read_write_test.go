@@ -151,11 +151,13 @@ type readTestType uint
 const (
 	readTestTypeData readTestType = iota
 	readTestTypeMetadata
+	readTestTypeMetadataEncodedTags
 )
 
 var readTestTypes = []readTestType{
 	readTestTypeData,
 	readTestTypeMetadata,
+	readTestTypeMetadataEncodedTags,
 }
 
 // readTestData will test reading back the data matches what was written,
@@ -240,6 +242,30 @@ func readTestData(t *testing.T, r DataFileSetReader, shard uint32, timestamp tim
 				// at least contains every ID
 				assert.True(t, bloomFilter.Test(id.Bytes()))
 
+				id.Finalize()
+				tags.Close()
+			case readTestTypeMetadataEncodedTags:
+				id, encodedTags, length, checksum, err := r.ReadMetadataEncodedTags()
+				require.NoError(t, err)
+
+				// Assert id
+				assert.Equal(t, entries[i].id, id.String())
+
+				// Assert tags once decoded
+				tags := ident.EmptyTagIterator
+				if len(encodedTags) > 0 {
+					decoder := testDefaultOpts.TagDecoderPool().Get()
+					decoder.Reset(checked.NewBytes(encodedTags, nil))
+					tags = decoder
+				}
+				tagMatcher := ident.NewTagIterMatcher(ident.NewTagsIterator(entries[i].Tags()))
+				assert.True(t, tagMatcher.Matches(tags))
+
+				assert.Equal(t, digest.Checksum(entries[i].data), checksum)
+				assert.Equal(t, len(entries[i].data), length)
+
+				assert.Equal(t, i+1, r.MetadataRead())
+
 				id.Finalize()
 				tags.Close()
 			}
@@ -23,7 +23,7 @@
 // spinning-disks the concurrency can be set to 1 to serialize all disk fetches
 // for a given namespace, and the concurrency be set higher in the case of SSDs.
 // This fetch concurrency is primarily implemented via the number of concurrent
-// fetchLoops that the retriever creates.
+// fetchLoops that the retriever creates, which can be changed at runtime.
 //
 // The block retriever also handles batching of requests for data, as well as
 // re-arranging the order of requests to increase data locality when seeking
@@ -39,10 +39,12 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/x/checked"
+	xclose "github.com/m3db/m3/src/x/close"
 	"github.com/m3db/m3/src/x/context"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/pool"
@@ -91,12 +93,14 @@ type blockRetriever struct {
 
 	blockSize time.Duration
 
-	status                     blockRetrieverStatus
-	reqsByShardIdx             []*shardRetrieveRequests
-	seekerMgr                  DataFileSetSeekerManager
-	notifyFetch                chan struct{}
-	fetchLoopsShouldShutdownCh chan struct{}
-	fetchLoopsHaveShutdownCh   chan struct{}
+	status         blockRetrieverStatus
+	reqsByShardIdx []*shardRetrieveRequests
+	seekerMgr      DataFileSetSeekerManager
+	notifyFetch    chan struct{}
+	// fetchLoops holds the channel closed to stop each running fetchLoop.
+	fetchLoops          []chan struct{}
+	fetchLoopsWg        sync.WaitGroup
+	runtimeOptsListener xclose.SimpleCloser
 }
 
 // NewBlockRetriever returns a new block retriever for TSDB file sets.
@@ -122,23 +126,19 @@ func NewBlockRetriever(
 		idPool:         opts.IdentifierPool(),
 		status:         blockRetrieverNotOpen,
 		notifyFetch:    make(chan struct{}, 1),
-		// We just close this channel when the fetchLoops should shutdown, so no
-		// buffering is required
-		fetchLoopsShouldShutdownCh: make(chan struct{}),
-		fetchLoopsHaveShutdownCh:   make(chan struct{}, opts.FetchConcurrency()),
 	}, nil
 }
 
 func (r *blockRetriever) Open(ns namespace.Metadata) error {
 	r.Lock()
-	defer r.Unlock()
-
 	if r.status != blockRetrieverNotOpen {
+		r.Unlock()
 		return errBlockRetrieverAlreadyOpenOrClosed
 	}
 
 	seekerMgr := r.newSeekerMgrFn(r.bytesPool, r.fsOpts, r.opts)
 	if err := seekerMgr.Open(ns); err != nil {
+		r.Unlock()
 		return err
 	}
 
@@ -149,12 +149,49 @@ func (r *blockRetriever) Open(ns namespace.Metadata) error {
 	// Cache blockSize result
 	r.blockSize = ns.Options().RetentionOptions().BlockSize()
 
-	for i := 0; i < r.opts.FetchConcurrency(); i++ {
-		go r.fetchLoop(seekerMgr)
-	}
+	r.setFetchConcurrencyWithLock(r.opts.FetchConcurrency())
+	r.Unlock()
+
+	// NB: registering synchronously delivers the current runtime options
+	// which acquires the lock, so this must happen once it is released.
+	listener := r.fsOpts.RuntimeOptionsManager().RegisterListener(r)
+	r.Lock()
+	r.runtimeOptsListener = listener
+	r.Unlock()
 	return nil
 }
 
+func (r *blockRetriever) SetRuntimeOptions(value runtime.Options) {
+	concurrency := value.FetchConcurrency()
+	if concurrency <= 0 {
+		concurrency = r.opts.FetchConcurrency()
+	}
+
+	r.Lock()
+	if r.status == blockRetrieverOpen {
+		r.setFetchConcurrencyWithLock(concurrency)
+	}
+	r.Unlock()
+}
+
+// setFetchConcurrencyWithLock starts or stops fetchLoops until the number
+// running matches the concurrency. Stopped fetchLoops leave any queued
+// requests to the fetchLoops that remain.
+func (r *blockRetriever) setFetchConcurrencyWithLock(concurrency int) {
+	for len(r.fetchLoops) < concurrency {
+		stopCh := make(chan struct{})
+		r.fetchLoops = append(r.fetchLoops, stopCh)
+		r.fetchLoopsWg.Add(1)
+		go r.fetchLoop(r.seekerMgr, stopCh)
+	}
+	for len(r.fetchLoops) > concurrency {
+		last := len(r.fetchLoops) - 1
+		close(r.fetchLoops[last])
+		r.fetchLoops[last] = nil
+		r.fetchLoops = r.fetchLoops[:last]
+	}
+}
+
 func (r *blockRetriever) CacheShardIndices(shards []uint32) error {
 	r.RLock()
 	if r.status != blockRetrieverOpen {
@@ -172,7 +209,12 @@ func (r *blockRetriever) CacheShardIndices(shards []uint32) error {
 	return seekerMgr.CacheShardIndices(shards)
 }
 
-func (r *blockRetriever) fetchLoop(seekerMgr DataFileSetSeekerManager) {
+func (r *blockRetriever) fetchLoop(
+	seekerMgr DataFileSetSeekerManager,
+	stopCh <-chan struct{},
+) {
+	defer r.fetchLoopsWg.Done()
+
 	var (
 		seekerResources = NewReusableSeekerResources(r.fsOpts)
 		inFlight        []*retrieveRequest
@@ -187,6 +229,19 @@ func (r *blockRetriever) fetchLoop(seekerMgr DataFileSetSeekerManager) {
 
 		// Select in flight requests
 		r.RLock()
+		// Exit if stopped because the fetch concurrency was lowered, while
+		// closing the fetchLoops are all stopped but must first fulfill all
+		// open requests.
+		if r.status == blockRetrieverOpen && isStopped(stopCh) {
+			r.RUnlock()
+			// Pass on any notification this fetchLoop consumed so that the
+			// requests it was notified of are picked up by another.
+			select {
+			case r.notifyFetch <- struct{}{}:
+			default:
+			}
+			break
+		}
 		// Move requests from shard retriever reqs into in flight slice
 		for _, reqs := range r.reqsByShardIdx {
 			reqs.Lock()
@@ -211,8 +266,8 @@ func (r *blockRetriever) fetchLoop(seekerMgr DataFileSetSeekerManager) {
 			select {
 			case <-r.notifyFetch:
 				continue
-			case <-r.fetchLoopsShouldShutdownCh:
-				break
+			case <-stopCh:
+				continue
 			}
 		}
 
@@ -266,8 +321,15 @@ func (r *blockRetriever) fetchLoop(seekerMgr DataFileSetSeekerManager) {
 			currBatchReqs = currBatchReqs[:0]
 		}
 	}
+}
 
-	r.fetchLoopsHaveShutdownCh <- struct{}{}
+func isStopped(stopCh <-chan struct{}) bool {
+	select {
+	case <-stopCh:
+		return true
+	default:
+		return false
+	}
 }
 
 func (r *blockRetriever) fetchBatch(
@@ -277,6 +339,11 @@ func (r *blockRetriever) fetchBatch(
 	reqs []*retrieveRequest,
 	seekerResources ReusableSeekerResources,
 ) {
+	if limiter := r.opts.OpenFilesLimiter(); limiter != nil {
+		limiter.Acquire()
+		defer limiter.Release()
+	}
+
 	// Resolve the seeker from the seeker mgr
 	seeker, err := seekerMgr.Borrow(shard, blockStart)
 	if err != nil {
@@ -297,6 +364,7 @@ func (r *blockRetriever) fetchBatch(
 
 		if err == errSeekIDNotFound {
 			req.notFound = true
+			seekerMgr.RecordNotFound(req.id, shard, blockStart, seeker)
 		}
 		req.indexEntry = entry
 	}
@@ -516,12 +584,15 @@ func (r *blockRetriever) Close() error {
 	r.status = blockRetrieverClosed
 
 	r.blockSize = 0
+	listener := r.runtimeOptsListener
+	r.runtimeOptsListener = nil
+	r.setFetchConcurrencyWithLock(0)
 	r.Unlock()
 
-	close(r.fetchLoopsShouldShutdownCh)
-	for i := 0; i < r.opts.FetchConcurrency(); i++ {
-		<-r.fetchLoopsHaveShutdownCh
+	if listener != nil {
+		listener.Close()
 	}
+	r.fetchLoopsWg.Wait()
 
 	return r.seekerMgr.Close()
 }
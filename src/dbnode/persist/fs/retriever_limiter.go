@@ -0,0 +1,80 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"sync/atomic"
+
+	"github.com/m3db/m3/src/x/instrument"
+
+	"github.com/uber-go/tally"
+)
+
+type retrieverOpenFilesLimiterMetrics struct {
+	openFiles tally.Gauge
+	waits     tally.Counter
+}
+
+func newRetrieverOpenFilesLimiterMetrics(
+	scope tally.Scope,
+) retrieverOpenFilesLimiterMetrics {
+	return retrieverOpenFilesLimiterMetrics{
+		openFiles: scope.Gauge("open-files"),
+		waits:     scope.Counter("open-files-waits"),
+	}
+}
+
+type retrieverOpenFilesLimiter struct {
+	tokens    chan struct{}
+	openFiles int64
+	metrics   retrieverOpenFilesLimiterMetrics
+}
+
+// NewRetrieverOpenFilesLimiter returns a new limiter on the number of files
+// concurrently open for reading, it is intended to be shared across all block
+// retrievers so the limit applies to the process as a whole.
+func NewRetrieverOpenFilesLimiter(
+	maxOpenFiles int,
+	iOpts instrument.Options,
+) RetrieverOpenFilesLimiter {
+	scope := iOpts.MetricsScope().SubScope("block-retriever")
+	return &retrieverOpenFilesLimiter{
+		tokens:  make(chan struct{}, maxOpenFiles),
+		metrics: newRetrieverOpenFilesLimiterMetrics(scope),
+	}
+}
+
+func (l *retrieverOpenFilesLimiter) Acquire() {
+	select {
+	case l.tokens <- struct{}{}:
+	default:
+		// Limit reached, record that we had to wait and block until
+		// another retrieve releases its file.
+		l.metrics.waits.Inc(1)
+		l.tokens <- struct{}{}
+	}
+	l.metrics.openFiles.Update(float64(atomic.AddInt64(&l.openFiles, 1)))
+}
+
+func (l *retrieverOpenFilesLimiter) Release() {
+	l.metrics.openFiles.Update(float64(atomic.AddInt64(&l.openFiles, -1)))
+	<-l.tokens
+}
@@ -35,7 +35,8 @@ const (
 )
 
 var (
-	errBlockLeaseManagerNotSet = errors.New("block lease manager is not set")
+	errBlockLeaseManagerNotSet   = errors.New("block lease manager is not set")
+	errNotFoundCacheSizeNegative = errors.New("not found cache size must not be negative")
 )
 
 type blockRetrieverOptions struct {
@@ -45,6 +46,8 @@ type blockRetrieverOptions struct {
 	fetchConcurrency  int
 	identifierPool    ident.Pool
 	blockLeaseManager block.LeaseManager
+	openFilesLimiter  RetrieverOpenFilesLimiter
+	notFoundCacheSize int
 }
 
 // NewBlockRetrieverOptions creates a new set of block retriever options
@@ -71,6 +74,9 @@ func (o *blockRetrieverOptions) Validate() error {
 	if o.blockLeaseManager == nil {
 		return errBlockLeaseManagerNotSet
 	}
+	if o.notFoundCacheSize < 0 {
+		return errNotFoundCacheSizeNegative
+	}
 	return nil
 }
 
@@ -133,3 +139,23 @@ func (o *blockRetrieverOptions) SetBlockLeaseManager(leaseMgr block.LeaseManager
 func (o *blockRetrieverOptions) BlockLeaseManager() block.LeaseManager {
 	return o.blockLeaseManager
 }
+
+func (o *blockRetrieverOptions) SetOpenFilesLimiter(value RetrieverOpenFilesLimiter) BlockRetrieverOptions {
+	opts := *o
+	opts.openFilesLimiter = value
+	return &opts
+}
+
+func (o *blockRetrieverOptions) OpenFilesLimiter() RetrieverOpenFilesLimiter {
+	return o.openFilesLimiter
+}
+
+func (o *blockRetrieverOptions) SetNotFoundCacheSize(value int) BlockRetrieverOptions {
+	opts := *o
+	opts.notFoundCacheSize = value
+	return &opts
+}
+
+func (o *blockRetrieverOptions) NotFoundCacheSize() int {
+	return o.notFoundCacheSize
+}
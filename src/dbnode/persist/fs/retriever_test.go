@@ -35,11 +35,13 @@ import (
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/index/convert"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/x/checked"
+	xclock "github.com/m3db/m3/src/x/clock"
 	"github.com/m3db/m3/src/x/context"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/pool"
@@ -516,6 +518,57 @@ func TestBlockRetrieverIDDoesNotExist(t *testing.T) {
 	assert.Equal(t, nil, segment.Tail)
 }
 
+// TestBlockRetrieverRuntimeFetchConcurrency verifies that the number of
+// fetchLoops follows the fetch concurrency set in the runtime options.
+func TestBlockRetrieverRuntimeFetchConcurrency(t *testing.T) {
+	defer leaktest.CheckTimeout(t, time.Minute)()
+
+	dir, err := ioutil.TempDir("", "testdb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filePathPrefix := filepath.Join(dir, "")
+
+	runtimeOptsMgr := runtime.NewOptionsManager()
+	defer runtimeOptsMgr.Close()
+
+	fsOpts := testDefaultOpts.
+		SetFilePathPrefix(filePathPrefix).
+		SetRuntimeOptionsManager(runtimeOptsMgr)
+	opts := testBlockRetrieverOptions{
+		retrieverOpts: defaultTestBlockRetrieverOptions.SetFetchConcurrency(2),
+		fsOpts:        fsOpts,
+	}
+	retriever, cleanup := newOpenTestBlockRetriever(t, opts)
+
+	numFetchLoops := func() int {
+		retriever.RLock()
+		defer retriever.RUnlock()
+		return len(retriever.fetchLoops)
+	}
+	require.Equal(t, 2, numFetchLoops())
+
+	// Runtime options are delivered asynchronously so wait for each update.
+	for _, tc := range []struct {
+		concurrency int
+		expected    int
+	}{
+		{concurrency: 8, expected: 8},
+		{concurrency: 1, expected: 1},
+		// Zero reverts to the fetch concurrency the retriever was created with.
+		{concurrency: 0, expected: 2},
+	} {
+		runtimeOpts := runtimeOptsMgr.Get().SetFetchConcurrency(tc.concurrency)
+		require.NoError(t, runtimeOptsMgr.Update(runtimeOpts))
+		require.True(t, xclock.WaitUntil(func() bool {
+			return numFetchLoops() == tc.expected
+		}, 5*time.Second))
+	}
+
+	// Closing stops all the fetchLoops, which the leak test verifies.
+	cleanup()
+	require.Equal(t, 0, numFetchLoops())
+}
+
 // TestBlockRetrieverOnlyCreatesTagItersIfTagsExists verifies that the block retriever
 // only creates a tag iterator in the OnRetrieve pathway if the series has tags.
 func TestBlockRetrieverOnlyCreatesTagItersIfTagsExists(t *testing.T) {
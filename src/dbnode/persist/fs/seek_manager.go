@@ -34,6 +34,7 @@ import (
 	"github.com/m3db/m3/src/x/pool"
 	xtime "github.com/m3db/m3/src/x/time"
 
+	"github.com/uber-go/tally"
 	"go.uber.org/zap"
 )
 
@@ -92,6 +93,7 @@ type seekerManager struct {
 	newOpenSeekerFn        newOpenSeekerFn
 	sleepFn                func(d time.Duration)
 	openCloseLoopDoneCh    chan struct{}
+	notFoundCacheHits      tally.Counter
 	// Pool of seeker resources that can be used to open new seekers.
 	reusableSeekerResourcesPool pool.ObjectPool
 }
@@ -108,7 +110,9 @@ type seekersAndBloom struct {
 	wg          *sync.WaitGroup
 	seekers     []borrowableSeeker
 	bloomFilter *ManagedConcurrentBloomFilter
-	volume      int
+	// notFound is nil unless the not found cache is enabled.
+	notFound *notFoundCache
+	volume   int
 }
 
 // borrowableSeeker is just a seeker with an additional field for keeping track of whether or not it has been borrowed.
@@ -158,6 +162,8 @@ func NewSeekerManager(
 		logger:                      opts.InstrumentOptions().Logger(),
 		openCloseLoopDoneCh:         make(chan struct{}),
 		reusableSeekerResourcesPool: reusableSeekerResourcesPool,
+		notFoundCacheHits: opts.InstrumentOptions().MetricsScope().
+			SubScope("block-retriever").Counter("not-found-cache-hits"),
 	}
 	m.openAnyUnopenSeekersFn = m.openAnyUnopenSeekers
 	m.newOpenSeekerFn = m.newOpenSeeker
@@ -219,7 +225,7 @@ func (m *seekerManager) Test(id ident.ID, shard uint32, start time.Time) (bool,
 
 	// Seekers are open: good to test but still hold RLock while doing so
 	if ok && seekers.active.wg == nil {
-		idExists := seekers.active.bloomFilter.Test(id.Bytes())
+		idExists := m.testWithLock(id, seekers.active)
 		byTime.RUnlock()
 		return idExists, nil
 	} else {
@@ -234,7 +240,40 @@ func (m *seekerManager) Test(id ident.ID, shard uint32, start time.Time) (bool,
 		return false, err
 	}
 
-	return seekersAndBloom.bloomFilter.Test(id.Bytes()), nil
+	return m.testWithLock(id, seekersAndBloom), nil
+}
+
+func (m *seekerManager) testWithLock(id ident.ID, seekers seekersAndBloom) bool {
+	if !seekers.bloomFilter.Test(id.Bytes()) {
+		return false
+	}
+	if seekers.notFound != nil && seekers.notFound.Contains(id.Bytes()) {
+		m.notFoundCacheHits.Inc(1)
+		return false
+	}
+	return true
+}
+
+func (m *seekerManager) RecordNotFound(
+	id ident.ID,
+	shard uint32,
+	start time.Time,
+	seeker ConcurrentDataFileSetSeeker,
+) {
+	byTime := m.seekersByTime(shard)
+	byTime.RLock()
+	defer byTime.RUnlock()
+
+	seekers, ok := byTime.seekers[xtime.ToUnixNano(start)]
+	if !ok || seekers.active.notFound == nil {
+		return
+	}
+	// Only record the ID if the seeker is of the active volume, the ID may
+	// exist in a newer volume opened since the seeker was borrowed.
+	if seeker.ConcurrentIDBloomFilter() != seekers.active.bloomFilter {
+		return
+	}
+	seekers.active.notFound.Add(id.Bytes())
 }
 
 func (m *seekerManager) Borrow(shard uint32, start time.Time) (ConcurrentDataFileSetSeeker, error) {
@@ -656,9 +695,15 @@ func (m *seekerManager) seekersAndBloomFromSeeker(seeker DataFileSetSeeker, volu
 		borrowableSeekers = append(borrowableSeekers, borrowableSeeker{seeker: clone})
 	}
 
+	var notFound *notFoundCache
+	if size := m.blockRetrieverOpts.NotFoundCacheSize(); size > 0 {
+		notFound = newNotFoundCache(size)
+	}
+
 	return seekersAndBloom{
 		seekers:     borrowableSeekers,
 		bloomFilter: borrowableSeekers[0].seeker.ConcurrentIDBloomFilter(),
+		notFound:    notFound,
 		volume:      volume,
 	}, nil
 }
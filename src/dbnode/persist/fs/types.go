@@ -138,6 +138,12 @@ type DataFileSetReader interface {
 	// be returned to their respective pools.
 	ReadMetadata() (id ident.ID, tags ident.TagIterator, length int, checksum uint32, err error)
 
+	// ReadMetadataEncodedTags returns the next id and metadata like ReadMetadata but
+	// returns the tags in their encoded form so that callers that may never inspect the
+	// tags avoid decoding them. Use either Read, ReadMetadata or ReadMetadataEncodedTags
+	// to progress through a volume, but only one of them.
+	ReadMetadataEncodedTags() (id ident.ID, encodedTags []byte, length int, checksum uint32, err error)
+
 	// ReadBloomFilter returns the bloom filter stored on disk in a container object that is safe
 	// for concurrent use and has a Close() method for releasing resources when done.
 	ReadBloomFilter() (*ManagedConcurrentBloomFilter, error)
@@ -247,6 +253,17 @@ type DataFileSetSeekerManager interface {
 	// Test checks if an ID exists in a concurrent ID bloom filter for a
 	// given shard, block, start time and volume.
 	Test(id ident.ID, shard uint32, start time.Time) (bool, error)
+
+	// RecordNotFound records that an ID which passed the bloom filter was not
+	// found by a seeker borrowed for the given shard and block start, so that
+	// subsequent tests of the ID fail without seeking. It is ignored if the
+	// seeker is no longer of the latest volume.
+	RecordNotFound(
+		id ident.ID,
+		shard uint32,
+		start time.Time,
+		seeker ConcurrentDataFileSetSeeker,
+	)
 }
 
 // DataBlockRetriever provides a block retriever for TSDB file sets
@@ -458,6 +475,26 @@ type Options interface {
 
 	// FSTOptions returns the fst options.
 	FSTOptions() fst.Options
+
+	// SetFsyncBatchSize sets the number of files written by the persist manager
+	// to batch before syncing them, zero leaves syncing to the operating system.
+	// The persist manager otherwise issues no fsyncs, so enabling this adds
+	// fsyncs to flushes rather than batching existing ones.
+	SetFsyncBatchSize(value int) Options
+
+	// FsyncBatchSize returns the number of files written by the persist manager
+	// to batch before syncing them, zero leaves syncing to the operating system.
+	// The persist manager otherwise issues no fsyncs, so enabling this adds
+	// fsyncs to flushes rather than batching existing ones.
+	FsyncBatchSize() int
+
+	// SetFsyncBatchInterval sets the max time between syncing batched files,
+	// zero only syncs full batches and at the end of each flush.
+	SetFsyncBatchInterval(value time.Duration) Options
+
+	// FsyncBatchInterval returns the max time between syncing batched files,
+	// zero only syncs full batches and at the end of each flush.
+	FsyncBatchInterval() time.Duration
 }
 
 // BlockRetrieverOptions represents the options for block retrieval
@@ -500,6 +537,33 @@ type BlockRetrieverOptions interface {
 
 	// BlockLeaseManager returns the block leaser.
 	BlockLeaseManager() block.LeaseManager
+
+	// SetOpenFilesLimiter sets the limiter on files concurrently open for
+	// reading, nil means no limit is applied.
+	SetOpenFilesLimiter(value RetrieverOpenFilesLimiter) BlockRetrieverOptions
+
+	// OpenFilesLimiter returns the limiter on files concurrently open for
+	// reading.
+	OpenFilesLimiter() RetrieverOpenFilesLimiter
+
+	// SetNotFoundCacheSize sets the number of IDs per fileset volume that are
+	// remembered as not found despite passing the bloom filter so repeated
+	// reads skip seeking them, zero disables the cache.
+	SetNotFoundCacheSize(value int) BlockRetrieverOptions
+
+	// NotFoundCacheSize returns the number of IDs per fileset volume that are
+	// remembered as not found despite passing the bloom filter.
+	NotFoundCacheSize() int
+}
+
+// RetrieverOpenFilesLimiter limits the number of files concurrently open
+// for reading by block retrievers.
+type RetrieverOpenFilesLimiter interface {
+	// Acquire blocks until a file can be opened.
+	Acquire()
+
+	// Release releases a file previously acquired.
+	Release()
 }
 
 // ForEachRemainingFn is the function that is run on each of the remaining
@@ -116,6 +116,11 @@ type SnapshotPreparer interface {
 
 	// DoneSnapshot marks the snapshot as complete.
 	DoneSnapshot(snapshotUUID uuid.UUID, commitLogIdentifier CommitLogFile) error
+
+	// DonePartialSnapshot marks a snapshot of a subset of the namespaces or
+	// block starts as complete. No snapshot metadata is written since the
+	// snapshot must not be relied upon to clean up commit logs.
+	DonePartialSnapshot() error
 }
 
 // IndexFlush is a persist flush cycle, each namespace, block combination needs
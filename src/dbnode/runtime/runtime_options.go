@@ -51,6 +51,12 @@ const (
 	defaultWriteNewSeriesAsync                  = false
 	defaultWriteNewSeriesBackoffDuration        = time.Duration(0)
 	defaultWriteNewSeriesLimitPerShardPerSecond = 0
+	defaultWriteNewSeriesMaxPendingPerShard     = 0
+	defaultMaxReadBlockReaders                  = 0
+	defaultFetchConcurrency                     = 0
+	defaultMaxConcurrentSnapshots               = 1
+	defaultWriteTimeout                         = time.Duration(0)
+	defaultDropDisabledColdWrites               = false
 	defaultTickSeriesBatchSize                  = 512
 	defaultTickPerSeriesSleepDuration           = 100 * time.Microsecond
 	defaultTickMinimumInterval                  = 10 * time.Second
@@ -62,6 +68,16 @@ var (
 		"write new series backoff duration cannot be negative")
 	errWriteNewSeriesLimitPerShardPerSecondIsNegative = errors.New(
 		"write new series limit per shard per cannot be negative")
+	errWriteNewSeriesMaxPendingPerShardIsNegative = errors.New(
+		"write new series max pending per shard cannot be negative")
+	errMaxReadBlockReadersIsNegative = errors.New(
+		"max read block readers cannot be negative")
+	errFetchConcurrencyIsNegative = errors.New(
+		"fetch concurrency cannot be negative")
+	errMaxConcurrentSnapshotsMustBePositive = errors.New(
+		"max concurrent snapshots must be positive")
+	errWriteTimeoutIsNegative = errors.New(
+		"write timeout cannot be negative")
 	errTickSeriesBatchSizeMustBePositive = errors.New(
 		"tick series batch size must be positive")
 	errTickPerSeriesSleepDurationMustBePositive = errors.New(
@@ -73,6 +89,12 @@ type options struct {
 	writeNewSeriesAsync                  bool
 	writeNewSeriesBackoffDuration        time.Duration
 	writeNewSeriesLimitPerShardPerSecond int
+	writeNewSeriesMaxPendingPerShard     int
+	maxReadBlockReaders                  int
+	fetchConcurrency                     int
+	maxConcurrentSnapshots               int
+	writeTimeout                         time.Duration
+	dropDisabledColdWrites               bool
 	tickSeriesBatchSize                  int
 	tickPerSeriesSleepDuration           time.Duration
 	tickMinimumInterval                  time.Duration
@@ -82,6 +104,7 @@ type options struct {
 	clientWriteConsistencyLevel          topology.ConsistencyLevel
 	indexDefaultQueryTimeout             time.Duration
 	flushIndexBlockNumSegments           uint
+	debugLoggingNamespaces               []string
 }
 
 // NewOptions creates a new set of runtime options with defaults
@@ -91,6 +114,12 @@ func NewOptions() Options {
 		writeNewSeriesAsync:                  defaultWriteNewSeriesAsync,
 		writeNewSeriesBackoffDuration:        defaultWriteNewSeriesBackoffDuration,
 		writeNewSeriesLimitPerShardPerSecond: defaultWriteNewSeriesLimitPerShardPerSecond,
+		writeNewSeriesMaxPendingPerShard:     defaultWriteNewSeriesMaxPendingPerShard,
+		maxReadBlockReaders:                  defaultMaxReadBlockReaders,
+		fetchConcurrency:                     defaultFetchConcurrency,
+		maxConcurrentSnapshots:               defaultMaxConcurrentSnapshots,
+		writeTimeout:                         defaultWriteTimeout,
+		dropDisabledColdWrites:               defaultDropDisabledColdWrites,
 		tickSeriesBatchSize:                  defaultTickSeriesBatchSize,
 		tickPerSeriesSleepDuration:           defaultTickPerSeriesSleepDuration,
 		tickMinimumInterval:                  defaultTickMinimumInterval,
@@ -115,6 +144,33 @@ func (o *options) Validate() error {
 		return errWriteNewSeriesLimitPerShardPerSecondIsNegative
 	}
 
+	// writeNewSeriesMaxPendingPerShard can be zero to specify that
+	// no limit should be enforced
+	if o.writeNewSeriesMaxPendingPerShard < 0 {
+		return errWriteNewSeriesMaxPendingPerShardIsNegative
+	}
+
+	// maxReadBlockReaders can be zero to specify that no limit
+	// should be enforced
+	if o.maxReadBlockReaders < 0 {
+		return errMaxReadBlockReadersIsNegative
+	}
+
+	// fetchConcurrency can be zero to specify that the fetch concurrency
+	// the block retrievers were constructed with should be used
+	if o.fetchConcurrency < 0 {
+		return errFetchConcurrencyIsNegative
+	}
+
+	if !(o.maxConcurrentSnapshots > 0) {
+		return errMaxConcurrentSnapshotsMustBePositive
+	}
+
+	// writeTimeout can be zero to specify no timeout
+	if o.writeTimeout < 0 {
+		return errWriteTimeoutIsNegative
+	}
+
 	if !(o.tickSeriesBatchSize > 0) {
 		return errTickSeriesBatchSizeMustBePositive
 	}
@@ -168,6 +224,66 @@ func (o *options) WriteNewSeriesLimitPerShardPerSecond() int {
 	return o.writeNewSeriesLimitPerShardPerSecond
 }
 
+func (o *options) SetWriteNewSeriesMaxPendingPerShard(value int) Options {
+	opts := *o
+	opts.writeNewSeriesMaxPendingPerShard = value
+	return &opts
+}
+
+func (o *options) WriteNewSeriesMaxPendingPerShard() int {
+	return o.writeNewSeriesMaxPendingPerShard
+}
+
+func (o *options) SetMaxReadBlockReaders(value int) Options {
+	opts := *o
+	opts.maxReadBlockReaders = value
+	return &opts
+}
+
+func (o *options) MaxReadBlockReaders() int {
+	return o.maxReadBlockReaders
+}
+
+func (o *options) SetFetchConcurrency(value int) Options {
+	opts := *o
+	opts.fetchConcurrency = value
+	return &opts
+}
+
+func (o *options) FetchConcurrency() int {
+	return o.fetchConcurrency
+}
+
+func (o *options) SetMaxConcurrentSnapshots(value int) Options {
+	opts := *o
+	opts.maxConcurrentSnapshots = value
+	return &opts
+}
+
+func (o *options) MaxConcurrentSnapshots() int {
+	return o.maxConcurrentSnapshots
+}
+
+func (o *options) SetWriteTimeout(value time.Duration) Options {
+	opts := *o
+	opts.writeTimeout = value
+	return &opts
+}
+
+func (o *options) WriteTimeout() time.Duration {
+	return o.writeTimeout
+}
+
+func (o *options) SetDropDisabledColdWrites(value bool) Options {
+	opts := *o
+	opts.dropDisabledColdWrites = value
+	return &opts
+}
+
+func (o *options) DropDisabledColdWrites() bool {
+	return o.dropDisabledColdWrites
+}
+
 func (o *options) SetTickSeriesBatchSize(value int) Options {
 	opts := *o
 	opts.tickSeriesBatchSize = value
@@ -257,3 +373,13 @@ func (o *options) SetFlushIndexBlockNumSegments(value uint) Options {
 func (o *options) FlushIndexBlockNumSegments() uint {
 	return o.flushIndexBlockNumSegments
 }
+
+func (o *options) SetDebugLoggingNamespaces(value []string) Options {
+	opts := *o
+	opts.debugLoggingNamespaces = value
+	return &opts
+}
+
+func (o *options) DebugLoggingNamespaces() []string {
+	return o.debugLoggingNamespaces
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package runtime
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/topology"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// exportedOptions is the serialized form of the full set of runtime options.
+type exportedOptions struct {
+	PersistRateLimitEnabled              bool          `yaml:"persistRateLimitEnabled"`
+	PersistRateLimitMbps                 float64       `yaml:"persistRateLimitMbps"`
+	PersistRateLimitCheckEvery           int           `yaml:"persistRateLimitCheckEvery"`
+	WriteNewSeriesAsync                  bool          `yaml:"writeNewSeriesAsync"`
+	WriteNewSeriesBackoffDuration        time.Duration `yaml:"writeNewSeriesBackoffDuration"`
+	WriteNewSeriesLimitPerShardPerSecond int           `yaml:"writeNewSeriesLimitPerShardPerSecond"`
+	WriteNewSeriesMaxPendingPerShard     int           `yaml:"writeNewSeriesMaxPendingPerShard"`
+	MaxReadBlockReaders                  int           `yaml:"maxReadBlockReaders"`
+	MaxConcurrentSnapshots               int           `yaml:"maxConcurrentSnapshots"`
+	WriteTimeout                         time.Duration `yaml:"writeTimeout"`
+	DropDisabledColdWrites               bool          `yaml:"dropDisabledColdWrites"`
+	TickSeriesBatchSize                  int           `yaml:"tickSeriesBatchSize"`
+	TickPerSeriesSleepDuration           time.Duration `yaml:"tickPerSeriesSleepDuration"`
+	TickMinimumInterval                  time.Duration `yaml:"tickMinimumInterval"`
+	MaxWiredBlocks                       uint          `yaml:"maxWiredBlocks"`
+	ClientBootstrapConsistencyLevel      string        `yaml:"clientBootstrapConsistencyLevel"`
+	ClientReadConsistencyLevel           string        `yaml:"clientReadConsistencyLevel"`
+	ClientWriteConsistencyLevel          string        `yaml:"clientWriteConsistencyLevel"`
+	IndexDefaultQueryTimeout             time.Duration `yaml:"indexDefaultQueryTimeout"`
+	FlushIndexBlockNumSegments           uint          `yaml:"flushIndexBlockNumSegments"`
+	DebugLoggingNamespaces               []string      `yaml:"debugLoggingNamespaces"`
+}
+
+func newExportedOptions(opts Options) exportedOptions {
+	rateLimitOpts := opts.PersistRateLimitOptions()
+	return exportedOptions{
+		PersistRateLimitEnabled:              rateLimitOpts.LimitEnabled(),
+		PersistRateLimitMbps:                 rateLimitOpts.LimitMbps(),
+		PersistRateLimitCheckEvery:           rateLimitOpts.LimitCheckEvery(),
+		WriteNewSeriesAsync:                  opts.WriteNewSeriesAsync(),
+		WriteNewSeriesBackoffDuration:        opts.WriteNewSeriesBackoffDuration(),
+		WriteNewSeriesLimitPerShardPerSecond: opts.WriteNewSeriesLimitPerShardPerSecond(),
+		WriteNewSeriesMaxPendingPerShard:     opts.WriteNewSeriesMaxPendingPerShard(),
+		MaxReadBlockReaders:                  opts.MaxReadBlockReaders(),
+		MaxConcurrentSnapshots:               opts.MaxConcurrentSnapshots(),
+		WriteTimeout:                         opts.WriteTimeout(),
+		DropDisabledColdWrites:               opts.DropDisabledColdWrites(),
+		TickSeriesBatchSize:                  opts.TickSeriesBatchSize(),
+		TickPerSeriesSleepDuration:           opts.TickPerSeriesSleepDuration(),
+		TickMinimumInterval:                  opts.TickMinimumInterval(),
+		MaxWiredBlocks:                       opts.MaxWiredBlocks(),
+		ClientBootstrapConsistencyLevel:      opts.ClientBootstrapConsistencyLevel().String(),
+		ClientReadConsistencyLevel:           opts.ClientReadConsistencyLevel().String(),
+		ClientWriteConsistencyLevel:          opts.ClientWriteConsistencyLevel().String(),
+		IndexDefaultQueryTimeout:             opts.IndexDefaultQueryTimeout(),
+		FlushIndexBlockNumSegments:           opts.FlushIndexBlockNumSegments(),
+		DebugLoggingNamespaces:               opts.DebugLoggingNamespaces(),
+	}
+}
+
+func (e exportedOptions) options(opts Options) (Options, error) {
+	bootstrapLevel, err := parseReadConsistencyLevel(e.ClientBootstrapConsistencyLevel)
+	if err != nil {
+		return nil, err
+	}
+	readLevel, err := parseReadConsistencyLevel(e.ClientReadConsistencyLevel)
+	if err != nil {
+		return nil, err
+	}
+	writeLevel, err := parseConsistencyLevel(e.ClientWriteConsistencyLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimitOpts := opts.PersistRateLimitOptions().
+		SetLimitEnabled(e.PersistRateLimitEnabled).
+		SetLimitMbps(e.PersistRateLimitMbps).
+		SetLimitCheckEvery(e.PersistRateLimitCheckEvery)
+	return opts.
+		SetPersistRateLimitOptions(rateLimitOpts).
+		SetWriteNewSeriesAsync(e.WriteNewSeriesAsync).
+		SetWriteNewSeriesBackoffDuration(e.WriteNewSeriesBackoffDuration).
+		SetWriteNewSeriesLimitPerShardPerSecond(e.WriteNewSeriesLimitPerShardPerSecond).
+		SetWriteNewSeriesMaxPendingPerShard(e.WriteNewSeriesMaxPendingPerShard).
+		SetMaxReadBlockReaders(e.MaxReadBlockReaders).
+		SetMaxConcurrentSnapshots(e.MaxConcurrentSnapshots).
+		SetWriteTimeout(e.WriteTimeout).
+		SetDropDisabledColdWrites(e.DropDisabledColdWrites).
+		SetTickSeriesBatchSize(e.TickSeriesBatchSize).
+		SetTickPerSeriesSleepDuration(e.TickPerSeriesSleepDuration).
+		SetTickMinimumInterval(e.TickMinimumInterval).
+		SetMaxWiredBlocks(e.MaxWiredBlocks).
+		SetClientBootstrapConsistencyLevel(bootstrapLevel).
+		SetClientReadConsistencyLevel(readLevel).
+		SetClientWriteConsistencyLevel(writeLevel).
+		SetIndexDefaultQueryTimeout(e.IndexDefaultQueryTimeout).
+		SetFlushIndexBlockNumSegments(e.FlushIndexBlockNumSegments).
+		SetDebugLoggingNamespaces(e.DebugLoggingNamespaces), nil
+}
+
+// ExportOptions serializes the full set of runtime options as a single blob.
+func ExportOptions(opts Options) ([]byte, error) {
+	return yaml.Marshal(newExportedOptions(opts))
+}
+
+// ImportOptions deserializes a blob created by ExportOptions on top of the
+// provided runtime options and validates the result, any runtime options
+// missing from the blob retain their value from the provided options.
+func ImportOptions(opts Options, data []byte) (Options, error) {
+	exported := newExportedOptions(opts)
+	if err := yaml.UnmarshalStrict(data, &exported); err != nil {
+		return nil, err
+	}
+	result, err := exported.options(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := result.Validate(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func parseReadConsistencyLevel(value string) (topology.ReadConsistencyLevel, error) {
+	for _, level := range topology.ValidReadConsistencyLevels() {
+		if level.String() == value {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid read consistency level: %s", value)
+}
+
+func parseConsistencyLevel(value string) (topology.ConsistencyLevel, error) {
+	for _, level := range topology.ValidConsistencyLevels() {
+		if level.String() == value {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid consistency level: %s", value)
+}
@@ -50,6 +50,18 @@ func (w *optionsManager) Get() Options {
 	return w.watchable.Get().(Options)
 }
 
+func (w *optionsManager) Export() ([]byte, error) {
+	return ExportOptions(w.Get())
+}
+
+func (w *optionsManager) Import(data []byte) error {
+	value, err := ImportOptions(w.Get(), data)
+	if err != nil {
+		return err
+	}
+	return w.Update(value)
+}
+
 func (w *optionsManager) RegisterListener(
 	listener OptionsListener,
 ) xclose.SimpleCloser {
@@ -96,6 +108,14 @@ func (n noOpOptionsManager) Get() Options {
 	return n.opts
 }
 
+func (n noOpOptionsManager) Export() ([]byte, error) {
+	return ExportOptions(n.opts)
+}
+
+func (n noOpOptionsManager) Import(data []byte) error {
+	return fmt.Errorf("no-op options manager cannot import options")
+}
+
 func (n noOpOptionsManager) RegisterListener(
 	listener OptionsListener,
 ) xclose.SimpleCloser {
@@ -25,6 +25,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/topology"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -71,3 +73,39 @@ func TestRuntimeOptionsManagerUpdate(t *testing.T) {
 	}
 	assert.Equal(t, true, l.runtimeOptions().WriteNewSeriesAsync())
 }
+
+func TestRuntimeOptionsManagerExportImport(t *testing.T) {
+	opts := NewOptions().
+		SetWriteNewSeriesAsync(true).
+		SetTickMinimumInterval(time.Minute).
+		SetClientReadConsistencyLevel(topology.ReadConsistencyLevelOne).
+		SetPersistRateLimitOptions(NewOptions().PersistRateLimitOptions().
+			SetLimitEnabled(true).
+			SetLimitMbps(42))
+	src := NewOptionsManager()
+	require.NoError(t, src.Update(opts))
+
+	data, err := src.Export()
+	require.NoError(t, err)
+
+	dst := NewOptionsManager()
+	require.NoError(t, dst.Import(data))
+
+	result := dst.Get()
+	assert.True(t, result.WriteNewSeriesAsync())
+	assert.Equal(t, time.Minute, result.TickMinimumInterval())
+	assert.Equal(t, topology.ReadConsistencyLevelOne, result.ClientReadConsistencyLevel())
+	assert.True(t, result.PersistRateLimitOptions().LimitEnabled())
+	assert.Equal(t, float64(42), result.PersistRateLimitOptions().LimitMbps())
+
+	// Ensure round trips the full set of options.
+	roundTripped, err := dst.Export()
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(roundTripped))
+
+	// Ensure invalid options are rejected and not applied.
+	require.Error(t, dst.Import([]byte("tickSeriesBatchSize: 0")))
+	require.Error(t, dst.Import([]byte("clientReadConsistencyLevel: invalid")))
+	require.Error(t, dst.Import([]byte("unknownOption: true")))
+	assert.Equal(t, opts.TickSeriesBatchSize(), dst.Get().TickSeriesBatchSize())
+}
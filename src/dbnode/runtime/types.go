@@ -77,6 +77,78 @@ type Options interface {
 	// time series being inserted.
 	WriteNewSeriesLimitPerShardPerSecond() int
 
+	// SetWriteNewSeriesMaxPendingPerShard sets the maximum number of new
+	// series inserts that may be pending in a shard's async insert queue,
+	// once exceeded new series writes are rejected with a retryable error
+	// rather than queued. Setting to zero disables the limit.
+	SetWriteNewSeriesMaxPendingPerShard(value int) Options
+
+	// WriteNewSeriesMaxPendingPerShard returns the maximum number of new
+	// series inserts that may be pending in a shard's async insert queue,
+	// once exceeded new series writes are rejected with a retryable error
+	// rather than queued. Setting to zero disables the limit.
+	WriteNewSeriesMaxPendingPerShard() int
+
+	// SetMaxReadBlockReaders sets the maximum number of block readers a single
+	// read of a series may assemble before being rejected as too large,
+	// setting to zero disables the limit. This limit is primarily offered to
+	// prevent a single expensive read from monopolizing resources.
+	SetMaxReadBlockReaders(value int) Options
+
+	// MaxReadBlockReaders returns the maximum number of block readers a single
+	// read of a series may assemble before being rejected as too large,
+	// setting to zero disables the limit. This limit is primarily offered to
+	// prevent a single expensive read from monopolizing resources.
+	MaxReadBlockReaders() int
+
+	// SetFetchConcurrency sets the number of concurrent fetches from disk
+	// each block retriever performs, setting to zero uses the fetch
+	// concurrency the block retrievers were constructed with.
+	SetFetchConcurrency(value int) Options
+
+	// FetchConcurrency returns the number of concurrent fetches from disk
+	// each block retriever performs, setting to zero uses the fetch
+	// concurrency the block retrievers were constructed with.
+	FetchConcurrency() int
+
+	// SetMaxConcurrentSnapshots sets the maximum number of shards that are
+	// snapshotted concurrently, each writing its own snapshot fileset. This
+	// bounds the IO used when snapshotting many shards at a block boundary,
+	// the persist rate limit is divided evenly between the shards.
+	SetMaxConcurrentSnapshots(value int) Options
+
+	// MaxConcurrentSnapshots returns the maximum number of shards that are
+	// snapshotted concurrently, each writing its own snapshot fileset. This
+	// bounds the IO used when snapshotting many shards at a block boundary,
+	// the persist rate limit is divided evenly between the shards.
+	MaxConcurrentSnapshots() int
+
+	// SetWriteTimeout sets the server side timeout for a write request to be
+	// enqueued to the commit log, after which the write fails with a retryable
+	// error rather than blocking. Time spent writing to the series before the
+	// enqueue is not bounded, so a write that times out enqueueing has already
+	// been applied and may be read although it is not durable. Setting to zero
+	// disables the timeout.
+	SetWriteTimeout(value time.Duration) Options
+
+	// WriteTimeout returns the server side timeout for a write request to be
+	// enqueued to the commit log, after which the write fails with a retryable
+	// error rather than blocking. Time spent writing to the series before the
+	// enqueue is not bounded, so a write that times out enqueueing has already
+	// been applied and may be read although it is not durable. Setting to zero
+	// disables the timeout.
+	WriteTimeout() time.Duration
+
+	// SetDropDisabledColdWrites sets whether writes outside of the buffer
+	// past/future window for namespaces with cold writes disabled are silently
+	// dropped rather than rejected with an error.
+	SetDropDisabledColdWrites(value bool) Options
+
+	// DropDisabledColdWrites returns whether writes outside of the buffer
+	// past/future window for namespaces with cold writes disabled are silently
+	// dropped rather than rejected with an error.
+	DropDisabledColdWrites() bool
+
 	// SetTickSeriesBatchSize sets the batch size to process series together
 	// during a tick before yielding and sleeping the per series duration
 	// multiplied by the batch size.
@@ -180,6 +252,14 @@ type Options interface {
 	// greater amount of segments that need to be searched independently but
 	// a higher number reduces the memory pressure when flushing an index block.
 	FlushIndexBlockNumSegments() uint
+
+	// SetDebugLoggingNamespaces sets the IDs of the namespaces for which debug
+	// level logging is enabled regardless of the process wide log level.
+	SetDebugLoggingNamespaces(value []string) Options
+
+	// DebugLoggingNamespaces returns the IDs of the namespaces for which debug
+	// level logging is enabled regardless of the process wide log level.
+	DebugLoggingNamespaces() []string
 }
 
 // OptionsManager updates and supplies runtime options.
@@ -190,6 +270,14 @@ type OptionsManager interface {
 	// Get returns the current values.
 	Get() Options
 
+	// Export serializes the full set of current runtime options as a single
+	// blob that can be imported with Import.
+	Export() ([]byte, error)
+
+	// Import atomically updates the current runtime options to those
+	// serialized in a blob created by Export, validating them first.
+	Import(data []byte) error
+
 	// RegisterListener registers a listener for updates to runtime options,
 	// it will synchronously call back the listener when this method is called
 	// to deliver the current set of runtime options.
@@ -0,0 +1,311 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/m3db/m3/src/cmd/services/m3dbnode/config"
+	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
+	"github.com/m3db/m3/src/dbnode/topology"
+	xconfig "github.com/m3db/m3/src/x/config"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+var errNoDBConfiguration = errors.New("configuration does not contain a db section")
+
+// configReloader watches the configuration files and applies changes to
+// the subset of fields that are safe to change at runtime, changes to any
+// other field are logged as requiring a restart and ignored.
+type configReloader struct {
+	configFiles    []string
+	logLevel       *zap.AtomicLevel
+	topo           topology.Topology
+	runtimeOptsMgr m3dbruntime.OptionsManager
+	logger         *zap.Logger
+	watcher        *fsnotify.Watcher
+	current        config.DBConfiguration
+	closedCh       chan struct{}
+	doneCh         chan struct{}
+}
+
+// newConfigReloader starts watching the configuration files, logLevel may be
+// nil if the logger is not owned by the server in which case changes to the
+// log level are ignored.
+func newConfigReloader(
+	configFiles []string,
+	logLevel *zap.AtomicLevel,
+	topo topology.Topology,
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+	logger *zap.Logger,
+) (*configReloader, error) {
+	current, err := loadDBConfiguration(configFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	// Watch the parent directories rather than the files themselves so that
+	// files replaced by a rename, as most editors do, are still observed.
+	dirs := make(map[string]struct{}, len(configFiles))
+	for _, file := range configFiles {
+		dir := filepath.Dir(file)
+		if _, ok := dirs[dir]; ok {
+			continue
+		}
+		dirs[dir] = struct{}{}
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("could not watch config dir %s: %v", dir, err)
+		}
+	}
+
+	r := &configReloader{
+		configFiles:    configFiles,
+		logLevel:       logLevel,
+		topo:           topo,
+		runtimeOptsMgr: runtimeOptsMgr,
+		logger:         logger,
+		watcher:        watcher,
+		current:        current,
+		closedCh:       make(chan struct{}),
+		doneCh:         make(chan struct{}),
+	}
+	go r.watch()
+	return r, nil
+}
+
+func (r *configReloader) watch() {
+	defer close(r.doneCh)
+
+	for {
+		select {
+		case <-r.closedCh:
+			return
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 ||
+				!r.isConfigFile(event.Name) {
+				continue
+			}
+			r.reload()
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			r.logger.Warn("config file watch error", zap.Error(err))
+		}
+	}
+}
+
+func (r *configReloader) isConfigFile(name string) bool {
+	name = filepath.Clean(name)
+	for _, file := range r.configFiles {
+		if filepath.Clean(file) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *configReloader) reload() {
+	cfg, err := loadDBConfiguration(r.configFiles)
+	if err != nil {
+		r.logger.Error("could not reload configuration",
+			zap.Strings("files", r.configFiles), zap.Error(err))
+		return
+	}
+	r.update(cfg)
+}
+
+// update applies the fields of the configuration that can be changed at
+// runtime, changes to any other field are logged and ignored.
+func (r *configReloader) update(cfg config.DBConfiguration) {
+	masked := cfg
+	copyRuntimeConfigFields(&masked, r.current)
+	if changed := changedConfigFields(r.current, masked); len(changed) > 0 {
+		r.logger.Warn("configuration change requires restart, ignoring",
+			zap.Strings("fields", changed))
+	}
+
+	next := r.current
+	copyRuntimeConfigFields(&next, cfg)
+	r.apply(next)
+}
+
+func (r *configReloader) apply(next config.DBConfiguration) {
+	prev := r.current
+
+	if next.Logging.Level != prev.Logging.Level {
+		if err := r.applyLogLevel(next.Logging.Level); err != nil {
+			r.logger.Error("could not apply reloaded log level",
+				zap.String("level", next.Logging.Level), zap.Error(err))
+			next.Logging.Level = prev.Logging.Level
+		} else {
+			r.logger.Info("applied reloaded log level",
+				zap.String("level", next.Logging.Level))
+		}
+	}
+
+	var (
+		runtimeOpts    = r.runtimeOptsMgr.Get()
+		runtimeChanged bool
+	)
+	if !reflect.DeepEqual(next.Tick, prev.Tick) {
+		defaults := m3dbruntime.NewOptions()
+		runtimeOpts = runtimeOpts.
+			SetTickSeriesBatchSize(defaults.TickSeriesBatchSize()).
+			SetTickPerSeriesSleepDuration(defaults.TickPerSeriesSleepDuration()).
+			SetTickMinimumInterval(defaults.TickMinimumInterval())
+		if tick := next.Tick; tick != nil {
+			runtimeOpts = runtimeOpts.
+				SetTickSeriesBatchSize(tick.SeriesBatchSize).
+				SetTickPerSeriesSleepDuration(tick.PerSeriesSleepDuration).
+				SetTickMinimumInterval(tick.MinimumInterval)
+		}
+		runtimeChanged = true
+	}
+	if next.Filesystem.ThroughputLimitMbpsOrDefault() != prev.Filesystem.ThroughputLimitMbpsOrDefault() ||
+		next.Filesystem.ThroughputCheckEveryOrDefault() != prev.Filesystem.ThroughputCheckEveryOrDefault() {
+		runtimeOpts = runtimeOpts.SetPersistRateLimitOptions(runtimeOpts.PersistRateLimitOptions().
+			SetLimitMbps(next.Filesystem.ThroughputLimitMbpsOrDefault()).
+			SetLimitCheckEvery(next.Filesystem.ThroughputCheckEveryOrDefault()))
+		runtimeChanged = true
+	}
+	if next.WriteNewSeriesBackoffDuration != prev.WriteNewSeriesBackoffDuration {
+		runtimeOpts = runtimeOpts.SetWriteNewSeriesBackoffDuration(next.WriteNewSeriesBackoffDuration)
+		runtimeChanged = true
+	}
+	if runtimeChanged {
+		if err := r.runtimeOptsMgr.Update(runtimeOpts); err != nil {
+			r.logger.Error("could not apply reloaded runtime options", zap.Error(err))
+			next.Tick = prev.Tick
+			next.Filesystem.ThroughputLimitMbps = prev.Filesystem.ThroughputLimitMbps
+			next.Filesystem.ThroughputCheckEvery = prev.Filesystem.ThroughputCheckEvery
+			next.WriteNewSeriesBackoffDuration = prev.WriteNewSeriesBackoffDuration
+		} else {
+			r.logger.Info("applied reloaded runtime options")
+		}
+	}
+
+	if next.WriteNewSeriesLimitPerSecond != prev.WriteNewSeriesLimitPerSecond {
+		err := setNewSeriesLimitPerShardOnChange(r.topo, r.runtimeOptsMgr,
+			next.WriteNewSeriesLimitPerSecond)
+		if err != nil {
+			r.logger.Error("could not apply reloaded new series limit", zap.Error(err))
+			next.WriteNewSeriesLimitPerSecond = prev.WriteNewSeriesLimitPerSecond
+		} else {
+			r.logger.Info("applied reloaded new series limit",
+				zap.Int("limit", next.WriteNewSeriesLimitPerSecond))
+		}
+	}
+
+	r.current = next
+}
+
+func (r *configReloader) applyLogLevel(level string) error {
+	if r.logLevel == nil {
+		return errors.New("logger is not owned by the server")
+	}
+
+	parsed := zap.NewAtomicLevel()
+	if level != "" {
+		if err := parsed.UnmarshalText([]byte(level)); err != nil {
+			return err
+		}
+	}
+	r.logLevel.SetLevel(parsed.Level())
+	return nil
+}
+
+// Close stops watching the configuration files.
+func (r *configReloader) Close() error {
+	close(r.closedCh)
+	err := r.watcher.Close()
+	<-r.doneCh
+	return err
+}
+
+func loadDBConfiguration(configFiles []string) (config.DBConfiguration, error) {
+	var rootCfg config.Configuration
+	if err := xconfig.LoadFiles(&rootCfg, configFiles, xconfig.Options{}); err != nil {
+		return config.DBConfiguration{}, err
+	}
+	if rootCfg.DB == nil {
+		return config.DBConfiguration{}, errNoDBConfiguration
+	}
+
+	cfg := *rootCfg.DB
+	if err := cfg.InitDefaultsAndValidate(); err != nil {
+		return config.DBConfiguration{}, err
+	}
+	return cfg, nil
+}
+
+// copyRuntimeConfigFields copies the fields that can be applied without a
+// restart from src to dst.
+func copyRuntimeConfigFields(dst *config.DBConfiguration, src config.DBConfiguration) {
+	dst.Logging.Level = src.Logging.Level
+	dst.Tick = src.Tick
+	dst.Filesystem.ThroughputLimitMbps = src.Filesystem.ThroughputLimitMbps
+	dst.Filesystem.ThroughputCheckEvery = src.Filesystem.ThroughputCheckEvery
+	dst.WriteNewSeriesLimitPerSecond = src.WriteNewSeriesLimitPerSecond
+	dst.WriteNewSeriesBackoffDuration = src.WriteNewSeriesBackoffDuration
+}
+
+// changedConfigFields returns the YAML names of the top level fields that
+// differ between the two configurations.
+func changedConfigFields(a, b config.DBConfiguration) []string {
+	var (
+		av      = reflect.ValueOf(a)
+		bv      = reflect.ValueOf(b)
+		t       = av.Type()
+		changed []string
+	)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// Unexported.
+			continue
+		}
+		if reflect.DeepEqual(av.Field(i).Interface(), bv.Field(i).Interface()) {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		changed = append(changed, name)
+	}
+	return changed
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cmd/services/m3dbnode/config"
+	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
+	xlog "github.com/m3db/m3/src/x/log"
+
+	"github.com/fortytw2/leaktest"
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newTestConfigReloader(
+	current config.DBConfiguration,
+) (*configReloader, zap.AtomicLevel) {
+	logLevel := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	return &configReloader{
+		logLevel:       &logLevel,
+		runtimeOptsMgr: m3dbruntime.NewOptionsManager(),
+		logger:         zap.NewNop(),
+		current:        current,
+	}, logLevel
+}
+
+func TestConfigReloaderUpdate(t *testing.T) {
+	base := config.DBConfiguration{
+		Logging:       xlog.Configuration{Level: "info"},
+		ListenAddress: "0.0.0.0:9000",
+	}
+
+	tests := []struct {
+		name    string
+		update  func(cfg *config.DBConfiguration)
+		restart []string
+		verify  func(t *testing.T, r *configReloader, logLevel zap.AtomicLevel)
+	}{
+		{
+			name: "log level",
+			update: func(cfg *config.DBConfiguration) {
+				cfg.Logging.Level = "debug"
+			},
+			verify: func(t *testing.T, r *configReloader, logLevel zap.AtomicLevel) {
+				require.Equal(t, "debug", r.current.Logging.Level)
+				require.Equal(t, zapcore.DebugLevel, logLevel.Level())
+			},
+		},
+		{
+			name: "tick",
+			update: func(cfg *config.DBConfiguration) {
+				cfg.Tick = &config.TickConfiguration{
+					SeriesBatchSize:        64,
+					PerSeriesSleepDuration: time.Millisecond,
+					MinimumInterval:        time.Minute,
+				}
+			},
+			verify: func(t *testing.T, r *configReloader, _ zap.AtomicLevel) {
+				runtimeOpts := r.runtimeOptsMgr.Get()
+				require.Equal(t, 64, runtimeOpts.TickSeriesBatchSize())
+				require.Equal(t, time.Millisecond, runtimeOpts.TickPerSeriesSleepDuration())
+				require.Equal(t, time.Minute, runtimeOpts.TickMinimumInterval())
+				require.Equal(t, 64, r.current.Tick.SeriesBatchSize)
+			},
+		},
+		{
+			name: "write new series backoff",
+			update: func(cfg *config.DBConfiguration) {
+				cfg.WriteNewSeriesBackoffDuration = 10 * time.Millisecond
+			},
+			verify: func(t *testing.T, r *configReloader, _ zap.AtomicLevel) {
+				require.Equal(t, 10*time.Millisecond,
+					r.runtimeOptsMgr.Get().WriteNewSeriesBackoffDuration())
+				require.Equal(t, 10*time.Millisecond, r.current.WriteNewSeriesBackoffDuration)
+			},
+		},
+		{
+			name: "invalid write new series backoff",
+			update: func(cfg *config.DBConfiguration) {
+				cfg.WriteNewSeriesBackoffDuration = -time.Millisecond
+			},
+			verify: func(t *testing.T, r *configReloader, _ zap.AtomicLevel) {
+				require.Equal(t, m3dbruntime.NewOptions().WriteNewSeriesBackoffDuration(),
+					r.runtimeOptsMgr.Get().WriteNewSeriesBackoffDuration())
+				require.Equal(t, time.Duration(0), r.current.WriteNewSeriesBackoffDuration)
+			},
+		},
+		{
+			name: "listen address",
+			update: func(cfg *config.DBConfiguration) {
+				cfg.ListenAddress = "0.0.0.0:9999"
+			},
+			restart: []string{"listenAddress"},
+			verify: func(t *testing.T, r *configReloader, _ zap.AtomicLevel) {
+				require.Equal(t, "0.0.0.0:9000", r.current.ListenAddress)
+			},
+		},
+		{
+			name: "listen address and log level",
+			update: func(cfg *config.DBConfiguration) {
+				cfg.ListenAddress = "0.0.0.0:9999"
+				cfg.Logging.Level = "warn"
+			},
+			restart: []string{"listenAddress"},
+			verify: func(t *testing.T, r *configReloader, logLevel zap.AtomicLevel) {
+				require.Equal(t, "0.0.0.0:9000", r.current.ListenAddress)
+				require.Equal(t, "warn", r.current.Logging.Level)
+				require.Equal(t, zapcore.WarnLevel, logLevel.Level())
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, logLevel := newTestConfigReloader(base)
+
+			cfg := base
+			test.update(&cfg)
+
+			masked := cfg
+			copyRuntimeConfigFields(&masked, base)
+			require.Equal(t, test.restart, changedConfigFields(base, masked))
+
+			r.update(cfg)
+			test.verify(t, r, logLevel)
+		})
+	}
+}
+
+func TestConfigReloaderCloseStopsWatch(t *testing.T) {
+	defer leaktest.CheckTimeout(t, 5*time.Second)()
+
+	dir, err := ioutil.TempDir("", "config-reload")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	watcher, err := fsnotify.NewWatcher()
+	require.NoError(t, err)
+	require.NoError(t, watcher.Add(dir))
+
+	r, _ := newTestConfigReloader(config.DBConfiguration{})
+	r.watcher = watcher
+	r.closedCh = make(chan struct{})
+	r.doneCh = make(chan struct{})
+	go r.watch()
+
+	require.NoError(t, r.Close())
+
+	select {
+	case <-r.doneCh:
+	default:
+		require.FailNow(t, "watch goroutine still running after close")
+	}
+}
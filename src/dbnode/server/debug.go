@@ -0,0 +1,628 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/topology"
+	xerrors "github.com/m3db/m3/src/x/errors"
+	"github.com/m3db/m3/src/x/ident"
+	"github.com/m3db/m3/src/x/pool"
+)
+
+const (
+	debugShardRoutePath    = "/debug/shard-route"
+	debugShardRouteIDParam = "id"
+
+	debugSeriesBufferPath           = "/debug/series-buffer"
+	debugSeriesBufferNamespaceParam = "namespace"
+	debugSeriesBufferIDParam        = "id"
+
+	debugNamespacesPath = "/debug/namespaces"
+
+	debugShardStatsPath           = "/debug/shard-stats"
+	debugShardStatsNamespaceParam = "namespace"
+
+	debugSeriesMemoryPath           = "/debug/series-memory"
+	debugSeriesMemoryNamespaceParam = "namespace"
+	debugSeriesMemoryLimitParam     = "limit"
+	debugSeriesMemoryDefaultLimit   = 100
+
+	debugSnapshotPath            = "/debug/snapshot"
+	debugSnapshotNamespaceParam  = "namespace"
+	debugSnapshotBlockStartParam = "blockStart"
+
+	debugBytesPoolRecommendationPath = "/debug/bytes-pool-recommendation"
+
+	healthPath = "/health"
+	readyPath  = "/ready"
+)
+
+// healthHandler reports the node as healthy, it is registered once the node
+// listeners are up so any response means the process is serving requests.
+type healthHandler struct{}
+
+func newHealthHandler() http.Handler {
+	return healthHandler{}
+}
+
+func (h healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+type readyResponse struct {
+	Ready      bool                     `json:"ready"`
+	Namespaces []readyNamespaceResponse `json:"namespaces"`
+}
+
+type readyNamespaceResponse struct {
+	ID           string `json:"id"`
+	Bootstrapped bool   `json:"bootstrapped"`
+}
+
+// readyHandler reports the node as ready once the database has bootstrapped
+// every namespace, it responds with service unavailable until the database
+// is set and bootstrapped so it can be registered before the database is
+// constructed.
+type readyHandler struct {
+	sync.RWMutex
+	db storage.Database
+}
+
+func newReadyHandler() *readyHandler {
+	return &readyHandler{}
+}
+
+func (h *readyHandler) setDatabase(db storage.Database) {
+	h.Lock()
+	h.db = db
+	h.Unlock()
+}
+
+func (h *readyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.RLock()
+	db := h.db
+	h.RUnlock()
+
+	resp := readyResponse{
+		Namespaces: []readyNamespaceResponse{},
+	}
+	if db != nil {
+		resp.Ready = db.IsBootstrapped()
+		for ns, bootstrapped := range db.NamespacesBootstrapped() {
+			resp.Ready = resp.Ready && bootstrapped
+			resp.Namespaces = append(resp.Namespaces, readyNamespaceResponse{
+				ID:           ns,
+				Bootstrapped: bootstrapped,
+			})
+		}
+		sort.Slice(resp.Namespaces, func(i, j int) bool {
+			return resp.Namespaces[i].ID < resp.Namespaces[j].ID
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !resp.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type shardRouteResponse struct {
+	ID    string           `json:"id"`
+	Shard uint32           `json:"shard"`
+	Hosts []shardRouteHost `json:"hosts"`
+}
+
+type shardRouteHost struct {
+	ID         string `json:"id"`
+	Address    string `json:"address"`
+	ShardState string `json:"shardState"`
+}
+
+// shardRouteHandler returns the shard a series ID hashes to under the current
+// topology and the hosts that own that shard, it is useful for debugging
+// misrouted writes.
+type shardRouteHandler struct {
+	topo topology.Topology
+}
+
+func newShardRouteHandler(topo topology.Topology) http.Handler {
+	return &shardRouteHandler{topo: topo}
+}
+
+func (h *shardRouteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get(debugShardRouteIDParam)
+	if id == "" {
+		http.Error(w, "missing required param: "+debugShardRouteIDParam,
+			http.StatusBadRequest)
+		return
+	}
+
+	topoMap := h.topo.Get()
+	shard, hosts, err := topoMap.Route(ident.StringID(id))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := shardRouteResponse{
+		ID:    id,
+		Shard: shard,
+		Hosts: make([]shardRouteHost, 0, len(hosts)),
+	}
+	for _, host := range hosts {
+		routeHost := shardRouteHost{
+			ID:      host.ID(),
+			Address: host.Address(),
+		}
+		if hostShardSet, ok := topoMap.LookupHostShardSet(host.ID()); ok {
+			state, err := hostShardSet.ShardSet().LookupStateByID(shard)
+			if err == nil {
+				routeHost.ShardState = state.String()
+			}
+		}
+		resp.Hosts = append(resp.Hosts, routeHost)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type seriesBufferResponse struct {
+	Namespace string              `json:"namespace"`
+	ID        string              `json:"id"`
+	Blocks    []seriesBufferBlock `json:"blocks"`
+}
+
+type seriesBufferBlock struct {
+	BlockStart time.Time            `json:"blockStart"`
+	Buckets    []seriesBufferBucket `json:"buckets"`
+}
+
+type seriesBufferBucket struct {
+	Version         int    `json:"version"`
+	WriteType       string `json:"writeType"`
+	NumEncoders     int    `json:"numEncoders"`
+	NumLoadedBlocks int    `json:"numLoadedBlocks"`
+}
+
+// seriesBufferHandler returns the warm and cold buffer bucket versions held
+// in memory for a series per block start, it is useful for debugging why a
+// series does or does not get cold flushed.
+type seriesBufferHandler struct {
+	db storage.Database
+}
+
+func newSeriesBufferHandler(db storage.Database) http.Handler {
+	return &seriesBufferHandler{db: db}
+}
+
+func (h *seriesBufferHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	for _, param := range []string{
+		debugSeriesBufferNamespaceParam,
+		debugSeriesBufferIDParam,
+	} {
+		if query.Get(param) == "" {
+			http.Error(w, "missing required param: "+param, http.StatusBadRequest)
+			return
+		}
+	}
+
+	var (
+		ns = query.Get(debugSeriesBufferNamespaceParam)
+		id = query.Get(debugSeriesBufferIDParam)
+	)
+	infos, err := h.db.SeriesBufferDebugInfo(ident.StringID(ns), ident.StringID(id))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := seriesBufferResponse{
+		Namespace: ns,
+		ID:        id,
+		Blocks:    make([]seriesBufferBlock, 0, len(infos)),
+	}
+	for _, info := range infos {
+		block := seriesBufferBlock{
+			BlockStart: info.BlockStart,
+			Buckets:    make([]seriesBufferBucket, 0, len(info.Buckets)),
+		}
+		for _, bucket := range info.Buckets {
+			block.Buckets = append(block.Buckets, seriesBufferBucket{
+				Version:         bucket.Version,
+				WriteType:       bucket.WriteType.String(),
+				NumEncoders:     bucket.NumEncoders,
+				NumLoadedBlocks: bucket.NumLoadedBlocks,
+			})
+		}
+		resp.Blocks = append(resp.Blocks, block)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type namespacesResponse struct {
+	CachePolicy string              `json:"cachePolicy"`
+	Namespaces  []namespaceResponse `json:"namespaces"`
+}
+
+type namespaceResponse struct {
+	ID                string                     `json:"id"`
+	NumSeries         int64                      `json:"numSeries"`
+	NumShards         int                        `json:"numShards"`
+	BootstrapEnabled  bool                       `json:"bootstrapEnabled"`
+	FlushEnabled      bool                       `json:"flushEnabled"`
+	SnapshotEnabled   bool                       `json:"snapshotEnabled"`
+	WritesToCommitLog bool                       `json:"writesToCommitLog"`
+	CleanupEnabled    bool                       `json:"cleanupEnabled"`
+	RepairEnabled     bool                       `json:"repairEnabled"`
+	ColdWritesEnabled bool                       `json:"coldWritesEnabled"`
+	Retention         namespaceRetentionResponse `json:"retention"`
+	Index             namespaceIndexResponse     `json:"index"`
+}
+
+type namespaceRetentionResponse struct {
+	RetentionPeriod                       string `json:"retentionPeriod"`
+	FutureRetentionPeriod                 string `json:"futureRetentionPeriod"`
+	BlockSize                             string `json:"blockSize"`
+	BufferPast                            string `json:"bufferPast"`
+	BufferFuture                          string `json:"bufferFuture"`
+	BlockDataExpiry                       bool   `json:"blockDataExpiry"`
+	BlockDataExpiryAfterNotAccessedPeriod string `json:"blockDataExpiryAfterNotAccessedPeriod"`
+}
+
+type namespaceIndexResponse struct {
+	Enabled   bool   `json:"enabled"`
+	BlockSize string `json:"blockSize"`
+}
+
+// namespacesHandler returns every namespace the node serves along with its
+// effective options, it is useful for confirming what configuration a node
+// is actually running with.
+type namespacesHandler struct {
+	db storage.Database
+}
+
+func newNamespacesHandler(db storage.Database) http.Handler {
+	return &namespacesHandler{db: db}
+}
+
+func (h *namespacesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	namespaces := h.db.Namespaces()
+	resp := namespacesResponse{
+		CachePolicy: h.db.Options().SeriesCachePolicy().String(),
+		Namespaces:  make([]namespaceResponse, 0, len(namespaces)),
+	}
+	for _, ns := range namespaces {
+		var (
+			opts    = ns.Options()
+			ropts   = opts.RetentionOptions()
+			idxOpts = opts.IndexOptions()
+		)
+		resp.Namespaces = append(resp.Namespaces, namespaceResponse{
+			ID:                ns.ID().String(),
+			NumSeries:         ns.NumSeries(),
+			NumShards:         len(ns.Shards()),
+			BootstrapEnabled:  opts.BootstrapEnabled(),
+			FlushEnabled:      opts.FlushEnabled(),
+			SnapshotEnabled:   opts.SnapshotEnabled(),
+			WritesToCommitLog: opts.WritesToCommitLog(),
+			CleanupEnabled:    opts.CleanupEnabled(),
+			RepairEnabled:     opts.RepairEnabled(),
+			ColdWritesEnabled: opts.ColdWritesEnabled(),
+			Retention: namespaceRetentionResponse{
+				RetentionPeriod:                       ropts.RetentionPeriod().String(),
+				FutureRetentionPeriod:                 ropts.FutureRetentionPeriod().String(),
+				BlockSize:                             ropts.BlockSize().String(),
+				BufferPast:                            ropts.BufferPast().String(),
+				BufferFuture:                          ropts.BufferFuture().String(),
+				BlockDataExpiry:                       ropts.BlockDataExpiry(),
+				BlockDataExpiryAfterNotAccessedPeriod: ropts.BlockDataExpiryAfterNotAccessedPeriod().String(),
+			},
+			Index: namespaceIndexResponse{
+				Enabled:   idxOpts.Enabled(),
+				BlockSize: idxOpts.BlockSize().String(),
+			},
+		})
+	}
+	sort.Slice(resp.Namespaces, func(i, j int) bool {
+		return resp.Namespaces[i].ID < resp.Namespaces[j].ID
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type shardStatsResponse struct {
+	Namespace string              `json:"namespace"`
+	Shards    []shardStatsElement `json:"shards"`
+}
+
+type shardStatsElement struct {
+	Shard                               uint32 `json:"shard"`
+	NumSeries                           int64  `json:"numSeries"`
+	NumSeriesNotBootstrapped            int64  `json:"numSeriesNotBootstrapped"`
+	NumSeriesBlockStatesNotBootstrapped int64  `json:"numSeriesBlockStatesNotBootstrapped"`
+	ActiveBlocks                        int    `json:"activeBlocks"`
+	WiredBlocks                         int    `json:"wiredBlocks"`
+	PendingMergeBlocks                  int    `json:"pendingMergeBlocks"`
+	PendingColdWriteBlocks              int    `json:"pendingColdWriteBlocks"`
+	EstimatedMemoryBytes                int64  `json:"estimatedMemoryBytes"`
+}
+
+// shardStatsHandler returns a snapshot of the stats of each shard of a
+// namespace owned by the node, aggregated on demand from the series, it is
+// useful for finding shards that hold a disproportionate amount of data.
+type shardStatsHandler struct {
+	db storage.Database
+}
+
+func newShardStatsHandler(db storage.Database) http.Handler {
+	return &shardStatsHandler{db: db}
+}
+
+func (h *shardStatsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ns := r.URL.Query().Get(debugShardStatsNamespaceParam)
+	if ns == "" {
+		http.Error(w, "missing required param: "+debugShardStatsNamespaceParam,
+			http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.db.ShardStats(ident.StringID(ns))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := shardStatsResponse{
+		Namespace: ns,
+		Shards:    make([]shardStatsElement, 0, len(stats)),
+	}
+	for _, s := range stats {
+		resp.Shards = append(resp.Shards, shardStatsElement{
+			Shard:                               s.Shard,
+			NumSeries:                           s.NumSeries,
+			NumSeriesNotBootstrapped:            s.NumSeriesNotBootstrapped,
+			NumSeriesBlockStatesNotBootstrapped: s.NumSeriesBlockStatesNotBootstrapped,
+			ActiveBlocks:                        s.ActiveBlocks,
+			WiredBlocks:                         s.WiredBlocks,
+			PendingMergeBlocks:                  s.PendingMergeBlocks,
+			PendingColdWriteBlocks:              s.PendingColdWriteBlocks,
+			EstimatedMemoryBytes:                s.EstimatedMemoryBytes,
+		})
+	}
+	sort.Slice(resp.Shards, func(i, j int) bool {
+		return resp.Shards[i].Shard < resp.Shards[j].Shard
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type seriesMemoryResponse struct {
+	Namespace string                `json:"namespace"`
+	Series    []seriesMemoryElement `json:"series"`
+}
+
+type seriesMemoryElement struct {
+	Shard                uint32 `json:"shard"`
+	ID                   string `json:"id"`
+	EstimatedMemoryBytes int64  `json:"estimatedMemoryBytes"`
+}
+
+// seriesMemoryHandler returns the series of a namespace with the largest
+// estimated memory footprint, it is useful for capacity planning and for
+// finding series that hold a disproportionate amount of data.
+type seriesMemoryHandler struct {
+	db storage.Database
+}
+
+func newSeriesMemoryHandler(db storage.Database) http.Handler {
+	return &seriesMemoryHandler{db: db}
+}
+
+func (h *seriesMemoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ns := r.URL.Query().Get(debugSeriesMemoryNamespaceParam)
+	if ns == "" {
+		http.Error(w, "missing required param: "+debugSeriesMemoryNamespaceParam,
+			http.StatusBadRequest)
+		return
+	}
+
+	limit := debugSeriesMemoryDefaultLimit
+	if v := r.URL.Query().Get(debugSeriesMemoryLimitParam); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid param: "+debugSeriesMemoryLimitParam,
+				http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	series, err := h.db.ApproxMemoryBySeries(ident.StringID(ns), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := seriesMemoryResponse{
+		Namespace: ns,
+		Series:    make([]seriesMemoryElement, 0, len(series)),
+	}
+	for _, s := range series {
+		resp.Series = append(resp.Series, seriesMemoryElement{
+			Shard:                s.Shard,
+			ID:                   s.ID.String(),
+			EstimatedMemoryBytes: s.EstimatedMemoryBytes,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type snapshotResponse struct {
+	Namespace         string    `json:"namespace"`
+	BlockStart        time.Time `json:"blockStart"`
+	SeriesSnapshotted int       `json:"seriesSnapshotted"`
+}
+
+// snapshotHandler snapshots the unflushed data of a block of a namespace on
+// demand, it is useful to persist data ahead of a risky operation without
+// waiting for the next snapshot cycle.
+type snapshotHandler struct {
+	db storage.Database
+}
+
+func newSnapshotHandler(db storage.Database) http.Handler {
+	return &snapshotHandler{db: db}
+}
+
+func (h *snapshotHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	for _, param := range []string{
+		debugSnapshotNamespaceParam,
+		debugSnapshotBlockStartParam,
+	} {
+		if query.Get(param) == "" {
+			http.Error(w, "missing required param: "+param,
+				http.StatusBadRequest)
+			return
+		}
+	}
+
+	ns := query.Get(debugSnapshotNamespaceParam)
+	blockStart, err := parseDebugTime(query.Get(debugSnapshotBlockStartParam))
+	if err != nil {
+		http.Error(w, "invalid param: "+debugSnapshotBlockStartParam,
+			http.StatusBadRequest)
+		return
+	}
+
+	numSeries, err := h.db.ForceSnapshot(ident.StringID(ns), blockStart)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if xerrors.IsInvalidParams(err) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	resp := snapshotResponse{
+		Namespace:         ns,
+		BlockStart:        blockStart,
+		SeriesSnapshotted: numSeries,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// parseDebugTime parses a time given either in unix seconds or RFC3339.
+func parseDebugTime(value string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+type bytesPoolRecommendationResponse struct {
+	Samples     int64                     `json:"samples"`
+	Unpooled    int64                     `json:"unpooled"`
+	Current     []bytesPoolBucketResponse `json:"current"`
+	Recommended []bytesPoolBucketResponse `json:"recommended"`
+}
+
+type bytesPoolBucketResponse struct {
+	Capacity int   `json:"capacity"`
+	Count    int   `json:"count"`
+	Samples  int64 `json:"samples"`
+}
+
+// bytesPoolRecommendationHandler returns the usage of the configured bytes
+// pool buckets and the buckets recommended from the sampled capacities, it is
+// useful for tuning the bytes pool buckets of the pooling policy.
+type bytesPoolRecommendationHandler struct {
+	pool pool.SampledCheckedBytesPool
+}
+
+func newBytesPoolRecommendationHandler(p pool.SampledCheckedBytesPool) http.Handler {
+	return &bytesPoolRecommendationHandler{pool: p}
+}
+
+func (h *bytesPoolRecommendationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rec := h.pool.Recommendation()
+	resp := bytesPoolRecommendationResponse{
+		Samples:     rec.Samples,
+		Unpooled:    rec.Unpooled,
+		Current:     newBytesPoolBucketResponses(rec.Current),
+		Recommended: newBytesPoolBucketResponses(rec.Recommended),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func newBytesPoolBucketResponses(buckets []pool.BucketUsage) []bytesPoolBucketResponse {
+	resp := make([]bytesPoolBucketResponse, 0, len(buckets))
+	for _, b := range buckets {
+		resp = append(resp, bytesPoolBucketResponse{
+			Capacity: b.Capacity,
+			Count:    b.Count,
+			Samples:  b.Samples,
+		})
+	}
+	return resp
+}
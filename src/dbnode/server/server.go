@@ -21,6 +21,7 @@
 package server
 
 import (
+	stdctx "context"
 	"errors"
 	"fmt"
 	"io"
@@ -37,6 +38,7 @@ import (
 	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
 	"github.com/m3db/m3/src/cluster/kv"
 	"github.com/m3db/m3/src/cluster/kv/util"
+	"github.com/m3db/m3/src/cluster/shard"
 	"github.com/m3db/m3/src/cmd/services/m3dbnode/config"
 	"github.com/m3db/m3/src/dbnode/client"
 	"github.com/m3db/m3/src/dbnode/encoding"
@@ -80,6 +82,7 @@ import (
 	xsync "github.com/m3db/m3/src/x/sync"
 
 	"github.com/coreos/etcd/embed"
+	protobuf "github.com/golang/protobuf/proto"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/uber-go/tally"
 	"go.uber.org/zap"
@@ -88,9 +91,11 @@ import (
 const (
 	bootstrapConfigInitTimeout       = 10 * time.Second
 	serverGracefulCloseTimeout       = 10 * time.Second
+	serverDrainTimeout               = 10 * time.Second
 	bgProcessLimitInterval           = 10 * time.Second
 	maxBgProcessLimitMonitorDuration = 5 * time.Minute
-	cpuProfileDuration               = 5 * time.Second
+	minGCPercentageOverride          = 1
+	maxGCPercentageOverride          = 500
 	filePathPrefixLockFile           = ".lock"
 	defaultServiceName               = "m3dbnode"
 )
@@ -101,13 +106,30 @@ type RunOptions struct {
 	// ConfigFile is the YAML configuration file to use to run the server.
 	ConfigFile string
 
+	// ConfigFiles are YAML configuration files to use to run the server,
+	// they are loaded in order and merged with values in later files
+	// overriding those in earlier files. It is an error to specify both
+	// ConfigFile and ConfigFiles.
+	ConfigFiles []string
+
+	// WatchConfigFile when set watches ConfigFile, or ConfigFiles, and
+	// applies changes to the fields that are safe to change at runtime
+	// without a restart, changes to any other field are logged and ignored.
+	WatchConfigFile bool
+
 	// Config is an alternate way to provide configuration and will be used
-	// instead of parsing ConfigFile if ConfigFile is not specified.
+	// instead of parsing ConfigFile if neither ConfigFile nor ConfigFiles are
+	// specified.
 	Config config.DBConfiguration
 
 	// BootstrapCh is a channel to listen on to be notified of bootstrap.
 	BootstrapCh chan<- struct{}
 
+	// ReadinessCh is a channel to listen on to be notified that the node
+	// listeners are up and the database is constructed, which happens before
+	// bootstrap completes. It is always notified before BootstrapCh.
+	ReadinessCh chan<- struct{}
+
 	// EmbeddedKVCh is a channel to listen on to be notified that the embedded KV has bootstrapped.
 	EmbeddedKVCh chan<- struct{}
 
@@ -120,16 +142,61 @@ type RunOptions struct {
 	// InterruptCh is a programmatic interrupt channel to supply to
 	// interrupt and shutdown the server.
 	InterruptCh <-chan error
+
+	// Context is an optional context to manage the lifecycle of the server,
+	// its cancellation is treated the same as an interrupt and its deadline,
+	// if any, bounds the graceful close of the server.
+	Context stdctx.Context
+
+	// Tracer is an optional tracer to use instead of building one from the
+	// tracing configuration, it takes precedence over the tracing config
+	// and is not closed by the server.
+	Tracer opentracing.Tracer
+
+	// Logger is an optional logger to use instead of building one from the
+	// logging configuration, it is not synced by the server.
+	Logger *zap.Logger
+
+	// DrainTimeout is how long to wait for the node requests in flight to
+	// complete on shutdown before closing the database, defaults to 10s.
+	DrainTimeout time.Duration
+
+	// GracefulShutdownTimeout is how long to wait for the database to close
+	// on shutdown before exiting regardless, if set it overrides the graceful
+	// shutdown timeout of the configuration.
+	GracefulShutdownTimeout time.Duration
+
+	// BytesPoolFactory is an optional factory used to construct the bytes
+	// pool when the pooling policy type is set to external.
+	BytesPoolFactory storage.BytesPoolFactory
+
+	// ValidateOnly when set loads and validates the configuration and builds
+	// the logger then returns without starting the server, it does not
+	// acquire the file path prefix lock so it can run alongside a live node.
+	ValidateOnly bool
 }
 
 // Run runs the server programmatically given a filename for the
 // configuration file.
 func Run(runOpts RunOptions) {
-	var cfg config.DBConfiguration
+	if runOpts.ConfigFile != "" && len(runOpts.ConfigFiles) > 0 {
+		fmt.Fprintf(os.Stderr, "only one of config file and config files may be specified")
+		os.Exit(1)
+	}
+
+	configFiles := runOpts.ConfigFiles
 	if runOpts.ConfigFile != "" {
+		configFiles = []string{runOpts.ConfigFile}
+	}
+
+	startup := newStartupTimer()
+	configLoadDone := startup.phase(startupPhaseConfigLoad)
+
+	var cfg config.DBConfiguration
+	if len(configFiles) > 0 {
 		var rootCfg config.Configuration
-		if err := xconfig.LoadFile(&rootCfg, runOpts.ConfigFile, xconfig.Options{}); err != nil {
-			fmt.Fprintf(os.Stderr, "unable to load %s: %v", runOpts.ConfigFile, err)
+		if err := xconfig.LoadFiles(&rootCfg, configFiles, xconfig.Options{}); err != nil {
+			fmt.Fprintf(os.Stderr, "unable to load %v: %v", configFiles, err)
 			os.Exit(1)
 		}
 
@@ -143,16 +210,42 @@ func Run(runOpts RunOptions) {
 		fmt.Fprintf(os.Stderr, "error initializing config defaults and validating config: %v", err)
 		os.Exit(1)
 	}
+	configLoadDone()
 
-	logger, err := cfg.Logging.BuildLogger()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "unable to create logger: %v", err)
-		os.Exit(1)
+	var (
+		logger   = runOpts.Logger
+		logLevel *zap.AtomicLevel
+	)
+	if logger == nil {
+		var level zap.AtomicLevel
+		logger, level, err = cfg.Logging.BuildLoggerWithLevel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to create logger: %v", err)
+			os.Exit(1)
+		}
+		// NB: Only sync loggers that are owned by the server, a supplied
+		// logger may be shared with the caller.
+		defer logger.Sync()
+		logLevel = &level
 	}
-	defer logger.Sync()
 
 	xconfig.WarnOnDeprecation(cfg, logger)
 
+	if runOpts.ValidateOnly {
+		logger.Info("configuration validated")
+		return
+	}
+
+	// NB: The run context is cancelled once the server is interrupted so
+	// that work still in flight, such as bootstrapping, can tell that it is
+	// being stopped due to shutdown.
+	runCtx := runOpts.Context
+	if runCtx == nil {
+		runCtx = stdctx.Background()
+	}
+	runCtx, cancelRun := stdctx.WithCancel(runCtx)
+	defer cancelRun()
+
 	// Raise fd limits to nr_open system limit
 	result, err := xos.RaiseProcessNoFileToNROpen()
 	if err != nil {
@@ -208,7 +301,15 @@ func Run(runOpts RunOptions) {
 		traceCloser io.Closer
 	)
 
-	if cfg.Tracing == nil {
+	if runOpts.Tracer != nil {
+		// The caller owns the lifecycle of the supplied tracer so there is
+		// nothing to close.
+		if cfg.Tracing != nil {
+			logger.Warn("tracer supplied in run options, ignoring tracing config")
+		}
+		tracer = runOpts.Tracer
+		logger.Info("tracing enabled with tracer supplied in run options")
+	} else if cfg.Tracing == nil {
 		tracer = opentracing.NoopTracer{}
 		logger.Info("tracing disabled; set `tracing.backend` to enable")
 	} else {
@@ -235,7 +336,8 @@ func Run(runOpts RunOptions) {
 		// Default etcd client clusters if not set already
 		clusters := cfg.EnvironmentConfig.Service.ETCDClusters
 		seedNodes := cfg.EnvironmentConfig.SeedNodes.InitialCluster
-		if len(clusters) == 0 {
+		defaultedClusters := len(clusters) == 0
+		if defaultedClusters {
 			endpoints, err := config.InitialClusterEndpoints(seedNodes)
 			if err != nil {
 				logger.Fatal("unable to create etcd clusters", zap.Error(err))
@@ -269,32 +371,57 @@ func Run(runOpts RunOptions) {
 				logger.Fatal("unable to create etcd config", zap.Error(err))
 			}
 
+			etcdStartDone := startup.phase(startupPhaseEtcdStart)
 			e, err := embed.StartEtcd(etcdCfg)
+			etcdStartDone()
 			if err != nil {
-				logger.Fatal("could not start embedded etcd", zap.Error(err))
-			}
+				if !cfg.EnvironmentConfig.SeedNodes.ContinueOnEmbeddedKVFailure {
+					logger.Fatal("could not start embedded etcd", zap.Error(err))
+				}
+				logger.Error("could not start embedded etcd, continuing as non-seed node",
+					zap.Error(err))
+
+				if defaultedClusters {
+					// Only use the remaining seed nodes since this node's
+					// etcd server is not running.
+					endpoints, err := config.InitialClusterEndpoints(
+						config.PeerSeedNodes(seedNodes, hostID))
+					if err != nil {
+						logger.Fatal("unable to create etcd clusters", zap.Error(err))
+					}
+					if len(endpoints) == 0 {
+						logger.Fatal("no remaining seed nodes to use as etcd cluster")
+					}
+
+					logger.Info("using remaining seed nodes etcd cluster",
+						zap.Strings("endpoints", endpoints))
+					cfg.EnvironmentConfig.Service.ETCDClusters[0].Endpoints = endpoints
+				}
+			} else {
+				if runOpts.EmbeddedKVCh != nil {
+					// Notify on embedded KV bootstrap chan if specified
+					runOpts.EmbeddedKVCh <- struct{}{}
+				}
 
-			if runOpts.EmbeddedKVCh != nil {
-				// Notify on embedded KV bootstrap chan if specified
-				runOpts.EmbeddedKVCh <- struct{}{}
+				defer e.Close()
 			}
-
-			defer e.Close()
 		}
 	}
 
-	opts := storage.NewOptions()
+	opts := storage.NewOptions().
+		SetBytesPoolFactory(runOpts.BytesPoolFactory)
 	iopts := opts.InstrumentOptions().
 		SetLogger(logger).
 		SetMetricsScope(scope).
 		SetMetricsSamplingRate(cfg.Metrics.SampleRate()).
+		SetDebugMetricsEnabled(cfg.Metrics.DebugMetrics).
 		SetTracer(tracer)
 	opts = opts.SetInstrumentOptions(iopts)
 
 	opentracing.SetGlobalTracer(tracer)
 
 	debugWriter, err := xdebug.NewZipWriterWithDefaultSources(
-		cpuProfileDuration,
+		cfg.DebugProfile.CPUProfileDurationOrDefault(),
 		iopts,
 	)
 	if err != nil {
@@ -321,10 +448,16 @@ func Run(runOpts RunOptions) {
 			SetLimitMbps(cfg.Filesystem.ThroughputLimitMbpsOrDefault()).
 			SetLimitCheckEvery(cfg.Filesystem.ThroughputCheckEveryOrDefault())).
 		SetWriteNewSeriesAsync(cfg.WriteNewSeriesAsync).
-		SetWriteNewSeriesBackoffDuration(cfg.WriteNewSeriesBackoffDuration)
+		SetWriteNewSeriesBackoffDuration(cfg.WriteNewSeriesBackoffDuration).
+		SetMaxReadBlockReaders(cfg.Limits.MaxReadBlockReaders).
+		SetWriteTimeout(cfg.Limits.WriteTimeout).
+		SetDropDisabledColdWrites(cfg.DropDisabledColdWrites)
 	if lruCfg := cfg.Cache.SeriesConfiguration().LRU; lruCfg != nil {
 		runtimeOpts = runtimeOpts.SetMaxWiredBlocks(lruCfg.MaxBlocks)
 	}
+	if n := cfg.Limits.MaxConcurrentSnapshots; n > 0 {
+		runtimeOpts = runtimeOpts.SetMaxConcurrentSnapshots(n)
+	}
 
 	// Setup postings list cache.
 	var (
@@ -423,6 +556,12 @@ func Run(runOpts RunOptions) {
 		SetForceIndexSummariesMmapMemory(cfg.Filesystem.ForceIndexSummariesMmapMemoryOrDefault()).
 		SetForceBloomFilterMmapMemory(cfg.Filesystem.ForceBloomFilterMmapMemoryOrDefault())
 
+	if fsyncCfg := cfg.Filesystem.FsyncBatch; fsyncCfg != nil {
+		fsopts = fsopts.
+			SetFsyncBatchSize(fsyncCfg.Size).
+			SetFsyncBatchInterval(fsyncCfg.Interval)
+	}
+
 	var commitLogQueueSize int
 	specified := cfg.CommitLog.Queue.Size
 	switch cfg.CommitLog.Queue.CalculationType {
@@ -454,18 +593,53 @@ func Run(runOpts RunOptions) {
 	// Set the series cache policy.
 	seriesCachePolicy := cfg.Cache.SeriesConfiguration().Policy
 	opts = opts.SetSeriesCachePolicy(seriesCachePolicy)
+	if cfg.Cache.SeriesConfiguration().EncodedTags {
+		opts = opts.SetSeriesOptions(opts.SeriesOptions().
+			SetCacheEncodedTags(true).
+			SetTagEncoderPool(tagEncoderPool))
+	}
 
 	// Apply pooling options.
 	opts = withEncodingAndPoolingOptions(cfg, logger, opts, cfg.PoolingPolicy)
 
+	sampledBytesPool, _ := opts.BytesPool().(pool.SampledCheckedBytesPool)
+	if sampledBytesPool != nil {
+		stopReporting := startBytesPoolRecommendationReport(sampledBytesPool,
+			cfg.PoolingPolicy.BytesPoolSampling.ReportIntervalOrDefault(), logger)
+		defer stopReporting()
+	}
+
+	if v := cfg.Bootstrap.VerifyBlocksOnLoad; v != nil && *v {
+		opts = opts.SetSeriesOptions(opts.SeriesOptions().SetVerifyBlocksOnLoad(true))
+	}
+	if v := cfg.Bootstrap.MergeDuplicateSeriesBootstrap; v != nil && *v {
+		opts = opts.SetSeriesOptions(opts.SeriesOptions().SetMergeDuplicateBootstrap(true))
+	}
+	if v := cfg.Bootstrap.PartialFailure; v != nil && v.Enabled {
+		opts = opts.SetBootstrapFailureRetryInterval(v.RetryIntervalOrDefault())
+	}
+	if v := cfg.Bootstrap.NamespaceConcurrency; v > 0 {
+		opts = opts.SetBootstrapConcurrency(v)
+	}
+	if v := cfg.MaxEncodersPerBufferBucket; v > 0 {
+		opts = opts.SetSeriesOptions(opts.SeriesOptions().SetMaxEncodersPerBucket(v))
+	}
+	if v := cfg.SeriesIdleEvictionTimeout; v > 0 {
+		opts = opts.SetSeriesOptions(opts.SeriesOptions().SetIdleEvictionTimeout(v))
+	}
+
 	opts = opts.SetCommitLogOptions(opts.CommitLogOptions().
 		SetInstrumentOptions(opts.InstrumentOptions()).
 		SetFilesystemOptions(fsopts).
-		SetStrategy(commitlog.StrategyWriteBehind).
+		SetStrategy(cfg.CommitLog.StrategyOrDefault()).
 		SetFlushSize(cfg.CommitLog.FlushMaxBytes).
 		SetFlushInterval(cfg.CommitLog.FlushEvery).
 		SetBacklogQueueSize(commitLogQueueSize).
-		SetBacklogQueueChannelSize(commitLogQueueChannelSize))
+		SetBacklogQueueChannelSize(commitLogQueueChannelSize).
+		SetDurableAckNamespaces(cfg.CommitLog.DurableAckNamespaces).
+		SetRotateSize(cfg.CommitLog.RotateMaxBytes).
+		SetRotateInterval(cfg.CommitLog.RotateEvery).
+		SetBackpressureHighWatermark(cfg.CommitLog.BackpressureHighWatermark))
 
 	// Setup the block retriever
 	switch seriesCachePolicy {
@@ -481,7 +655,14 @@ func Run(runOpts RunOptions) {
 			SetBlockLeaseManager(blockLeaseManager)
 		if blockRetrieveCfg := cfg.BlockRetrieve; blockRetrieveCfg != nil {
 			retrieverOpts = retrieverOpts.
-				SetFetchConcurrency(blockRetrieveCfg.FetchConcurrency)
+				SetFetchConcurrency(blockRetrieveCfg.FetchConcurrency).
+				SetNotFoundCacheSize(blockRetrieveCfg.NotFoundCacheSize)
+			if blockRetrieveCfg.MaxOpenFiles > 0 {
+				// NB: the limiter is shared by the retrievers of every namespace.
+				limiter := fs.NewRetrieverOpenFilesLimiter(
+					blockRetrieveCfg.MaxOpenFiles, iopts)
+				retrieverOpts = retrieverOpts.SetOpenFilesLimiter(limiter)
+			}
 		}
 		blockRetrieverMgr := block.NewDatabaseBlockRetrieverManager(
 			func(md namespace.Metadata) (block.DatabaseBlockRetriever, error) {
@@ -534,7 +715,9 @@ func Run(runOpts RunOptions) {
 		runOpts.ClusterClientCh <- envCfg.ClusterClient
 	}
 
-	opts = opts.SetNamespaceInitializer(envCfg.NamespaceInitializer)
+	opts = opts.SetNamespaceInitializer(envCfg.NamespaceInitializer).
+		SetMaxNamespaceRetentionPeriod(cfg.MaxNamespaceRetentionPeriod).
+		SetConsistencyCheckSampleRate(cfg.ConsistencyCheckSampleRate)
 
 	// Set tchannelthrift options.
 	ttopts := tchannelthrift.NewOptions().
@@ -545,7 +728,23 @@ func Run(runOpts RunOptions) {
 		SetTagEncoderPool(tagEncoderPool).
 		SetTagDecoderPool(tagDecoderPool).
 		SetMaxOutstandingWriteRequests(cfg.Limits.MaxOutstandingWriteRequests).
-		SetMaxOutstandingReadRequests(cfg.Limits.MaxOutstandingReadRequests)
+		SetMaxOutstandingReadRequests(cfg.Limits.MaxOutstandingReadRequests).
+		SetNamespaceTraceSampleRates(cfg.TracingNamespaceSampleRates).
+		SetReadProxyWhileBootstrappingNamespaces(cfg.ReadProxyWhileBootstrappingNamespaces).
+		SetBootstrappedReadinessGracePeriod(cfg.BootstrappedReadinessGracePeriod).
+		SetAdminAuditLogEnabled(cfg.AdminAuditLog.Enabled).
+		SetAdminAuditMetricsEnabled(cfg.AdminAuditLog.MetricsEnabled)
+
+	// In strict startup mode verify the node can actually start before binding any
+	// listeners so that orchestration tools never route to a doomed instance.
+	var topo topology.Topology
+	if cfg.StrictStartup {
+		topo, err = runStartupPreflightChecks(cfg, envCfg.TopologyInitializer, logger)
+		if err != nil {
+			logger.Fatal("startup pre-flight checks failed", zap.Error(err))
+		}
+		logger.Info("startup pre-flight checks passed")
+	}
 
 	// Start servers before constructing the DB so orchestration tools can check health endpoints
 	// before topology is set.
@@ -562,7 +761,6 @@ func Run(runOpts RunOptions) {
 		logger.Fatal("could not open tchannelthrift interface",
 			zap.String("address", cfg.ListenAddress), zap.Error(err))
 	}
-	defer tchannelthriftNodeClose()
 	logger.Info("node tchannelthrift: listening", zap.String("address", cfg.ListenAddress))
 
 	httpjsonNodeClose, err := hjnode.NewServer(service,
@@ -571,10 +769,12 @@ func Run(runOpts RunOptions) {
 		logger.Fatal("could not open httpjson interface",
 			zap.String("address", cfg.HTTPNodeListenAddress), zap.Error(err))
 	}
-	defer httpjsonNodeClose()
 	logger.Info("node httpjson: listening", zap.String("address", cfg.HTTPNodeListenAddress))
 
+	ready := newReadyHandler()
 	if cfg.DebugListenAddress != "" {
+		http.DefaultServeMux.Handle(healthPath, newHealthHandler())
+		http.DefaultServeMux.Handle(readyPath, ready)
 		go func() {
 			mux := http.DefaultServeMux
 			if debugWriter != nil {
@@ -594,9 +794,17 @@ func Run(runOpts RunOptions) {
 		}()
 	}
 
-	topo, err := envCfg.TopologyInitializer.Init()
-	if err != nil {
-		logger.Fatal("could not initialize m3db topology", zap.Error(err))
+	if topo == nil {
+		topologyInitDone := startup.phase(startupPhaseTopologyInit)
+		topo, err = envCfg.TopologyInitializer.Init()
+		if err != nil {
+			logger.Fatal("could not initialize m3db topology", zap.Error(err))
+		}
+		topologyInitDone()
+	}
+
+	if cfg.DebugListenAddress != "" {
+		http.DefaultServeMux.Handle(debugShardRoutePath, newShardRouteHandler(topo))
 	}
 
 	var protoEnabled bool
@@ -618,6 +826,7 @@ func Run(runOpts RunOptions) {
 	}
 
 	origin := topology.NewHost(hostID, "")
+	clientCreationDone := startup.phase(startupPhaseClientCreation)
 	m3dbClient, err := cfg.Client.NewAdminClient(
 		client.ConfigurationParameters{
 			InstrumentOptions: iopts.
@@ -648,15 +857,38 @@ func Run(runOpts RunOptions) {
 	if err != nil {
 		logger.Fatal("could not create m3db client", zap.Error(err))
 	}
+	clientCreationDone()
 
 	if runOpts.ClientCh != nil {
 		runOpts.ClientCh <- m3dbClient
 	}
 
+	if len(cfg.ReadProxyWhileBootstrappingNamespaces) > 0 {
+		// Reads are proxied to peers with the admin client until bootstrapped.
+		if err := service.SetReadProxyClient(m3dbClient); err != nil {
+			logger.Fatal("could not set read proxy client", zap.Error(err))
+		}
+	}
+
 	// Kick off runtime options manager KV watches
 	clientAdminOpts := m3dbClient.Options().(client.AdminOptions)
-	kvWatchClientConsistencyLevels(envCfg.KVStore, logger,
+	kvWatchClientConsistencyLevels(envCfg.KVStore, logger, scope,
 		clientAdminOpts, runtimeOptsMgr)
+	kvWatchMaxReadBlockReaders(envCfg.KVStore, logger, scope,
+		runtimeOptsMgr, cfg.Limits.MaxReadBlockReaders)
+	kvWatchFetchConcurrency(envCfg.KVStore, logger, scope, runtimeOptsMgr)
+	kvWatchWriteTimeout(envCfg.KVStore, logger, scope,
+		runtimeOptsMgr, cfg.Limits.WriteTimeout)
+	kvWatchMaxPendingNewSeriesInserts(envCfg.KVStore, logger, scope,
+		runtimeOptsMgr, cfg.Limits.MaxPendingNewSeriesInsertsPerShard)
+	kvWatchDebugLoggingNamespaces(envCfg.KVStore, logger, scope, runtimeOptsMgr)
+	kvWatchGCPercentage(envCfg.KVStore, logger, scope, cfg.GCPercentage)
+	kvWatchPoolRefillHighWatermark(envCfg.KVStore, logger, scope,
+		kvconfig.TagEncoderPoolRefillHighWatermarkKey, tagEncoderPool,
+		policy.TagEncoderPool.RefillHighWaterMarkOrDefault())
+	kvWatchPoolRefillHighWatermark(envCfg.KVStore, logger, scope,
+		kvconfig.TagDecoderPoolRefillHighWatermarkKey, tagDecoderPool,
+		policy.TagDecoderPool.RefillHighWaterMarkOrDefault())
 
 	opts = opts.SetRepairEnabled(false)
 	if cfg.Repair != nil {
@@ -667,13 +899,22 @@ func Run(runOpts RunOptions) {
 			SetRepairThrottle(cfg.Repair.Throttle).
 			SetRepairCheckInterval(cfg.Repair.CheckInterval).
 			SetAdminClient(m3dbClient).
-			SetDebugShadowComparisonsEnabled(cfg.Repair.DebugShadowComparisonsEnabled)
+			SetDebugShadowComparisonsEnabled(cfg.Repair.DebugShadowComparisonsEnabled).
+			SetDryRun(cfg.Repair.DryRun)
 
 		if cfg.Repair.DebugShadowComparisonsPercentage > 0 {
 			// Set conditionally to avoid stomping on the default value of 1.0.
 			repairOpts = repairOpts.SetDebugShadowComparisonsPercentage(cfg.Repair.DebugShadowComparisonsPercentage)
 		}
 
+		if v := cfg.Repair.PeerSelection; v != nil {
+			if err := v.ValidateReplicas(topo.Get().Replicas()); err != nil {
+				logger.Fatal("invalid repair peer selection",
+					zap.Stringer("peerSelection", *v), zap.Error(err))
+			}
+			repairOpts = repairOpts.SetPeerSelection(*v)
+		}
+
 		opts = opts.
 			SetRepairEnabled(cfg.Repair.Enabled).
 			SetRepairOptions(repairOpts)
@@ -688,8 +929,17 @@ func Run(runOpts RunOptions) {
 	// recent as the one that triggered the bootstrap, if not newer.
 	// See GitHub issue #1013 for more details.
 	topoMapProvider := newTopoMapProvider(topo)
+
+	// NB: Cancel the bootstrap process once the run context is cancelled so
+	// that an in flight bootstrap does not keep running during shutdown.
+	bootstrapCancellable := context.NewCancellable()
+	go func() {
+		<-runCtx.Done()
+		bootstrapCancellable.Cancel()
+	}()
+
 	bs, err := cfg.Bootstrap.New(config.NewBootstrapConfigurationValidator(),
-		opts, topoMapProvider, origin, m3dbClient)
+		opts, topoMapProvider, origin, m3dbClient, bootstrapCancellable)
 	if err != nil {
 		logger.Fatal("could not create bootstrap process", zap.Error(err))
 	}
@@ -710,19 +960,17 @@ func Run(runOpts RunOptions) {
 		}
 	}()
 
-	kvWatchBootstrappers(envCfg.KVStore, logger, timeout, cfg.Bootstrap.Bootstrappers,
-		func(bootstrappers []string) {
+	kvWatchBootstrappers(envCfg.KVStore, logger, scope, timeout, cfg.Bootstrap.Bootstrappers,
+		func(bootstrappers []string) error {
 			if len(bootstrappers) == 0 {
-				logger.Error("updated bootstrapper list is empty")
-				return
+				return errors.New("updated bootstrapper list is empty")
 			}
 
 			cfg.Bootstrap.Bootstrappers = bootstrappers
 			updated, err := cfg.Bootstrap.New(config.NewBootstrapConfigurationValidator(),
-				opts, topoMapProvider, origin, m3dbClient)
+				opts, topoMapProvider, origin, m3dbClient, bootstrapCancellable)
 			if err != nil {
-				logger.Error("updated bootstrapper list failed", zap.Error(err))
-				return
+				return fmt.Errorf("updated bootstrapper list failed: %v", err)
 			}
 
 			bs.SetBootstrapperProvider(updated.BootstrapperProvider())
@@ -733,6 +981,7 @@ func Run(runOpts RunOptions) {
 					zap.Error(err),
 				)
 			}
+			return nil
 		})
 
 	// Start the cluster services now that the M3DB client is available.
@@ -771,36 +1020,124 @@ func Run(runOpts RunOptions) {
 	leaseVerifier := storage.NewLeaseVerifier(db)
 	blockLeaseManager.SetLeaseVerifier(leaseVerifier)
 
+	dbOpenDone := startup.phase(startupPhaseDBOpen)
 	if err := db.Open(); err != nil {
 		logger.Fatal("could not open database", zap.Error(err))
 	}
+	dbOpenDone()
 
 	// Now that we've initialized the database we can set it on the service.
 	service.SetDatabase(db)
+	ready.setDatabase(db)
+
+	if cfg.DebugListenAddress != "" {
+		http.DefaultServeMux.Handle(debugSeriesBufferPath, newSeriesBufferHandler(db))
+		http.DefaultServeMux.Handle(debugNamespacesPath, newNamespacesHandler(db))
+		http.DefaultServeMux.Handle(debugShardStatsPath, newShardStatsHandler(db))
+		http.DefaultServeMux.Handle(debugSeriesMemoryPath, newSeriesMemoryHandler(db))
+		if cfg.DebugForceSnapshotEnabled {
+			http.DefaultServeMux.Handle(debugSnapshotPath, newSnapshotHandler(db))
+		}
+		if sampledBytesPool != nil {
+			http.DefaultServeMux.Handle(debugBytesPoolRecommendationPath,
+				newBytesPoolRecommendationHandler(sampledBytesPool))
+		}
+	}
+
+	// Notify on readiness chan if specified, this is done asynchronously so
+	// that bootstrapping does not wait on the listener.
+	readinessNotifiedCh := make(chan struct{})
+	go func() {
+		defer close(readinessNotifiedCh)
+		if runOpts.ReadinessCh != nil {
+			runOpts.ReadinessCh <- struct{}{}
+		}
+	}()
 
 	go func() {
 		if runOpts.BootstrapCh != nil {
 			// Notify on bootstrap chan if specified.
 			defer func() {
+				// Ensure readiness is always notified before bootstrap.
+				<-readinessNotifiedCh
 				runOpts.BootstrapCh <- struct{}{}
 			}()
 		}
 
 		// Bootstrap asynchronously so we can handle interrupt.
+		bootstrapDone := startup.phase(startupPhaseBootstrap)
 		if err := db.Bootstrap(); err != nil {
+			if runCtx.Err() != nil {
+				// The bootstrap process is cancelled along with the run
+				// context, this is expected so is not fatal.
+				logger.Warn("bootstrap cancelled", zap.Error(err))
+				return
+			}
 			logger.Fatal("could not bootstrap database", zap.Error(err))
 		}
+		bootstrapDone()
 		logger.Info("bootstrapped")
+		startup.report(scope, logger)
+
+		if verifyCfg := cfg.Bootstrap.Verify; verifyCfg != nil && verifyCfg.Enabled {
+			verifyBootstrap(db, topoMapProvider, hostID, *verifyCfg, service,
+				iopts.MetricsScope(), logger)
+		}
 
 		// Only set the write new series limit after bootstrapping
-		kvWatchNewSeriesLimitPerShard(envCfg.KVStore, logger, topo,
+		kvWatchNewSeriesLimitPerShard(envCfg.KVStore, logger, scope, topo,
 			runtimeOptsMgr, cfg.WriteNewSeriesLimitPerSecond)
 	}()
 
+	var reloader *configReloader
+	if runOpts.WatchConfigFile {
+		if len(configFiles) == 0 {
+			logger.Warn("config file watch enabled without a config file, not watching")
+		} else {
+			reloader, err = newConfigReloader(configFiles, logLevel, topo,
+				runtimeOptsMgr, logger)
+			if err != nil {
+				logger.Error("could not watch config file", zap.Error(err))
+			} else {
+				logger.Info("watching config file for changes",
+					zap.Strings("files", configFiles))
+			}
+		}
+	}
+
 	// Wait for process interrupt.
 	xos.WaitForInterrupt(logger, xos.InterruptOptions{
 		InterruptCh: runOpts.InterruptCh,
+		Context:     runCtx,
 	})
+	cancelRun()
+
+	// Stop applying config changes before shutting down.
+	if reloader != nil {
+		if err := reloader.Close(); err != nil {
+			logger.Warn("could not close config file watch", zap.Error(err))
+		}
+	}
+
+	// Stop accepting new node requests and drain the requests in flight
+	// before closing the database so that they are not aborted.
+	tchannelthriftNodeClose()
+	httpjsonNodeClose()
+	drainTimeout := runOpts.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = serverDrainTimeout
+	}
+	if deadline, ok := runCtx.Deadline(); ok {
+		if untilDeadline := time.Until(deadline); untilDeadline < drainTimeout {
+			drainTimeout = untilDeadline
+		}
+	}
+	if service.Drain(drainTimeout) {
+		logger.Info("drained requests in flight")
+	} else {
+		logger.Warn("requests still in flight after drain timeout",
+			zap.Duration("timeout", drainTimeout))
+	}
 
 	// Attempt graceful server close.
 	closedCh := make(chan struct{})
@@ -814,10 +1151,25 @@ func Run(runOpts RunOptions) {
 
 	// Wait then close or hard close.
 	closeTimeout := serverGracefulCloseTimeout
+	if v := cfg.GracefulShutdownTimeout; v > 0 {
+		closeTimeout = v
+	}
+	if v := runOpts.GracefulShutdownTimeout; v > 0 {
+		closeTimeout = v
+	}
+	if deadline, ok := runCtx.Deadline(); ok {
+		if untilDeadline := time.Until(deadline); untilDeadline < closeTimeout {
+			closeTimeout = untilDeadline
+		}
+		if closeTimeout < 0 {
+			closeTimeout = 0
+		}
+	}
 	select {
 	case <-closedCh:
 		logger.Info("server closed")
 	case <-time.After(closeTimeout):
+		scope.SubScope("shutdown").Counter("forced").Inc(1)
 		logger.Error("server closed after timeout", zap.Duration("timeout", closeTimeout))
 	}
 }
@@ -854,14 +1206,183 @@ func bgValidateProcessLimits(logger *zap.Logger) {
 	}
 }
 
+// runStartupPreflightChecks verifies that the data directory is readable, that
+// any schemas configured for the schema registry load and that the topology
+// is reachable. The initialized topology is returned so it can be reused.
+func runStartupPreflightChecks(
+	cfg config.DBConfiguration,
+	topoInit topology.Initializer,
+	logger *zap.Logger,
+) (topology.Topology, error) {
+	filePathPrefix := cfg.Filesystem.FilePathPrefixOrDefault()
+	for _, dir := range []string{filePathPrefix, fs.DataDirPath(filePathPrefix)} {
+		if err := checkDirReadable(dir); err != nil {
+			return nil, fmt.Errorf("data directory %s not readable: %v", dir, err)
+		}
+	}
+
+	if cfg.Proto != nil && cfg.Proto.Enabled {
+		schemaRegistry := namespace.NewSchemaRegistry(true, logger)
+		for nsID, protoConfig := range cfg.Proto.SchemaRegistry {
+			if err := namespace.LoadSchemaRegistryFromFile(schemaRegistry,
+				ident.StringID(nsID), "preflight", protoConfig.SchemaFilePath,
+				protoConfig.MessageName); err != nil {
+				return nil, fmt.Errorf("could not load schema for namespace %s: %v", nsID, err)
+			}
+		}
+	}
+
+	topo, err := topoInit.Init()
+	if err != nil {
+		return nil, fmt.Errorf("topology not reachable: %v", err)
+	}
+	return topo, nil
+}
+
+func checkDirReadable(dir string) error {
+	f, err := os.Open(dir)
+	if os.IsNotExist(err) {
+		// Directories that do not exist yet are created on demand.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Readdirnames(1)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// bootstrapVerifyErrorSetter is set with the result of the bootstrap
+// verification to decline readiness when it fails.
+type bootstrapVerifyErrorSetter interface {
+	SetBootstrapVerifyError(err error)
+}
+
+// verifyBootstrap verifies the bootstrap against the topology and declines
+// readiness if the verification fails and is configured to fail readiness.
+func verifyBootstrap(
+	db storage.Database,
+	topoMapProvider topology.MapProvider,
+	hostID string,
+	cfg config.BootstrapVerifyConfiguration,
+	setter bootstrapVerifyErrorSetter,
+	scope tally.Scope,
+	logger *zap.Logger,
+) {
+	err := verifyBootstrapAgainstTopology(db, topoMapProvider, hostID,
+		cfg.MinShardsWithDataFraction, scope, logger)
+	if err != nil && cfg.FailReadiness {
+		setter.SetBootstrapVerifyError(err)
+	}
+}
+
+// verifyBootstrapAgainstTopology verifies that every shard the topology
+// assigns to the host was bootstrapped for every namespace, logging and
+// reporting the number of shards per namespace that were not. If
+// minShardsWithDataFraction is non-zero it also verifies that at least that
+// fraction of the assigned shards hold series in every namespace.
+func verifyBootstrapAgainstTopology(
+	db storage.Database,
+	topoMapProvider topology.MapProvider,
+	hostID string,
+	minShardsWithDataFraction float64,
+	scope tally.Scope,
+	logger *zap.Logger,
+) error {
+	topoMap, err := topoMapProvider.TopologyMap()
+	if err != nil {
+		logger.Error("could not verify bootstrap, no topology map", zap.Error(err))
+		return err
+	}
+
+	hostShardSet, ok := topoMap.LookupHostShardSet(hostID)
+	if !ok {
+		// Host not in the placement so has no shards assigned to verify.
+		return nil
+	}
+
+	scope = scope.SubScope("bootstrap-verify")
+	var unbootstrapped, sparse int
+	for _, ns := range db.Namespaces() {
+		shards := make(map[uint32]storage.Shard)
+		for _, s := range ns.Shards() {
+			shards[s.ID()] = s
+		}
+
+		var (
+			missing  []uint32
+			expected int
+			withData int
+		)
+		for _, s := range hostShardSet.ShardSet().All() {
+			if s.State() == shard.Leaving {
+				// Leaving shards are no longer required to be bootstrapped.
+				continue
+			}
+			expected++
+			owned, ok := shards[s.ID()]
+			if !ok || !owned.IsBootstrapped() {
+				missing = append(missing, s.ID())
+				continue
+			}
+			if owned.NumSeries() > 0 {
+				withData++
+			}
+		}
+
+		nsID := ns.ID().String()
+		nsScope := scope.Tagged(map[string]string{"namespace": nsID})
+		nsScope.Gauge("unbootstrapped-shards").Update(float64(len(missing)))
+		if len(missing) > 0 {
+			logger.Error("shards assigned by topology were not bootstrapped",
+				zap.String("namespace", nsID), zap.Uint32s("shards", missing))
+		}
+		unbootstrapped += len(missing)
+
+		if minShardsWithDataFraction <= 0 || expected == 0 {
+			continue
+		}
+		withDataFraction := float64(withData) / float64(expected)
+		nsScope.Gauge("shards-with-data-fraction").Update(withDataFraction)
+		if withDataFraction < minShardsWithDataFraction {
+			logger.Warn("fewer bootstrapped shards hold series than expected",
+				zap.String("namespace", nsID),
+				zap.Int("shardsWithData", withData),
+				zap.Int("shardsExpected", expected),
+				zap.Float64("minShardsWithDataFraction", minShardsWithDataFraction))
+			sparse++
+		}
+	}
+
+	if unbootstrapped > 0 {
+		return fmt.Errorf("%d shards assigned by topology were not bootstrapped",
+			unbootstrapped)
+	}
+	if sparse > 0 {
+		return fmt.Errorf("%d namespaces hold series in less than %v of assigned shards",
+			sparse, minShardsWithDataFraction)
+	}
+	logger.Info("verified all shards assigned by topology were bootstrapped")
+	return nil
+}
+
 func kvWatchNewSeriesLimitPerShard(
 	store kv.Store,
 	logger *zap.Logger,
+	scope tally.Scope,
 	topo topology.Topology,
 	runtimeOptsMgr m3dbruntime.OptionsManager,
 	defaultClusterNewSeriesLimit int,
 ) {
-	var initClusterLimit int
+	var (
+		metrics          = newKVWatchMetrics(scope, kvconfig.ClusterNewSeriesInsertLimitKey)
+		initClusterLimit int
+	)
 
 	value, err := store.Get(kvconfig.ClusterNewSeriesInsertLimitKey)
 	if err == nil {
@@ -869,6 +1390,8 @@ func kvWatchNewSeriesLimitPerShard(
 		err = value.Unmarshal(protoValue)
 		if err == nil {
 			initClusterLimit = int(protoValue.Value)
+		} else {
+			metrics.unmarshalErrors.Inc(1)
 		}
 	}
 
@@ -881,7 +1404,10 @@ func kvWatchNewSeriesLimitPerShard(
 
 	err = setNewSeriesLimitPerShardOnChange(topo, runtimeOptsMgr, initClusterLimit)
 	if err != nil {
+		metrics.applyErrors.Inc(1)
 		logger.Warn("unable to set cluster new series insert limit", zap.Error(err))
+	} else {
+		metrics.applied(float64(initClusterLimit))
 	}
 
 	watch, err := store.Watch(kvconfig.ClusterNewSeriesInsertLimitKey)
@@ -896,6 +1422,7 @@ func kvWatchNewSeriesLimitPerShard(
 			value := defaultClusterNewSeriesLimit
 			if newValue := watch.Get(); newValue != nil {
 				if err := newValue.Unmarshal(protoValue); err != nil {
+					metrics.unmarshalErrors.Inc(1)
 					logger.Warn("unable to parse new cluster new series insert limit", zap.Error(err))
 					continue
 				}
@@ -904,16 +1431,88 @@ func kvWatchNewSeriesLimitPerShard(
 
 			err = setNewSeriesLimitPerShardOnChange(topo, runtimeOptsMgr, value)
 			if err != nil {
+				metrics.applyErrors.Inc(1)
 				logger.Warn("unable to set cluster new series insert limit", zap.Error(err))
 				continue
 			}
+			metrics.applied(float64(value))
 		}
 	}()
 }
 
+func kvWatchMaxReadBlockReaders(
+	store kv.Store,
+	logger *zap.Logger,
+	scope tally.Scope,
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+	defaultMaxReadBlockReaders int,
+) {
+	kvWatchInt64Value(store, logger, scope,
+		kvconfig.MaxReadBlockReadersKey,
+		func(value int64) error {
+			return setMaxReadBlockReadersOnChange(runtimeOptsMgr, int(value))
+		},
+		func() error {
+			return setMaxReadBlockReadersOnChange(runtimeOptsMgr, defaultMaxReadBlockReaders)
+		})
+}
+
+func kvWatchFetchConcurrency(
+	store kv.Store,
+	logger *zap.Logger,
+	scope tally.Scope,
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+) {
+	// NB: a value of zero reverts the block retrievers to the fetch
+	// concurrency they were constructed with from the configuration.
+	kvWatchInt64Value(store, logger, scope,
+		kvconfig.FetchConcurrencyKey,
+		func(value int64) error {
+			return setFetchConcurrencyOnChange(runtimeOptsMgr, int(value))
+		},
+		func() error {
+			return setFetchConcurrencyOnChange(runtimeOptsMgr, 0)
+		})
+}
+
+func kvWatchMaxPendingNewSeriesInserts(
+	store kv.Store,
+	logger *zap.Logger,
+	scope tally.Scope,
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+	defaultMaxPending int,
+) {
+	kvWatchInt64Value(store, logger, scope,
+		kvconfig.MaxPendingNewSeriesInsertsPerShardKey,
+		func(value int64) error {
+			return setMaxPendingNewSeriesInsertsOnChange(runtimeOptsMgr, int(value))
+		},
+		func() error {
+			return setMaxPendingNewSeriesInsertsOnChange(runtimeOptsMgr, defaultMaxPending)
+		})
+}
+
+func kvWatchWriteTimeout(
+	store kv.Store,
+	logger *zap.Logger,
+	scope tally.Scope,
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+	defaultWriteTimeout time.Duration,
+) {
+	kvWatchDurationValue(store, logger, scope,
+		kvconfig.WriteTimeoutKey,
+		func(value time.Duration) error {
+			return setWriteTimeoutOnChange(runtimeOptsMgr, value)
+		},
+		func() error {
+			return setWriteTimeoutOnChange(runtimeOptsMgr, defaultWriteTimeout)
+		})
+}
+
 func kvWatchClientConsistencyLevels(
 	store kv.Store,
 	logger *zap.Logger,
+	scope tally.Scope,
 	clientOpts client.AdminOptions,
 	runtimeOptsMgr m3dbruntime.OptionsManager,
 ) {
@@ -943,7 +1542,7 @@ func kvWatchClientConsistencyLevels(
 		return fmt.Errorf("invalid consistency level set: %s", v)
 	}
 
-	kvWatchStringValue(store, logger,
+	kvWatchStringValue(store, logger, scope,
 		kvconfig.ClientBootstrapConsistencyLevel,
 		func(value string) error {
 			return setReadConsistencyLevel(value,
@@ -956,7 +1555,7 @@ func kvWatchClientConsistencyLevels(
 				SetClientBootstrapConsistencyLevel(clientOpts.BootstrapConsistencyLevel()))
 		})
 
-	kvWatchStringValue(store, logger,
+	kvWatchStringValue(store, logger, scope,
 		kvconfig.ClientReadConsistencyLevel,
 		func(value string) error {
 			return setReadConsistencyLevel(value,
@@ -969,7 +1568,7 @@ func kvWatchClientConsistencyLevels(
 				SetClientReadConsistencyLevel(clientOpts.ReadConsistencyLevel()))
 		})
 
-	kvWatchStringValue(store, logger,
+	kvWatchStringValue(store, logger, scope,
 		kvconfig.ClientWriteConsistencyLevel,
 		func(value string) error {
 			return setConsistencyLevel(value,
@@ -983,28 +1582,178 @@ func kvWatchClientConsistencyLevels(
 		})
 }
 
+func kvWatchGCPercentage(
+	store kv.Store,
+	logger *zap.Logger,
+	scope tally.Scope,
+	defaultGCPercentage int,
+) {
+	kvWatchInt64Value(store, logger, scope,
+		kvconfig.GCPercentageKey,
+		func(value int64) error {
+			if value < minGCPercentageOverride || value > maxGCPercentageOverride {
+				return fmt.Errorf("gc percentage %d must be between %d and %d",
+					value, minGCPercentageOverride, maxGCPercentageOverride)
+			}
+			debug.SetGCPercent(int(value))
+			return nil
+		},
+		func() error {
+			// Revert to the configured value once the override is removed.
+			debug.SetGCPercent(defaultGCPercentage)
+			return nil
+		})
+}
+
+// refillHighWatermarkSetter is a pool whose refill high watermark can be
+// set at runtime.
+type refillHighWatermarkSetter interface {
+	SetRefillHighWatermark(value float64) error
+}
+
+func kvWatchPoolRefillHighWatermark(
+	store kv.Store,
+	logger *zap.Logger,
+	scope tally.Scope,
+	key string,
+	pool refillHighWatermarkSetter,
+	defaultRefillHighWatermark float64,
+) {
+	kvWatchFloat64Value(store, logger, scope, key,
+		pool.SetRefillHighWatermark,
+		func() error {
+			// Revert to the configured value once the override is removed.
+			return pool.SetRefillHighWatermark(defaultRefillHighWatermark)
+		})
+}
+
 func kvWatchStringValue(
 	store kv.Store,
 	logger *zap.Logger,
+	scope tally.Scope,
 	key string,
 	onValue func(value string) error,
 	onDelete func() error,
 ) {
 	protoValue := &commonpb.StringProto{}
+	kvWatchValue(store, logger, newKVWatchMetrics(scope, key), key, protoValue,
+		func() error {
+			return onValue(protoValue.Value)
+		},
+		onDelete)
+}
+
+func kvWatchInt64Value(
+	store kv.Store,
+	logger *zap.Logger,
+	scope tally.Scope,
+	key string,
+	onValue func(value int64) error,
+	onDelete func() error,
+) {
+	var (
+		metrics    = newKVWatchMetrics(scope, key)
+		protoValue = &commonpb.Int64Proto{}
+	)
+	kvWatchValue(store, logger, metrics, key, protoValue,
+		func() error {
+			if err := onValue(protoValue.Value); err != nil {
+				return err
+			}
+			metrics.value.Update(float64(protoValue.Value))
+			return nil
+		},
+		onDelete)
+}
 
+func kvWatchFloat64Value(
+	store kv.Store,
+	logger *zap.Logger,
+	scope tally.Scope,
+	key string,
+	onValue func(value float64) error,
+	onDelete func() error,
+) {
+	var (
+		metrics    = newKVWatchMetrics(scope, key)
+		protoValue = &commonpb.Float64Proto{}
+	)
+	kvWatchValue(store, logger, metrics, key, protoValue,
+		func() error {
+			if err := onValue(protoValue.Value); err != nil {
+				return err
+			}
+			metrics.value.Update(protoValue.Value)
+			return nil
+		},
+		onDelete)
+}
+
+// kvWatchDurationValue watches a KV key holding a duration string such as
+// "5s", the applied value is emitted in seconds.
+func kvWatchDurationValue(
+	store kv.Store,
+	logger *zap.Logger,
+	scope tally.Scope,
+	key string,
+	onValue func(value time.Duration) error,
+	onDelete func() error,
+) {
+	var (
+		metrics    = newKVWatchMetrics(scope, key)
+		protoValue = &commonpb.StringProto{}
+	)
+	kvWatchValue(store, logger, metrics, key, protoValue,
+		func() error {
+			value, err := time.ParseDuration(protoValue.Value)
+			if err != nil {
+				return err
+			}
+			if err := onValue(value); err != nil {
+				return err
+			}
+			metrics.value.Update(value.Seconds())
+			return nil
+		},
+		onDelete)
+}
+
+// kvWatchValue watches a KV key, unmarshalling each value into the proto
+// value before applying it with onValue and reverting to the configured
+// default with onDelete when the key is not set.
+func kvWatchValue(
+	store kv.Store,
+	logger *zap.Logger,
+	metrics kvWatchMetrics,
+	key string,
+	protoValue protobuf.Message,
+	onValue func() error,
+	onDelete func() error,
+) {
 	// First try to eagerly set the value so it doesn't flap if the
 	// watch returns but not immediately for an existing value
 	value, err := store.Get(key)
 	if err != nil && err != kv.ErrNotFound {
 		logger.Error("could not resolve KV", zap.String("key", key), zap.Error(err))
 	}
+	if err == kv.ErrNotFound {
+		if err := onDelete(); err != nil {
+			metrics.applyErrors.Inc(1)
+			logger.Error("could not set default for KV key", zap.String("key", key), zap.Error(err))
+		} else {
+			metrics.applies.Inc(1)
+		}
+	}
 	if err == nil {
 		if err := value.Unmarshal(protoValue); err != nil {
+			metrics.unmarshalErrors.Inc(1)
 			logger.Error("could not unmarshal KV key", zap.String("key", key), zap.Error(err))
-		} else if err := onValue(protoValue.Value); err != nil {
+		} else if err := onValue(); err != nil {
+			metrics.applyErrors.Inc(1)
 			logger.Error("could not process value of KV", zap.String("key", key), zap.Error(err))
 		} else {
-			logger.Info("set KV key", zap.String("key", key), zap.Any("value", protoValue.Value))
+			metrics.applies.Inc(1)
+			logger.Info("set KV key", zap.String("key", key), zap.Stringer("value", protoValue))
 		}
 	}
 
@@ -1019,25 +1768,57 @@ func kvWatchStringValue(
 			newValue := watch.Get()
 			if newValue == nil {
 				if err := onDelete(); err != nil {
+					metrics.applyErrors.Inc(1)
 					logger.Warn("could not set default for KV key", zap.String("key", key), zap.Error(err))
+					continue
 				}
+				metrics.applies.Inc(1)
 				continue
 			}
 
 			err := newValue.Unmarshal(protoValue)
 			if err != nil {
+				metrics.unmarshalErrors.Inc(1)
 				logger.Warn("could not unmarshal KV key", zap.String("key", key), zap.Error(err))
 				continue
 			}
-			if err := onValue(protoValue.Value); err != nil {
+			if err := onValue(); err != nil {
+				metrics.applyErrors.Inc(1)
 				logger.Warn("could not process change for KV key", zap.String("key", key), zap.Error(err))
 				continue
 			}
-			logger.Info("set KV key", zap.String("key", key), zap.Any("value", protoValue.Value))
+			metrics.applies.Inc(1)
+			logger.Info("set KV key", zap.String("key", key), zap.Stringer("value", protoValue))
 		}
 	}()
 }
 
+// kvWatchMetrics tracks how often a watched KV key changes and whether
+// those changes are successfully applied, so that a flapping or invalid
+// key can be alerted on.
+type kvWatchMetrics struct {
+	applies         tally.Counter
+	unmarshalErrors tally.Counter
+	applyErrors     tally.Counter
+	value           tally.Gauge
+}
+
+func newKVWatchMetrics(scope tally.Scope, key string) kvWatchMetrics {
+	scope = scope.SubScope("kv-watch").Tagged(map[string]string{"key": key})
+	return kvWatchMetrics{
+		applies:         scope.Counter("applies"),
+		unmarshalErrors: scope.Counter("unmarshal-errors"),
+		applyErrors:     scope.Counter("apply-errors"),
+		value:           scope.Gauge("value"),
+	}
+}
+
+// applied records a successful apply of a numeric value.
+func (m kvWatchMetrics) applied(value float64) {
+	m.applies.Inc(1)
+	m.value.Update(value)
+}
+
 func setNewSeriesLimitPerShardOnChange(
 	topo topology.Topology,
 	runtimeOptsMgr m3dbruntime.OptionsManager,
@@ -1055,6 +1836,111 @@ func setNewSeriesLimitPerShardOnChange(
 	return runtimeOptsMgr.Update(newRuntimeOpts)
 }
 
+func setMaxReadBlockReadersOnChange(
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+	limit int,
+) error {
+	runtimeOpts := runtimeOptsMgr.Get()
+	if runtimeOpts.MaxReadBlockReaders() == limit {
+		// Not changed, no need to set the value and trigger a runtime options update
+		return nil
+	}
+
+	newRuntimeOpts := runtimeOpts.
+		SetMaxReadBlockReaders(limit)
+	return runtimeOptsMgr.Update(newRuntimeOpts)
+}
+
+func setFetchConcurrencyOnChange(
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+	concurrency int,
+) error {
+	runtimeOpts := runtimeOptsMgr.Get()
+	if runtimeOpts.FetchConcurrency() == concurrency {
+		// Not changed, no need to set the value and trigger a runtime options update
+		return nil
+	}
+
+	newRuntimeOpts := runtimeOpts.
+		SetFetchConcurrency(concurrency)
+	return runtimeOptsMgr.Update(newRuntimeOpts)
+}
+
+func setWriteTimeoutOnChange(
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+	timeout time.Duration,
+) error {
+	runtimeOpts := runtimeOptsMgr.Get()
+	if runtimeOpts.WriteTimeout() == timeout {
+		// Not changed, no need to set the value and trigger a runtime options update
+		return nil
+	}
+
+	newRuntimeOpts := runtimeOpts.
+		SetWriteTimeout(timeout)
+	return runtimeOptsMgr.Update(newRuntimeOpts)
+}
+
+func kvWatchDebugLoggingNamespaces(
+	store kv.Store,
+	logger *zap.Logger,
+	scope tally.Scope,
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+) {
+	protoValue := &commonpb.StringArrayProto{}
+	kvWatchValue(store, logger,
+		newKVWatchMetrics(scope, kvconfig.DebugLoggingNamespacesKey),
+		kvconfig.DebugLoggingNamespacesKey, protoValue,
+		func() error {
+			return setDebugLoggingNamespacesOnChange(runtimeOptsMgr, protoValue.Values)
+		},
+		func() error {
+			return setDebugLoggingNamespacesOnChange(runtimeOptsMgr, nil)
+		})
+}
+
+func setMaxPendingNewSeriesInsertsOnChange(
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+	limit int,
+) error {
+	runtimeOpts := runtimeOptsMgr.Get()
+	if runtimeOpts.WriteNewSeriesMaxPendingPerShard() == limit {
+		// Not changed, no need to set the value and trigger a runtime options update
+		return nil
+	}
+
+	newRuntimeOpts := runtimeOpts.
+		SetWriteNewSeriesMaxPendingPerShard(limit)
+	return runtimeOptsMgr.Update(newRuntimeOpts)
+}
+
+func setDebugLoggingNamespacesOnChange(
+	runtimeOptsMgr m3dbruntime.OptionsManager,
+	namespaces []string,
+) error {
+	runtimeOpts := runtimeOptsMgr.Get()
+	if stringSlicesEqual(runtimeOpts.DebugLoggingNamespaces(), namespaces) {
+		// Not changed, no need to set the value and trigger a runtime options update
+		return nil
+	}
+
+	newRuntimeOpts := runtimeOpts.
+		SetDebugLoggingNamespaces(namespaces)
+	return runtimeOptsMgr.Update(newRuntimeOpts)
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func clusterLimitToPlacedShardLimit(topo topology.Topology, clusterLimit int) int {
 	if clusterLimit < 1 {
 		return 0
@@ -1071,14 +1957,18 @@ func clusterLimitToPlacedShardLimit(topo topology.Topology, clusterLimit int) in
 }
 
 // this function will block for at most waitTimeout to try to get an initial value
-// before we kick off the bootstrap
+// before we kick off the bootstrap, entries of the string array may be annotated
+// such as "peers?" which are parsed when the bootstrappers are constructed
 func kvWatchBootstrappers(
 	kv kv.Store,
 	logger *zap.Logger,
+	scope tally.Scope,
 	waitTimeout time.Duration,
 	defaultBootstrappers []string,
-	onUpdate func(bootstrappers []string),
+	onUpdate func(bootstrappers []string) error,
 ) {
+	metrics := newKVWatchMetrics(scope, kvconfig.BootstrapperKey)
+
 	vw, err := kv.Watch(kvconfig.BootstrapperKey)
 	if err != nil {
 		logger.Fatal("could not watch value for key with KV",
@@ -1095,6 +1985,7 @@ func kvWatchBootstrappers(
 			v, err := util.StringArrayFromValue(vw.Get(),
 				kvconfig.BootstrapperKey, defaultBootstrappers, opts)
 			if err != nil {
+				metrics.unmarshalErrors.Inc(1)
 				logger.Error("error converting KV update to string array",
 					zap.String("key", kvconfig.BootstrapperKey),
 					zap.Error(err),
@@ -1102,7 +1993,16 @@ func kvWatchBootstrappers(
 				continue
 			}
 
-			onUpdate(v)
+			if err := onUpdate(v); err != nil {
+				metrics.applyErrors.Inc(1)
+				logger.Error("could not process change for KV key",
+					zap.String("key", kvconfig.BootstrapperKey),
+					zap.Strings("bootstrappers", v),
+					zap.Error(err),
+				)
+			} else {
+				metrics.applies.Inc(1)
+			}
 
 			if !initialized {
 				initialized = true
@@ -1154,10 +2054,23 @@ func withEncodingAndPoolingOptions(
 			func(s []pool.Bucket) pool.BytesPool {
 				return pool.NewBytesPool(s, bytesPoolOpts)
 			})
+	case config.ExternalPooling:
+		newBytesPool := opts.BytesPoolFactory()
+		if newBytesPool == nil {
+			logger.Fatal("external pooling type requires a bytes pool factory")
+		}
+		bytesPool = newBytesPool(buckets, checkedBytesPoolOpts)
 	default:
 		logger.Fatal("unrecognized pooling type", zap.Any("type", policy.Type))
 	}
 
+	if samplingPolicy := policy.BytesPoolSampling; samplingPolicy != nil {
+		logger.Info("bytes pool sampling enabled",
+			zap.Float64("sampleRate", samplingPolicy.SampleRateOrDefault()))
+		bytesPool = pool.NewSampledCheckedBytesPool(bytesPool, buckets,
+			samplingPolicy.SampleRateOrDefault())
+	}
+
 	{
 		// Avoid polluting the rest of the function with `l` var
 		l := logger
@@ -1228,6 +2141,8 @@ func withEncodingAndPoolingOptions(
 		// want to be able to buffer at least one full commitlog queues worth of
 		// writes without allocating because these objects are very expensive to
 		// allocate.
+		// NB: this holds for both commit log strategies, with write wait the
+		// batches are held for longer but the queue bounds them all the same.
 		commitlogQueueSize := opts.CommitLogOptions().BacklogQueueSize()
 		expectedBatchSize := *writeBatchPoolInitialBatchSize
 		writeBatchPoolSize = commitlogQueueSize / expectedBatchSize
@@ -1282,6 +2197,17 @@ func withEncodingAndPoolingOptions(
 		SetBytesPool(bytesPool).
 		SetSegmentReaderPool(segmentReaderPool)
 
+	if cfg.Proto != nil && cfg.Proto.Enabled && cfg.Proto.FieldPresenceDrift != nil {
+		driftCfg := cfg.Proto.FieldPresenceDrift
+		encodingOpts = encodingOpts.SetFieldPresenceMonitor(proto.NewFieldPresenceMonitor(
+			proto.FieldPresenceMonitorOptions{
+				WindowSize: driftCfg.WindowSize,
+				Tolerance:  driftCfg.Tolerance,
+				InstrumentOptions: iopts.SetMetricsScope(
+					scope.SubScope("proto-field-presence")),
+			}))
+	}
+
 	encoderPool.Init(func() encoding.Encoder {
 		if cfg.Proto != nil && cfg.Proto.Enabled {
 			enc := proto.NewEncoder(time.Time{}, encodingOpts)
@@ -1363,7 +2289,8 @@ func withEncodingAndPoolingOptions(
 	// NB(prateek): retention opts are overridden per namespace during series creation
 	retentionOpts := retention.NewOptions()
 	seriesOpts := storage.NewSeriesOptionsFromOptions(opts, retentionOpts).
-		SetFetchBlockMetadataResultsPool(opts.FetchBlockMetadataResultsPool())
+		SetFetchBlockMetadataResultsPool(opts.FetchBlockMetadataResultsPool()).
+		SetRecycleEvictedEncoders(policy.RecycleEvictedEncodersOrDefault())
 	seriesPool := series.NewDatabaseSeriesPool(
 		poolOptions(
 			policy.SeriesPool,
@@ -1382,17 +2309,19 @@ func withEncodingAndPoolingOptions(
 	queryResultsPool := index.NewQueryResultsPool(
 		poolOptions(policy.IndexResultsPool, scope.SubScope("index-query-results-pool")))
 	aggregateQueryResultsPool := index.NewAggregateResultsPool(
-		poolOptions(policy.IndexResultsPool, scope.SubScope("index-aggregate-results-pool")))
+		poolOptions(policy.IndexAggregateResultsPool, scope.SubScope("index-aggregate-results-pool")))
 
 	// Set value transformation options.
 	opts = opts.SetTruncateType(cfg.Transforms.TruncateBy)
+	writeTransformOpts := series.WriteTransformOptions{
+		RoundToMultiple: cfg.Transforms.RoundToMultiple,
+	}
 	forcedValue := cfg.Transforms.ForcedValue
 	if forcedValue != nil {
-		opts = opts.SetWriteTransformOptions(series.WriteTransformOptions{
-			ForceValueEnabled: true,
-			ForceValue:        *forcedValue,
-		})
+		writeTransformOpts.ForceValueEnabled = true
+		writeTransformOpts.ForceValue = *forcedValue
 	}
+	opts = opts.SetWriteTransformOptions(writeTransformOpts)
 
 	// Set index options.
 	indexOpts := opts.IndexOptions().
@@ -1511,6 +2440,42 @@ func maxCapacityPoolOptions(
 	return opts
 }
 
+// startBytesPoolRecommendationReport periodically logs the buckets recommended
+// from the capacities sampled from the bytes pool until the returned function
+// is called.
+func startBytesPoolRecommendationReport(
+	bytesPool pool.SampledCheckedBytesPool,
+	interval time.Duration,
+	logger *zap.Logger,
+) func() {
+	closedCh := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-closedCh:
+				return
+			case <-ticker.C:
+				r := bytesPool.Recommendation()
+				if r.Samples == 0 {
+					continue
+				}
+				logger.Info("bytes pool bucket recommendation",
+					zap.Int64("samples", r.Samples),
+					zap.Int64("unpooled", r.Unpooled),
+					zap.Any("current", r.Current),
+					zap.Any("recommended", r.Recommended),
+				)
+			}
+		}
+	}()
+	return func() {
+		close(closedCh)
+	}
+}
+
 func hostSupportsHugeTLB() (bool, error) {
 	// Try and determine if the host supports HugeTLB in the first place
 	withHugeTLB, err := mmap.Bytes(10, mmap.Options{
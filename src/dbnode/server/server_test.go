@@ -0,0 +1,244 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/cluster/generated/proto/commonpb"
+	"github.com/m3db/m3/src/cluster/kv/mem"
+	"github.com/m3db/m3/src/cluster/shard"
+	"github.com/m3db/m3/src/cmd/services/m3dbnode/config"
+	"github.com/m3db/m3/src/dbnode/sharding"
+	"github.com/m3db/m3/src/dbnode/storage"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/x/ident"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const testVerifyHostID = "testhost"
+
+type testVerifyShard struct {
+	bootstrapped bool
+	numSeries    int64
+}
+
+func newTestVerifyBootstrapDatabase(
+	t *testing.T,
+	ctrl *gomock.Controller,
+	shards []testVerifyShard,
+) (storage.Database, topology.MapProvider) {
+	ids := make([]uint32, 0, len(shards))
+	storageShards := make([]storage.Shard, 0, len(shards))
+	for i, s := range shards {
+		ids = append(ids, uint32(i))
+		storageShard := storage.NewMockShard(ctrl)
+		storageShard.EXPECT().ID().Return(uint32(i)).AnyTimes()
+		storageShard.EXPECT().IsBootstrapped().Return(s.bootstrapped).AnyTimes()
+		storageShard.EXPECT().NumSeries().Return(s.numSeries).AnyTimes()
+		storageShards = append(storageShards, storageShard)
+	}
+
+	ns := storage.NewMockNamespace(ctrl)
+	ns.EXPECT().ID().Return(ident.StringID("testns")).AnyTimes()
+	ns.EXPECT().Shards().Return(storageShards).AnyTimes()
+
+	db := storage.NewMockDatabase(ctrl)
+	db.EXPECT().Namespaces().Return([]storage.Namespace{ns}).AnyTimes()
+
+	shardSet, err := sharding.NewShardSet(sharding.NewShards(ids, shard.Available),
+		sharding.DefaultHashFn(len(ids)))
+	require.NoError(t, err)
+	hostShardSet := topology.NewHostShardSet(
+		topology.NewHost(testVerifyHostID, "127.0.0.1:9000"), shardSet)
+
+	topoMap := topology.NewMockMap(ctrl)
+	topoMap.EXPECT().LookupHostShardSet(testVerifyHostID).Return(hostShardSet, true).AnyTimes()
+	topoMapProvider := topology.NewMockMapProvider(ctrl)
+	topoMapProvider.EXPECT().TopologyMap().Return(topoMap, nil).AnyTimes()
+
+	return db, topoMapProvider
+}
+
+func TestVerifyBootstrapAgainstTopology(t *testing.T) {
+	tests := []struct {
+		name                      string
+		shards                    []testVerifyShard
+		minShardsWithDataFraction float64
+		expectErr                 bool
+		expectFraction            float64
+	}{
+		{
+			name: "all shards bootstrapped with data",
+			shards: []testVerifyShard{
+				{true, 10}, {true, 10}, {true, 10}, {true, 10},
+			},
+			minShardsWithDataFraction: 0.5,
+			expectFraction:            1,
+		},
+		{
+			name: "shard not bootstrapped",
+			shards: []testVerifyShard{
+				{true, 10}, {false, 0}, {true, 10}, {true, 10},
+			},
+			expectErr: true,
+		},
+		{
+			name: "shards with data at threshold",
+			shards: []testVerifyShard{
+				{true, 10}, {true, 1}, {true, 0}, {true, 0},
+			},
+			minShardsWithDataFraction: 0.5,
+			expectFraction:            0.5,
+		},
+		{
+			name: "shards with data below threshold",
+			shards: []testVerifyShard{
+				{true, 10}, {true, 0}, {true, 0}, {true, 0},
+			},
+			minShardsWithDataFraction: 0.5,
+			expectErr:                 true,
+			expectFraction:            0.25,
+		},
+		{
+			name: "shards with data check disabled",
+			shards: []testVerifyShard{
+				{true, 0}, {true, 0}, {true, 0}, {true, 0},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			db, topoMapProvider := newTestVerifyBootstrapDatabase(t, ctrl, test.shards)
+			scope := tally.NewTestScope("", nil)
+			err := verifyBootstrapAgainstTopology(db, topoMapProvider, testVerifyHostID,
+				test.minShardsWithDataFraction, scope, zap.NewNop())
+			if test.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			gauge, ok := scope.Snapshot().Gauges()["bootstrap-verify.shards-with-data-fraction+namespace=testns"]
+			if test.expectFraction == 0 {
+				require.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			require.Equal(t, test.expectFraction, gauge.Value())
+		})
+	}
+}
+
+type testBootstrapVerifyErrorSetter struct {
+	err error
+}
+
+func (s *testBootstrapVerifyErrorSetter) SetBootstrapVerifyError(err error) {
+	s.err = err
+}
+
+func TestVerifyBootstrapDeclinesReadiness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	shards := []testVerifyShard{{true, 10}, {true, 0}, {true, 0}, {true, 0}}
+	db, topoMapProvider := newTestVerifyBootstrapDatabase(t, ctrl, shards)
+
+	for _, failReadiness := range []bool{false, true} {
+		cfg := config.BootstrapVerifyConfiguration{
+			Enabled:                   true,
+			FailReadiness:             failReadiness,
+			MinShardsWithDataFraction: 0.5,
+		}
+		setter := &testBootstrapVerifyErrorSetter{}
+		verifyBootstrap(db, topoMapProvider, testVerifyHostID, cfg, setter,
+			tally.NoopScope, zap.NewNop())
+		if failReadiness {
+			require.Error(t, setter.err)
+		} else {
+			require.NoError(t, setter.err)
+		}
+	}
+}
+
+func TestKVWatchInt64Value(t *testing.T) {
+	var (
+		store  = mem.NewStore()
+		scope  = tally.NewTestScope("", nil)
+		key    = "test-key"
+		values = make(chan int64, 8)
+	)
+
+	// Deletes are recorded as a negative value.
+	kvWatchInt64Value(store, zap.NewNop(), scope, key,
+		func(value int64) error {
+			values <- value
+			return nil
+		},
+		func() error {
+			values <- -1
+			return nil
+		})
+
+	// The default is applied eagerly as the key is not set.
+	require.Equal(t, int64(-1), requireKVWatchValue(t, values))
+
+	_, err := store.Set(key, &commonpb.Int64Proto{Value: 42})
+	require.NoError(t, err)
+	require.Equal(t, int64(42), requireKVWatchValue(t, values))
+
+	_, err = store.Delete(key)
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), requireKVWatchValue(t, values))
+
+	// Applies are counted once each callback returns so wait for the last.
+	appliesKey := "kv-watch.applies+key=test-key"
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if c, ok := scope.Snapshot().Counters()[appliesKey]; ok && c.Value() == 3 {
+			break
+		}
+	}
+
+	snapshot := scope.Snapshot()
+	require.Equal(t, int64(3), snapshot.Counters()[appliesKey].Value())
+	require.Equal(t, float64(42),
+		snapshot.Gauges()["kv-watch.value+key=test-key"].Value())
+}
+
+func requireKVWatchValue(t *testing.T, values <-chan int64) int64 {
+	select {
+	case v := <-values:
+		return v
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for KV watch value")
+		return 0
+	}
+}
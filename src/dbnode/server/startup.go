@@ -0,0 +1,86 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package server
+
+import (
+	"time"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap"
+)
+
+const (
+	startupPhaseConfigLoad     = "config-load"
+	startupPhaseEtcdStart      = "etcd-start"
+	startupPhaseTopologyInit   = "topology-init"
+	startupPhaseClientCreation = "client-creation"
+	startupPhaseDBOpen         = "db-open"
+	startupPhaseBootstrap      = "bootstrap"
+)
+
+type startupPhase struct {
+	name     string
+	duration time.Duration
+}
+
+// startupTimer records the wall-clock duration of each startup phase so
+// they can be reported together once startup completes.
+type startupTimer struct {
+	nowFn  func() time.Time
+	start  time.Time
+	phases []startupPhase
+}
+
+func newStartupTimer() *startupTimer {
+	return &startupTimer{
+		nowFn: time.Now,
+		start: time.Now(),
+	}
+}
+
+// phase starts timing the named phase, the returned func records its
+// duration when called.
+func (t *startupTimer) phase(name string) func() {
+	start := t.nowFn()
+	return func() {
+		t.phases = append(t.phases, startupPhase{
+			name:     name,
+			duration: t.nowFn().Sub(start),
+		})
+	}
+}
+
+// report emits each phase as a timer under the startup subscope and logs
+// a single startup complete message with the duration of each phase.
+func (t *startupTimer) report(scope tally.Scope, logger *zap.Logger) {
+	startupScope := scope.SubScope("startup")
+	fields := make([]zap.Field, 0, len(t.phases)+1)
+	for _, p := range t.phases {
+		startupScope.Timer(p.name).Record(p.duration)
+		fields = append(fields, zap.Duration(p.name, p.duration))
+	}
+
+	total := t.nowFn().Sub(t.start)
+	startupScope.Timer("total").Record(total)
+	fields = append(fields, zap.Duration("total", total))
+
+	logger.Info("startup complete", fields...)
+}
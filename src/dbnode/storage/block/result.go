@@ -103,7 +103,18 @@ func (s *fetchBlockMetadataResults) Results() []FetchBlockMetadataResult {
 }
 
 func (s *fetchBlockMetadataResults) Sort() {
-	sort.Sort(fetchBlockMetadataResultByTimeAscending(s.results))
+	// NB: Use a stable sort so results with the same block start keep the
+	// order they were added in, which paged fetches rely on.
+	sort.Stable(fetchBlockMetadataResultByTimeAscending(s.results))
+}
+
+func (s *fetchBlockMetadataResults) Slice(start, end int) {
+	n := copy(s.results, s.results[start:end])
+	var zeroed FetchBlockMetadataResult
+	for i := n; i < len(s.results); i++ {
+		s.results[i] = zeroed
+	}
+	s.results = s.results[:n]
 }
 
 func (s *fetchBlockMetadataResults) Reset() {
@@ -223,8 +234,14 @@ func (it *filteredBlocksMetadataIter) Next() bool {
 	}
 	it.id = it.res[it.resIdx].ID
 	block := blocks[it.blockIdx]
-	var tags ident.Tags
-	if tagsIter := it.res[it.resIdx].Tags; tagsIter != nil {
+	var (
+		tags     ident.Tags
+		tagsIter = it.res[it.resIdx].Tags
+	)
+	if resolve := it.res[it.resIdx].ResolveTags; tagsIter == nil && resolve != nil {
+		tagsIter = resolve()
+	}
+	if tagsIter != nil {
 		for tagsIter.Next() {
 			curr := tagsIter.Current()
 			tags.Append(ident.StringTag(curr.Name.String(), curr.Value.String()))
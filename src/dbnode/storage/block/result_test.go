@@ -60,6 +60,25 @@ func TestSortFetchBlockMetadataResultByTimeAscending(t *testing.T) {
 	require.Equal(t, expected, res.Results())
 }
 
+func TestFetchBlockMetadataResultsSlice(t *testing.T) {
+	now := time.Now()
+	res := newPooledFetchBlockMetadataResults(nil, nil)
+	for i := 0; i < 5; i++ {
+		res.Add(NewFetchBlockMetadataResult(now.Add(time.Duration(i)*time.Second),
+			int64(i), nil, time.Time{}, nil))
+	}
+	backing := res.Results()
+
+	res.Slice(1, 3)
+	results := res.Results()
+	require.Equal(t, 2, len(results))
+	require.Equal(t, int64(1), results[0].Size)
+	require.Equal(t, int64(2), results[1].Size)
+
+	// Released results are zeroed to avoid holding refs.
+	require.Equal(t, FetchBlockMetadataResult{}, backing[4])
+}
+
 func TestFilteredBlocksMetadataIter(t *testing.T) {
 	now := time.Now()
 	sizes := []int64{1, 2, 3}
@@ -79,6 +79,10 @@ type FetchBlocksMetadataOptions struct {
 	IncludeSizes     bool
 	IncludeChecksums bool
 	IncludeLastRead  bool
+	// LazyTags returns the tags of series read from disk in their encoded
+	// form rather than decoding them, they can be resolved with the result's
+	// ResolveTags if required.
+	LazyTags bool
 }
 
 // FetchBlockMetadataResult captures the block start time, the block size, and any errors encountered
@@ -101,6 +105,10 @@ type FetchBlockMetadataResults interface {
 	// SortByTimeAscending sorts the results in time ascending order
 	Sort()
 
+	// Slice reduces the results to those in the range [start, end),
+	// releasing the rest.
+	Slice(start, end int)
+
 	// Reset resets the results
 	Reset()
 
@@ -113,6 +121,28 @@ type FetchBlocksMetadataResult struct {
 	ID     ident.ID
 	Tags   ident.TagIterator
 	Blocks FetchBlockMetadataResults
+
+	// EncodedTags is the serialized form of Tags if precomputed by the
+	// series, nil if the tags must be encoded from the Tags iterator.
+	EncodedTags []byte
+
+	// ResolveTags decodes EncodedTags when the tags were returned lazily and
+	// Tags is nil, the caller must close the returned iterator. It is nil if
+	// the tags were not returned lazily.
+	ResolveTags func() ident.TagIterator
+
+	// NextCursor is the cursor to fetch the next page of blocks from when
+	// the fetch was limited, nil if all the blocks were returned.
+	NextCursor *FetchBlocksMetadataCursor
+}
+
+// FetchBlocksMetadataCursor is the position to resume a paged fetch of blocks
+// metadata from. Results are ordered by block start and a block start can be
+// returned more than once, i.e. for both a cached block and the buffer, so
+// Offset counts the results at BlockStart that were already returned.
+type FetchBlocksMetadataCursor struct {
+	BlockStart time.Time
+	Offset     int
 }
 
 // FetchBlocksMetadataResults captures a collection of FetchBlocksMetadataResult
@@ -22,12 +22,14 @@ package storage
 
 import (
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	xerrors "github.com/m3db/m3/src/x/errors"
+	xsync "github.com/m3db/m3/src/x/sync"
 
 	"github.com/uber-go/tally"
 	"go.uber.org/zap"
@@ -57,8 +59,15 @@ var (
 
 	// errBootstrapEnqueued raised when trying to bootstrap and bootstrap becomes enqueued.
 	errBootstrapEnqueued = errors.New("database bootstrapping enqueued bootstrap")
+
+	// errBootstrapAborted raised when the bootstrap manager is closed while bootstrapping.
+	errBootstrapAborted = errors.New("bootstrap aborted due to bootstrap manager close")
 )
 
+// allNamespacesFailedBootstrap is the key of the failed bootstrap shards
+// when the bootstrap failed before any namespace could be bootstrapped.
+const allNamespacesFailedBootstrap = "*"
+
 type bootstrapManager struct {
 	sync.RWMutex
 
@@ -71,7 +80,12 @@ type bootstrapManager struct {
 	state                       BootstrapState
 	hasPending                  bool
 	status                      tally.Gauge
+	failedShardsGauge           tally.Gauge
 	lastBootstrapCompletionTime time.Time
+	retryInterval               time.Duration
+	failedShards                map[string][]uint32
+	retryTimer                  *time.Timer
+	closed                      bool
 }
 
 func newBootstrapManager(
@@ -81,13 +95,15 @@ func newBootstrapManager(
 ) databaseBootstrapManager {
 	scope := opts.InstrumentOptions().MetricsScope()
 	return &bootstrapManager{
-		database:        database,
-		mediator:        mediator,
-		opts:            opts,
-		log:             opts.InstrumentOptions().Logger(),
-		nowFn:           opts.ClockOptions().NowFn(),
-		processProvider: opts.BootstrapProcessProvider(),
-		status:          scope.Gauge("bootstrapped"),
+		database:          database,
+		mediator:          mediator,
+		opts:              opts,
+		log:               opts.InstrumentOptions().Logger(),
+		nowFn:             opts.ClockOptions().NowFn(),
+		processProvider:   opts.BootstrapProcessProvider(),
+		status:            scope.Gauge("bootstrapped"),
+		failedShardsGauge: scope.Gauge("bootstrap-failed-shards"),
+		retryInterval:     opts.BootstrapFailureRetryInterval(),
 	}
 }
 
@@ -126,15 +142,26 @@ func (m *bootstrapManager) Bootstrap() error {
 	defer m.mediator.EnableFileOps()
 
 	// Keep performing bootstraps until none pending
-	multiErr := xerrors.NewMultiError()
+	var (
+		multiErr = xerrors.NewMultiError()
+		failed   map[string][]uint32
+	)
 	for {
-		err := m.bootstrap()
+		var err error
+		failed, err = m.bootstrap()
 		if err != nil {
 			multiErr = multiErr.Add(err)
+			if len(failed) == 0 {
+				// NB: The whole node failed to bootstrap, record the failure
+				// so that the node is not ready until a retry succeeds.
+				failed = map[string][]uint32{allNamespacesFailedBootstrap: nil}
+			}
 		}
 
 		m.Lock()
-		currPending := m.hasPending
+		// NB: Pending bootstraps are dropped once closed since the database
+		// is shutting down.
+		currPending := m.hasPending && !m.closed
 		if currPending {
 			// New bootstrap calls should now enqueue another pending bootstrap
 			m.hasPending = false
@@ -158,7 +185,42 @@ func (m *bootstrapManager) Bootstrap() error {
 	// across the cluster.
 
 	m.lastBootstrapCompletionTime = m.nowFn()
-	return multiErr.FinalError()
+	err := multiErr.FinalError()
+
+	// NB: When retrying failures the successfully bootstrapped namespaces
+	// and shards are served while the failed ones are retried in the
+	// background, the failures are tracked so that they are reflected in
+	// readiness rather than returned.
+	m.Lock()
+	m.failedShards = failed
+	retry := err != nil && m.retryInterval > 0 && !m.closed
+	if retry {
+		if m.retryTimer != nil {
+			m.retryTimer.Stop()
+		}
+		m.retryTimer = time.AfterFunc(m.retryInterval, m.retryBootstrap)
+	}
+	m.Unlock()
+
+	if retry {
+		m.log.Error("bootstrap failed, retrying failed namespaces and shards in background",
+			zap.Any("failedShards", failed),
+			zap.Duration("retryInterval", m.retryInterval),
+			zap.Error(err))
+		return nil
+	}
+	return err
+}
+
+func (m *bootstrapManager) FailedBootstrapShards() map[string][]uint32 {
+	m.RLock()
+	defer m.RUnlock()
+
+	failed := make(map[string][]uint32, len(m.failedShards))
+	for namespace, shards := range m.failedShards {
+		failed[namespace] = append([]uint32(nil), shards...)
+	}
+	return failed
 }
 
 func (m *bootstrapManager) Report() {
@@ -167,14 +229,43 @@ func (m *bootstrapManager) Report() {
 	} else {
 		m.status.Update(0)
 	}
+
+	m.RLock()
+	numFailedShards := 0
+	for _, shards := range m.failedShards {
+		numFailedShards += len(shards)
+	}
+	m.RUnlock()
+	m.failedShardsGauge.Update(float64(numFailedShards))
+}
+
+func (m *bootstrapManager) Close() {
+	m.Lock()
+	m.closed = true
+	if m.retryTimer != nil {
+		m.retryTimer.Stop()
+	}
+	m.Unlock()
+}
+
+func (m *bootstrapManager) retryBootstrap() {
+	m.log.Info("retrying failed bootstrap")
+	// NB: Bootstrapping again only bootstraps the shards that are not yet
+	// bootstrapped, a bootstrap already in progress will pick up the failed
+	// shards when it completes so the retry is enqueued.
+	if err := m.Bootstrap(); err != nil && err != errBootstrapEnqueued {
+		m.log.Error("retry of failed bootstrap failed", zap.Error(err))
+	}
 }
 
-func (m *bootstrapManager) bootstrap() error {
+// bootstrap bootstraps the owned namespaces, returning the shards that are
+// not bootstrapped keyed by the namespaces that failed to bootstrap.
+func (m *bootstrapManager) bootstrap() (map[string][]uint32, error) {
 	// NB(r): construct new instance of the bootstrap process to avoid
 	// state being kept around by bootstrappers.
 	process, err := m.processProvider.Provide()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// NB(xichen): each bootstrapper should be responsible for choosing the most
@@ -183,21 +274,91 @@ func (m *bootstrapManager) bootstrap() error {
 
 	namespaces, err := m.database.GetOwnedNamespaces()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	startBootstrap := m.nowFn()
+	var (
+		failed         = make(map[string][]uint32)
+		startBootstrap = m.nowFn()
+		concurrency    = m.opts.BootstrapConcurrency()
+		workers        = xsync.NewWorkerPool(concurrency)
+		aborted        bool
+		mutex          sync.Mutex
+		wg             sync.WaitGroup
+	)
+	workers.Init()
 	for _, namespace := range namespaces {
-		startNamespaceBootstrap := m.nowFn()
-		if err := namespace.Bootstrap(startBootstrap, process); err != nil {
-			multiErr = multiErr.Add(err)
-		}
-		took := m.nowFn().Sub(startNamespaceBootstrap)
-		m.log.Info("bootstrap finished",
-			zap.String("namespace", namespace.ID().String()),
-			zap.Duration("duration", took),
-		)
+		namespace := namespace
+		wg.Add(1)
+		workers.Go(func() {
+			defer wg.Done()
+
+			if m.isClosed() {
+				// Leave the remaining namespaces unbootstrapped rather than
+				// delaying the close of the database.
+				mutex.Lock()
+				aborted = true
+				failed[namespace.ID().String()] = notBootstrappedShards(namespace)
+				mutex.Unlock()
+				return
+			}
+
+			// NB: namespaces bootstrapped concurrently each use their own
+			// instance of the bootstrap process so that no bootstrapper
+			// state is shared between them.
+			nsProcess := process
+			if concurrency > 1 {
+				var err error
+				nsProcess, err = m.processProvider.Provide()
+				if err != nil {
+					mutex.Lock()
+					multiErr = multiErr.Add(err)
+					failed[namespace.ID().String()] = notBootstrappedShards(namespace)
+					mutex.Unlock()
+					return
+				}
+			}
+
+			startNamespaceBootstrap := m.nowFn()
+			err := namespace.Bootstrap(startBootstrap, nsProcess)
+			if err != nil {
+				mutex.Lock()
+				multiErr = multiErr.Add(err)
+				failed[namespace.ID().String()] = notBootstrappedShards(namespace)
+				mutex.Unlock()
+			}
+			took := m.nowFn().Sub(startNamespaceBootstrap)
+			m.log.Info("bootstrap finished",
+				zap.String("namespace", namespace.ID().String()),
+				zap.Duration("duration", took),
+			)
+		})
 	}
+	wg.Wait()
 
-	return multiErr.FinalError()
+	if aborted {
+		multiErr = multiErr.Add(errBootstrapAborted)
+	}
+
+	return failed, multiErr.FinalError()
+}
+
+func (m *bootstrapManager) isClosed() bool {
+	m.RLock()
+	closed := m.closed
+	m.RUnlock()
+	return closed
+}
+
+func notBootstrappedShards(namespace databaseNamespace) []uint32 {
+	var shards []uint32
+	for shard, state := range namespace.BootstrapState() {
+		if state != Bootstrapped {
+			shards = append(shards, shard)
+		}
+	}
+	sort.Slice(shards, func(i, j int) bool {
+		return shards[i] < shards[j]
+	})
+	return shards
 }
@@ -237,6 +237,7 @@ func (s *commitLogSource) ReadData(
 	}
 
 	// Read / M3TSZ encode all the datapoints in the commit log that we need to read.
+	readStart := time.Now()
 	for iter.Next() {
 		series, dp, unit, annotation := iter.Current()
 		if !s.shouldEncodeForData(shardDataByShard, blockSize, series, dp.Timestamp) {
@@ -281,6 +282,7 @@ func (s *commitLogSource) ReadData(
 	// encoded by the worker goroutines
 	wg.Wait()
 	s.logEncodingOutcome(workerErrs, iter)
+	s.metrics.data.emitReplayed(datapointsRead, time.Since(readStart))
 
 	// Merge all the different encoders from the commit log that we created with
 	// the data that is available in the snapshot files.
@@ -1551,6 +1553,8 @@ func newCommitLogSourceDataAndIndexMetrics(scope tally.Scope) commitLogSourceDat
 type commitLogSourceMetrics struct {
 	corruptCommitlogFile tally.Counter
 	bootstrapping        tally.Gauge
+	datapointsReplayed   tally.Counter
+	replayThroughput     tally.Gauge
 }
 
 type gaugeLoopCloserFn func()
@@ -1577,5 +1581,17 @@ func newCommitLogSourceMetrics(scope tally.Scope) commitLogSourceMetrics {
 	return commitLogSourceMetrics{
 		corruptCommitlogFile: scope.SubScope("commitlog").Counter("corrupt"),
 		bootstrapping:        scope.SubScope("status").Gauge("bootstrapping"),
+		datapointsReplayed:   scope.SubScope("commitlog").Counter("datapoints-replayed"),
+		replayThroughput:     scope.SubScope("commitlog").Gauge("replay-throughput"),
 	}
 }
+
+// emitReplayed records the number of datapoints replayed from the commit log
+// and the replay throughput in datapoints per second.
+func (m commitLogSourceMetrics) emitReplayed(datapoints int, took time.Duration) {
+	m.datapointsReplayed.Inc(int64(datapoints))
+	if took <= 0 {
+		return
+	}
+	m.replayThroughput.Update(float64(datapoints) / took.Seconds())
+}
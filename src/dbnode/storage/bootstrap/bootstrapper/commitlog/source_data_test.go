@@ -46,6 +46,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 )
 
 var (
@@ -157,6 +158,53 @@ func testReadOrderedValues(t *testing.T, opts Options, md namespace.Metadata, se
 		values[:4], blockSize, res.ShardResults(), opts))
 }
 
+func TestReadEmitsReplayMetrics(t *testing.T) {
+	var (
+		scope = tally.NewTestScope("", nil)
+		rOpts = testDefaultOpts.ResultOptions()
+		iOpts = rOpts.InstrumentOptions().SetMetricsScope(scope)
+		opts  = testDefaultOpts.SetResultOptions(rOpts.SetInstrumentOptions(iOpts))
+		md    = testNsMetadata(t)
+		nsCtx = namespace.NewContextFrom(md)
+		src   = newCommitLogSource(opts, fs.Inspection{}).(*commitLogSource)
+	)
+
+	blockSize := md.Options().RetentionOptions().BlockSize()
+	now := time.Now()
+	start := now.Truncate(blockSize).Add(-blockSize)
+	end := now.Truncate(blockSize)
+
+	ranges := xtime.Ranges{}
+	ranges = ranges.AddRange(xtime.Range{
+		Start: start,
+		End:   end,
+	})
+
+	foo := ts.Series{Namespace: nsCtx.ID, Shard: 0, ID: ident.StringID("foo")}
+	values := []testValue{
+		{foo, start, 1.0, xtime.Second, nil},
+		{foo, start.Add(1 * time.Minute), 2.0, xtime.Second, nil},
+		{foo, start.Add(2 * time.Minute), 3.0, xtime.Second, nil},
+	}
+	src.newIteratorFn = func(_ commitlog.IteratorOpts) (commitlog.Iterator, []commitlog.ErrorWithPath, error) {
+		return newTestCommitLogIterator(values, nil), nil, nil
+	}
+
+	targetRanges := result.ShardTimeRanges{0: ranges}
+	res, err := src.ReadData(md, targetRanges, testDefaultRunOpts)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+
+	var replayed int64
+	for _, c := range scope.Snapshot().Counters() {
+		if c.Name() == "bootstrapper-commitlog.commitlog.datapoints-replayed" &&
+			c.Tags()["source_type"] == "data" {
+			replayed = c.Value()
+		}
+	}
+	require.Equal(t, int64(len(values)), replayed)
+}
+
 func TestReadUnorderedValues(t *testing.T) {
 	opts := testDefaultOpts
 	md := testNsMetadata(t)
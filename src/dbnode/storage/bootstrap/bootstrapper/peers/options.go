@@ -53,6 +53,7 @@ type options struct {
 	persistManager              persist.Manager
 	blockRetrieverManager       block.DatabaseBlockRetrieverManager
 	runtimeOptionsManager       m3dbruntime.OptionsManager
+	skipIfPeersUnavailable      bool
 }
 
 // NewOptions creates new bootstrap options
@@ -159,3 +160,13 @@ func (o *options) SetRuntimeOptionsManager(value m3dbruntime.OptionsManager) Opt
 func (o *options) RuntimeOptionsManager() m3dbruntime.OptionsManager {
 	return o.runtimeOptionsManager
 }
+
+func (o *options) SetSkipIfPeersUnavailable(value bool) Options {
+	opts := *o
+	opts.skipIfPeersUnavailable = value
+	return &opts
+}
+
+func (o *options) SkipIfPeersUnavailable() bool {
+	return o.skipIfPeersUnavailable
+}
@@ -759,6 +759,16 @@ func (s *peersSource) peerAvailability(
 		availableShardTimeRanges[shardIDUint] = shardsTimeRanges[shardIDUint]
 	}
 
+	if s.opts.SkipIfPeersUnavailable() &&
+		len(availableShardTimeRanges) != len(shardsTimeRanges) {
+		// Leave every shard to the next bootstrapper rather than only the
+		// shards the peers cannot serve.
+		s.log.Info("peers unavailable for some shards, skipping peers bootstrapper",
+			zap.Int("shards", len(shardsTimeRanges)),
+			zap.Int("availableShards", len(availableShardTimeRanges)))
+		return result.ShardTimeRanges{}, nil
+	}
+
 	return availableShardTimeRanges, nil
 }
 
@@ -71,6 +71,7 @@ func TestPeersSourceAvailableDataAndIndex(t *testing.T) {
 		topoState                         *topology.StateSnapshot
 		bootstrapReadConsistency          topology.ReadConsistencyLevel
 		shardsTimeRangesToBootstrap       result.ShardTimeRanges
+		skipIfPeersUnavailable            bool
 		expectedAvailableShardsTimeRanges result.ShardTimeRanges
 		expectedErr                       error
 	}{
@@ -128,6 +129,30 @@ func TestPeersSourceAvailableDataAndIndex(t *testing.T) {
 			shardsTimeRangesToBootstrap:       shardTimeRangesToBootstrap,
 			expectedAvailableShardsTimeRanges: result.ShardTimeRanges{},
 		},
+		{
+			title: "Returns empty if skipping when peers unavailable for some shards",
+			topoState: tu.NewStateSnapshot(2, tu.HostShardStates{
+				tu.SelfID:  tu.ShardsRange(0, numShards, shard.Initializing),
+				notSelfID1: tu.ShardsRange(0, numShards, shard.Available),
+				notSelfID2: tu.ShardsRange(0, numShards, shard.Available),
+			}),
+			bootstrapReadConsistency:          topology.ReadConsistencyLevelMajority,
+			shardsTimeRangesToBootstrap:       shardTimeRangesToBootstrapOneExtra,
+			skipIfPeersUnavailable:            true,
+			expectedAvailableShardsTimeRanges: result.ShardTimeRanges{},
+		},
+		{
+			title: "Returns success if skipping when peers unavailable and all shards available",
+			topoState: tu.NewStateSnapshot(2, tu.HostShardStates{
+				tu.SelfID:  tu.ShardsRange(0, numShards, shard.Initializing),
+				notSelfID1: tu.ShardsRange(0, numShards, shard.Available),
+				notSelfID2: tu.ShardsRange(0, numShards, shard.Available),
+			}),
+			bootstrapReadConsistency:          topology.ReadConsistencyLevelMajority,
+			shardsTimeRangesToBootstrap:       shardTimeRangesToBootstrap,
+			skipIfPeersUnavailable:            true,
+			expectedAvailableShardsTimeRanges: shardTimeRangesToBootstrap,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -147,7 +172,8 @@ func TestPeersSourceAvailableDataAndIndex(t *testing.T) {
 				AnyTimes()
 
 			opts := testDefaultOpts.
-				SetRuntimeOptionsManager(mockRuntimeOptsMgr)
+				SetRuntimeOptionsManager(mockRuntimeOptsMgr).
+				SetSkipIfPeersUnavailable(tc.skipIfPeersUnavailable)
 
 			src, err := newPeersSource(opts)
 			require.NoError(t, err)
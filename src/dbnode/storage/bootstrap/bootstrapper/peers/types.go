@@ -100,4 +100,14 @@ type Options interface {
 
 	// RuntimeOptionsManagers returns the RuntimeOptionsManager.
 	RuntimeOptionsManager() m3dbruntime.OptionsManager
+
+	// SetSkipIfPeersUnavailable sets whether the peers bootstrapper is
+	// skipped, leaving every range to the next bootstrapper, when the peers
+	// cannot serve all of the shards being bootstrapped.
+	SetSkipIfPeersUnavailable(value bool) Options
+
+	// SkipIfPeersUnavailable returns whether the peers bootstrapper is
+	// skipped, leaving every range to the next bootstrapper, when the peers
+	// cannot serve all of the shards being bootstrapped.
+	SkipIfPeersUnavailable() bool
 }
@@ -41,6 +41,15 @@ func (b noOpBootstrapProcessProvider) BootstrapperProvider() BootstrapperProvide
 	return nil
 }
 
+func (b noOpBootstrapProcessProvider) SetNamespaceBootstrapperProviders(
+	value map[string]BootstrapperProvider,
+) {
+}
+
+func (b noOpBootstrapProcessProvider) NamespaceBootstrapperProviders() map[string]BootstrapperProvider {
+	return nil
+}
+
 func (b noOpBootstrapProcessProvider) Provide() (Process, error) {
 	return noOpBootstrapProcess{}, nil
 }
@@ -21,6 +21,7 @@
 package bootstrap
 
 import (
+	"errors"
 	"sync"
 	"time"
 
@@ -36,6 +37,12 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+var (
+	// errBootstrapCancelled raised when the process cancellable is cancelled
+	// while bootstrapping.
+	errBootstrapCancelled = errors.New("bootstrap process cancelled")
+)
+
 // bootstrapProcessProvider is the bootstrapping process provider.
 type bootstrapProcessProvider struct {
 	sync.RWMutex
@@ -43,6 +50,7 @@ type bootstrapProcessProvider struct {
 	resultOpts           result.Options
 	log                  *zap.Logger
 	bootstrapperProvider BootstrapperProvider
+	nsProviders          map[string]BootstrapperProvider
 }
 
 type bootstrapRunType string
@@ -82,6 +90,20 @@ func (b *bootstrapProcessProvider) BootstrapperProvider() BootstrapperProvider {
 	return b.bootstrapperProvider
 }
 
+func (b *bootstrapProcessProvider) SetNamespaceBootstrapperProviders(
+	value map[string]BootstrapperProvider,
+) {
+	b.Lock()
+	defer b.Unlock()
+	b.nsProviders = value
+}
+
+func (b *bootstrapProcessProvider) NamespaceBootstrapperProviders() map[string]BootstrapperProvider {
+	b.RLock()
+	defer b.RUnlock()
+	return b.nsProviders
+}
+
 func (b *bootstrapProcessProvider) Provide() (Process, error) {
 	b.RLock()
 	defer b.RUnlock()
@@ -90,6 +112,14 @@ func (b *bootstrapProcessProvider) Provide() (Process, error) {
 		return nil, err
 	}
 
+	nsBootstrappers := make(map[string]Bootstrapper, len(b.nsProviders))
+	for ns, provider := range b.nsProviders {
+		nsBootstrappers[ns], err = provider.Provide()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	initialTopologyState, err := b.newInitialTopologyState()
 	if err != nil {
 		return nil, err
@@ -101,6 +131,7 @@ func (b *bootstrapProcessProvider) Provide() (Process, error) {
 		nowFn:                b.resultOpts.ClockOptions().NowFn(),
 		log:                  b.log,
 		bootstrapper:         bootstrapper,
+		nsBootstrappers:      nsBootstrappers,
 		initialTopologyState: initialTopologyState,
 	}, nil
 }
@@ -146,6 +177,7 @@ type bootstrapProcess struct {
 	nowFn                clock.NowFn
 	log                  *zap.Logger
 	bootstrapper         Bootstrapper
+	nsBootstrappers      map[string]Bootstrapper
 	initialTopologyState *topology.StateSnapshot
 }
 
@@ -154,11 +186,25 @@ func (b bootstrapProcess) Run(
 	namespace namespace.Metadata,
 	shards []uint32,
 ) (ProcessResult, error) {
+	// NB: the process is passed by value so resolving the bootstrapper for
+	// the namespace here does not affect runs for other namespaces.
+	if bootstrapper, ok := b.nsBootstrappers[namespace.ID().String()]; ok {
+		b.bootstrapper = bootstrapper
+	}
+
+	if b.isCancelled() {
+		return ProcessResult{}, errBootstrapCancelled
+	}
+
 	dataResult, err := b.bootstrapData(start, namespace, shards)
 	if err != nil {
 		return ProcessResult{}, err
 	}
 
+	if b.isCancelled() {
+		return ProcessResult{}, errBootstrapCancelled
+	}
+
 	indexResult, err := b.bootstrapIndex(start, namespace, shards)
 	if err != nil {
 		return ProcessResult{}, err
@@ -179,6 +225,10 @@ func (b bootstrapProcess) bootstrapData(
 	ropts := namespace.Options().RetentionOptions()
 	targetRanges := b.targetRangesForData(at, ropts)
 	for _, target := range targetRanges {
+		if b.isCancelled() {
+			return nil, errBootstrapCancelled
+		}
+
 		logFields := b.logFields(bootstrapDataRunType, namespace,
 			shards, target.Range)
 		b.logBootstrapRun(logFields)
@@ -214,6 +264,10 @@ func (b bootstrapProcess) bootstrapIndex(
 
 	targetRanges := b.targetRangesForIndex(at, ropts, idxopts)
 	for _, target := range targetRanges {
+		if b.isCancelled() {
+			return nil, errBootstrapCancelled
+		}
+
 		logFields := b.logFields(bootstrapIndexRunType, namespace,
 			shards, target.Range)
 		b.logBootstrapRun(logFields)
@@ -234,6 +288,10 @@ func (b bootstrapProcess) bootstrapIndex(
 	return bootstrapResult, nil
 }
 
+func (b bootstrapProcess) isCancelled() bool {
+	return b.processOpts.Cancellable().IsCancelled()
+}
+
 func (b bootstrapProcess) logFields(
 	runType bootstrapRunType,
 	namespace namespace.Metadata,
@@ -24,6 +24,7 @@ import (
 	"errors"
 
 	"github.com/m3db/m3/src/dbnode/topology"
+	xcontext "github.com/m3db/m3/src/x/context"
 )
 
 const (
@@ -41,6 +42,7 @@ type processOptions struct {
 	cacheSeriesMetadata bool
 	topoMapProvider     topology.MapProvider
 	origin              topology.Host
+	cancellable         xcontext.Cancellable
 }
 
 // NewProcessOptions creates new bootstrap run options
@@ -49,6 +51,7 @@ func NewProcessOptions() ProcessOptions {
 		cacheSeriesMetadata: defaultCacheSeriesMetadata,
 		topoMapProvider:     nil,
 		origin:              nil,
+		cancellable:         xcontext.NewNoOpCanncellable(),
 	}
 }
 
@@ -93,3 +96,13 @@ func (o *processOptions) SetOrigin(value topology.Host) ProcessOptions {
 func (o *processOptions) Origin() topology.Host {
 	return o.origin
 }
+
+func (o *processOptions) SetCancellable(value xcontext.Cancellable) ProcessOptions {
+	opts := *o
+	opts.cancellable = value
+	return &opts
+}
+
+func (o *processOptions) Cancellable() xcontext.Cancellable {
+	return o.cancellable
+}
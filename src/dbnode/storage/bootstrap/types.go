@@ -27,6 +27,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/topology"
+	xcontext "github.com/m3db/m3/src/x/context"
 	xtime "github.com/m3db/m3/src/x/time"
 )
 
@@ -41,6 +42,15 @@ type ProcessProvider interface {
 	// running the process.
 	BootstrapperProvider() BootstrapperProvider
 
+	// SetNamespaceBootstrapperProviders sets the bootstrapper providers to
+	// use for specific namespaces, keyed by namespace ID, in place of the
+	// default bootstrapper provider.
+	SetNamespaceBootstrapperProviders(value map[string]BootstrapperProvider)
+
+	// NamespaceBootstrapperProviders returns the bootstrapper providers to
+	// use for specific namespaces, keyed by namespace ID.
+	NamespaceBootstrapperProviders() map[string]BootstrapperProvider
+
 	// Provide constructs a bootstrap process.
 	Provide() (Process, error)
 }
@@ -100,6 +110,14 @@ type ProcessOptions interface {
 	// Origin returns the origin.
 	Origin() topology.Host
 
+	// SetCancellable sets the cancellable checked by the process between
+	// bootstrap runs, once cancelled any remaining runs are aborted.
+	SetCancellable(value xcontext.Cancellable) ProcessOptions
+
+	// Cancellable returns the cancellable checked by the process between
+	// bootstrap runs.
+	Cancellable() xcontext.Cancellable
+
 	// Validate validates that the ProcessOptions are correct.
 	Validate() error
 }
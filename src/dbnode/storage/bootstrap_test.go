@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3/src/x/ident"
 
 	"github.com/golang/mock/gomock"
@@ -45,7 +46,11 @@ func TestDatabaseBootstrapWithBootstrapError(t *testing.T) {
 
 	ns := NewMockdatabaseNamespace(ctrl)
 	ns.EXPECT().Bootstrap(now, gomock.Any()).Return(fmt.Errorf("an error"))
-	ns.EXPECT().ID().Return(ident.StringID("test"))
+	ns.EXPECT().ID().Return(ident.StringID("test")).AnyTimes()
+	ns.EXPECT().BootstrapState().Return(ShardBootstrapStates{
+		0: Bootstrapped,
+		1: BootstrapNotStarted,
+	})
 	namespaces := []databaseNamespace{ns}
 
 	db := NewMockdatabase(ctrl)
@@ -60,6 +65,150 @@ func TestDatabaseBootstrapWithBootstrapError(t *testing.T) {
 	require.NotNil(t, err)
 	require.Equal(t, "an error", err.Error())
 	require.Equal(t, Bootstrapped, bsm.state)
+	require.Equal(t, map[string][]uint32{"test": []uint32{1}}, bsm.FailedBootstrapShards())
+}
+
+func TestDatabaseBootstrapNamespacesConcurrently(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := DefaultTestOptions().SetBootstrapConcurrency(2)
+	now := time.Now()
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return now
+	}))
+
+	// Each namespace bootstrap waits for the other to start, which only
+	// completes if they are bootstrapped concurrently.
+	var bothStarted sync.WaitGroup
+	bothStarted.Add(2)
+	waitForBoth := func(arg0, arg1 interface{}) {
+		bothStarted.Done()
+		done := make(chan struct{})
+		go func() {
+			bothStarted.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Error("namespaces were not bootstrapped concurrently")
+		}
+	}
+
+	ns1 := NewMockdatabaseNamespace(ctrl)
+	ns1.EXPECT().Bootstrap(now, gomock.Any()).Do(waitForBoth).Return(fmt.Errorf("an error"))
+	ns1.EXPECT().ID().Return(ident.StringID("ns1")).AnyTimes()
+	ns1.EXPECT().BootstrapState().Return(ShardBootstrapStates{
+		0: BootstrapNotStarted,
+	})
+	ns2 := NewMockdatabaseNamespace(ctrl)
+	ns2.EXPECT().Bootstrap(now, gomock.Any()).Do(waitForBoth).Return(nil)
+	ns2.EXPECT().ID().Return(ident.StringID("ns2")).AnyTimes()
+
+	db := NewMockdatabase(ctrl)
+	db.EXPECT().GetOwnedNamespaces().Return([]databaseNamespace{ns1, ns2}, nil)
+
+	m := NewMockdatabaseMediator(ctrl)
+	m.EXPECT().DisableFileOps()
+	m.EXPECT().EnableFileOps().AnyTimes()
+	bsm := newBootstrapManager(db, m, opts).(*bootstrapManager)
+	err := bsm.Bootstrap()
+
+	require.NotNil(t, err)
+	require.Equal(t, "an error", err.Error())
+	require.Equal(t, Bootstrapped, bsm.state)
+	require.Equal(t, map[string][]uint32{"ns1": []uint32{0}}, bsm.FailedBootstrapShards())
+}
+
+func TestDatabaseBootstrapWithBootstrapErrorRetried(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := DefaultTestOptions().
+		SetBootstrapFailureRetryInterval(10 * time.Millisecond)
+	now := time.Now()
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return now
+	}))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	ns := NewMockdatabaseNamespace(ctrl)
+	gomock.InOrder(
+		ns.EXPECT().Bootstrap(now, gomock.Any()).Return(fmt.Errorf("an error")),
+		ns.EXPECT().Bootstrap(now, gomock.Any()).Return(nil).
+			Do(func(arg0, arg1 interface{}) {
+				wg.Done()
+			}),
+	)
+	ns.EXPECT().ID().Return(ident.StringID("test")).AnyTimes()
+	ns.EXPECT().BootstrapState().Return(ShardBootstrapStates{
+		0: BootstrapNotStarted,
+	})
+
+	db := NewMockdatabase(ctrl)
+	db.EXPECT().GetOwnedNamespaces().Return([]databaseNamespace{ns}, nil).Times(2)
+
+	m := NewMockdatabaseMediator(ctrl)
+	m.EXPECT().DisableFileOps().Times(2)
+	m.EXPECT().EnableFileOps().AnyTimes()
+	bsm := newBootstrapManager(db, m, opts).(*bootstrapManager)
+	defer bsm.Close()
+
+	// The failure is tracked rather than returned.
+	require.NoError(t, bsm.Bootstrap())
+	require.True(t, bsm.IsBootstrapped())
+	require.Equal(t, map[string][]uint32{"test": []uint32{0}}, bsm.FailedBootstrapShards())
+
+	// Wait for the background retry to succeed.
+	wg.Wait()
+	for i := 0; i < 100 && len(bsm.FailedBootstrapShards()) > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Empty(t, bsm.FailedBootstrapShards())
+}
+
+func TestDatabaseBootstrapWithProvideErrorRetried(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	provider := bootstrap.NewMockProcessProvider(ctrl)
+	gomock.InOrder(
+		provider.EXPECT().Provide().Return(nil, fmt.Errorf("an error")),
+		provider.EXPECT().Provide().Return(bootstrap.NewMockProcess(ctrl), nil).
+			Do(func() {
+				wg.Done()
+			}),
+	)
+
+	opts := DefaultTestOptions().
+		SetBootstrapProcessProvider(provider).
+		SetBootstrapFailureRetryInterval(10 * time.Millisecond)
+
+	db := NewMockdatabase(ctrl)
+	db.EXPECT().GetOwnedNamespaces().Return(nil, nil)
+
+	m := NewMockdatabaseMediator(ctrl)
+	m.EXPECT().DisableFileOps().Times(2)
+	m.EXPECT().EnableFileOps().AnyTimes()
+	bsm := newBootstrapManager(db, m, opts).(*bootstrapManager)
+	defer bsm.Close()
+
+	// The whole node failing to bootstrap is tracked so readiness fails.
+	require.NoError(t, bsm.Bootstrap())
+	require.True(t, bsm.IsBootstrapped())
+	require.Equal(t, map[string][]uint32{allNamespacesFailedBootstrap: nil},
+		bsm.FailedBootstrapShards())
+
+	// Wait for the background retry to succeed.
+	wg.Wait()
+	for i := 0; i < 100 && len(bsm.FailedBootstrapShards()) > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.Empty(t, bsm.FailedBootstrapShards())
 }
 
 func TestDatabaseBootstrapSubsequentCallsQueued(t *testing.T) {
@@ -114,3 +263,53 @@ func TestDatabaseBootstrapSubsequentCallsQueued(t *testing.T) {
 	err := bsm.Bootstrap()
 	require.Nil(t, err)
 }
+
+func TestDatabaseBootstrapAbortedOnClose(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := DefaultTestOptions().
+		SetBootstrapFailureRetryInterval(10 * time.Millisecond)
+	now := time.Now()
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return now
+	}))
+
+	m := NewMockdatabaseMediator(ctrl)
+	m.EXPECT().DisableFileOps()
+	m.EXPECT().EnableFileOps().AnyTimes()
+
+	db := NewMockdatabase(ctrl)
+	bsm := newBootstrapManager(db, m, opts).(*bootstrapManager)
+
+	// Close the bootstrap manager while the first namespace bootstraps, the
+	// second namespace should not be bootstrapped.
+	first := NewMockdatabaseNamespace(ctrl)
+	first.EXPECT().
+		Bootstrap(now, gomock.Any()).
+		Return(nil).
+		Do(func(arg0, arg1 interface{}) {
+			bsm.Close()
+		})
+	first.EXPECT().ID().Return(ident.StringID("first")).AnyTimes()
+
+	second := NewMockdatabaseNamespace(ctrl)
+	second.EXPECT().ID().Return(ident.StringID("second")).AnyTimes()
+	second.EXPECT().BootstrapState().Return(ShardBootstrapStates{
+		0: BootstrapNotStarted,
+	})
+
+	db.EXPECT().
+		GetOwnedNamespaces().
+		Return([]databaseNamespace{first, second}, nil)
+
+	err := bsm.Bootstrap()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), errBootstrapAborted.Error())
+	require.Equal(t, map[string][]uint32{"second": []uint32{0}}, bsm.FailedBootstrapShards())
+
+	// No retry is scheduled once closed.
+	bsm.RLock()
+	assert.Nil(t, bsm.retryTimer)
+	bsm.RUnlock()
+}
@@ -30,11 +30,13 @@ import (
 
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
 	"github.com/m3db/m3/src/dbnode/sharding"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
+	"github.com/m3db/m3/src/dbnode/storage/series"
 	"github.com/m3db/m3/src/dbnode/tracepoint"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
@@ -45,6 +47,7 @@ import (
 	xtime "github.com/m3db/m3/src/x/time"
 
 	opentracinglog "github.com/opentracing/opentracing-go/log"
+	"github.com/pborman/uuid"
 	"github.com/uber-go/tally"
 	"go.uber.org/zap"
 )
@@ -73,6 +76,9 @@ var (
 	// errWriterDoesNotImplementWriteBatch is raised when the provided ts.BatchWriter does not implement
 	// ts.WriteBatch.
 	errWriterDoesNotImplementWriteBatch = errors.New("provided writer does not implement ts.WriteBatch")
+
+	// errWriteTimeout raised when the deadline of a write is exceeded before the write is applied.
+	errWriteTimeout = errors.New("write timed out before being applied")
 )
 
 type databaseState int
@@ -112,6 +118,8 @@ type db struct {
 	log     *zap.Logger
 
 	writeBatchPool *ts.WriteBatchPool
+
+	writeStageTimings bool
 }
 
 type databaseMetrics struct {
@@ -126,6 +134,7 @@ type databaseMetrics struct {
 	unknownNamespaceQueryIDs            tally.Counter
 	errQueryIDsIndexDisabled            tally.Counter
 	errWriteTaggedIndexDisabled         tally.Counter
+	writeCommitLogEnqueue               tally.Histogram
 }
 
 func newDatabaseMetrics(scope tally.Scope) databaseMetrics {
@@ -143,6 +152,8 @@ func newDatabaseMetrics(scope tally.Scope) databaseMetrics {
 		unknownNamespaceQueryIDs:            unknownNamespaceScope.Counter("query-ids"),
 		errQueryIDsIndexDisabled:            indexDisabledScope.Counter("err-query-ids"),
 		errWriteTaggedIndexDisabled:         indexDisabledScope.Counter("err-write-tagged"),
+		writeCommitLogEnqueue: scope.Histogram("write-commitlog-enqueue-latency",
+			series.WriteStageDurationBuckets()),
 	}
 }
 
@@ -170,6 +181,11 @@ func NewDatabase(
 		nowFn  = opts.ClockOptions().NowFn()
 	)
 
+	if hwm := opts.CommitLogOptions().BackpressureHighWatermark(); hwm > 0 {
+		opts = opts.SetSeriesOptions(opts.SeriesOptions().SetWriteBackpressure(
+			newCommitLogBackpressure(commitLog, hwm, scope)))
+	}
+
 	d := &db{
 		opts:                  opts,
 		nowFn:                 nowFn,
@@ -181,6 +197,7 @@ func NewDatabase(
 		metrics:               newDatabaseMetrics(scope),
 		log:                   logger,
 		writeBatchPool:        opts.WriteBatchPool(),
+		writeStageTimings:     iopts.DebugMetricsEnabled(),
 	}
 
 	databaseIOpts := iopts.SetMetricsScope(scope)
@@ -291,6 +308,13 @@ func (d *db) UpdateOwnedNamespaces(newNamespaces namespace.Map) error {
 		return enrichedErr
 	}
 
+	// reject any namespaces that exceed the maximum retention before adding
+	// the rest so a single namespace does not prevent the others being added
+	adds, rejectErr := d.rejectNamespacesExceedingMaxRetention(adds)
+	if rejectErr != nil {
+		d.log.Error("rejected namespaces exceeding max retention", zap.Error(rejectErr))
+	}
+
 	// add any namespaces marked for addition
 	if err := d.addNamespacesWithLock(adds); err != nil {
 		enrichedErr := fmt.Errorf("unable to add namespaces: %v", err)
@@ -308,7 +332,35 @@ func (d *db) UpdateOwnedNamespaces(newNamespaces namespace.Map) error {
 		d.queueBootstrapWithLock()
 	}
 
-	return nil
+	return rejectErr
+}
+
+// rejectNamespacesExceedingMaxRetention returns the namespaces whose retention
+// period does not exceed the configured maximum along with an error
+// describing each namespace that does.
+func (d *db) rejectNamespacesExceedingMaxRetention(
+	namespaces []namespace.Metadata,
+) ([]namespace.Metadata, error) {
+	maxRetention := d.opts.MaxNamespaceRetentionPeriod()
+	if maxRetention <= 0 {
+		return namespaces, nil
+	}
+
+	var (
+		allowed  = namespaces[:0]
+		multiErr xerrors.MultiError
+	)
+	for _, n := range namespaces {
+		retention := n.Options().RetentionOptions().RetentionPeriod()
+		if retention > maxRetention {
+			multiErr = multiErr.Add(fmt.Errorf(
+				"namespace %s retention period %v exceeds max namespace retention period %v",
+				n.ID().String(), retention, maxRetention))
+			continue
+		}
+		allowed = append(allowed, n)
+	}
+	return allowed, multiErr.FinalError()
 }
 
 func (d *db) namespaceDeltaWithLock(newNamespaces namespace.Map) ([]ident.ID, []namespace.Metadata, []namespace.Metadata) {
@@ -608,6 +660,9 @@ func (d *db) Write(
 		d.metrics.unknownNamespaceWrite.Inc(1)
 		return err
 	}
+	if err := writeDeadlineExceeded(ctx); err != nil {
+		return err
+	}
 
 	series, wasWritten, err := n.Write(ctx, id, timestamp, value, unit, annotation)
 	if err != nil {
@@ -619,7 +674,7 @@ func (d *db) Write(
 	}
 
 	dp := ts.Datapoint{Timestamp: timestamp, Value: value}
-	return d.commitLog.Write(ctx, series, dp, unit, annotation)
+	return d.writeCommitLog(ctx, series, dp, unit, annotation)
 }
 
 func (d *db) WriteTagged(
@@ -637,6 +692,9 @@ func (d *db) WriteTagged(
 		d.metrics.unknownNamespaceWriteTagged.Inc(1)
 		return err
 	}
+	if err := writeDeadlineExceeded(ctx); err != nil {
+		return err
+	}
 
 	series, wasWritten, err := n.WriteTagged(ctx, id, tags, timestamp, value, unit, annotation)
 	if err != nil {
@@ -648,7 +706,7 @@ func (d *db) WriteTagged(
 	}
 
 	dp := ts.Datapoint{Timestamp: timestamp, Value: value}
-	return d.commitLog.Write(ctx, series, dp, unit, annotation)
+	return d.writeCommitLog(ctx, series, dp, unit, annotation)
 }
 
 func (d *db) BatchWriter(namespace ident.ID, batchSize int) (ts.BatchWriter, error) {
@@ -705,6 +763,10 @@ func (d *db) writeBatch(
 		}
 		return err
 	}
+	if err := writeDeadlineExceeded(ctx); err != nil {
+		writes.Finalize()
+		return err
+	}
 
 	iter := writes.Iter()
 	for i, write := range iter {
@@ -759,7 +821,46 @@ func (d *db) writeBatch(
 		return nil
 	}
 
-	return d.commitLog.WriteBatch(ctx, writes)
+	if !d.writeStageTimings {
+		return d.commitLog.WriteBatch(ctx, writes)
+	}
+
+	start := time.Now()
+	err = d.commitLog.WriteBatch(ctx, writes)
+	d.metrics.writeCommitLogEnqueue.RecordDuration(time.Since(start))
+	return err
+}
+
+// writeDeadlineExceeded returns a retryable error if the deadline set on the
+// write context by the write timeout has been exceeded. It is checked before
+// the series and index are written to so that a write that has already timed
+// out is not applied. A write that instead times out enqueueing to the commit
+// log has already been applied, so is readable but not durable.
+func writeDeadlineExceeded(ctx context.Context) error {
+	goCtx, ok := ctx.GoContext()
+	if !ok || goCtx.Err() == nil {
+		return nil
+	}
+	return xerrors.NewRetryableError(errWriteTimeout)
+}
+
+// writeCommitLog writes a single datapoint to the commit log, timing the
+// commit log enqueue if write stage timings are enabled.
+func (d *db) writeCommitLog(
+	ctx context.Context,
+	series ts.Series,
+	dp ts.Datapoint,
+	unit xtime.Unit,
+	annotation []byte,
+) error {
+	if !d.writeStageTimings {
+		return d.commitLog.Write(ctx, series, dp, unit, annotation)
+	}
+
+	start := time.Now()
+	err := d.commitLog.Write(ctx, series, dp, unit, annotation)
+	d.metrics.writeCommitLogEnqueue.RecordDuration(time.Since(start))
+	return err
 }
 
 func (d *db) QueryIDs(
@@ -819,6 +920,60 @@ func (d *db) ReadEncoded(
 	return n.ReadEncoded(ctx, id, start, end)
 }
 
+func (d *db) ReadTransformed(
+	ctx context.Context,
+	namespace ident.ID,
+	id ident.ID,
+	start, end time.Time,
+	transforms []string,
+) ([]ts.Datapoint, error) {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		d.metrics.unknownNamespaceRead.Inc(1)
+		return nil, err
+	}
+
+	var (
+		registered = d.opts.ReadTransforms()
+		pipeline   = make([]ReadTransform, 0, len(transforms))
+	)
+	for _, name := range transforms {
+		newTransformFn, ok := registered[name]
+		if !ok {
+			return nil, xerrors.NewInvalidParamsError(
+				fmt.Errorf("unknown read transform: %s", name))
+		}
+		pipeline = append(pipeline, newTransformFn())
+	}
+
+	encoded, err := n.ReadEncoded(ctx, id, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	iter := d.opts.MultiReaderIteratorPool().Get()
+	iter.ResetSliceOfSlices(
+		xio.NewReaderSliceOfSlicesFromBlockReadersIterator(encoded), n.Schema())
+	defer iter.Close()
+
+	var results []ts.Datapoint
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		// Blocks may contain datapoints outside of the requested range.
+		if dp.Timestamp.Before(start) || !dp.Timestamp.Before(end) {
+			continue
+		}
+		if dp, ok := applyReadTransforms(pipeline, dp); ok {
+			results = append(results, dp)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
 func (d *db) FetchBlocks(
 	ctx context.Context,
 	namespace ident.ID,
@@ -865,6 +1020,26 @@ func (d *db) IsBootstrapped() bool {
 	return d.mediator.IsBootstrapped()
 }
 
+func (d *db) FailedBootstrapShards() map[string][]uint32 {
+	return d.mediator.FailedBootstrapShards()
+}
+
+func (d *db) NamespacesBootstrapped() map[string]bool {
+	states := d.BootstrapState().NamespaceBootstrapStates
+	bootstrapped := make(map[string]bool, len(states))
+	for ns, shardStates := range states {
+		nsBootstrapped := true
+		for _, state := range shardStates {
+			if state != Bootstrapped {
+				nsBootstrapped = false
+				break
+			}
+		}
+		bootstrapped[ns] = nsBootstrapped
+	}
+	return bootstrapped
+}
+
 // IsBootstrappedAndDurable should only return true if the following conditions are met:
 //    1. The database is bootstrapped.
 //    2. The last successful snapshot began AFTER the last bootstrap completed.
@@ -922,6 +1097,18 @@ func (d *db) Repair() error {
 	return d.mediator.Repair()
 }
 
+func (d *db) Quiesce() error {
+	return d.mediator.Quiesce()
+}
+
+func (d *db) Unquiesce() error {
+	return d.mediator.Unquiesce()
+}
+
+func (d *db) IsQuiesced() bool {
+	return d.mediator.IsQuiesced()
+}
+
 func (d *db) Truncate(namespace ident.ID) (int64, error) {
 	n, err := d.namespaceFor(namespace)
 	if err != nil {
@@ -936,6 +1123,34 @@ func (d *db) IsOverloaded() bool {
 	return queueSize >= commitLogQueueCapacityOverloadedFactor*queueCapacity
 }
 
+// commitLogBackpressure applies write backpressure while the commit log
+// backlog is at or above the high watermark.
+type commitLogBackpressure struct {
+	commitLog     commitlog.CommitLog
+	highWatermark int64
+	rejected      tally.Counter
+}
+
+func newCommitLogBackpressure(
+	commitLog commitlog.CommitLog,
+	highWatermark int,
+	scope tally.Scope,
+) *commitLogBackpressure {
+	return &commitLogBackpressure{
+		commitLog:     commitLog,
+		highWatermark: int64(highWatermark),
+		rejected:      scope.Counter("commitlog-backpressure.rejected"),
+	}
+}
+
+func (b *commitLogBackpressure) Applies() bool {
+	if b.commitLog.QueueLength() < b.highWatermark {
+		return false
+	}
+	b.rejected.Inc(1)
+	return true
+}
+
 func (d *db) BootstrapState() DatabaseBootstrapState {
 	nsBootstrapStates := NamespaceBootstrapStates{}
 
@@ -963,6 +1178,81 @@ func (d *db) FlushState(
 	return n.FlushState(shardID, blockStart)
 }
 
+func (d *db) SeriesBufferDebugInfo(
+	namespace ident.ID,
+	id ident.ID,
+) ([]series.BufferBlockDebugInfo, error) {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return n.SeriesBufferDebugInfo(id)
+}
+
+func (d *db) ShardStats(namespace ident.ID) ([]ShardStats, error) {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return n.ShardStats(), nil
+}
+
+func (d *db) ApproxMemoryBySeries(
+	namespace ident.ID,
+	n int,
+) ([]SeriesMemory, error) {
+	ns, err := d.namespaceFor(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return ns.ApproxMemoryBySeries(n), nil
+}
+
+func (d *db) ForceSnapshot(
+	namespace ident.ID,
+	blockStart time.Time,
+) (int, error) {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		return 0, err
+	}
+
+	blockSize := n.Options().RetentionOptions().BlockSize()
+	if !blockStart.Equal(blockStart.Truncate(blockSize)) {
+		return 0, xerrors.NewInvalidParamsError(fmt.Errorf(
+			"block start %s is not aligned to block size %s",
+			blockStart.String(), blockSize.String()))
+	}
+
+	preparer, err := d.opts.PersistManager().StartSnapshotPersist(uuid.NewUUID())
+	if err != nil {
+		return 0, err
+	}
+
+	// NB: the namespace may snapshot shards concurrently while there is a
+	// single persist manager so the shards are written one at a time.
+	snapshotPersist := newSnapshotPreparerPool([]persist.SnapshotPreparer{preparer},
+		d.scope.SubScope("force-snapshot").Gauge("snapshots-in-progress"))
+
+	multiErr := xerrors.NewMultiError()
+	result, err := n.Snapshot(blockStart, d.nowFn(), snapshotPersist)
+	multiErr = multiErr.Add(err)
+	multiErr = multiErr.Add(snapshotPersist.DonePartialSnapshot())
+	return result.SeriesPersist, multiErr.FinalError()
+}
+
+func (d *db) SubscribeWrites(
+	namespace ident.ID,
+	id ident.ID,
+	bufferSize int,
+) (WriteSubscription, error) {
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return n.SubscribeWrites(id, bufferSize)
+}
+
 func (d *db) namespaceFor(namespace ident.ID) (databaseNamespace, error) {
 	d.RLock()
 	n, exists := d.namespaces.Get(namespace)
@@ -31,7 +31,10 @@ import (
 
 	"github.com/m3db/m3/src/cluster/shard"
 	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/sharding"
@@ -42,6 +45,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/tracepoint"
 	"github.com/m3db/m3/src/dbnode/ts"
 	xmetrics "github.com/m3db/m3/src/dbnode/x/metrics"
+	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/m3ninx/idx"
 	xclock "github.com/m3db/m3/src/x/clock"
 	"github.com/m3db/m3/src/x/context"
@@ -282,6 +286,55 @@ func TestDatabaseReadEncodedNamespaceOwned(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestDatabaseReadTransformed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	d, mapCh, _ := defaultTestDatabase(t, ctrl, Bootstrapped)
+	defer func() {
+		close(mapCh)
+	}()
+
+	var (
+		ns    = ident.StringID("testns1")
+		id    = ident.StringID("bar")
+		start = time.Now().Truncate(time.Hour)
+		end   = start.Add(time.Hour)
+	)
+	encoder := m3tsz.NewEncoder(start, nil, true, encoding.NewOptions())
+	for i, v := range []float64{10, 30, 90} {
+		dp := ts.Datapoint{Timestamp: start.Add(time.Duration(i) * 10 * time.Second), Value: v}
+		require.NoError(t, encoder.Encode(dp, xtime.Second, nil))
+	}
+	segment := encoder.Discard()
+	encoded := [][]xio.BlockReader{{{
+		SegmentReader: xio.NewSegmentReader(segment),
+		Start:         start,
+		BlockSize:     time.Hour,
+	}}}
+
+	mockNamespace := NewMockdatabaseNamespace(ctrl)
+	mockNamespace.EXPECT().ReadEncoded(ctx, id, start, end).Return(encoded, nil)
+	mockNamespace.EXPECT().Schema().Return(nil)
+	d.namespaces.Set(ns, mockNamespace)
+
+	res, err := d.ReadTransformed(ctx, ns, id, start, end,
+		[]string{ReadTransformDelta, ReadTransformRate})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(res))
+	require.True(t, res[0].Equal(ts.Datapoint{
+		Timestamp: start.Add(20 * time.Second),
+		Value:     4,
+	}))
+
+	_, err = d.ReadTransformed(ctx, ns, id, start, end, []string{"unknown"})
+	require.Error(t, err)
+	require.True(t, xerrors.IsInvalidParams(err))
+}
+
 func TestDatabaseFetchBlocksNamespaceNotOwned(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -565,6 +618,47 @@ func TestDatabaseAddNamespace(t *testing.T) {
 	require.Equal(t, md1.Options(), ns3.Options())
 }
 
+func TestDatabaseAddNamespaceExceedingMaxRetention(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	d, mapCh, _ := defaultTestDatabase(t, ctrl, Bootstrapped)
+	require.NoError(t, d.Open())
+	defer func() {
+		close(mapCh)
+		require.NoError(t, d.Close())
+		leaktest.CheckTimeout(t, time.Second)()
+	}()
+
+	d.opts = d.opts.SetMaxNamespaceRetentionPeriod(7 * 24 * time.Hour)
+
+	// construct new namespace Map with one namespace within the maximum
+	// retention and one exceeding it
+	md1, err := namespace.NewMetadata(defaultTestNs1ID, defaultTestNs1Opts)
+	require.NoError(t, err)
+	md2, err := namespace.NewMetadata(defaultTestNs2ID, defaultTestNs2Opts)
+	require.NoError(t, err)
+	md3, err := namespace.NewMetadata(ident.StringID("and1"), defaultTestNs1Opts)
+	require.NoError(t, err)
+	longRetentionOpts := defaultTestNs1Opts.SetRetentionOptions(
+		defaultTestRetentionOpts.SetRetentionPeriod(3 * 365 * 24 * time.Hour))
+	md4, err := namespace.NewMetadata(ident.StringID("and2"), longRetentionOpts)
+	require.NoError(t, err)
+	nsMap, err := namespace.NewMap([]namespace.Metadata{md1, md2, md3, md4})
+	require.NoError(t, err)
+
+	err = d.UpdateOwnedNamespaces(nsMap)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "namespace and2 retention period")
+
+	// ensure only the namespace within the maximum retention was added
+	require.Len(t, d.Namespaces(), 3)
+	_, ok := d.Namespace(ident.StringID("and1"))
+	require.True(t, ok)
+	_, ok = d.Namespace(ident.StringID("and2"))
+	require.False(t, ok)
+}
+
 func TestDatabaseUpdateNamespace(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1094,6 +1188,31 @@ func TestDatabaseBootstrapState(t *testing.T) {
 	}, dbBootstrapState)
 }
 
+func TestDatabaseNamespacesBootstrapped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	d, mapCh, _ := defaultTestDatabase(t, ctrl, Bootstrapped)
+	defer func() {
+		close(mapCh)
+	}()
+
+	ns1 := dbAddNewMockNamespace(ctrl, d, "testns1")
+	ns1.EXPECT().BootstrapState().Return(ShardBootstrapStates{
+		1: Bootstrapped,
+		2: Bootstrapping,
+	})
+	ns2 := dbAddNewMockNamespace(ctrl, d, "testns2")
+	ns2.EXPECT().BootstrapState().Return(ShardBootstrapStates{
+		3: Bootstrapped,
+	})
+
+	require.Equal(t, map[string]bool{
+		"testns1": false,
+		"testns2": true,
+	}, d.NamespacesBootstrapped())
+}
+
 func TestDatabaseFlushState(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1329,6 +1448,50 @@ func TestUpdateBatchWriterBasedOnShardResults(t *testing.T) {
 	require.NoError(t, d.Close())
 }
 
+func TestDatabaseWriteDeadlineExceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	d, mapCh, _ := defaultTestDatabase(t, ctrl, BootstrapNotStarted)
+	defer func() {
+		close(mapCh)
+	}()
+
+	// No writes are expected to the namespace since the deadline of the
+	// write has already been exceeded.
+	ns := dbAddNewMockNamespace(ctrl, d, "testns")
+	ns.EXPECT().GetOwnedShards().Return([]databaseShard{}).AnyTimes()
+	ns.EXPECT().Tick(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().BootstrapState().Return(ShardBootstrapStates{}).AnyTimes()
+	ns.EXPECT().Close().Return(nil).Times(1)
+	require.NoError(t, d.Open())
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+	goCtx, cancel := stdlibctx.WithTimeout(stdlibctx.Background(), 0)
+	defer cancel()
+	ctx.SetGoContext(goCtx)
+
+	namespace := ident.StringID("testns")
+	err := d.Write(ctx, namespace, ident.StringID("foo"), time.Now(), 1.0,
+		xtime.Second, nil)
+	require.True(t, xerrors.IsRetryableError(err))
+	require.Equal(t, errWriteTimeout, xerrors.GetInnerRetryableError(err))
+
+	err = d.WriteTagged(ctx, namespace, ident.StringID("foo"),
+		ident.EmptyTagIterator, time.Now(), 1.0, xtime.Second, nil)
+	require.True(t, xerrors.IsRetryableError(err))
+	require.Equal(t, errWriteTimeout, xerrors.GetInnerRetryableError(err))
+
+	batchWriter := ts.NewMockWriteBatch(ctrl)
+	batchWriter.EXPECT().Finalize()
+	err = d.WriteBatch(ctx, namespace, batchWriter, &fakeIndexedErrorHandler{})
+	require.True(t, xerrors.IsRetryableError(err))
+	require.Equal(t, errWriteTimeout, xerrors.GetInnerRetryableError(err))
+
+	require.NoError(t, d.Close())
+}
+
 func TestDatabaseIsOverloaded(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1351,3 +1514,64 @@ func TestDatabaseIsOverloaded(t *testing.T) {
 	mockCL.EXPECT().QueueLength().Return(int64(90))
 	require.Equal(t, true, d.IsOverloaded())
 }
+
+func TestCommitLogBackpressure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scope := tally.NewTestScope("", nil)
+	mockCL := commitlog.NewMockCommitLog(ctrl)
+	backpressure := newCommitLogBackpressure(mockCL, 100, scope)
+
+	mockCL.EXPECT().QueueLength().Return(int64(99))
+	require.False(t, backpressure.Applies())
+
+	mockCL.EXPECT().QueueLength().Return(int64(100))
+	require.True(t, backpressure.Applies())
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(1), counters["commitlog-backpressure.rejected+"].Value())
+}
+
+func TestDatabaseForceSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	d, mapCh, _ := defaultTestDatabase(t, ctrl, Bootstrapped)
+	defer func() {
+		close(mapCh)
+	}()
+
+	var (
+		nsOpts     = namespace.NewOptions()
+		blockSize  = nsOpts.RetentionOptions().BlockSize()
+		now        = time.Now()
+		blockStart = now.Truncate(blockSize)
+	)
+	d.nowFn = func() time.Time { return now }
+
+	snapshotPersist := persist.NewMockSnapshotPreparer(ctrl)
+	pm := persist.NewMockManager(ctrl)
+	pm.EXPECT().StartSnapshotPersist(gomock.Any()).Return(snapshotPersist, nil)
+	d.opts = d.opts.SetPersistManager(pm)
+
+	ns := dbAddNewMockNamespace(ctrl, d, "testns")
+	ns.EXPECT().Options().Return(nsOpts).AnyTimes()
+	ns.EXPECT().
+		Snapshot(blockStart, now, gomock.Any()).
+		Return(SnapshotResult{SeriesPersist: 3}, nil)
+	snapshotPersist.EXPECT().DonePartialSnapshot().Return(nil)
+
+	numSeries, err := d.ForceSnapshot(ident.StringID("testns"), blockStart)
+	require.NoError(t, err)
+	require.Equal(t, 3, numSeries)
+
+	// Block starts not aligned to the block size are rejected.
+	_, err = d.ForceSnapshot(ident.StringID("testns"), blockStart.Add(time.Second))
+	require.Error(t, err)
+	require.True(t, xerrors.IsInvalidParams(err))
+
+	// Unknown namespaces are rejected.
+	_, err = d.ForceSnapshot(ident.StringID("unknown"), blockStart)
+	require.Error(t, err)
+}
@@ -23,6 +23,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	xerrors "github.com/m3db/m3/src/x/errors"
 )
@@ -63,3 +64,146 @@ func IsUnknownNamespaceError(err error) bool {
 	_, ok := nsErr.(unknownNamespace)
 	return ok
 }
+
+// NewColdWritesDisabledError returns a new error indicating a write fell
+// outside of the buffer past/future window while cold writes are disabled.
+func NewColdWritesDisabledError(err error) error {
+	return xerrors.NewInvalidParamsError(coldWritesDisabled{err})
+}
+
+type coldWritesDisabled struct {
+	error
+}
+
+// IsColdWritesDisabledError returns true if this is a cold writes disabled error.
+func IsColdWritesDisabledError(err error) bool {
+	innerErr := xerrors.GetInnerInvalidParamsError(err)
+	if innerErr == nil {
+		return false
+	}
+	_, ok := innerErr.(coldWritesDisabled)
+	return ok
+}
+
+// NewQueryTooLargeError returns a new error indicating a read assembled more
+// block readers than the configured limit allows.
+func NewQueryTooLargeError(numReaders, limit int) error {
+	return xerrors.NewInvalidParamsError(queryTooLarge{
+		numReaders: numReaders,
+		limit:      limit,
+	})
+}
+
+type queryTooLarge struct {
+	numReaders int
+	limit      int
+}
+
+func (e queryTooLarge) Error() string {
+	return fmt.Sprintf("query too large: %d block readers exceeds limit of %d",
+		e.numReaders, e.limit)
+}
+
+// IsQueryTooLargeError returns true if this is a query too large error.
+func IsQueryTooLargeError(err error) bool {
+	innerErr := xerrors.GetInnerInvalidParamsError(err)
+	if innerErr == nil {
+		return false
+	}
+	_, ok := innerErr.(queryTooLarge)
+	return ok
+}
+
+// NewClockSkewError returns a new error indicating a write timestamp was
+// further ahead of the node's clock than the max future skew tolerated.
+func NewClockSkewError(skew, tolerance time.Duration) error {
+	return xerrors.NewInvalidParamsError(clockSkew{
+		skew:      skew,
+		tolerance: tolerance,
+	})
+}
+
+type clockSkew struct {
+	skew      time.Duration
+	tolerance time.Duration
+}
+
+func (e clockSkew) Error() string {
+	return fmt.Sprintf("datapoint timestamp is %s ahead of now, exceeds max future skew of %s",
+		e.skew.String(), e.tolerance.String())
+}
+
+// IsClockSkewError returns true if this is a clock skew error.
+func IsClockSkewError(err error) bool {
+	innerErr := xerrors.GetInnerInvalidParamsError(err)
+	if innerErr == nil {
+		return false
+	}
+	_, ok := innerErr.(clockSkew)
+	return ok
+}
+
+// NewValueOutOfBoundsError returns a new error indicating a written value
+// was outside of the namespace's configured value bounds.
+func NewValueOutOfBoundsError(value, min, max float64) error {
+	return xerrors.NewInvalidParamsError(valueOutOfBounds{
+		value: value,
+		min:   min,
+		max:   max,
+	})
+}
+
+type valueOutOfBounds struct {
+	value float64
+	min   float64
+	max   float64
+}
+
+func (e valueOutOfBounds) Error() string {
+	return fmt.Sprintf("datapoint value %v is outside of value bounds [%v, %v]",
+		e.value, e.min, e.max)
+}
+
+// IsValueOutOfBoundsError returns true if this is a value out of bounds error.
+func IsValueOutOfBoundsError(err error) bool {
+	innerErr := xerrors.GetInnerInvalidParamsError(err)
+	if innerErr == nil {
+		return false
+	}
+	_, ok := innerErr.(valueOutOfBounds)
+	return ok
+}
+
+// NewWriteOutsideRetentionError returns a new error indicating a write
+// timestamp was outside of the namespace's retention, i.e. before earliest
+// or not before latest.
+func NewWriteOutsideRetentionError(timestamp, earliest, latest time.Time) error {
+	return xerrors.NewInvalidParamsError(writeOutsideRetention{
+		timestamp: timestamp,
+		earliest:  earliest,
+		latest:    latest,
+	})
+}
+
+type writeOutsideRetention struct {
+	timestamp time.Time
+	earliest  time.Time
+	latest    time.Time
+}
+
+func (e writeOutsideRetention) Error() string {
+	return fmt.Sprintf("datapoint timestamp %s is outside of retention [%s, %s)",
+		e.timestamp.Format(time.RFC3339Nano), e.earliest.Format(time.RFC3339Nano),
+		e.latest.Format(time.RFC3339Nano))
+}
+
+// IsWriteOutsideRetentionError returns true if this is a write outside of
+// retention error.
+func IsWriteOutsideRetentionError(err error) bool {
+	innerErr := xerrors.GetInnerInvalidParamsError(err)
+	if innerErr == nil {
+		return false
+	}
+	_, ok := innerErr.(writeOutsideRetention)
+	return ok
+}
@@ -21,7 +21,9 @@
 package errors
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -31,3 +33,42 @@ func TestUnknownNamespaceError(t *testing.T) {
 	require.Equal(t, "unknown namespace: ns", err.Error())
 	require.True(t, IsUnknownNamespaceError(err))
 }
+
+func TestColdWritesDisabledError(t *testing.T) {
+	err := NewColdWritesDisabledError(errors.New("datapoint too far in past"))
+	require.Equal(t, "datapoint too far in past", err.Error())
+	require.True(t, IsColdWritesDisabledError(err))
+	require.False(t, IsColdWritesDisabledError(ErrTooPast))
+}
+
+func TestQueryTooLargeError(t *testing.T) {
+	err := NewQueryTooLargeError(10, 5)
+	require.Equal(t, "query too large: 10 block readers exceeds limit of 5", err.Error())
+	require.True(t, IsQueryTooLargeError(err))
+	require.False(t, IsUnknownNamespaceError(err))
+	require.False(t, IsQueryTooLargeError(NewUnknownNamespaceError("ns")))
+}
+
+func TestClockSkewError(t *testing.T) {
+	err := NewClockSkewError(time.Hour, time.Minute)
+	require.Equal(t, "datapoint timestamp is 1h0m0s ahead of now, exceeds max future skew of 1m0s", err.Error())
+	require.True(t, IsClockSkewError(err))
+	require.False(t, IsClockSkewError(ErrTooFuture))
+}
+
+func TestValueOutOfBoundsError(t *testing.T) {
+	err := NewValueOutOfBoundsError(-1, 0, 100)
+	require.Equal(t, "datapoint value -1 is outside of value bounds [0, 100]", err.Error())
+	require.True(t, IsValueOutOfBoundsError(err))
+	require.False(t, IsValueOutOfBoundsError(NewClockSkewError(time.Hour, time.Minute)))
+}
+
+func TestWriteOutsideRetentionError(t *testing.T) {
+	earliest := time.Unix(0, 0).UTC()
+	latest := earliest.Add(time.Hour)
+	err := NewWriteOutsideRetentionError(latest, earliest, latest)
+	require.Equal(t, "datapoint timestamp 1970-01-01T01:00:00Z is outside of "+
+		"retention [1970-01-01T00:00:00Z, 1970-01-01T01:00:00Z)", err.Error())
+	require.True(t, IsWriteOutsideRetentionError(err))
+	require.False(t, IsWriteOutsideRetentionError(ErrTooPast))
+}
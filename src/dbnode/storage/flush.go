@@ -24,9 +24,11 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/persist"
+	"github.com/m3db/m3/src/dbnode/persist/fs"
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
 	"github.com/m3db/m3/src/dbnode/retention"
 	xerrors "github.com/m3db/m3/src/x/errors"
@@ -59,6 +61,11 @@ type flushManager struct {
 	commitlog commitlog.CommitLog
 	opts      Options
 	pm        persist.Manager
+	// snapshotPMs are the additional persist managers used to snapshot
+	// shards concurrently, they are created as the max concurrent snapshots
+	// runtime option requires them.
+	snapshotPMs         []persist.Manager
+	newPersistManagerFn func() (persist.Manager, error)
 	// state is used to protect the flush manager against concurrent use,
 	// while flushInProgress and snapshotInProgress are more granular and
 	// are used for emitting granular gauges.
@@ -67,6 +74,8 @@ type flushManager struct {
 	isColdFlushing  tally.Gauge
 	isSnapshotting  tally.Gauge
 	isIndexFlushing tally.Gauge
+	// snapshotsInProgress is the number of shards being snapshotted.
+	snapshotsInProgress tally.Gauge
 	// This is a "debug" metric for making sure that the snapshotting process
 	// is not overly aggressive.
 	maxBlocksSnapshottedByNamespace tally.Gauge
@@ -82,14 +91,22 @@ func newFlushManager(
 		commitlog:                       commitlog,
 		opts:                            opts,
 		pm:                              opts.PersistManager(),
+		newPersistManagerFn:             newSnapshotPersistManagerFn(opts),
 		isFlushing:                      scope.Gauge("flush"),
 		isColdFlushing:                  scope.Gauge("cold-flush"),
 		isSnapshotting:                  scope.Gauge("snapshot"),
 		isIndexFlushing:                 scope.Gauge("index-flush"),
+		snapshotsInProgress:             scope.Gauge("snapshots-in-progress"),
 		maxBlocksSnapshottedByNamespace: scope.Gauge("max-blocks-snapshotted-by-namespace"),
 	}
 }
 
+func newSnapshotPersistManagerFn(opts Options) func() (persist.Manager, error) {
+	return func() (persist.Manager, error) {
+		return fs.NewPersistManager(opts.CommitLogOptions().FilesystemOptions())
+	}
+}
+
 func (m *flushManager) Flush(
 	tickStart time.Time,
 	dbBootstrapStateAtTickStart DatabaseBootstrapState,
@@ -228,7 +245,7 @@ func (m *flushManager) dataSnapshot(
 ) error {
 	snapshotID := uuid.NewUUID()
 
-	snapshotPersist, err := m.pm.StartSnapshotPersist(snapshotID)
+	snapshotPersist, err := m.startSnapshotPersist(snapshotID)
 	if err != nil {
 		return err
 	}
@@ -252,7 +269,7 @@ func (m *flushManager) dataSnapshot(
 			maxBlocksSnapshottedByNamespace = len(snapshotBlockStarts)
 		}
 		for _, snapshotBlockStart := range snapshotBlockStarts {
-			err := ns.Snapshot(
+			_, err := ns.Snapshot(
 				snapshotBlockStart, tickStart, snapshotPersist)
 
 			if err != nil {
@@ -276,6 +293,117 @@ func (m *flushManager) dataSnapshot(
 	return finalErr
 }
 
+// startSnapshotPersist starts a snapshot with one persist manager for each
+// shard that may be snapshotted concurrently, as set by the max concurrent
+// snapshots runtime option.
+func (m *flushManager) startSnapshotPersist(
+	snapshotID uuid.UUID,
+) (persist.SnapshotPreparer, error) {
+	concurrency := m.opts.RuntimeOptionsManager().Get().MaxConcurrentSnapshots()
+	for len(m.snapshotPMs) < concurrency-1 {
+		pm, err := m.newPersistManagerFn()
+		if err != nil {
+			return nil, err
+		}
+		m.snapshotPMs = append(m.snapshotPMs, pm)
+	}
+
+	preparers := make([]persist.SnapshotPreparer, 0, concurrency)
+	for i := 0; i < concurrency; i++ {
+		pm := m.pm
+		if i > 0 {
+			pm = m.snapshotPMs[i-1]
+		}
+		preparer, err := pm.StartSnapshotPersist(snapshotID)
+		if err != nil {
+			// Release the persist managers that were already started.
+			multiErr := xerrors.NewMultiError().Add(err)
+			for _, started := range preparers {
+				multiErr = multiErr.Add(started.DonePartialSnapshot())
+			}
+			return nil, multiErr.FinalError()
+		}
+		preparers = append(preparers, preparer)
+	}
+
+	return newSnapshotPreparerPool(preparers, m.snapshotsInProgress), nil
+}
+
+// snapshotPreparerPool is a snapshot preparer that hands out a fixed set of
+// snapshot preparers to the shards being snapshotted. Each preparer writes
+// a single fileset at a time so PrepareData blocks until one is free, which
+// bounds the number of shards snapshotted concurrently to the pool size.
+type snapshotPreparerPool struct {
+	preparers     []persist.SnapshotPreparer
+	free          chan persist.SnapshotPreparer
+	numInProgress int64
+	inProgress    tally.Gauge
+}
+
+func newSnapshotPreparerPool(
+	preparers []persist.SnapshotPreparer,
+	inProgress tally.Gauge,
+) *snapshotPreparerPool {
+	free := make(chan persist.SnapshotPreparer, len(preparers))
+	for _, preparer := range preparers {
+		free <- preparer
+	}
+	return &snapshotPreparerPool{
+		preparers:  preparers,
+		free:       free,
+		inProgress: inProgress,
+	}
+}
+
+func (p *snapshotPreparerPool) PrepareData(
+	opts persist.DataPrepareOptions,
+) (persist.PreparedDataPersist, error) {
+	preparer := <-p.free
+	prepared, err := preparer.PrepareData(opts)
+	if err != nil {
+		p.free <- preparer
+		return prepared, err
+	}
+
+	p.inProgress.Update(float64(atomic.AddInt64(&p.numInProgress, 1)))
+	closeFn := prepared.Close
+	prepared.Close = func() error {
+		err := closeFn()
+		p.inProgress.Update(float64(atomic.AddInt64(&p.numInProgress, -1)))
+		p.free <- preparer
+		return err
+	}
+	return prepared, nil
+}
+
+// DoneSnapshot completes the snapshot of every preparer, only the first
+// preparer writes the snapshot metadata and only once the filesets of all
+// the others are durable.
+func (p *snapshotPreparerPool) DoneSnapshot(
+	snapshotUUID uuid.UUID,
+	commitLogIdentifier persist.CommitLogFile,
+) error {
+	multiErr := xerrors.NewMultiError()
+	for _, preparer := range p.preparers[1:] {
+		multiErr = multiErr.Add(preparer.DonePartialSnapshot())
+	}
+	if !multiErr.Empty() {
+		// The snapshot is incomplete so it must not be relied upon to
+		// clean up commit logs.
+		multiErr = multiErr.Add(p.preparers[0].DonePartialSnapshot())
+		return multiErr.FinalError()
+	}
+	return p.preparers[0].DoneSnapshot(snapshotUUID, commitLogIdentifier)
+}
+
+func (p *snapshotPreparerPool) DonePartialSnapshot() error {
+	multiErr := xerrors.NewMultiError()
+	for _, preparer := range p.preparers {
+		multiErr = multiErr.Add(preparer.DonePartialSnapshot())
+	}
+	return multiErr.FinalError()
+}
+
 func (m *flushManager) indexFlush(
 	namespaces []databaseNamespace,
 ) error {
@@ -31,10 +31,12 @@ import (
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs/commitlog"
 	"github.com/m3db/m3/src/dbnode/retention"
+	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/x/ident"
 	xtest "github.com/m3db/m3/src/x/test"
 
 	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/uber-go/tally"
 )
@@ -283,7 +285,7 @@ func TestFlushManagerSkipNamespaceIndexingDisabled(t *testing.T) {
 	ns.EXPECT().NeedsFlush(gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
 	ns.EXPECT().WarmFlush(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	ns.EXPECT().ColdFlush(gomock.Any()).Return(nil).AnyTimes()
-	ns.EXPECT().Snapshot(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().Snapshot(gomock.Any(), gomock.Any(), gomock.Any()).Return(SnapshotResult{}, nil).AnyTimes()
 
 	var (
 		mockFlushPersist    = persist.NewMockFlushPreparer(ctrl)
@@ -332,7 +334,7 @@ func TestFlushManagerNamespaceIndexingEnabled(t *testing.T) {
 	ns.EXPECT().NeedsFlush(gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
 	ns.EXPECT().WarmFlush(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	ns.EXPECT().ColdFlush(gomock.Any()).Return(nil).AnyTimes()
-	ns.EXPECT().Snapshot(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	ns.EXPECT().Snapshot(gomock.Any(), gomock.Any(), gomock.Any()).Return(SnapshotResult{}, nil).AnyTimes()
 	ns.EXPECT().FlushIndex(gomock.Any()).Return(nil)
 
 	var (
@@ -568,3 +570,89 @@ type timesInOrder []time.Time
 func (a timesInOrder) Len() int           { return len(a) }
 func (a timesInOrder) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a timesInOrder) Less(i, j int) bool { return a[i].Before(a[j]) }
+
+func TestFlushManagerStartSnapshotPersistConcurrency(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{T: t})
+	defer ctrl.Finish()
+
+	var (
+		snapshotID        = uuid.NewUUID()
+		mockPersistMgr    = persist.NewMockManager(ctrl)
+		mockSnapshotPMgr  = persist.NewMockManager(ctrl)
+		mockPersist       = persist.NewMockSnapshotPreparer(ctrl)
+		mockExtraPersist  = persist.NewMockSnapshotPreparer(ctrl)
+		runtimeOptsMgr    = runtime.NewOptionsManager()
+		newPersistManager int
+	)
+	require.NoError(t, runtimeOptsMgr.Update(
+		runtime.NewOptions().SetMaxConcurrentSnapshots(2)))
+
+	testOpts := DefaultTestOptions().
+		SetPersistManager(mockPersistMgr).
+		SetRuntimeOptionsManager(runtimeOptsMgr)
+	db := newMockdatabase(ctrl)
+	db.EXPECT().Options().Return(testOpts).AnyTimes()
+
+	fm := newFlushManager(db, commitlog.NewMockCommitLog(ctrl), tally.NoopScope).(*flushManager)
+	fm.newPersistManagerFn = func() (persist.Manager, error) {
+		newPersistManager++
+		return mockSnapshotPMgr, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		mockPersistMgr.EXPECT().StartSnapshotPersist(snapshotID).Return(mockPersist, nil)
+		mockSnapshotPMgr.EXPECT().StartSnapshotPersist(snapshotID).Return(mockExtraPersist, nil)
+
+		// The snapshot metadata is only written once the additional
+		// snapshot filesets are durable.
+		gomock.InOrder(
+			mockExtraPersist.EXPECT().DonePartialSnapshot().Return(nil),
+			mockPersist.EXPECT().DoneSnapshot(snapshotID, testCommitlogFile).Return(nil),
+		)
+
+		snapshotPersist, err := fm.startSnapshotPersist(snapshotID)
+		require.NoError(t, err)
+		require.NoError(t, snapshotPersist.DoneSnapshot(snapshotID, testCommitlogFile))
+	}
+
+	// The additional persist managers are reused across snapshots.
+	require.Equal(t, 1, newPersistManager)
+}
+
+func TestSnapshotPreparerPoolBoundsConcurrency(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{T: t})
+	defer ctrl.Finish()
+
+	var preparers []persist.SnapshotPreparer
+	for i := 0; i < 2; i++ {
+		preparer := persist.NewMockSnapshotPreparer(ctrl)
+		preparer.EXPECT().PrepareData(gomock.Any()).Return(persist.PreparedDataPersist{
+			Close: func() error { return nil },
+		}, nil).AnyTimes()
+		preparers = append(preparers, preparer)
+	}
+
+	scope := tally.NewTestScope("", nil)
+	pool := newSnapshotPreparerPool(preparers, scope.Gauge("snapshots-in-progress"))
+
+	first, err := pool.PrepareData(persist.DataPrepareOptions{})
+	require.NoError(t, err)
+	_, err = pool.PrepareData(persist.DataPrepareOptions{})
+	require.NoError(t, err)
+	require.Equal(t, float64(2), scope.Snapshot().Gauges()["snapshots-in-progress+"].Value())
+
+	// A third shard can only be prepared once one of the others completes.
+	preparedCh := make(chan error, 1)
+	go func() {
+		_, err := pool.PrepareData(persist.DataPrepareOptions{})
+		preparedCh <- err
+	}()
+	select {
+	case <-preparedCh:
+		require.FailNow(t, "prepared more shards than the pool size")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, first.Close())
+	require.NoError(t, <-preparedCh)
+}
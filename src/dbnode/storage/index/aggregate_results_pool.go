@@ -23,25 +23,35 @@ package index
 import "github.com/m3db/m3/src/x/pool"
 
 type aggregateResultsPool struct {
-	pool pool.ObjectPool
+	pool    pool.ObjectPool
+	metrics resultsPoolMetrics
 }
 
 // NewAggregateResultsPool creates a new AggregateResultsPool.
 func NewAggregateResultsPool(
 	opts pool.ObjectPoolOptions) AggregateResultsPool {
-	return &aggregateResultsPool{pool: pool.NewObjectPool(opts)}
+	if opts == nil {
+		opts = pool.NewObjectPoolOptions()
+	}
+	return &aggregateResultsPool{
+		pool:    pool.NewObjectPool(opts),
+		metrics: newResultsPoolMetrics(opts),
+	}
 }
 
 func (p *aggregateResultsPool) Init(alloc AggregateResultsAllocator) {
 	p.pool.Init(func() interface{} {
+		p.metrics.alloc.Inc(1)
 		return alloc()
 	})
 }
 
 func (p *aggregateResultsPool) Get() AggregateResults {
+	p.metrics.get.Inc(1)
 	return p.pool.Get().(AggregateResults)
 }
 
 func (p *aggregateResultsPool) Put(value AggregateResults) {
+	p.metrics.put.Inc(1)
 	p.pool.Put(value)
 }
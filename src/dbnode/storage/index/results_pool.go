@@ -20,27 +20,58 @@
 
 package index
 
-import "github.com/m3db/m3/src/x/pool"
+import (
+	"github.com/m3db/m3/src/x/pool"
+
+	"github.com/uber-go/tally"
+)
+
+type resultsPoolMetrics struct {
+	get   tally.Counter
+	put   tally.Counter
+	alloc tally.Counter
+}
+
+func newResultsPoolMetrics(opts pool.ObjectPoolOptions) resultsPoolMetrics {
+	scope := opts.InstrumentOptions().MetricsScope()
+	return resultsPoolMetrics{
+		get:   scope.Counter("get"),
+		put:   scope.Counter("put"),
+		alloc: scope.Counter("alloc"),
+	}
+}
 
 type resultsPool struct {
-	pool pool.ObjectPool
+	pool    pool.ObjectPool
+	metrics resultsPoolMetrics
 }
 
 // NewQueryResultsPool creates a new QueryResultsPool.
 func NewQueryResultsPool(opts pool.ObjectPoolOptions) QueryResultsPool {
-	return &resultsPool{pool: pool.NewObjectPool(opts)}
+	if opts == nil {
+		opts = pool.NewObjectPoolOptions()
+	}
+	return &resultsPool{
+		pool:    pool.NewObjectPool(opts),
+		metrics: newResultsPoolMetrics(opts),
+	}
 }
 
 func (p *resultsPool) Init(alloc QueryResultsAllocator) {
 	p.pool.Init(func() interface{} {
+		// Allocations after the initial fill happen when the pool is empty,
+		// tracking them alongside get-on-empty helps size the pool.
+		p.metrics.alloc.Inc(1)
 		return alloc()
 	})
 }
 
 func (p *resultsPool) Get() QueryResults {
+	p.metrics.get.Inc(1)
 	return p.pool.Get().(QueryResults)
 }
 
 func (p *resultsPool) Put(value QueryResults) {
+	p.metrics.put.Inc(1)
 	p.pool.Put(value)
 }
@@ -44,24 +44,31 @@ const (
 )
 
 var (
-	errMediatorAlreadyOpen   = errors.New("mediator is already open")
-	errMediatorNotOpen       = errors.New("mediator is not open")
-	errMediatorAlreadyClosed = errors.New("mediator is already closed")
+	errMediatorAlreadyOpen     = errors.New("mediator is already open")
+	errMediatorNotOpen         = errors.New("mediator is not open")
+	errMediatorAlreadyClosed   = errors.New("mediator is already closed")
+	errMediatorAlreadyQuiesced = errors.New("mediator is already quiesced")
+	errMediatorNotQuiesced     = errors.New("mediator is not quiesced")
+	errMediatorQuiesced        = errors.New("mediator is quiesced")
 )
 
 type mediatorMetrics struct {
-	bootstrapStatus tally.Gauge
-	cleanupStatus   tally.Gauge
-	flushStatus     tally.Gauge
-	repairStatus    tally.Gauge
+	bootstrapStatus  tally.Gauge
+	cleanupStatus    tally.Gauge
+	flushStatus      tally.Gauge
+	repairStatus     tally.Gauge
+	quiesced         tally.Gauge
+	quiescedDuration tally.Gauge
 }
 
 func newMediatorMetrics(scope tally.Scope) mediatorMetrics {
 	return mediatorMetrics{
-		bootstrapStatus: scope.Gauge("bootstrapped"),
-		cleanupStatus:   scope.Gauge("cleanup"),
-		flushStatus:     scope.Gauge("flush"),
-		repairStatus:    scope.Gauge("repair"),
+		bootstrapStatus:  scope.Gauge("bootstrapped"),
+		cleanupStatus:    scope.Gauge("cleanup"),
+		flushStatus:      scope.Gauge("flush"),
+		repairStatus:     scope.Gauge("repair"),
+		quiesced:         scope.Gauge("quiesced"),
+		quiescedDuration: scope.Gauge("quiesced-duration"),
 	}
 }
 
@@ -79,6 +86,12 @@ type mediator struct {
 	metrics  mediatorMetrics
 	state    mediatorState
 	closedCh chan struct{}
+
+	quiesced   bool
+	quiescedAt time.Time
+	// fileOpsDisabled is the number of DisableFileOps calls that are yet to
+	// be followed by a call to EnableFileOps.
+	fileOpsDisabled int
 }
 
 func newMediator(database database, commitlog commitlog.CommitLog, opts Options) (databaseMediator, error) {
@@ -124,15 +137,86 @@ func (m *mediator) Open() error {
 }
 
 func (m *mediator) DisableFileOps() {
+	m.Lock()
+	m.fileOpsDisabled++
 	status := m.databaseFileSystemManager.Disable()
+	m.Unlock()
+
+	m.waitForFileOps(status)
+}
+
+func (m *mediator) EnableFileOps() {
+	m.Lock()
+	if m.fileOpsDisabled > 0 {
+		m.fileOpsDisabled--
+	}
+	m.enableFileOpsWithLock()
+	m.Unlock()
+}
+
+// enableFileOpsWithLock enables file operations unless they are still
+// disabled by being quiesced or by another caller of DisableFileOps.
+func (m *mediator) enableFileOpsWithLock() {
+	if m.quiesced || m.fileOpsDisabled > 0 {
+		return
+	}
+	m.databaseFileSystemManager.Enable()
+}
+
+func (m *mediator) waitForFileOps(status fileOpStatus) {
 	for status == fileOpInProgress {
 		m.sleepFn(fileOpCheckInterval)
 		status = m.databaseFileSystemManager.Status()
 	}
 }
 
-func (m *mediator) EnableFileOps() {
-	m.databaseFileSystemManager.Enable()
+func (m *mediator) Quiesce() error {
+	m.Lock()
+	if m.state != mediatorOpen {
+		m.Unlock()
+		return errMediatorNotOpen
+	}
+	if m.quiesced {
+		m.Unlock()
+		return errMediatorAlreadyQuiesced
+	}
+	m.quiesced = true
+	m.quiescedAt = m.nowFn()
+	status := m.databaseFileSystemManager.Disable()
+	m.Unlock()
+
+	m.opts.InstrumentOptions().Logger().Info("quiescing background processes")
+
+	// Wait for any in-progress file operations to complete so that callers
+	// can safely begin maintenance once this returns.
+	m.waitForFileOps(status)
+	return nil
+}
+
+func (m *mediator) Unquiesce() error {
+	m.Lock()
+	if !m.quiesced {
+		m.Unlock()
+		return errMediatorNotQuiesced
+	}
+	quiescedFor := m.nowFn().Sub(m.quiescedAt)
+	m.quiesced = false
+	m.quiescedAt = time.Time{}
+	// NB: File operations disabled by a bootstrap remain disabled until the
+	// bootstrap re-enables them.
+	m.enableFileOpsWithLock()
+	m.Unlock()
+
+	m.opts.InstrumentOptions().Logger().Info("unquiescing background processes",
+		zap.Duration("quiescedFor", quiescedFor))
+	return nil
+}
+
+func (m *mediator) IsQuiesced() bool {
+	m.RLock()
+	quiesced := m.quiesced
+	m.RUnlock()
+	return quiesced
 }
 
 // Tick mediates the relationship between ticks and flushes/snapshots/cleanups.
@@ -156,6 +240,10 @@ func (m *mediator) EnableFileOps() {
 // will think that the old block hasn't been flushed (even thought it has) and try to flush it even though the data
 // is potentially still on disk (if it hasn't been cleaned up yet).
 func (m *mediator) Tick(runType runType, forceType forceType) error {
+	if m.IsQuiesced() {
+		return errMediatorQuiesced
+	}
+
 	tickStart := m.nowFn()
 	dbBootstrapStateAtTickStart := m.database.BootstrapState()
 
@@ -175,6 +263,17 @@ func (m *mediator) Report() {
 	m.databaseBootstrapManager.Report()
 	m.databaseRepairer.Report()
 	m.databaseFileSystemManager.Report()
+
+	m.RLock()
+	quiesced, quiescedAt := m.quiesced, m.quiescedAt
+	m.RUnlock()
+	if quiesced {
+		m.metrics.quiesced.Update(1)
+		m.metrics.quiescedDuration.Update(m.nowFn().Sub(quiescedAt).Seconds())
+	} else {
+		m.metrics.quiesced.Update(0)
+		m.metrics.quiescedDuration.Update(0)
+	}
 }
 
 func (m *mediator) Close() error {
@@ -189,6 +288,7 @@ func (m *mediator) Close() error {
 	m.state = mediatorClosed
 	close(m.closedCh)
 	m.databaseRepairer.Stop()
+	m.databaseBootstrapManager.Close()
 	return nil
 }
 
@@ -202,7 +302,7 @@ func (m *mediator) ongoingTick() {
 			// is in progress, throttle a little to avoid constantly
 			// checking whether the ongoing tick is finished
 			err := m.Tick(asyncRun, noForce)
-			if err == errTickInProgress {
+			if err == errTickInProgress || err == errMediatorQuiesced {
 				m.sleepFn(tickCheckInterval)
 			} else if err != nil {
 				log := m.opts.InstrumentOptions().Logger()
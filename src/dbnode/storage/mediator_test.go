@@ -89,3 +89,76 @@ func TestDatabaseMediatorDisableFileOps(t *testing.T) {
 	m.DisableFileOps()
 	require.Equal(t, 3, len(slept))
 }
+
+func TestDatabaseMediatorQuiesceUnquiesce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := DefaultTestOptions().SetRepairEnabled(false)
+	now := time.Now()
+	opts = opts.
+		SetBootstrapProcessProvider(nil).
+		SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+			return now
+		}))
+
+	db := NewMockdatabase(ctrl)
+	db.EXPECT().Options().Return(opts).AnyTimes()
+	med, err := newMediator(db, nil, opts)
+	require.NoError(t, err)
+
+	m := med.(*mediator)
+	fsm := NewMockdatabaseFileSystemManager(ctrl)
+	m.databaseFileSystemManager = fsm
+	m.state = mediatorOpen
+
+	require.Equal(t, errMediatorNotQuiesced, m.Unquiesce())
+
+	fsm.EXPECT().Disable().Return(fileOpNotStarted)
+	require.NoError(t, m.Quiesce())
+	require.True(t, m.IsQuiesced())
+	require.Equal(t, errMediatorAlreadyQuiesced, m.Quiesce())
+
+	// Ticks and re-enabling file ops are skipped while quiesced.
+	require.Equal(t, errMediatorQuiesced, m.Tick(asyncRun, noForce))
+	m.EnableFileOps()
+
+	fsm.EXPECT().Enable().Return(fileOpNotStarted)
+	require.NoError(t, m.Unquiesce())
+	require.False(t, m.IsQuiesced())
+}
+
+func TestDatabaseMediatorUnquiesceWhileFileOpsDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := DefaultTestOptions().SetRepairEnabled(false)
+	now := time.Now()
+	opts = opts.
+		SetBootstrapProcessProvider(nil).
+		SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+			return now
+		}))
+
+	db := NewMockdatabase(ctrl)
+	db.EXPECT().Options().Return(opts).AnyTimes()
+	med, err := newMediator(db, nil, opts)
+	require.NoError(t, err)
+
+	m := med.(*mediator)
+	fsm := NewMockdatabaseFileSystemManager(ctrl)
+	m.databaseFileSystemManager = fsm
+	m.state = mediatorOpen
+
+	// File ops are disabled by a bootstrap and then by quiescing.
+	fsm.EXPECT().Disable().Return(fileOpNotStarted).Times(2)
+	m.DisableFileOps()
+	require.NoError(t, m.Quiesce())
+
+	// Unquiescing leaves the file ops disabled by the bootstrap disabled.
+	require.NoError(t, m.Unquiesce())
+
+	// The file ops are enabled once the bootstrap completes.
+	fsm.EXPECT().Enable().Return(fileOpNotStarted)
+	m.EnableFileOps()
+}
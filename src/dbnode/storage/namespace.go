@@ -32,6 +32,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/persist/fs"
+	m3dbruntime "github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/sharding"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
@@ -53,6 +54,14 @@ import (
 	opentracinglog "github.com/opentracing/opentracing-go/log"
 	"github.com/uber-go/tally"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// debugLoggingDisabledLevel is the namespace log level override used when
+	// the namespace is not configured for debug logging, it enables no levels
+	// so the process wide log level applies.
+	debugLoggingDisabledLevel = zapcore.FatalLevel + 1
 )
 
 var (
@@ -111,9 +120,11 @@ type dbNamespace struct {
 	metadata           namespace.Metadata
 	nopts              namespace.Options
 	seriesOpts         series.Options
+	writeTransformOpts series.WriteTransformOptions
 	nowFn              clock.NowFn
 	snapshotFilesFn    snapshotFilesFn
 	log                *zap.Logger
+	logLevel           zap.AtomicLevel
 	bootstrapState     BootstrapState
 
 	// schemaDescr caches the latest schema for the namespace.
@@ -121,6 +132,10 @@ type dbNamespace struct {
 	schemaListener xclose.SimpleCloser
 	schemaDescr    namespace.SchemaDescr
 
+	// runtimeOptsListener toggles debug logging for the namespace whenever
+	// the set of debug logging namespaces in the runtime options changes.
+	runtimeOptsListener xclose.SimpleCloser
+
 	// Contains an entry to all shards for fast shard lookup, an
 	// entry will be nil when this shard does not belong to current database
 	shards []databaseShard
@@ -129,6 +144,9 @@ type dbNamespace struct {
 	commitLogWriter commitLogWriter
 	reverseIndex    namespaceIndex
 
+	// readCache is nil unless read result caching is enabled for the namespace.
+	readCache *readCache
+
 	tickWorkers            xsync.WorkerPool
 	tickWorkersConcurrency int
 	statsLastTick          databaseNamespaceStatsLastTick
@@ -189,6 +207,7 @@ type databaseNamespaceTickMetrics struct {
 	errors                 tally.Counter
 	index                  databaseNamespaceIndexTickMetrics
 	evictedBuckets         tally.Counter
+	estimatedMemoryBytes   tally.Gauge
 }
 
 type databaseNamespaceIndexTickMetrics struct {
@@ -206,6 +225,7 @@ type databaseNamespaceIndexTickMetrics struct {
 type databaseNamespaceStatusMetrics struct {
 	activeSeries tally.Gauge
 	activeBlocks tally.Gauge
+	dirtyShards  tally.Gauge
 	index        databaseNamespaceIndexStatusMetrics
 }
 
@@ -267,11 +287,13 @@ func newDatabaseNamespaceMetrics(scope tally.Scope, samplingRate float64) databa
 				numBlocksSealed:  indexTickScope.Counter("num-blocks-sealed"),
 				numBlocksEvicted: indexTickScope.Counter("num-blocks-evicted"),
 			},
-			evictedBuckets: tickScope.Counter("evicted-buckets"),
+			evictedBuckets:       tickScope.Counter("evicted-buckets"),
+			estimatedMemoryBytes: tickScope.Gauge("estimated-memory-bytes"),
 		},
 		status: databaseNamespaceStatusMetrics{
 			activeSeries: statusScope.Gauge("active-series"),
 			activeBlocks: statusScope.Gauge("active-blocks"),
+			dirtyShards:  statusScope.Gauge("dirty-shards"),
 			index: databaseNamespaceIndexStatusMetrics{
 				numDocs:     indexStatusScope.Gauge("num-docs"),
 				numBlocks:   indexStatusScope.Gauge("num-blocks"),
@@ -297,8 +319,13 @@ func newDatabaseNamespace(
 		commitLogWriter = commitLogWriteNoOp
 	}
 
+	// The namespace scoped logger is threaded through to the shards, series
+	// and index via the instrument options so that debug logging can be
+	// enabled for this namespace alone at runtime.
 	iops := opts.InstrumentOptions()
-	logger := iops.Logger().With(zap.String("namespace", id.String()))
+	logLevel := zap.NewAtomicLevelAt(debugLoggingDisabledLevel)
+	logger := instrument.NewLevelOverrideLogger(
+		iops.Logger().With(zap.String("namespace", id.String())), logLevel)
 	iops = iops.SetLogger(logger)
 	opts = opts.SetInstrumentOptions(iops)
 
@@ -311,19 +338,40 @@ func newDatabaseNamespace(
 	tickWorkers := xsync.NewWorkerPool(tickWorkersConcurrency)
 	tickWorkers.Init()
 
+	seriesCachePolicy, err := namespaceSeriesCachePolicy(opts, nopts, blockRetriever)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"unable to create namespace %v, invalid series cache policy: %v",
+			metadata.ID().String(), err)
+	}
+
 	seriesOpts := NewSeriesOptionsFromOptions(opts, nopts.RetentionOptions()).
-		SetStats(series.NewStats(scope)).
-		SetColdWritesEnabled(nopts.ColdWritesEnabled())
+		SetStats(series.NewStats(scope).
+			SetWriteStageTimingsEnabled(iops.DebugMetricsEnabled())).
+		SetColdWritesEnabled(nopts.ColdWritesEnabled()).
+		SetMaxWriteFutureSkew(nopts.MaxWriteFutureSkew()).
+		SetCompressionLevel(nopts.CompressionLevel()).
+		SetCachePolicy(seriesCachePolicy)
 	if err := seriesOpts.Validate(); err != nil {
 		return nil, fmt.Errorf(
 			"unable to create namespace %v, invalid series options: %v",
 			metadata.ID().String(), err)
 	}
 
-	var (
-		index namespaceIndex
-		err   error
-	)
+	writeTransformOpts := opts.WriteTransformOptions()
+	if valueBoundsOpts := nopts.ValueBoundsOptions(); valueBoundsOpts.Enabled() {
+		writeTransformOpts.ValueBoundsEnabled = true
+		writeTransformOpts.MinValue = valueBoundsOpts.Min()
+		writeTransformOpts.MaxValue = valueBoundsOpts.Max()
+		writeTransformOpts.ClampValues = valueBoundsOpts.Clamp()
+	}
+	// A namespace forced value takes precedence over the database wide one.
+	if forcedValueOpts := nopts.ForcedValueOptions(); forcedValueOpts.Enabled() {
+		writeTransformOpts.ForceValueEnabled = true
+		writeTransformOpts.ForceValue = forcedValueOpts.Value()
+	}
+
+	var index namespaceIndex
 	if metadata.Options().IndexOptions().Enabled() {
 		index, err = newNamespaceIndex(metadata, shardSet, opts)
 		if err != nil {
@@ -341,9 +389,11 @@ func newDatabaseNamespace(
 		metadata:               metadata,
 		nopts:                  nopts,
 		seriesOpts:             seriesOpts,
+		writeTransformOpts:     writeTransformOpts,
 		nowFn:                  opts.ClockOptions().NowFn(),
 		snapshotFilesFn:        fs.SnapshotFiles,
 		log:                    logger,
+		logLevel:               logLevel,
 		increasingIndex:        increasingIndex,
 		commitLogWriter:        commitLogWriter,
 		reverseIndex:           index,
@@ -361,12 +411,62 @@ func newDatabaseNamespace(
 			metadata.ID().String(), err)
 	}
 	n.schemaListener = sl
+	if readCacheOpts := nopts.ReadCacheOptions(); readCacheOpts.Enabled() {
+		n.readCache = newReadCache(readCacheOpts, n.nowFn, scope)
+	}
+	n.runtimeOptsListener = opts.RuntimeOptionsManager().RegisterListener(n)
 	n.initShards(nopts.BootstrapEnabled())
 	go n.reportStatusLoop(opts.InstrumentOptions().ReportInterval())
 
 	return n, nil
 }
 
+// namespaceSeriesCachePolicy returns the series cache policy of a namespace,
+// the database wide policy is used unless the namespace overrides it.
+func namespaceSeriesCachePolicy(
+	opts Options,
+	nopts namespace.Options,
+	blockRetriever block.DatabaseBlockRetriever,
+) (series.CachePolicy, error) {
+	if nopts.SeriesCachePolicy() == "" {
+		return opts.SeriesCachePolicy(), nil
+	}
+
+	policy, err := series.ParseCachePolicy(nopts.SeriesCachePolicy())
+	if err != nil {
+		return 0, err
+	}
+	// Every policy other than caching all series relies on retrieving blocks
+	// from disk on a cache miss.
+	if policy != series.CacheAll && blockRetriever == nil {
+		return 0, fmt.Errorf("series cache policy %v requires a block retriever", policy)
+	}
+	// Blocks retrieved from disk with the LRU policy are owned and closed by
+	// the wired list, which only exists when it is the database wide policy.
+	if policy == series.CacheLRU && opts.DatabaseBlockOptions().WiredList() == nil {
+		return 0, fmt.Errorf("series cache policy %v requires a wired list", policy)
+	}
+	return policy, nil
+}
+
+// SetRuntimeOptions implements runtime.OptionsListener.
+func (n *dbNamespace) SetRuntimeOptions(value m3dbruntime.Options) {
+	level := debugLoggingDisabledLevel
+	for _, id := range value.DebugLoggingNamespaces() {
+		if id == n.id.String() {
+			level = zapcore.DebugLevel
+			break
+		}
+	}
+	if n.logLevel.Level() == level {
+		return
+	}
+
+	n.logLevel.SetLevel(level)
+	n.log.Info("namespace debug logging updated",
+		zap.Bool("enabled", level == zapcore.DebugLevel))
+}
+
 // SetSchemaHistory implements namespace.SchemaListener.
 func (n *dbNamespace) SetSchemaHistory(value namespace.SchemaHistory) {
 	n.Lock()
@@ -403,6 +503,14 @@ func (n *dbNamespace) reportStatusLoop(reportInterval time.Duration) {
 			n.metrics.status.index.numBlocks.Update(float64(n.statsLastTick.index.numBlocks))
 			n.metrics.status.index.numSegments.Update(float64(n.statsLastTick.index.numSegments))
 			n.statsLastTick.RUnlock()
+
+			var dirtyShards int
+			for _, shard := range n.GetOwnedShards() {
+				if shard.IsDirty() {
+					dirtyShards++
+				}
+			}
+			n.metrics.status.dirtyShards.Update(float64(dirtyShards))
 		}
 	}
 }
@@ -590,6 +698,7 @@ func (n *dbNamespace) Tick(c context.Cancellable, tickStart time.Time) error {
 	n.metrics.tick.activeSeries.Update(float64(r.activeSeries))
 	n.metrics.tick.expiredSeries.Inc(int64(r.expiredSeries))
 	n.metrics.tick.activeBlocks.Update(float64(r.activeBlocks))
+	n.seriesOpts.Stats().UpdateCacheActiveBlocks(n.seriesOpts.CachePolicy(), r.activeBlocks)
 	n.metrics.tick.wiredBlocks.Update(float64(r.wiredBlocks))
 	n.metrics.tick.unwiredBlocks.Update(float64(r.unwiredBlocks))
 	n.metrics.tick.pendingMergeBlocks.Update(float64(r.pendingMergeBlocks))
@@ -597,6 +706,7 @@ func (n *dbNamespace) Tick(c context.Cancellable, tickStart time.Time) error {
 	n.metrics.tick.madeUnwiredBlocks.Inc(int64(r.madeUnwiredBlocks))
 	n.metrics.tick.mergedOutOfOrderBlocks.Inc(int64(r.mergedOutOfOrderBlocks))
 	n.metrics.tick.evictedBuckets.Inc(int64(r.evictedBuckets))
+	n.metrics.tick.estimatedMemoryBytes.Update(float64(r.estimatedMemoryBytes))
 	n.metrics.tick.index.numDocs.Update(float64(indexTickResults.NumTotalDocs))
 	n.metrics.tick.index.numBlocks.Update(float64(indexTickResults.NumBlocks))
 	n.metrics.tick.index.numSegments.Update(float64(indexTickResults.NumSegments))
@@ -622,11 +732,15 @@ func (n *dbNamespace) Write(
 		return ts.Series{}, false, err
 	}
 	opts := series.WriteOptions{
-		TruncateType: n.opts.TruncateType(),
-		SchemaDesc:   nsCtx.Schema,
+		TruncateType:     n.opts.TruncateType(),
+		TransformOptions: n.writeTransformOpts,
+		SchemaDesc:       nsCtx.Schema,
 	}
 	series, wasWritten, err := shard.Write(ctx, id, timestamp,
 		value, unit, annotation, opts)
+	if n.readCache != nil {
+		n.readCache.invalidate(id)
+	}
 	n.metrics.write.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
 	return series, wasWritten, err
 }
@@ -651,11 +765,15 @@ func (n *dbNamespace) WriteTagged(
 		return ts.Series{}, false, err
 	}
 	opts := series.WriteOptions{
-		TruncateType: n.opts.TruncateType(),
-		SchemaDesc:   nsCtx.Schema,
+		TruncateType:     n.opts.TruncateType(),
+		TransformOptions: n.writeTransformOpts,
+		SchemaDesc:       nsCtx.Schema,
 	}
 	series, wasWritten, err := shard.WriteTagged(ctx, id, tags, timestamp,
 		value, unit, annotation, opts)
+	if n.readCache != nil {
+		n.readCache.invalidate(id)
+	}
 	n.metrics.writeTagged.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
 	return series, wasWritten, err
 }
@@ -735,7 +853,20 @@ func (n *dbNamespace) ReadEncoded(
 		n.metrics.read.ReportError(n.nowFn().Sub(callStart))
 		return nil, err
 	}
+	if n.readCache != nil {
+		if res, ok := n.readCache.get(id, start, end); ok {
+			n.metrics.read.ReportSuccess(n.nowFn().Sub(callStart))
+			return res, nil
+		}
+	}
 	res, err := shard.ReadEncoded(ctx, id, start, end, nsCtx)
+	if n.readCache != nil {
+		if err != nil {
+			n.readCache.fill(id, start, end, nil)
+		} else {
+			n.readCache.fill(id, start, end, res)
+		}
+	}
 	n.metrics.read.ReportSuccessOrError(err, n.nowFn().Sub(callStart))
 	return res, err
 }
@@ -879,6 +1010,12 @@ func (n *dbNamespace) Bootstrap(start time.Time, process bootstrap.Process) erro
 	}
 	wg.Wait()
 
+	// NB: Shards load bootstrapped blocks into series directly rather than
+	// through Write, so drop any results read before the blocks were loaded.
+	if n.readCache != nil {
+		n.readCache.reset()
+	}
+
 	if n.reverseIndex != nil {
 		err := n.reverseIndex.Bootstrap(bootstrapResult.IndexResult.IndexResults())
 		multiErr = multiErr.Add(err)
@@ -1106,17 +1243,20 @@ func (n *dbNamespace) Snapshot(
 	blockStart,
 	snapshotTime time.Time,
 	snapshotPersist persist.SnapshotPreparer,
-) error {
+) (SnapshotResult, error) {
 	// NB(rartoul): This value can be used for emitting metrics, but should not be used
 	// for business logic.
 	callStart := n.nowFn()
 
-	var nsCtx namespace.Context
+	var (
+		nsCtx  namespace.Context
+		result SnapshotResult
+	)
 	n.RLock()
 	if n.bootstrapState != Bootstrapped {
 		n.RUnlock()
 		n.metrics.snapshot.ReportError(n.nowFn().Sub(callStart))
-		return errNamespaceNotBootstrapped
+		return result, errNamespaceNotBootstrapped
 	}
 	nsCtx = n.nsContextWithRLock()
 	n.RUnlock()
@@ -1127,23 +1267,44 @@ func (n *dbNamespace) Snapshot(
 		// loss due to the commitlog cleanup logic assuming that a valid snapshot checkpoint file
 		// means that all namespaces were successfully snapshotted.
 		n.metrics.snapshot.ReportSuccess(n.nowFn().Sub(callStart))
-		return nil
+		return result, nil
 	}
 
-	multiErr := xerrors.NewMultiError()
-	shards := n.GetOwnedShards()
+	// NB: shards are snapshotted concurrently up to the max concurrent
+	// snapshots runtime option, the snapshot preparer bounds the number of
+	// shards that are written at once.
+	var (
+		wg          sync.WaitGroup
+		resultLock  sync.Mutex
+		multiErr    = xerrors.NewMultiError()
+		shards      = n.GetOwnedShards()
+		concurrency = n.opts.RuntimeOptionsManager().Get().MaxConcurrentSnapshots()
+		workers     = xsync.NewWorkerPool(concurrency)
+	)
+	workers.Init()
 	for _, shard := range shards {
-		err := shard.Snapshot(blockStart, snapshotTime, snapshotPersist, nsCtx)
-		if err != nil {
-			detailedErr := fmt.Errorf("shard %d failed to snapshot: %v", shard.ID(), err)
-			multiErr = multiErr.Add(detailedErr)
-			// Continue with remaining shards
-		}
+		shard := shard
+		wg.Add(1)
+		workers.Go(func() {
+			defer wg.Done()
+
+			shardResult, err := shard.Snapshot(blockStart, snapshotTime, snapshotPersist, nsCtx)
+
+			resultLock.Lock()
+			defer resultLock.Unlock()
+			result.SeriesPersist += shardResult.SeriesPersist
+			if err != nil {
+				detailedErr := fmt.Errorf("shard %d failed to snapshot: %v", shard.ID(), err)
+				multiErr = multiErr.Add(detailedErr)
+				// Continue with remaining shards
+			}
+		})
 	}
+	wg.Wait()
 
 	res := multiErr.FinalError()
 	n.metrics.snapshot.ReportSuccessOrError(res, n.nowFn().Sub(callStart))
-	return res
+	return result, res
 }
 
 func (n *dbNamespace) NeedsFlush(
@@ -1206,6 +1367,9 @@ func (n *dbNamespace) Truncate() (int64, error) {
 	// reclaimed memory to the OS. In the future, we might investigate whether it's worth returning
 	// the pooled objects to the pools if the pool is low and needs replenishing.
 	n.initShards(false)
+	if n.readCache != nil {
+		n.readCache.reset()
+	}
 
 	// NB(xichen): possibly also clean up disk files and force a GC here to reclaim memory immediately
 	return totalNumSeries, nil
@@ -1235,9 +1399,12 @@ func (n *dbNamespace) Repair(
 	multiErr := xerrors.NewMultiError()
 	shards := n.GetOwnedShards()
 	numShards := len(shards)
+	throttle := repairer.Options().RepairThrottle()
+	if v := n.nopts.RepairOptions().Throttle(); v > 0 {
+		throttle = v
+	}
 	if numShards > 0 {
-		throttlePerShard = time.Duration(
-			int64(repairer.Options().RepairThrottle()) / int64(numShards))
+		throttlePerShard = time.Duration(int64(throttle) / int64(numShards))
 	}
 
 	workers := xsync.NewWorkerPool(repairer.Options().RepairShardConcurrency())
@@ -1395,6 +1562,9 @@ func (n *dbNamespace) Close() error {
 	n.namespaceReaderMgr.close()
 	n.closeShards(shards, true)
 	close(n.shutdownCh)
+	if n.runtimeOptsListener != nil {
+		n.runtimeOptsListener.Close()
+	}
 	if n.reverseIndex != nil {
 		return n.reverseIndex.Close()
 	}
@@ -1431,6 +1601,48 @@ func (n *dbNamespace) FlushState(shardID uint32, blockStart time.Time) (fileOpSt
 	return flushState, nil
 }
 
+func (n *dbNamespace) SeriesBufferDebugInfo(
+	id ident.ID,
+) ([]series.BufferBlockDebugInfo, error) {
+	shard, _, err := n.readableShardFor(id)
+	if err != nil {
+		return nil, err
+	}
+	return shard.SeriesBufferDebugInfo(id)
+}
+
+func (n *dbNamespace) ShardStats() []ShardStats {
+	shards := n.GetOwnedShards()
+	stats := make([]ShardStats, 0, len(shards))
+	for _, shard := range shards {
+		stats = append(stats, shard.Stats())
+	}
+	return stats
+}
+
+func (n *dbNamespace) ApproxMemoryBySeries(limit int) []SeriesMemory {
+	var result []SeriesMemory
+	for _, shard := range n.GetOwnedShards() {
+		result = append(result, shard.ApproxMemoryBySeries(limit)...)
+	}
+	sortSeriesMemoryDesc(result)
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+func (n *dbNamespace) SubscribeWrites(
+	id ident.ID,
+	bufferSize int,
+) (WriteSubscription, error) {
+	shard, _, err := n.shardFor(id)
+	if err != nil {
+		return nil, err
+	}
+	return shard.SubscribeWrites(id, bufferSize), nil
+}
+
 func (n *dbNamespace) nsContextWithRLock() namespace.Context {
 	return namespace.Context{ID: n.id, Schema: n.schemaDescr}
 }
@@ -33,6 +33,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/sharding"
+	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/index"
@@ -42,6 +43,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/ts"
 	xmetrics "github.com/m3db/m3/src/dbnode/x/metrics"
 	xidx "github.com/m3db/m3/src/m3ninx/idx"
+	xclock "github.com/m3db/m3/src/x/clock"
 	"github.com/m3db/m3/src/x/context"
 	xerrors "github.com/m3db/m3/src/x/errors"
 	"github.com/m3db/m3/src/x/ident"
@@ -55,6 +57,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/uber-go/tally"
+	"go.uber.org/zap/zapcore"
 )
 
 var (
@@ -143,6 +146,26 @@ func TestNamespaceName(t *testing.T) {
 	require.True(t, defaultTestNs1ID.Equal(ns.ID()))
 }
 
+func TestNamespaceDebugLoggingRuntimeOptions(t *testing.T) {
+	ns, closer := newTestNamespace(t)
+	defer closer()
+
+	require.False(t, ns.log.Core().Enabled(zapcore.DebugLevel))
+
+	runtimeOptsMgr := ns.opts.RuntimeOptionsManager()
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().
+		SetDebugLoggingNamespaces([]string{"other", ns.ID().String()})))
+	require.True(t, xclock.WaitUntil(func() bool {
+		return ns.log.Core().Enabled(zapcore.DebugLevel)
+	}, 2*time.Second))
+
+	require.NoError(t, runtimeOptsMgr.Update(runtimeOptsMgr.Get().
+		SetDebugLoggingNamespaces([]string{"other"})))
+	require.True(t, xclock.WaitUntil(func() bool {
+		return !ns.log.Core().Enabled(zapcore.DebugLevel)
+	}, 2*time.Second))
+}
+
 func TestNamespaceTick(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -237,6 +260,128 @@ func TestNamespaceWriteShardOwned(t *testing.T) {
 	}
 }
 
+func TestNamespaceWriteValueBounds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	nsOpts := defaultTestNs1Opts.SetValueBoundsOptions(
+		namespace.NewValueBoundsOptions().
+			SetEnabled(true).
+			SetMin(0).
+			SetMax(100).
+			SetClamp(true))
+	ns, closer := newTestNamespaceWithIDOpts(t, defaultTestNs1ID, nsOpts)
+	defer closer()
+
+	var (
+		id   = ident.StringID("foo")
+		now  = time.Now()
+		ant  = []byte(nil)
+		opts = series.WriteOptions{
+			TruncateType: ns.opts.TruncateType(),
+			TransformOptions: series.WriteTransformOptions{
+				ValueBoundsEnabled: true,
+				MinValue:           0,
+				MaxValue:           100,
+				ClampValues:        true,
+			},
+		}
+	)
+	shard := NewMockdatabaseShard(ctrl)
+	shard.EXPECT().Write(ctx, id, now, 1.0, xtime.Second, ant, opts).
+		Return(ts.Series{}, true, nil)
+	ns.shards[testShardIDs[0].ID()] = shard
+
+	_, wasWritten, err := ns.Write(ctx, id, now, 1.0, xtime.Second, ant)
+	require.NoError(t, err)
+	require.True(t, wasWritten)
+}
+
+func TestNamespaceWriteForcedValue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	forcedNsOpts := defaultTestNs1Opts.SetForcedValueOptions(
+		namespace.NewForcedValueOptions().
+			SetEnabled(true).
+			SetValue(1.0))
+	forcedNs, forcedCloser := newTestNamespaceWithIDOpts(t,
+		ident.StringID("forced"), forcedNsOpts)
+	defer forcedCloser()
+	otherNs, otherCloser := newTestNamespaceWithIDOpts(t,
+		ident.StringID("other"), defaultTestNs1Opts)
+	defer otherCloser()
+
+	var (
+		id  = ident.StringID("foo")
+		now = time.Now()
+		ant = []byte(nil)
+	)
+	for _, test := range []struct {
+		ns               *dbNamespace
+		transformOptions series.WriteTransformOptions
+	}{
+		{
+			ns: forcedNs,
+			transformOptions: series.WriteTransformOptions{
+				ForceValueEnabled: true,
+				ForceValue:        1.0,
+			},
+		},
+		{
+			ns: otherNs,
+		},
+	} {
+		opts := series.WriteOptions{
+			TruncateType:     test.ns.opts.TruncateType(),
+			TransformOptions: test.transformOptions,
+		}
+		shard := NewMockdatabaseShard(ctrl)
+		shard.EXPECT().Write(ctx, id, now, 42.0, xtime.Second, ant, opts).
+			Return(ts.Series{}, true, nil)
+		test.ns.shards[testShardIDs[0].ID()] = shard
+
+		_, wasWritten, err := test.ns.Write(ctx, id, now, 42.0, xtime.Second, ant)
+		require.NoError(t, err)
+		require.True(t, wasWritten)
+	}
+}
+
+func TestNamespaceForcedValueOverridesDatabaseForcedValue(t *testing.T) {
+	dopts := DefaultTestOptions().
+		SetRuntimeOptionsManager(runtime.NewOptionsManager()).
+		SetWriteTransformOptions(series.WriteTransformOptions{
+			ForceValueEnabled: true,
+			ForceValue:        2.0,
+		})
+	defer dopts.RuntimeOptionsManager().Close()
+
+	hashFn := func(identifier ident.ID) uint32 { return testShardIDs[0].ID() }
+	shardSet, err := sharding.NewShardSet(testShardIDs, hashFn)
+	require.NoError(t, err)
+
+	// Namespaces without a forced value fall back to the database wide one.
+	metadata := newTestNamespaceMetadataWithIDOpts(t, defaultTestNs1ID,
+		defaultTestNs1Opts)
+	ns, err := newDatabaseNamespace(metadata, shardSet, nil, nil, nil, dopts)
+	require.NoError(t, err)
+	require.Equal(t, 2.0, ns.(*dbNamespace).writeTransformOpts.ForceValue)
+
+	metadata = newTestNamespaceMetadataWithIDOpts(t, defaultTestNs1ID,
+		defaultTestNs1Opts.SetForcedValueOptions(
+			namespace.NewForcedValueOptions().SetEnabled(true).SetValue(1.0)))
+	ns, err = newDatabaseNamespace(metadata, shardSet, nil, nil, nil, dopts)
+	require.NoError(t, err)
+	require.True(t, ns.(*dbNamespace).writeTransformOpts.ForceValueEnabled)
+	require.Equal(t, 1.0, ns.(*dbNamespace).writeTransformOpts.ForceValue)
+}
+
 func TestNamespaceReadEncodedShardNotOwned(t *testing.T) {
 	ctx := context.NewContext()
 	defer ctx.Close()
@@ -368,6 +513,49 @@ func TestNamespaceBootstrapAllShards(t *testing.T) {
 	require.Equal(t, BootstrapNotStarted, ns.bootstrapState)
 }
 
+func TestNamespaceBootstrapResetsReadCache(t *testing.T) {
+	ctrl := gomock.NewController(xtest.Reporter{T: t})
+	defer ctrl.Finish()
+
+	ns, closer := newTestNamespace(t)
+	defer closer()
+
+	start := time.Now()
+
+	cache, _, now := newTestReadCache(10)
+	var (
+		id         = ident.StringID("foo")
+		blockStart = now.Truncate(time.Hour)
+		blockEnd   = blockStart.Add(time.Hour)
+	)
+	_, ok := cache.get(id, blockStart, blockEnd)
+	require.False(t, ok)
+	cache.fill(id, blockStart, blockEnd, newTestReadCacheResults(blockStart, "foo"))
+	ns.readCache = cache
+
+	bs := bootstrap.NewMockProcess(ctrl)
+	bs.EXPECT().
+		Run(start, ns.metadata, sharding.IDs(testShardIDs)).
+		Return(bootstrap.ProcessResult{
+			DataResult:  result.NewDataBootstrapResult(),
+			IndexResult: result.NewIndexBootstrapResult(),
+		}, nil)
+
+	for _, testShard := range testShardIDs {
+		shard := NewMockdatabaseShard(ctrl)
+		shard.EXPECT().IsBootstrapped().Return(false)
+		shard.EXPECT().ID().Return(testShard.ID()).AnyTimes()
+		shard.EXPECT().Bootstrap(gomock.Any()).Return(nil)
+		ns.shards[testShard.ID()] = shard
+	}
+
+	require.NoError(t, ns.Bootstrap(start, bs))
+
+	// Blocks loaded by the bootstrap are read rather than the cached result.
+	_, ok = cache.get(id, blockStart, blockEnd)
+	require.False(t, ok)
+}
+
 func TestNamespaceBootstrapOnlyNonBootstrappedShards(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -515,7 +703,8 @@ func TestNamespaceSnapshotNotBootstrapped(t *testing.T) {
 
 	blockSize := ns.Options().RetentionOptions().BlockSize()
 	blockStart := time.Now().Truncate(blockSize)
-	require.Equal(t, errNamespaceNotBootstrapped, ns.Snapshot(blockStart, blockStart, nil))
+	_, err := ns.Snapshot(blockStart, blockStart, nil)
+	require.Equal(t, errNamespaceNotBootstrapped, err)
 }
 
 func TestNamespaceSnapshotAllShardsSuccess(t *testing.T) {
@@ -533,7 +722,9 @@ func TestNamespaceSnapshotAllShardsSuccess(t *testing.T) {
 			shardSnapshotErr:              nil,
 		},
 	}
-	require.NoError(t, testSnapshotWithShardSnapshotErrs(t, shardMethodResults))
+	result, err := testSnapshotWithShardSnapshotErrs(t, shardMethodResults)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.SeriesPersist)
 }
 
 func TestNamespaceSnapshotShardError(t *testing.T) {
@@ -551,10 +742,14 @@ func TestNamespaceSnapshotShardError(t *testing.T) {
 			shardSnapshotErr:              errors.New("err"),
 		},
 	}
-	require.Error(t, testSnapshotWithShardSnapshotErrs(t, shardMethodResults))
+	_, err := testSnapshotWithShardSnapshotErrs(t, shardMethodResults)
+	require.Error(t, err)
 }
 
-func testSnapshotWithShardSnapshotErrs(t *testing.T, shardMethodResults []snapshotTestCase) error {
+func testSnapshotWithShardSnapshotErrs(
+	t *testing.T,
+	shardMethodResults []snapshotTestCase,
+) (SnapshotResult, error) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -581,7 +776,8 @@ func testSnapshotWithShardSnapshotErrs(t *testing.T, shardMethodResults []snapsh
 		shardID := uint32(i)
 		shard.EXPECT().ID().Return(uint32(i)).AnyTimes()
 		if tc.expectSnapshot {
-			shard.EXPECT().Snapshot(blockStart, now, gomock.Any(), gomock.Any()).Return(tc.shardSnapshotErr)
+			shard.EXPECT().Snapshot(blockStart, now, gomock.Any(), gomock.Any()).
+				Return(SnapshotResult{SeriesPersist: 1}, tc.shardSnapshotErr)
 		}
 		ns.shards[testShardIDs[i].ID()] = shard
 		shardBootstrapStates[shardID] = tc.shardBootstrapStateBeforeTick
@@ -1286,3 +1482,45 @@ func assertNeedsFlush(t *testing.T, ns *dbNamespace, t0, t1 time.Time, assertTru
 	require.NoError(t, err)
 	require.Equal(t, assertTrue, needsFlush)
 }
+
+func TestNamespaceSeriesCachePolicyOverride(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := DefaultTestOptions().SetSeriesCachePolicy(series.CacheRecentlyRead)
+	retriever := block.NewMockDatabaseBlockRetriever(ctrl)
+
+	// Without an override the database wide policy is used.
+	policy, err := namespaceSeriesCachePolicy(opts, defaultTestNs1Opts, retriever)
+	require.NoError(t, err)
+	require.Equal(t, series.CacheRecentlyRead, policy)
+
+	policy, err = namespaceSeriesCachePolicy(opts,
+		defaultTestNs1Opts.SetSeriesCachePolicy("none"), retriever)
+	require.NoError(t, err)
+	require.Equal(t, series.CacheNone, policy)
+
+	// Caching all series does not need to retrieve blocks from disk.
+	policy, err = namespaceSeriesCachePolicy(opts,
+		defaultTestNs1Opts.SetSeriesCachePolicy("all"), nil)
+	require.NoError(t, err)
+	require.Equal(t, series.CacheAll, policy)
+
+	_, err = namespaceSeriesCachePolicy(opts,
+		defaultTestNs1Opts.SetSeriesCachePolicy("none"), nil)
+	require.Error(t, err)
+
+	_, err = namespaceSeriesCachePolicy(opts,
+		defaultTestNs1Opts.SetSeriesCachePolicy("lru"), retriever)
+	require.Error(t, err)
+
+	_, err = namespaceSeriesCachePolicy(opts,
+		defaultTestNs1Opts.SetSeriesCachePolicy("unknown"), retriever)
+	require.Error(t, err)
+
+	// The override is threaded through to the series of the namespace.
+	ns, closer := newTestNamespaceWithIDOpts(t, defaultTestNs1ID,
+		defaultTestNs1Opts.SetSeriesCachePolicy("all"))
+	defer closer()
+	require.Equal(t, series.CacheAll, ns.seriesOpts.CachePolicy())
+}
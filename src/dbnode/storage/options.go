@@ -68,6 +68,9 @@ const (
 
 	// defaultIndexingEnabled disables indexing by default.
 	defaultIndexingEnabled = false
+
+	// defaultBootstrapConcurrency bootstraps namespaces one at a time by default.
+	defaultBootstrapConcurrency = 1
 )
 
 var (
@@ -86,6 +89,10 @@ var (
 	errIndexOptionsNotSet         = errors.New("index enabled but index options are not set")
 	errPersistManagerNotSet       = errors.New("persist manager is not set")
 	errBlockLeaserNotSet          = errors.New("block leaser is not set")
+	errMaxNamespaceRetentionNeg   = errors.New("max namespace retention period is negative")
+	errConsistencyCheckSampleRate = errors.New("consistency check sample rate must be between 0 and 1")
+	errBootstrapRetryIntervalNeg  = errors.New("bootstrap failure retry interval is negative")
+	errBootstrapConcurrencyNotPos = errors.New("bootstrap concurrency must be positive")
 )
 
 // NewSeriesOptionsFromOptions creates a new set of database series options from provided options.
@@ -134,6 +141,7 @@ type options struct {
 	seriesOpts                     series.Options
 	seriesPool                     series.DatabaseSeriesPool
 	bytesPool                      pool.CheckedBytesPool
+	bytesPoolFactory               BytesPoolFactory
 	encoderPool                    encoding.EncoderPool
 	segmentReaderPool              xio.SegmentReaderPool
 	readerIteratorPool             encoding.ReaderIteratorPool
@@ -147,6 +155,11 @@ type options struct {
 	bufferBucketVersionsPool       *series.BufferBucketVersionsPool
 	schemaReg                      namespace.SchemaRegistry
 	blockLeaseManager              block.LeaseManager
+	readTransforms                 map[string]NewReadTransformFn
+	maxNamespaceRetentionPeriod    time.Duration
+	consistencyCheckSampleRate     float64
+	bootstrapFailureRetryInterval  time.Duration
+	bootstrapConcurrency           int
 }
 
 // NewOptions creates a new set of storage options with defaults
@@ -205,6 +218,8 @@ func newOptions(poolOpts pool.ObjectPoolOptions) Options {
 		bufferBucketVersionsPool:       series.NewBufferBucketVersionsPool(poolOpts),
 		bufferBucketPool:               series.NewBufferBucketPool(poolOpts),
 		schemaReg:                      namespace.NewSchemaRegistry(false, nil),
+		bootstrapConcurrency:           defaultBootstrapConcurrency,
+		readTransforms:                 DefaultReadTransforms(),
 	}
 	return o.SetEncodingM3TSZPooled()
 }
@@ -258,6 +273,22 @@ func (o *options) Validate() error {
 		return errBlockLeaserNotSet
 	}
 
+	if o.maxNamespaceRetentionPeriod < 0 {
+		return errMaxNamespaceRetentionNeg
+	}
+
+	if o.consistencyCheckSampleRate < 0 || o.consistencyCheckSampleRate > 1 {
+		return errConsistencyCheckSampleRate
+	}
+
+	if o.bootstrapFailureRetryInterval < 0 {
+		return errBootstrapRetryIntervalNeg
+	}
+
+	if o.bootstrapConcurrency <= 0 {
+		return errBootstrapConcurrencyNotPos
+	}
+
 	return nil
 }
 
@@ -562,6 +593,16 @@ func (o *options) BytesPool() pool.CheckedBytesPool {
 	return o.bytesPool
 }
 
+func (o *options) SetBytesPoolFactory(value BytesPoolFactory) Options {
+	opts := *o
+	opts.bytesPoolFactory = value
+	return &opts
+}
+
+func (o *options) BytesPoolFactory() BytesPoolFactory {
+	return o.bytesPoolFactory
+}
+
 func (o *options) SetEncoderPool(value encoding.EncoderPool) Options {
 	opts := *o
 	opts.encoderPool = value
@@ -692,3 +733,53 @@ func (o *options) SetBlockLeaseManager(leaseMgr block.LeaseManager) Options {
 func (o *options) BlockLeaseManager() block.LeaseManager {
 	return o.blockLeaseManager
 }
+
+func (o *options) SetReadTransforms(value map[string]NewReadTransformFn) Options {
+	opts := *o
+	opts.readTransforms = value
+	return &opts
+}
+
+func (o *options) ReadTransforms() map[string]NewReadTransformFn {
+	return o.readTransforms
+}
+
+func (o *options) SetMaxNamespaceRetentionPeriod(value time.Duration) Options {
+	opts := *o
+	opts.maxNamespaceRetentionPeriod = value
+	return &opts
+}
+
+func (o *options) MaxNamespaceRetentionPeriod() time.Duration {
+	return o.maxNamespaceRetentionPeriod
+}
+
+func (o *options) SetConsistencyCheckSampleRate(value float64) Options {
+	opts := *o
+	opts.consistencyCheckSampleRate = value
+	return &opts
+}
+
+func (o *options) ConsistencyCheckSampleRate() float64 {
+	return o.consistencyCheckSampleRate
+}
+
+func (o *options) SetBootstrapFailureRetryInterval(value time.Duration) Options {
+	opts := *o
+	opts.bootstrapFailureRetryInterval = value
+	return &opts
+}
+
+func (o *options) BootstrapFailureRetryInterval() time.Duration {
+	return o.bootstrapFailureRetryInterval
+}
+
+func (o *options) SetBootstrapConcurrency(value int) Options {
+	opts := *o
+	opts.bootstrapConcurrency = value
+	return &opts
+}
+
+func (o *options) BootstrapConcurrency() int {
+	return o.bootstrapConcurrency
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/clock"
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3/src/x/checked"
+	"github.com/m3db/m3/src/x/ident"
+
+	"github.com/uber-go/tally"
+)
+
+type readCacheKey struct {
+	id    string
+	start int64
+	end   int64
+}
+
+type readCacheEntry struct {
+	key       readCacheKey
+	expiresAt time.Time
+	results   [][]readCacheBlock
+}
+
+type readCacheBlock struct {
+	start     time.Time
+	blockSize time.Duration
+	segment   ts.Segment
+}
+
+// readCacheFill tracks reads of a series that missed the cache and are yet
+// to fill it, a write to the series while a fill is pending invalidates the
+// fill so that a result read before the write is never cached.
+type readCacheFill struct {
+	pending     int
+	invalidated bool
+}
+
+type readCacheMetrics struct {
+	hits          tally.Counter
+	misses        tally.Counter
+	evictions     tally.Counter
+	invalidations tally.Counter
+}
+
+func newReadCacheMetrics(scope tally.Scope) readCacheMetrics {
+	scope = scope.SubScope("read-cache")
+	return readCacheMetrics{
+		hits:          scope.Counter("hits"),
+		misses:        scope.Counter("misses"),
+		evictions:     scope.Counter("evictions"),
+		invalidations: scope.Counter("invalidations"),
+	}
+}
+
+// readCache caches the encoded results of reads keyed by series ID and time
+// range. Results are held until they expire, are evicted to keep the cache
+// within its size or the series is written to.
+type readCache struct {
+	sync.Mutex
+
+	size    int
+	ttl     time.Duration
+	nowFn   clock.NowFn
+	lru     *list.List
+	entries map[readCacheKey]*list.Element
+	byID    map[string]map[readCacheKey]struct{}
+	fills   map[string]*readCacheFill
+	metrics readCacheMetrics
+}
+
+func newReadCache(
+	opts namespace.ReadCacheOptions,
+	nowFn clock.NowFn,
+	scope tally.Scope,
+) *readCache {
+	return &readCache{
+		size:    opts.Size(),
+		ttl:     opts.TTL(),
+		nowFn:   nowFn,
+		lru:     list.New(),
+		entries: make(map[readCacheKey]*list.Element),
+		byID:    make(map[string]map[readCacheKey]struct{}),
+		fills:   make(map[string]*readCacheFill),
+		metrics: newReadCacheMetrics(scope),
+	}
+}
+
+// get returns the cached result for the read, on a miss the caller must
+// read the series and call fill with the result (or nil if the read failed).
+func (c *readCache) get(
+	id ident.ID,
+	start, end time.Time,
+) ([][]xio.BlockReader, bool) {
+	key := newReadCacheKey(id, start, end)
+
+	c.Lock()
+	defer c.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*readCacheEntry)
+		if c.nowFn().Before(entry.expiresAt) {
+			c.lru.MoveToFront(elem)
+			c.metrics.hits.Inc(1)
+			return entry.readers(), true
+		}
+		c.removeWithLock(elem)
+	}
+
+	fill, ok := c.fills[key.id]
+	if !ok {
+		fill = &readCacheFill{}
+		c.fills[key.id] = fill
+	}
+	fill.pending++
+	c.metrics.misses.Inc(1)
+	return nil, false
+}
+
+// fill caches the result of a read that missed the cache, the result is not
+// cached if it is empty or the series was written to since the miss.
+func (c *readCache) fill(
+	id ident.ID,
+	start, end time.Time,
+	results [][]xio.BlockReader,
+) {
+	key := newReadCacheKey(id, start, end)
+
+	// NB: Segments read from disk are only available once retrieved, so copy
+	// them before taking the lock.
+	cached, ok := newReadCacheResults(results)
+
+	c.Lock()
+	defer c.Unlock()
+
+	fill, exists := c.fills[key.id]
+	if !exists {
+		return
+	}
+	fill.pending--
+	if fill.pending == 0 {
+		delete(c.fills, key.id)
+	}
+	if !ok || fill.invalidated {
+		return
+	}
+
+	if elem, exists := c.entries[key]; exists {
+		c.removeWithLock(elem)
+	}
+	entry := &readCacheEntry{
+		key:       key,
+		expiresAt: c.nowFn().Add(c.ttl),
+		results:   cached,
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	keys, exists := c.byID[key.id]
+	if !exists {
+		keys = make(map[readCacheKey]struct{})
+		c.byID[key.id] = keys
+	}
+	keys[key] = struct{}{}
+
+	for c.lru.Len() > c.size {
+		c.removeWithLock(c.lru.Back())
+		c.metrics.evictions.Inc(1)
+	}
+}
+
+// invalidate drops all cached results for the series and any pending fills.
+func (c *readCache) invalidate(id ident.ID) {
+	c.Lock()
+	defer c.Unlock()
+
+	if fill, ok := c.fills[string(id.Bytes())]; ok {
+		fill.invalidated = true
+	}
+	keys, ok := c.byID[string(id.Bytes())]
+	if !ok {
+		return
+	}
+	for key := range keys {
+		c.removeWithLock(c.entries[key])
+		c.metrics.invalidations.Inc(1)
+	}
+}
+
+// reset drops all cached results.
+func (c *readCache) reset() {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, fill := range c.fills {
+		fill.invalidated = true
+	}
+	c.lru.Init()
+	c.entries = make(map[readCacheKey]*list.Element)
+	c.byID = make(map[string]map[readCacheKey]struct{})
+}
+
+func (c *readCache) removeWithLock(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*readCacheEntry)
+	delete(c.entries, entry.key)
+	keys := c.byID[entry.key.id]
+	delete(keys, entry.key)
+	if len(keys) == 0 {
+		delete(c.byID, entry.key.id)
+	}
+}
+
+func (e *readCacheEntry) readers() [][]xio.BlockReader {
+	results := make([][]xio.BlockReader, 0, len(e.results))
+	for _, blocks := range e.results {
+		readers := make([]xio.BlockReader, 0, len(blocks))
+		for _, b := range blocks {
+			readers = append(readers, xio.BlockReader{
+				SegmentReader: xio.NewSegmentReader(b.segment),
+				Start:         b.start,
+				BlockSize:     b.blockSize,
+			})
+		}
+		results = append(results, readers)
+	}
+	return results
+}
+
+func newReadCacheKey(id ident.ID, start, end time.Time) readCacheKey {
+	return readCacheKey{
+		id:    id.String(),
+		start: start.UnixNano(),
+		end:   end.UnixNano(),
+	}
+}
+
+// newReadCacheResults copies the segments of a read result so the cached
+// result does not depend on the lifetime of the pooled bytes being read,
+// returns false if the result is empty or a segment could not be read.
+func newReadCacheResults(
+	results [][]xio.BlockReader,
+) ([][]readCacheBlock, bool) {
+	var (
+		cached    = make([][]readCacheBlock, 0, len(results))
+		numBlocks int
+	)
+	for _, readers := range results {
+		blocks := make([]readCacheBlock, 0, len(readers))
+		for _, reader := range readers {
+			if reader.SegmentReader == nil {
+				continue
+			}
+			segment, err := reader.Segment()
+			if err != nil {
+				return nil, false
+			}
+			blocks = append(blocks, readCacheBlock{
+				start:     reader.Start,
+				blockSize: reader.BlockSize,
+				segment: ts.NewSegment(copyReadCacheBytes(segment.Head),
+					copyReadCacheBytes(segment.Tail), ts.FinalizeNone),
+			})
+		}
+		numBlocks += len(blocks)
+		cached = append(cached, blocks)
+	}
+	return cached, numBlocks > 0
+}
+
+func copyReadCacheBytes(b checked.Bytes) checked.Bytes {
+	if b == nil {
+		return nil
+	}
+	b.IncRef()
+	copied := append([]byte(nil), b.Bytes()...)
+	b.DecRef()
+	// NB: Cached bytes are never returned to a pool, hold a ref for the
+	// lifetime of the cached result.
+	result := checked.NewBytes(copied, nil)
+	result.IncRef()
+	return result
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/dbnode/x/xio"
+	"github.com/m3db/m3/src/x/checked"
+	"github.com/m3db/m3/src/x/ident"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func newTestReadCache(size int) (*readCache, tally.TestScope, *time.Time) {
+	var (
+		now   = time.Now()
+		scope = tally.NewTestScope("", nil)
+		opts  = namespace.NewReadCacheOptions().
+			SetEnabled(true).
+			SetSize(size).
+			SetTTL(time.Minute)
+	)
+	return newReadCache(opts, func() time.Time { return now }, scope), scope, &now
+}
+
+func newTestReadCacheResults(start time.Time, data string) [][]xio.BlockReader {
+	head := checked.NewBytes([]byte(data), nil)
+	return [][]xio.BlockReader{{{
+		SegmentReader: xio.NewSegmentReader(ts.NewSegment(head, nil, ts.FinalizeNone)),
+		Start:         start,
+		BlockSize:     time.Hour,
+	}}}
+}
+
+func requireReadCacheHit(
+	t *testing.T,
+	c *readCache,
+	id ident.ID,
+	start, end time.Time,
+	expected string,
+) {
+	res, ok := c.get(id, start, end)
+	require.True(t, ok)
+	require.Equal(t, 1, len(res))
+	require.Equal(t, 1, len(res[0]))
+	require.True(t, start.Equal(res[0][0].Start))
+	require.Equal(t, time.Hour, res[0][0].BlockSize)
+	data, err := ioutil.ReadAll(res[0][0])
+	require.NoError(t, err)
+	require.Equal(t, expected, string(data))
+}
+
+func TestReadCacheHitMissAndExpiry(t *testing.T) {
+	c, scope, now := newTestReadCache(10)
+	var (
+		id    = ident.StringID("foo")
+		start = now.Truncate(time.Hour)
+		end   = start.Add(time.Hour)
+	)
+
+	_, ok := c.get(id, start, end)
+	require.False(t, ok)
+	c.fill(id, start, end, newTestReadCacheResults(start, "abc"))
+
+	requireReadCacheHit(t, c, id, start, end, "abc")
+	// Each hit returns new readers over the cached result.
+	requireReadCacheHit(t, c, id, start, end, "abc")
+
+	// A different range is a different result.
+	_, ok = c.get(id, start, end.Add(time.Minute))
+	require.False(t, ok)
+	c.fill(id, start, end.Add(time.Minute), nil)
+
+	*now = now.Add(time.Minute)
+	_, ok = c.get(id, start, end)
+	require.False(t, ok)
+	c.fill(id, start, end, nil)
+	require.Equal(t, 0, c.lru.Len())
+	require.Equal(t, 0, len(c.fills))
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(2), counters["read-cache.hits+"].Value())
+	require.Equal(t, int64(3), counters["read-cache.misses+"].Value())
+}
+
+func TestReadCacheInvalidate(t *testing.T) {
+	c, scope, now := newTestReadCache(10)
+	var (
+		foo   = ident.StringID("foo")
+		bar   = ident.StringID("bar")
+		start = now.Truncate(time.Hour)
+		end   = start.Add(time.Hour)
+	)
+
+	for _, id := range []ident.ID{foo, bar} {
+		_, ok := c.get(id, start, end)
+		require.False(t, ok)
+		c.fill(id, start, end, newTestReadCacheResults(start, id.String()))
+	}
+
+	c.invalidate(foo)
+	_, ok := c.get(foo, start, end)
+	require.False(t, ok)
+	requireReadCacheHit(t, c, bar, start, end, "bar")
+
+	// A write while the read is in flight prevents caching the result.
+	c.invalidate(foo)
+	c.fill(foo, start, end, newTestReadCacheResults(start, "foo"))
+	_, ok = c.get(foo, start, end)
+	require.False(t, ok)
+	c.fill(foo, start, end, newTestReadCacheResults(start, "foo"))
+	requireReadCacheHit(t, c, foo, start, end, "foo")
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(1), counters["read-cache.invalidations+"].Value())
+}
+
+func TestReadCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c, scope, now := newTestReadCache(2)
+	var (
+		start = now.Truncate(time.Hour)
+		ids   = []ident.ID{
+			ident.StringID("a"),
+			ident.StringID("b"),
+			ident.StringID("c"),
+		}
+	)
+
+	for i, id := range ids {
+		if i == 2 {
+			// Use the first result so the second is least recently used.
+			requireReadCacheHit(t, c, ids[0], start, start.Add(time.Hour), "a")
+		}
+		_, ok := c.get(id, start, start.Add(time.Hour))
+		require.False(t, ok)
+		c.fill(id, start, start.Add(time.Hour),
+			newTestReadCacheResults(start, id.String()))
+	}
+
+	require.Equal(t, 2, c.lru.Len())
+	requireReadCacheHit(t, c, ids[0], start, start.Add(time.Hour), "a")
+	requireReadCacheHit(t, c, ids[2], start, start.Add(time.Hour), "c")
+	_, ok := c.get(ids[1], start, start.Add(time.Hour))
+	require.False(t, ok)
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(1), counters["read-cache.evictions+"].Value())
+}
+
+func TestReadCacheSkipsEmptyResults(t *testing.T) {
+	c, _, now := newTestReadCache(10)
+	var (
+		id    = ident.StringID("foo")
+		start = now.Truncate(time.Hour)
+		end   = start.Add(time.Hour)
+	)
+
+	_, ok := c.get(id, start, end)
+	require.False(t, ok)
+	c.fill(id, start, end, [][]xio.BlockReader{{}})
+	require.Equal(t, 0, c.lru.Len())
+	require.Equal(t, 0, len(c.fills))
+}
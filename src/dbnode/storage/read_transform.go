@@ -0,0 +1,115 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+)
+
+const (
+	// ReadTransformRate is the name of the built-in rate read transform.
+	ReadTransformRate = "rate"
+
+	// ReadTransformDelta is the name of the built-in delta read transform.
+	ReadTransformDelta = "delta"
+)
+
+// DefaultReadTransforms returns the built-in read transforms by name.
+func DefaultReadTransforms() map[string]NewReadTransformFn {
+	return map[string]NewReadTransformFn{
+		ReadTransformRate:  newRateReadTransform,
+		ReadTransformDelta: newDeltaReadTransform,
+	}
+}
+
+// applyReadTransforms applies the transforms in order, returning false if
+// any of the transforms drops the datapoint.
+func applyReadTransforms(
+	transforms []ReadTransform,
+	dp ts.Datapoint,
+) (ts.Datapoint, bool) {
+	for _, transform := range transforms {
+		var ok bool
+		dp, ok = transform.Apply(dp)
+		if !ok {
+			return ts.Datapoint{}, false
+		}
+	}
+	return dp, true
+}
+
+// rateReadTransform returns the per second rate of change between each
+// datapoint and the previous one, timestamped at the later datapoint. The
+// first datapoint only seeds the rate and is dropped. Gaps are not
+// interpolated, the rate across a gap is averaged over the whole gap. Values
+// are not treated as counters, so a decrease produces a negative rate.
+// Datapoints with the same timestamp as the previous one are dropped since
+// no rate can be computed, and rates are subject to float64 precision.
+type rateReadTransform struct {
+	prev    ts.Datapoint
+	hasPrev bool
+}
+
+func newRateReadTransform() ReadTransform {
+	return &rateReadTransform{}
+}
+
+func (t *rateReadTransform) Apply(dp ts.Datapoint) (ts.Datapoint, bool) {
+	prev, hasPrev := t.prev, t.hasPrev
+	t.prev, t.hasPrev = dp, true
+	if !hasPrev {
+		return ts.Datapoint{}, false
+	}
+
+	elapsed := dp.Timestamp.Sub(prev.Timestamp)
+	if elapsed <= 0 {
+		return ts.Datapoint{}, false
+	}
+	dp.Value = (dp.Value - prev.Value) / (float64(elapsed) / float64(time.Second))
+	return dp, true
+}
+
+// deltaReadTransform returns the difference between each datapoint and the
+// previous one, timestamped at the later datapoint. The first datapoint only
+// seeds the delta and is dropped. Gaps are not interpolated so a delta across
+// a gap covers the whole gap, and a NaN value produces NaN deltas for both
+// the datapoint and the one following it.
+type deltaReadTransform struct {
+	prev    float64
+	hasPrev bool
+}
+
+func newDeltaReadTransform() ReadTransform {
+	return &deltaReadTransform{}
+}
+
+func (t *deltaReadTransform) Apply(dp ts.Datapoint) (ts.Datapoint, bool) {
+	prev, hasPrev := t.prev, t.hasPrev
+	t.prev, t.hasPrev = dp.Value, true
+	if !hasPrev {
+		return ts.Datapoint{}, false
+	}
+
+	dp.Value -= prev
+	return dp, true
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+
+	"github.com/stretchr/testify/require"
+)
+
+func applyTestReadTransform(
+	transform ReadTransform,
+	dps []ts.Datapoint,
+) []ts.Datapoint {
+	var results []ts.Datapoint
+	for _, dp := range dps {
+		if dp, ok := applyReadTransforms([]ReadTransform{transform}, dp); ok {
+			results = append(results, dp)
+		}
+	}
+	return results
+}
+
+func TestRateReadTransform(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	results := applyTestReadTransform(newRateReadTransform(), []ts.Datapoint{
+		{Timestamp: now, Value: 10},
+		{Timestamp: now.Add(10 * time.Second), Value: 30},
+		// Same timestamp as the previous datapoint is dropped.
+		{Timestamp: now.Add(10 * time.Second), Value: 40},
+		// Gap is averaged over its duration.
+		{Timestamp: now.Add(time.Minute), Value: 140},
+		{Timestamp: now.Add(70 * time.Second), Value: 120},
+	})
+	require.Equal(t, []ts.Datapoint{
+		{Timestamp: now.Add(10 * time.Second), Value: 2},
+		{Timestamp: now.Add(time.Minute), Value: 2},
+		{Timestamp: now.Add(70 * time.Second), Value: -2},
+	}, results)
+}
+
+func TestDeltaReadTransform(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	results := applyTestReadTransform(newDeltaReadTransform(), []ts.Datapoint{
+		{Timestamp: now, Value: 10},
+		{Timestamp: now.Add(10 * time.Second), Value: 30},
+		{Timestamp: now.Add(time.Minute), Value: 25},
+	})
+	require.Equal(t, []ts.Datapoint{
+		{Timestamp: now.Add(10 * time.Second), Value: 20},
+		{Timestamp: now.Add(time.Minute), Value: -5},
+	}, results)
+}
+
+func TestApplyReadTransformsInOrder(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	pipeline := []ReadTransform{newDeltaReadTransform(), newDeltaReadTransform()}
+
+	var results []ts.Datapoint
+	for i, v := range []float64{1, 2, 4, 8} {
+		dp := ts.Datapoint{Timestamp: now.Add(time.Duration(i) * time.Second), Value: v}
+		if dp, ok := applyReadTransforms(pipeline, dp); ok {
+			results = append(results, dp)
+		}
+	}
+	require.Equal(t, []ts.Datapoint{
+		{Timestamp: now.Add(2 * time.Second), Value: 1},
+		{Timestamp: now.Add(3 * time.Second), Value: 2},
+	}, results)
+}
@@ -52,18 +52,26 @@ import (
 var (
 	errNoRepairOptions  = errors.New("no repair options")
 	errRepairInProgress = errors.New("repair already in progress")
+	errRepairQuiesced   = errors.New("repair is paused while database is quiesced")
+)
+
+const (
+	// dryRunMismatchedSeriesSampleSize is the max number of mismatched series
+	// IDs logged per shard by a dry run repair.
+	dryRunMismatchedSeriesSampleSize = 10
 )
 
 type recordFn func(namespace ident.ID, shard databaseShard, diffRes repair.MetadataComparisonResult)
 
 type shardRepairer struct {
-	opts     Options
-	rpopts   repair.Options
-	client   client.AdminClient
-	recordFn recordFn
-	logger   *zap.Logger
-	scope    tally.Scope
-	nowFn    clock.NowFn
+	opts             Options
+	rpopts           repair.Options
+	client           client.AdminClient
+	recordFn         recordFn
+	logger           *zap.Logger
+	scope            tally.Scope
+	dryRunMismatches tally.Counter
+	nowFn            clock.NowFn
 }
 
 func newShardRepairer(opts Options, rpopts repair.Options) databaseShardRepairer {
@@ -77,6 +85,8 @@ func newShardRepairer(opts Options, rpopts repair.Options) databaseShardRepairer
 		logger: iopts.Logger(),
 		scope:  scope,
 		nowFn:  opts.ClockOptions().NowFn(),
+
+		dryRunMismatches: scope.SubScope("dryrun").Counter("mismatches"),
 	}
 	r.recordFn = r.recordDifferences
 
@@ -99,13 +109,17 @@ func (r shardRepairer) Repair(
 	}
 
 	var (
-		start    = tr.Start
-		end      = tr.End
-		origin   = session.Origin()
-		replicas = session.Replicas()
+		start         = tr.Start
+		end           = tr.End
+		origin        = session.Origin()
+		replicas      = session.Replicas()
+		peerSelection = r.rpopts.PeerSelection()
 	)
 
-	metadata := repair.NewReplicaMetadataComparer(replicas, r.rpopts)
+	// Only the selected peers are compared against so they make up the
+	// replica set along with the local host.
+	metadata := repair.NewReplicaMetadataComparer(
+		peerSelection.NumPeers(replicas)+1, r.rpopts)
 	ctx.RegisterFinalizer(metadata)
 
 	// Add local metadata
@@ -161,9 +175,17 @@ func (r shardRepairer) Repair(
 	}
 
 	// Add peer metadata
-	level := r.rpopts.RepairConsistencyLevel()
-	peerIter, err := session.FetchBlocksMetadataFromPeers(nsCtx.ID, shard.ID(), start, end,
-		level, result.NewOptions())
+	var (
+		level    = r.rpopts.RepairConsistencyLevel()
+		peerIter client.PeerBlockMetadataIter
+	)
+	if selectPeers := peerSelection.PeerSelector(replicas); selectPeers != nil {
+		peerIter, err = session.FetchBlocksMetadataFromSelectedPeers(nsCtx.ID, shard.ID(),
+			start, end, level, selectPeers, result.NewOptions())
+	} else {
+		peerIter, err = session.FetchBlocksMetadataFromPeers(nsCtx.ID, shard.ID(), start, end,
+			level, result.NewOptions())
+	}
 	if err != nil {
 		return repair.MetadataComparisonResult{}, err
 	}
@@ -174,10 +196,67 @@ func (r shardRepairer) Repair(
 	metadataRes := metadata.Compare()
 
 	r.recordFn(nsCtx.ID, shard, metadataRes)
+	if r.rpopts.DryRun() {
+		r.recordDryRunMismatches(nsCtx.ID, shard, metadataRes)
+	}
 
 	return metadataRes, nil
 }
 
+// recordDryRunMismatches records the number of series blocks that differ
+// between the local host and its peers and logs a sample of the mismatched
+// series so operators can gauge the repair volume before enabling repairs.
+func (r shardRepairer) recordDryRunMismatches(
+	namespace ident.ID,
+	shard databaseShard,
+	diffRes repair.MetadataComparisonResult,
+) {
+	// A block can differ in both size and checksum so dedupe the blocks of
+	// each series across both sets of differences.
+	mismatches := make(map[string]map[xtime.UnixNano]struct{})
+	for _, diffs := range []repair.ReplicaSeriesMetadata{
+		diffRes.SizeDifferences,
+		diffRes.ChecksumDifferences,
+	} {
+		if diffs == nil {
+			continue
+		}
+		for _, entry := range diffs.Series().Iter() {
+			id := entry.Key().String()
+			blockStarts, ok := mismatches[id]
+			if !ok {
+				blockStarts = make(map[xtime.UnixNano]struct{})
+				mismatches[id] = blockStarts
+			}
+			for blockStart := range entry.Value().Metadata.Blocks() {
+				blockStarts[blockStart] = struct{}{}
+			}
+		}
+	}
+	if len(mismatches) == 0 {
+		return
+	}
+
+	var (
+		numMismatchedBlocks int64
+		sample              = make([]string, 0, dryRunMismatchedSeriesSampleSize)
+	)
+	for id, blockStarts := range mismatches {
+		numMismatchedBlocks += int64(len(blockStarts))
+		if len(sample) < dryRunMismatchedSeriesSampleSize {
+			sample = append(sample, id)
+		}
+	}
+	r.dryRunMismatches.Inc(numMismatchedBlocks)
+
+	r.logger.Debug("repair dry run found mismatches",
+		zap.String("namespace", namespace.String()),
+		zap.Uint32("shard", shard.ID()),
+		zap.Int("numMismatchedSeries", len(mismatches)),
+		zap.Int64("numMismatchedBlocks", numMismatchedBlocks),
+		zap.Strings("sampleMismatchedSeries", sample))
+}
+
 func (r shardRepairer) recordDifferences(
 	namespace ident.ID,
 	shard databaseShard,
@@ -208,6 +287,8 @@ func (r shardRepairer) recordDifferences(
 
 type repairFn func() error
 
+type repairNamespacesFn func(include func(n databaseNamespace) bool) error
+
 type sleepFn func(d time.Duration)
 
 type repairStatus int
@@ -275,6 +356,7 @@ type dbRepairer struct {
 	repairStatesByNs repairStatesByNs
 
 	repairFn            repairFn
+	repairNamespacesFn  repairNamespacesFn
 	sleepFn             sleepFn
 	nowFn               clock.NowFn
 	logger              *zap.Logger
@@ -285,6 +367,10 @@ type dbRepairer struct {
 	repairMaxRetries    int
 	status              tally.Gauge
 
+	// jitterSrc and schedulesByNs are only accessed from the run loop.
+	jitterSrc     rand.Source
+	schedulesByNs map[string]*namespaceRepairSchedule
+
 	closedLock sync.Mutex
 	running    int32
 	closed     bool
@@ -303,11 +389,8 @@ func newDatabaseRepairer(database database, opts Options) (databaseRepairer, err
 
 	shardRepairer := newShardRepairer(opts, ropts)
 
-	var jitter time.Duration
-	if repairJitter := ropts.RepairTimeJitter(); repairJitter > 0 {
-		src := rand.NewSource(nowFn().UnixNano())
-		jitter = time.Duration(float64(repairJitter) * (float64(src.Int63()) / float64(math.MaxInt64)))
-	}
+	jitterSrc := rand.NewSource(nowFn().UnixNano())
+	jitter := randomJitter(jitterSrc, ropts.RepairTimeJitter())
 
 	r := &dbRepairer{
 		database:            database,
@@ -323,8 +406,11 @@ func newDatabaseRepairer(database database, opts Options) (databaseRepairer, err
 		repairCheckInterval: ropts.RepairCheckInterval(),
 		repairMaxRetries:    ropts.RepairMaxRetries(),
 		status:              scope.Gauge("repair"),
+		jitterSrc:           jitterSrc,
+		schedulesByNs:       make(map[string]*namespaceRepairSchedule),
 	}
 	r.repairFn = r.Repair
+	r.repairNamespacesFn = r.repairNamespaces
 
 	return r, nil
 }
@@ -344,6 +430,8 @@ func (r *dbRepairer) run() {
 		r.sleepFn(r.repairCheckInterval)
 
 		now := r.nowFn()
+		r.repairScheduledNamespaces(now)
+
 		intervalStart := now.Truncate(r.repairInterval)
 
 		// If we haven't reached the offset yet, skip
@@ -357,6 +445,12 @@ func (r *dbRepairer) run() {
 			continue
 		}
 
+		// If the database is quiesced, skip without marking the interval as
+		// repaired so that the repair is attempted once it is unquiesced
+		if r.database.IsQuiesced() {
+			continue
+		}
+
 		curIntervalStart = intervalStart
 		if err := r.repairFn(); err != nil {
 			r.logger.Error("error repairing database", zap.Error(err))
@@ -364,6 +458,71 @@ func (r *dbRepairer) run() {
 	}
 }
 
+// repairScheduledNamespaces repairs the namespaces that override the database
+// wide repair schedule and have reached their own repair target time.
+func (r *dbRepairer) repairScheduledNamespaces(now time.Time) {
+	namespaces, err := r.database.GetOwnedNamespaces()
+	if err != nil {
+		r.logger.Error("error listing namespaces to repair", zap.Error(err))
+		return
+	}
+
+	due := make(map[string]struct{})
+	for _, n := range namespaces {
+		nsRepairOpts := n.Options().RepairOptions()
+		if !hasRepairScheduleOverride(nsRepairOpts) {
+			continue
+		}
+
+		id := n.ID().String()
+		schedule, ok := r.schedulesByNs[id]
+		if !ok || !schedule.nsRepairOpts.Equal(nsRepairOpts) {
+			schedule = r.newNamespaceRepairSchedule(nsRepairOpts)
+			r.schedulesByNs[id] = schedule
+		}
+
+		intervalStart, ok := schedule.due(now)
+		if !ok || r.database.IsQuiesced() {
+			continue
+		}
+
+		schedule.curIntervalStart = intervalStart
+		due[id] = struct{}{}
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	err = r.repairNamespacesFn(func(n databaseNamespace) bool {
+		_, ok := due[n.ID().String()]
+		return ok
+	})
+	if err != nil {
+		r.logger.Error("error repairing namespaces", zap.Error(err))
+	}
+}
+
+func (r *dbRepairer) newNamespaceRepairSchedule(
+	nsRepairOpts namespace.RepairOptions,
+) *namespaceRepairSchedule {
+	schedule := &namespaceRepairSchedule{
+		nsRepairOpts: nsRepairOpts,
+		interval:     r.ropts.RepairInterval(),
+		offset:       r.ropts.RepairTimeOffset(),
+		jitter:       r.repairTimeJitter,
+	}
+	if v := nsRepairOpts.Interval(); v > 0 {
+		schedule.interval = v
+	}
+	if v := nsRepairOpts.Offset(); v > 0 {
+		schedule.offset = v
+	}
+	if v := nsRepairOpts.Jitter(); v > 0 {
+		schedule.jitter = randomJitter(r.jitterSrc, v)
+	}
+	return schedule
+}
+
 func (r *dbRepairer) namespaceRepairTimeRanges(ns databaseNamespace) xtime.Ranges {
 	var (
 		now       = r.nowFn()
@@ -408,11 +567,23 @@ func (r *dbRepairer) Stop() {
 }
 
 func (r *dbRepairer) Repair() error {
+	return r.repairNamespaces(func(n databaseNamespace) bool {
+		// Namespaces that override the repair schedule are repaired
+		// separately once their own repair target time is reached.
+		return !hasRepairScheduleOverride(n.Options().RepairOptions())
+	})
+}
+
+func (r *dbRepairer) repairNamespaces(include func(n databaseNamespace) bool) error {
 	// Don't attempt a repair if the database is not bootstrapped yet
 	if !r.database.IsBootstrapped() {
 		return nil
 	}
 
+	if r.database.IsQuiesced() {
+		return errRepairQuiesced
+	}
+
 	if !atomic.CompareAndSwapInt32(&r.running, 0, 1) {
 		return errRepairInProgress
 	}
@@ -427,6 +598,9 @@ func (r *dbRepairer) Repair() error {
 		return err
 	}
 	for _, n := range namespaces {
+		if !include(n) {
+			continue
+		}
 		iter := r.namespaceRepairTimeRanges(n).Iter()
 		for iter.Next() {
 			multiErr = multiErr.Add(r.repairNamespaceWithTimeRange(n, iter.Value()))
@@ -455,6 +629,12 @@ func (r *dbRepairer) repairNamespaceWithTimeRange(n databaseNamespace, tr xtime.
 		err = fmt.Errorf("namespace %s failed to repair time range %v: %v", n.ID().String(), tr, err)
 	}
 
+	// A dry run does not repair anything so leave the blocks to be compared
+	// again, and repaired once dry run is disabled.
+	if r.ropts.DryRun() {
+		return err
+	}
+
 	// update repairer state
 	for t := tr.Start; t.Before(tr.End); t = t.Add(blockSize) {
 		repairState, _ := r.repairStatesByNs.repairStates(n.ID(), t)
@@ -470,6 +650,42 @@ func (r *dbRepairer) repairNamespaceWithTimeRange(n databaseNamespace, tr xtime.
 	return err
 }
 
+// namespaceRepairSchedule is the repair schedule of a namespace that overrides
+// the database wide repair interval, offset or jitter.
+type namespaceRepairSchedule struct {
+	nsRepairOpts     namespace.RepairOptions
+	interval         time.Duration
+	offset           time.Duration
+	jitter           time.Duration
+	curIntervalStart time.Time
+}
+
+// due returns the start of the current interval and whether the namespace
+// needs to be repaired for it.
+func (s *namespaceRepairSchedule) due(now time.Time) (time.Time, bool) {
+	if s.interval <= 0 {
+		return time.Time{}, false
+	}
+	intervalStart := now.Truncate(s.interval)
+	if now.Before(intervalStart.Add(s.offset + s.jitter)) {
+		return time.Time{}, false
+	}
+	return intervalStart, !intervalStart.Equal(s.curIntervalStart)
+}
+
+func hasRepairScheduleOverride(nsRepairOpts namespace.RepairOptions) bool {
+	return nsRepairOpts.Interval() > 0 ||
+		nsRepairOpts.Offset() > 0 ||
+		nsRepairOpts.Jitter() > 0
+}
+
+func randomJitter(src rand.Source, maxJitter time.Duration) time.Duration {
+	if maxJitter <= 0 {
+		return 0
+	}
+	return time.Duration(float64(maxJitter) * (float64(src.Int63()) / float64(math.MaxInt64)))
+}
+
 var noOpRepairer databaseRepairer = repairerNoOp{}
 
 type repairerNoOp struct{}
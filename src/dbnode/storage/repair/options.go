@@ -39,6 +39,8 @@ const (
 	defaultRepairShardConcurrency           = 1
 	defaultDebugShadowComparisonsEnabled    = false
 	defaultDebugShadowComparisonsPercentage = 1.0
+	defaultDryRun                           = false
+	defaultPeerSelection                    = AllPeers
 )
 
 var (
@@ -68,6 +70,8 @@ type options struct {
 	hostBlockMetadataSlicePool       HostBlockMetadataSlicePool
 	debugShadowComparisonsEnabled    bool
 	debugShadowComparisonsPercentage float64
+	dryRun                           bool
+	peerSelection                    PeerSelection
 }
 
 // NewOptions creates new bootstrap options
@@ -84,6 +88,8 @@ func NewOptions() Options {
 		hostBlockMetadataSlicePool:       NewHostBlockMetadataSlicePool(nil, 0),
 		debugShadowComparisonsEnabled:    defaultDebugShadowComparisonsEnabled,
 		debugShadowComparisonsPercentage: defaultDebugShadowComparisonsPercentage,
+		dryRun:                           defaultDryRun,
+		peerSelection:                    defaultPeerSelection,
 	}
 }
 
@@ -207,6 +213,26 @@ func (o *options) DebugShadowComparisonsPercentage() float64 {
 	return o.debugShadowComparisonsPercentage
 }
 
+func (o *options) SetDryRun(value bool) Options {
+	opts := *o
+	opts.dryRun = value
+	return &opts
+}
+
+func (o *options) DryRun() bool {
+	return o.dryRun
+}
+
+func (o *options) SetPeerSelection(value PeerSelection) Options {
+	opts := *o
+	opts.peerSelection = value
+	return &opts
+}
+
+func (o *options) PeerSelection() PeerSelection {
+	return o.peerSelection
+}
+
 func (o *options) Validate() error {
 	if o.adminClient == nil {
 		return errNoAdminClient
@@ -242,5 +268,8 @@ func (o *options) Validate() error {
 		o.debugShadowComparisonsPercentage < 0 {
 		return errInvalidDebugShadowComparisonsPercentage
 	}
+	if err := o.peerSelection.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
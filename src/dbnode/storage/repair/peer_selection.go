@@ -0,0 +1,172 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package repair
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/topology"
+)
+
+// PeerSelection is the strategy used to select the peers of a shard that
+// a repair compares the local metadata against.
+type PeerSelection int
+
+const (
+	// AllPeers compares against every peer of the shard.
+	AllPeers PeerSelection = iota
+
+	// MajorityOnly compares against ceil(replicas/2) peers of the shard,
+	// picked deterministically per shard so comparisons are consistent
+	// across runs.
+	MajorityOnly
+
+	// SingleRandomPeer compares against a single randomly picked peer of
+	// the shard.
+	SingleRandomPeer
+)
+
+var validPeerSelections = []PeerSelection{
+	AllPeers,
+	MajorityOnly,
+	SingleRandomPeer,
+}
+
+var (
+	errPeerSelectionUnspecified = errors.New("repair peer selection not specified")
+	errPeerSelectionNoPeers     = errors.New("repair peer selection requires at least two replicas")
+)
+
+func (s PeerSelection) String() string {
+	switch s {
+	case AllPeers:
+		return "all_peers"
+	case MajorityOnly:
+		return "majority_only"
+	case SingleRandomPeer:
+		return "single_random_peer"
+	}
+	return "unknown"
+}
+
+// Validate returns an error if the peer selection is invalid.
+func (s PeerSelection) Validate() error {
+	for _, valid := range validPeerSelections {
+		if s == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid repair peer selection: %d", int(s))
+}
+
+// ValidateReplicas returns an error if the peer selection cannot select
+// any peers for the given number of replicas.
+func (s PeerSelection) ValidateReplicas(replicas int) error {
+	if s != AllPeers && replicas < 2 {
+		return errPeerSelectionNoPeers
+	}
+	return nil
+}
+
+// NumPeers returns the number of peers selected for the given number of
+// replicas, which includes the local host.
+func (s PeerSelection) NumPeers(replicas int) int {
+	numPeers := replicas - 1
+	switch s {
+	case MajorityOnly:
+		numPeers = minInt((replicas+1)/2, numPeers)
+	case SingleRandomPeer:
+		numPeers = minInt(1, numPeers)
+	}
+	if numPeers < 0 {
+		return 0
+	}
+	return numPeers
+}
+
+// PeerSelector returns the peer selector that selects the peers of a shard
+// for the given number of replicas, it returns nil if every peer is
+// selected.
+func (s PeerSelection) PeerSelector(replicas int) client.PeerSelector {
+	numPeers := s.NumPeers(replicas)
+	switch s {
+	case MajorityOnly:
+		return func(shard uint32, peers []topology.Host) []topology.Host {
+			if len(peers) <= numPeers {
+				return peers
+			}
+			sorted := make([]topology.Host, len(peers))
+			copy(sorted, peers)
+			sort.Slice(sorted, func(i, j int) bool {
+				return sorted[i].ID() < sorted[j].ID()
+			})
+			// Rotate the starting peer by shard so the comparisons are
+			// spread across peers while staying stable across runs.
+			offset := int(shard % uint32(len(sorted)))
+			selected := make([]topology.Host, 0, numPeers)
+			for i := 0; i < numPeers; i++ {
+				selected = append(selected, sorted[(offset+i)%len(sorted)])
+			}
+			return selected
+		}
+	case SingleRandomPeer:
+		return func(shard uint32, peers []topology.Host) []topology.Host {
+			if len(peers) <= numPeers {
+				return peers
+			}
+			return []topology.Host{peers[rand.Intn(len(peers))]}
+		}
+	}
+	return nil
+}
+
+// UnmarshalYAML unmarshals a PeerSelection into a valid type from string.
+func (s *PeerSelection) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var str string
+	if err := unmarshal(&str); err != nil {
+		return err
+	}
+	if str == "" {
+		return errPeerSelectionUnspecified
+	}
+	strs := make([]string, 0, len(validPeerSelections))
+	for _, valid := range validPeerSelections {
+		if str == valid.String() {
+			*s = valid
+			return nil
+		}
+		strs = append(strs, "'"+valid.String()+"'")
+	}
+	return fmt.Errorf("invalid PeerSelection '%s' valid types are: %s",
+		str, strings.Join(strs, ", "))
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
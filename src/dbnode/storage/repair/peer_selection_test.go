@@ -0,0 +1,114 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package repair
+
+import (
+	"testing"
+
+	"github.com/m3db/m3/src/dbnode/topology"
+
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func testPeerSelectionHosts() []topology.Host {
+	return []topology.Host{
+		topology.NewHost("d", "addrd"),
+		topology.NewHost("b", "addrb"),
+		topology.NewHost("a", "addra"),
+		topology.NewHost("c", "addrc"),
+	}
+}
+
+func TestPeerSelectionNumPeers(t *testing.T) {
+	tests := []struct {
+		selection PeerSelection
+		replicas  int
+		expected  int
+	}{
+		{AllPeers, 1, 0},
+		{AllPeers, 3, 2},
+		{MajorityOnly, 2, 1},
+		{MajorityOnly, 3, 2},
+		{MajorityOnly, 5, 3},
+		{SingleRandomPeer, 1, 0},
+		{SingleRandomPeer, 5, 1},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.expected, test.selection.NumPeers(test.replicas),
+			"%s with %d replicas", test.selection, test.replicas)
+	}
+}
+
+func TestPeerSelectionValidateReplicas(t *testing.T) {
+	require.NoError(t, AllPeers.ValidateReplicas(1))
+	require.Error(t, MajorityOnly.ValidateReplicas(1))
+	require.Error(t, SingleRandomPeer.ValidateReplicas(1))
+	require.NoError(t, MajorityOnly.ValidateReplicas(2))
+	require.NoError(t, SingleRandomPeer.ValidateReplicas(3))
+	require.Error(t, PeerSelection(10).Validate())
+}
+
+func TestPeerSelectionAllPeersSelectsEveryPeer(t *testing.T) {
+	require.Nil(t, AllPeers.PeerSelector(5))
+}
+
+func TestPeerSelectionMajorityOnlyIsDeterministicPerShard(t *testing.T) {
+	selectPeers := MajorityOnly.PeerSelector(5)
+
+	hostIDs := func(hosts []topology.Host) []string {
+		ids := make([]string, 0, len(hosts))
+		for _, host := range hosts {
+			ids = append(ids, host.ID())
+		}
+		return ids
+	}
+
+	require.Equal(t, []string{"a", "b", "c"},
+		hostIDs(selectPeers(0, testPeerSelectionHosts())))
+	require.Equal(t, []string{"b", "c", "d"},
+		hostIDs(selectPeers(1, testPeerSelectionHosts())))
+	require.Equal(t, []string{"d", "a", "b"},
+		hostIDs(selectPeers(7, testPeerSelectionHosts())))
+
+	// Reordering the peers does not change the selection.
+	hosts := testPeerSelectionHosts()
+	hosts[0], hosts[3] = hosts[3], hosts[0]
+	require.Equal(t, []string{"b", "c", "d"}, hostIDs(selectPeers(1, hosts)))
+}
+
+func TestPeerSelectionSingleRandomPeer(t *testing.T) {
+	selectPeers := SingleRandomPeer.PeerSelector(5)
+	for i := 0; i < 10; i++ {
+		require.Equal(t, 1, len(selectPeers(0, testPeerSelectionHosts())))
+	}
+}
+
+func TestPeerSelectionUnmarshalYAML(t *testing.T) {
+	for _, valid := range validPeerSelections {
+		var selection PeerSelection
+		require.NoError(t, yaml.Unmarshal([]byte(valid.String()), &selection))
+		require.Equal(t, valid, selection)
+	}
+
+	var selection PeerSelection
+	require.Error(t, yaml.Unmarshal([]byte("some_peers"), &selection))
+}
@@ -224,6 +224,22 @@ type Options interface {
 	// DebugShadowComparisonsPercentage returns the debug shadow comparisons percentage.
 	DebugShadowComparisonsPercentage() float64
 
+	// SetDryRun sets whether repairs only report the differences between
+	// peers without being marked as repaired.
+	SetDryRun(value bool) Options
+
+	// DryRun returns whether repairs only report the differences between
+	// peers without being marked as repaired.
+	DryRun() bool
+
+	// SetPeerSelection sets the strategy used to select the peers of a shard
+	// that repairs compare against.
+	SetPeerSelection(value PeerSelection) Options
+
+	// PeerSelection returns the strategy used to select the peers of a shard
+	// that repairs compare against.
+	PeerSelection() PeerSelection
+
 	// Validate checks if the options are valid.
 	Validate() error
 }
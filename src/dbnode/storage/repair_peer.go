@@ -0,0 +1,153 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/client"
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
+	"github.com/m3db/m3/src/dbnode/storage/series"
+	"github.com/m3db/m3/src/dbnode/topology"
+	"github.com/m3db/m3/src/x/context"
+	"github.com/m3db/m3/src/x/ident"
+	xtime "github.com/m3db/m3/src/x/time"
+)
+
+type seriesReconcilePeer struct {
+	client client.AdminClient
+	nsMeta namespace.Metadata
+	shard  uint32
+	level  topology.ReadConsistencyLevel
+}
+
+// NewSeriesReconcilePeer returns a series reconcile peer that fetches the
+// replicas of a series' blocks from the peers of the shard using the admin
+// client, for use with DatabaseSeries.ReconcileWithPeer.
+func NewSeriesReconcilePeer(
+	client client.AdminClient,
+	nsMeta namespace.Metadata,
+	shard uint32,
+	level topology.ReadConsistencyLevel,
+) series.ReconcilePeer {
+	return &seriesReconcilePeer{
+		client: client,
+		nsMeta: nsMeta,
+		shard:  shard,
+		level:  level,
+	}
+}
+
+func (p *seriesReconcilePeer) FetchBlocksMetadata(
+	ctx context.Context,
+	id ident.ID,
+	blockStarts []time.Time,
+) ([]block.ReplicaMetadata, error) {
+	if len(blockStarts) == 0 {
+		return nil, nil
+	}
+
+	session, err := p.client.DefaultAdminSession()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		blockSize = p.nsMeta.Options().RetentionOptions().BlockSize()
+		starts    = make(map[xtime.UnixNano]struct{}, len(blockStarts))
+		start     = blockStarts[0]
+		end       = blockStarts[0]
+	)
+	for _, blockStart := range blockStarts {
+		starts[xtime.ToUnixNano(blockStart)] = struct{}{}
+		if blockStart.Before(start) {
+			start = blockStart
+		}
+		if blockStart.After(end) {
+			end = blockStart
+		}
+	}
+
+	// NB: peers can only stream the metadata of a whole shard so the
+	// metadata of the other series in the shard is skipped.
+	iter, err := session.FetchBlocksMetadataFromPeers(p.nsMeta.ID(), p.shard,
+		start, end.Add(blockSize), p.level, result.NewOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	var replicas []block.ReplicaMetadata
+	for iter.Next() {
+		host, metadata := iter.Current()
+		if !metadata.ID.Equal(id) {
+			continue
+		}
+		if _, ok := starts[xtime.ToUnixNano(metadata.Start)]; !ok {
+			continue
+		}
+		// The metadata is only valid until the next iteration so take
+		// the caller's ID rather than copying it.
+		metadata.ID = id
+		metadata.Tags = ident.Tags{}
+		replicas = append(replicas, block.ReplicaMetadata{
+			Metadata: metadata,
+			Host:     host,
+		})
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	return replicas, nil
+}
+
+func (p *seriesReconcilePeer) FetchBlocks(
+	ctx context.Context,
+	replicas []block.ReplicaMetadata,
+) ([]block.DatabaseBlock, error) {
+	if len(replicas) == 0 {
+		return nil, nil
+	}
+
+	session, err := p.client.DefaultAdminSession()
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := session.FetchBlocksFromPeers(p.nsMeta, p.shard, p.level,
+		replicas, result.NewOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make([]block.DatabaseBlock, 0, len(replicas))
+	for iter.Next() {
+		_, _, b := iter.Current()
+		blocks = append(blocks, b)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
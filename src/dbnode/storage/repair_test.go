@@ -30,6 +30,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
 	"github.com/m3db/m3/src/dbnode/topology"
 	"github.com/m3db/m3/src/x/context"
@@ -48,6 +49,8 @@ func TestDatabaseRepairerStartStop(t *testing.T) {
 	opts := DefaultTestOptions().SetRepairOptions(testRepairOptions(ctrl))
 	db := NewMockdatabase(ctrl)
 	db.EXPECT().Options().Return(opts).AnyTimes()
+	db.EXPECT().IsQuiesced().Return(false).AnyTimes()
+	db.EXPECT().GetOwnedNamespaces().Return(nil, nil).AnyTimes()
 
 	databaseRepairer, err := newDatabaseRepairer(db, opts)
 	require.NoError(t, err)
@@ -114,6 +117,7 @@ func TestDatabaseRepairerHaveNotReachedOffset(t *testing.T) {
 		SetRepairOptions(repairOpts)
 	mockDatabase := NewMockdatabase(ctrl)
 	mockDatabase.EXPECT().Options().Return(opts).AnyTimes()
+	mockDatabase.EXPECT().GetOwnedNamespaces().Return(nil, nil).AnyTimes()
 
 	databaseRepairer, err := newDatabaseRepairer(mockDatabase, opts)
 	require.NoError(t, err)
@@ -171,6 +175,8 @@ func TestDatabaseRepairerOnlyOncePerInterval(t *testing.T) {
 		SetRepairOptions(repairOpts)
 	mockDatabase := NewMockdatabase(ctrl)
 	mockDatabase.EXPECT().Options().Return(opts).AnyTimes()
+	mockDatabase.EXPECT().IsQuiesced().Return(false).AnyTimes()
+	mockDatabase.EXPECT().GetOwnedNamespaces().Return(nil, nil).AnyTimes()
 
 	databaseRepairer, err := newDatabaseRepairer(mockDatabase, opts)
 	require.NoError(t, err)
@@ -193,6 +199,77 @@ func TestDatabaseRepairerOnlyOncePerInterval(t *testing.T) {
 	require.Equal(t, 2, numRepairs)
 }
 
+func TestDatabaseRepairerNamespaceScheduleOverride(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		repairInterval   = 2 * time.Hour
+		repairTimeOffset = time.Hour
+		now              = time.Now().Truncate(repairInterval).Add(30 * time.Minute)
+		numIter          = 0
+		repaired         []string
+	)
+
+	nowFn := func() time.Time {
+		if numIter < 3 {
+			return now
+		}
+		return now.Add(20 * time.Minute)
+	}
+	opts := DefaultTestOptions()
+	clockOpts := opts.ClockOptions().SetNowFn(nowFn)
+	repairOpts := testRepairOptions(ctrl).
+		SetRepairInterval(repairInterval).
+		SetRepairTimeOffset(repairTimeOffset)
+	opts = opts.
+		SetClockOptions(clockOpts.SetNowFn(nowFn)).
+		SetRepairOptions(repairOpts)
+
+	overridden := NewMockdatabaseNamespace(ctrl)
+	overridden.EXPECT().ID().Return(ident.StringID("overridden")).AnyTimes()
+	overridden.EXPECT().Options().Return(namespace.NewOptions().
+		SetRepairOptions(namespace.NewRepairOptions().
+			SetInterval(time.Hour).
+			SetOffset(10 * time.Minute))).AnyTimes()
+	defaults := NewMockdatabaseNamespace(ctrl)
+	defaults.EXPECT().ID().Return(ident.StringID("defaults")).AnyTimes()
+	defaults.EXPECT().Options().Return(namespace.NewOptions()).AnyTimes()
+	namespaces := []databaseNamespace{overridden, defaults}
+
+	mockDatabase := NewMockdatabase(ctrl)
+	mockDatabase.EXPECT().Options().Return(opts).AnyTimes()
+	mockDatabase.EXPECT().IsQuiesced().Return(false).AnyTimes()
+	mockDatabase.EXPECT().GetOwnedNamespaces().Return(namespaces, nil).AnyTimes()
+
+	databaseRepairer, err := newDatabaseRepairer(mockDatabase, opts)
+	require.NoError(t, err)
+	repairer := databaseRepairer.(*dbRepairer)
+
+	repairer.repairFn = func() error {
+		require.FailNow(t, "database wide repair offset should not be reached")
+		return nil
+	}
+	repairer.repairNamespacesFn = func(include func(n databaseNamespace) bool) error {
+		for _, n := range namespaces {
+			if include(n) {
+				repaired = append(repaired, n.ID().String())
+			}
+		}
+		return nil
+	}
+	repairer.sleepFn = func(_ time.Duration) {
+		if numIter == 2 {
+			repairer.closed = true
+		}
+		numIter++
+	}
+
+	repairer.run()
+	require.Equal(t, 3, numIter)
+	require.Equal(t, []string{"overridden"}, repaired)
+}
+
 func TestDatabaseRepairerRepairNotBootstrapped(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -208,6 +285,22 @@ func TestDatabaseRepairerRepairNotBootstrapped(t *testing.T) {
 	require.Nil(t, repairer.Repair())
 }
 
+func TestDatabaseRepairerRepairQuiesced(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := DefaultTestOptions().SetRepairOptions(testRepairOptions(ctrl))
+	mockDatabase := NewMockdatabase(ctrl)
+
+	databaseRepairer, err := newDatabaseRepairer(mockDatabase, opts)
+	require.NoError(t, err)
+	repairer := databaseRepairer.(*dbRepairer)
+
+	mockDatabase.EXPECT().IsBootstrapped().Return(true)
+	mockDatabase.EXPECT().IsQuiesced().Return(true)
+	require.Equal(t, errRepairQuiesced, repairer.Repair())
+}
+
 func TestDatabaseShardRepairerRepair(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -343,6 +436,70 @@ func TestDatabaseShardRepairerRepair(t *testing.T) {
 	require.Equal(t, expected, block.Metadata())
 }
 
+func TestDatabaseShardRepairerRepairSelectedPeers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := client.NewMockAdminSession(ctrl)
+	session.EXPECT().Origin().Return(topology.NewHost("0", "addr0"))
+	session.EXPECT().Replicas().Return(3)
+
+	mockClient := client.NewMockAdminClient(ctrl)
+	mockClient.EXPECT().DefaultAdminSession().Return(session, nil)
+
+	var (
+		rpOpts = testRepairOptions(ctrl).
+			SetAdminClient(mockClient).
+			SetPeerSelection(repair.SingleRandomPeer)
+		opts            = DefaultTestOptions()
+		namespaceID     = ident.StringID("testNamespace")
+		start           = time.Now().Truncate(time.Hour)
+		end             = start.Add(time.Hour)
+		repairTimeRange = xtime.Range{Start: start, End: end}
+		shard           = NewMockdatabaseShard(ctrl)
+	)
+
+	shard.EXPECT().
+		FetchBlocksMetadataV2(gomock.Any(), start, end, gomock.Any(), nil, gomock.Any()).
+		Return(nil, nil, nil)
+	shard.EXPECT().ID().Return(uint32(0)).AnyTimes()
+
+	peerIter := client.NewMockPeerBlockMetadataIter(ctrl)
+	peerIter.EXPECT().Next().Return(false)
+	peerIter.EXPECT().Err().Return(nil)
+
+	var selectPeers client.PeerSelector
+	session.EXPECT().
+		FetchBlocksMetadataFromSelectedPeers(namespaceID, uint32(0), start, end,
+			rpOpts.RepairConsistencyLevel(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ ident.ID,
+			_ uint32,
+			_, _ time.Time,
+			_ topology.ReadConsistencyLevel,
+			fn client.PeerSelector,
+			_ result.Options,
+		) (client.PeerBlockMetadataIter, error) {
+			selectPeers = fn
+			return peerIter, nil
+		})
+
+	repairer := newShardRepairer(opts, rpOpts).(shardRepairer)
+	repairer.recordFn = func(ident.ID, databaseShard, repair.MetadataComparisonResult) {}
+
+	ctx := context.NewContext()
+	nsCtx := namespace.Context{ID: namespaceID}
+	_, err := repairer.Repair(ctx, nsCtx, repairTimeRange, shard)
+	require.NoError(t, err)
+
+	require.NotNil(t, selectPeers)
+	peers := []topology.Host{
+		topology.NewHost("1", "addr1"),
+		topology.NewHost("2", "addr2"),
+	}
+	require.Equal(t, 1, len(selectPeers(0, peers)))
+}
+
 func TestRepairerRepairTimes(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -423,6 +580,76 @@ func TestRepairerRepairWithTime(t *testing.T) {
 	}
 }
 
+func TestRepairerRepairWithTimeDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repairTimeRange := xtime.Range{Start: time.Unix(7200, 0), End: time.Unix(14400, 0)}
+	opts := DefaultTestOptions().SetRepairOptions(testRepairOptions(ctrl).SetDryRun(true))
+	database := NewMockdatabase(ctrl)
+	database.EXPECT().Options().Return(opts).AnyTimes()
+
+	repairer, err := newDatabaseRepairer(database, opts)
+	require.NoError(t, err)
+	r := repairer.(*dbRepairer)
+
+	ns := NewMockdatabaseNamespace(ctrl)
+	id := ident.StringID("foo")
+	nsOpts := namespace.NewMockOptions(ctrl)
+	nsOpts.EXPECT().RetentionOptions().Return(retention.NewOptions())
+	ns.EXPECT().Options().Return(nsOpts)
+	ns.EXPECT().Repair(gomock.Not(nil), repairTimeRange).Return(nil)
+	ns.EXPECT().ID().Return(id).AnyTimes()
+
+	require.NoError(t, r.repairNamespaceWithTimeRange(ns, repairTimeRange))
+
+	// The dry run must not mark the blocks repaired.
+	_, ok := r.repairStatesByNs.repairStates(id, time.Unix(7200, 0))
+	require.False(t, ok)
+	require.True(t, r.needsRepair(id, time.Unix(7200, 0)))
+}
+
+func TestShardRepairerRecordDryRunMismatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		scope  = tally.NewTestScope("", nil)
+		opts   = DefaultTestOptions()
+		iopts  = opts.InstrumentOptions()
+		rpOpts = testRepairOptions(ctrl).SetDryRun(true)
+		start  = time.Unix(7200, 0)
+	)
+	opts = opts.SetInstrumentOptions(iopts.SetMetricsScope(scope))
+	repairer := newShardRepairer(opts, rpOpts).(shardRepairer)
+
+	// The foo block differs in both size and checksum so it must only be
+	// counted once.
+	sizeDiffs := repair.NewReplicaSeriesMetadata()
+	sizeDiffs.GetOrAdd(ident.StringID("foo")).
+		Add(repair.NewReplicaBlockMetadata(start, nil))
+	checksumDiffs := repair.NewReplicaSeriesMetadata()
+	checksumDiffs.GetOrAdd(ident.StringID("foo")).
+		Add(repair.NewReplicaBlockMetadata(start, nil))
+	checksumDiffs.GetOrAdd(ident.StringID("bar")).
+		Add(repair.NewReplicaBlockMetadata(start, nil))
+	checksumDiffs.GetOrAdd(ident.StringID("bar")).
+		Add(repair.NewReplicaBlockMetadata(start.Add(2*time.Hour), nil))
+
+	shard := NewMockdatabaseShard(ctrl)
+	shard.EXPECT().ID().Return(uint32(0)).AnyTimes()
+
+	repairer.recordDryRunMismatches(ident.StringID("testns"), shard,
+		repair.MetadataComparisonResult{
+			SizeDifferences:     sizeDiffs,
+			ChecksumDifferences: checksumDiffs,
+		})
+
+	counter, ok := scope.Snapshot().Counters()["repair.dryrun.mismatches+"]
+	require.True(t, ok)
+	require.Equal(t, int64(3), counter.Value())
+}
+
 func TestRepairerTimesMultipleNamespaces(t *testing.T) {
 	// tf2(i) returns the start time of the i_th 2 hour block since epoch
 	tf2 := func(i int) time.Time {
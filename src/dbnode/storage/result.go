@@ -21,31 +21,35 @@
 package storage
 
 type tickResult struct {
-	activeSeries           int
-	expiredSeries          int
-	activeBlocks           int
-	wiredBlocks            int
-	unwiredBlocks          int
-	pendingMergeBlocks     int
-	madeExpiredBlocks      int
-	madeUnwiredBlocks      int
-	mergedOutOfOrderBlocks int
-	errors                 int
-	evictedBuckets         int
+	activeSeries                int
+	expiredSeries               int
+	activeBlocks                int
+	wiredBlocks                 int
+	unwiredBlocks               int
+	pendingMergeBlocks          int
+	madeExpiredBlocks           int
+	madeUnwiredBlocks           int
+	mergedOutOfOrderBlocks      int
+	errors                      int
+	evictedBuckets              int
+	estimatedMemoryBytes        int64
+	skippedUnbootstrappedBlocks int
 }
 
 func (r tickResult) merge(other tickResult) tickResult {
 	return tickResult{
-		activeSeries:           r.activeSeries + other.activeSeries,
-		expiredSeries:          r.expiredSeries + other.expiredSeries,
-		activeBlocks:           r.activeBlocks + other.activeBlocks,
-		wiredBlocks:            r.wiredBlocks + other.wiredBlocks,
-		pendingMergeBlocks:     r.pendingMergeBlocks + other.pendingMergeBlocks,
-		unwiredBlocks:          r.unwiredBlocks + other.unwiredBlocks,
-		madeExpiredBlocks:      r.madeExpiredBlocks + other.madeExpiredBlocks,
-		madeUnwiredBlocks:      r.madeUnwiredBlocks + other.madeUnwiredBlocks,
-		mergedOutOfOrderBlocks: r.mergedOutOfOrderBlocks + other.mergedOutOfOrderBlocks,
-		errors:                 r.errors + other.errors,
-		evictedBuckets:         r.evictedBuckets + other.evictedBuckets,
+		activeSeries:                r.activeSeries + other.activeSeries,
+		expiredSeries:               r.expiredSeries + other.expiredSeries,
+		activeBlocks:                r.activeBlocks + other.activeBlocks,
+		wiredBlocks:                 r.wiredBlocks + other.wiredBlocks,
+		pendingMergeBlocks:          r.pendingMergeBlocks + other.pendingMergeBlocks,
+		unwiredBlocks:               r.unwiredBlocks + other.unwiredBlocks,
+		madeExpiredBlocks:           r.madeExpiredBlocks + other.madeExpiredBlocks,
+		madeUnwiredBlocks:           r.madeUnwiredBlocks + other.madeUnwiredBlocks,
+		mergedOutOfOrderBlocks:      r.mergedOutOfOrderBlocks + other.mergedOutOfOrderBlocks,
+		errors:                      r.errors + other.errors,
+		evictedBuckets:              r.evictedBuckets + other.evictedBuckets,
+		estimatedMemoryBytes:        r.estimatedMemoryBytes + other.estimatedMemoryBytes,
+		skippedUnbootstrappedBlocks: r.skippedUnbootstrappedBlocks + other.skippedUnbootstrappedBlocks,
 	}
 }
@@ -23,6 +23,7 @@ package series
 import (
 	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"sync/atomic"
 	"time"
@@ -37,7 +38,6 @@ import (
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/x/context"
-	xerrors "github.com/m3db/m3/src/x/errors"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/instrument"
 	"github.com/m3db/m3/src/x/pool"
@@ -108,10 +108,14 @@ type databaseBuffer interface {
 		nsCtx namespace.Context,
 	) ([][]xio.BlockReader, error)
 
+	MarkColdFlushVersion(
+		start time.Time,
+		version int,
+	) (bool, error)
+
 	FetchBlocksForColdFlush(
 		ctx context.Context,
 		start time.Time,
-		version int,
 		nsCtx namespace.Context,
 	) ([]xio.BlockReader, error)
 
@@ -121,6 +125,13 @@ type databaseBuffer interface {
 		nsCtx namespace.Context,
 	) []block.FetchBlockResult
 
+	FetchBlocksVersioned(
+		ctx context.Context,
+		starts []time.Time,
+		version int,
+		nsCtx namespace.Context,
+	) []block.FetchBlockResult
+
 	FetchBlocksMetadata(
 		ctx context.Context,
 		start, end time.Time,
@@ -131,12 +142,20 @@ type databaseBuffer interface {
 
 	ColdFlushBlockStarts(blockStates map[xtime.UnixNano]BlockState) OptimizedTimes
 
+	BlockStarts() OptimizedTimes
+
+	DebugInfo() []BufferBlockDebugInfo
+
 	Stats() bufferStats
 
+	EstimatedMemoryBytes() int64
+
 	Tick(versions ShardBlockStateSnapshot, nsCtx namespace.Context) bufferTickResult
 
 	Load(bl block.DatabaseBlock, writeType WriteType)
 
+	LoadWarmRetrievable(bl block.DatabaseBlock)
+
 	Reset(id ident.ID, opts Options)
 }
 
@@ -230,6 +249,8 @@ type dbBuffer struct {
 	coldWritesEnabled     bool
 	retentionPeriod       time.Duration
 	futureRetentionPeriod time.Duration
+	maxWriteFutureSkew    time.Duration
+	compressionLevel      namespace.CompressionLevel
 }
 
 // NB(prateek): databaseBuffer.Reset(...) must be called upon the returned
@@ -255,6 +276,8 @@ func (b *dbBuffer) Reset(id ident.ID, opts Options) {
 	b.coldWritesEnabled = opts.ColdWritesEnabled()
 	b.retentionPeriod = ropts.RetentionPeriod()
 	b.futureRetentionPeriod = ropts.FutureRetentionPeriod()
+	b.maxWriteFutureSkew = opts.MaxWriteFutureSkew()
+	b.compressionLevel = opts.CompressionLevel()
 }
 
 func (b *dbBuffer) Write(
@@ -271,11 +294,23 @@ func (b *dbBuffer) Write(
 		futureLimit = now.Add(b.bufferFuture)
 		writeType   WriteType
 	)
+	if skew := timestamp.Sub(now); b.maxWriteFutureSkew > 0 && skew > b.maxWriteFutureSkew {
+		// Reject points from clients with badly skewed clocks regardless of
+		// whether cold writes are enabled, they would otherwise distort
+		// tick and expiry.
+		b.opts.Stats().incWriteFutureSkewRejected()
+		return false, m3dberrors.NewClockSkewError(skew, b.maxWriteFutureSkew)
+	}
+	value, err := transformValue(value, wOpts.TransformOptions, b.opts.Stats())
+	if err != nil {
+		return false, err
+	}
+
 	switch {
 	case !pastLimit.Before(timestamp):
 		writeType = ColdWrite
 		if !b.coldWritesEnabled {
-			return false, xerrors.NewInvalidParamsError(
+			return false, m3dberrors.NewColdWritesDisabledError(
 				fmt.Errorf("datapoint too far in past: "+
 					"id=%s, off_by=%s, timestamp=%s, past_limit=%s, "+
 					"timestamp_unix_nanos=%d, past_limit_unix_nanos=%d",
@@ -287,7 +322,7 @@ func (b *dbBuffer) Write(
 	case !futureLimit.After(timestamp):
 		writeType = ColdWrite
 		if !b.coldWritesEnabled {
-			return false, xerrors.NewInvalidParamsError(
+			return false, m3dberrors.NewColdWritesDisabledError(
 				fmt.Errorf("datapoint too far in future: "+
 					"id=%s, off_by=%s, timestamp=%s, future_limit=%s, "+
 					"timestamp_unix_nanos=%d, future_limit_unix_nanos=%d",
@@ -327,6 +362,45 @@ func (b *dbBuffer) Write(
 	return buckets.write(timestamp, value, unit, annotation, writeType, wOpts.SchemaDesc)
 }
 
+// transformValue rounds the value and validates it against the value bounds
+// of the write transform options, the forced value is applied separately.
+func transformValue(
+	value float64,
+	tOpts WriteTransformOptions,
+	stats Stats,
+) (float64, error) {
+	if m := tOpts.RoundToMultiple; m > 0 {
+		// Round before validating the value bounds so that rounding can't
+		// produce an out of bounds value.
+		value = roundToMultiple(value, m)
+	}
+	if tOpts.ValueBoundsEnabled &&
+		(value < tOpts.MinValue || value > tOpts.MaxValue) {
+		if !tOpts.ClampValues {
+			stats.incValueBoundsRejected()
+			return 0, m3dberrors.NewValueOutOfBoundsError(value,
+				tOpts.MinValue, tOpts.MaxValue)
+		}
+		stats.incValueBoundsClamped()
+		value = math.Max(tOpts.MinValue, math.Min(value, tOpts.MaxValue))
+	}
+	return value, nil
+}
+
+// roundToMultiple rounds the value to the nearest multiple, halfway values
+// are rounded away from zero. NaN and infinite values, as well as values too
+// large to be divided by the multiple, are returned as is.
+func roundToMultiple(value, multiple float64) float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return value
+	}
+	quotient := value / multiple
+	if math.IsInf(quotient, 0) {
+		return value
+	}
+	return math.Round(quotient) * multiple
+}
+
 func (b *dbBuffer) IsEmpty() bool {
 	// A buffer can only be empty if there are no buckets in its map, since
 	// buckets are only created when a write for a new block start is done, and
@@ -359,12 +433,54 @@ func (b *dbBuffer) ColdFlushBlockStarts(blockStates map[xtime.UnixNano]BlockStat
 	return times
 }
 
+func (b *dbBuffer) BlockStarts() OptimizedTimes {
+	var times OptimizedTimes
+	for t := range b.bucketsMap {
+		times.Add(t)
+	}
+	return times
+}
+
+func (b *dbBuffer) DebugInfo() []BufferBlockDebugInfo {
+	result := make([]BufferBlockDebugInfo, 0, len(b.bucketsMap))
+	for _, bucketVersions := range b.bucketsMap {
+		info := BufferBlockDebugInfo{
+			BlockStart: bucketVersions.start,
+			Buckets:    make([]BufferBucketDebugInfo, 0, len(bucketVersions.buckets)),
+		}
+		for _, bucket := range bucketVersions.buckets {
+			info.Buckets = append(info.Buckets, BufferBucketDebugInfo{
+				Version:         bucket.version,
+				WriteType:       bucket.writeType,
+				NumEncoders:     len(bucket.encoders),
+				NumLoadedBlocks: len(bucket.loadedBlocks),
+			})
+		}
+		result = append(result, info)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].BlockStart.Before(result[j].BlockStart)
+	})
+	return result
+}
+
 func (b *dbBuffer) Stats() bufferStats {
 	return bufferStats{
 		wiredBlocks: len(b.bucketsMap),
 	}
 }
 
+func (b *dbBuffer) EstimatedMemoryBytes() int64 {
+	var total int64
+	for _, bucketVersions := range b.bucketsMap {
+		for _, bucket := range bucketVersions.buckets {
+			total += bucket.estimatedMemoryBytes()
+		}
+	}
+	return total
+}
+
 func (b *dbBuffer) Tick(blockStates ShardBlockStateSnapshot, nsCtx namespace.Context) bufferTickResult {
 	mergedOutOfOrder := 0
 	var evictedBucketTimes OptimizedTimes
@@ -416,7 +532,7 @@ func (b *dbBuffer) Tick(blockStates ShardBlockStateSnapshot, nsCtx namespace.Con
 
 		// Once we've evicted all eligible buckets, we merge duplicate encoders
 		// in the remaining ones to try and reclaim memory.
-		merges, err := buckets.merge(WarmWrite, nsCtx)
+		merges, err := buckets.merge(WarmWrite, mergeTriggerTick, nsCtx)
 		if err != nil {
 			log := b.opts.InstrumentOptions().Logger()
 			log.Error("buffer merge encode error", zap.Error(err))
@@ -440,6 +556,18 @@ func (b *dbBuffer) Load(bl block.DatabaseBlock, writeType WriteType) {
 	bucket.loadedBlocks = append(bucket.loadedBlocks, bl)
 }
 
+// LoadWarmRetrievable loads a block for a block start that has already been
+// warm flushed into the warm flushed bucket version, the block will never be
+// warm flushed again so the bucket is evicted on the next tick instead.
+func (b *dbBuffer) LoadWarmRetrievable(bl block.DatabaseBlock) {
+	var (
+		blockStart = bl.StartTime()
+		buckets    = b.bucketVersionsAtCreate(blockStart)
+		bucket     = buckets.warmFlushedBucketCreate()
+	)
+	bucket.loadedBlocks = append(bucket.loadedBlocks, bl)
+}
+
 func (b *dbBuffer) Snapshot(
 	ctx context.Context,
 	blockStart time.Time,
@@ -456,7 +584,11 @@ func (b *dbBuffer) Snapshot(
 	// Snapshot must take both cold and warm writes because cold flushes don't
 	// happen for the current block (since cold flushes can't happen before a
 	// warm flush has happened).
-	streams, err := buckets.mergeToStreams(ctx, streamsOptions{filterWriteType: false, nsCtx: nsCtx})
+	streams, err := buckets.mergeToStreams(ctx, streamsOptions{
+		filterWriteType: false,
+		mergeTrigger:    mergeTriggerSnapshot,
+		nsCtx:           nsCtx,
+	})
 	if err != nil {
 		return err
 	}
@@ -492,6 +624,9 @@ func (b *dbBuffer) Snapshot(
 		if err := iter.Err(); err != nil {
 			return err
 		}
+		if numStreams > 1 {
+			b.opts.Stats().incMerges(mergeTriggerSnapshot, numStreams)
+		}
 
 		var ok bool
 		mergedStream, ok = encoder.Stream(encoding.StreamOptions{})
@@ -530,7 +665,12 @@ func (b *dbBuffer) WarmFlush(
 
 	// Flush only deals with WarmWrites. ColdWrites get persisted to disk via
 	// the compaction cycle.
-	streams, err := buckets.mergeToStreams(ctx, streamsOptions{filterWriteType: true, writeType: WarmWrite, nsCtx: nsCtx})
+	streams, err := buckets.mergeToStreams(ctx, streamsOptions{
+		filterWriteType: true,
+		writeType:       WarmWrite,
+		mergeTrigger:    mergeTriggerFlush,
+		nsCtx:           nsCtx,
+	})
 	if err != nil {
 		return FlushOutcomeErr, err
 	}
@@ -552,6 +692,9 @@ func (b *dbBuffer) WarmFlush(
 		if err != nil {
 			return FlushOutcomeErr, err
 		}
+		if numStreams > 1 {
+			b.opts.Stats().incMerges(mergeTriggerFlush, numStreams)
+		}
 
 		stream, ok = encoder.Stream(encoding.StreamOptions{})
 		encoder.Close()
@@ -572,6 +715,22 @@ func (b *dbBuffer) WarmFlush(
 		return FlushOutcomeBlockDoesNotExist, nil
 	}
 
+	// Proto encoded blocks do not benefit from time unit compaction so are
+	// always persisted as is.
+	if b.compressionLevel == namespace.HighCompressionLevel && nsCtx.Schema == nil {
+		compacted, err := compactSegment(blockStart, segment, b.opts, nsCtx)
+		if err != nil {
+			return FlushOutcomeErr, err
+		}
+		b.opts.Stats().recordFlushCompression(segment.Len(), compacted.Len())
+		if compacted.Len() < segment.Len() {
+			segment = compacted
+			defer segment.Finalize()
+		} else {
+			compacted.Finalize()
+		}
+	}
+
 	checksum := digest.SegmentChecksum(segment)
 	err = persistFn(id, tags, segment, checksum)
 	if err != nil {
@@ -629,19 +788,49 @@ func (b *dbBuffer) ReadEncoded(
 	return res, nil
 }
 
-func (b *dbBuffer) FetchBlocksForColdFlush(
-	ctx context.Context,
+// MarkColdFlushVersion sets the version of the writable cold bucket at the
+// block start so that subsequent cold writes go to a new bucket. It returns
+// false if there is no cold data to flush at the block start. This is the
+// only part of a cold flush that mutates the buffer, the blocks of the marked
+// buckets can then be fetched with FetchBlocksForColdFlush.
+func (b *dbBuffer) MarkColdFlushVersion(
 	start time.Time,
 	version int,
-	nsCtx namespace.Context,
-) ([]xio.BlockReader, error) {
-	res := b.fetchBlocks(ctx, []time.Time{start},
-		streamsOptions{filterWriteType: true, writeType: ColdWrite, nsCtx: nsCtx})
-	if len(res) == 0 {
+) (bool, error) {
+	buckets, exists := b.bucketVersionsAt(start)
+	if !exists || buckets.streamsLenWithWriteType(ColdWrite) == 0 {
 		// The lifecycle of calling this function is preceded by first checking
 		// which blocks have cold data that have not yet been flushed.
 		// If we don't get data here, it means that it has since fallen out of
 		// retention and has been evicted.
+		return false, nil
+	}
+
+	bucket, exists := buckets.writableBucket(ColdWrite)
+	if !exists {
+		return false, fmt.Errorf("writable bucket does not exist with block start %s", start)
+	}
+	bucket.version = version
+
+	return true, nil
+}
+
+// FetchBlocksForColdFlush returns the blocks of the cold buckets at the block
+// start that have been marked by MarkColdFlushVersion, it does not mutate the
+// buffer so it is safe to call with concurrent readers.
+func (b *dbBuffer) FetchBlocksForColdFlush(
+	ctx context.Context,
+	start time.Time,
+	nsCtx namespace.Context,
+) ([]xio.BlockReader, error) {
+	res := b.fetchBlocks(ctx, []time.Time{start}, streamsOptions{
+		filterWriteType: true,
+		writeType:       ColdWrite,
+		excludeWritable: true,
+		nsCtx:           nsCtx,
+	})
+	if len(res) == 0 {
+		// The block start may have been evicted since it was marked.
 		return nil, nil
 	}
 	if len(res) != 1 {
@@ -650,25 +839,42 @@ func (b *dbBuffer) FetchBlocksForColdFlush(
 		return nil, fmt.Errorf("fetchBlocks did not return just one block for block start %s", start)
 	}
 
-	blocks := res[0].Blocks
-
-	buckets, exists := b.bucketVersionsAt(start)
-	if !exists {
-		return nil, fmt.Errorf("buckets do not exist with block start %s", start)
-	}
-	if bucket, exists := buckets.writableBucket(ColdWrite); exists {
-		bucket.version = version
-	} else {
-		return nil, fmt.Errorf("writable bucket does not exist with block start %s", start)
-	}
-
-	return blocks, nil
+	return res[0].Blocks, nil
 }
 
 func (b *dbBuffer) FetchBlocks(ctx context.Context, starts []time.Time, nsCtx namespace.Context) []block.FetchBlockResult {
 	return b.fetchBlocks(ctx, starts, streamsOptions{filterWriteType: false, nsCtx: nsCtx})
 }
 
+func (b *dbBuffer) FetchBlocksVersioned(
+	ctx context.Context,
+	starts []time.Time,
+	version int,
+	nsCtx namespace.Context,
+) []block.FetchBlockResult {
+	res := make([]block.FetchBlockResult, 0, len(starts))
+	for _, start := range starts {
+		var streams []xio.BlockReader
+		if buckets, ok := b.bucketVersionsAt(start); ok {
+			streams = buckets.streams(ctx, streamsOptions{
+				filterVersion: true,
+				version:       version,
+				nsCtx:         nsCtx,
+			})
+		}
+		if len(streams) == 0 {
+			res = append(res, block.NewFetchBlockResult(start, nil, ErrBlockVersionNotFound))
+			continue
+		}
+		res = append(res, block.NewFetchBlockResult(start, streams, nil))
+	}
+
+	// Result should be sorted in ascending order.
+	sort.Slice(res, func(i, j int) bool { return res[i].Start.Before(res[j].Start) })
+
+	return res
+}
+
 func (b *dbBuffer) fetchBlocks(
 	ctx context.Context,
 	starts []time.Time,
@@ -724,6 +930,9 @@ func (b *dbBuffer) FetchBlocksMetadata(
 		}
 		var resultSize int64
 		if opts.IncludeSizes {
+			if size < opts.MinBlockSizeBytes {
+				continue
+			}
 			resultSize = size
 		}
 		var resultLastRead time.Time
@@ -820,6 +1029,12 @@ func (b *dbBuffer) removeBucketVersionsAt(blockStart time.Time) {
 	delete(b.bucketsMap, xtime.ToUnixNano(blockStart))
 	b.removeBucketVersionsInCache(buckets)
 	b.inOrderBlockStartsRemove(blockStart)
+	if b.opts.RecycleEvictedEncoders() {
+		for _, bucket := range buckets.buckets {
+			buckets.recycleEncoders(bucket)
+			b.bucketPool.Put(bucket)
+		}
+	}
 	// nil out pointers.
 	buckets.resetTo(timeZero, nil, nil)
 	b.bucketVersionsPool.Put(buckets)
@@ -885,13 +1100,32 @@ func (b *BufferBucketVersions) resetTo(
 	b.bucketPool = bucketPool
 }
 
+// recycleEncoders resets an evicted bucket, returning its encoders to the
+// encoder pool, if recycling evicted encoders is enabled. Otherwise the
+// encoders are only released once the bucket is reused.
+func (b *BufferBucketVersions) recycleEncoders(bucket *BufferBucket) {
+	if !b.opts.RecycleEvictedEncoders() {
+		return
+	}
+	numEncoders := len(bucket.encoders)
+	bucket.reset()
+	b.opts.Stats().incEncodersRecycled(numEncoders)
+}
+
 // streams returns all the streams for this BufferBucketVersions.
 func (b *BufferBucketVersions) streams(ctx context.Context, opts streamsOptions) []xio.BlockReader {
 	var res []xio.BlockReader
 	for _, bucket := range b.buckets {
-		if !opts.filterWriteType || bucket.writeType == opts.writeType {
-			res = append(res, bucket.streams(ctx)...)
+		if opts.filterWriteType && bucket.writeType != opts.writeType {
+			continue
 		}
+		if opts.filterVersion && bucket.version != opts.version {
+			continue
+		}
+		if opts.excludeWritable && bucket.version == writableBucketVersion {
+			continue
+		}
+		res = append(res, bucket.streams(ctx)...)
 	}
 
 	return res
@@ -905,6 +1139,16 @@ func (b *BufferBucketVersions) streamsLen() int {
 	return res
 }
 
+func (b *BufferBucketVersions) streamsLenWithWriteType(writeType WriteType) int {
+	res := 0
+	for _, bucket := range b.buckets {
+		if bucket.writeType == writeType {
+			res += bucket.streamsLen()
+		}
+	}
+	return res
+}
+
 func (b *BufferBucketVersions) write(
 	timestamp time.Time,
 	value float64,
@@ -916,12 +1160,16 @@ func (b *BufferBucketVersions) write(
 	return b.writableBucketCreate(writeType).write(timestamp, value, unit, annotation, schema)
 }
 
-func (b *BufferBucketVersions) merge(writeType WriteType, nsCtx namespace.Context) (int, error) {
+func (b *BufferBucketVersions) merge(
+	writeType WriteType,
+	trigger mergeTrigger,
+	nsCtx namespace.Context,
+) (int, error) {
 	res := 0
 	for _, bucket := range b.buckets {
 		// Only makes sense to merge buckets that are writable.
 		if bucket.version == writableBucketVersion && writeType == bucket.writeType {
-			merges, err := bucket.merge(nsCtx)
+			merges, err := bucket.merge(trigger, nsCtx)
 			if err != nil {
 				return 0, err
 			}
@@ -947,6 +1195,7 @@ func (b *BufferBucketVersions) removeBucketsUpToVersion(
 			// or less than the retrievable version, since that means
 			// that the version has successfully persisted to disk.
 			// Bucket gets reset before use.
+			b.recycleEncoders(bucket)
 			b.bucketPool.Put(bucket)
 			continue
 		}
@@ -988,6 +1237,22 @@ func (b *BufferBucketVersions) writableBucketCreate(writeType WriteType) *Buffer
 	return newBucket
 }
 
+func (b *BufferBucketVersions) warmFlushedBucketCreate() *BufferBucket {
+	for _, bucket := range b.buckets {
+		if bucket.version == 1 && bucket.writeType == WarmWrite {
+			return bucket
+		}
+	}
+
+	newBucket := b.bucketPool.Get()
+	newBucket.resetTo(b.start, WarmWrite, b.opts)
+	// WarmFlushes only happen once per block so the warm flushed version is
+	// always 1, see WarmFlush.
+	newBucket.version = 1
+	b.buckets = append(b.buckets, newBucket)
+	return newBucket
+}
+
 // mergeToStreams merges each buffer bucket version's streams into one, then
 // returns a single stream for each buffer bucket version.
 func (b *BufferBucketVersions) mergeToStreams(ctx context.Context, opts streamsOptions) ([]xio.SegmentReader, error) {
@@ -996,7 +1261,7 @@ func (b *BufferBucketVersions) mergeToStreams(ctx context.Context, opts streamsO
 
 	for _, bucket := range buckets {
 		if !opts.filterWriteType || bucket.writeType == opts.writeType {
-			stream, ok, err := bucket.mergeToStream(ctx, opts.nsCtx)
+			stream, ok, err := bucket.mergeToStream(ctx, opts.mergeTrigger, opts.nsCtx)
 			if err != nil {
 				return nil, err
 			}
@@ -1013,9 +1278,39 @@ func (b *BufferBucketVersions) mergeToStreams(ctx context.Context, opts streamsO
 type streamsOptions struct {
 	filterWriteType bool
 	writeType       WriteType
+	filterVersion   bool
+	version         int
+	excludeWritable bool
+	mergeTrigger    mergeTrigger
 	nsCtx           namespace.Context
 }
 
+// mergeTrigger describes what caused a merge of a bucket's encoders.
+type mergeTrigger int
+
+const (
+	mergeTriggerTick mergeTrigger = iota
+	mergeTriggerSnapshot
+	mergeTriggerFlush
+	mergeTriggerEncoderLimit
+
+	numMergeTriggers = iota
+)
+
+func (t mergeTrigger) String() string {
+	switch t {
+	case mergeTriggerTick:
+		return "tick"
+	case mergeTriggerSnapshot:
+		return "snapshot"
+	case mergeTriggerFlush:
+		return "flush"
+	case mergeTriggerEncoderLimit:
+		return "encoder-limit"
+	}
+	return "unknown"
+}
+
 // BufferBucket is a specific version of a bucket of encoders, which is where
 // writes are ultimately stored before they are persisted to disk as a fileset.
 // See comment for BufferBucketVersions for more detail on bucket versions.
@@ -1033,6 +1328,19 @@ type inOrderEncoder struct {
 	lastWriteAt time.Time
 }
 
+// estimatedMemoryBytes returns the number of bytes held by the encoders and
+// loaded blocks of the bucket.
+func (b *BufferBucket) estimatedMemoryBytes() int64 {
+	var total int64
+	for _, e := range b.encoders {
+		total += int64(e.encoder.Len())
+	}
+	for _, bl := range b.loadedBlocks {
+		total += int64(bl.Len())
+	}
+	return total
+}
+
 func (b *BufferBucket) resetTo(
 	start time.Time,
 	writeType WriteType,
@@ -1125,6 +1433,16 @@ func (b *BufferBucket) write(
 		b.encoders = b.encoders[:idx]
 		return false, err
 	}
+
+	b.opts.Stats().recordEncodersPerBucket(len(b.encoders))
+	if limit := b.opts.MaxEncodersPerBucket(); limit > 0 && len(b.encoders) > limit {
+		// Merge inline to bound the read amplification of many out of order
+		// encoders. The write itself has already succeeded and a failed merge
+		// leaves the encoders untouched, so they are merged on the next tick.
+		if _, err := b.merge(mergeTriggerEncoderLimit, namespace.Context{Schema: schema}); err != nil {
+			b.opts.Stats().incInlineMergeErrors()
+		}
+	}
 	return true, nil
 }
 
@@ -1217,7 +1535,7 @@ func (b *BufferBucket) hasJustSingleLoadedBlock() bool {
 	return encodersEmpty && len(b.loadedBlocks) == 1
 }
 
-func (b *BufferBucket) merge(nsCtx namespace.Context) (int, error) {
+func (b *BufferBucket) merge(trigger mergeTrigger, nsCtx namespace.Context) (int, error) {
 	if !b.needsMerge() {
 		// Save unnecessary work
 		return 0, nil
@@ -1271,6 +1589,7 @@ func (b *BufferBucket) merge(nsCtx namespace.Context) (int, error) {
 		lastWriteAt: lastWriteAt,
 	})
 
+	b.opts.Stats().incMerges(trigger, merges)
 	return merges, nil
 }
 
@@ -1307,9 +1626,38 @@ func mergeStreamsToEncoder(
 	return encoder, lastWriteAt, nil
 }
 
+// compactSegment re-encodes a segment with the coarsest time unit that
+// exactly represents all of its timestamps, the caller owns the returned
+// segment and is responsible for finalizing it.
+func compactSegment(
+	blockStart time.Time,
+	segment ts.Segment,
+	opts Options,
+	nsCtx namespace.Context,
+) (ts.Segment, error) {
+	bopts := opts.DatabaseBlockOptions()
+	encoder := opts.EncoderPool().Get()
+	encoder.Reset(blockStart, bopts.DatabaseBlockAllocSize(), nsCtx.Schema)
+	iter := opts.MultiReaderIteratorPool().Get()
+	defer iter.Close()
+
+	iter.Reset([]xio.SegmentReader{xio.NewSegmentReader(segment)},
+		blockStart, opts.RetentionOptions().BlockSize(), nsCtx.Schema)
+	if err := encoding.EncodeCompacted(iter, encoder); err != nil {
+		encoder.Close()
+		return ts.Segment{}, err
+	}
+
+	return encoder.Discard(), nil
+}
+
 // mergeToStream merges all streams in this BufferBucket into one stream and
 // returns it.
-func (b *BufferBucket) mergeToStream(ctx context.Context, nsCtx namespace.Context) (xio.SegmentReader, bool, error) {
+func (b *BufferBucket) mergeToStream(
+	ctx context.Context,
+	trigger mergeTrigger,
+	nsCtx namespace.Context,
+) (xio.SegmentReader, bool, error) {
 	if b.hasJustSingleEncoder() {
 		b.resetLoadedBlocks()
 		// Already merged as a single encoder.
@@ -1332,7 +1680,7 @@ func (b *BufferBucket) mergeToStream(ctx context.Context, nsCtx namespace.Contex
 		return stream, true, nil
 	}
 
-	_, err := b.merge(nsCtx)
+	_, err := b.merge(trigger, nsCtx)
 	if err != nil {
 		b.resetEncoders()
 		b.resetLoadedBlocks()
@@ -21,15 +21,19 @@
 package series
 
 import (
+	"errors"
 	"io"
+	"math"
 	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/x/checked"
@@ -42,6 +46,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 )
 
 func newBufferTestOptions() Options {
@@ -108,6 +113,156 @@ func TestBufferWriteTooFuture(t *testing.T) {
 	assert.True(t, strings.Contains(err.Error(), "future_limit="))
 }
 
+func TestBufferWriteExceedsMaxFutureSkew(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	opts := newBufferTestOptions().
+		SetColdWritesEnabled(true).
+		SetMaxWriteFutureSkew(time.Minute).
+		SetStats(NewStats(scope))
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer().(*dbBuffer)
+	buffer.Reset(ident.StringID("foo"), opts)
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	// Within the tolerance the write is accepted as a cold write.
+	wasWritten, err := buffer.Write(ctx, curr.Add(30*time.Second), 1,
+		xtime.Second, nil, WriteOptions{})
+	require.NoError(t, err)
+	assert.True(t, wasWritten)
+
+	wasWritten, err = buffer.Write(ctx, curr.Add(2*time.Minute), 1,
+		xtime.Second, nil, WriteOptions{})
+	assert.False(t, wasWritten)
+	assert.True(t, xerrors.IsInvalidParams(err))
+	assert.True(t, m3dberrors.IsClockSkewError(err))
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(1), counters["series.write-future-skew-rejected+"].Value())
+}
+
+func TestBufferWriteValueBounds(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	opts := newBufferTestOptions().SetStats(NewStats(scope))
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer().(*dbBuffer)
+	buffer.Reset(ident.StringID("foo"), opts)
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	writeOpts := WriteOptions{
+		TransformOptions: WriteTransformOptions{
+			ValueBoundsEnabled: true,
+			MinValue:           0,
+			MaxValue:           100,
+		},
+	}
+	wasWritten, err := buffer.Write(ctx, curr, -1, xtime.Second, nil, writeOpts)
+	assert.False(t, wasWritten)
+	assert.True(t, xerrors.IsInvalidParams(err))
+	assert.True(t, m3dberrors.IsValueOutOfBoundsError(err))
+
+	wasWritten, err = buffer.Write(ctx, curr, 50, xtime.Second, nil, writeOpts)
+	require.NoError(t, err)
+	assert.True(t, wasWritten)
+
+	writeOpts.TransformOptions.ClampValues = true
+	wasWritten, err = buffer.Write(ctx, curr.Add(secs(1)), 200, xtime.Second,
+		nil, writeOpts)
+	require.NoError(t, err)
+	assert.True(t, wasWritten)
+
+	results, err := buffer.ReadEncoded(ctx, timeZero, timeDistantFuture, namespace.Context{})
+	assert.NoError(t, err)
+	assert.NotNil(t, results)
+
+	requireReaderValuesEqual(t, []value{
+		{curr, 50, xtime.Second, nil},
+		{curr.Add(secs(1)), 100, xtime.Second, nil},
+	}, results, opts, namespace.Context{})
+
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(1), counters["series.value-bounds-rejected+"].Value())
+	assert.Equal(t, int64(1), counters["series.value-bounds-clamped+"].Value())
+}
+
+func TestBufferWriteRoundToMultiple(t *testing.T) {
+	opts := newBufferTestOptions()
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer().(*dbBuffer)
+	buffer.Reset(ident.StringID("foo"), opts)
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	writeOpts := WriteOptions{
+		TransformOptions: WriteTransformOptions{
+			RoundToMultiple: 0.5,
+		},
+	}
+	data := []value{
+		{curr, 1.2, xtime.Second, nil},
+		{curr.Add(secs(1)), -1.3, xtime.Second, nil},
+		{curr.Add(secs(2)), 1.75, xtime.Second, nil},
+	}
+	for _, v := range data {
+		wasWritten, err := buffer.Write(ctx, v.timestamp, v.value, v.unit,
+			v.annotation, writeOpts)
+		require.NoError(t, err)
+		require.True(t, wasWritten)
+	}
+
+	results, err := buffer.ReadEncoded(ctx, timeZero, timeDistantFuture, namespace.Context{})
+	assert.NoError(t, err)
+	assert.NotNil(t, results)
+
+	requireReaderValuesEqual(t, []value{
+		{curr, 1.0, xtime.Second, nil},
+		{curr.Add(secs(1)), -1.5, xtime.Second, nil},
+		{curr.Add(secs(2)), 2.0, xtime.Second, nil},
+	}, results, opts, namespace.Context{})
+}
+
+func TestRoundToMultiple(t *testing.T) {
+	tests := []struct {
+		value    float64
+		multiple float64
+		expected float64
+	}{
+		{value: 1.2, multiple: 0.5, expected: 1.0},
+		{value: 1.25, multiple: 0.5, expected: 1.5},
+		{value: -1.2, multiple: 0.5, expected: -1.0},
+		{value: -1.25, multiple: 0.5, expected: -1.5},
+		{value: 1.0, multiple: 0.3, expected: 0.9},
+		{value: -1.0, multiple: 0.3, expected: -0.9},
+		{value: 7, multiple: 3, expected: 6},
+		{value: -8, multiple: 3, expected: -9},
+		{value: 3.14159, multiple: 0.01, expected: 3.14},
+		{value: 0, multiple: 0.5, expected: 0},
+	}
+	for _, test := range tests {
+		actual := roundToMultiple(test.value, test.multiple)
+		assert.InDelta(t, test.expected, actual, 1e-9,
+			"value=%v, multiple=%v", test.value, test.multiple)
+	}
+
+	assert.True(t, math.IsNaN(roundToMultiple(math.NaN(), 0.5)))
+	assert.True(t, math.IsInf(roundToMultiple(math.Inf(1), 0.5), 1))
+	assert.True(t, math.IsInf(roundToMultiple(math.Inf(-1), 0.5), -1))
+	assert.Equal(t, math.MaxFloat64, roundToMultiple(math.MaxFloat64, 1e-10))
+}
+
 func TestBufferWriteTooPast(t *testing.T) {
 	opts := newBufferTestOptions()
 	rops := opts.RetentionOptions()
@@ -274,6 +429,112 @@ func TestBufferWriteOutOfOrder(t *testing.T) {
 	requireReaderValuesEqual(t, data, results, opts, namespace.Context{})
 }
 
+func TestBufferWriteOutOfOrderMaxEncodersPerBucket(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	opts := newBufferTestOptions().
+		SetMaxEncodersPerBucket(2).
+		SetStats(NewStats(scope))
+	rops := opts.RetentionOptions()
+	start := time.Now().Truncate(rops.BlockSize())
+	curr := start
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer().(*dbBuffer)
+	buffer.Reset(ident.StringID("foo"), opts)
+
+	// Each write is older than the last so requires a new encoder.
+	data := []value{
+		{curr.Add(secs(10)), 1, xtime.Second, nil},
+		{curr.Add(secs(8)), 2, xtime.Second, nil},
+		{curr.Add(secs(6)), 3, xtime.Second, nil},
+	}
+	curr = data[0].timestamp
+	for _, v := range data {
+		verifyWriteToBuffer(t, buffer, v, nil)
+	}
+
+	// The third encoder exceeded the limit and triggered an inline merge.
+	buckets, ok := buffer.bucketVersionsAt(start)
+	require.True(t, ok)
+	bucket, ok := buckets.writableBucket(WarmWrite)
+	require.True(t, ok)
+	require.Len(t, bucket.encoders, 1)
+
+	snapshot := scope.Snapshot()
+	assert.Equal(t, 3.0, snapshot.Gauges()["series.max-encoders-per-bucket+"].Value())
+	assert.Equal(t, int64(1),
+		snapshot.Counters()["series.encoder-merges+trigger=encoder-limit"].Value())
+
+	sort.Sort(valuesByTime(data))
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	results, err := buffer.ReadEncoded(ctx, timeZero, timeDistantFuture, namespace.Context{})
+	require.NoError(t, err)
+	requireReaderValuesEqual(t, data, results, opts, namespace.Context{})
+}
+
+func TestBufferWriteOutOfOrderMaxEncodersPerBucketMergeError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scope := tally.NewTestScope("", nil)
+	opts := newBufferTestOptions().
+		SetMaxEncodersPerBucket(2).
+		SetStats(NewStats(scope))
+	rops := opts.RetentionOptions()
+	start := time.Now().Truncate(rops.BlockSize())
+	curr := start
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+
+	// Fail the inline merge when iterating over the encoders.
+	iter := encoding.NewMockMultiReaderIterator(ctrl)
+	iter.EXPECT().Reset(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+	iter.EXPECT().Next().Return(false)
+	iter.EXPECT().Err().Return(errors.New("merge error"))
+	iter.EXPECT().Close()
+	iterPool := encoding.NewMockMultiReaderIteratorPool(ctrl)
+	iterPool.EXPECT().Get().Return(iter)
+
+	buffer := newDatabaseBuffer().(*dbBuffer)
+	buffer.Reset(ident.StringID("foo"), opts.SetMultiReaderIteratorPool(iterPool))
+
+	// Each write is older than the last so requires a new encoder.
+	data := []value{
+		{curr.Add(secs(10)), 1, xtime.Second, nil},
+		{curr.Add(secs(8)), 2, xtime.Second, nil},
+		{curr.Add(secs(6)), 3, xtime.Second, nil},
+	}
+	curr = data[0].timestamp
+	for _, v := range data {
+		verifyWriteToBuffer(t, buffer, v, nil)
+	}
+
+	// The failed merge leaves the encoders untouched.
+	buckets, ok := buffer.bucketVersionsAt(start)
+	require.True(t, ok)
+	bucket, ok := buckets.writableBucket(WarmWrite)
+	require.True(t, ok)
+	require.Len(t, bucket.encoders, 3)
+
+	snapshot := scope.Snapshot()
+	assert.Equal(t, int64(1),
+		snapshot.Counters()["series.inline-merge-errors+"].Value())
+
+	sort.Sort(valuesByTime(data))
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	results, err := buffer.ReadEncoded(ctx, timeZero, timeDistantFuture, namespace.Context{})
+	require.NoError(t, err)
+	requireReaderValuesEqual(t, data, results, opts, namespace.Context{})
+}
+
 func newTestBufferBucketWithData(t *testing.T, opts Options, setAnn setAnnotation) (*BufferBucket, []value) {
 	rops := opts.RetentionOptions()
 	curr := time.Now().Truncate(rops.BlockSize())
@@ -405,7 +666,7 @@ func testBufferBucketMerge(t *testing.T, opts Options, setAnn setAnnotation) {
 	if setAnn != nil {
 		nsCtx.Schema = testSchemaDesc
 	}
-	sr, ok, err := b.mergeToStream(ctx, nsCtx)
+	sr, ok, err := b.mergeToStream(ctx, mergeTriggerTick, nsCtx)
 
 	require.NoError(t, err)
 	require.True(t, ok)
@@ -446,7 +707,7 @@ func TestBufferBucketMergeNilEncoderStreams(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, stream)
 
-	mergeRes, err := b.merge(namespace.Context{})
+	mergeRes, err := b.merge(mergeTriggerTick, namespace.Context{})
 	require.NoError(t, err)
 	assert.Equal(t, 1, mergeRes)
 	assert.Equal(t, 1, len(b.encoders))
@@ -513,7 +774,7 @@ func TestBufferBucketWriteDuplicateUpserts(t *testing.T) {
 	requireReaderValuesEqual(t, expected, results, opts, namespace.Context{})
 
 	// Now assert that mergeToStream() returns same expected result.
-	stream, ok, err := b.mergeToStream(ctx, namespace.Context{})
+	stream, ok, err := b.mergeToStream(ctx, mergeTriggerTick, namespace.Context{})
 	require.NoError(t, err)
 	require.True(t, ok)
 	requireSegmentValuesEqual(t, expected, []xio.SegmentReader{stream}, opts, namespace.Context{})
@@ -583,7 +844,7 @@ func TestBufferBucketDuplicatePointsNotWrittenButUpserted(t *testing.T) {
 	requireReaderValuesEqual(t, expected, results, opts, namespace.Context{})
 
 	// Now assert that mergeToStream() returns same expected result.
-	stream, ok, err := b.mergeToStream(ctx, namespace.Context{})
+	stream, ok, err := b.mergeToStream(ctx, mergeTriggerTick, namespace.Context{})
 	require.NoError(t, err)
 	require.True(t, ok)
 	requireSegmentValuesEqual(t, expected, []xio.SegmentReader{stream}, opts, namespace.Context{})
@@ -661,6 +922,36 @@ func testBufferFetchBlocks(t *testing.T, opts Options, setAnn setAnnotation) {
 	requireReaderValuesEqual(t, expected, [][]xio.BlockReader{res[0].Blocks}, opts, nsCtx)
 }
 
+func TestBufferFetchBlocksVersioned(t *testing.T) {
+	opts := newBufferTestOptions()
+	b, expected := newTestBufferBucketsWithData(t, opts, nil)
+	b.buckets[0].version = 2
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+
+	buffer := newDatabaseBuffer().(*dbBuffer)
+	buffer.Reset(ident.StringID("foo"), opts)
+	buffer.bucketsMap[xtime.ToUnixNano(b.start)] = b
+
+	nsCtx := namespace.Context{}
+	res := buffer.FetchBlocksVersioned(ctx, []time.Time{b.start}, 2, nsCtx)
+	require.Equal(t, 1, len(res))
+	require.Equal(t, b.start, res[0].Start)
+	require.NoError(t, res[0].Err)
+	requireReaderValuesEqual(t, expected, [][]xio.BlockReader{res[0].Blocks}, opts, nsCtx)
+
+	// Versions and block starts that are not held by the buffer are returned
+	// as empty results so that callers can probe for versions.
+	missingStart := b.start.Add(opts.RetentionOptions().BlockSize())
+	res = buffer.FetchBlocksVersioned(ctx, []time.Time{missingStart, b.start}, 1, nsCtx)
+	require.Equal(t, 2, len(res))
+	for i, start := range []time.Time{b.start, missingStart} {
+		require.Equal(t, start, res[i].Start)
+		require.Equal(t, ErrBlockVersionNotFound, res[i].Err)
+		require.Empty(t, res[i].Blocks)
+	}
+}
+
 func TestBufferFetchBlocksOneResultPerBlock(t *testing.T) {
 	opts := newBufferTestOptions()
 	opts.SetColdWritesEnabled(true)
@@ -778,11 +1069,54 @@ func TestBufferFetchBlocksMetadata(t *testing.T) {
 	assert.True(t, expectedLastRead.Equal(res[0].LastRead))
 }
 
+func TestBufferFetchBlocksMetadataMinBlockSize(t *testing.T) {
+	opts := newBufferTestOptions()
+
+	b, _ := newTestBufferBucketsWithData(t, opts, nil)
+
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+
+	start := b.start.Add(-time.Second)
+	end := b.start.Add(time.Second)
+
+	buffer := newDatabaseBuffer().(*dbBuffer)
+	buffer.Reset(ident.StringID("foo"), opts)
+	buffer.bucketsMap[xtime.ToUnixNano(b.start)] = b
+	buffer.inOrderBlockStarts = append(buffer.inOrderBlockStarts, b.start)
+
+	size := int64(b.streamsLen())
+
+	// Blocks smaller than the minimum size are omitted.
+	fetchOpts := FetchBlocksMetadataOptions{
+		FetchBlocksMetadataOptions: block.FetchBlocksMetadataOptions{
+			IncludeSizes: true,
+		},
+		MinBlockSizeBytes: size + 1,
+	}
+	metadata, err := buffer.FetchBlocksMetadata(ctx, start, end, fetchOpts)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(metadata.Results()))
+
+	fetchOpts.MinBlockSizeBytes = size
+	metadata, err = buffer.FetchBlocksMetadata(ctx, start, end, fetchOpts)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(metadata.Results()))
+
+	// The minimum size is ignored when sizes are not included.
+	fetchOpts.IncludeSizes = false
+	fetchOpts.MinBlockSizeBytes = size + 1
+	metadata, err = buffer.FetchBlocksMetadata(ctx, start, end, fetchOpts)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(metadata.Results()))
+}
+
 func TestBufferTickReordersOutOfOrderBuffers(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	opts := newBufferTestOptions()
+	scope := tally.NewTestScope("", nil)
+	opts := newBufferTestOptions().SetStats(NewStats(scope))
 	rops := opts.RetentionOptions()
 	curr := time.Now().Truncate(rops.BlockSize())
 	start := curr
@@ -867,6 +1201,11 @@ func TestBufferTickReordersOutOfOrderBuffers(t *testing.T) {
 
 	// Ensure single encoder again.
 	assert.Equal(t, 1, len(encoders))
+
+	// Ensure the merge was recorded against the tick trigger.
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(1), counters["series.encoder-merges+trigger=tick"].Value())
+	assert.Equal(t, int64(2), counters["series.encoders-merged+trigger=tick"].Value())
 }
 
 func TestBufferRemoveBucket(t *testing.T) {
@@ -924,6 +1263,57 @@ func TestBufferRemoveBucket(t *testing.T) {
 	assert.True(t, buffer.IsEmpty())
 }
 
+func TestBufferRemoveBucketRecyclesEncoders(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	opts := newBufferTestOptions().
+		SetRecycleEvictedEncoders(true).
+		SetStats(NewStats(scope))
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	start := curr
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer().(*dbBuffer)
+	buffer.Reset(ident.StringID("foo"), opts)
+
+	// Perform out of order writes that will create two in order encoders.
+	data := []value{
+		{curr, 1, xtime.Second, nil},
+		{curr.Add(mins(0.5)), 2, xtime.Second, nil},
+		{curr.Add(mins(0.5)).Add(-5 * time.Second), 3, xtime.Second, nil},
+	}
+
+	for _, v := range data {
+		curr = v.timestamp
+		verifyWriteToBuffer(t, buffer, v, nil)
+	}
+
+	buckets, exists := buffer.bucketVersionsAt(start)
+	require.True(t, exists)
+	bucket, exists := buckets.writableBucket(WarmWrite)
+	require.True(t, exists)
+	require.Len(t, bucket.encoders, 2)
+
+	// Simulate that a flush has fully completed on this bucket.
+	blockStates := BootstrappedBlockStateSnapshot{
+		Snapshot: map[xtime.UnixNano]BlockState{
+			xtime.ToUnixNano(start): BlockState{
+				WarmRetrievable: true,
+			},
+		},
+	}
+	bucket.version = 1
+
+	buffer.Tick(NewShardBlockStateSnapshot(true, blockStates), namespace.Context{})
+	assert.True(t, buffer.IsEmpty())
+
+	// The evicted bucket's encoders have been returned to the pool.
+	assert.Len(t, bucket.encoders, 0)
+	counters := scope.Snapshot().Counters()
+	assert.Equal(t, int64(2), counters["series.encoders-recycled+"].Value())
+}
+
 func TestBuffertoStream(t *testing.T) {
 	opts := newBufferTestOptions()
 
@@ -1023,6 +1413,65 @@ func testBufferWithEmptyEncoder(t *testing.T, testSnapshot bool) {
 	}
 }
 
+func TestBufferWarmFlushHighCompressionLevel(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	opts := newBufferTestOptions().
+		SetStats(NewStats(scope)).
+		SetCompressionLevel(namespace.HighCompressionLevel)
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer().(*dbBuffer)
+	buffer.Reset(ident.StringID("foo"), opts)
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	// Write with a finer time unit than required by the timestamps so that
+	// flushing at the high compression level encodes them in seconds.
+	data := []value{
+		{curr, 1, xtime.Nanosecond, nil},
+		{curr.Add(secs(10)), 2, xtime.Nanosecond, nil},
+		{curr.Add(secs(20)), 3, xtime.Millisecond, nil},
+		{curr.Add(secs(30)), 4, xtime.Nanosecond, nil},
+	}
+	for _, v := range data {
+		wasWritten, err := buffer.Write(ctx, v.timestamp, v.value, v.unit,
+			v.annotation, WriteOptions{})
+		require.NoError(t, err)
+		require.True(t, wasWritten)
+	}
+
+	persisted := false
+	persistFn := func(id ident.ID, tags ident.Tags, segment ts.Segment, checksum uint32) error {
+		persisted = true
+		assert.Equal(t, digest.SegmentChecksum(segment), checksum)
+
+		expected := make([]value, 0, len(data))
+		for _, v := range data {
+			v.unit = xtime.Second
+			expected = append(expected, v)
+		}
+		reader := xio.NewSegmentReader(segment)
+		requireSegmentValuesEqual(t, expected, []xio.SegmentReader{reader},
+			opts, namespace.Context{})
+		return nil
+	}
+
+	outcome, err := buffer.WarmFlush(ctx, curr, ident.StringID("foo"),
+		ident.Tags{}, persistFn, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, FlushOutcomeFlushedToDisk, outcome)
+	require.True(t, persisted)
+
+	counters := scope.Snapshot().Counters()
+	inBytes := counters["series.flush-compression-in-bytes+"].Value()
+	outBytes := counters["series.flush-compression-out-bytes+"].Value()
+	assert.True(t, inBytes > 0)
+	assert.True(t, outBytes < inBytes)
+}
+
 func TestBufferSnapshot(t *testing.T) {
 	opts := newBufferTestOptions()
 	testBufferSnapshot(t, opts, nil)
@@ -1506,6 +1955,29 @@ func TestColdFlushBlockStarts(t *testing.T) {
 	assert.Equal(t, 2, flushStarts.Len())
 	assert.True(t, flushStarts.Contains(xtime.ToUnixNano(blockStart1)))
 	assert.True(t, flushStarts.Contains(xtime.ToUnixNano(blockStart3)))
+
+	// Debug info exposes the bucket versions driving the above, sorted by
+	// block start.
+	debugInfo := buffer.DebugInfo()
+	require.Equal(t, 4, len(debugInfo))
+	for i, expected := range []struct {
+		start     time.Time
+		version   int
+		writeType WriteType
+	}{
+		{blockStart1, 0, ColdWrite},
+		{blockStart2, 1, ColdWrite},
+		{blockStart3, 1, ColdWrite},
+		{blockStart4, 0, WarmWrite},
+	} {
+		assert.True(t, expected.start.Equal(debugInfo[i].BlockStart))
+		require.Equal(t, 1, len(debugInfo[i].Buckets))
+		bucket := debugInfo[i].Buckets[0]
+		assert.Equal(t, expected.version, bucket.Version)
+		assert.Equal(t, expected.writeType, bucket.WriteType)
+		assert.Equal(t, 1, bucket.NumEncoders)
+		assert.Equal(t, 0, bucket.NumLoadedBlocks)
+	}
 }
 
 func TestFetchBlocksForColdFlush(t *testing.T) {
@@ -1573,27 +2045,50 @@ func TestFetchBlocksForColdFlush(t *testing.T) {
 	ctx := context.NewContext()
 	defer ctx.Close()
 	nsCtx := namespace.Context{Schema: testSchemaDesc}
-	reader, err := buffer.FetchBlocksForColdFlush(ctx, blockStart1, 4, nsCtx)
-	assert.NoError(t, err)
+	reader := fetchBlocksForColdFlush(t, ctx, buffer, blockStart1, 4, nsCtx)
 	// Verify that we got the correct data and that version is correct set.
 	requireReaderValuesEqual(t, expected[blockStartNano1], [][]xio.BlockReader{reader}, opts, nsCtx)
 	assert.Equal(t, 4, buffer.bucketsMap[blockStartNano1].buckets[0].version)
 
-	// Try to fetch from block1 again, which should result in error since we
+	// Try to mark block1 again, which should result in error since we
 	// just fetched, which would mark those buckets as not dirty.
-	_, err = buffer.FetchBlocksForColdFlush(ctx, blockStart1, 9, nsCtx)
+	_, err := buffer.MarkColdFlushVersion(blockStart1, 9)
 	assert.Error(t, err)
 
-	reader, err = buffer.FetchBlocksForColdFlush(ctx, blockStart3, 1, nsCtx)
-	assert.NoError(t, err)
+	reader = fetchBlocksForColdFlush(t, ctx, buffer, blockStart3, 1, nsCtx)
 	requireReaderValuesEqual(t, expected[blockStartNano3], [][]xio.BlockReader{reader}, opts, nsCtx)
 	assert.Equal(t, 1, buffer.bucketsMap[blockStartNano3].buckets[0].version)
 
+	// Cold writes after the bucket has been marked go to a new bucket and are
+	// not fetched as part of the marked flush.
+	_, err = buffer.bucketsMap[blockStartNano3].write(blockStart3.Add(secs(72)),
+		16, xtime.Second, nil, ColdWrite, nsCtx.Schema)
+	require.NoError(t, err)
+	reader = fetchBlocksForColdFlush(t, ctx, buffer, blockStart3, 2, nsCtx)
+	requireReaderValuesEqual(t, expected[blockStartNano3], [][]xio.BlockReader{reader}, opts, nsCtx)
+
 	// Try to fetch from a block that only has warm buckets. It has no data
 	// but is not an error.
-	reader, err = buffer.FetchBlocksForColdFlush(ctx, blockStart4, 1, nsCtx)
+	marked, err := buffer.MarkColdFlushVersion(blockStart4, 1)
 	assert.NoError(t, err)
-	requireReaderValuesEqual(t, []value{}, [][]xio.BlockReader{reader}, opts, nsCtx)
+	assert.False(t, marked)
+}
+
+func fetchBlocksForColdFlush(
+	t *testing.T,
+	ctx context.Context,
+	buffer *dbBuffer,
+	start time.Time,
+	version int,
+	nsCtx namespace.Context,
+) []xio.BlockReader {
+	marked, err := buffer.MarkColdFlushVersion(start, version)
+	require.NoError(t, err)
+	require.True(t, marked)
+
+	reader, err := buffer.FetchBlocksForColdFlush(ctx, start, nsCtx)
+	require.NoError(t, err)
+	return reader
 }
 
 // TestBufferLoadWarmWrite tests the Load method, ensuring that blocks are successfully loaded into
@@ -21,14 +21,18 @@
 package series
 
 import (
+	"time"
+
 	"github.com/m3db/m3/src/dbnode/clock"
 	"github.com/m3db/m3/src/dbnode/encoding"
+	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/x/context"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/instrument"
 	"github.com/m3db/m3/src/x/pool"
+	"github.com/m3db/m3/src/x/serialize"
 )
 
 type options struct {
@@ -42,10 +46,20 @@ type options struct {
 	multiReaderIteratorPool       encoding.MultiReaderIteratorPool
 	fetchBlockMetadataResultsPool block.FetchBlockMetadataResultsPool
 	identifierPool                ident.Pool
+	tagEncoderPool                serialize.TagEncoderPool
 	stats                         Stats
 	coldWritesEnabled             bool
+	verifyBlocksOnLoad            bool
+	mergeDuplicateBootstrap       bool
+	recycleEvictedEncoders        bool
+	cacheEncodedTags              bool
+	writeBackpressure             WriteBackpressure
 	bufferBucketPool              *BufferBucketPool
 	bufferBucketVersionsPool      *BufferBucketVersionsPool
+	maxEncodersPerBucket          int
+	maxWriteFutureSkew            time.Duration
+	idleEvictionTimeout           time.Duration
+	compressionLevel              namespace.CompressionLevel
 }
 
 // NewOptions creates new database series options
@@ -56,6 +70,9 @@ func NewOptions() Options {
 		return pool.NewBytesPool(s, nil)
 	})
 	bytesPool.Init()
+	tagEncoderPool := serialize.NewTagEncoderPool(
+		serialize.NewTagEncoderOptions(), pool.NewObjectPoolOptions())
+	tagEncoderPool.Init()
 	iopts := instrument.NewOptions()
 	return &options{
 		clockOpts:                     clock.NewOptions(),
@@ -68,6 +85,7 @@ func NewOptions() Options {
 		multiReaderIteratorPool:       encoding.NewMultiReaderIteratorPool(nil),
 		fetchBlockMetadataResultsPool: block.NewFetchBlockMetadataResultsPool(nil, 0),
 		identifierPool:                ident.NewPool(bytesPool, ident.PoolOptions{}),
+		tagEncoderPool:                tagEncoderPool,
 		stats:                         NewStats(iopts.MetricsScope()),
 	}
 }
@@ -179,6 +197,16 @@ func (o *options) IdentifierPool() ident.Pool {
 	return o.identifierPool
 }
 
+func (o *options) SetTagEncoderPool(value serialize.TagEncoderPool) Options {
+	opts := *o
+	opts.tagEncoderPool = value
+	return &opts
+}
+
+func (o *options) TagEncoderPool() serialize.TagEncoderPool {
+	return o.tagEncoderPool
+}
+
 func (o *options) SetStats(value Stats) Options {
 	opts := *o
 	opts.stats = value
@@ -199,6 +227,56 @@ func (o *options) ColdWritesEnabled() bool {
 	return o.coldWritesEnabled
 }
 
+func (o *options) SetVerifyBlocksOnLoad(value bool) Options {
+	opts := *o
+	opts.verifyBlocksOnLoad = value
+	return &opts
+}
+
+func (o *options) VerifyBlocksOnLoad() bool {
+	return o.verifyBlocksOnLoad
+}
+
+func (o *options) SetMergeDuplicateBootstrap(value bool) Options {
+	opts := *o
+	opts.mergeDuplicateBootstrap = value
+	return &opts
+}
+
+func (o *options) MergeDuplicateBootstrap() bool {
+	return o.mergeDuplicateBootstrap
+}
+
+func (o *options) SetRecycleEvictedEncoders(value bool) Options {
+	opts := *o
+	opts.recycleEvictedEncoders = value
+	return &opts
+}
+
+func (o *options) RecycleEvictedEncoders() bool {
+	return o.recycleEvictedEncoders
+}
+
+func (o *options) SetCacheEncodedTags(value bool) Options {
+	opts := *o
+	opts.cacheEncodedTags = value
+	return &opts
+}
+
+func (o *options) CacheEncodedTags() bool {
+	return o.cacheEncodedTags
+}
+
+func (o *options) SetWriteBackpressure(value WriteBackpressure) Options {
+	opts := *o
+	opts.writeBackpressure = value
+	return &opts
+}
+
+func (o *options) WriteBackpressure() WriteBackpressure {
+	return o.writeBackpressure
+}
+
 func (o *options) SetBufferBucketVersionsPool(value *BufferBucketVersionsPool) Options {
 	opts := *o
 	opts.bufferBucketVersionsPool = value
@@ -218,3 +296,43 @@ func (o *options) SetBufferBucketPool(value *BufferBucketPool) Options {
 func (o *options) BufferBucketPool() *BufferBucketPool {
 	return o.bufferBucketPool
 }
+
+func (o *options) SetMaxEncodersPerBucket(value int) Options {
+	opts := *o
+	opts.maxEncodersPerBucket = value
+	return &opts
+}
+
+func (o *options) MaxEncodersPerBucket() int {
+	return o.maxEncodersPerBucket
+}
+
+func (o *options) SetMaxWriteFutureSkew(value time.Duration) Options {
+	opts := *o
+	opts.maxWriteFutureSkew = value
+	return &opts
+}
+
+func (o *options) MaxWriteFutureSkew() time.Duration {
+	return o.maxWriteFutureSkew
+}
+
+func (o *options) SetIdleEvictionTimeout(value time.Duration) Options {
+	opts := *o
+	opts.idleEvictionTimeout = value
+	return &opts
+}
+
+func (o *options) IdleEvictionTimeout() time.Duration {
+	return o.idleEvictionTimeout
+}
+
+func (o *options) SetCompressionLevel(value namespace.CompressionLevel) Options {
+	opts := *o
+	opts.compressionLevel = value
+	return &opts
+}
+
+func (o *options) CompressionLevel() namespace.CompressionLevel {
+	return o.compressionLevel
+}
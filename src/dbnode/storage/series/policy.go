@@ -47,6 +47,11 @@ const (
 	// using an LRU of fixed capacity. Series that are least recently
 	// used will be evicted first.
 	CacheLRU
+	// CacheWriteThrough specifies that freshly written blocks are kept
+	// cached until they have been flushed, after which they are unwired
+	// on the next tick. This suits write heavy workloads that are rarely
+	// read but still want low latency reads of recently written data.
+	CacheWriteThrough
 
 	// DefaultCachePolicy is the default cache policy.
 	DefaultCachePolicy = CacheRecentlyRead
@@ -54,7 +59,7 @@ const (
 
 // ValidCachePolicies returns the valid series cache policies.
 func ValidCachePolicies() []CachePolicy {
-	return []CachePolicy{CacheNone, CacheAll, CacheRecentlyRead, CacheLRU}
+	return []CachePolicy{CacheNone, CacheAll, CacheRecentlyRead, CacheLRU, CacheWriteThrough}
 }
 
 func (p CachePolicy) String() string {
@@ -67,6 +72,8 @@ func (p CachePolicy) String() string {
 		return "recently_read"
 	case CacheLRU:
 		return "lru"
+	case CacheWriteThrough:
+		return "write_through"
 	}
 	return "unknown"
 }
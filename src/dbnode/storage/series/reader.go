@@ -28,6 +28,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/x/context"
 	xerrors "github.com/m3db/m3/src/x/errors"
@@ -76,7 +77,18 @@ func (r Reader) ReadEncoded(
 	start, end time.Time,
 	nsCtx namespace.Context,
 ) ([][]xio.BlockReader, error) {
-	return r.readersWithBlocksMapAndBuffer(ctx, start, end, nil, nil, nsCtx)
+	return r.readersWithBlocksMapAndBuffer(ctx, start, end, nil, nil, 0, nsCtx)
+}
+
+// ReadEncodedWithLimit reads encoded blocks using just a block retriever,
+// failing as soon as more than max readers block readers have been assembled.
+func (r Reader) ReadEncodedWithLimit(
+	ctx context.Context,
+	start, end time.Time,
+	maxReaders int,
+	nsCtx namespace.Context,
+) ([][]xio.BlockReader, error) {
+	return r.readersWithBlocksMapAndBuffer(ctx, start, end, nil, nil, maxReaders, nsCtx)
 }
 
 func (r Reader) readersWithBlocksMapAndBuffer(
@@ -84,6 +96,7 @@ func (r Reader) readersWithBlocksMapAndBuffer(
 	start, end time.Time,
 	seriesBlocks block.DatabaseSeriesBlocks,
 	seriesBuffer databaseBuffer,
+	maxReaders int,
 	nsCtx namespace.Context,
 ) ([][]xio.BlockReader, error) {
 	// Two-dimensional slice such that the first dimension is unique by blockstart
@@ -126,6 +139,7 @@ func (r Reader) readersWithBlocksMapAndBuffer(
 		alignedEnd = latest
 	}
 
+	var numReaders int
 	first, last := alignedStart, alignedEnd
 	for blockAt := first; !blockAt.After(last); blockAt = blockAt.Add(size) {
 		// resultsBlock holds the results from one block. The flow is:
@@ -195,6 +209,14 @@ func (r Reader) readersWithBlocksMapAndBuffer(
 			}
 		}
 
+		numReaders += len(resultsBlock)
+		if maxReaders > 0 && numReaders > maxReaders {
+			// NB: Stop assembling readers as soon as the limit is exceeded,
+			// the readers already assembled are registered with the context
+			// and will be finalized when the context is closed.
+			return nil, m3dberrors.NewQueryTooLargeError(numReaders, maxReaders)
+		}
+
 		if len(resultsBlock) > 0 {
 			results = append(results, resultsBlock)
 		}
@@ -614,7 +614,7 @@ func TestReaderReadEncodedRobust(t *testing.T) {
 				// End is not inclusive so add blocksize to the last time.
 				end = tc.times[len(tc.times)-1].Add(blockSize)
 			)
-			r, err := reader.readersWithBlocksMapAndBuffer(ctx, start, end, diskCache, buffer, namespace.Context{})
+			r, err := reader.readersWithBlocksMapAndBuffer(ctx, start, end, diskCache, buffer, 0, namespace.Context{})
 
 			anyContainErr := false
 			for _, sr := range tc.cachedBlocks {
@@ -22,14 +22,19 @@ package series
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/digest"
+	"github.com/m3db/m3/src/dbnode/encoding/tuple"
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/x/context"
+	xerrors "github.com/m3db/m3/src/x/errors"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/instrument"
 	xtime "github.com/m3db/m3/src/x/time"
@@ -49,9 +54,23 @@ var (
 	// ErrSeriesAllDatapointsExpired is returned on tick when all datapoints are expired
 	ErrSeriesAllDatapointsExpired = errors.New("series datapoints are all expired")
 
+	// ErrSeriesIdle is returned on tick when the series has not been written
+	// to for longer than the idle eviction timeout and all of its data has
+	// been flushed, the series' blocks are unwired so it can be evicted.
+	ErrSeriesIdle = errors.New("series is idle")
+
+	// ErrBlockVersionNotFound is set as the error of a versioned fetch block
+	// result when the buffer does not hold the requested version of the block.
+	ErrBlockVersionNotFound = errors.New("block version not found")
+
+	// ErrTupleNoValues is returned, as an invalid params error, when writing
+	// a tuple without any values.
+	ErrTupleNoValues = errors.New("tuple write must have at least one value")
+
 	errSeriesAlreadyBootstrapped         = errors.New("series is already bootstrapped")
 	errSeriesNotBootstrapped             = errors.New("series is not yet bootstrapped")
 	errBlockStateSnapshotNotBootstrapped = errors.New("block state snapshot is not bootstrapped")
+	errWriteBackpressure                 = errors.New("series write rejected while under write backpressure")
 )
 
 type dbSeries struct {
@@ -65,6 +84,11 @@ type dbSeries struct {
 	id   ident.ID
 	tags ident.Tags
 
+	// encodedTags is the serialized form of tags, only set when the
+	// CacheEncodedTags option is enabled. Since tags do not change once a
+	// series is created it is computed once on Reset.
+	encodedTags []byte
+
 	buffer                      databaseBuffer
 	cachedBlocks                block.DatabaseSeriesBlocks
 	bs                          bootstrapState
@@ -72,6 +96,14 @@ type dbSeries struct {
 	onRetrieveBlock             block.OnRetrieveBlock
 	blockOnEvictedFromWiredList block.OnEvictedFromWiredList
 	pool                        DatabaseSeriesPool
+
+	// lastWrite is the time of the last accepted write, or the time the
+	// series was reset if it has not been written to since.
+	lastWrite time.Time
+
+	// blockStatesBootstrapped is set once the series is ticked with
+	// bootstrapped block states, they never revert to not bootstrapped.
+	blockStatesBootstrapped bool
 }
 
 // NewDatabaseSeries creates a new database series
@@ -123,6 +155,9 @@ func (s *dbSeries) Tick(blockStates ShardBlockStateSnapshot, nsCtx namespace.Con
 
 	s.Lock()
 
+	if _, bootstrapped := blockStates.UnwrapValue(); bootstrapped {
+		s.blockStatesBootstrapped = true
+	}
 	bufferResult := s.buffer.Tick(blockStates, nsCtx)
 	r.MergedOutOfOrderBlocks = bufferResult.mergedOutOfOrderBlocks
 	r.EvictedBuckets = bufferResult.evictedBucketTimes.Len()
@@ -134,6 +169,14 @@ func (s *dbSeries) Tick(blockStates ShardBlockStateSnapshot, nsCtx namespace.Con
 	r.TickStatus = update.TickStatus
 	r.MadeExpiredBlocks, r.MadeUnwiredBlocks =
 		update.madeExpiredBlocks, update.madeUnwiredBlocks
+	r.SkippedUnbootstrappedBlocks = update.skippedUnbootstrappedBlocks
+
+	if update.ActiveBlocks > 0 && s.isIdleWithLock(blockStates) {
+		r.TickStatus = TickStatus{}
+		r.MadeUnwiredBlocks += s.unwireAllBlocksWithLock()
+		s.Unlock()
+		return r, ErrSeriesIdle
+	}
 
 	s.Unlock()
 
@@ -143,10 +186,62 @@ func (s *dbSeries) Tick(blockStates ShardBlockStateSnapshot, nsCtx namespace.Con
 	return r, nil
 }
 
+// isIdleWithLock returns whether the series has gone without writes for
+// longer than the idle eviction timeout with all of its data flushed.
+func (s *dbSeries) isIdleWithLock(blockStates ShardBlockStateSnapshot) bool {
+	// NB: With the CacheAll policy there is no block retriever so an evicted
+	// series could never have its blocks read back from disk.
+	if s.opts.CachePolicy() == CacheAll {
+		return false
+	}
+
+	idleTimeout := s.opts.IdleEvictionTimeout()
+	if idleTimeout <= 0 || !s.buffer.IsEmpty() ||
+		s.now().Sub(s.lastWrite) < idleTimeout {
+		return false
+	}
+
+	// Only evict once block states are bootstrapped so that blocks that are
+	// yet to be flushed are never dropped.
+	blockStatesSnapshot, bootstrapped := blockStates.UnwrapValue()
+	if !bootstrapped {
+		return false
+	}
+	for startNano := range s.cachedBlocks.AllBlocks() {
+		if !blockStatesSnapshot.Snapshot[startNano].WarmRetrievable {
+			return false
+		}
+	}
+	return true
+}
+
+// unwireAllBlocksWithLock removes all cached blocks, they can still be
+// retrieved from disk since idle eviction is never used with the CacheAll
+// policy, and returns the number of blocks removed.
+func (s *dbSeries) unwireAllBlocksWithLock() int {
+	var (
+		unwired     int
+		cachePolicy = s.opts.CachePolicy()
+	)
+	for startNano, currBlock := range s.cachedBlocks.AllBlocks() {
+		s.cachedBlocks.RemoveBlockAt(startNano.ToTime())
+		// NB: Blocks retrieved from disk with the LRU policy are closed by
+		// the WiredList, see updateBlocksWithLock.
+		if !(cachePolicy == CacheLRU && currBlock.WasRetrievedFromDisk()) {
+			currBlock.Close()
+		}
+		unwired++
+	}
+	return unwired
+}
+
 type updateBlocksResult struct {
 	TickStatus
 	madeExpiredBlocks int
 	madeUnwiredBlocks int
+	// skippedUnbootstrappedBlocks is the number of blocks that could not be
+	// unwired as the block states were not yet bootstrapped.
+	skippedUnbootstrappedBlocks int
 }
 
 func (s *dbSeries) updateBlocksWithLock(
@@ -224,11 +319,18 @@ func (s *dbSeries) updateBlocksWithLock(
 					// read from disk (not retrieved), and the WiredList will manage those that were
 					// retrieved from disk.
 					shouldUnwire = !currBlock.WasRetrievedFromDisk()
+				case CacheWriteThrough:
+					// Blocks are only kept wired until they have been flushed,
+					// once flushed they are unwired on the first tick that
+					// observes the flush.
+					shouldUnwire = true
 				default:
 					s.opts.InstrumentOptions().Logger().Fatal(
 						"unhandled cache policy in series tick", zap.Any("policy", cachePolicy))
 				}
 			}
+		} else {
+			result.skippedUnbootstrappedBlocks++
 		}
 
 		if shouldUnwire {
@@ -256,6 +358,49 @@ func (s *dbSeries) updateBlocksWithLock(
 	return result, nil
 }
 
+func (s *dbSeries) EvictCachedBlocks(
+	blockStates ShardBlockStateSnapshot,
+	idleFor time.Duration,
+) int {
+	cachePolicy := s.opts.CachePolicy()
+	if cachePolicy == CacheAll {
+		// Never unwire
+		return 0
+	}
+	// Without bootstrapped block states there is no way to tell whether a
+	// block has been flushed, so nothing can be safely evicted.
+	blockStatesSnapshot, bootstrapped := blockStates.UnwrapValue()
+	if !bootstrapped {
+		return 0
+	}
+
+	var (
+		now     = s.now()
+		evicted int
+	)
+	s.Lock()
+	for startNano, currBlock := range s.cachedBlocks.AllBlocks() {
+		if !blockStatesSnapshot.Snapshot[startNano].WarmRetrievable {
+			continue
+		}
+		// Same as the tick, blocks retrieved from disk when using the LRU
+		// policy are owned by the WiredList and must not be closed here.
+		if cachePolicy == CacheLRU && currBlock.WasRetrievedFromDisk() {
+			continue
+		}
+		if now.Sub(currBlock.LastReadTime()) < idleFor {
+			continue
+		}
+		// Remove the block and it will be looked up later
+		s.cachedBlocks.RemoveBlockAt(startNano.ToTime())
+		currBlock.Close()
+		evicted++
+	}
+	s.Unlock()
+
+	return evicted
+}
+
 func (s *dbSeries) IsEmpty() bool {
 	s.RLock()
 	blocksLen := s.cachedBlocks.Len()
@@ -274,6 +419,23 @@ func (s *dbSeries) NumActiveBlocks() int {
 	return value
 }
 
+func (s *dbSeries) EstimatedMemoryBytes() int64 {
+	s.RLock()
+	defer s.RUnlock()
+
+	total := s.buffer.EstimatedMemoryBytes()
+	for _, b := range s.cachedBlocks.AllBlocks() {
+		total += int64(b.Len())
+	}
+	if s.id != nil {
+		total += int64(len(s.id.Bytes()))
+	}
+	for _, tag := range s.tags.Values() {
+		total += int64(len(tag.Name.Bytes()) + len(tag.Value.Bytes()))
+	}
+	return total + int64(len(s.encodedTags))
+}
+
 func (s *dbSeries) IsBootstrapped() bool {
 	s.RLock()
 	state := s.bs
@@ -281,6 +443,19 @@ func (s *dbSeries) IsBootstrapped() bool {
 	return state == bootstrapped
 }
 
+func (s *dbSeries) BootstrapState() BootstrapState {
+	s.RLock()
+	defer s.RUnlock()
+	switch {
+	case s.bs != bootstrapped:
+		return BootstrapNotStarted
+	case s.blockStatesBootstrapped:
+		return BlockStateBootstrapped
+	default:
+		return SeriesBootstrapped
+	}
+}
+
 func (s *dbSeries) Write(
 	ctx context.Context,
 	timestamp time.Time,
@@ -289,35 +464,312 @@ func (s *dbSeries) Write(
 	annotation []byte,
 	wOpts WriteOptions,
 ) (bool, error) {
+	// NB: Reject the write before the buffer is mutated so that the client
+	// retries rather than the write being held in memory undurably.
+	if bp := s.opts.WriteBackpressure(); bp != nil && bp.Applies() {
+		return false, xerrors.NewRetryableError(errWriteBackpressure)
+	}
+	if err := s.checkWriteInRetention(timestamp); err != nil {
+		return false, err
+	}
+
+	stats := s.opts.Stats()
+	if stats.WriteStageTimingsEnabled() {
+		return s.writeWithStageTimings(ctx, timestamp, value, unit, annotation, wOpts, stats)
+	}
+
 	s.Lock()
 	wasWritten, err := s.buffer.Write(ctx, timestamp, value, unit, annotation, wOpts)
+	if wasWritten {
+		s.lastWrite = s.now()
+	}
 	s.Unlock()
+
+	return s.handleWriteResult(wasWritten, err, wOpts)
+}
+
+// writeWithStageTimings performs a write while recording the time spent
+// waiting for the series lock and writing to the buffer separately.
+func (s *dbSeries) writeWithStageTimings(
+	ctx context.Context,
+	timestamp time.Time,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
+	wOpts WriteOptions,
+	stats Stats,
+) (bool, error) {
+	lockStart := time.Now()
+	s.Lock()
+	bufferStart := time.Now()
+	wasWritten, err := s.buffer.Write(ctx, timestamp, value, unit, annotation, wOpts)
+	bufferEnd := time.Now()
+	if wasWritten {
+		s.lastWrite = s.now()
+	}
+	s.Unlock()
+
+	stats.RecordWriteLockWait(bufferStart.Sub(lockStart))
+	stats.RecordWriteBuffer(bufferEnd.Sub(bufferStart))
+
+	return s.handleWriteResult(wasWritten, err, wOpts)
+}
+
+// checkWriteInRetention rejects writes outside of the retention before the
+// series lock is taken or the buffer is touched, it mirrors the bounds the
+// buffer applies to cold writes. Writes are only checked when cold writes are
+// enabled since otherwise the buffer past/future window is tighter and the
+// write is handled as a disabled cold write.
+func (s *dbSeries) checkWriteInRetention(timestamp time.Time) error {
+	if !s.opts.ColdWritesEnabled() {
+		return nil
+	}
+	var (
+		now      = s.now()
+		ropts    = s.opts.RetentionOptions()
+		earliest = now.Add(-ropts.RetentionPeriod())
+		latest   = now.Add(ropts.FutureRetentionPeriod()).Add(ropts.BlockSize())
+	)
+	if timestamp.Before(earliest) || !timestamp.Before(latest) {
+		s.opts.Stats().incOutsideRetentionRejected()
+		return m3dberrors.NewWriteOutsideRetentionError(timestamp, earliest, latest)
+	}
+	return nil
+}
+
+func (s *dbSeries) handleWriteResult(
+	wasWritten bool,
+	err error,
+	wOpts WriteOptions,
+) (bool, error) {
+	if err != nil && m3dberrors.IsColdWritesDisabledError(err) {
+		if wOpts.DropDisabledColdWrites {
+			s.opts.Stats().IncColdWritesDisabledDropped()
+			return false, nil
+		}
+		s.opts.Stats().IncColdWritesDisabledRejected()
+	}
 	return wasWritten, err
 }
 
+func (s *dbSeries) WriteBatch(
+	ctx context.Context,
+	datapoints []ValueDatapoint,
+	wOpts WriteOptions,
+) (int, error) {
+	if bp := s.opts.WriteBackpressure(); bp != nil && bp.Applies() {
+		return 0, xerrors.NewRetryableError(errWriteBackpressure)
+	}
+
+	var (
+		written     int
+		err         error
+		stats       = s.opts.Stats()
+		timed       = stats.WriteStageTimingsEnabled()
+		lockStart   time.Time
+		bufferStart time.Time
+		bufferEnd   time.Time
+	)
+	if timed {
+		lockStart = time.Now()
+	}
+	s.Lock()
+	if timed {
+		bufferStart = time.Now()
+	}
+	for _, dp := range datapoints {
+		if err = s.checkWriteInRetention(dp.Timestamp); err != nil {
+			break
+		}
+		var wasWritten bool
+		wasWritten, err = s.buffer.Write(ctx, dp.Timestamp, dp.Value, dp.Unit,
+			dp.Annotation, wOpts)
+		wasWritten, err = s.handleWriteResult(wasWritten, err, wOpts)
+		if err != nil {
+			break
+		}
+		if wasWritten {
+			written++
+		}
+	}
+	if timed {
+		bufferEnd = time.Now()
+	}
+	if written > 0 {
+		s.lastWrite = s.now()
+	}
+	s.Unlock()
+
+	if timed {
+		// NB: The batch is written under a single acquisition of the lock so
+		// the stages are recorded once for the whole batch.
+		stats.RecordWriteLockWait(bufferStart.Sub(lockStart))
+		stats.RecordWriteBuffer(bufferEnd.Sub(bufferStart))
+	}
+
+	return written, err
+}
+
+func (s *dbSeries) WriteTuple(
+	ctx context.Context,
+	timestamp time.Time,
+	values []float64,
+	unit xtime.Unit,
+	wOpts WriteOptions,
+) (bool, error) {
+	if len(values) == 0 {
+		return false, xerrors.NewInvalidParamsError(ErrTupleNoValues)
+	}
+
+	// NB: The write transforms are applied to every value of the tuple here
+	// rather than only to the first value by the buffer, so that the values
+	// encoded in the annotation match the written datapoint value.
+	var (
+		tOpts       = wOpts.TransformOptions
+		transformed = make([]float64, 0, len(values))
+	)
+	for _, value := range values {
+		value, err := transformValue(value, tOpts, s.opts.Stats())
+		if err != nil {
+			return false, err
+		}
+		if tOpts.ForceValueEnabled {
+			value = tOpts.ForceValue
+		}
+		transformed = append(transformed, value)
+	}
+	wOpts.TransformOptions = WriteTransformOptions{}
+
+	// NB: Always encode into a new buffer since the encoder may retain a
+	// reference to the previous annotation to avoid re-encoding it.
+	annotation, err := tuple.Encode(transformed, nil)
+	if err != nil {
+		return false, xerrors.NewInvalidParamsError(err)
+	}
+	return s.Write(ctx, timestamp, transformed[0], unit, annotation, wOpts)
+}
+
 func (s *dbSeries) ReadEncoded(
 	ctx context.Context,
 	start, end time.Time,
 	nsCtx namespace.Context,
+) ([][]xio.BlockReader, error) {
+	return s.ReadEncodedWithLimit(ctx, start, end, 0, nsCtx)
+}
+
+func (s *dbSeries) ReadEncodedWithLimit(
+	ctx context.Context,
+	start, end time.Time,
+	maxReaders int,
+	nsCtx namespace.Context,
 ) ([][]xio.BlockReader, error) {
 	s.RLock()
 	reader := NewReaderUsingRetriever(s.id, s.blockRetriever, s.onRetrieveBlock, s, s.opts)
-	r, err := reader.readersWithBlocksMapAndBuffer(ctx, start, end, s.cachedBlocks, s.buffer, nsCtx)
+	r, err := reader.readersWithBlocksMapAndBuffer(ctx, start, end, s.cachedBlocks,
+		s.buffer, maxReaders, nsCtx)
 	s.RUnlock()
 	return r, err
 }
 
+func (s *dbSeries) ReadFiltered(
+	ctx context.Context,
+	start, end time.Time,
+	opts ReadOptions,
+	nsCtx namespace.Context,
+) ([]ts.Datapoint, error) {
+	encoded, err := s.ReadEncoded(ctx, start, end, nsCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	iter := s.opts.MultiReaderIteratorPool().Get()
+	iter.ResetSliceOfSlices(xio.NewReaderSliceOfSlicesFromBlockReadersIterator(encoded), nsCtx.Schema)
+	defer iter.Close()
+
+	var (
+		results    []ts.Datapoint
+		annotation ts.Annotation
+	)
+	for iter.Next() {
+		dp, _, ant := iter.Current()
+		if len(ant) > 0 {
+			// NB: The annotation is invalidated by the next call to Next.
+			annotation = append(annotation[:0], ant...)
+		}
+		// Blocks may contain datapoints outside of the requested range.
+		if dp.Timestamp.Before(start) || !dp.Timestamp.Before(end) {
+			continue
+		}
+		if opts.AnnotationPredicate != nil && !opts.AnnotationPredicate(annotation) {
+			continue
+		}
+		results = append(results, dp)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *dbSeries) ReadStepped(
+	ctx context.Context,
+	start, end time.Time,
+	step time.Duration,
+	aggregation StepAggregation,
+	nsCtx namespace.Context,
+) ([]ts.Datapoint, error) {
+	if step <= 0 {
+		return nil, xerrors.NewInvalidParamsError(errStepMustBePositive)
+	}
+	if err := aggregation.Validate(); err != nil {
+		return nil, xerrors.NewInvalidParamsError(err)
+	}
+
+	encoded, err := s.ReadEncoded(ctx, start, end, nsCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	iter := s.opts.MultiReaderIteratorPool().Get()
+	iter.ResetSliceOfSlices(xio.NewReaderSliceOfSlicesFromBlockReadersIterator(encoded), nsCtx.Schema)
+	defer iter.Close()
+
+	aggregator := newStepAggregator(start, step, aggregation)
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		// Blocks may contain datapoints outside of the requested range.
+		if dp.Timestamp.Before(start) || !dp.Timestamp.Before(end) {
+			continue
+		}
+		aggregator.add(dp)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	return aggregator.finish(), nil
+}
+
 func (s *dbSeries) FetchBlocksForColdFlush(
 	ctx context.Context,
 	start time.Time,
 	version int,
 	nsCtx namespace.Context,
 ) ([]xio.BlockReader, error) {
-	// This needs a write lock because the version on underlying buckets need
-	// to be modified.
+	// Only marking the version on the underlying buckets needs a write lock,
+	// the blocks are read under a read lock so that reads of the series are
+	// not blocked for the duration of the fetch.
 	s.Lock()
-	br, err := s.buffer.FetchBlocksForColdFlush(ctx, start, version, nsCtx)
+	marked, err := s.buffer.MarkColdFlushVersion(start, version)
 	s.Unlock()
+	if err != nil || !marked {
+		return nil, err
+	}
+
+	s.RLock()
+	br, err := s.buffer.FetchBlocksForColdFlush(ctx, start, nsCtx)
+	s.RUnlock()
 
 	return br, err
 }
@@ -338,6 +790,158 @@ func (s *dbSeries) FetchBlocks(
 	return r, err
 }
 
+func (s *dbSeries) FetchBlocksVersioned(
+	ctx context.Context,
+	starts []time.Time,
+	version int,
+	nsCtx namespace.Context,
+) []block.FetchBlockResult {
+	s.RLock()
+	r := s.buffer.FetchBlocksVersioned(ctx, starts, version, nsCtx)
+	s.RUnlock()
+	return r
+}
+
+func (s *dbSeries) ReconcileWithPeer(
+	ctx context.Context,
+	peer ReconcilePeer,
+	blockStarts []time.Time,
+	blockStates BootstrappedBlockStateSnapshot,
+	nsCtx namespace.Context,
+) (int, error) {
+	if len(blockStarts) == 0 {
+		return 0, nil
+	}
+
+	id := s.ID()
+	replicas, err := peer.FetchBlocksMetadata(ctx, id, blockStarts)
+	if err != nil {
+		return 0, err
+	}
+
+	localChecksums, err := s.blockChecksums(ctx, blockStarts, nsCtx)
+	if err != nil {
+		return 0, err
+	}
+
+	// A block is consistent if any replica matches the local checksum,
+	// otherwise it is healed from the first replica with a checksum.
+	var (
+		consistent = make(map[xtime.UnixNano]struct{}, len(replicas))
+		candidates = make(map[xtime.UnixNano]block.ReplicaMetadata, len(replicas))
+	)
+	for _, replica := range replicas {
+		if replica.Checksum == nil {
+			continue
+		}
+		blockStart := xtime.ToUnixNano(replica.Start)
+		local, ok := localChecksums[blockStart]
+		if ok && local == *replica.Checksum {
+			consistent[blockStart] = struct{}{}
+			continue
+		}
+		if _, ok := candidates[blockStart]; !ok {
+			candidates[blockStart] = replica
+		}
+	}
+
+	differing := make([]block.ReplicaMetadata, 0, len(candidates))
+	for blockStart, replica := range candidates {
+		if _, ok := consistent[blockStart]; ok {
+			continue
+		}
+		differing = append(differing, replica)
+	}
+	if len(differing) == 0 {
+		return 0, nil
+	}
+
+	blocks, err := peer.FetchBlocks(ctx, differing)
+	if err != nil {
+		return 0, err
+	}
+
+	// The healed blocks are loaded into the buffer so that they are
+	// persisted by the next warm or cold flush, the buffer takes ownership
+	// of the blocks loaded and the remainder are closed here.
+	healedBlocks := block.NewDatabaseSeriesBlocks(len(blocks))
+	for _, b := range blocks {
+		if b.Len() == 0 {
+			b.Close()
+			continue
+		}
+		if _, ok := healedBlocks.BlockAt(b.StartTime()); ok {
+			b.Close()
+			continue
+		}
+		healedBlocks.AddBlock(b)
+	}
+	if healedBlocks.Len() == 0 {
+		return 0, nil
+	}
+
+	s.Lock()
+	numCorrupt := s.loadWithLock(LoadOptions{SchemaDesc: nsCtx.Schema},
+		healedBlocks, blockStates)
+	s.Unlock()
+
+	return healedBlocks.Len() - numCorrupt, nil
+}
+
+// blockChecksums returns the checksums of the blocks the series holds at the
+// given block starts, merging the blocks from the buffer and disk if both
+// hold data for a block start.
+func (s *dbSeries) blockChecksums(
+	ctx context.Context,
+	blockStarts []time.Time,
+	nsCtx namespace.Context,
+) (map[xtime.UnixNano]uint32, error) {
+	results, err := s.FetchBlocks(ctx, blockStarts, nsCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	checksums := make(map[xtime.UnixNano]uint32, len(results))
+	for _, result := range results {
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		var segment ts.Segment
+		switch len(result.Blocks) {
+		case 0:
+			continue
+		case 1:
+			segment, err = result.Blocks[0].Segment()
+			if err != nil {
+				return nil, err
+			}
+		default:
+			streams := make([]xio.SegmentReader, 0, len(result.Blocks))
+			for _, br := range result.Blocks {
+				streams = append(streams, br.SegmentReader)
+			}
+			encoder, _, err := mergeStreamsToEncoder(result.Start, streams, s.opts, nsCtx)
+			if err != nil {
+				return nil, err
+			}
+			merged := encoder.Discard()
+			if merged.Len() > 0 {
+				checksums[xtime.ToUnixNano(result.Start)] = digest.SegmentChecksum(merged)
+			}
+			merged.Finalize()
+			continue
+		}
+
+		if segment.Len() == 0 {
+			continue
+		}
+		checksums[xtime.ToUnixNano(result.Start)] = digest.SegmentChecksum(segment)
+	}
+
+	return checksums, nil
+}
+
 func (s *dbSeries) FetchBlocksMetadata(
 	ctx context.Context,
 	start, end time.Time,
@@ -356,6 +960,10 @@ func (s *dbSeries) FetchBlocksMetadata(
 		if !start.Before(t.Add(blockSize)) || !t.Before(end) {
 			continue
 		}
+		if opts.Cursor != nil && t.Before(opts.Cursor.BlockStart) {
+			// Already returned by a previous page.
+			continue
+		}
 		if !opts.IncludeCachedBlocks && b.WasRetrievedFromDisk() {
 			// Do not include cached blocks if not specified to, this is
 			// to avoid high amounts of duplication if a significant number of
@@ -370,6 +978,9 @@ func (s *dbSeries) FetchBlocksMetadata(
 		)
 		if opts.IncludeSizes {
 			size = int64(b.Len())
+			if size < opts.MinBlockSizeBytes {
+				continue
+			}
 		}
 		if opts.IncludeChecksums {
 			v, err := b.Checksum()
@@ -396,6 +1007,9 @@ func (s *dbSeries) FetchBlocksMetadata(
 			return block.FetchBlocksMetadataResult{}, err
 		}
 		for _, result := range bufferResults.Results() {
+			if opts.Cursor != nil && result.Start.Before(opts.Cursor.BlockStart) {
+				continue
+			}
 			res.Add(result)
 		}
 		bufferResults.Close()
@@ -403,11 +1017,56 @@ func (s *dbSeries) FetchBlocksMetadata(
 
 	res.Sort()
 
+	var nextCursor *block.FetchBlocksMetadataCursor
+	if opts.Cursor != nil || opts.Limit > 0 {
+		nextCursor = pageFetchBlockMetadataResults(res, opts.Cursor, opts.Limit)
+	}
+
 	// NB(r): Since ID and Tags are garbage collected we can safely
 	// return refs.
 	tagsIter := s.opts.IdentifierPool().TagsIterator()
 	tagsIter.Reset(s.tags)
-	return block.NewFetchBlocksMetadataResult(s.id, tagsIter, res), nil
+	result := block.NewFetchBlocksMetadataResult(s.id, tagsIter, res)
+	result.EncodedTags = s.encodedTags
+	result.NextCursor = nextCursor
+	return result, nil
+}
+
+// pageFetchBlockMetadataResults reduces the sorted results to the page of at
+// most limit results following the cursor, results with a block start before
+// the cursor are expected to have been skipped already. Returns the cursor
+// for the next page, or nil if no results remain.
+func pageFetchBlockMetadataResults(
+	res block.FetchBlockMetadataResults,
+	cursor *block.FetchBlocksMetadataCursor,
+	limit int,
+) *block.FetchBlocksMetadataCursor {
+	var (
+		results = res.Results()
+		first   int
+	)
+	if cursor != nil {
+		for first < len(results) && first < cursor.Offset &&
+			results[first].Start.Equal(cursor.BlockStart) {
+			first++
+		}
+	}
+
+	last := len(results)
+	if limit > 0 && first+limit < last {
+		last = first + limit
+	}
+
+	var next *block.FetchBlocksMetadataCursor
+	if last < len(results) {
+		next = &block.FetchBlocksMetadataCursor{BlockStart: results[last].Start}
+		for i := last - 1; i >= 0 && results[i].Start.Equal(next.BlockStart); i-- {
+			next.Offset++
+		}
+	}
+
+	res.Slice(first, last)
+	return next
 }
 
 func (s *dbSeries) addBlockWithLock(b block.DatabaseBlock) {
@@ -421,15 +1080,16 @@ func (s *dbSeries) Load(
 	blockStates BootstrappedBlockStateSnapshot,
 ) (LoadResult, error) {
 	if opts.Bootstrap {
-		bsResult, err := s.bootstrap(bootstrappedBlocks, blockStates)
+		bsResult, err := s.bootstrap(opts, bootstrappedBlocks, blockStates)
 		return LoadResult{Bootstrap: bsResult}, err
 	}
 
-	s.load(bootstrappedBlocks, blockStates)
+	s.load(opts, bootstrappedBlocks, blockStates)
 	return LoadResult{}, nil
 }
 
 func (s *dbSeries) bootstrap(
+	opts LoadOptions,
 	bootstrappedBlocks block.DatabaseSeriesBlocks,
 	blockStates BootstrappedBlockStateSnapshot,
 ) (BootstrapResult, error) {
@@ -440,43 +1100,70 @@ func (s *dbSeries) bootstrap(
 	}()
 
 	var result BootstrapResult
-	if s.bs == bootstrapped {
+	if s.bs == bootstrapped && !s.opts.MergeDuplicateBootstrap() {
 		return result, errSeriesAlreadyBootstrapped
 	}
 
+	// NB: If the series is already bootstrapped and merging duplicate
+	// bootstraps is enabled the blocks are loaded into the buffer alongside
+	// the existing data, duplicate datapoints are resolved when the buffer
+	// buckets are merged, the same as for blocks loaded after bootstrap.
+
 	if bootstrappedBlocks == nil {
 		return result, nil
 	}
 
-	s.loadWithLock(bootstrappedBlocks, blockStates)
-	result.NumBlocksMovedToBuffer += int64(bootstrappedBlocks.Len())
+	numCorrupt := s.loadWithLock(opts, bootstrappedBlocks, blockStates)
+	result.NumBlocksMovedToBuffer += int64(bootstrappedBlocks.Len() - numCorrupt)
+	result.NumBlocksCorrupt += int64(numCorrupt)
 
 	return result, nil
 }
 
 func (s *dbSeries) load(
+	opts LoadOptions,
 	bootstrappedBlocks block.DatabaseSeriesBlocks,
 	blockStates BootstrappedBlockStateSnapshot,
 ) {
 	s.Lock()
-	s.loadWithLock(bootstrappedBlocks, blockStates)
+	s.loadWithLock(opts, bootstrappedBlocks, blockStates)
 	s.Unlock()
 }
 
+// loadWithLock loads the blocks into the buffer and returns the number of
+// blocks that were skipped due to failing verification.
 func (s *dbSeries) loadWithLock(
+	opts LoadOptions,
 	bootstrappedBlocks block.DatabaseSeriesBlocks,
 	blockStates BootstrappedBlockStateSnapshot,
-) {
+) int {
+	numCorrupt := 0
 	for _, block := range bootstrappedBlocks.AllBlocks() {
+		if s.opts.VerifyBlocksOnLoad() {
+			if err := s.verifyBlock(block, opts.SchemaDesc); err != nil {
+				s.opts.InstrumentOptions().Logger().Warn("skipping corrupt block on load",
+					zap.Stringer("id", s.id),
+					zap.Time("blockStart", block.StartTime()),
+					zap.Error(err))
+				block.Close()
+				numCorrupt++
+				continue
+			}
+		}
+
 		blStartNano := xtime.ToUnixNano(block.StartTime())
 		blState := blockStates.Snapshot[blStartNano]
 		if !blState.WarmRetrievable {
 			// If the block being bootstrapped has never been warm flushed before then the block
 			// can be loaded into the buffer as a WarmWrite because a subsequent warm flush will
 			// ensure that it gets persisted to disk.
-			//
-			// If the ColdWrites feature is disabled then this branch should always be followed.
 			s.buffer.Load(block, WarmWrite)
+		} else if !s.opts.ColdWritesEnabled() {
+			// If the ColdWrites feature is disabled there will never be a cold flush to persist
+			// a block loaded as a ColdWrite, nor another warm flush of the block start. The block
+			// is loaded into the already warm flushed version so that it is evicted by the next
+			// tick rather than being held in the buffer forever.
+			s.buffer.LoadWarmRetrievable(block)
 		} else {
 			// If the block being bootstrapped has been warm flushed before then the block should
 			// be loaded into the buffer as a ColdWrite so that a subsequent cold flush will ensure
@@ -489,6 +1176,45 @@ func (s *dbSeries) loadWithLock(
 			s.buffer.Load(block, ColdWrite)
 		}
 	}
+	return numCorrupt
+}
+
+// verifyBlock checks that the block data matches the block checksum and
+// that the block data can be decoded in full.
+func (s *dbSeries) verifyBlock(b block.DatabaseBlock, schema namespace.SchemaDescr) error {
+	checksum, err := b.Checksum()
+	if err != nil {
+		return err
+	}
+
+	ctx := s.opts.ContextPool().Get()
+	defer ctx.Close()
+
+	stream, err := b.Stream(ctx)
+	if err != nil {
+		return err
+	}
+	if stream.SegmentReader == nil {
+		return nil
+	}
+
+	segment, err := stream.Segment()
+	if err != nil {
+		return err
+	}
+	if actual := digest.SegmentChecksum(segment); actual != checksum {
+		return fmt.Errorf("block checksum mismatch: expected=%d, actual=%d",
+			checksum, actual)
+	}
+
+	iter := s.opts.MultiReaderIteratorPool().Get()
+	defer iter.Close()
+
+	iter.Reset([]xio.SegmentReader{stream.SegmentReader},
+		stream.Start, stream.BlockSize, schema)
+	for iter.Next() {
+	}
+	return iter.Err()
 }
 
 func (s *dbSeries) OnRetrieveBlock(
@@ -498,6 +1224,8 @@ func (s *dbSeries) OnRetrieveBlock(
 	segment ts.Segment,
 	nsCtx namespace.Context,
 ) {
+	s.opts.Stats().incCacheRetrieve(s.opts.CachePolicy())
+
 	var (
 		b    block.DatabaseBlock
 		list *block.WiredList
@@ -544,6 +1272,8 @@ func (s *dbSeries) OnRetrieveBlock(
 // OnReadBlock is only called for blocks that were read from memory, regardless of
 // whether the data originated from disk or buffer rotation.
 func (s *dbSeries) OnReadBlock(b block.DatabaseBlock) {
+	s.opts.Stats().incCacheReadHit(s.opts.CachePolicy())
+
 	if list := s.opts.DatabaseBlockOptions().WiredList(); list != nil {
 		// The WiredList is only responsible for managing the lifecycle of blocks
 		// retrieved from disk.
@@ -630,6 +1360,30 @@ func (s *dbSeries) ColdFlushBlockStarts(blockStates BootstrappedBlockStateSnapsh
 	return s.buffer.ColdFlushBlockStarts(blockStates.Snapshot)
 }
 
+func (s *dbSeries) BufferDebugInfo() []BufferBlockDebugInfo {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.buffer.DebugInfo()
+}
+
+func (s *dbSeries) CachedBlockBufferOverlap() OptimizedTimes {
+	s.RLock()
+	defer s.RUnlock()
+
+	var overlap OptimizedTimes
+	bufferStarts := s.buffer.BlockStarts()
+	if bufferStarts.Len() == 0 {
+		return overlap
+	}
+	for t := range s.cachedBlocks.AllBlocks() {
+		if bufferStarts.Contains(t) {
+			overlap.Add(t)
+		}
+	}
+	return overlap
+}
+
 func (s *dbSeries) Close() {
 	s.Lock()
 	defer s.Unlock()
@@ -637,6 +1391,7 @@ func (s *dbSeries) Close() {
 	// See Reset() for why these aren't finalized
 	s.id = nil
 	s.tags = ident.Tags{}
+	s.encodedTags = nil
 
 	switch s.opts.CachePolicy() {
 	case CacheLRU:
@@ -659,6 +1414,29 @@ func (s *dbSeries) Close() {
 	}
 }
 
+// encodeTags returns an owned copy of the serialized tags, or nil if the tags
+// could not be encoded in which case callers fall back to encoding the tags
+// from an iterator.
+func encodeTags(tags ident.Tags, opts Options) []byte {
+	enc := opts.TagEncoderPool().Get()
+	defer enc.Finalize()
+
+	tagsIter := opts.IdentifierPool().TagsIterator()
+	tagsIter.Reset(tags)
+	defer tagsIter.Close()
+
+	if err := enc.Encode(tagsIter); err != nil {
+		opts.InstrumentOptions().Logger().Warn("unable to encode series tags",
+			zap.Error(err))
+		return nil
+	}
+	data, ok := enc.Data()
+	if !ok {
+		return nil
+	}
+	return append([]byte(nil), data.Bytes()...)
+}
+
 func (s *dbSeries) Reset(
 	id ident.ID,
 	tags ident.Tags,
@@ -687,12 +1465,18 @@ func (s *dbSeries) Reset(
 	// a long period of time.
 	s.id = id
 	s.tags = tags
+	s.encodedTags = nil
+	if opts.CacheEncodedTags() && len(tags.Values()) > 0 {
+		s.encodedTags = encodeTags(tags, opts)
+	}
 
 	s.cachedBlocks.Reset()
 	s.buffer.Reset(id, opts)
 	s.opts = opts
 	s.bs = bootstrapNotStarted
+	s.blockStatesBootstrapped = false
 	s.blockRetriever = blockRetriever
 	s.onRetrieveBlock = onRetrieveBlock
 	s.blockOnEvictedFromWiredList = onEvictedFromWiredList
+	s.lastWrite = s.now()
 }
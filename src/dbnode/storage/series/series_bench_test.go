@@ -0,0 +1,104 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package series
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/namespace"
+	"github.com/m3db/m3/src/x/context"
+	"github.com/m3db/m3/src/x/ident"
+	xtime "github.com/m3db/m3/src/x/time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// BenchmarkSeriesReadDuringColdFlush measures reads of a series while its
+// cold data is repeatedly fetched for a cold flush, which is sensitive to
+// how long the cold flush holds the series lock.
+func BenchmarkSeriesReadDuringColdFlush(b *testing.B) {
+	var (
+		opts       = newSeriesTestOptions().SetColdWritesEnabled(true)
+		blockSize  = opts.RetentionOptions().BlockSize()
+		blockStart = time.Now().Truncate(blockSize).Add(-10 * blockSize)
+		series     = NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+		nsCtx      = namespace.Context{}
+	)
+
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	require.NoError(b, err)
+
+	writeCtx := context.NewContext()
+	defer writeCtx.Close()
+
+	write := func(i int) {
+		ts := blockStart.Add(time.Duration(i) * time.Millisecond)
+		if _, err := series.Write(writeCtx, ts, float64(i), xtime.Millisecond, nil, WriteOptions{}); err != nil {
+			panic(err)
+		}
+	}
+	for i := 0; i < 10000; i++ {
+		write(i)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for version := 1; ; version++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			// Write a new cold point so that there is always a writable cold
+			// bucket to mark for the flush.
+			write(10000 + version)
+
+			ctx := context.NewContext()
+			if _, err := series.FetchBlocksForColdFlush(ctx, blockStart, version, nsCtx); err != nil {
+				panic(err)
+			}
+			ctx.BlockingClose()
+		}
+	}()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ctx := context.NewContext()
+			if _, err := series.ReadEncoded(ctx, blockStart, blockStart.Add(blockSize), nsCtx); err != nil {
+				panic(err)
+			}
+			ctx.BlockingClose()
+		}
+	})
+	b.StopTimer()
+
+	close(done)
+	wg.Wait()
+}
@@ -22,6 +22,7 @@ package series
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"testing"
 	"time"
@@ -30,20 +31,24 @@ import (
 	"github.com/m3db/m3/src/dbnode/digest"
 	"github.com/m3db/m3/src/dbnode/encoding"
 	"github.com/m3db/m3/src/dbnode/encoding/m3tsz"
+	"github.com/m3db/m3/src/dbnode/encoding/tuple"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	m3dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/x/checked"
 	"github.com/m3db/m3/src/x/context"
 	xerrors "github.com/m3db/m3/src/x/errors"
 	"github.com/m3db/m3/src/x/ident"
+	"github.com/m3db/m3/src/x/serialize"
 	xtime "github.com/m3db/m3/src/x/time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/m3db/m3/src/dbnode/namespace"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 )
 
 func newSeriesTestOptions() Options {
@@ -132,6 +137,394 @@ func TestSeriesWriteFlush(t *testing.T) {
 	requireSegmentValuesEqual(t, data[:2], streams, opts, namespace.Context{})
 }
 
+func TestSeriesWriteColdWritesDisabled(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	opts := newSeriesTestOptions().SetStats(NewStats(scope))
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	require.NoError(t, err)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	// Check out of window writes are rejected with an error by default.
+	wasWritten, err := series.Write(ctx, curr.Add(-rops.BufferPast()), 1,
+		xtime.Second, nil, WriteOptions{})
+	require.Error(t, err)
+	require.True(t, xerrors.IsInvalidParams(err))
+	require.False(t, wasWritten)
+
+	// Check out of window writes are silently dropped when configured.
+	wasWritten, err = series.Write(ctx, curr.Add(rops.BufferFuture()), 1,
+		xtime.Second, nil, WriteOptions{DropDisabledColdWrites: true})
+	require.NoError(t, err)
+	require.False(t, wasWritten)
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(1), counters["series.cold-writes-disabled-rejected+"].Value())
+	require.Equal(t, int64(1), counters["series.cold-writes-disabled-dropped+"].Value())
+}
+
+func TestSeriesWriteOutsideRetention(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scope := tally.NewTestScope("", nil)
+	opts := newSeriesTestOptions().
+		SetColdWritesEnabled(true).
+		SetStats(NewStats(scope))
+	rops := opts.RetentionOptions().SetFutureRetentionPeriod(time.Hour)
+	opts = opts.SetRetentionOptions(rops)
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	require.NoError(t, err)
+	buffer := NewMockdatabaseBuffer(ctrl)
+	series.buffer = buffer
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	var (
+		earliest = curr.Add(-rops.RetentionPeriod())
+		latest   = curr.Add(rops.FutureRetentionPeriod()).Add(rops.BlockSize())
+	)
+	// The earliest timestamp is inclusive and the latest is exclusive.
+	for _, timestamp := range []time.Time{earliest, latest.Add(-time.Nanosecond)} {
+		buffer.EXPECT().Write(ctx, timestamp, 1.0, xtime.Second, []byte(nil), WriteOptions{}).
+			Return(true, nil)
+		wasWritten, err := series.Write(ctx, timestamp, 1, xtime.Second, nil, WriteOptions{})
+		require.NoError(t, err)
+		require.True(t, wasWritten)
+	}
+
+	// Writes outside of retention are rejected before reaching the buffer.
+	for _, timestamp := range []time.Time{earliest.Add(-time.Nanosecond), latest} {
+		wasWritten, err := series.Write(ctx, timestamp, 1, xtime.Second, nil, WriteOptions{})
+		require.Error(t, err)
+		require.True(t, xerrors.IsInvalidParams(err))
+		require.True(t, m3dberrors.IsWriteOutsideRetentionError(err))
+		require.False(t, wasWritten)
+	}
+
+	written, err := series.WriteBatch(ctx, []ValueDatapoint{
+		{Timestamp: earliest.Add(-time.Nanosecond), Value: 1, Unit: xtime.Second},
+	}, WriteOptions{})
+	require.True(t, m3dberrors.IsWriteOutsideRetentionError(err))
+	require.Equal(t, 0, written)
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(3), counters["series.write-outside-retention-rejected+"].Value())
+}
+
+func TestSeriesCacheStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scope := tally.NewTestScope("", nil)
+	opts := newSeriesTestOptions().
+		SetCachePolicy(CacheRecentlyRead).
+		SetStats(NewStats(scope))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+
+	series.OnReadBlock(block.NewMockDatabaseBlock(ctrl))
+	series.OnReadBlock(block.NewMockDatabaseBlock(ctrl))
+	// The retrieval is recorded even if the series has since been reused.
+	series.OnRetrieveBlock(ident.StringID("bar"), ident.EmptyTagIterator,
+		time.Now(), ts.Segment{}, namespace.Context{})
+	opts.Stats().UpdateCacheActiveBlocks(CacheRecentlyRead, 3)
+
+	snapshot := scope.Snapshot()
+	counters := snapshot.Counters()
+	require.Equal(t, int64(2), counters["series.cache.read-hit+policy=recently_read"].Value())
+	require.Equal(t, int64(1), counters["series.cache.retrieve+policy=recently_read"].Value())
+	require.Equal(t, int64(0), counters["series.cache.read-hit+policy=lru"].Value())
+	gauges := snapshot.Gauges()
+	require.Equal(t, float64(3), gauges["series.cache.active-blocks+policy=recently_read"].Value())
+}
+
+type testWriteBackpressure struct {
+	applies bool
+}
+
+func (b *testWriteBackpressure) Applies() bool {
+	return b.applies
+}
+
+func TestSeriesWriteBackpressure(t *testing.T) {
+	backpressure := &testWriteBackpressure{applies: true}
+	opts := newSeriesTestOptions().SetWriteBackpressure(backpressure)
+	curr := time.Now().Truncate(opts.RetentionOptions().BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	require.NoError(t, err)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	// Check the write is rejected without mutating the buffer.
+	wasWritten, err := series.Write(ctx, curr, 1, xtime.Second, nil, WriteOptions{})
+	require.Error(t, err)
+	require.True(t, xerrors.IsRetryableError(err))
+	require.False(t, wasWritten)
+	require.True(t, series.buffer.IsEmpty())
+
+	backpressure.applies = false
+	wasWritten, err = series.Write(ctx, curr, 1, xtime.Second, nil, WriteOptions{})
+	require.NoError(t, err)
+	require.True(t, wasWritten)
+	require.False(t, series.buffer.IsEmpty())
+}
+
+func TestSeriesEstimatedMemoryBytes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSeriesTestOptions()
+	curr := time.Now().Truncate(opts.RetentionOptions().BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	tags := ident.NewTags(ident.StringTag("name", "value"))
+	series := NewDatabaseSeries(ident.StringID("foo"), tags, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	require.NoError(t, err)
+
+	idAndTagsBytes := int64(len("foo") + len("name") + len("value"))
+	require.Equal(t, idAndTagsBytes, series.EstimatedMemoryBytes())
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	wasWritten, err := series.Write(ctx, curr, 1, xtime.Second, nil, WriteOptions{})
+	require.NoError(t, err)
+	require.True(t, wasWritten)
+
+	bufferBytes := series.buffer.EstimatedMemoryBytes()
+	require.True(t, bufferBytes > 0)
+
+	b := block.NewMockDatabaseBlock(ctrl)
+	b.EXPECT().StartTime().Return(curr.Add(-opts.RetentionOptions().BlockSize()))
+	b.EXPECT().Len().Return(100)
+	series.cachedBlocks.AddBlock(b)
+
+	require.Equal(t, idAndTagsBytes+bufferBytes+100, series.EstimatedMemoryBytes())
+}
+
+func TestSeriesWriteStageTimings(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	opts := newSeriesTestOptions().
+		SetStats(NewStats(scope).SetWriteStageTimingsEnabled(true))
+	curr := time.Now().Truncate(opts.RetentionOptions().BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	require.NoError(t, err)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	for i := 0; i < 3; i++ {
+		wasWritten, err := series.Write(ctx, curr.Add(time.Duration(i)*time.Second),
+			float64(i), xtime.Second, nil, WriteOptions{})
+		require.NoError(t, err)
+		require.True(t, wasWritten)
+	}
+
+	// A batch records each stage once since it is written under one lock.
+	written, err := series.WriteBatch(ctx, []ValueDatapoint{
+		{Timestamp: curr.Add(3 * time.Second), Value: 3, Unit: xtime.Second},
+		{Timestamp: curr.Add(4 * time.Second), Value: 4, Unit: xtime.Second},
+	}, WriteOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, written)
+
+	histograms := scope.Snapshot().Histograms()
+	for _, name := range []string{
+		"series.write-lock-wait-latency+",
+		"series.write-buffer-latency+",
+	} {
+		h, ok := histograms[name]
+		require.True(t, ok, name)
+		var recorded int64
+		for _, count := range h.Durations() {
+			recorded += count
+		}
+		require.Equal(t, int64(4), recorded, name)
+	}
+}
+
+func TestSeriesWriteTupleReadTuples(t *testing.T) {
+	opts := newSeriesTestOptions()
+	curr := time.Now().Truncate(opts.RetentionOptions().BlockSize())
+	start := curr
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	require.NoError(t, err)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	input := [][]float64{{1, 10}, {2, 20}, {2, 20}, {3, 30}}
+	for i, values := range input {
+		curr = start.Add(time.Duration(i) * time.Second)
+		wasWritten, err := series.WriteTuple(ctx, curr, values, xtime.Second, WriteOptions{})
+		require.NoError(t, err)
+		require.True(t, wasWritten)
+	}
+
+	_, err = series.WriteTuple(ctx, curr, nil, xtime.Second, WriteOptions{})
+	require.Error(t, err)
+	require.True(t, xerrors.IsInvalidParams(err))
+	require.Equal(t, ErrTupleNoValues, xerrors.GetInnerInvalidParamsError(err))
+
+	results, err := series.ReadEncoded(ctx, start, curr.Add(time.Second), namespace.Context{})
+	require.NoError(t, err)
+
+	multiIter := opts.MultiReaderIteratorPool().Get()
+	multiIter.ResetSliceOfSlices(xio.NewReaderSliceOfSlicesFromBlockReadersIterator(results), nil)
+	iter := tuple.NewIterator(multiIter)
+	defer iter.Close()
+
+	var i int
+	for iter.Next() {
+		dp, _, values := iter.Current()
+		require.True(t, start.Add(time.Duration(i)*time.Second).Equal(dp.Timestamp))
+		require.Equal(t, input[i][0], dp.Value)
+		require.Equal(t, input[i], values)
+		i++
+	}
+	require.NoError(t, iter.Err())
+	require.Equal(t, len(input), i)
+}
+
+func TestSeriesWriteTupleTransformsEveryValue(t *testing.T) {
+	opts := newSeriesTestOptions()
+	curr := time.Now().Truncate(opts.RetentionOptions().BlockSize())
+	start := curr
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	require.NoError(t, err)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	wOpts := WriteOptions{
+		TransformOptions: WriteTransformOptions{
+			ValueBoundsEnabled: true,
+			MinValue:           0,
+			MaxValue:           10,
+			ClampValues:        true,
+			RoundToMultiple:    0.5,
+		},
+	}
+	wasWritten, err := series.WriteTuple(ctx, curr, []float64{1.3, 20, -3},
+		xtime.Second, wOpts)
+	require.NoError(t, err)
+	require.True(t, wasWritten)
+
+	// Values are rejected if any value of the tuple is out of bounds.
+	wOpts.TransformOptions.ClampValues = false
+	_, err = series.WriteTuple(ctx, curr.Add(time.Second), []float64{1, 20},
+		xtime.Second, wOpts)
+	require.Error(t, err)
+
+	curr = curr.Add(2 * time.Second)
+	wOpts.TransformOptions = WriteTransformOptions{
+		ForceValueEnabled: true,
+		ForceValue:        42,
+	}
+	wasWritten, err = series.WriteTuple(ctx, curr, []float64{1, 2}, xtime.Second, wOpts)
+	require.NoError(t, err)
+	require.True(t, wasWritten)
+
+	results, err := series.ReadEncoded(ctx, start, curr.Add(time.Second), namespace.Context{})
+	require.NoError(t, err)
+
+	multiIter := opts.MultiReaderIteratorPool().Get()
+	multiIter.ResetSliceOfSlices(xio.NewReaderSliceOfSlicesFromBlockReadersIterator(results), nil)
+	iter := tuple.NewIterator(multiIter)
+	defer iter.Close()
+
+	expected := [][]float64{{1.5, 10, 0}, {42, 42}}
+	var i int
+	for iter.Next() {
+		dp, _, values := iter.Current()
+		require.Equal(t, expected[i][0], dp.Value)
+		require.Equal(t, expected[i], values)
+		i++
+	}
+	require.NoError(t, iter.Err())
+	require.Equal(t, len(expected), i)
+}
+
+func TestSeriesWriteBatch(t *testing.T) {
+	opts := newSeriesTestOptions()
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	require.NoError(t, err)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	datapoints := []ValueDatapoint{
+		{Timestamp: curr, Value: 1, Unit: xtime.Second, Annotation: []byte("a")},
+		// The same point is not written twice and so is not counted.
+		{Timestamp: curr, Value: 1, Unit: xtime.Second, Annotation: []byte("a")},
+		{Timestamp: curr.Add(time.Second), Value: 2, Unit: xtime.Second, Annotation: []byte("b")},
+		// Out of window writes are rejected as cold writes are disabled.
+		{Timestamp: curr.Add(-rops.BufferPast()), Value: 3, Unit: xtime.Second},
+		{Timestamp: curr.Add(2 * time.Second), Value: 4, Unit: xtime.Second},
+	}
+	written, err := series.WriteBatch(ctx, datapoints, WriteOptions{})
+	require.Error(t, err)
+	require.True(t, xerrors.IsInvalidParams(err))
+	require.Equal(t, 2, written)
+
+	results, err := series.ReadEncoded(ctx, curr, curr.Add(time.Minute), namespace.Context{})
+	require.NoError(t, err)
+
+	multiIter := opts.MultiReaderIteratorPool().Get()
+	multiIter.ResetSliceOfSlices(xio.NewReaderSliceOfSlicesFromBlockReadersIterator(results), nil)
+	defer multiIter.Close()
+
+	expected := []ValueDatapoint{datapoints[0], datapoints[2]}
+	var i int
+	for multiIter.Next() {
+		dp, _, annotation := multiIter.Current()
+		require.True(t, expected[i].Timestamp.Equal(dp.Timestamp))
+		require.Equal(t, expected[i].Value, dp.Value)
+		require.Equal(t, expected[i].Annotation, []byte(annotation))
+		i++
+	}
+	require.NoError(t, multiIter.Err())
+	require.Equal(t, len(expected), i)
+}
+
 func TestSeriesSamePointDoesNotWrite(t *testing.T) {
 	opts := newSeriesTestOptions()
 	rops := opts.RetentionOptions()
@@ -221,8 +614,17 @@ func TestSeriesWriteFlushRead(t *testing.T) {
 // data into the series and that the data (merged with any existing data) can be retrieved.
 //
 // It also ensures that blocks for blockStarts that have not been warm flushed yet are loaded as
-// warm write and block for blockStarts that have already been warm flushed are loaded as cold writes.
+// warm write and block for blockStarts that have already been warm flushed are loaded as cold writes,
+// unless cold writes are disabled in which case all blocks are loaded as warm writes.
 func TestSeriesBootstrapAndLoad(t *testing.T) {
+	for _, coldWritesEnabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("coldWritesEnabled=%v", coldWritesEnabled), func(t *testing.T) {
+			testSeriesBootstrapAndLoad(t, coldWritesEnabled)
+		})
+	}
+}
+
+func testSeriesBootstrapAndLoad(t *testing.T, coldWritesEnabled bool) {
 	testCases := []struct {
 		title string
 		f     func(
@@ -252,7 +654,7 @@ func TestSeriesBootstrapAndLoad(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.title, func(t *testing.T) {
 			var (
-				opts      = newSeriesTestOptions()
+				opts      = newSeriesTestOptions().SetColdWritesEnabled(coldWritesEnabled)
 				blockSize = opts.RetentionOptions().BlockSize()
 				curr      = time.Now().Truncate(blockSize)
 				start     = curr
@@ -330,13 +732,115 @@ func TestSeriesBootstrapAndLoad(t *testing.T) {
 					coldFlushBlockStarts = append(coldFlushBlockStarts, blockStart)
 				})
 
-				expectedColdFlushBlockStarts := []xtime.UnixNano{xtime.ToUnixNano(alreadyWarmFlushedBlockStart)}
+				expectedColdFlushBlockStarts := []xtime.UnixNano{}
+				if coldWritesEnabled {
+					expectedColdFlushBlockStarts = append(expectedColdFlushBlockStarts,
+						xtime.ToUnixNano(alreadyWarmFlushedBlockStart))
+				}
 				require.Equal(t, expectedColdFlushBlockStarts, coldFlushBlockStarts)
 			})
+
+			if coldWritesEnabled {
+				return
+			}
+
+			t.Run("Flushed blocks evicted on tick when cold writes disabled", func(t *testing.T) {
+				_, err := series.Tick(NewShardBlockStateSnapshot(true, blockStates), nsCtx)
+				require.NoError(t, err)
+
+				buffer := series.buffer.(*dbBuffer)
+				_, exists := buffer.bucketVersionsAt(alreadyWarmFlushedBlockStart)
+				require.False(t, exists)
+				_, exists = buffer.bucketVersionsAt(notYetWarmFlushedBlockStart)
+				require.True(t, exists)
+			})
 		})
 	}
 }
 
+func TestSeriesBootstrapVerifyBlocksOnLoad(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		opts      = newSeriesTestOptions().SetVerifyBlocksOnLoad(true)
+		blockSize = opts.RetentionOptions().BlockSize()
+		start     = time.Now().Truncate(blockSize)
+		blockOpts = opts.DatabaseBlockOptions()
+		blocks    = block.NewDatabaseSeriesBlocks(2)
+		nsCtx     = namespace.Context{}
+	)
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+
+	enc := opts.EncoderPool().Get()
+	enc.Reset(start, 0, nil)
+	dp := ts.Datapoint{Timestamp: start.Add(time.Second), Value: 1}
+	require.NoError(t, enc.Encode(dp, xtime.Second, nil))
+	segment := enc.Discard()
+	blocks.AddBlock(block.NewDatabaseBlock(start, blockSize, segment, blockOpts, nsCtx))
+
+	// Add a block whose data does not match its checksum.
+	corruptStart := start.Add(-blockSize)
+	corruptBlock := block.NewMockDatabaseBlock(ctrl)
+	corruptBlock.EXPECT().StartTime().Return(corruptStart).AnyTimes()
+	corruptBlock.EXPECT().Checksum().Return(digest.SegmentChecksum(segment)+1, nil)
+	corruptBlock.EXPECT().Stream(gomock.Any()).Return(xio.BlockReader{
+		SegmentReader: xio.NewSegmentReader(segment),
+		Start:         corruptStart,
+		BlockSize:     blockSize,
+	}, nil)
+	corruptBlock.EXPECT().Close()
+	blocks.AddBlock(corruptBlock)
+
+	result, err := series.Load(LoadOptions{Bootstrap: true}, blocks,
+		BootstrappedBlockStateSnapshot{})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Bootstrap.NumBlocksMovedToBuffer)
+	require.Equal(t, int64(1), result.Bootstrap.NumBlocksCorrupt)
+}
+
+func TestSeriesBootstrapDuplicate(t *testing.T) {
+	for _, merge := range []bool{false, true} {
+		var (
+			opts      = newSeriesTestOptions().SetMergeDuplicateBootstrap(merge)
+			blockSize = opts.RetentionOptions().BlockSize()
+			start     = time.Now().Truncate(blockSize).Add(-2 * blockSize)
+			blockOpts = opts.DatabaseBlockOptions()
+			nsCtx     = namespace.Context{}
+			data      = []value{
+				{start.Add(time.Second), 1, xtime.Second, nil},
+				{start.Add(2 * time.Second), 2, xtime.Second, nil},
+			}
+			err error
+		)
+		series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+
+		for _, v := range data {
+			enc := opts.EncoderPool().Get()
+			enc.Reset(start, 0, nil)
+			dp := ts.Datapoint{Timestamp: v.timestamp, Value: v.value}
+			require.NoError(t, enc.Encode(dp, v.unit, nil))
+
+			blocks := block.NewDatabaseSeriesBlocks(1)
+			blocks.AddBlock(block.NewDatabaseBlock(start, blockSize, enc.Discard(), blockOpts, nsCtx))
+			_, err = series.Load(LoadOptions{Bootstrap: true}, blocks,
+				BootstrappedBlockStateSnapshot{})
+		}
+
+		if !merge {
+			require.Equal(t, errSeriesAlreadyBootstrapped, err)
+			continue
+		}
+		require.NoError(t, err)
+
+		ctx := context.NewContext()
+		results, err := series.ReadEncoded(ctx, start, start.Add(blockSize), nsCtx)
+		require.NoError(t, err)
+		requireReaderValuesEqual(t, data, results, opts, nsCtx)
+		ctx.Close()
+	}
+}
+
 func TestSeriesReadEndBeforeStart(t *testing.T) {
 	opts := newSeriesTestOptions()
 	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
@@ -353,6 +857,93 @@ func TestSeriesReadEndBeforeStart(t *testing.T) {
 	assert.Nil(t, results)
 }
 
+func TestSeriesReadStepped(t *testing.T) {
+	opts := newSeriesTestOptions()
+	curr := time.Now().Truncate(opts.RetentionOptions().BlockSize())
+	start := curr
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	require.NoError(t, err)
+
+	data := []value{
+		{curr, 1, xtime.Second, nil},
+		{curr.Add(secs(30)), 2, xtime.Second, nil},
+		{curr.Add(mins(1)), 3, xtime.Second, nil},
+		{curr.Add(mins(1) + secs(10)), 4, xtime.Second, nil},
+		{curr.Add(mins(3)), 5, xtime.Second, nil},
+	}
+	for _, v := range data {
+		curr = v.timestamp
+		verifyWriteToSeries(t, series, v)
+	}
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	results, err := series.ReadStepped(ctx, start, start.Add(mins(2)),
+		time.Minute, StepAggregationSum, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(results))
+	assert.True(t, start.Equal(results[0].Timestamp))
+	assert.Equal(t, float64(3), results[0].Value)
+	assert.True(t, start.Add(mins(1)).Equal(results[1].Timestamp))
+	assert.Equal(t, float64(7), results[1].Value)
+
+	_, err = series.ReadStepped(ctx, start, start.Add(mins(2)),
+		0, StepAggregationSum, namespace.Context{})
+	require.Error(t, err)
+	require.True(t, xerrors.IsInvalidParams(err))
+}
+
+func TestSeriesReadFiltered(t *testing.T) {
+	opts := newSeriesTestOptions()
+	curr := time.Now().Truncate(opts.RetentionOptions().BlockSize())
+	start := curr
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	require.NoError(t, err)
+
+	data := []value{
+		{curr, 1, xtime.Second, []byte("good")},
+		{curr.Add(mins(1)), 2, xtime.Second, nil},
+		{curr.Add(mins(2)), 3, xtime.Second, []byte("bad")},
+		{curr.Add(mins(3)), 4, xtime.Second, nil},
+		{curr.Add(mins(4)), 5, xtime.Second, []byte("good")},
+	}
+	for _, v := range data {
+		curr = v.timestamp
+		verifyWriteToSeries(t, series, v)
+	}
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	results, err := series.ReadFiltered(ctx, start, start.Add(mins(5)),
+		ReadOptions{}, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, len(data), len(results))
+
+	// Datapoints without an annotation use the last annotation written.
+	results, err = series.ReadFiltered(ctx, start, start.Add(mins(5)),
+		ReadOptions{
+			AnnotationPredicate: func(annotation ts.Annotation) bool {
+				return string(annotation) == "good"
+			},
+		}, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(results))
+	for i, expected := range []value{data[0], data[1], data[4]} {
+		assert.True(t, expected.timestamp.Equal(results[i].Timestamp))
+		assert.Equal(t, expected.value, results[i].Value)
+	}
+}
+
 func TestSeriesFlushNoBlock(t *testing.T) {
 	opts := newSeriesTestOptions()
 	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
@@ -399,6 +990,32 @@ func TestSeriesFlush(t *testing.T) {
 	}
 }
 
+func TestSeriesBootstrapState(t *testing.T) {
+	opts := newSeriesTestOptions()
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	require.Equal(t, BootstrapNotStarted, series.BootstrapState())
+	require.False(t, series.IsBootstrapped())
+
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	require.NoError(t, err)
+	require.Equal(t, SeriesBootstrapped, series.BootstrapState())
+	require.True(t, series.IsBootstrapped())
+
+	// Ticking without bootstrapped block states does not change the state.
+	_, err = series.Tick(NewShardBlockStateSnapshot(false, BootstrappedBlockStateSnapshot{}), namespace.Context{})
+	require.Equal(t, ErrSeriesAllDatapointsExpired, err)
+	require.Equal(t, SeriesBootstrapped, series.BootstrapState())
+
+	_, err = series.Tick(NewShardBlockStateSnapshot(true, BootstrappedBlockStateSnapshot{}), namespace.Context{})
+	require.Equal(t, ErrSeriesAllDatapointsExpired, err)
+	require.Equal(t, BlockStateBootstrapped, series.BootstrapState())
+	require.True(t, series.IsBootstrapped())
+
+	series.Reset(ident.StringID("bar"), ident.Tags{}, nil, nil, nil, opts)
+	require.Equal(t, BootstrapNotStarted, series.BootstrapState())
+	require.False(t, series.IsBootstrapped())
+}
+
 func TestSeriesTickEmptySeries(t *testing.T) {
 	opts := newSeriesTestOptions()
 	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
@@ -500,9 +1117,261 @@ func TestSeriesTickRecentlyRead(t *testing.T) {
 	// Test case where block has been read within expiry period - won't be removed
 	b := block.NewMockDatabaseBlock(ctrl)
 	b.EXPECT().StartTime().Return(curr)
-	b.EXPECT().LastReadTime().Return(
-		curr.Add(-opts.RetentionOptions().BlockDataExpiryAfterNotAccessedPeriod() / 2))
-	b.EXPECT().HasMergeTarget().Return(true)
+	b.EXPECT().LastReadTime().Return(
+		curr.Add(-opts.RetentionOptions().BlockDataExpiryAfterNotAccessedPeriod() / 2))
+	b.EXPECT().HasMergeTarget().Return(true)
+	series.cachedBlocks.AddBlock(b)
+
+	blockStates := BootstrappedBlockStateSnapshot{
+		Snapshot: map[xtime.UnixNano]BlockState{
+			xtime.ToUnixNano(curr): BlockState{
+				WarmRetrievable: true,
+				ColdVersion:     1,
+			},
+		},
+	}
+	shardBlockStates := NewShardBlockStateSnapshot(true, blockStates)
+	tickResult, err := series.Tick(shardBlockStates, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 0, tickResult.UnwiredBlocks)
+	require.Equal(t, 1, tickResult.PendingMergeBlocks)
+
+	// Test case where block has not been read within expiry period - will be removed
+	b = block.NewMockDatabaseBlock(ctrl)
+	b.EXPECT().StartTime().Return(curr)
+	b.EXPECT().LastReadTime().Return(
+		curr.Add(-opts.RetentionOptions().BlockDataExpiryAfterNotAccessedPeriod() * 2))
+	b.EXPECT().Close().Return()
+	series.cachedBlocks.AddBlock(b)
+
+	tickResult, err = series.Tick(shardBlockStates, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 1, tickResult.UnwiredBlocks)
+	require.Equal(t, 0, tickResult.PendingMergeBlocks)
+
+	// Test case where block is not flushed yet (not retrievable) - Will not be removed
+	b = block.NewMockDatabaseBlock(ctrl)
+	b.EXPECT().StartTime().Return(curr)
+	b.EXPECT().HasMergeTarget().Return(true)
+	series.cachedBlocks.AddBlock(b)
+
+	blockStates = BootstrappedBlockStateSnapshot{
+		Snapshot: map[xtime.UnixNano]BlockState{
+			xtime.ToUnixNano(curr): BlockState{
+				WarmRetrievable: false,
+				ColdVersion:     0,
+			},
+		},
+	}
+	shardBlockStates = NewShardBlockStateSnapshot(true, blockStates)
+	tickResult, err = series.Tick(shardBlockStates, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 0, tickResult.UnwiredBlocks)
+	require.Equal(t, 1, tickResult.PendingMergeBlocks)
+}
+
+func TestSeriesCachedBlockBufferOverlap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSeriesTestOptions()
+	blockSize := opts.RetentionOptions().BlockSize()
+	curr := time.Now().Truncate(blockSize)
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	buffer := NewMockdatabaseBuffer(ctrl)
+	series.buffer = buffer
+
+	prev := curr.Add(-blockSize)
+	for _, start := range []time.Time{prev, curr.Add(-2 * blockSize)} {
+		b := block.NewMockDatabaseBlock(ctrl)
+		b.EXPECT().StartTime().Return(start)
+		series.cachedBlocks.AddBlock(b)
+	}
+
+	// No overlap when the buffer is empty
+	buffer.EXPECT().BlockStarts().Return(OptimizedTimes{})
+	overlap := series.CachedBlockBufferOverlap()
+	require.Equal(t, 0, overlap.Len())
+
+	var bufferStarts OptimizedTimes
+	bufferStarts.Add(xtime.ToUnixNano(curr))
+	bufferStarts.Add(xtime.ToUnixNano(prev))
+	buffer.EXPECT().BlockStarts().Return(bufferStarts)
+	overlap = series.CachedBlockBufferOverlap()
+	require.Equal(t, 1, overlap.Len())
+	require.True(t, overlap.Contains(xtime.ToUnixNano(prev)))
+}
+
+func TestSeriesEvictCachedBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSeriesTestOptions().SetCachePolicy(CacheLRU)
+	ropts := opts.RetentionOptions()
+	curr := time.Now().Truncate(ropts.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	assert.NoError(t, err)
+
+	var (
+		idleFor = 10 * time.Minute
+		flushed = curr.Add(-ropts.BlockSize())
+	)
+	blockStates := NewShardBlockStateSnapshot(true, BootstrappedBlockStateSnapshot{
+		Snapshot: map[xtime.UnixNano]BlockState{
+			xtime.ToUnixNano(flushed): BlockState{WarmRetrievable: true},
+		},
+	})
+
+	// Block not flushed yet (not retrievable) - will not be evicted
+	b := block.NewMockDatabaseBlock(ctrl)
+	b.EXPECT().StartTime().Return(curr)
+	series.cachedBlocks.AddBlock(b)
+
+	// Flushed block read recently - will not be evicted
+	b = block.NewMockDatabaseBlock(ctrl)
+	b.EXPECT().StartTime().Return(flushed)
+	b.EXPECT().WasRetrievedFromDisk().Return(false)
+	b.EXPECT().LastReadTime().Return(curr.Add(-idleFor / 2))
+	series.cachedBlocks.AddBlock(b)
+	require.Equal(t, 0, series.EvictCachedBlocks(blockStates, idleFor))
+
+	// Flushed block retrieved from disk - owned by the WiredList
+	b = block.NewMockDatabaseBlock(ctrl)
+	b.EXPECT().StartTime().Return(flushed)
+	b.EXPECT().WasRetrievedFromDisk().Return(true)
+	series.cachedBlocks.AddBlock(b)
+	require.Equal(t, 0, series.EvictCachedBlocks(blockStates, idleFor))
+
+	// Flushed block not read within idle period - will be evicted
+	b = block.NewMockDatabaseBlock(ctrl)
+	b.EXPECT().StartTime().Return(flushed)
+	b.EXPECT().WasRetrievedFromDisk().Return(false)
+	b.EXPECT().LastReadTime().Return(curr.Add(-2 * idleFor))
+	b.EXPECT().Close()
+	series.cachedBlocks.AddBlock(b)
+	require.Equal(t, 1, series.EvictCachedBlocks(blockStates, idleFor))
+
+	_, ok := series.cachedBlocks.BlockAt(flushed)
+	require.False(t, ok)
+	_, ok = series.cachedBlocks.BlockAt(curr)
+	require.True(t, ok)
+
+	// Nothing is evicted without bootstrapped block states
+	require.Equal(t, 0, series.EvictCachedBlocks(
+		NewShardBlockStateSnapshot(false, BootstrappedBlockStateSnapshot{}), idleFor))
+}
+
+func TestSeriesTickCacheLRU(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	retentionPeriod := time.Hour
+	opts := newSeriesTestOptions()
+	opts = opts.
+		SetCachePolicy(CacheLRU).
+		SetRetentionOptions(opts.RetentionOptions().SetRetentionPeriod(retentionPeriod))
+	ropts := opts.RetentionOptions()
+	curr := time.Now().Truncate(ropts.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	blockRetriever := NewMockQueryableBlockRetriever(ctrl)
+	series.blockRetriever = blockRetriever
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	assert.NoError(t, err)
+
+	// Test case where block was not retrieved from disk - Will be removed
+	b := block.NewMockDatabaseBlock(ctrl)
+	b.EXPECT().StartTime().Return(curr)
+	b.EXPECT().WasRetrievedFromDisk().Return(false)
+	b.EXPECT().Close().Return()
+	series.cachedBlocks.AddBlock(b)
+
+	blockStates := BootstrappedBlockStateSnapshot{
+		Snapshot: map[xtime.UnixNano]BlockState{
+			xtime.ToUnixNano(curr): BlockState{
+				WarmRetrievable: true,
+				ColdVersion:     1,
+			},
+		},
+	}
+	shardBlockStates := NewShardBlockStateSnapshot(true, blockStates)
+	tickResult, err := series.Tick(shardBlockStates, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 1, tickResult.UnwiredBlocks)
+	require.Equal(t, 0, tickResult.PendingMergeBlocks)
+
+	// Test case where block was retrieved from disk - Will not be removed
+	b = block.NewMockDatabaseBlock(ctrl)
+	b.EXPECT().StartTime().Return(curr)
+	b.EXPECT().HasMergeTarget().Return(true)
+	b.EXPECT().WasRetrievedFromDisk().Return(true)
+	series.cachedBlocks.AddBlock(b)
+
+	tickResult, err = series.Tick(shardBlockStates, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 0, tickResult.UnwiredBlocks)
+	require.Equal(t, 1, tickResult.PendingMergeBlocks)
+
+	// Test case where block is not flushed yet (not retrievable) - Will not be removed
+	b = block.NewMockDatabaseBlock(ctrl)
+	b.EXPECT().StartTime().Return(curr)
+	b.EXPECT().HasMergeTarget().Return(true)
+	series.cachedBlocks.AddBlock(b)
+
+	// Test case where block was retrieved from disk and is out of retention. Will be removed, but not closed.
+	b = block.NewMockDatabaseBlock(ctrl)
+	b.EXPECT().StartTime().Return(curr.Add(-2 * retentionPeriod))
+	b.EXPECT().WasRetrievedFromDisk().Return(true)
+	series.cachedBlocks.AddBlock(b)
+	_, expiredBlockExists := series.cachedBlocks.BlockAt(curr.Add(-2 * retentionPeriod))
+	require.Equal(t, true, expiredBlockExists)
+
+	blockStates = BootstrappedBlockStateSnapshot{
+		Snapshot: map[xtime.UnixNano]BlockState{
+			xtime.ToUnixNano(curr): BlockState{
+				WarmRetrievable: false,
+				ColdVersion:     0,
+			},
+		},
+	}
+	shardBlockStates = NewShardBlockStateSnapshot(true, blockStates)
+	tickResult, err = series.Tick(shardBlockStates, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 0, tickResult.UnwiredBlocks)
+	require.Equal(t, 1, tickResult.PendingMergeBlocks)
+	_, expiredBlockExists = series.cachedBlocks.BlockAt(curr.Add(-2 * retentionPeriod))
+	require.Equal(t, false, expiredBlockExists)
+}
+
+func TestSeriesTickCacheNone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSeriesTestOptions()
+	opts = opts.
+		SetCachePolicy(CacheNone).
+		SetRetentionOptions(opts.RetentionOptions().SetBlockDataExpiryAfterNotAccessedPeriod(10 * time.Minute))
+	ropts := opts.RetentionOptions()
+	curr := time.Now().Truncate(ropts.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	blockRetriever := NewMockQueryableBlockRetriever(ctrl)
+	series.blockRetriever = blockRetriever
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	assert.NoError(t, err)
+
+	// Retrievable blocks should be removed
+	b := block.NewMockDatabaseBlock(ctrl)
+	b.EXPECT().StartTime().Return(curr)
+	b.EXPECT().Close().Return()
 	series.cachedBlocks.AddBlock(b)
 
 	blockStates := BootstrappedBlockStateSnapshot{
@@ -516,23 +1385,10 @@ func TestSeriesTickRecentlyRead(t *testing.T) {
 	shardBlockStates := NewShardBlockStateSnapshot(true, blockStates)
 	tickResult, err := series.Tick(shardBlockStates, namespace.Context{})
 	require.NoError(t, err)
-	require.Equal(t, 0, tickResult.UnwiredBlocks)
-	require.Equal(t, 1, tickResult.PendingMergeBlocks)
-
-	// Test case where block has not been read within expiry period - will be removed
-	b = block.NewMockDatabaseBlock(ctrl)
-	b.EXPECT().StartTime().Return(curr)
-	b.EXPECT().LastReadTime().Return(
-		curr.Add(-opts.RetentionOptions().BlockDataExpiryAfterNotAccessedPeriod() * 2))
-	b.EXPECT().Close().Return()
-	series.cachedBlocks.AddBlock(b)
-
-	tickResult, err = series.Tick(shardBlockStates, namespace.Context{})
-	require.NoError(t, err)
 	require.Equal(t, 1, tickResult.UnwiredBlocks)
 	require.Equal(t, 0, tickResult.PendingMergeBlocks)
 
-	// Test case where block is not flushed yet (not retrievable) - Will not be removed
+	// Non-retrievable blocks should not be removed
 	b = block.NewMockDatabaseBlock(ctrl)
 	b.EXPECT().StartTime().Return(curr)
 	b.EXPECT().HasMergeTarget().Return(true)
@@ -553,15 +1409,56 @@ func TestSeriesTickRecentlyRead(t *testing.T) {
 	require.Equal(t, 1, tickResult.PendingMergeBlocks)
 }
 
-func TestSeriesTickCacheLRU(t *testing.T) {
+func TestSeriesTickSkippedUnbootstrappedBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSeriesTestOptions()
+	opts = opts.SetCachePolicy(CacheNone)
+	ropts := opts.RetentionOptions()
+	curr := time.Now().Truncate(ropts.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	assert.NoError(t, err)
+
+	b := block.NewMockDatabaseBlock(ctrl)
+	b.EXPECT().StartTime().Return(curr)
+	b.EXPECT().HasMergeTarget().Return(false).Times(2)
+	series.cachedBlocks.AddBlock(b)
+
+	// Blocks are kept wired and reported as skipped while the block states
+	// are not bootstrapped.
+	shardBlockStates := NewShardBlockStateSnapshot(false, BootstrappedBlockStateSnapshot{})
+	tickResult, err := series.Tick(shardBlockStates, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 1, tickResult.WiredBlocks)
+	require.Equal(t, 1, tickResult.SkippedUnbootstrappedBlocks)
+
+	// Once bootstrapped the blocks are no longer skipped.
+	shardBlockStates = NewShardBlockStateSnapshot(true, BootstrappedBlockStateSnapshot{
+		Snapshot: map[xtime.UnixNano]BlockState{
+			xtime.ToUnixNano(curr): BlockState{
+				WarmRetrievable: false,
+			},
+		},
+	})
+	tickResult, err = series.Tick(shardBlockStates, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 1, tickResult.WiredBlocks)
+	require.Equal(t, 0, tickResult.SkippedUnbootstrappedBlocks)
+}
+
+func TestSeriesTickCacheWriteThrough(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	retentionPeriod := time.Hour
 	opts := newSeriesTestOptions()
 	opts = opts.
-		SetCachePolicy(CacheLRU).
-		SetRetentionOptions(opts.RetentionOptions().SetRetentionPeriod(retentionPeriod))
+		SetCachePolicy(CacheWriteThrough).
+		SetRetentionOptions(opts.RetentionOptions().SetBlockDataExpiryAfterNotAccessedPeriod(10 * time.Minute))
 	ropts := opts.RetentionOptions()
 	curr := time.Now().Truncate(ropts.BlockSize())
 	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
@@ -573,57 +1470,33 @@ func TestSeriesTickCacheLRU(t *testing.T) {
 	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
 	assert.NoError(t, err)
 
-	// Test case where block was not retrieved from disk - Will be removed
+	// Freshly written blocks stay wired until they have been flushed.
 	b := block.NewMockDatabaseBlock(ctrl)
 	b.EXPECT().StartTime().Return(curr)
-	b.EXPECT().WasRetrievedFromDisk().Return(false)
-	b.EXPECT().Close().Return()
+	b.EXPECT().HasMergeTarget().Return(false)
 	series.cachedBlocks.AddBlock(b)
 
 	blockStates := BootstrappedBlockStateSnapshot{
 		Snapshot: map[xtime.UnixNano]BlockState{
 			xtime.ToUnixNano(curr): BlockState{
-				WarmRetrievable: true,
-				ColdVersion:     1,
+				WarmRetrievable: false,
+				ColdVersion:     0,
 			},
 		},
 	}
 	shardBlockStates := NewShardBlockStateSnapshot(true, blockStates)
 	tickResult, err := series.Tick(shardBlockStates, namespace.Context{})
 	require.NoError(t, err)
-	require.Equal(t, 1, tickResult.UnwiredBlocks)
-	require.Equal(t, 0, tickResult.PendingMergeBlocks)
-
-	// Test case where block was retrieved from disk - Will not be removed
-	b = block.NewMockDatabaseBlock(ctrl)
-	b.EXPECT().StartTime().Return(curr)
-	b.EXPECT().HasMergeTarget().Return(true)
-	b.EXPECT().WasRetrievedFromDisk().Return(true)
-	series.cachedBlocks.AddBlock(b)
-
-	tickResult, err = series.Tick(shardBlockStates, namespace.Context{})
-	require.NoError(t, err)
 	require.Equal(t, 0, tickResult.UnwiredBlocks)
-	require.Equal(t, 1, tickResult.PendingMergeBlocks)
-
-	// Test case where block is not flushed yet (not retrievable) - Will not be removed
-	b = block.NewMockDatabaseBlock(ctrl)
-	b.EXPECT().StartTime().Return(curr)
-	b.EXPECT().HasMergeTarget().Return(true)
-	series.cachedBlocks.AddBlock(b)
+	require.Equal(t, 1, tickResult.WiredBlocks)
 
-	// Test case where block was retrieved from disk and is out of retention. Will be removed, but not closed.
-	b = block.NewMockDatabaseBlock(ctrl)
-	b.EXPECT().StartTime().Return(curr.Add(-2 * retentionPeriod))
-	b.EXPECT().WasRetrievedFromDisk().Return(true)
-	series.cachedBlocks.AddBlock(b)
-	_, expiredBlockExists := series.cachedBlocks.BlockAt(curr.Add(-2 * retentionPeriod))
-	require.Equal(t, true, expiredBlockExists)
+	// Once the flush is confirmed the block is unwired on the next tick.
+	b.EXPECT().Close().Return()
 
 	blockStates = BootstrappedBlockStateSnapshot{
 		Snapshot: map[xtime.UnixNano]BlockState{
 			xtime.ToUnixNano(curr): BlockState{
-				WarmRetrievable: false,
+				WarmRetrievable: true,
 				ColdVersion:     0,
 			},
 		},
@@ -631,35 +1504,32 @@ func TestSeriesTickCacheLRU(t *testing.T) {
 	shardBlockStates = NewShardBlockStateSnapshot(true, blockStates)
 	tickResult, err = series.Tick(shardBlockStates, namespace.Context{})
 	require.NoError(t, err)
-	require.Equal(t, 0, tickResult.UnwiredBlocks)
-	require.Equal(t, 1, tickResult.PendingMergeBlocks)
-	_, expiredBlockExists = series.cachedBlocks.BlockAt(curr.Add(-2 * retentionPeriod))
-	require.Equal(t, false, expiredBlockExists)
+	require.Equal(t, 1, tickResult.UnwiredBlocks)
+	require.Equal(t, 0, tickResult.WiredBlocks)
+	require.Equal(t, 0, series.cachedBlocks.Len())
 }
 
-func TestSeriesTickCacheNone(t *testing.T) {
+func TestSeriesTickIdleEviction(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	opts := newSeriesTestOptions()
 	opts = opts.
-		SetCachePolicy(CacheNone).
-		SetRetentionOptions(opts.RetentionOptions().SetBlockDataExpiryAfterNotAccessedPeriod(10 * time.Minute))
+		SetCachePolicy(CacheLRU).
+		SetIdleEvictionTimeout(10 * time.Minute)
 	ropts := opts.RetentionOptions()
 	curr := time.Now().Truncate(ropts.BlockSize())
 	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
 		return curr
 	}))
 	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
-	blockRetriever := NewMockQueryableBlockRetriever(ctrl)
-	series.blockRetriever = blockRetriever
-	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
-	assert.NoError(t, err)
 
-	// Retrievable blocks should be removed
+	// The block is retrieved from disk so it is left for the WiredList to
+	// close rather than being closed by the series.
 	b := block.NewMockDatabaseBlock(ctrl)
 	b.EXPECT().StartTime().Return(curr)
-	b.EXPECT().Close().Return()
+	b.EXPECT().WasRetrievedFromDisk().Return(true).AnyTimes()
+	b.EXPECT().HasMergeTarget().Return(false).AnyTimes()
 	series.cachedBlocks.AddBlock(b)
 
 	blockStates := BootstrappedBlockStateSnapshot{
@@ -671,30 +1541,66 @@ func TestSeriesTickCacheNone(t *testing.T) {
 		},
 	}
 	shardBlockStates := NewShardBlockStateSnapshot(true, blockStates)
+
+	// Not idle for long enough yet.
+	curr = curr.Add(5 * time.Minute)
 	tickResult, err := series.Tick(shardBlockStates, namespace.Context{})
 	require.NoError(t, err)
-	require.Equal(t, 1, tickResult.UnwiredBlocks)
-	require.Equal(t, 0, tickResult.PendingMergeBlocks)
+	require.Equal(t, 1, tickResult.ActiveBlocks)
+	require.False(t, series.IsEmpty())
 
-	// Non-retrievable blocks should not be removed
-	b = block.NewMockDatabaseBlock(ctrl)
+	// Blocks that are not yet flushed are never evicted.
+	curr = curr.Add(10 * time.Minute)
+	tickResult, err = series.Tick(NewShardBlockStateSnapshot(true,
+		BootstrappedBlockStateSnapshot{}), namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 1, tickResult.ActiveBlocks)
+	require.False(t, series.IsEmpty())
+
+	tickResult, err = series.Tick(shardBlockStates, namespace.Context{})
+	require.Equal(t, ErrSeriesIdle, err)
+	require.Equal(t, 0, tickResult.ActiveBlocks)
+	require.Equal(t, 1, tickResult.MadeUnwiredBlocks)
+	require.True(t, series.IsEmpty())
+}
+
+func TestSeriesTickIdleEvictionCacheAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSeriesTestOptions()
+	opts = opts.
+		SetCachePolicy(CacheAll).
+		SetIdleEvictionTimeout(10 * time.Minute)
+	ropts := opts.RetentionOptions()
+	curr := time.Now().Truncate(ropts.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ident.StringID("foo"), ident.Tags{}, opts).(*dbSeries)
+
+	b := block.NewMockDatabaseBlock(ctrl)
 	b.EXPECT().StartTime().Return(curr)
-	b.EXPECT().HasMergeTarget().Return(true)
 	series.cachedBlocks.AddBlock(b)
 
-	blockStates = BootstrappedBlockStateSnapshot{
+	blockStates := BootstrappedBlockStateSnapshot{
 		Snapshot: map[xtime.UnixNano]BlockState{
 			xtime.ToUnixNano(curr): BlockState{
-				WarmRetrievable: false,
-				ColdVersion:     0,
+				WarmRetrievable: true,
+				ColdVersion:     1,
 			},
 		},
 	}
-	shardBlockStates = NewShardBlockStateSnapshot(true, blockStates)
-	tickResult, err = series.Tick(shardBlockStates, namespace.Context{})
+
+	// The series is never evicted as its blocks could not be retrieved
+	// from disk once unwired.
+	curr = curr.Add(time.Hour)
+	tickResult, err := series.Tick(NewShardBlockStateSnapshot(true, blockStates),
+		namespace.Context{})
 	require.NoError(t, err)
-	require.Equal(t, 0, tickResult.UnwiredBlocks)
-	require.Equal(t, 1, tickResult.PendingMergeBlocks)
+	require.Equal(t, 1, tickResult.ActiveBlocks)
+	require.Equal(t, 0, tickResult.MadeUnwiredBlocks)
+	require.False(t, series.IsEmpty())
 }
 
 func TestSeriesTickCachedBlockRemove(t *testing.T) {
@@ -808,6 +1714,124 @@ func TestSeriesFetchBlocks(t *testing.T) {
 	}
 }
 
+func TestSeriesReconcileWithPeer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSeriesTestOptions().SetColdWritesEnabled(true)
+	blockSize := opts.RetentionOptions().BlockSize()
+	curr := time.Now().Truncate(blockSize)
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+
+	var (
+		id        = ident.StringID("foo")
+		start     = curr.Add(-3 * blockSize)
+		starts    = []time.Time{start, start.Add(blockSize), start.Add(2 * blockSize)}
+		nsCtx     = namespace.Context{}
+		blockOpts = opts.DatabaseBlockOptions()
+	)
+	newValues := func(blockStart time.Time, vals ...float64) []value {
+		values := make([]value, 0, len(vals))
+		for i, v := range vals {
+			values = append(values, value{blockStart.Add(time.Duration(i) * time.Second),
+				v, xtime.Second, nil})
+		}
+		return values
+	}
+	newSegment := func(values []value) ts.Segment {
+		encoder := opts.EncoderPool().Get()
+		encoder.Reset(values[0].timestamp, 0, nil)
+		for _, v := range values {
+			dp := ts.Datapoint{Timestamp: v.timestamp, Value: v.value}
+			require.NoError(t, encoder.Encode(dp, v.unit, v.annotation))
+		}
+		return encoder.Discard()
+	}
+	checksumOf := func(segment ts.Segment) *uint32 {
+		checksum := digest.SegmentChecksum(segment)
+		return &checksum
+	}
+
+	series := NewDatabaseSeries(id, ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	require.NoError(t, err)
+
+	// The first block matches the peer, the second differs from the peer and
+	// has been warm flushed and the third is missing locally.
+	localValues := [][]value{newValues(starts[0], 1, 2), newValues(starts[1], 3)}
+	peerValues := [][]value{newValues(starts[0], 1, 2), newValues(starts[1], 3, 4),
+		newValues(starts[2], 5)}
+	for i, values := range localValues {
+		series.cachedBlocks.AddBlock(block.NewDatabaseBlock(starts[i], blockSize,
+			newSegment(values), blockOpts, nsCtx))
+	}
+
+	replicas := make([]block.ReplicaMetadata, 0, len(peerValues))
+	for i, values := range peerValues {
+		segment := newSegment(values)
+		replicas = append(replicas, block.ReplicaMetadata{
+			Metadata: block.NewMetadata(id, ident.Tags{}, starts[i],
+				int64(segment.Len()), checksumOf(segment), time.Time{}),
+		})
+	}
+
+	peer := NewMockReconcilePeer(ctrl)
+	peer.EXPECT().FetchBlocksMetadata(ctx, id, starts).Return(replicas, nil)
+	peer.EXPECT().
+		FetchBlocks(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, differing []block.ReplicaMetadata) ([]block.DatabaseBlock, error) {
+			require.Equal(t, 2, len(differing))
+			var blocks []block.DatabaseBlock
+			for _, replica := range differing {
+				i := int(replica.Start.Sub(start) / blockSize)
+				require.NotEqual(t, 0, i)
+				blocks = append(blocks, block.NewDatabaseBlock(replica.Start, blockSize,
+					newSegment(peerValues[i]), blockOpts, nsCtx))
+			}
+			return blocks, nil
+		})
+
+	blockStates := BootstrappedBlockStateSnapshot{
+		Snapshot: map[xtime.UnixNano]BlockState{
+			xtime.ToUnixNano(starts[0]): BlockState{WarmRetrievable: true},
+			xtime.ToUnixNano(starts[1]): BlockState{WarmRetrievable: true},
+		},
+	}
+	healed, err := series.ReconcileWithPeer(ctx, peer, starts, blockStates, nsCtx)
+	require.NoError(t, err)
+	require.Equal(t, 2, healed)
+
+	// The healed blocks are held by the buffer until they are flushed.
+	_, err = series.Tick(NewShardBlockStateSnapshot(true, blockStates), nsCtx)
+	require.NoError(t, err)
+
+	// The block missing locally is persisted by the warm flush.
+	var flushed []value
+	persistFn := func(_ ident.ID, _ ident.Tags, segment ts.Segment, _ uint32) error {
+		reader := xio.BlockReader{SegmentReader: xio.NewSegmentReader(segment)}
+		values, err := decodedReaderValues([][]xio.BlockReader{{reader}}, opts, nsCtx)
+		flushed = values
+		return err
+	}
+	outcome, err := series.WarmFlush(ctx, starts[2], persistFn, nsCtx)
+	require.NoError(t, err)
+	require.Equal(t, FlushOutcomeFlushedToDisk, outcome)
+	requireValuesEqual(t, peerValues[2], flushed, nsCtx)
+
+	// The block that differs has been warm flushed already so it is
+	// persisted by the cold flush.
+	coldFlushStarts := series.ColdFlushBlockStarts(blockStates)
+	require.Equal(t, 1, coldFlushStarts.Len())
+	require.True(t, coldFlushStarts.Contains(xtime.ToUnixNano(starts[1])))
+	readers, err := series.FetchBlocksForColdFlush(ctx, starts[1], 1, nsCtx)
+	require.NoError(t, err)
+	requireReaderValuesEqual(t, peerValues[1], [][]xio.BlockReader{readers}, opts, nsCtx)
+}
+
 func TestSeriesFetchBlocksMetadata(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -894,6 +1918,163 @@ func TestSeriesFetchBlocksMetadata(t *testing.T) {
 	}
 }
 
+func TestSeriesFetchBlocksMetadataMinBlockSize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSeriesTestOptions()
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+
+	blockSize := opts.RetentionOptions().BlockSize()
+	now := time.Now().Truncate(blockSize)
+	start := now.Add(-4 * blockSize)
+	end := now.Add(blockSize)
+	starts := []time.Time{now.Add(-2 * blockSize), now.Add(-blockSize)}
+
+	blocks := map[xtime.UnixNano]block.DatabaseBlock{}
+	for i, blockStart := range starts {
+		b := block.NewMockDatabaseBlock(ctrl)
+		b.EXPECT().WasRetrievedFromDisk().Return(false)
+		b.EXPECT().Len().Return(i + 1)
+		blocks[xtime.ToUnixNano(blockStart)] = b
+	}
+
+	fetchOpts := FetchBlocksMetadataOptions{
+		FetchBlocksMetadataOptions: block.FetchBlocksMetadataOptions{
+			IncludeSizes: true,
+		},
+		MinBlockSizeBytes: 2,
+	}
+
+	// The buffer is passed the same options so honors the same filter.
+	buffer := NewMockdatabaseBuffer(ctrl)
+	buffer.EXPECT().IsEmpty().Return(false)
+	buffer.EXPECT().
+		FetchBlocksMetadata(ctx, start, end, fetchOpts).
+		Return(block.NewFetchBlockMetadataResults(), nil)
+
+	series := NewDatabaseSeries(ident.StringID("bar"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	assert.NoError(t, err)
+	mockBlocks := block.NewMockDatabaseSeriesBlocks(ctrl)
+	mockBlocks.EXPECT().AllBlocks().Return(blocks)
+	series.cachedBlocks = mockBlocks
+	series.buffer = buffer
+
+	res, err := series.FetchBlocksMetadata(ctx, start, end, fetchOpts)
+	require.NoError(t, err)
+	metadata := res.Blocks.Results()
+	require.Equal(t, 1, len(metadata))
+	require.True(t, starts[1].Equal(metadata[0].Start))
+	require.Equal(t, int64(2), metadata[0].Size)
+}
+
+func TestSeriesFetchBlocksMetadataPaged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSeriesTestOptions()
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+
+	blockSize := opts.RetentionOptions().BlockSize()
+	now := time.Now().Truncate(blockSize)
+	start := now.Add(-4 * blockSize)
+	end := now.Add(blockSize)
+	starts := []time.Time{now.Add(-2 * blockSize), now.Add(-blockSize), now}
+
+	blocks := map[xtime.UnixNano]block.DatabaseBlock{}
+	for _, blockStart := range starts[:2] {
+		b := block.NewMockDatabaseBlock(ctrl)
+		b.EXPECT().WasRetrievedFromDisk().Return(false).AnyTimes()
+		blocks[xtime.ToUnixNano(blockStart)] = b
+	}
+
+	// The buffer holds the same block start as a cached block, results are
+	// ordered with the cached block first.
+	buffer := NewMockdatabaseBuffer(ctrl)
+	buffer.EXPECT().IsEmpty().Return(false).AnyTimes()
+	buffer.EXPECT().
+		FetchBlocksMetadata(ctx, start, end, gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			_, _ time.Time,
+			_ FetchBlocksMetadataOptions,
+		) (block.FetchBlockMetadataResults, error) {
+			results := block.NewFetchBlockMetadataResults()
+			results.Add(block.FetchBlockMetadataResult{Start: starts[2], Size: 2})
+			results.Add(block.FetchBlockMetadataResult{Start: starts[1], Size: 1})
+			return results, nil
+		}).
+		Times(2)
+
+	series := NewDatabaseSeries(ident.StringID("bar"), ident.Tags{}, opts).(*dbSeries)
+	_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+	assert.NoError(t, err)
+	mockBlocks := block.NewMockDatabaseSeriesBlocks(ctrl)
+	mockBlocks.EXPECT().AllBlocks().Return(blocks).Times(2)
+	series.cachedBlocks = mockBlocks
+	series.buffer = buffer
+
+	res, err := series.FetchBlocksMetadata(ctx, start, end,
+		FetchBlocksMetadataOptions{Limit: 2})
+	require.NoError(t, err)
+	metadata := res.Blocks.Results()
+	require.Equal(t, 2, len(metadata))
+	require.True(t, starts[0].Equal(metadata[0].Start))
+	require.True(t, starts[1].Equal(metadata[1].Start))
+	require.Equal(t, int64(0), metadata[1].Size)
+	require.NotNil(t, res.NextCursor)
+	require.True(t, starts[1].Equal(res.NextCursor.BlockStart))
+	require.Equal(t, 1, res.NextCursor.Offset)
+
+	res, err = series.FetchBlocksMetadata(ctx, start, end,
+		FetchBlocksMetadataOptions{Limit: 2, Cursor: res.NextCursor})
+	require.NoError(t, err)
+	metadata = res.Blocks.Results()
+	require.Equal(t, 2, len(metadata))
+	require.True(t, starts[1].Equal(metadata[0].Start))
+	require.Equal(t, int64(1), metadata[0].Size)
+	require.True(t, starts[2].Equal(metadata[1].Start))
+	require.Nil(t, res.NextCursor)
+}
+
+func TestSeriesFetchBlocksMetadataEncodedTags(t *testing.T) {
+	tags := ident.NewTags(ident.StringTag("foo", "bar"), ident.StringTag("baz", "qux"))
+
+	enc := serialize.NewTagEncoderPool(serialize.NewTagEncoderOptions(), nil)
+	enc.Init()
+	expectedEnc := enc.Get()
+	require.NoError(t, expectedEnc.Encode(ident.NewTagsIterator(tags)))
+	expected, ok := expectedEnc.Data()
+	require.True(t, ok)
+
+	for _, cache := range []bool{false, true} {
+		opts := newSeriesTestOptions().SetCacheEncodedTags(cache)
+		ctx := opts.ContextPool().Get()
+
+		series := NewDatabaseSeries(ident.StringID("bar"), tags, opts).(*dbSeries)
+		_, err := series.Load(LoadOptions{Bootstrap: true}, nil, BootstrappedBlockStateSnapshot{})
+		require.NoError(t, err)
+
+		now := time.Now()
+		res, err := series.FetchBlocksMetadata(ctx, now.Add(-time.Hour),
+			now.Add(time.Hour), FetchBlocksMetadataOptions{})
+		require.NoError(t, err)
+		require.Equal(t, 2, res.Tags.Remaining())
+		if cache {
+			require.Equal(t, expected.Bytes(), res.EncodedTags)
+		} else {
+			require.Nil(t, res.EncodedTags)
+		}
+
+		series.Close()
+		require.Nil(t, series.encodedTags)
+		ctx.Close()
+	}
+}
+
 func TestSeriesOutOfOrderWritesAndRotate(t *testing.T) {
 	now := time.Unix(1477929600, 0)
 	nowFn := func() time.Time { return now }
@@ -0,0 +1,173 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package series
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+)
+
+var errStepMustBePositive = errors.New("read step must be positive")
+
+// StepAggregation determines how the datapoints within a step of a stepped
+// read are aggregated into a single value.
+type StepAggregation uint8
+
+const (
+	// StepAggregationLast takes the last datapoint within each step.
+	StepAggregationLast StepAggregation = iota
+
+	// StepAggregationAvg takes the average of the datapoints within each step.
+	StepAggregationAvg
+
+	// StepAggregationMin takes the minimum of the datapoints within each step.
+	StepAggregationMin
+
+	// StepAggregationMax takes the maximum of the datapoints within each step.
+	StepAggregationMax
+
+	// StepAggregationSum takes the sum of the datapoints within each step.
+	StepAggregationSum
+)
+
+var validStepAggregations = []StepAggregation{
+	StepAggregationLast,
+	StepAggregationAvg,
+	StepAggregationMin,
+	StepAggregationMax,
+	StepAggregationSum,
+}
+
+// Validate validates that the step aggregation is valid.
+func (a StepAggregation) Validate() error {
+	if a >= StepAggregationLast && a <= StepAggregationSum {
+		return nil
+	}
+
+	return fmt.Errorf("invalid step aggregation: '%v' valid aggregations are: %v",
+		a, validStepAggregations)
+}
+
+func (a StepAggregation) String() string {
+	switch a {
+	case StepAggregationLast:
+		return "last"
+	case StepAggregationAvg:
+		return "avg"
+	case StepAggregationMin:
+		return "min"
+	case StepAggregationMax:
+		return "max"
+	case StepAggregationSum:
+		return "sum"
+	default:
+		// Should never get here.
+		return "unknown"
+	}
+}
+
+// ParseStepAggregation parses a step aggregation from its string form.
+func ParseStepAggregation(str string) (StepAggregation, error) {
+	for _, valid := range validStepAggregations {
+		if str == valid.String() {
+			return valid, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid step aggregation: '%s' valid aggregations are: %v",
+		str, validStepAggregations)
+}
+
+// stepAggregator aggregates datapoints, which must be added in time order,
+// into a single value per step where steps are aligned to the read start.
+type stepAggregator struct {
+	start       time.Time
+	step        time.Duration
+	aggregation StepAggregation
+
+	results   []ts.Datapoint
+	stepStart time.Time
+	value     float64
+	count     int
+}
+
+func newStepAggregator(
+	start time.Time,
+	step time.Duration,
+	aggregation StepAggregation,
+) *stepAggregator {
+	return &stepAggregator{
+		start:       start,
+		step:        step,
+		aggregation: aggregation,
+	}
+}
+
+func (a *stepAggregator) add(dp ts.Datapoint) {
+	stepStart := a.start.Add(dp.Timestamp.Sub(a.start) / a.step * a.step)
+	if a.count > 0 && !stepStart.Equal(a.stepStart) {
+		a.flushStep()
+	}
+
+	if a.count == 0 {
+		a.stepStart = stepStart
+		a.value = dp.Value
+		a.count = 1
+		return
+	}
+
+	a.count++
+	switch a.aggregation {
+	case StepAggregationLast:
+		a.value = dp.Value
+	case StepAggregationAvg, StepAggregationSum:
+		a.value += dp.Value
+	case StepAggregationMin:
+		a.value = math.Min(a.value, dp.Value)
+	case StepAggregationMax:
+		a.value = math.Max(a.value, dp.Value)
+	}
+}
+
+func (a *stepAggregator) flushStep() {
+	value := a.value
+	if a.aggregation == StepAggregationAvg {
+		value /= float64(a.count)
+	}
+	a.results = append(a.results, ts.Datapoint{
+		Timestamp: a.stepStart,
+		Value:     value,
+	})
+	a.count = 0
+}
+
+// finish returns the aggregated value of each step that contained at least
+// one datapoint, timestamped at the start of the step.
+func (a *stepAggregator) finish() []ts.Datapoint {
+	if a.count > 0 {
+		a.flushStep()
+	}
+	return a.results
+}
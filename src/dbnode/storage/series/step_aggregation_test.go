@@ -0,0 +1,90 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package series
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepAggregationValidation(t *testing.T) {
+	for _, aggregation := range validStepAggregations {
+		assert.NoError(t, aggregation.Validate())
+	}
+	assert.Error(t, StepAggregation(10).Validate())
+}
+
+func TestParseStepAggregation(t *testing.T) {
+	for _, aggregation := range validStepAggregations {
+		parsed, err := ParseStepAggregation(aggregation.String())
+		require.NoError(t, err)
+		assert.Equal(t, aggregation, parsed)
+	}
+
+	_, err := ParseStepAggregation("not_a_known_aggregation")
+	assert.Error(t, err)
+}
+
+func TestStepAggregator(t *testing.T) {
+	start := time.Unix(0, 0)
+	step := time.Minute
+	datapoints := []ts.Datapoint{
+		{Timestamp: start, Value: 4},
+		{Timestamp: start.Add(10 * time.Second), Value: 1},
+		{Timestamp: start.Add(50 * time.Second), Value: 7},
+		// No datapoints in the second step.
+		{Timestamp: start.Add(2 * time.Minute), Value: 3},
+		{Timestamp: start.Add(2*time.Minute + 30*time.Second), Value: 5},
+	}
+
+	tests := []struct {
+		aggregation StepAggregation
+		expected    []float64
+	}{
+		{StepAggregationLast, []float64{7, 5}},
+		{StepAggregationAvg, []float64{4, 4}},
+		{StepAggregationMin, []float64{1, 3}},
+		{StepAggregationMax, []float64{7, 5}},
+		{StepAggregationSum, []float64{12, 8}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.aggregation.String(), func(t *testing.T) {
+			aggregator := newStepAggregator(start, step, test.aggregation)
+			for _, dp := range datapoints {
+				aggregator.add(dp)
+			}
+
+			results := aggregator.finish()
+			require.Equal(t, len(test.expected), len(results))
+			assert.True(t, start.Equal(results[0].Timestamp))
+			assert.True(t, start.Add(2*time.Minute).Equal(results[1].Timestamp))
+			for i, expected := range test.expected {
+				assert.Equal(t, expected, results[i].Value)
+			}
+		})
+	}
+}
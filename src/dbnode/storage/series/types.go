@@ -21,6 +21,7 @@
 package series
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
@@ -29,15 +30,26 @@ import (
 	"github.com/m3db/m3/src/dbnode/persist"
 	"github.com/m3db/m3/src/dbnode/retention"
 	"github.com/m3db/m3/src/dbnode/storage/block"
+	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/dbnode/x/xio"
 	"github.com/m3db/m3/src/x/context"
 	"github.com/m3db/m3/src/x/ident"
 	"github.com/m3db/m3/src/x/instrument"
+	"github.com/m3db/m3/src/x/serialize"
 	xtime "github.com/m3db/m3/src/x/time"
 
 	"github.com/uber-go/tally"
 )
 
+// ValueDatapoint is a datapoint to write to a series, the annotation is
+// owned by the datapoint and must not be reused for other datapoints.
+type ValueDatapoint struct {
+	Timestamp  time.Time
+	Value      float64
+	Unit       xtime.Unit
+	Annotation []byte
+}
+
 // DatabaseSeries is a series in the database.
 type DatabaseSeries interface {
 	block.OnRetrieveBlock
@@ -62,6 +74,28 @@ type DatabaseSeries interface {
 		wOpts WriteOptions,
 	) (bool, error)
 
+	// WriteBatch writes the datapoints in order while holding the series lock
+	// once, it stops at the first error and returns the number of datapoints
+	// written before it along with the error.
+	WriteBatch(
+		ctx context.Context,
+		datapoints []ValueDatapoint,
+		wOpts WriteOptions,
+	) (int, error)
+
+	// WriteTuple writes a new tuple of values at a single timestamp, the first
+	// value is written as the datapoint value and the tuple is encoded as the
+	// datapoint annotation, use a tuple.Iterator to read the tuples back. The
+	// tuple must have at least one value, the write transforms are applied to
+	// every value of the tuple.
+	WriteTuple(
+		ctx context.Context,
+		timestamp time.Time,
+		values []float64,
+		unit xtime.Unit,
+		wOpts WriteOptions,
+	) (bool, error)
+
 	// ReadEncoded reads encoded blocks.
 	ReadEncoded(
 		ctx context.Context,
@@ -69,6 +103,40 @@ type DatabaseSeries interface {
 		nsCtx namespace.Context,
 	) ([][]xio.BlockReader, error)
 
+	// ReadEncodedWithLimit reads encoded blocks, failing with a query too
+	// large error as soon as more than max readers block readers have been
+	// assembled. A max readers of zero does not limit the read.
+	ReadEncodedWithLimit(
+		ctx context.Context,
+		start, end time.Time,
+		maxReaders int,
+		nsCtx namespace.Context,
+	) ([][]xio.BlockReader, error)
+
+	// ReadFiltered reads the datapoints between start and end and returns
+	// only those accepted by the read options. Every datapoint must be
+	// decoded to be filtered so, unlike ReadEncoded, this does not return
+	// the encoded data as is and is considerably more expensive, it should
+	// only be used when datapoints need to be filtered server side.
+	ReadFiltered(
+		ctx context.Context,
+		start, end time.Time,
+		opts ReadOptions,
+		nsCtx namespace.Context,
+	) ([]ts.Datapoint, error)
+
+	// ReadStepped reads the datapoints between start and end and aggregates
+	// the datapoints within each step, aligned to start, into a single value
+	// timestamped at the start of the step. Steps without datapoints are
+	// omitted.
+	ReadStepped(
+		ctx context.Context,
+		start, end time.Time,
+		step time.Duration,
+		aggregation StepAggregation,
+		nsCtx namespace.Context,
+	) ([]ts.Datapoint, error)
+
 	// FetchBlocks returns data blocks given a list of block start times.
 	FetchBlocks(
 		ctx context.Context,
@@ -76,6 +144,19 @@ type DatabaseSeries interface {
 		nsCtx namespace.Context,
 	) ([]block.FetchBlockResult, error)
 
+	// ReconcileWithPeer compares the checksums of the blocks at the given
+	// block starts with those held by the peer, fetches the blocks that
+	// differ or are missing locally from the peer and loads them into the
+	// buffer so they are persisted by the next flush, returning the number
+	// of blocks healed.
+	ReconcileWithPeer(
+		ctx context.Context,
+		peer ReconcilePeer,
+		blockStarts []time.Time,
+		blockStates BootstrappedBlockStateSnapshot,
+		nsCtx namespace.Context,
+	) (int, error)
+
 	// FetchBlocksForColdFlush fetches blocks for a cold flush. This function
 	// informs the series and the buffer that a cold flush for the specified
 	// block start is occurring so that it knows to update bucket versions.
@@ -86,6 +167,18 @@ type DatabaseSeries interface {
 		nsCtx namespace.Context,
 	) ([]xio.BlockReader, error)
 
+	// FetchBlocksVersioned returns the buffered data blocks at the given
+	// cold flush version for a list of block start times, the writable
+	// version is zero. It is intended for debugging versioning so blocks that
+	// are not held by the buffer at the version are not returned as errors
+	// but as empty results with the ErrBlockVersionNotFound error.
+	FetchBlocksVersioned(
+		ctx context.Context,
+		starts []time.Time,
+		version int,
+		nsCtx namespace.Context,
+	) []block.FetchBlockResult
+
 	// FetchBlocksMetadata returns the blocks metadata.
 	FetchBlocksMetadata(
 		ctx context.Context,
@@ -99,9 +192,24 @@ type DatabaseSeries interface {
 	// NumActiveBlocks returns the number of active blocks the series currently holds.
 	NumActiveBlocks() int
 
+	// EstimatedMemoryBytes returns an estimate of the memory held by the
+	// series, summing the buffer encoders, cached blocks and ID and tags.
+	EstimatedMemoryBytes() int64
+
+	// EvictCachedBlocks removes the flushed cached blocks that have not been
+	// read within idleFor, without closing the series, and returns the number
+	// of blocks evicted. Blocks owned by the WiredList are left to it.
+	EvictCachedBlocks(blockStates ShardBlockStateSnapshot, idleFor time.Duration) int
+
 	// IsBootstrapped returns whether the series is bootstrapped or not.
 	IsBootstrapped() bool
 
+	// BootstrapState returns the bootstrap state of the series, unlike
+	// IsBootstrapped it also distinguishes whether the series has been
+	// ticked with bootstrapped block states, only then are decisions that
+	// depend on the block states, such as evicting blocks, made.
+	BootstrapState() BootstrapState
+
 	// Load loads data into the series.
 	Load(
 		opts LoadOptions,
@@ -129,6 +237,15 @@ type DatabaseSeries interface {
 	// ColdFlushBlockStarts returns the block starts that need cold flushes.
 	ColdFlushBlockStarts(blockStates BootstrappedBlockStateSnapshot) OptimizedTimes
 
+	// BufferDebugInfo returns the versions of the buffer buckets for each
+	// block start, it is intended for debugging cold flushes only.
+	BufferDebugInfo() []BufferBlockDebugInfo
+
+	// CachedBlockBufferOverlap returns the block starts that are present in
+	// both the cached blocks and the buffer, which may lead to datapoints
+	// being double counted on read and should not happen.
+	CachedBlockBufferOverlap() OptimizedTimes
+
 	// Close will close the series and if pooled returned to the pool.
 	Close()
 
@@ -151,6 +268,37 @@ type FetchBlocksMetadataOptions struct {
 	// IncludeCachedBlocks specifies whether to also include cached blocks
 	// when returning series metadata.
 	IncludeCachedBlocks bool
+
+	// MinBlockSizeBytes omits blocks smaller than the given size from the
+	// results, zero includes every block. The filter is ignored unless
+	// IncludeSizes is set as block sizes are only computed when requested.
+	MinBlockSizeBytes int64
+
+	// Limit caps the number of blocks returned, zero returns every block.
+	// When capped the result carries the cursor to fetch the next page from.
+	Limit int
+
+	// Cursor resumes a paged fetch from the cursor of a previous result,
+	// nil fetches from the first block.
+	Cursor *block.FetchBlocksMetadataCursor
+}
+
+// ReconcilePeer provides the authoritative replicas of a series' blocks to
+// reconcile against.
+type ReconcilePeer interface {
+	// FetchBlocksMetadata returns the metadata, including checksums, of the
+	// replicas of the series blocks at the given block starts.
+	FetchBlocksMetadata(
+		ctx context.Context,
+		id ident.ID,
+		blockStarts []time.Time,
+	) ([]block.ReplicaMetadata, error)
+
+	// FetchBlocks returns the blocks for the given replicas.
+	FetchBlocks(
+		ctx context.Context,
+		replicas []block.ReplicaMetadata,
+	) ([]block.DatabaseBlock, error)
 }
 
 // QueryableBlockRetriever is a block retriever that can tell if a block
@@ -234,6 +382,9 @@ type TickResult struct {
 	MergedOutOfOrderBlocks int
 	// EvictedBuckets is count of buckets just evicted from the buffer map.
 	EvictedBuckets int
+	// SkippedUnbootstrappedBlocks is count of blocks that were not considered
+	// for unwiring as the block states were not yet bootstrapped.
+	SkippedUnbootstrappedBlocks int
 }
 
 // DatabaseSeriesAllocate allocates a database series for a pool.
@@ -329,6 +480,12 @@ type Options interface {
 	// IdentifierPool returns the identifierPool
 	IdentifierPool() ident.Pool
 
+	// SetTagEncoderPool sets the tag encoder pool
+	SetTagEncoderPool(value serialize.TagEncoderPool) Options
+
+	// TagEncoderPool returns the tag encoder pool
+	TagEncoderPool() serialize.TagEncoderPool
+
 	// SetStats sets the configured Stats.
 	SetStats(value Stats) Options
 
@@ -341,6 +498,54 @@ type Options interface {
 	// ColdWritesEnabled returns whether cold writes are enabled.
 	ColdWritesEnabled() bool
 
+	// SetVerifyBlocksOnLoad sets whether blocks are verified against their
+	// checksum and decoded before being loaded, blocks that fail
+	// verification are skipped rather than loaded.
+	SetVerifyBlocksOnLoad(value bool) Options
+
+	// VerifyBlocksOnLoad returns whether blocks are verified against their
+	// checksum and decoded before being loaded, blocks that fail
+	// verification are skipped rather than loaded.
+	VerifyBlocksOnLoad() bool
+
+	// SetMergeDuplicateBootstrap sets whether bootstrapping an already
+	// bootstrapped series merges the newly bootstrapped blocks into the
+	// series rather than returning an error.
+	SetMergeDuplicateBootstrap(value bool) Options
+
+	// MergeDuplicateBootstrap returns whether bootstrapping an already
+	// bootstrapped series merges the newly bootstrapped blocks into the
+	// series rather than returning an error.
+	MergeDuplicateBootstrap() bool
+
+	// SetRecycleEvictedEncoders sets whether encoders of buffer buckets
+	// evicted after being flushed are reset and returned to the encoder pool
+	// rather than left to be garbage collected.
+	SetRecycleEvictedEncoders(value bool) Options
+
+	// RecycleEvictedEncoders returns whether encoders of buffer buckets
+	// evicted after being flushed are reset and returned to the encoder pool
+	// rather than left to be garbage collected.
+	RecycleEvictedEncoders() bool
+
+	// SetCacheEncodedTags sets whether each series keeps a serialized copy
+	// of its tags, computed once on creation, to return from fetching
+	// blocks metadata rather than having the tags encoded on every call.
+	SetCacheEncodedTags(value bool) Options
+
+	// CacheEncodedTags returns whether each series keeps a serialized copy
+	// of its tags, computed once on creation, to return from fetching
+	// blocks metadata rather than having the tags encoded on every call.
+	CacheEncodedTags() bool
+
+	// SetWriteBackpressure sets the backpressure checked before each write
+	// is applied to the series buffer, nil disables the check.
+	SetWriteBackpressure(value WriteBackpressure) Options
+
+	// WriteBackpressure returns the backpressure checked before each write
+	// is applied to the series buffer, nil disables the check.
+	WriteBackpressure() WriteBackpressure
+
 	// SetBufferBucketVersionsPool sets the BufferBucketVersionsPool.
 	SetBufferBucketVersionsPool(value *BufferBucketVersionsPool) Options
 
@@ -352,21 +557,171 @@ type Options interface {
 
 	// BufferBucketPool returns the BufferBucketPool.
 	BufferBucketPool() *BufferBucketPool
+
+	// SetMaxEncodersPerBucket sets the maximum number of encoders a buffer
+	// bucket may hold before its encoders are merged inline on write rather
+	// than waiting for the next tick, zero disables the limit.
+	SetMaxEncodersPerBucket(value int) Options
+
+	// MaxEncodersPerBucket returns the maximum number of encoders a buffer
+	// bucket may hold before its encoders are merged inline on write rather
+	// than waiting for the next tick, zero disables the limit.
+	MaxEncodersPerBucket() int
+
+	// SetMaxWriteFutureSkew sets how far ahead of now a write timestamp may
+	// be before the write is rejected as clock skewed, zero disables the check.
+	SetMaxWriteFutureSkew(value time.Duration) Options
+
+	// MaxWriteFutureSkew returns how far ahead of now a write timestamp may
+	// be before the write is rejected as clock skewed, zero disables the check.
+	MaxWriteFutureSkew() time.Duration
+
+	// SetIdleEvictionTimeout sets how long a series may go without writes
+	// before it is evicted on tick once its buffer is empty and all of its
+	// blocks are flushed, zero disables idle eviction. Idle eviction never
+	// applies with the CacheAll policy as the blocks cannot be retrieved.
+	SetIdleEvictionTimeout(value time.Duration) Options
+
+	// IdleEvictionTimeout returns how long a series may go without writes
+	// before it is evicted on tick once its buffer is empty and all of its
+	// blocks are flushed, zero disables idle eviction. Idle eviction never
+	// applies with the CacheAll policy as the blocks cannot be retrieved.
+	IdleEvictionTimeout() time.Duration
+
+	// SetCompressionLevel sets the compression level for blocks warm flushed
+	// to disk.
+	SetCompressionLevel(value namespace.CompressionLevel) Options
+
+	// CompressionLevel returns the compression level for blocks warm flushed
+	// to disk.
+	CompressionLevel() namespace.CompressionLevel
+}
+
+// WriteBackpressure determines whether writes should be rejected before they
+// are applied to the series buffer, e.g. since they could not be durably
+// logged in a timely manner.
+type WriteBackpressure interface {
+	// Applies returns true if writes should currently be rejected.
+	Applies() bool
 }
 
 // Stats is passed down from namespace/shard to avoid allocations per series.
 type Stats struct {
-	encoderCreated tally.Counter
-	coldWrites     tally.Counter
+	encoderCreated             tally.Counter
+	coldWrites                 tally.Counter
+	coldWritesDisabledDropped  tally.Counter
+	coldWritesDisabledRejected tally.Counter
+	writeFutureSkewRejected    tally.Counter
+	valueBoundsRejected        tally.Counter
+	valueBoundsClamped         tally.Counter
+	outsideRetentionRejected   tally.Counter
+	flushCompressionInBytes    tally.Counter
+	flushCompressionOutBytes   tally.Counter
+	encodersRecycled           tally.Counter
+	maxEncodersPerBucket       *maxEncodersPerBucketStats
+	merges                     [numMergeTriggers]mergeStats
+	inlineMergeErrors          tally.Counter
+	cache                      map[CachePolicy]cacheStats
+	writeStageTimings          bool
+	writeLockWait              tally.Histogram
+	writeBuffer                tally.Histogram
+}
+
+type mergeStats struct {
+	merges         tally.Counter
+	encodersMerged tally.Counter
+}
+
+type cacheStats struct {
+	retrieve     tally.Counter
+	readHit      tally.Counter
+	activeBlocks tally.Gauge
+}
+
+// maxEncodersPerBucketStats is shared by all copies of a Stats so that the
+// maximum is tracked across every series using them.
+type maxEncodersPerBucketStats struct {
+	value int64
+	gauge tally.Gauge
 }
 
 // NewStats returns a new Stats for the provided scope.
 func NewStats(scope tally.Scope) Stats {
 	subScope := scope.SubScope("series")
-	return Stats{
-		encoderCreated: subScope.Counter("encoder-created"),
-		coldWrites:     subScope.Counter("cold-writes"),
+	s := Stats{
+		encoderCreated:             subScope.Counter("encoder-created"),
+		coldWrites:                 subScope.Counter("cold-writes"),
+		coldWritesDisabledDropped:  subScope.Counter("cold-writes-disabled-dropped"),
+		coldWritesDisabledRejected: subScope.Counter("cold-writes-disabled-rejected"),
+		writeFutureSkewRejected:    subScope.Counter("write-future-skew-rejected"),
+		valueBoundsRejected:        subScope.Counter("value-bounds-rejected"),
+		valueBoundsClamped:         subScope.Counter("value-bounds-clamped"),
+		outsideRetentionRejected:   subScope.Counter("write-outside-retention-rejected"),
+		flushCompressionInBytes:    subScope.Counter("flush-compression-in-bytes"),
+		flushCompressionOutBytes:   subScope.Counter("flush-compression-out-bytes"),
+		encodersRecycled:           subScope.Counter("encoders-recycled"),
+		inlineMergeErrors:          subScope.Counter("inline-merge-errors"),
+		maxEncodersPerBucket: &maxEncodersPerBucketStats{
+			gauge: subScope.Gauge("max-encoders-per-bucket"),
+		},
+		writeLockWait: subScope.Histogram("write-lock-wait-latency",
+			WriteStageDurationBuckets()),
+		writeBuffer: subScope.Histogram("write-buffer-latency",
+			WriteStageDurationBuckets()),
+	}
+	for i := range s.merges {
+		triggerScope := subScope.Tagged(map[string]string{
+			"trigger": mergeTrigger(i).String(),
+		})
+		s.merges[i] = mergeStats{
+			merges:         triggerScope.Counter("encoder-merges"),
+			encodersMerged: triggerScope.Counter("encoders-merged"),
+		}
+	}
+	cacheScope := subScope.SubScope("cache")
+	s.cache = make(map[CachePolicy]cacheStats, len(ValidCachePolicies()))
+	for _, policy := range ValidCachePolicies() {
+		policyScope := cacheScope.Tagged(map[string]string{
+			"policy": policy.String(),
+		})
+		s.cache[policy] = cacheStats{
+			retrieve:     policyScope.Counter("retrieve"),
+			readHit:      policyScope.Counter("read-hit"),
+			activeBlocks: policyScope.Gauge("active-blocks"),
+		}
 	}
+	return s
+}
+
+// WriteStageDurationBuckets returns the histogram buckets used for timing
+// the individual stages of the write path.
+func WriteStageDurationBuckets() tally.DurationBuckets {
+	return append(tally.DurationBuckets{0},
+		tally.MustMakeExponentialDurationBuckets(time.Microsecond, 2, 20)...)
+}
+
+// SetWriteStageTimingsEnabled returns a copy of the stats with the timing
+// of the individual write stages enabled or disabled.
+func (s Stats) SetWriteStageTimingsEnabled(value bool) Stats {
+	s.writeStageTimings = value
+	return s
+}
+
+// WriteStageTimingsEnabled returns whether the timing of the individual
+// write stages is enabled.
+func (s Stats) WriteStageTimingsEnabled() bool {
+	return s.writeStageTimings
+}
+
+// RecordWriteLockWait records the time spent waiting to acquire the series
+// lock on the write path.
+func (s Stats) RecordWriteLockWait(d time.Duration) {
+	s.writeLockWait.RecordDuration(d)
+}
+
+// RecordWriteBuffer records the time spent writing to the series buffer.
+func (s Stats) RecordWriteBuffer(d time.Duration) {
+	s.writeBuffer.RecordDuration(d)
 }
 
 // IncCreatedEncoders incs the EncoderCreated stat.
@@ -379,6 +734,112 @@ func (s Stats) IncColdWrites() {
 	s.coldWrites.Inc(1)
 }
 
+// IncColdWritesDisabledDropped incs the ColdWritesDisabledDropped stat.
+func (s Stats) IncColdWritesDisabledDropped() {
+	s.coldWritesDisabledDropped.Inc(1)
+}
+
+// IncColdWritesDisabledRejected incs the ColdWritesDisabledRejected stat.
+func (s Stats) IncColdWritesDisabledRejected() {
+	s.coldWritesDisabledRejected.Inc(1)
+}
+
+// incWriteFutureSkewRejected records a write rejected for having a
+// timestamp too far ahead of now.
+func (s Stats) incWriteFutureSkewRejected() {
+	s.writeFutureSkewRejected.Inc(1)
+}
+
+// incValueBoundsRejected records a write rejected for having a value
+// outside of the namespace value bounds.
+func (s Stats) incValueBoundsRejected() {
+	s.valueBoundsRejected.Inc(1)
+}
+
+// incValueBoundsClamped records a write whose value was clamped to the
+// namespace value bounds.
+func (s Stats) incValueBoundsClamped() {
+	s.valueBoundsClamped.Inc(1)
+}
+
+// incOutsideRetentionRejected records a write rejected for having a
+// timestamp outside of the retention before it reached the buffer.
+func (s Stats) incOutsideRetentionRejected() {
+	s.outsideRetentionRejected.Inc(1)
+}
+
+// recordFlushCompression records the size of a flushed block before and
+// after it was compressed, comparing the two gives the compression ratio.
+func (s Stats) recordFlushCompression(inBytes, outBytes int) {
+	s.flushCompressionInBytes.Inc(int64(inBytes))
+	s.flushCompressionOutBytes.Inc(int64(outBytes))
+}
+
+// incEncodersRecycled records encoders of evicted buffer buckets being
+// returned to the encoder pool, comparing it with the number of encoders
+// created gives the encoder reuse rate.
+func (s Stats) incEncodersRecycled(encoders int) {
+	s.encodersRecycled.Inc(int64(encoders))
+}
+
+// recordEncodersPerBucket records the number of encoders held by a buffer
+// bucket, updating the max encoders per bucket gauge if it is the largest
+// number observed.
+func (s Stats) recordEncodersPerBucket(encoders int) {
+	if s.maxEncodersPerBucket == nil {
+		return
+	}
+	value := int64(encoders)
+	for {
+		curr := atomic.LoadInt64(&s.maxEncodersPerBucket.value)
+		if value <= curr {
+			return
+		}
+		if atomic.CompareAndSwapInt64(&s.maxEncodersPerBucket.value, curr, value) {
+			s.maxEncodersPerBucket.gauge.Update(float64(value))
+			return
+		}
+	}
+}
+
+// incMerges records a merge of the given number of encoders and loaded
+// blocks, broken down by what triggered the merge.
+func (s Stats) incMerges(trigger mergeTrigger, encoders int) {
+	s.merges[trigger].merges.Inc(1)
+	s.merges[trigger].encodersMerged.Inc(int64(encoders))
+}
+
+// incInlineMergeErrors records a failed inline merge of the encoders of a
+// bucket that exceeded the max encoders per bucket, the encoders are left
+// unmerged until the next tick.
+func (s Stats) incInlineMergeErrors() {
+	s.inlineMergeErrors.Inc(1)
+}
+
+// incCacheRetrieve records a block being retrieved from disk, i.e. a cache
+// miss, broken down by the cache policy.
+func (s Stats) incCacheRetrieve(policy CachePolicy) {
+	if c, ok := s.cache[policy]; ok {
+		c.retrieve.Inc(1)
+	}
+}
+
+// incCacheReadHit records a block being read from memory, i.e. a cache hit,
+// broken down by the cache policy.
+func (s Stats) incCacheReadHit(policy CachePolicy) {
+	if c, ok := s.cache[policy]; ok {
+		c.readHit.Inc(1)
+	}
+}
+
+// UpdateCacheActiveBlocks records the number of active blocks sampled during
+// a tick, broken down by the cache policy.
+func (s Stats) UpdateCacheActiveBlocks(policy CachePolicy, activeBlocks int) {
+	if c, ok := s.cache[policy]; ok {
+		c.activeBlocks.Update(float64(activeBlocks))
+	}
+}
+
 // WriteType is an enum for warm/cold write types.
 type WriteType int
 
@@ -390,6 +851,58 @@ const (
 	ColdWrite
 )
 
+func (t WriteType) String() string {
+	switch t {
+	case WarmWrite:
+		return "warm"
+	case ColdWrite:
+		return "cold"
+	}
+	return "unknown"
+}
+
+// BootstrapState is an enum for the bootstrap state of a series.
+type BootstrapState int
+
+const (
+	// BootstrapNotStarted indicates the series has not been bootstrapped.
+	BootstrapNotStarted BootstrapState = iota
+
+	// SeriesBootstrapped indicates the series has been bootstrapped but has
+	// not yet been ticked with bootstrapped block states.
+	SeriesBootstrapped
+
+	// BlockStateBootstrapped indicates the series has been bootstrapped and
+	// ticked with bootstrapped block states.
+	BlockStateBootstrapped
+)
+
+func (s BootstrapState) String() string {
+	switch s {
+	case BootstrapNotStarted:
+		return "not_started"
+	case SeriesBootstrapped:
+		return "series_bootstrapped"
+	case BlockStateBootstrapped:
+		return "block_state_bootstrapped"
+	}
+	return "unknown"
+}
+
+// BufferBlockDebugInfo describes the buffer buckets held for a block start.
+type BufferBlockDebugInfo struct {
+	BlockStart time.Time
+	Buckets    []BufferBucketDebugInfo
+}
+
+// BufferBucketDebugInfo describes a single buffer bucket version.
+type BufferBucketDebugInfo struct {
+	Version         int
+	WriteType       WriteType
+	NumEncoders     int
+	NumLoadedBlocks int
+}
+
 // WriteTransformOptions describes transforms to run on incoming writes.
 type WriteTransformOptions struct {
 	// ForceValueEnabled indicates if the values for incoming writes
@@ -397,6 +910,19 @@ type WriteTransformOptions struct {
 	ForceValueEnabled bool
 	// ForceValue is the value that incoming writes should be forced to.
 	ForceValue float64
+	// ValueBoundsEnabled indicates if the values for incoming writes
+	// should be validated against `MinValue` and `MaxValue`.
+	ValueBoundsEnabled bool
+	// MinValue is the min value, inclusive, incoming writes may have.
+	MinValue float64
+	// MaxValue is the max value, inclusive, incoming writes may have.
+	MaxValue float64
+	// ClampValues indicates if out of bounds values should be clamped to
+	// the nearest bound rather than rejected.
+	ClampValues bool
+	// RoundToMultiple rounds the values for incoming writes to the nearest
+	// multiple of it when positive, e.g. 0.5 or 0.01 for two decimal places.
+	RoundToMultiple float64
 }
 
 // WriteOptions provides a set of options for a write.
@@ -407,6 +933,23 @@ type WriteOptions struct {
 	TruncateType TruncateType
 	// TransformOptions describes transformation options for incoming writes.
 	TransformOptions WriteTransformOptions
+	// DropDisabledColdWrites describes whether writes outside of the buffer
+	// past/future window are silently dropped rather than rejected with an
+	// error when cold writes are disabled.
+	DropDisabledColdWrites bool
+}
+
+// AnnotationPredicate returns true if a datapoint with the annotation should
+// be read, the annotation must not be retained after returning.
+type AnnotationPredicate func(annotation ts.Annotation) bool
+
+// ReadOptions provides a set of options for a filtered read.
+type ReadOptions struct {
+	// AnnotationPredicate filters datapoints by their annotation, all
+	// datapoints are read if not set. Encoders only write an annotation when
+	// it differs from the previous one, so the predicate is applied to the
+	// last annotation written at or before each datapoint, or nil if none.
+	AnnotationPredicate AnnotationPredicate
 }
 
 // LoadOptions contains the options for the Load() method.
@@ -415,6 +958,9 @@ type LoadOptions struct {
 	// or if additional data is being loaded after the fact (as in the case
 	// of repairs).
 	Bootstrap bool
+	// SchemaDesc is the schema description used to decode blocks when
+	// verifying them before they are loaded.
+	SchemaDesc namespace.SchemaDescr
 }
 
 // LoadResult contains the return information for the Load() method.
@@ -429,4 +975,5 @@ type LoadResult struct {
 type BootstrapResult struct {
 	NumBlocksMovedToBuffer int64
 	NumBlocksMerged        int64
+	NumBlocksCorrupt       int64
 }
@@ -21,12 +21,16 @@
 package storage
 
 import (
+	"container/heap"
 	"container/list"
 	"errors"
 	"fmt"
 	"io"
 	"math"
+	"math/rand"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/m3db/m3/src/dbnode/clock"
@@ -38,6 +42,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
+	dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/index"
 	"github.com/m3db/m3/src/dbnode/storage/index/convert"
 	"github.com/m3db/m3/src/dbnode/storage/repair"
@@ -175,9 +180,20 @@ type dbShard struct {
 	currRuntimeOptions       dbShardRuntimeOptions
 	logger                   *zap.Logger
 	metrics                  dbShardMetrics
+	writeSubscriptions       *shardWriteSubscriptions
 	newSeriesBootstrapped    bool
 	ticking                  bool
+	lastTickStatus           series.TickStatus
 	shard                    uint32
+	// dirty is set atomically on each write and cleared when a snapshot
+	// of the shard begins, dirtyClearedAt is the snapshot time it was
+	// last cleared for and is protected by the shard mutex. It is only
+	// reported as a metric, snapshots never skip clean shards since the
+	// snapshot cleanup only retains the filesets of the latest snapshot.
+	// It is not cleared on flush since a flush of one block start leaves
+	// the writes to other block starts unsnapshotted.
+	dirty          int32
+	dirtyClearedAt time.Time
 }
 
 // NB(r): dbShardRuntimeOptions does not contain its own
@@ -189,6 +205,8 @@ type dbShardRuntimeOptions struct {
 	writeNewSeriesAsync      bool
 	tickSleepSeriesBatchSize int
 	tickSleepPerSeries       time.Duration
+	maxReadBlockReaders      int
+	dropDisabledColdWrites   bool
 }
 
 type dbShardMetrics struct {
@@ -201,7 +219,15 @@ type dbShardMetrics struct {
 	insertAsyncWriteErrors        tally.Counter
 	seriesBootstrapBlocksToBuffer tally.Counter
 	seriesBootstrapBlocksMerged   tally.Counter
+	seriesBootstrapBlocksCorrupt  tally.Counter
 	seriesTicked                  tally.Gauge
+	seriesEstimatedMemoryBytes    tally.Gauge
+	evictedCachedBlocks           tally.Counter
+	readBlockReadersLimitExceeded tally.Counter
+	consistencyCheckedSeries      tally.Counter
+	cachedBlockBufferOverlaps     tally.Counter
+	seriesIdleEvicted             tally.Counter
+	tickSkippedUnbootstrapped     tally.Counter
 }
 
 func newDatabaseShardMetrics(shardID uint32, scope tally.Scope) dbShardMetrics {
@@ -222,9 +248,19 @@ func newDatabaseShardMetrics(shardID uint32, scope tally.Scope) dbShardMetrics {
 		}).Counter("insert-async.errors"),
 		seriesBootstrapBlocksToBuffer: seriesBootstrapScope.Counter("blocks-to-buffer"),
 		seriesBootstrapBlocksMerged:   seriesBootstrapScope.Counter("blocks-merged"),
+		seriesBootstrapBlocksCorrupt:  seriesBootstrapScope.Counter("blocks-corrupt"),
 		seriesTicked: scope.Tagged(map[string]string{
 			"shard": fmt.Sprintf("%d", shardID),
 		}).Gauge("series-ticked"),
+		seriesEstimatedMemoryBytes: scope.Tagged(map[string]string{
+			"shard": fmt.Sprintf("%d", shardID),
+		}).Gauge("series-estimated-memory-bytes"),
+		evictedCachedBlocks:           scope.Counter("evicted-cached-blocks"),
+		consistencyCheckedSeries:      scope.Counter("consistency-checked-series"),
+		cachedBlockBufferOverlaps:     scope.Counter("cached-block-buffer-overlaps"),
+		seriesIdleEvicted:             scope.Counter("series-idle-evicted"),
+		tickSkippedUnbootstrapped:     scope.Counter("tick-skipped-unbootstrapped-blocks"),
+		readBlockReadersLimitExceeded: scope.Counter("read-block-readers-limit-exceeded"),
 	}
 }
 
@@ -286,9 +322,11 @@ func newDatabaseShard(
 		tickWg:               &sync.WaitGroup{},
 		logger:               opts.InstrumentOptions().Logger(),
 		metrics:              newDatabaseShardMetrics(shard, scope),
+		writeSubscriptions:   newShardWriteSubscriptions(scope),
 	}
 	s.insertQueue = newDatabaseShardInsertQueue(s.insertSeriesBatch,
-		s.nowFn, scope)
+		s.nowFn, namespaceMetadata.Options().WriteNewSeriesBackoffDuration(),
+		scope)
 
 	registerRuntimeOptionsListener := func(listener runtime.OptionsListener) {
 		elem := opts.RuntimeOptionsManager().RegisterListener(listener)
@@ -330,6 +368,8 @@ func (s *dbShard) SetRuntimeOptions(value runtime.Options) {
 		writeNewSeriesAsync:      value.WriteNewSeriesAsync(),
 		tickSleepSeriesBatchSize: value.TickSeriesBatchSize(),
 		tickSleepPerSeries:       value.TickPerSeriesSleepDuration(),
+		maxReadBlockReaders:      value.MaxReadBlockReaders(),
+		dropDisabledColdWrites:   value.DropDisabledColdWrites(),
 	}
 	s.Unlock()
 }
@@ -633,7 +673,20 @@ func (s *dbShard) isClosingWithLock() bool {
 
 func (s *dbShard) Tick(c context.Cancellable, tickStart time.Time, nsCtx namespace.Context) (tickResult, error) {
 	s.removeAnyFlushStatesTooEarly(tickStart)
-	return s.tickAndExpire(c, tickPolicyRegular, nsCtx)
+	r, err := s.tickAndExpire(c, tickPolicyRegular, nsCtx)
+	if err == nil {
+		s.metrics.seriesEstimatedMemoryBytes.Update(float64(r.estimatedMemoryBytes))
+		s.metrics.tickSkippedUnbootstrapped.Inc(int64(r.skippedUnbootstrappedBlocks))
+		s.Lock()
+		s.lastTickStatus = series.TickStatus{
+			ActiveBlocks:       r.activeBlocks,
+			WiredBlocks:        r.wiredBlocks,
+			UnwiredBlocks:      r.unwiredBlocks,
+			PendingMergeBlocks: r.pendingMergeBlocks,
+		}
+		s.Unlock()
+	}
+	return r, err
 }
 
 func (s *dbShard) tickAndExpire(
@@ -677,10 +730,12 @@ func (s *dbShard) tickAndExpire(
 		i                             int
 		slept                         time.Duration
 		expired                       []*lookup.Entry
+		idle                          []*lookup.Entry
 	)
 	s.RLock()
 	tickSleepBatch := s.currRuntimeOptions.tickSleepSeriesBatchSize
 	tickSleepPerSeries := s.currRuntimeOptions.tickSleepPerSeries
+	consistencyCheckSampleRate := s.opts.ConsistencyCheckSampleRate()
 	// Acquire snapshot of block states here to avoid releasing the
 	// RLock and acquiring it right after.
 	blockStates := s.BlockStatesSnapshot()
@@ -728,11 +783,18 @@ func (s *dbShard) tickAndExpire(
 			if err == series.ErrSeriesAllDatapointsExpired {
 				expired = append(expired, entry)
 				r.expiredSeries++
+			} else if err == series.ErrSeriesIdle {
+				idle = append(idle, entry)
 			} else {
 				r.activeSeries++
+				r.estimatedMemoryBytes += entry.Series.EstimatedMemoryBytes()
 				if err != nil {
 					r.errors++
 				}
+				if consistencyCheckSampleRate > 0 &&
+					rand.Float64() < consistencyCheckSampleRate {
+					s.checkSeriesConsistency(entry)
+				}
 			}
 			r.activeBlocks += result.ActiveBlocks
 			r.wiredBlocks += result.WiredBlocks
@@ -742,6 +804,7 @@ func (s *dbShard) tickAndExpire(
 			r.madeUnwiredBlocks += result.MadeUnwiredBlocks
 			r.mergedOutOfOrderBlocks += result.MergedOutOfOrderBlocks
 			r.evictedBuckets += result.EvictedBuckets
+			r.skippedUnbootstrappedBlocks += result.SkippedUnbootstrappedBlocks
 			i++
 		}
 
@@ -753,6 +816,16 @@ func (s *dbShard) tickAndExpire(
 			}
 			expired = expired[:0]
 		}
+		// Evict any idle series, they are purged the same as expired series
+		// since all of their blocks have been unwired.
+		if len(idle) > 0 {
+			evicted := s.purgeExpiredSeries(idle)
+			s.metrics.seriesIdleEvicted.Inc(int64(evicted))
+			for i := range idle {
+				idle[i] = nil
+			}
+			idle = idle[:0]
+		}
 		// Continue
 		return true
 	})
@@ -769,8 +842,9 @@ func (s *dbShard) tickAndExpire(
 // Currently, this function is only called by the lambda inside `tickAndExpire`'s `forEachShardEntryBatch`
 // call. This satisfies the contract of all entries it operating upon being guaranteed to have a
 // readerWriterEntryCount of at least 1, by virtue of the implementation of `forEachShardEntryBatch`.
-func (s *dbShard) purgeExpiredSeries(expiredEntries []*lookup.Entry) {
+func (s *dbShard) purgeExpiredSeries(expiredEntries []*lookup.Entry) int {
 	// Remove all expired series from lookup and list.
+	var purged int
 	s.Lock()
 	for _, entry := range expiredEntries {
 		series := entry.Series
@@ -805,8 +879,10 @@ func (s *dbShard) purgeExpiredSeries(expiredEntries []*lookup.Entry) {
 		series.Close()
 		s.list.Remove(elem)
 		s.lookup.Delete(id)
+		purged++
 	}
 	s.Unlock()
+	return purged
 }
 
 func (s *dbShard) WriteTagged(
@@ -853,6 +929,7 @@ func (s *dbShard) writeAndIndex(
 		return ts.Series{}, false, err
 	}
 
+	wOpts.DropDisabledColdWrites = opts.dropDisabledColdWrites
 	writable := entry != nil
 
 	// If no entry and we are not writing new series asynchronously.
@@ -897,6 +974,11 @@ func (s *dbShard) writeAndIndex(
 		// synchronously and all downstream code will copy anthing they need to maintain
 		// a reference to.
 		wasWritten, err = entry.Series.Write(ctx, timestamp, value, unit, annotation, wOpts)
+		if wasWritten {
+			s.markDirty()
+			s.writeSubscriptions.publish(id,
+				ts.Datapoint{Timestamp: timestamp, Value: value})
+		}
 		// Load series metadata before decrementing the writer count
 		// to ensure this metadata is snapshotted at a consistent state
 		// NB(r): We explicitly do not place the series ID back into a
@@ -983,10 +1065,11 @@ func (s *dbShard) ReadEncoded(
 		entry.IncrementReaderWriterCount()
 		defer entry.DecrementReaderWriterCount()
 	}
+	maxReadBlockReaders := s.currRuntimeOptions.maxReadBlockReaders
 	s.RUnlock()
 
 	if err == errShardEntryNotFound {
-		switch s.opts.SeriesCachePolicy() {
+		switch s.seriesOpts.CachePolicy() {
 		case series.CacheAll:
 			// No-op, would be in memory if cached
 			return nil, nil
@@ -995,15 +1078,26 @@ func (s *dbShard) ReadEncoded(
 		return nil, err
 	}
 
+	var results [][]xio.BlockReader
 	if entry != nil {
-		return entry.Series.ReadEncoded(ctx, start, end, nsCtx)
+		results, err = entry.Series.ReadEncodedWithLimit(ctx, start, end,
+			maxReadBlockReaders, nsCtx)
+	} else {
+		retriever := s.seriesBlockRetriever
+		onRetrieve := s.seriesOnRetrieveBlock
+		opts := s.seriesOpts
+		reader := series.NewReaderUsingRetriever(id, retriever, onRetrieve, nil, opts)
+		results, err = reader.ReadEncodedWithLimit(ctx, start, end,
+			maxReadBlockReaders, nsCtx)
+	}
+	if err != nil {
+		if dberrors.IsQueryTooLargeError(err) {
+			s.metrics.readBlockReadersLimitExceeded.Inc(1)
+		}
+		return nil, err
 	}
 
-	retriever := s.seriesBlockRetriever
-	onRetrieve := s.seriesOnRetrieveBlock
-	opts := s.seriesOpts
-	reader := series.NewReaderUsingRetriever(id, retriever, onRetrieve, nil, opts)
-	return reader.ReadEncoded(ctx, start, end, nsCtx)
+	return results, nil
 }
 
 // lookupEntryWithLock returns the entry for a given id while holding a read lock or a write lock.
@@ -1388,11 +1482,16 @@ func (s *dbShard) insertSeriesBatch(inserts []dbShardInsert) error {
 			// operation and there is nothing further to do with this value.
 			// TODO: Consider propagating the `wasWritten` argument back to the caller
 			// using waitgroup (or otherwise) in the future.
-			_, err := entry.Series.Write(ctx, write.timestamp, write.value,
+			wasWritten, err := entry.Series.Write(ctx, write.timestamp, write.value,
 				write.unit, annotationBytes, write.opts)
 			if err != nil {
 				s.metrics.insertAsyncWriteErrors.Inc(1)
 			}
+			if wasWritten {
+				s.markDirty()
+				s.writeSubscriptions.publish(entry.Series.ID(),
+					ts.Datapoint{Timestamp: write.timestamp, Value: write.value})
+			}
 
 			if write.annotation != nil {
 				// Now that we've performed the write, we can finalize the annotation because
@@ -1467,7 +1566,7 @@ func (s *dbShard) FetchBlocks(
 	s.RUnlock()
 
 	if err == errShardEntryNotFound {
-		switch s.opts.SeriesCachePolicy() {
+		switch s.seriesOpts.CachePolicy() {
 		case series.CacheAll:
 			// No-op, would be in memory if cached
 			return nil, nil
@@ -1577,7 +1676,7 @@ func (s *dbShard) FetchBlocksMetadataV2(
 	activePhase := token.ActiveSeriesPhase
 	flushedPhase := token.FlushedSeriesPhase
 
-	cachePolicy := s.opts.SeriesCachePolicy()
+	cachePolicy := s.seriesOpts.CachePolicy()
 	if cachePolicy == series.CacheAll {
 		// If we are using a series cache policy that caches all block metadata
 		// in memory then we only ever perform the active phase as all metadata
@@ -1727,7 +1826,19 @@ func (s *dbShard) FetchBlocksMetadataV2(
 		}
 
 		for numResults < limit {
-			id, tags, size, checksum, err := reader.ReadMetadata()
+			var (
+				id          ident.ID
+				tags        ident.TagIterator
+				encodedTags []byte
+				size        int
+				checksum    uint32
+				err         error
+			)
+			if opts.LazyTags {
+				id, encodedTags, size, checksum, err = reader.ReadMetadataEncodedTags()
+			} else {
+				id, tags, size, checksum, err = reader.ReadMetadata()
+			}
 			if err == io.EOF {
 				// Clean end of volume, we can break now
 				if err := reader.Close(); err != nil {
@@ -1761,8 +1872,12 @@ func (s *dbShard) FetchBlocksMetadataV2(
 			blockResult.Add(value)
 
 			numResults++
-			result.Add(block.NewFetchBlocksMetadataResult(id, tags,
-				blockResult))
+			res := block.NewFetchBlocksMetadataResult(id, tags, blockResult)
+			if opts.LazyTags && len(encodedTags) > 0 {
+				res.EncodedTags = encodedTags
+				res.ResolveTags = s.newLazyTagsResolver(encodedTags)
+			}
+			result.Add(res)
 		}
 
 		// Return the reader to the cache
@@ -1888,6 +2003,7 @@ func (s *dbShard) loadSeries(
 		// Only used for the bootstrap path.
 		shardBootstrapResult = dbShardBootstrapResult{}
 		multiErr             = xerrors.NewMultiError()
+		nsCtx                = namespace.NewContextFrom(s.namespace)
 	)
 	// Safe to use the same snapshot for all the series since the block states can't change while
 	// this is running since no warm/cold flushes can occur while the bootstrap is ongoing.
@@ -1925,7 +2041,10 @@ func (s *dbShard) loadSeries(
 			dbBlocks.Tags.Finalize()
 		}
 
-		loadOpts := series.LoadOptions{Bootstrap: bootstrap}
+		loadOpts := series.LoadOptions{
+			Bootstrap:  bootstrap,
+			SchemaDesc: nsCtx.Schema,
+		}
 		loadResult, err := entry.Series.Load(
 			loadOpts,
 			dbBlocks.Blocks,
@@ -2222,21 +2341,51 @@ func (s *dbShard) ColdFlush(
 	return multiErr.FinalError()
 }
 
+func (s *dbShard) IsDirty() bool {
+	return atomic.LoadInt32(&s.dirty) == 1
+}
+
+func (s *dbShard) markDirty() {
+	// NB: avoid contending on the cache line with a store on every write.
+	if atomic.LoadInt32(&s.dirty) == 0 {
+		atomic.StoreInt32(&s.dirty, 1)
+	}
+}
+
 func (s *dbShard) Snapshot(
 	blockStart time.Time,
 	snapshotTime time.Time,
 	snapshotPreparer persist.SnapshotPreparer,
 	nsCtx namespace.Context,
-) error {
+) (SnapshotResult, error) {
+	var result SnapshotResult
+
 	// We don't snapshot data when the shard is still bootstrapping
 	s.RLock()
 	if s.bootstrapState != Bootstrapped {
 		s.RUnlock()
-		return errShardNotBootstrappedToSnapshot
+		return result, errShardNotBootstrappedToSnapshot
 	}
 	s.RUnlock()
 
+	// NB: the shard is snapshotted once per block start for each snapshot
+	// time, clear the dirty flag before the first of these so that any write
+	// that lands after this point marks the shard dirty again. Writes before
+	// this point are captured by the snapshots that follow.
+	s.Lock()
+	if !snapshotTime.Equal(s.dirtyClearedAt) {
+		s.dirtyClearedAt = snapshotTime
+		atomic.StoreInt32(&s.dirty, 0)
+	}
+	s.Unlock()
+
 	var multiErr xerrors.MultiError
+	defer func() {
+		if multiErr.FinalError() != nil {
+			// Snapshot did not complete so the shard remains dirty.
+			s.markDirty()
+		}
+	}()
 
 	prepareOpts := persist.DataPrepareOptions{
 		NamespaceMetadata: s.namespace,
@@ -2256,7 +2405,17 @@ func (s *dbShard) Snapshot(
 	// Add the err so the defer will capture it
 	multiErr = multiErr.Add(err)
 	if err != nil {
-		return err
+		return result, err
+	}
+
+	persistFn := func(
+		id ident.ID,
+		tags ident.Tags,
+		segment ts.Segment,
+		checksum uint32,
+	) error {
+		result.SeriesPersist++
+		return prepared.Persist(id, tags, segment, checksum)
 	}
 
 	tmpCtx := context.NewContext()
@@ -2265,7 +2424,7 @@ func (s *dbShard) Snapshot(
 		// Use a temporary context here so the stream readers can be returned to
 		// pool after we finish fetching flushing the series
 		tmpCtx.Reset()
-		err := series.Snapshot(tmpCtx, blockStart, prepared.Persist, nsCtx)
+		err := series.Snapshot(tmpCtx, blockStart, persistFn, nsCtx)
 		tmpCtx.BlockingClose()
 
 		if err != nil {
@@ -2282,7 +2441,7 @@ func (s *dbShard) Snapshot(
 		multiErr = multiErr.Add(err)
 	}
 
-	return multiErr.FinalError()
+	return result, multiErr.FinalError()
 }
 
 func (s *dbShard) FlushState(blockStart time.Time) (fileOpState, error) {
@@ -2295,6 +2454,164 @@ func (s *dbShard) FlushState(blockStart time.Time) (fileOpState, error) {
 	return s.flushStateWithRLock(blockStart), nil
 }
 
+func (s *dbShard) SeriesBufferDebugInfo(
+	id ident.ID,
+) ([]series.BufferBlockDebugInfo, error) {
+	s.RLock()
+	entry, _, err := s.lookupEntryWithLock(id)
+	if entry != nil {
+		// Ensure the series is not expired while being inspected.
+		entry.IncrementReaderWriterCount()
+		defer entry.DecrementReaderWriterCount()
+	}
+	s.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return entry.Series.BufferDebugInfo(), nil
+}
+
+func (s *dbShard) Stats() ShardStats {
+	s.RLock()
+	tickStatus := s.lastTickStatus
+	s.RUnlock()
+
+	stats := ShardStats{
+		Shard:              s.ID(),
+		WiredBlocks:        tickStatus.WiredBlocks,
+		PendingMergeBlocks: tickStatus.PendingMergeBlocks,
+	}
+	blockStates, bootstrapped := s.BlockStatesSnapshot().UnwrapValue()
+	s.forEachShardEntry(func(entry *lookup.Entry) bool {
+		stats.NumSeries++
+		switch entry.Series.BootstrapState() {
+		case series.BootstrapNotStarted:
+			stats.NumSeriesNotBootstrapped++
+		case series.SeriesBootstrapped:
+			stats.NumSeriesBlockStatesNotBootstrapped++
+		}
+		stats.ActiveBlocks += entry.Series.NumActiveBlocks()
+		stats.EstimatedMemoryBytes += entry.Series.EstimatedMemoryBytes()
+		if bootstrapped {
+			// Cold flush block starts can only be determined once the
+			// flush states of the shard are bootstrapped.
+			blockStarts := entry.Series.ColdFlushBlockStarts(blockStates)
+			stats.PendingColdWriteBlocks += blockStarts.Len()
+		}
+		return true
+	})
+	return stats
+}
+
+func (s *dbShard) ApproxMemoryBySeries(n int) []SeriesMemory {
+	if n <= 0 {
+		return nil
+	}
+
+	// Keep only the n largest series seen so far in a min heap so that the
+	// series IDs are only copied for the candidates rather than every series.
+	largest := make(seriesMemoryHeap, 0, n)
+	s.forEachShardEntry(func(entry *lookup.Entry) bool {
+		bytes := entry.Series.EstimatedMemoryBytes()
+		if len(largest) == n {
+			if bytes <= largest[0].EstimatedMemoryBytes {
+				return true
+			}
+			heap.Pop(&largest)
+		}
+		heap.Push(&largest, SeriesMemory{
+			Shard:                s.shard,
+			ID:                   ident.BytesID(append([]byte(nil), entry.Series.ID().Bytes()...)),
+			EstimatedMemoryBytes: bytes,
+		})
+		return true
+	})
+
+	result := []SeriesMemory(largest)
+	sortSeriesMemoryDesc(result)
+	return result
+}
+
+// seriesMemoryHeap is a min heap of series ordered by estimated memory.
+type seriesMemoryHeap []SeriesMemory
+
+func (h seriesMemoryHeap) Len() int { return len(h) }
+func (h seriesMemoryHeap) Less(i, j int) bool {
+	return h[i].EstimatedMemoryBytes < h[j].EstimatedMemoryBytes
+}
+func (h seriesMemoryHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *seriesMemoryHeap) Push(x interface{}) {
+	*h = append(*h, x.(SeriesMemory))
+}
+
+func (h *seriesMemoryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+func sortSeriesMemoryDesc(values []SeriesMemory) {
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].EstimatedMemoryBytes > values[j].EstimatedMemoryBytes
+	})
+}
+
+// newLazyTagsResolver returns a function that decodes the encoded tags using
+// the tag decoder pool only when called.
+func (s *dbShard) newLazyTagsResolver(encodedTags []byte) func() ident.TagIterator {
+	return func() ident.TagIterator {
+		decoderPool := s.opts.CommitLogOptions().FilesystemOptions().TagDecoderPool()
+		decoder := decoderPool.Get()
+		decoder.Reset(checked.NewBytes(encodedTags, nil))
+		return decoder
+	}
+}
+
+// checkSeriesConsistency verifies that no block start of the series is present
+// in both its cached blocks and its buffer since that can lead to datapoints
+// being double counted on read.
+func (s *dbShard) checkSeriesConsistency(entry *lookup.Entry) {
+	s.metrics.consistencyCheckedSeries.Inc(1)
+	overlap := entry.Series.CachedBlockBufferOverlap()
+	if overlap.Len() == 0 {
+		return
+	}
+
+	s.metrics.cachedBlockBufferOverlaps.Inc(int64(overlap.Len()))
+	blockStarts := make([]time.Time, 0, overlap.Len())
+	overlap.ForEach(func(t xtime.UnixNano) {
+		blockStarts = append(blockStarts, t.ToTime())
+	})
+	instrument.EmitAndLogInvariantViolation(s.opts.InstrumentOptions(),
+		func(l *zap.Logger) {
+			l.Error("series block starts present in both cached blocks and buffer",
+				zap.Stringer("id", entry.Series.ID()),
+				zap.Uint32("shard", s.shard),
+				zap.Times("blockStarts", blockStarts))
+		})
+}
+
+func (s *dbShard) SubscribeWrites(id ident.ID, bufferSize int) WriteSubscription {
+	return s.writeSubscriptions.subscribe(id, bufferSize)
+}
+
+func (s *dbShard) EvictIdleCachedBlocks(idleFor time.Duration) int {
+	var (
+		blockStates = s.BlockStatesSnapshot()
+		evicted     int
+	)
+	s.forEachShardEntry(func(entry *lookup.Entry) bool {
+		evicted += entry.Series.EvictCachedBlocks(blockStates, idleFor)
+		return true
+	})
+	s.metrics.evictedCachedBlocks.Inc(int64(evicted))
+	return evicted
+}
+
 func (s *dbShard) flushStateNoBootstrapCheck(blockStart time.Time) fileOpState {
 	s.flushState.RLock()
 	defer s.flushState.RUnlock()
@@ -2434,6 +2751,7 @@ func (s *dbShard) BootstrapState() BootstrapState {
 func (s *dbShard) emitBootstrapResult(r dbShardBootstrapResult) {
 	s.metrics.seriesBootstrapBlocksToBuffer.Inc(r.numBlocksMovedToBuffer)
 	s.metrics.seriesBootstrapBlocksMerged.Inc(r.numBlocksMerged)
+	s.metrics.seriesBootstrapBlocksCorrupt.Inc(r.numBlocksCorrupt)
 }
 
 func (s *dbShard) logFlushResult(r dbShardFlushResult) {
@@ -2448,11 +2766,13 @@ func (s *dbShard) logFlushResult(r dbShardFlushResult) {
 type dbShardBootstrapResult struct {
 	numBlocksMovedToBuffer int64
 	numBlocksMerged        int64
+	numBlocksCorrupt       int64
 }
 
 func (r *dbShardBootstrapResult) update(u series.BootstrapResult) {
 	r.numBlocksMovedToBuffer += u.NumBlocksMovedToBuffer
 	r.numBlocksMerged += u.NumBlocksMerged
+	r.numBlocksCorrupt += u.NumBlocksCorrupt
 }
 
 // dbShardFlushResult is a helper struct for keeping track of the result of flushing all the
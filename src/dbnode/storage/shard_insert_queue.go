@@ -31,6 +31,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/storage/series/lookup"
 	"github.com/m3db/m3/src/dbnode/ts"
 	"github.com/m3db/m3/src/x/checked"
+	xerrors "github.com/m3db/m3/src/x/errors"
 	"github.com/m3db/m3/src/x/ident"
 	xtime "github.com/m3db/m3/src/x/time"
 
@@ -42,6 +43,8 @@ var (
 	errShardInsertQueueNotOpen             = errors.New("shard insert queue is not open")
 	errShardInsertQueueAlreadyOpenOrClosed = errors.New("shard insert queue already open or is closed")
 	errNewSeriesInsertRateLimitExceeded    = errors.New("shard insert of new series exceeds rate limit")
+	errNewSeriesInsertMaxPendingExceeded   = xerrors.NewRetryableError(
+		errors.New("shard insert of new series exceeds max pending inserts"))
 )
 
 type dbShardInsertQueueState int
@@ -64,9 +67,17 @@ type dbShardInsertQueue struct {
 	insertBatchBackoff   time.Duration
 	insertPerSecondLimit int
 
+	// insertBatchBackoffOverride when non-zero takes precedence over the
+	// runtime options backoff, set per namespace.
+	insertBatchBackoffOverride time.Duration
+
 	insertPerSecondLimitWindowNanos  int64
 	insertPerSecondLimitWindowValues int
 
+	// pending inserts limit, protected by mutex
+	maxPendingWriteInserts int
+	pendingInserts         int
+
 	currBatch    *dbShardInsertBatch
 	notifyInsert chan struct{}
 	closeCh      chan struct{}
@@ -75,8 +86,9 @@ type dbShardInsertQueue struct {
 }
 
 type dbShardInsertQueueMetrics struct {
-	insertsNoPendingWrite tally.Counter
-	insertsPendingWrite   tally.Counter
+	insertsNoPendingWrite    tally.Counter
+	insertsPendingWrite      tally.Counter
+	insertsMaxPendingRejects tally.Counter
 }
 
 func newDatabaseShardInsertQueueMetrics(
@@ -91,6 +103,7 @@ func newDatabaseShardInsertQueueMetrics(
 		insertsPendingWrite: scope.Tagged(map[string]string{
 			insertPendingWriteTagName: "yes",
 		}).Counter(insertName),
+		insertsMaxPendingRejects: scope.Counter("max-pending-rejects"),
 	}
 }
 
@@ -171,9 +184,13 @@ type dbShardInsertEntryBatchFn func(inserts []dbShardInsert) error
 // The batching as it is without any sleep and just relying on a notification
 // trigger and hot looping when being flooded improved by a factor of roughly
 // 4x during floods of new series.
+//
+// A non-zero insertBatchBackoffOverride is used as the backoff between
+// batches instead of the runtime options value.
 func newDatabaseShardInsertQueue(
 	insertEntryBatchFn dbShardInsertEntryBatchFn,
 	nowFn clock.NowFn,
+	insertBatchBackoffOverride time.Duration,
 	scope tally.Scope,
 ) *dbShardInsertQueue {
 	currBatch := &dbShardInsertBatch{}
@@ -183,17 +200,24 @@ func newDatabaseShardInsertQueue(
 		nowFn:              nowFn,
 		insertEntryBatchFn: insertEntryBatchFn,
 		sleepFn:            time.Sleep,
+		insertBatchBackoff: insertBatchBackoffOverride,
 		currBatch:          currBatch,
 		notifyInsert:       make(chan struct{}, 1),
 		closeCh:            make(chan struct{}, 1),
 		metrics:            newDatabaseShardInsertQueueMetrics(subscope),
+
+		insertBatchBackoffOverride: insertBatchBackoffOverride,
 	}
 }
 
 func (q *dbShardInsertQueue) SetRuntimeOptions(value runtime.Options) {
 	q.Lock()
 	q.insertBatchBackoff = value.WriteNewSeriesBackoffDuration()
+	if q.insertBatchBackoffOverride > 0 {
+		q.insertBatchBackoff = q.insertBatchBackoffOverride
+	}
 	q.insertPerSecondLimit = value.WriteNewSeriesLimitPerShardPerSecond()
+	q.maxPendingWriteInserts = value.WriteNewSeriesMaxPendingPerShard()
 	q.Unlock()
 }
 
@@ -236,8 +260,11 @@ func (q *dbShardInsertQueue) insertLoop() {
 			q.Unlock()
 		}
 
-		if len(batch.inserts) > 0 {
+		if n := len(batch.inserts); n > 0 {
 			q.insertEntryBatchFn(batch.inserts)
+			q.Lock()
+			q.pendingInserts -= n
+			q.Unlock()
 		}
 		batch.wg.Done()
 
@@ -310,6 +337,16 @@ func (q *dbShardInsertQueue) Insert(insert dbShardInsert) (*sync.WaitGroup, erro
 			return nil, errNewSeriesInsertRateLimitExceeded
 		}
 	}
+	if limit := q.maxPendingWriteInserts; limit > 0 && insert.opts.hasPendingWrite {
+		// Only new series writes are rejected, other inserts such as those
+		// for indexing are still queued.
+		if q.pendingInserts >= limit {
+			q.Unlock()
+			q.metrics.insertsMaxPendingRejects.Inc(1)
+			return nil, errNewSeriesInsertMaxPendingExceeded
+		}
+	}
+	q.pendingInserts++
 	q.currBatch.inserts = append(q.currBatch.inserts, insert)
 	wg := q.currBatch.wg
 	q.Unlock()
@@ -27,6 +27,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/dbnode/runtime"
+	xerrors "github.com/m3db/m3/src/x/errors"
+
 	"github.com/fortytw2/leaktest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -64,7 +67,7 @@ func TestShardInsertQueueBatchBackoff(t *testing.T) {
 		timeLock.Lock()
 		defer timeLock.Unlock()
 		return currTime
-	}, tally.NoopScope)
+	}, 0, tally.NoopScope)
 
 	q.insertBatchBackoff = backoff
 
@@ -124,6 +127,24 @@ func TestShardInsertQueueBatchBackoff(t *testing.T) {
 	insertProgressWgs[2].Done()
 }
 
+func TestShardInsertQueueBatchBackoffOverride(t *testing.T) {
+	runtimeOpts := runtime.NewOptions().
+		SetWriteNewSeriesBackoffDuration(time.Millisecond)
+
+	q := newDatabaseShardInsertQueue(func(value []dbShardInsert) error {
+		return nil
+	}, time.Now, 0, tally.NoopScope)
+	q.SetRuntimeOptions(runtimeOpts)
+	require.Equal(t, time.Millisecond, q.insertBatchBackoff)
+
+	q = newDatabaseShardInsertQueue(func(value []dbShardInsert) error {
+		return nil
+	}, time.Now, 5*time.Millisecond, tally.NoopScope)
+	require.Equal(t, 5*time.Millisecond, q.insertBatchBackoff)
+	q.SetRuntimeOptions(runtimeOpts)
+	require.Equal(t, 5*time.Millisecond, q.insertBatchBackoff)
+}
+
 func TestShardInsertQueueRateLimit(t *testing.T) {
 	defer leaktest.CheckTimeout(t, time.Second)()
 
@@ -142,7 +163,7 @@ func TestShardInsertQueueRateLimit(t *testing.T) {
 		timeLock.Lock()
 		defer timeLock.Unlock()
 		return currTime
-	}, tally.NoopScope)
+	}, 0, tally.NoopScope)
 
 	q.insertPerSecondLimit = 2
 
@@ -191,6 +212,49 @@ func TestShardInsertQueueRateLimit(t *testing.T) {
 	q.Unlock()
 }
 
+func TestShardInsertQueueMaxPendingWriteInserts(t *testing.T) {
+	defer leaktest.CheckTimeout(t, time.Second)()
+
+	unblock := make(chan struct{})
+	q := newDatabaseShardInsertQueue(func(value []dbShardInsert) error {
+		<-unblock
+		return nil
+	}, time.Now, 0, tally.NoopScope)
+
+	q.maxPendingWriteInserts = 2
+
+	require.NoError(t, q.Start())
+	defer func() {
+		require.NoError(t, q.Stop())
+	}()
+
+	writeInsert := dbShardInsert{
+		opts: dbShardInsertAsyncOptions{hasPendingWrite: true},
+	}
+
+	_, err := q.Insert(writeInsert)
+	require.NoError(t, err)
+	_, err = q.Insert(writeInsert)
+	require.NoError(t, err)
+
+	// Further new series writes are rejected with a retryable error.
+	_, err = q.Insert(writeInsert)
+	require.Error(t, err)
+	require.Equal(t, errNewSeriesInsertMaxPendingExceeded, err)
+	require.True(t, xerrors.IsRetryableError(err))
+
+	// Inserts without a pending write are still queued.
+	wg, err := q.Insert(dbShardInsert{})
+	require.NoError(t, err)
+
+	close(unblock)
+	wg.Wait()
+
+	// Once the pending inserts are drained writes are accepted again.
+	_, err = q.Insert(writeInsert)
+	require.NoError(t, err)
+}
+
 func TestShardInsertQueueFlushedOnClose(t *testing.T) {
 	defer leaktest.CheckTimeout(t, 5*time.Second)()
 
@@ -203,7 +267,7 @@ func TestShardInsertQueueFlushedOnClose(t *testing.T) {
 	q := newDatabaseShardInsertQueue(func(value []dbShardInsert) error {
 		atomic.AddInt64(&numInsertObserved, int64(len(value)))
 		return nil
-	}, func() time.Time { return currTime }, tally.NoopScope)
+	}, func() time.Time { return currTime }, 0, tally.NoopScope)
 
 	require.NoError(t, q.Start())
 
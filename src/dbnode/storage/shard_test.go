@@ -40,6 +40,7 @@ import (
 	"github.com/m3db/m3/src/dbnode/runtime"
 	"github.com/m3db/m3/src/dbnode/storage/block"
 	"github.com/m3db/m3/src/dbnode/storage/bootstrap/result"
+	dberrors "github.com/m3db/m3/src/dbnode/storage/errors"
 	"github.com/m3db/m3/src/dbnode/storage/series"
 	"github.com/m3db/m3/src/dbnode/storage/series/lookup"
 	"github.com/m3db/m3/src/dbnode/ts"
@@ -756,7 +757,7 @@ func TestShardSnapshotShardNotBootstrapped(t *testing.T) {
 	s.bootstrapState = Bootstrapping
 
 	snapshotPreparer := persist.NewMockSnapshotPreparer(ctrl)
-	err := s.Snapshot(blockStart, blockStart, snapshotPreparer, namespace.Context{})
+	_, err := s.Snapshot(blockStart, blockStart, snapshotPreparer, namespace.Context{})
 	require.Equal(t, errShardNotBootstrappedToSnapshot, err)
 }
 
@@ -803,7 +804,7 @@ func TestShardSnapshotSeriesSnapshotSuccess(t *testing.T) {
 		s.list.PushBack(lookup.NewEntry(series, 0))
 	}
 
-	err := s.Snapshot(blockStart, blockStart, snapshotPreparer, namespace.Context{})
+	_, err := s.Snapshot(blockStart, blockStart, snapshotPreparer, namespace.Context{})
 
 	require.Equal(t, len(snapshotted), 2)
 	for i := 0; i < 2; i++ {
@@ -815,6 +816,72 @@ func TestShardSnapshotSeriesSnapshotSuccess(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestShardSnapshotClearsDirty(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		blockSize     = 2 * time.Hour
+		blockStart    = time.Unix(21600, 0)
+		snapshotTime  = blockStart.Add(blockSize)
+		nextBlockTime = blockStart.Add(blockSize)
+	)
+
+	s := testDatabaseShard(t, DefaultTestOptions())
+	defer s.Close()
+	s.bootstrapState = Bootstrapped
+	require.False(t, s.IsDirty())
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	id := ident.StringID("foo")
+	mockSeries := addMockTestSeries(ctrl, s, id)
+	mockSeries.EXPECT().Tags().Return(ident.Tags{}).AnyTimes()
+	mockSeries.EXPECT().IsEmpty().Return(false).AnyTimes()
+	mockSeries.EXPECT().
+		Write(gomock.Any(), gomock.Any(), 1.0, xtime.Second, nil, gomock.Any()).
+		Return(true, nil).
+		Times(2)
+	mockSeries.EXPECT().
+		Snapshot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+
+	_, _, err := s.Write(ctx, id, blockStart, 1.0, xtime.Second, nil,
+		series.WriteOptions{})
+	require.NoError(t, err)
+	require.True(t, s.IsDirty())
+
+	snapshotPreparer := persist.NewMockSnapshotPreparer(ctrl)
+	snapshotPreparer.EXPECT().PrepareData(gomock.Any()).Return(persist.PreparedDataPersist{
+		Persist: func(ident.ID, ident.Tags, ts.Segment, uint32) error { return nil },
+		Close:   func() error { return nil },
+	}, nil).Times(2)
+
+	// The first snapshot for a snapshot time clears the dirty flag.
+	_, err = s.Snapshot(blockStart, snapshotTime, snapshotPreparer, namespace.Context{})
+	require.NoError(t, err)
+	require.False(t, s.IsDirty())
+
+	// Writes after the snapshot began mark the shard dirty again, and are not
+	// cleared by snapshots of other block starts for the same snapshot time.
+	_, _, err = s.Write(ctx, id, blockStart, 1.0, xtime.Second, nil,
+		series.WriteOptions{})
+	require.NoError(t, err)
+	_, err = s.Snapshot(nextBlockTime, snapshotTime, snapshotPreparer, namespace.Context{})
+	require.NoError(t, err)
+	require.True(t, s.IsDirty())
+
+	// Failed snapshots leave the shard dirty.
+	snapshotPreparer.EXPECT().PrepareData(gomock.Any()).
+		Return(persist.PreparedDataPersist{}, errors.New("prepare failed"))
+	_, err = s.Snapshot(blockStart, snapshotTime.Add(blockSize), snapshotPreparer,
+		namespace.Context{})
+	require.Error(t, err)
+	require.True(t, s.IsDirty())
+}
+
 func addMockTestSeries(ctrl *gomock.Controller, shard *dbShard, id ident.ID) *series.MockDatabaseSeries {
 	series := series.NewMockDatabaseSeries(ctrl)
 	series.EXPECT().ID().AnyTimes().Return(id)
@@ -866,6 +933,62 @@ func writeShardAndVerify(
 	assert.Equal(t, expectedIdx, series.UniqueIndex)
 }
 
+func TestShardTickConsistencyCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scope := tally.NewTestScope("", nil)
+	opts := DefaultTestOptions().SetConsistencyCheckSampleRate(1)
+	opts = opts.SetInstrumentOptions(opts.InstrumentOptions().SetMetricsScope(scope))
+	shard := testDatabaseShard(t, opts)
+	defer shard.Close()
+
+	var overlap series.OptimizedTimes
+	overlap.Add(xtime.ToUnixNano(time.Now().Truncate(time.Hour)))
+	for i, result := range []series.OptimizedTimes{{}, overlap} {
+		s := addMockSeries(ctrl, shard, ident.StringID(fmt.Sprintf("foo.%d", i)), ident.Tags{}, uint64(i))
+		s.EXPECT().Tick(gomock.Any(), gomock.Any()).Return(series.TickResult{}, nil)
+		s.EXPECT().EstimatedMemoryBytes().Return(int64(0))
+		s.EXPECT().CachedBlockBufferOverlap().Return(result)
+	}
+
+	_, err := shard.tickAndExpire(context.NewNoOpCanncellable(), tickPolicyRegular, namespace.Context{})
+	require.NoError(t, err)
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(2), counters["dbshard.consistency-checked-series+"].Value())
+	require.Equal(t, int64(1), counters["dbshard.cached-block-buffer-overlaps+"].Value())
+}
+
+func TestShardTickIdleSeriesEvicted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scope := tally.NewTestScope("", nil)
+	opts := DefaultTestOptions()
+	opts = opts.SetInstrumentOptions(opts.InstrumentOptions().SetMetricsScope(scope))
+	shard := testDatabaseShard(t, opts)
+	defer shard.Close()
+
+	s := series.NewMockDatabaseSeries(ctrl)
+	s.EXPECT().ID().Return(ident.StringID("foo")).AnyTimes()
+	s.EXPECT().Tick(gomock.Any(), gomock.Any()).Return(series.TickResult{}, series.ErrSeriesIdle)
+	s.EXPECT().IsEmpty().Return(true)
+	s.EXPECT().Close()
+	shard.Lock()
+	shard.insertNewShardEntryWithLock(lookup.NewEntry(s, 0))
+	shard.Unlock()
+
+	r, err := shard.tickAndExpire(context.NewNoOpCanncellable(), tickPolicyRegular, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 0, r.activeSeries)
+	require.Equal(t, 0, r.expiredSeries)
+	require.Equal(t, 0, shard.lookup.Len())
+
+	counters := scope.Snapshot().Counters()
+	require.Equal(t, int64(1), counters["dbshard.series-idle-evicted+"].Value())
+}
+
 func TestShardTick(t *testing.T) {
 	dir, err := ioutil.TempDir("", "testdir")
 	require.NoError(t, err)
@@ -1196,6 +1319,8 @@ func TestShardReturnsErrorForConcurrentTicks(t *testing.T) {
 	tick2Wg.Add(1)
 	closeWg.Add(2)
 
+	foo.EXPECT().EstimatedMemoryBytes().Return(int64(0)).AnyTimes()
+
 	// wait to return the other tick has returned error
 	foo.EXPECT().Tick(gomock.Any(), gomock.Any()).Do(func(interface{}, interface{}) {
 		tick1Wg.Done()
@@ -1256,6 +1381,7 @@ func TestShardTicksStopWhenClosing(t *testing.T) {
 	)
 
 	orderWg.Add(1)
+	foo.EXPECT().EstimatedMemoryBytes().Return(int64(0)).AnyTimes()
 	gomock.InOrder(
 		// loop until the shard is marked for Closing
 		foo.EXPECT().Tick(gomock.Any(), gomock.Any()).Do(func(interface{}, interface{}) {
@@ -1324,6 +1450,154 @@ func TestPurgeExpiredSeriesNonEmptySeries(t *testing.T) {
 	require.Equal(t, 0, r.expiredSeries)
 }
 
+func TestShardEvictIdleCachedBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := DefaultTestOptions()
+	shard := testDatabaseShard(t, opts)
+	defer shard.Close()
+
+	idleFor := time.Minute
+	for i, id := range []string{"foo", "bar"} {
+		s := addMockSeries(ctrl, shard, ident.StringID(id), ident.Tags{}, uint64(i))
+		s.EXPECT().EvictCachedBlocks(gomock.Any(), idleFor).Return(i + 1)
+	}
+
+	require.Equal(t, 3, shard.EvictIdleCachedBlocks(idleFor))
+}
+
+func TestShardSubscribeWrites(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := DefaultTestOptions()
+	shard := testDatabaseShard(t, opts)
+	defer shard.Close()
+
+	id := ident.StringID("foo")
+	s := addMockSeries(ctrl, shard, id, ident.Tags{}, 0)
+	s.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).Times(3)
+
+	sub := shard.SubscribeWrites(id, 1)
+
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+	now := opts.ClockOptions().NowFn()()
+	_, _, err := shard.Write(ctx, id, now, 1.0, xtime.Second, nil, series.WriteOptions{})
+	require.NoError(t, err)
+
+	// The subscription buffer is full so the second write is dropped
+	// rather than blocking the write.
+	_, _, err = shard.Write(ctx, id, now.Add(time.Second), 2.0, xtime.Second, nil, series.WriteOptions{})
+	require.NoError(t, err)
+
+	dp := <-sub.C()
+	require.True(t, dp.Equal(ts.Datapoint{Timestamp: now, Value: 1.0}))
+
+	sub.Close()
+	_, ok := <-sub.C()
+	require.False(t, ok)
+
+	// Writes after the subscription is closed must not be delivered.
+	_, _, err = shard.Write(ctx, id, now.Add(2*time.Second), 3.0, xtime.Second, nil, series.WriteOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int64(0), shard.writeSubscriptions.numSubscriptions)
+}
+
+func TestShardStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := DefaultTestOptions()
+	shard := testDatabaseShard(t, opts)
+	shard.Bootstrap(nil)
+	shard.lastTickStatus = series.TickStatus{
+		WiredBlocks:        4,
+		PendingMergeBlocks: 1,
+	}
+
+	blockSize := opts.SeriesOptions().RetentionOptions().BlockSize()
+	t0 := opts.ClockOptions().NowFn()().Truncate(blockSize).Add(-2 * blockSize)
+	seriesStats := []struct {
+		bootstrapState series.BootstrapState
+		activeBlocks   int
+		memoryBytes    int64
+		coldWrites     []time.Time
+	}{
+		{
+			bootstrapState: series.BlockStateBootstrapped,
+			activeBlocks:   2,
+			memoryBytes:    100,
+			coldWrites:     []time.Time{t0},
+		},
+		{
+			bootstrapState: series.SeriesBootstrapped,
+			activeBlocks:   3,
+			memoryBytes:    200,
+			coldWrites:     []time.Time{t0, t0.Add(blockSize)},
+		},
+		{
+			bootstrapState: series.BootstrapNotStarted,
+		},
+	}
+	for _, ss := range seriesStats {
+		curr := series.NewMockDatabaseSeries(ctrl)
+		curr.EXPECT().BootstrapState().Return(ss.bootstrapState)
+		curr.EXPECT().NumActiveBlocks().Return(ss.activeBlocks)
+		curr.EXPECT().EstimatedMemoryBytes().Return(ss.memoryBytes)
+		curr.EXPECT().ColdFlushBlockStarts(gomock.Any()).
+			Return(optimizedTimesFromTimes(ss.coldWrites))
+		shard.list.PushBack(lookup.NewEntry(curr, 0))
+	}
+
+	require.Equal(t, ShardStats{
+		Shard:                               shard.ID(),
+		NumSeries:                           3,
+		NumSeriesNotBootstrapped:            1,
+		NumSeriesBlockStatesNotBootstrapped: 1,
+		ActiveBlocks:                        5,
+		WiredBlocks:                         4,
+		PendingMergeBlocks:                  1,
+		PendingColdWriteBlocks:              3,
+		EstimatedMemoryBytes:                300,
+	}, shard.Stats())
+}
+
+func TestShardApproxMemoryBySeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	shard := testDatabaseShard(t, DefaultTestOptions())
+	shard.Bootstrap(nil)
+
+	memoryBytes := map[string]int64{
+		"foo": 300,
+		"bar": 100,
+		"baz": 500,
+		"qux": 200,
+	}
+	for id, bytes := range memoryBytes {
+		curr := series.NewMockDatabaseSeries(ctrl)
+		curr.EXPECT().ID().Return(ident.StringID(id)).AnyTimes()
+		curr.EXPECT().EstimatedMemoryBytes().Return(bytes)
+		shard.list.PushBack(lookup.NewEntry(curr, 0))
+	}
+
+	result := shard.ApproxMemoryBySeries(2)
+	require.Equal(t, 2, len(result))
+	require.Equal(t, "baz", result[0].ID.String())
+	require.Equal(t, int64(500), result[0].EstimatedMemoryBytes)
+	require.Equal(t, "foo", result[1].ID.String())
+	require.Equal(t, int64(300), result[1].EstimatedMemoryBytes)
+	for _, r := range result {
+		require.Equal(t, shard.ID(), r.Shard)
+	}
+
+	require.Equal(t, 0, len(shard.ApproxMemoryBySeries(0)))
+}
+
 // This tests the scenario where a series is empty when series.Tick() is called,
 // but receives writes after tickForEachSeries finishes but before purgeExpiredSeries
 // starts. The expected behavior is not to expire series in this case.
@@ -1614,6 +1888,57 @@ func TestShardReadEncodedCachesSeriesWithRecentlyReadPolicy(t *testing.T) {
 	assert.Equal(t, 2, entry.Series.NumActiveBlocks())
 }
 
+func TestShardReadEncodedMaxReadBlockReadersExceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := DefaultTestOptions().
+		SetSeriesCachePolicy(series.CacheRecentlyRead)
+
+	shard := testDatabaseShard(t, opts)
+	defer shard.Close()
+	require.NoError(t, shard.Bootstrap(nil))
+
+	ropts := shard.seriesOpts.RetentionOptions()
+	end := opts.ClockOptions().NowFn()().Truncate(ropts.BlockSize())
+	start := end.Add(-3 * ropts.BlockSize())
+	mid := start.Add(ropts.BlockSize())
+	last := mid.Add(ropts.BlockSize())
+	shard.markWarmFlushStateSuccess(start)
+	shard.markWarmFlushStateSuccess(mid)
+	shard.markWarmFlushStateSuccess(last)
+
+	retriever := block.NewMockDatabaseBlockRetriever(ctrl)
+	shard.setBlockRetriever(retriever)
+
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+
+	// The last block is only streamed by the read within the limit since
+	// the read exceeding the limit stops as soon as the limit is exceeded.
+	for at, times := range map[time.Time]int{start: 2, mid: 2, last: 1} {
+		retriever.EXPECT().
+			Stream(ctx, shard.shard, ident.NewIDMatcher("foo"),
+				at, shard.seriesOnRetrieveBlock, gomock.Any()).
+			Return(xio.BlockReader{
+				SegmentReader: xio.NewMockSegmentReader(ctrl),
+			}, nil).
+			Times(times)
+	}
+
+	// Check reads within the limit succeed
+	shard.SetRuntimeOptions(runtime.NewOptions().SetMaxReadBlockReaders(3))
+	r, err := shard.ReadEncoded(ctx, ident.StringID("foo"), start, end, namespace.Context{})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(r))
+
+	// Check reads exceeding the limit are rejected
+	shard.SetRuntimeOptions(runtime.NewOptions().SetMaxReadBlockReaders(1))
+	_, err = shard.ReadEncoded(ctx, ident.StringID("foo"), start, end, namespace.Context{})
+	require.Error(t, err)
+	require.True(t, dberrors.IsQueryTooLargeError(err))
+}
+
 func TestShardNewInvalidShardEntry(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
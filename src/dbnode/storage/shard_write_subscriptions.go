@@ -0,0 +1,131 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/x/ident"
+
+	"github.com/uber-go/tally"
+)
+
+const defaultWriteSubscriptionBufferSize = 1024
+
+// shardWriteSubscriptions tracks the subscribers to the datapoints written
+// to series in a shard, subscribers that do not keep up have datapoints
+// dropped so that they never block writes.
+type shardWriteSubscriptions struct {
+	sync.RWMutex
+
+	// numSubscriptions is accessed atomically so that writes can skip
+	// taking the lock when there are no subscriptions.
+	numSubscriptions int64
+	byID             map[string][]*shardWriteSubscription
+	dropped          tally.Counter
+}
+
+func newShardWriteSubscriptions(scope tally.Scope) *shardWriteSubscriptions {
+	return &shardWriteSubscriptions{
+		byID:    make(map[string][]*shardWriteSubscription),
+		dropped: scope.Counter("write-subscription-dropped"),
+	}
+}
+
+func (s *shardWriteSubscriptions) subscribe(
+	id ident.ID,
+	bufferSize int,
+) WriteSubscription {
+	if bufferSize <= 0 {
+		bufferSize = defaultWriteSubscriptionBufferSize
+	}
+	sub := &shardWriteSubscription{
+		subscriptions: s,
+		id:            id.String(),
+		ch:            make(chan ts.Datapoint, bufferSize),
+	}
+
+	s.Lock()
+	s.byID[sub.id] = append(s.byID[sub.id], sub)
+	atomic.AddInt64(&s.numSubscriptions, 1)
+	s.Unlock()
+	return sub
+}
+
+func (s *shardWriteSubscriptions) publish(id ident.ID, dp ts.Datapoint) {
+	if atomic.LoadInt64(&s.numSubscriptions) == 0 {
+		return
+	}
+
+	s.RLock()
+	for _, sub := range s.byID[string(id.Bytes())] {
+		select {
+		case sub.ch <- dp:
+		default:
+			s.dropped.Inc(1)
+		}
+	}
+	s.RUnlock()
+}
+
+func (s *shardWriteSubscriptions) remove(sub *shardWriteSubscription) {
+	s.Lock()
+	defer s.Unlock()
+
+	subs := s.byID[sub.id]
+	for i, existing := range subs {
+		if existing != sub {
+			continue
+		}
+		subs[i] = subs[len(subs)-1]
+		subs[len(subs)-1] = nil
+		subs = subs[:len(subs)-1]
+		if len(subs) == 0 {
+			delete(s.byID, sub.id)
+		} else {
+			s.byID[sub.id] = subs
+		}
+		atomic.AddInt64(&s.numSubscriptions, -1)
+		// Close the channel while holding the lock so that no
+		// concurrent publish can send on it after it is closed.
+		close(sub.ch)
+		return
+	}
+}
+
+type shardWriteSubscription struct {
+	subscriptions *shardWriteSubscriptions
+	id            string
+	ch            chan ts.Datapoint
+	closeOnce     sync.Once
+}
+
+func (s *shardWriteSubscription) C() <-chan ts.Datapoint {
+	return s.ch
+}
+
+func (s *shardWriteSubscription) Close() {
+	s.closeOnce.Do(func() {
+		s.subscriptions.remove(s)
+	})
+}
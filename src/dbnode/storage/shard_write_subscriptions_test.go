@@ -0,0 +1,70 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3/src/dbnode/ts"
+	"github.com/m3db/m3/src/x/ident"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func TestShardWriteSubscriptionsPublish(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	subs := newShardWriteSubscriptions(scope)
+
+	var (
+		foo  = ident.StringID("foo")
+		bar  = ident.StringID("bar")
+		now  = time.Now()
+		dp   = ts.Datapoint{Timestamp: now, Value: 1}
+		sub1 = subs.subscribe(foo, 1)
+		sub2 = subs.subscribe(foo, 2)
+		sub3 = subs.subscribe(bar, 1)
+	)
+	defer sub3.Close()
+
+	subs.publish(foo, dp)
+	subs.publish(foo, dp)
+
+	// The first subscription only has room for a single datapoint.
+	require.Len(t, sub1.C(), 1)
+	require.Len(t, sub2.C(), 2)
+	require.Len(t, sub3.C(), 0)
+	counter, ok := scope.Snapshot().Counters()["write-subscription-dropped+"]
+	require.True(t, ok)
+	require.Equal(t, int64(1), counter.Value())
+
+	sub1.Close()
+	// Closing twice must be safe.
+	sub1.Close()
+	subs.publish(foo, dp)
+	require.Len(t, sub2.C(), 2)
+	require.Equal(t, int64(2), subs.numSubscriptions)
+
+	sub2.Close()
+	_, ok = subs.byID["foo"]
+	require.False(t, ok)
+}
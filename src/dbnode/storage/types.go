@@ -164,6 +164,18 @@ type Database interface {
 		start, end time.Time,
 	) ([][]xio.BlockReader, error)
 
+	// ReadTransformed reads the datapoints for an ID within [start, end) and
+	// applies the named read transforms, registered in the options, in order.
+	// Every datapoint must be decoded so this is considerably more expensive
+	// than ReadEncoded.
+	ReadTransformed(
+		ctx context.Context,
+		namespace ident.ID,
+		id ident.ID,
+		start, end time.Time,
+		transforms []string,
+	) ([]ts.Datapoint, error)
+
 	// FetchBlocks retrieves data blocks for a given id and a list of block
 	// start times.
 	FetchBlocks(
@@ -198,12 +210,32 @@ type Database interface {
 	// the local disk.
 	IsBootstrappedAndDurable() bool
 
+	// FailedBootstrapShards returns the shards that are not bootstrapped,
+	// keyed by the namespaces that failed to bootstrap and are being retried
+	// in the background, or keyed by "*" if the whole node failed to bootstrap.
+	FailedBootstrapShards() map[string][]uint32
+
+	// NamespacesBootstrapped returns whether each namespace has bootstrapped
+	// all of its shards, keyed by namespace.
+	NamespacesBootstrapped() map[string]bool
+
 	// IsOverloaded determines whether the database is overloaded.
 	IsOverloaded() bool
 
 	// Repair will issue a repair and return nil on success or error on error.
 	Repair() error
 
+	// Quiesce pauses background processes such as ticks, flushes, snapshots,
+	// cleanups and repairs while reads and writes continue to be served from
+	// memory. It returns once any in-progress file operations have completed.
+	Quiesce() error
+
+	// Unquiesce resumes background processes paused by Quiesce.
+	Unquiesce() error
+
+	// IsQuiesced determines whether the database is quiesced.
+	IsQuiesced() bool
+
 	// Truncate truncates data for the given namespace.
 	Truncate(namespace ident.ID) (int64, error)
 
@@ -213,6 +245,89 @@ type Database interface {
 
 	// FlushState returns the flush state for the specified shard and block start.
 	FlushState(namespace ident.ID, shardID uint32, blockStart time.Time) (fileOpState, error)
+
+	// SeriesBufferDebugInfo returns the buffer bucket versions of a series
+	// for each block start, it is intended for debugging cold flushes only.
+	SeriesBufferDebugInfo(
+		namespace ident.ID,
+		id ident.ID,
+	) ([]series.BufferBlockDebugInfo, error)
+
+	// SubscribeWrites subscribes to the datapoints written to a series from
+	// now on, it is intended for debugging and datapoints are dropped if the
+	// subscriber does not keep up with a buffer of the given size.
+	SubscribeWrites(
+		namespace ident.ID,
+		id ident.ID,
+		bufferSize int,
+	) (WriteSubscription, error)
+
+	// ShardStats returns an on demand snapshot of the stats of each shard
+	// of the namespace owned by this node.
+	ShardStats(namespace ident.ID) ([]ShardStats, error)
+
+	// ApproxMemoryBySeries returns the n series of the namespace with the
+	// largest estimated memory footprint, in descending order of footprint.
+	ApproxMemoryBySeries(namespace ident.ID, n int) ([]SeriesMemory, error)
+
+	// ForceSnapshot snapshots the unflushed data of a block start of the
+	// namespace on demand, returning the number of series snapshotted. The
+	// snapshot is partial so it is not used to clean up commit logs.
+	ForceSnapshot(namespace ident.ID, blockStart time.Time) (int, error)
+}
+
+// WriteSubscription is a subscription to the datapoints written to a series.
+type WriteSubscription interface {
+	// C returns the channel newly written datapoints are delivered on, the
+	// channel is closed when the subscription is closed.
+	C() <-chan ts.Datapoint
+
+	// Close removes the subscription.
+	Close()
+}
+
+// ShardStats is a snapshot of the stats of a shard aggregated from its series.
+type ShardStats struct {
+	// Shard is the ID of the shard.
+	Shard uint32
+	// NumSeries is the number of series in the shard.
+	NumSeries int64
+	// NumSeriesNotBootstrapped is the number of series in the shard that
+	// are not yet bootstrapped.
+	NumSeriesNotBootstrapped int64
+	// NumSeriesBlockStatesNotBootstrapped is the number of bootstrapped
+	// series in the shard that are yet to be ticked with bootstrapped block
+	// states, decisions such as evicting their blocks are not yet made.
+	NumSeriesBlockStatesNotBootstrapped int64
+	// ActiveBlocks is the number of blocks held in memory by the series.
+	ActiveBlocks int
+	// WiredBlocks is the number of blocks wired in memory as of the last tick.
+	WiredBlocks int
+	// PendingMergeBlocks is the number of blocks pending merges as of the
+	// last tick.
+	PendingMergeBlocks int
+	// PendingColdWriteBlocks is the number of series block starts with cold
+	// writes that have not been cold flushed yet.
+	PendingColdWriteBlocks int
+	// EstimatedMemoryBytes is an estimate of the memory held by the series.
+	EstimatedMemoryBytes int64
+}
+
+// SnapshotResult is the result of snapshotting a shard or namespace.
+type SnapshotResult struct {
+	// SeriesPersist is the number of series whose data was persisted to
+	// the snapshot.
+	SeriesPersist int
+}
+
+// SeriesMemory is the estimated memory footprint of a series.
+type SeriesMemory struct {
+	// Shard is the ID of the shard owning the series.
+	Shard uint32
+	// ID is the ID of the series.
+	ID ident.ID
+	// EstimatedMemoryBytes is an estimate of the memory held by the series.
+	EstimatedMemoryBytes int64
 }
 
 // database is the internal database interface
@@ -353,7 +468,10 @@ type databaseNamespace interface {
 	) error
 
 	// Snapshot snapshots unflushed in-memory WarmWrites.
-	Snapshot(blockStart, snapshotTime time.Time, flush persist.SnapshotPreparer) error
+	Snapshot(
+		blockStart, snapshotTime time.Time,
+		flush persist.SnapshotPreparer,
+	) (SnapshotResult, error)
 
 	// NeedsFlush returns true if the namespace needs a flush for the
 	// period: [start, end] (both inclusive).
@@ -372,6 +490,20 @@ type databaseNamespace interface {
 
 	// FlushState returns the flush state for the specified shard and block start.
 	FlushState(shardID uint32, blockStart time.Time) (fileOpState, error)
+
+	// SeriesBufferDebugInfo returns the buffer bucket versions of a series
+	// for each block start.
+	SeriesBufferDebugInfo(id ident.ID) ([]series.BufferBlockDebugInfo, error)
+
+	// SubscribeWrites subscribes to the datapoints written to a series.
+	SubscribeWrites(id ident.ID, bufferSize int) (WriteSubscription, error)
+
+	// ShardStats returns a snapshot of the stats of each owned shard.
+	ShardStats() []ShardStats
+
+	// ApproxMemoryBySeries returns the n series of the owned shards with
+	// the largest estimated memory footprint, in descending order.
+	ApproxMemoryBySeries(n int) []SeriesMemory
 }
 
 // Shard is a time series database shard.
@@ -490,11 +622,35 @@ type databaseShard interface {
 		snapshotStart time.Time,
 		flush persist.SnapshotPreparer,
 		nsCtx namespace.Context,
-	) error
+	) (SnapshotResult, error)
+
+	// IsDirty returns whether the shard has been written to since the
+	// last snapshot of the shard began, it is used only for reporting.
+	IsDirty() bool
 
 	// FlushState returns the flush state for this shard at block start.
 	FlushState(blockStart time.Time) (fileOpState, error)
 
+	// SeriesBufferDebugInfo returns the buffer bucket versions of a series
+	// for each block start.
+	SeriesBufferDebugInfo(id ident.ID) ([]series.BufferBlockDebugInfo, error)
+
+	// SubscribeWrites subscribes to the datapoints written to a series.
+	SubscribeWrites(id ident.ID, bufferSize int) WriteSubscription
+
+	// Stats returns a snapshot of the stats of the shard aggregated from
+	// its series.
+	Stats() ShardStats
+
+	// ApproxMemoryBySeries returns the n series of the shard with the
+	// largest estimated memory footprint, in descending order.
+	ApproxMemoryBySeries(n int) []SeriesMemory
+
+	// EvictIdleCachedBlocks evicts the flushed cached blocks of every series
+	// in the shard that have not been read within idleFor to reclaim memory,
+	// returning the number of blocks evicted.
+	EvictIdleCachedBlocks(idleFor time.Duration) int
+
 	// CleanupExpiredFileSets removes expired fileset files.
 	CleanupExpiredFileSets(earliestToRetain time.Time) error
 
@@ -610,8 +766,16 @@ type databaseBootstrapManager interface {
 	// Bootstrap performs bootstrapping for all namespaces and shards owned.
 	Bootstrap() error
 
+	// FailedBootstrapShards returns the shards that are not bootstrapped,
+	// keyed by the namespaces that failed to bootstrap.
+	FailedBootstrapShards() map[string][]uint32
+
 	// Report reports runtime information.
 	Report()
+
+	// Close stops any background bootstrap retries and aborts an in flight
+	// bootstrap before it bootstraps the next namespace.
+	Close()
 }
 
 // databaseFlushManager manages flushing in-memory data to persistent storage.
@@ -723,6 +887,10 @@ type databaseMediator interface {
 	// Bootstrap bootstraps the database with file operations performed at the end.
 	Bootstrap() error
 
+	// FailedBootstrapShards returns the shards that are not bootstrapped,
+	// keyed by the namespaces that failed to bootstrap.
+	FailedBootstrapShards() map[string][]uint32
+
 	// DisableFileOps disables file operations.
 	DisableFileOps()
 
@@ -735,6 +903,15 @@ type databaseMediator interface {
 	// Repair repairs the database.
 	Repair() error
 
+	// Quiesce pauses ticks, file operations and repairs.
+	Quiesce() error
+
+	// Unquiesce resumes ticks, file operations and repairs.
+	Unquiesce() error
+
+	// IsQuiesced returns whether the mediator is quiesced.
+	IsQuiesced() bool
+
 	// Close closes the mediator.
 	Close() error
 
@@ -905,6 +1082,14 @@ type Options interface {
 	// BytesPool returns the bytesPool.
 	BytesPool() pool.CheckedBytesPool
 
+	// SetBytesPoolFactory sets the factory used to construct the bytes pool
+	// when the external pooling type is configured.
+	SetBytesPoolFactory(value BytesPoolFactory) Options
+
+	// BytesPoolFactory returns the factory used to construct the bytes pool
+	// when the external pooling type is configured.
+	BytesPoolFactory() BytesPoolFactory
+
 	// SetEncoderPool sets the contextPool.
 	SetEncoderPool(value encoding.EncoderPool) Options
 
@@ -982,8 +1167,65 @@ type Options interface {
 
 	// BlockLeaseManager returns the block leaser.
 	BlockLeaseManager() block.LeaseManager
+
+	// SetReadTransforms sets the read transforms available to
+	// ReadTransformed by name.
+	SetReadTransforms(value map[string]NewReadTransformFn) Options
+
+	// ReadTransforms returns the read transforms available to
+	// ReadTransformed by name.
+	ReadTransforms() map[string]NewReadTransformFn
+
+	// SetMaxNamespaceRetentionPeriod sets the maximum retention period a
+	// namespace may be created with, namespaces exceeding it are rejected.
+	// Zero means no maximum.
+	SetMaxNamespaceRetentionPeriod(value time.Duration) Options
+
+	// MaxNamespaceRetentionPeriod returns the maximum retention period a
+	// namespace may be created with.
+	MaxNamespaceRetentionPeriod() time.Duration
+
+	// SetConsistencyCheckSampleRate sets the fraction of series checked
+	// during each shard tick for block starts present in both the cached
+	// blocks and the buffer, zero disables the check.
+	SetConsistencyCheckSampleRate(value float64) Options
+
+	// ConsistencyCheckSampleRate returns the fraction of series checked
+	// during each shard tick for overlapping cached blocks and buffer.
+	ConsistencyCheckSampleRate() float64
+
+	// SetBootstrapFailureRetryInterval sets the interval at which namespaces
+	// and shards that failed to bootstrap are retried in the background, when
+	// set bootstrap failures are tracked rather than returned so that the
+	// successfully bootstrapped namespaces and shards are served, zero returns
+	// bootstrap failures.
+	SetBootstrapFailureRetryInterval(value time.Duration) Options
+
+	// BootstrapFailureRetryInterval returns the interval at which namespaces
+	// and shards that failed to bootstrap are retried in the background, zero
+	// returns bootstrap failures.
+	BootstrapFailureRetryInterval() time.Duration
+
+	// SetBootstrapConcurrency sets the number of namespaces that are
+	// bootstrapped concurrently.
+	SetBootstrapConcurrency(value int) Options
+
+	// BootstrapConcurrency returns the number of namespaces that are
+	// bootstrapped concurrently.
+	BootstrapConcurrency() int
+}
+
+// ReadTransform transforms the datapoints of a series read in time order,
+// a new instance is created for every read so it may hold state.
+type ReadTransform interface {
+	// Apply transforms the datapoint, returning false if the datapoint
+	// should be dropped from the read.
+	Apply(dp ts.Datapoint) (ts.Datapoint, bool)
 }
 
+// NewReadTransformFn creates a new read transform.
+type NewReadTransformFn func() ReadTransform
+
 // DatabaseBootstrapState stores a snapshot of the bootstrap state for all shards across all
 // namespaces at a given moment in time.
 type DatabaseBootstrapState struct {
@@ -998,6 +1240,14 @@ type NamespaceBootstrapStates map[string]ShardBootstrapStates
 // namespace.
 type ShardBootstrapStates map[uint32]BootstrapState
 
+// BytesPoolFactory constructs a custom checked bytes pool from the configured
+// bytes pool buckets, allowing the default bytes pool implementation to be
+// replaced.
+type BytesPoolFactory func(
+	buckets []pool.Bucket,
+	opts pool.ObjectPoolOptions,
+) pool.CheckedBytesPool
+
 // BootstrapState is an enum representing the possible bootstrap states for a shard.
 type BootstrapState int
 
@@ -205,6 +205,44 @@ servers:
 	require.Equal(t, []string{"server3:8080", "server4:8080"}, cfg.Servers)
 }
 
+func TestLoadFilesMergesNestedPointers(t *testing.T) {
+	type repairConfiguration struct {
+		Enabled  bool   `yaml:"enabled"`
+		Interval string `yaml:"interval"`
+	}
+	type nestedConfiguration struct {
+		ListenAddress string               `yaml:"listen_address"`
+		Repair        *repairConfiguration `yaml:"repair"`
+	}
+
+	const baseConfig = `
+listen_address: localhost:4385
+repair:
+    enabled: false
+    interval: 2h
+`
+	base := writeFile(t, baseConfig)
+	defer os.Remove(base)
+
+	// The overlay only sets one field of the nested pointer, the other
+	// fields should be kept from the base config.
+	const overlayConfig = `
+repair:
+    enabled: true
+`
+	overlay := writeFile(t, overlayConfig)
+	defer os.Remove(overlay)
+
+	var cfg nestedConfiguration
+	err := LoadFiles(&cfg, []string{base, overlay}, Options{})
+	require.NoError(t, err)
+
+	require.Equal(t, "localhost:4385", cfg.ListenAddress)
+	require.NotNil(t, cfg.Repair)
+	require.True(t, cfg.Repair.Enabled)
+	require.Equal(t, "2h", cfg.Repair.Interval)
+}
+
 func TestLoadFilesValidateOnce(t *testing.T) {
 	const invalidConfig1 = `
     listen_address:
@@ -317,7 +317,10 @@ func (c *ctx) spanIsSampled(sp opentracing.Span) bool {
 	return true
 }
 
-func (c *ctx) StartSampledTraceSpan(name string) (Context, opentracing.Span, bool) {
+func (c *ctx) StartSampledTraceSpan(
+	name string,
+	opts ...opentracing.StartSpanOption,
+) (Context, opentracing.Span, bool) {
 	goCtx, exists := c.GoContext()
 	if !exists || c.checkedAndNotSampled {
 		return c, noopTracer.StartSpan(name), false
@@ -330,7 +333,7 @@ func (c *ctx) StartSampledTraceSpan(name string) (Context, opentracing.Span, boo
 
 	sp = opentracing.SpanFromContext(goCtx)
 	if sp == nil {
-		sp, spCtx = xopentracing.StartSpanFromContext(goCtx, name)
+		sp, spCtx = xopentracing.StartSpanFromContext(goCtx, name, opts...)
 		if c.spanIsSampled(sp) {
 			child := c.newChildContext()
 			child.SetGoContext(spCtx)
@@ -340,13 +343,16 @@ func (c *ctx) StartSampledTraceSpan(name string) (Context, opentracing.Span, boo
 		return c, noopTracer.StartSpan(name), false
 	}
 
-	sp, spCtx = xopentracing.StartSpanFromContext(goCtx, name)
+	sp, spCtx = xopentracing.StartSpanFromContext(goCtx, name, opts...)
 	child := c.newChildContext()
 	child.SetGoContext(spCtx)
 	return child, sp, true
 }
 
-func (c *ctx) StartTraceSpan(name string) (Context, opentracing.Span) {
-	ctx, sp, _ := c.StartSampledTraceSpan(name)
+func (c *ctx) StartTraceSpan(
+	name string,
+	opts ...opentracing.StartSpanOption,
+) (Context, opentracing.Span) {
+	ctx, sp, _ := c.StartSampledTraceSpan(name, opts...)
 	return ctx, sp
 }
@@ -68,6 +68,10 @@ type MetricsConfiguration struct {
 
 	// Metric sanitization type.
 	Sanitization *MetricSanitizationType `yaml:"sanitization"`
+
+	// DebugMetrics enables fine grained debug metrics, such as write path
+	// latency broken down by stage.
+	DebugMetrics bool `yaml:"debugMetrics"`
 }
 
 // NewRootScope creates a new tally.Scope based on a tally.CachedStatsReporter
@@ -0,0 +1,65 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package instrument
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewLevelOverrideLogger returns a logger that in addition to the entries
+// enabled by the given logger also writes any entries enabled by the
+// override level, this allows raising the verbosity of a subset of loggers
+// at runtime without changing the level of the underlying core.
+func NewLevelOverrideLogger(logger *zap.Logger, override zap.AtomicLevel) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelOverrideCore{Core: core, override: override}
+	}))
+}
+
+type levelOverrideCore struct {
+	zapcore.Core
+
+	override zap.AtomicLevel
+}
+
+func (c *levelOverrideCore) Enabled(level zapcore.Level) bool {
+	return c.Core.Enabled(level) || c.override.Enabled(level)
+}
+
+func (c *levelOverrideCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelOverrideCore{Core: c.Core.With(fields), override: c.override}
+}
+
+func (c *levelOverrideCore) Check(
+	entry zapcore.Entry,
+	checked *zapcore.CheckedEntry,
+) *zapcore.CheckedEntry {
+	if c.Core.Enabled(entry.Level) {
+		return c.Core.Check(entry, checked)
+	}
+	if c.override.Enabled(entry.Level) {
+		// Write directly to the underlying core since its own check would
+		// discard entries below its level.
+		return checked.AddCore(entry, c.Core)
+	}
+	return checked
+}
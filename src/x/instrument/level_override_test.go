@@ -0,0 +1,58 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package instrument
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLevelOverrideLogger(t *testing.T) {
+	var (
+		buf  bytes.Buffer
+		core = zapcore.NewCore(
+			zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+			zapcore.AddSync(&buf), zap.InfoLevel)
+		override = zap.NewAtomicLevelAt(zap.ErrorLevel)
+		logger   = NewLevelOverrideLogger(zap.New(core), override).
+				With(zap.String("component", "test"))
+	)
+
+	logger.Debug("not written")
+	logger.Info("written")
+	require.NotContains(t, buf.String(), "not written")
+	require.Contains(t, buf.String(), "written")
+
+	buf.Reset()
+	override.SetLevel(zap.DebugLevel)
+	logger.Debug("debug written")
+	require.Contains(t, buf.String(), "debug written")
+	require.Contains(t, buf.String(), `"component":"test"`)
+
+	buf.Reset()
+	override.SetLevel(zap.ErrorLevel)
+	logger.Debug("not written again")
+	require.Empty(t, buf.String())
+}
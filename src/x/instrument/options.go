@@ -41,6 +41,7 @@ type options struct {
 	tracer         opentracing.Tracer
 	samplingRate   float64
 	reportInterval time.Duration
+	debugMetrics   bool
 }
 
 // NewOptions creates new instrument options.
@@ -105,3 +106,13 @@ func (o *options) SetReportInterval(value time.Duration) Options {
 func (o *options) ReportInterval() time.Duration {
 	return o.reportInterval
 }
+
+func (o *options) SetDebugMetricsEnabled(value bool) Options {
+	opts := *o
+	opts.debugMetrics = value
+	return &opts
+}
+
+func (o *options) DebugMetricsEnabled() bool {
+	return o.debugMetrics
+}
@@ -69,4 +69,11 @@ type Options interface {
 
 	// GetReportInterval returns the time between reporting metrics within the system.
 	ReportInterval() time.Duration
+
+	// SetDebugMetricsEnabled sets whether fine grained debug metrics, which
+	// can be expensive to emit on hot paths, are enabled.
+	SetDebugMetricsEnabled(value bool) Options
+
+	// DebugMetricsEnabled returns whether fine grained debug metrics are enabled.
+	DebugMetricsEnabled() bool
 }
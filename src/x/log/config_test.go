@@ -27,6 +27,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func TestLoggingConfiguration(t *testing.T) {
@@ -60,3 +61,32 @@ func TestLoggingConfiguration(t *testing.T) {
 	require.True(t, strings.Contains(data, `"my-field":"my-val"`))
 	require.True(t, strings.Contains(data, `"level":"error"`))
 }
+
+func TestLoggingConfigurationWithLevel(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "logtest")
+	require.NoError(t, err)
+
+	defer tmpfile.Close()
+	defer os.Remove(tmpfile.Name())
+
+	cfg := Configuration{
+		Level: "error",
+		File:  tmpfile.Name(),
+	}
+
+	log, level, err := cfg.BuildLoggerWithLevel()
+	require.NoError(t, err)
+	require.Equal(t, zap.ErrorLevel, level.Level())
+
+	log.Info("should not appear")
+
+	level.SetLevel(zap.InfoLevel)
+	log.Info("this should appear")
+
+	b, err := ioutil.ReadAll(tmpfile)
+	require.NoError(t, err)
+
+	data := string(b)
+	require.Equal(t, 1, strings.Count(data, "\n"), data)
+	require.True(t, strings.Contains(data, `"msg":"this should appear"`))
+}
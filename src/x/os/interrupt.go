@@ -21,6 +21,7 @@
 package xos
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -34,6 +35,10 @@ type InterruptOptions struct {
 	// InterruptChannel is an existing interrupt channel, if none
 	// specified one will be created.
 	InterruptCh <-chan error
+
+	// Context is an optional context, its cancellation is treated the
+	// same as an interrupt.
+	Context context.Context
 }
 
 // WaitForInterrupt will wait for an interrupt to occur and return when done.
@@ -48,7 +53,17 @@ func WaitForInterrupt(logger *zap.Logger, opts InterruptOptions) {
 		logger.Info("using registered interrupt handler")
 	}
 
-	logger.Warn("interrupt", zap.Error(<-interruptCh))
+	var doneCh <-chan struct{}
+	if opts.Context != nil {
+		doneCh = opts.Context.Done()
+	}
+
+	select {
+	case err := <-interruptCh:
+		logger.Warn("interrupt", zap.Error(err))
+	case <-doneCh:
+		logger.Warn("interrupt", zap.Error(opts.Context.Err()))
+	}
 }
 
 // NewInterruptChannel will return an interrupt channel useful with multiple
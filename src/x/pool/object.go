@@ -22,6 +22,7 @@ package pool
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"sync/atomic"
 
@@ -32,6 +33,7 @@ var (
 	errPoolAlreadyInitialized   = errors.New("object pool already initialized")
 	errPoolGetBeforeInitialized = errors.New("object pool get before initialized")
 	errPoolPutBeforeInitialized = errors.New("object pool put before initialized")
+	errPoolRefillDisabled       = errors.New("object pool refill is disabled")
 )
 
 const (
@@ -45,7 +47,8 @@ type objectPool struct {
 	alloc               Allocator
 	size                int
 	refillLowWatermark  int
-	refillHighWatermark int
+	refillHighWatermark int64
+	checkedOut          int64
 	filling             int32
 	initialized         int32
 	dice                int32
@@ -55,6 +58,7 @@ type objectPool struct {
 type objectPoolMetrics struct {
 	free       tally.Gauge
 	total      tally.Gauge
+	checkedOut tally.Gauge
 	getOnEmpty tally.Counter
 	putOnFull  tally.Counter
 }
@@ -73,11 +77,12 @@ func NewObjectPool(opts ObjectPoolOptions) ObjectPool {
 		size:   opts.Size(),
 		refillLowWatermark: int(math.Ceil(
 			opts.RefillLowWatermark() * float64(opts.Size()))),
-		refillHighWatermark: int(math.Ceil(
+		refillHighWatermark: int64(math.Ceil(
 			opts.RefillHighWatermark() * float64(opts.Size()))),
 		metrics: objectPoolMetrics{
 			free:       m.Gauge("free"),
 			total:      m.Gauge("total"),
+			checkedOut: m.Gauge("checked-out"),
 			getOnEmpty: m.Counter("get-on-empty"),
 			putOnFull:  m.Counter("put-on-full"),
 		},
@@ -119,6 +124,7 @@ func (p *objectPool) Get() interface{} {
 		p.metrics.getOnEmpty.Inc(1)
 	}
 
+	atomic.AddInt64(&p.checkedOut, 1)
 	p.trySetGauges()
 
 	if p.refillLowWatermark > 0 && len(p.values) <= p.refillLowWatermark {
@@ -141,9 +147,24 @@ func (p *objectPool) Put(obj interface{}) {
 		p.metrics.putOnFull.Inc(1)
 	}
 
+	atomic.AddInt64(&p.checkedOut, -1)
 	p.trySetGauges()
 }
 
+func (p *objectPool) SetRefillHighWatermark(value float64) error {
+	if p.refillLowWatermark <= 0 {
+		return errPoolRefillDisabled
+	}
+	highWatermark := int64(math.Ceil(value * float64(p.size)))
+	if value > 1 || highWatermark <= int64(p.refillLowWatermark) {
+		return fmt.Errorf(
+			"refill high watermark %v must be above the low watermark and at most 1",
+			value)
+	}
+	atomic.StoreInt64(&p.refillHighWatermark, highWatermark)
+	return nil
+}
+
 func (p *objectPool) trySetGauges() {
 	if atomic.AddInt32(&p.dice, 1)%sampleObjectPoolLengthEvery == 0 {
 		p.setGauges()
@@ -153,6 +174,7 @@ func (p *objectPool) trySetGauges() {
 func (p *objectPool) setGauges() {
 	p.metrics.free.Update(float64(len(p.values)))
 	p.metrics.total.Update(float64(p.size))
+	p.metrics.checkedOut.Update(float64(atomic.LoadInt64(&p.checkedOut)))
 }
 
 func (p *objectPool) tryFill() {
@@ -163,7 +185,7 @@ func (p *objectPool) tryFill() {
 	go func() {
 		defer atomic.StoreInt32(&p.filling, 0)
 
-		for len(p.values) < p.refillHighWatermark {
+		for int64(len(p.values)) < atomic.LoadInt64(&p.refillHighWatermark) {
 			select {
 			case p.values <- p.alloc():
 			default:
@@ -24,8 +24,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3/src/x/instrument"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 )
 
 func TestObjectPoolRefillOnLowWaterMark(t *testing.T) {
@@ -62,6 +65,69 @@ func TestObjectPoolRefillOnLowWaterMark(t *testing.T) {
 	assert.Equal(t, 75, len(pool.values))
 }
 
+func TestObjectPoolSetRefillHighWatermark(t *testing.T) {
+	opts := NewObjectPoolOptions().
+		SetSize(100).
+		SetRefillLowWatermark(0.25).
+		SetRefillHighWatermark(0.5)
+
+	pool := NewObjectPool(opts).(*objectPool)
+	pool.Init(func() interface{} {
+		return 1
+	})
+
+	require.Error(t, pool.SetRefillHighWatermark(0.2))
+	require.Error(t, pool.SetRefillHighWatermark(1.5))
+	require.NoError(t, pool.SetRefillHighWatermark(0.9))
+
+	for i := 0; i < 75; i++ {
+		pool.Get()
+	}
+
+	start := time.Now()
+	for time.Since(start) < 10*time.Second {
+		if len(pool.values) == 90 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Assert refilled up to the new high watermark
+	assert.Equal(t, 90, len(pool.values))
+}
+
+func TestObjectPoolSetRefillHighWatermarkRefillDisabled(t *testing.T) {
+	pool := NewObjectPool(NewObjectPoolOptions().SetSize(100))
+	pool.Init(func() interface{} {
+		return 1
+	})
+
+	require.Equal(t, errPoolRefillDisabled, pool.SetRefillHighWatermark(0.9))
+}
+
+func TestObjectPoolCheckedOutGauge(t *testing.T) {
+	scope := tally.NewTestScope("", nil)
+	opts := NewObjectPoolOptions().
+		SetSize(10).
+		SetInstrumentOptions(instrument.NewOptions().SetMetricsScope(scope))
+
+	pool := NewObjectPool(opts).(*objectPool)
+	pool.Init(func() interface{} {
+		return 1
+	})
+
+	values := make([]interface{}, 0, 3)
+	for i := 0; i < 3; i++ {
+		values = append(values, pool.Get())
+	}
+	pool.Put(values[0])
+	pool.setGauges()
+
+	gauge, ok := scope.Snapshot().Gauges()["checked-out+"]
+	require.True(t, ok)
+	assert.Equal(t, float64(2), gauge.Value())
+}
+
 func TestObjectPoolInitTwiceError(t *testing.T) {
 	var accessErr error
 	opts := NewObjectPoolOptions().SetOnPoolAccessErrorFn(func(err error) {
@@ -0,0 +1,158 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pool
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+	"sync/atomic"
+
+	"github.com/m3db/m3/src/x/checked"
+)
+
+// maxSizeClasses is the number of power of two size classes sampled
+// capacities are recorded in.
+const maxSizeClasses = 64
+
+type sampledCheckedBytesPool struct {
+	// NB: calls is accessed atomically so is kept first for alignment.
+	calls uint64
+	every uint64
+
+	pool          CheckedBytesPool
+	buckets       []Bucket
+	samples       int64
+	unpooled      int64
+	bucketSamples []int64
+	classSamples  [maxSizeClasses]int64
+}
+
+// NewSampledCheckedBytesPool returns a checked bytes pool that delegates to
+// the given pool and samples the capacities requested at the given sample
+// rate, which must be in the range (0, 1], the buckets should be the buckets
+// the pool was constructed with.
+func NewSampledCheckedBytesPool(
+	pool CheckedBytesPool,
+	buckets []Bucket,
+	sampleRate float64,
+) SampledCheckedBytesPool {
+	sorted := make([]Bucket, len(buckets))
+	copy(sorted, buckets)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Capacity < sorted[j].Capacity
+	})
+
+	every := uint64(1)
+	if sampleRate > 0 && sampleRate < 1 {
+		every = uint64(math.Round(1 / sampleRate))
+	}
+
+	return &sampledCheckedBytesPool{
+		every:         every,
+		pool:          pool,
+		buckets:       sorted,
+		bucketSamples: make([]int64, len(sorted)),
+	}
+}
+
+func (p *sampledCheckedBytesPool) Init() {
+	p.pool.Init()
+}
+
+func (p *sampledCheckedBytesPool) Get(capacity int) checked.Bytes {
+	if atomic.AddUint64(&p.calls, 1)%p.every == 0 {
+		p.sample(capacity)
+	}
+	return p.pool.Get(capacity)
+}
+
+func (p *sampledCheckedBytesPool) BytesPool() BytesPool {
+	return p.pool.BytesPool()
+}
+
+func (p *sampledCheckedBytesPool) sample(capacity int) {
+	atomic.AddInt64(&p.samples, 1)
+	atomic.AddInt64(&p.classSamples[sizeClass(capacity)], 1)
+
+	// Buckets are chosen the same as the bucketized pool does, by the
+	// smallest bucket with a capacity large enough for the request.
+	i := sort.Search(len(p.buckets), func(i int) bool {
+		return p.buckets[i].Capacity >= capacity
+	})
+	if i == len(p.buckets) {
+		atomic.AddInt64(&p.unpooled, 1)
+		return
+	}
+	atomic.AddInt64(&p.bucketSamples[i], 1)
+}
+
+func (p *sampledCheckedBytesPool) Recommendation() BucketsRecommendation {
+	r := BucketsRecommendation{
+		Samples:  atomic.LoadInt64(&p.samples),
+		Unpooled: atomic.LoadInt64(&p.unpooled),
+		Current:  make([]BucketUsage, 0, len(p.buckets)),
+	}
+
+	totalCount := 0
+	for i, b := range p.buckets {
+		totalCount += b.Count
+		r.Current = append(r.Current, BucketUsage{
+			Capacity: b.Capacity,
+			Count:    b.Count,
+			Samples:  atomic.LoadInt64(&p.bucketSamples[i]),
+		})
+	}
+
+	var (
+		classSamples [maxSizeClasses]int64
+		totalSamples int64
+	)
+	for i := range p.classSamples {
+		classSamples[i] = atomic.LoadInt64(&p.classSamples[i])
+		totalSamples += classSamples[i]
+	}
+	if totalSamples == 0 {
+		return r
+	}
+
+	for i, n := range classSamples {
+		if n == 0 {
+			continue
+		}
+		share := float64(n) / float64(totalSamples)
+		r.Recommended = append(r.Recommended, BucketUsage{
+			Capacity: 1 << uint(i),
+			Count:    int(math.Ceil(share * float64(totalCount))),
+			Samples:  n,
+		})
+	}
+	return r
+}
+
+// sizeClass returns the index of the smallest power of two that is greater
+// than or equal to the capacity.
+func sizeClass(capacity int) int {
+	if capacity <= 1 {
+		return 0
+	}
+	return bits.Len(uint(capacity - 1))
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package pool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampledCheckedBytesPoolRecommendation(t *testing.T) {
+	buckets := []Bucket{
+		{Capacity: 16, Count: 4},
+		{Capacity: 100, Count: 4},
+	}
+	p := NewSampledCheckedBytesPool(NewCheckedBytesPool(buckets, nil, func(s []Bucket) BytesPool {
+		return NewBytesPool(s, nil)
+	}), buckets, 1)
+	p.Init()
+
+	for _, capacity := range []int{1, 10, 16, 60, 64, 128} {
+		b := p.Get(capacity)
+		require.True(t, b.Cap() >= capacity)
+	}
+
+	r := p.Recommendation()
+	assert.Equal(t, int64(6), r.Samples)
+	assert.Equal(t, int64(1), r.Unpooled)
+	assert.Equal(t, []BucketUsage{
+		{Capacity: 16, Count: 4, Samples: 3},
+		{Capacity: 100, Count: 4, Samples: 2},
+	}, r.Current)
+	assert.Equal(t, []BucketUsage{
+		{Capacity: 1, Count: 2, Samples: 1},
+		{Capacity: 16, Count: 3, Samples: 2},
+		{Capacity: 64, Count: 3, Samples: 2},
+		{Capacity: 128, Count: 2, Samples: 1},
+	}, r.Recommended)
+}
+
+func TestSampledCheckedBytesPoolSampleRate(t *testing.T) {
+	buckets := []Bucket{{Capacity: 16, Count: 4}}
+	p := NewSampledCheckedBytesPool(NewCheckedBytesPool(buckets, nil, func(s []Bucket) BytesPool {
+		return NewBytesPool(s, nil)
+	}), buckets, 0.25)
+	p.Init()
+
+	for i := 0; i < 8; i++ {
+		p.Get(8)
+	}
+
+	r := p.Recommendation()
+	assert.Equal(t, int64(2), r.Samples)
+	assert.Equal(t, []BucketUsage{{Capacity: 16, Count: 4, Samples: 2}}, r.Current)
+}
+
+func TestSizeClass(t *testing.T) {
+	for _, test := range []struct {
+		capacity int
+		expected int
+	}{
+		{capacity: 0, expected: 0},
+		{capacity: 1, expected: 0},
+		{capacity: 2, expected: 1},
+		{capacity: 3, expected: 2},
+		{capacity: 16, expected: 4},
+		{capacity: 17, expected: 5},
+	} {
+		assert.Equal(t, test.expected, sizeClass(test.capacity))
+	}
+}
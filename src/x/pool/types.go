@@ -42,6 +42,11 @@ type ObjectPool interface {
 
 	// Put returns an object to the pool.
 	Put(obj interface{})
+
+	// SetRefillHighWatermark sets the refill high watermark of the pool at
+	// runtime, it must be above the refill low watermark and at most 1 and
+	// is rejected if the pool was created with refilling disabled.
+	SetRefillHighWatermark(value float64) error
 }
 
 // CheckedObjectPool provides a checked pool for objects.
@@ -173,6 +178,49 @@ type CheckedBytesPool interface {
 	BytesPool() BytesPool
 }
 
+// SampledCheckedBytesPool is a checked bytes pool that samples the capacities
+// requested from it to recommend buckets for the pool, it does not change how
+// the pool allocates.
+type SampledCheckedBytesPool interface {
+	CheckedBytesPool
+
+	// Recommendation returns the usage of the configured buckets and the
+	// buckets recommended from the sampled requested capacities.
+	Recommendation() BucketsRecommendation
+}
+
+// BucketsRecommendation is the usage of the configured buckets of a pool and
+// the buckets recommended from the sampled requested capacities.
+type BucketsRecommendation struct {
+	// Samples is the number of requests sampled.
+	Samples int64
+
+	// Unpooled is the number of sampled requests larger than the largest
+	// configured bucket capacity which are allocated outside of the pool.
+	Unpooled int64
+
+	// Current is the configured buckets along with the number of sampled
+	// requests each bucket serves.
+	Current []BucketUsage
+
+	// Recommended is the recommended buckets, one for each power of two
+	// capacity requested, with the configured total count of elements
+	// distributed in proportion to the sampled requests.
+	Recommended []BucketUsage
+}
+
+// BucketUsage is the number of sampled requests served by a bucket.
+type BucketUsage struct {
+	// Capacity is the size of each element in the bucket.
+	Capacity int
+
+	// Count is the number of fixed elements in the bucket.
+	Count int
+
+	// Samples is the number of sampled requests served by the bucket.
+	Samples int64
+}
+
 // FloatsPool provides a pool for variable-sized float64 slices.
 type FloatsPool interface {
 	// Init initializes the pool.
@@ -54,3 +54,7 @@ func (p *decoderPool) Get() TagDecoder {
 func (p *decoderPool) Put(e TagDecoder) {
 	p.pool.Put(e)
 }
+
+func (p *decoderPool) SetRefillHighWatermark(value float64) error {
+	return p.pool.SetRefillHighWatermark(value)
+}
@@ -51,3 +51,7 @@ func (p *encoderPool) Get() TagEncoder {
 func (p *encoderPool) Put(e TagEncoder) {
 	p.pool.Put(e)
 }
+
+func (p *encoderPool) SetRefillHighWatermark(value float64) error {
+	return p.pool.SetRefillHighWatermark(value)
+}
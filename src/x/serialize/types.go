@@ -63,6 +63,10 @@ type TagEncoderPool interface {
 
 	// Put puts the encoder back in the pool.
 	Put(TagEncoder)
+
+	// SetRefillHighWatermark sets the refill high watermark of the pool
+	// at runtime.
+	SetRefillHighWatermark(value float64) error
 }
 
 // TagDecoder decodes an encoded byte stream to a TagIterator.
@@ -85,6 +89,10 @@ type TagDecoderPool interface {
 
 	// Put puts the decoder back in the pool.
 	Put(TagDecoder)
+
+	// SetRefillHighWatermark sets the refill high watermark of the pool
+	// at runtime.
+	SetRefillHighWatermark(value float64) error
 }
 
 // TagEncoderOptions sets the knobs for TagEncoder limits.